@@ -0,0 +1,137 @@
+package apk
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// BuildInfo is source provenance embedded in an APK by F-Droid or reproducible-build tooling.
+type BuildInfo struct {
+	Repository string // Source repository URL
+	Commit     string // Source commit hash (may be empty)
+	Entry      string // Archive entry the information was read from
+}
+
+// buildInfoRepoKeys are the (lowercased) keys that carry the source repository URL.
+var buildInfoRepoKeys = []string{"built-from", "repository", "repo", "source-code", "source_code", "sourcecode", "scm", "vcs-url", "vcs_url"}
+
+// buildInfoCommitKeys are the (lowercased) keys that carry the source commit hash.
+var buildInfoCommitKeys = []string{"commit", "git-commit", "git_commit", "revision", "vcs-revision", "vcs_revision"}
+
+// commitHashRegex matches abbreviated or full git commit hashes.
+var commitHashRegex = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// isBuildInfoEntry reports whether a zip entry may carry build provenance.
+func isBuildInfoEntry(name string) bool {
+	base := strings.ToLower(path.Base(name))
+	if base == "buildinfo.json" || strings.HasSuffix(base, ".buildinfo") {
+		return true
+	}
+	if !strings.HasPrefix(name, "META-INF/") {
+		return false
+	}
+	return base == "manifest.mf" || strings.HasPrefix(base, "fdroid")
+}
+
+// extractBuildInfo scans the APK for embedded source repository and commit metadata.
+// Returns nil when nothing usable is found.
+func extractBuildInfo(apkPath string) *BuildInfo {
+	r, err := zip.OpenReader(apkPath)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if !isValidZipEntryPath(f.Name) || !isBuildInfoEntry(f.Name) {
+			continue
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			continue
+		}
+		if info := parseBuildInfo(data); info != nil {
+			info.Entry = f.Name
+			return info
+		}
+	}
+	return nil
+}
+
+// parseBuildInfo reads repository and commit from a JSON object or from
+// "Key: value" / "key=value" lines. Returns nil if no repository URL is present.
+func parseBuildInfo(data []byte) *BuildInfo {
+	fields := make(map[string]string)
+
+	var obj map[string]any
+	if json.Unmarshal(data, &obj) == nil {
+		for k, v := range obj {
+			if s, ok := v.(string); ok {
+				fields[strings.ToLower(k)] = strings.TrimSpace(s)
+			}
+		}
+	} else {
+		var lastKey string
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimRight(scanner.Text(), "\r")
+			// MANIFEST.MF wraps long values onto lines starting with a space
+			if strings.HasPrefix(line, " ") && lastKey != "" {
+				fields[lastKey] += strings.TrimPrefix(line, " ")
+				continue
+			}
+			lastKey = ""
+			idx := strings.IndexAny(line, ":=")
+			if idx <= 0 {
+				continue
+			}
+			key := strings.ToLower(strings.TrimSpace(line[:idx]))
+			if _, exists := fields[key]; !exists {
+				fields[key] = strings.TrimSpace(line[idx+1:])
+				lastKey = key
+			}
+		}
+	}
+
+	info := &BuildInfo{}
+	for _, key := range buildInfoRepoKeys {
+		if repo, commit := splitBuiltFrom(fields[key]); repo != "" {
+			info.Repository, info.Commit = repo, commit
+			break
+		}
+	}
+	if info.Repository == "" {
+		return nil
+	}
+	for _, key := range buildInfoCommitKeys {
+		if commit := fields[key]; commitHashRegex.MatchString(commit) {
+			info.Commit = commit
+			break
+		}
+	}
+	return info
+}
+
+// splitBuiltFrom splits a value like "https://host/owner/repo@abc1234" (or with "#"
+// or a space before the commit) into repository URL and commit.
+// Returns empty strings when the value is not an http(s) URL.
+func splitBuiltFrom(value string) (repo, commit string) {
+	value = strings.TrimSpace(value)
+	if fields := strings.Fields(value); len(fields) == 2 && commitHashRegex.MatchString(fields[1]) {
+		value, commit = fields[0], fields[1]
+	} else if idx := strings.LastIndexAny(value, "@#"); idx > 0 && commitHashRegex.MatchString(value[idx+1:]) {
+		value, commit = value[:idx], value[idx+1:]
+	}
+
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ""
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(value, "/"), ".git"), commit
+}
@@ -0,0 +1,43 @@
+package apk
+
+import (
+	"crypto/x509"
+	"slices"
+)
+
+// CompareCertificate reports whether an APK whose signing lineage is lineage
+// (oldest first, ending with the current signer) is signed by known: "match"
+// when known is the current signer, "rotated" when the key was rotated away
+// from known (updates are checked against the current signer), else "mismatch".
+func CompareCertificate(lineage []*x509.Certificate, known *x509.Certificate) string {
+	switch {
+	case len(lineage) > 0 && lineage[len(lineage)-1].Equal(known):
+		return "match"
+	case slices.ContainsFunc(lineage, known.Equal):
+		return "rotated"
+	default:
+		return "mismatch"
+	}
+}
+
+// CompareCertMessage describes a --compare-cert status for humans.
+func CompareCertMessage(status string) string {
+	switch status {
+	case "verified":
+		return "APK is signed by a certificate with a verified, active identity proof"
+	case "expired":
+		return "Identity proof for this certificate has EXPIRED"
+	case "revoked":
+		return "Identity proof for this certificate has been REVOKED"
+	case "invalid":
+		return "Identity proof for this certificate failed verification"
+	case "no-proof":
+		return "No identity proof found for this certificate"
+	case "match":
+		return "APK is signed by this certificate"
+	case "rotated":
+		return "APK is signed by a newer key rotated from this certificate"
+	default:
+		return "APK is NOT signed by this certificate"
+	}
+}
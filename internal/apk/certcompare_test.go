@@ -0,0 +1,24 @@
+package apk
+
+import (
+	"crypto/x509"
+	"testing"
+)
+
+func TestCompareCertificate(t *testing.T) {
+	old, current, other := testCertificate(t, "old"), testCertificate(t, "current"), testCertificate(t, "other")
+	lineage := []*x509.Certificate{old, current}
+
+	for _, tt := range []struct {
+		known *x509.Certificate
+		want  string
+	}{
+		{current, "match"},
+		{old, "rotated"},
+		{other, "mismatch"},
+	} {
+		if got := CompareCertificate(lineage, tt.known); got != tt.want {
+			t.Errorf("CompareCertificate(%s) = %q, want %q", tt.known.Subject.CommonName, got, tt.want)
+		}
+	}
+}
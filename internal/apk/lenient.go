@@ -0,0 +1,233 @@
+package apk
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Binary XML chunk types, from ResourceTypes.h in the Android framework.
+const (
+	chunkStringPool   = 0x0001
+	chunkResourceMap  = 0x0180
+	chunkStartElement = 0x0102
+)
+
+// Resource IDs of the android:versionCode and android:versionName attributes.
+// Obfuscators strip attribute names from the string pool, but the resource
+// map still identifies them.
+const (
+	attrVersionCode = 0x0101021b
+	attrVersionName = 0x0101021c
+)
+
+// Res_value data types of the attribute values the scan reads.
+const (
+	valueString = 0x03
+	valueIntDec = 0x10
+	valueIntHex = 0x11
+)
+
+// noIndex marks an absent string pool reference.
+const noIndex = 0xffffffff
+
+// recoverManifest is the lenient fallback for a manifest parseManifest failed
+// on with cause. Fields decoded before the error are kept when they include
+// the package and a version. Otherwise the binary XML is scanned for the
+// manifest element's package and version attributes, tolerating the broken
+// chunk headers and stripped attribute names that make the decoder give up.
+// The warnings describe what was lost.
+func recoverManifest(path string, partial manifestInfo, cause error) (manifestInfo, []string, error) {
+	if partial.PackageID != "" && (partial.VersionName != "" || partial.VersionCode != 0) {
+		warning := fmt.Sprintf("manifest decoding stopped early (%v); using the fields read before the error, so permissions, features or the label may be missing", cause)
+		return partial, []string{warning}, nil
+	}
+
+	data, err := readAPKResource(path, "AndroidManifest.xml")
+	if err != nil {
+		return manifestInfo{}, nil, fmt.Errorf("%w (lenient scan: %v)", cause, err)
+	}
+	info := scanManifest(data)
+	if info.PackageID == "" {
+		return manifestInfo{}, nil, fmt.Errorf("%w (lenient scan found no package attribute either)", cause)
+	}
+
+	warnings := []string{fmt.Sprintf("manifest could not be decoded (%v); the package and version were recovered by scanning the binary XML, and SDK levels, permissions, features and the label are missing", cause)}
+	if info.VersionName == "" && info.VersionCode == 0 {
+		warnings = append(warnings, "the manifest scan found no version")
+	}
+	return info, warnings, nil
+}
+
+// scanManifest reads the package and version attributes of the manifest
+// element from Android binary XML without trusting its chunk structure: the
+// string pool, resource map and start elements are found by their chunk
+// signatures at any 4-byte aligned offset.
+func scanManifest(data []byte) manifestInfo {
+	var pool *stringPool
+	var resourceIDs []uint32
+	for off := 0; off+8 <= len(data); off += 4 {
+		switch {
+		case pool == nil && chunkAt(data, off, chunkStringPool, 0x1c):
+			pool = newStringPool(data, off)
+		case resourceIDs == nil && chunkAt(data, off, chunkResourceMap, 0x08):
+			size := int(binary.LittleEndian.Uint32(data[off+4:]))
+			for p := off + 8; p+4 <= off+size && p+4 <= len(data); p += 4 {
+				resourceIDs = append(resourceIDs, binary.LittleEndian.Uint32(data[p:]))
+			}
+		}
+	}
+	if pool == nil {
+		return manifestInfo{}
+	}
+
+	for off := 0; off+36 <= len(data); off += 4 {
+		if !chunkAt(data, off, chunkStartElement, 0x10) {
+			continue
+		}
+		info, isManifest := scanElement(data, off, pool, resourceIDs)
+		if isManifest || info.PackageID != "" {
+			return info
+		}
+	}
+	return manifestInfo{}
+}
+
+// chunkAt reports whether a chunk header of chunkType and headerSize starts at off.
+func chunkAt(data []byte, off int, chunkType, headerSize uint16) bool {
+	return binary.LittleEndian.Uint16(data[off:]) == chunkType && binary.LittleEndian.Uint16(data[off+2:]) == headerSize
+}
+
+// scanElement reads the version and package attributes of the start element
+// at off, and reports whether it is named manifest.
+func scanElement(data []byte, off int, pool *stringPool, resourceIDs []uint32) (info manifestInfo, isManifest bool) {
+	ext := off + 16
+	isManifest = pool.get(binary.LittleEndian.Uint32(data[ext+4:])) == "manifest"
+	attrStart := int(binary.LittleEndian.Uint16(data[ext+8:]))
+	attrSize := int(binary.LittleEndian.Uint16(data[ext+10:]))
+	attrCount := int(binary.LittleEndian.Uint16(data[ext+12:]))
+	if attrSize < 20 {
+		return info, isManifest
+	}
+
+	for i := range attrCount {
+		a := ext + attrStart + i*attrSize
+		if a+20 > len(data) {
+			break
+		}
+		nameIndex := binary.LittleEndian.Uint32(data[a+4:])
+		rawValue := binary.LittleEndian.Uint32(data[a+8:])
+		dataType := data[a+15]
+		value := binary.LittleEndian.Uint32(data[a+16:])
+
+		var resourceID uint32
+		if int(nameIndex) < len(resourceIDs) {
+			resourceID = resourceIDs[nameIndex]
+		}
+		str := pool.get(rawValue)
+		if str == "" && dataType == valueString {
+			str = pool.get(value)
+		}
+
+		switch name := pool.get(nameIndex); {
+		case name == "package" && resourceID == 0:
+			info.PackageID = str
+		case resourceID == attrVersionCode || name == "versionCode":
+			if dataType == valueIntDec || dataType == valueIntHex {
+				info.VersionCode = int64(value)
+			} else {
+				info.VersionCode = parseManifestInt(str)
+			}
+		case resourceID == attrVersionName || name == "versionName":
+			if !isResourceReference(str) {
+				info.VersionName = str
+			}
+		}
+	}
+	return info, isManifest
+}
+
+// stringPool reads strings from a binary XML string pool chunk.
+type stringPool struct {
+	data    []byte
+	offsets int // offset of the string offset array
+	strings int // offset of the string data
+	count   uint32
+	utf8    bool
+}
+
+func newStringPool(data []byte, off int) *stringPool {
+	if off+28 > len(data) {
+		return nil
+	}
+	return &stringPool{
+		data:    data,
+		offsets: off + int(binary.LittleEndian.Uint16(data[off+2:])),
+		strings: off + int(binary.LittleEndian.Uint32(data[off+20:])),
+		count:   binary.LittleEndian.Uint32(data[off+8:]),
+		utf8:    binary.LittleEndian.Uint32(data[off+16:])&0x100 != 0,
+	}
+}
+
+// get returns the string at index, or "" when it is absent or out of bounds.
+func (s *stringPool) get(index uint32) string {
+	if s == nil || index == noIndex || index >= s.count {
+		return ""
+	}
+	o := s.offsets + int(index)*4
+	if o+4 > len(s.data) {
+		return ""
+	}
+	p := s.strings + int(binary.LittleEndian.Uint32(s.data[o:]))
+	if p < 0 || p >= len(s.data) {
+		return ""
+	}
+
+	if s.utf8 {
+		// UTF-16 length, then UTF-8 length, each one or two bytes
+		var n int
+		_, p = s.utf8Length(p)
+		n, p = s.utf8Length(p)
+		if p < 0 || p+n > len(s.data) {
+			return ""
+		}
+		return string(s.data[p : p+n])
+	}
+
+	if p+2 > len(s.data) {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint16(s.data[p:]))
+	p += 2
+	if n&0x8000 != 0 {
+		if p+2 > len(s.data) {
+			return ""
+		}
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(s.data[p:]))
+		p += 2
+	}
+	if p+2*n > len(s.data) {
+		return ""
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(s.data[p+2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// utf8Length decodes a UTF-8 pool length prefix at p, returning the length
+// and the offset after it (-1 when out of bounds).
+func (s *stringPool) utf8Length(p int) (int, int) {
+	if p < 0 || p >= len(s.data) {
+		return 0, -1
+	}
+	n := int(s.data[p])
+	if n&0x80 == 0 {
+		return n, p + 1
+	}
+	if p+1 >= len(s.data) {
+		return 0, -1
+	}
+	return (n&0x7f)<<8 | int(s.data[p+1]), p + 2
+}
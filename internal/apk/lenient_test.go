@@ -0,0 +1,149 @@
+package apk
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+// testAttr is a binary XML attribute: string pool indices of its name and raw
+// value, and its typed value.
+type testAttr struct {
+	name, raw uint32
+	dataType  uint8
+	data      uint32
+}
+
+// testBinaryXML encodes a binary XML document of one start element.
+func testBinaryXML(strs []string, utf8 bool, resourceIDs []uint32, element uint32, attrs []testAttr) []byte {
+	le := binary.LittleEndian
+	pad := func(b []byte) []byte {
+		for len(b)%4 != 0 {
+			b = append(b, 0)
+		}
+		return b
+	}
+
+	var stringData []byte
+	var offsets []byte
+	for _, s := range strs {
+		offsets = le.AppendUint32(offsets, uint32(len(stringData)))
+		if utf8 {
+			stringData = append(stringData, byte(len(s)), byte(len(s)))
+			stringData = append(stringData, s...)
+			stringData = append(stringData, 0)
+		} else {
+			units := utf16.Encode([]rune(s))
+			stringData = le.AppendUint16(stringData, uint16(len(units)))
+			for _, u := range units {
+				stringData = le.AppendUint16(stringData, u)
+			}
+			stringData = le.AppendUint16(stringData, 0)
+		}
+	}
+	stringData = pad(stringData)
+	var flags uint32
+	if utf8 {
+		flags = 0x100
+	}
+	pool := le.AppendUint16(nil, chunkStringPool)
+	pool = le.AppendUint16(pool, 0x1c)
+	pool = le.AppendUint32(pool, uint32(28+len(offsets)+len(stringData)))
+	pool = le.AppendUint32(pool, uint32(len(strs)))
+	pool = le.AppendUint32(pool, 0)
+	pool = le.AppendUint32(pool, flags)
+	pool = le.AppendUint32(pool, uint32(28+len(offsets)))
+	pool = le.AppendUint32(pool, 0)
+	pool = append(append(pool, offsets...), stringData...)
+
+	resMap := le.AppendUint16(nil, chunkResourceMap)
+	resMap = le.AppendUint16(resMap, 0x08)
+	resMap = le.AppendUint32(resMap, uint32(8+4*len(resourceIDs)))
+	for _, id := range resourceIDs {
+		resMap = le.AppendUint32(resMap, id)
+	}
+
+	elem := le.AppendUint16(nil, chunkStartElement)
+	elem = le.AppendUint16(elem, 0x10)
+	elem = le.AppendUint32(elem, uint32(36+20*len(attrs)))
+	elem = le.AppendUint32(elem, 1)       // line number
+	elem = le.AppendUint32(elem, noIndex) // comment
+	elem = le.AppendUint32(elem, noIndex) // namespace
+	elem = le.AppendUint32(elem, element)
+	elem = le.AppendUint16(elem, 20) // attributeStart
+	elem = le.AppendUint16(elem, 20) // attributeSize
+	elem = le.AppendUint16(elem, uint16(len(attrs)))
+	elem = append(elem, 0, 0, 0, 0, 0, 0) // id, class and style indices
+	for _, a := range attrs {
+		elem = le.AppendUint32(elem, noIndex)
+		elem = le.AppendUint32(elem, a.name)
+		elem = le.AppendUint32(elem, a.raw)
+		elem = le.AppendUint16(elem, 8)
+		elem = append(elem, 0, a.dataType)
+		elem = le.AppendUint32(elem, a.data)
+	}
+
+	doc := le.AppendUint16(nil, 0x0003)
+	doc = le.AppendUint16(doc, 0x08)
+	doc = le.AppendUint32(doc, uint32(8+len(pool)+len(resMap)+len(elem)))
+	return append(append(append(doc, pool...), resMap...), elem...)
+}
+
+// obfuscatedManifest is a manifest whose versionCode and versionName names
+// were stripped from the string pool, with a zeroed document header.
+func obfuscatedManifest() []byte {
+	strs := []string{"", "", "package", "manifest", "com.example.app", "1.2.3"}
+	data := testBinaryXML(strs, false, []uint32{attrVersionCode, attrVersionName}, 3, []testAttr{
+		{name: 0, raw: noIndex, dataType: valueIntDec, data: 42},
+		{name: 1, raw: 5, dataType: valueString, data: 5},
+		{name: 2, raw: 4, dataType: valueString, data: 4},
+	})
+	clear(data[:8])
+	return data
+}
+
+func TestScanManifest(t *testing.T) {
+	info := scanManifest(obfuscatedManifest())
+	if info.PackageID != "com.example.app" || info.VersionName != "1.2.3" || info.VersionCode != 42 {
+		t.Errorf("scanManifest(obfuscated) = %+v", info)
+	}
+
+	strs := []string{"versionCode", "versionName", "package", "manifest", "com.example.app", "2.0", "7"}
+	info = scanManifest(testBinaryXML(strs, true, nil, 3, []testAttr{
+		{name: 2, raw: 4, dataType: valueString, data: 4},
+		{name: 0, raw: 6, dataType: valueString, data: 6},
+		{name: 1, raw: 5, dataType: valueString, data: 5},
+	}))
+	if info.PackageID != "com.example.app" || info.VersionName != "2.0" || info.VersionCode != 7 {
+		t.Errorf("scanManifest(utf8) = %+v", info)
+	}
+
+	if info := scanManifest([]byte("not binary xml at all")); info.PackageID != "" {
+		t.Errorf("scanManifest(garbage) = %+v", info)
+	}
+}
+
+func TestRecoverManifest(t *testing.T) {
+	cause := errors.New("bad chunk")
+
+	partial := manifestInfo{PackageID: "com.example.app", VersionCode: 3}
+	info, warnings, err := recoverManifest("unused.apk", partial, cause)
+	if err != nil || info.PackageID != "com.example.app" || len(warnings) != 1 {
+		t.Errorf("recoverManifest(partial) = %+v, %v, %v", info, warnings, err)
+	}
+
+	path := writeTestZip(t, map[string]string{"AndroidManifest.xml": string(obfuscatedManifest())})
+	info, warnings, err = recoverManifest(path, manifestInfo{}, cause)
+	if err != nil {
+		t.Fatalf("recoverManifest() error: %v", err)
+	}
+	if info.PackageID != "com.example.app" || info.VersionCode != 42 || len(warnings) != 1 {
+		t.Errorf("recoverManifest() = %+v, %v", info, warnings)
+	}
+
+	path = writeTestZip(t, map[string]string{"AndroidManifest.xml": "garbage"})
+	if _, _, err := recoverManifest(path, manifestInfo{}, cause); !errors.Is(err, cause) {
+		t.Errorf("recoverManifest(garbage) error = %v, want it to wrap the decode error", err)
+	}
+}
@@ -0,0 +1,268 @@
+package apk
+
+import (
+	"archive/zip"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// LintSeverity is how serious a lint finding is.
+type LintSeverity string
+
+const (
+	LintInfo    LintSeverity = "info"
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+
+	// LintNone is only a threshold: ranked above every finding, it never fails.
+	LintNone LintSeverity = "none"
+)
+
+// lintSeverityRanks orders severities.
+var lintSeverityRanks = map[LintSeverity]int{LintInfo: 1, LintWarning: 2, LintError: 3, LintNone: 4}
+
+// lintPenalties is what each finding takes off the 100-point score.
+var lintPenalties = map[LintSeverity]int{LintInfo: 2, LintWarning: 10, LintError: 25}
+
+// ParseLintSeverity parses a --fail-on threshold: info, warning, error or none.
+func ParseLintSeverity(s string) (LintSeverity, error) {
+	severity := LintSeverity(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := lintSeverityRanks[severity]; !ok {
+		return "", fmt.Errorf("invalid severity %q: must be info, warning, error or none", s)
+	}
+	return severity, nil
+}
+
+// LargeEntrySize is the uncompressed size above which a single APK entry is
+// reported by the large-entry check.
+const LargeEntrySize = 50 << 20
+
+// LintFinding is one problem found by a lint check.
+type LintFinding struct {
+	Check    string       `json:"check"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+// LintReport is the result of linting an APK.
+type LintReport struct {
+	APK       string        `json:"apk"`
+	PackageID string        `json:"package_id"`
+	Version   string        `json:"version"`
+	Score     int           `json:"score"` // 100 minus a penalty per finding, at least 0
+	Findings  []LintFinding `json:"findings"`
+	Ignored   []string      `json:"ignored,omitempty"` // checks suppressed by LintOptions.Ignore
+}
+
+// Fails reports whether the report has a finding at or above threshold.
+func (r *LintReport) Fails(threshold LintSeverity) bool {
+	for _, f := range r.Findings {
+		if lintSeverityRanks[f.Severity] >= lintSeverityRanks[threshold] {
+			return true
+		}
+	}
+	return false
+}
+
+// LintOptions controls which checks Lint runs.
+type LintOptions struct {
+	// Ignore lists check IDs to skip (see LintChecks).
+	Ignore []string
+}
+
+// lintInput is what the checks look at: the parsed APK and its zip entries.
+type lintInput struct {
+	info    *APKInfo
+	entries []*zip.File
+}
+
+// LintCheck is a packaging rule run by Lint.
+type LintCheck struct {
+	ID          string
+	Description string
+	run         func(in *lintInput) []LintFinding
+}
+
+// LintChecks are the checks Lint runs, in report order.
+var LintChecks = []LintCheck{
+	{"debuggable", "android:debuggable is set", lintDebuggable},
+	{"test-only", "android:testOnly is set", lintTestOnly},
+	{"v1-only-signature", "Signed only with the legacy v1 (JAR) scheme", lintV1Only},
+	{"missing-arm64", "Native libraries without arm64-v8a", lintMissingArm64},
+	{"manifest-placeholder", "Unresolved ${...} placeholders in the manifest", lintPlaceholders},
+	{"large-entry", "Entries larger than 50 MiB uncompressed", lintLargeEntries},
+	{"min-sdk", "minSdkVersion missing or above targetSdkVersion", lintMinSDK},
+	{"target-sdk", "targetSdkVersion missing or too old to install", lintTargetSDK},
+	{"abi-mismatch", "Native libraries missing from some ABIs", lintABIMismatch},
+}
+
+// Lint runs the packaging checks on a parsed APK, reading its entries from
+// info.FilePath. It fails on an unknown check ID in opts.Ignore.
+func Lint(info *APKInfo, opts LintOptions) (*LintReport, error) {
+	ignored := make(map[string]bool)
+	for _, id := range opts.Ignore {
+		if !slices.ContainsFunc(LintChecks, func(c LintCheck) bool { return c.ID == id }) {
+			return nil, fmt.Errorf("unknown lint check %q", id)
+		}
+		ignored[id] = true
+	}
+
+	r, err := zip.OpenReader(info.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open APK: %w", err)
+	}
+	defer r.Close()
+
+	in := &lintInput{info: info}
+	for _, f := range r.File {
+		if isValidZipEntryPath(f.Name) {
+			in.entries = append(in.entries, f)
+		}
+	}
+
+	report := &LintReport{
+		APK:       filepath.Base(info.FilePath),
+		PackageID: info.PackageID,
+		Version:   info.VersionName,
+		Score:     100,
+		Findings:  []LintFinding{},
+	}
+	for _, check := range LintChecks {
+		if ignored[check.ID] {
+			report.Ignored = append(report.Ignored, check.ID)
+			continue
+		}
+		for _, f := range check.run(in) {
+			f.Check = check.ID
+			report.Findings = append(report.Findings, f)
+			report.Score -= lintPenalties[f.Severity]
+		}
+	}
+	report.Score = max(report.Score, 0)
+	return report, nil
+}
+
+func lintDebuggable(in *lintInput) []LintFinding {
+	if !in.info.Debuggable {
+		return nil
+	}
+	return []LintFinding{{Severity: LintError, Message: "android:debuggable is true: anyone with adb can attach a debugger and read the app's data"}}
+}
+
+func lintTestOnly(in *lintInput) []LintFinding {
+	if !in.info.TestOnly {
+		return nil
+	}
+	return []LintFinding{{Severity: LintError, Message: "android:testOnly is true: Android only installs it with adb install -t"}}
+}
+
+func lintV1Only(in *lintInput) []LintFinding {
+	if !in.info.IsV1OnlySigned() {
+		return nil
+	}
+	return []LintFinding{{Severity: LintError, Message: ErrV1OnlySignature.Error()}}
+}
+
+func lintMissingArm64(in *lintInput) []LintFinding {
+	archs := in.info.Architectures
+	if len(archs) == 0 || slices.Contains(archs, "arm64-v8a") {
+		return nil
+	}
+	sorted := slices.Clone(archs)
+	slices.Sort(sorted)
+	return []LintFinding{{Severity: LintError, Message: fmt.Sprintf(
+		"native libraries for %s but not arm64-v8a: most current devices cannot run the app", strings.Join(sorted, ", "))}}
+}
+
+func lintPlaceholders(in *lintInput) []LintFinding {
+	var findings []LintFinding
+	for _, p := range in.info.Placeholders {
+		findings = append(findings, LintFinding{Severity: LintError, Message: "unresolved manifest placeholder: " + p})
+	}
+	return findings
+}
+
+func lintLargeEntries(in *lintInput) []LintFinding {
+	var findings []LintFinding
+	for _, f := range in.entries {
+		if f.UncompressedSize64 <= LargeEntrySize {
+			continue
+		}
+		msg := fmt.Sprintf("%s is %d MiB", f.Name, f.UncompressedSize64>>20)
+		if f.Method == zip.Store {
+			msg += ", stored uncompressed"
+			if strings.HasSuffix(f.Name, ".so") {
+				msg += " (extractNativeLibs=false keeps native libraries uncompressed in the APK)"
+			}
+		}
+		findings = append(findings, LintFinding{Severity: LintWarning, Message: msg})
+	}
+	return findings
+}
+
+func lintMinSDK(in *lintInput) []LintFinding {
+	minSDK, targetSDK := in.info.MinSDK, in.info.TargetSDK
+	switch {
+	case minSDK == 0:
+		return []LintFinding{{Severity: LintWarning, Message: "no minSdkVersion: the app claims to run on API level 1"}}
+	case targetSDK > 0 && minSDK > targetSDK:
+		return []LintFinding{{Severity: LintError, Message: fmt.Sprintf("minSdkVersion %d is above targetSdkVersion %d", minSDK, targetSDK)}}
+	}
+	return nil
+}
+
+// minInstallableTargetSDK is the lowest targetSdkVersion Android 14 installs.
+const minInstallableTargetSDK = 23
+
+func lintTargetSDK(in *lintInput) []LintFinding {
+	targetSDK := in.info.TargetSDK
+	switch {
+	case targetSDK == 0:
+		return []LintFinding{{Severity: LintWarning, Message: "no targetSdkVersion: it defaults to minSdkVersion, enabling every compatibility behavior"}}
+	case targetSDK < minInstallableTargetSDK:
+		return []LintFinding{{Severity: LintError, Message: fmt.Sprintf(
+			"targetSdkVersion %d is below %d: Android 14+ refuses to install the app", targetSDK, minInstallableTargetSDK)}}
+	}
+	return nil
+}
+
+// lintABIMismatch reports native libraries shipped for some ABIs but not
+// others: on a device using an ABI without it, loading the library crashes.
+func lintABIMismatch(in *lintInput) []LintFinding {
+	libsByABI := make(map[string]map[string]bool)
+	all := make(map[string]bool)
+	for _, f := range in.entries {
+		parts := strings.Split(f.Name, "/")
+		if len(parts) != 3 || parts[0] != "lib" || !strings.HasSuffix(parts[2], ".so") {
+			continue
+		}
+		if libsByABI[parts[1]] == nil {
+			libsByABI[parts[1]] = make(map[string]bool)
+		}
+		libsByABI[parts[1]][parts[2]] = true
+		all[parts[2]] = true
+	}
+	if len(libsByABI) < 2 {
+		return nil
+	}
+
+	var findings []LintFinding
+	for _, abi := range slices.Sorted(maps.Keys(libsByABI)) {
+		var missing []string
+		for lib := range all {
+			if !libsByABI[abi][lib] {
+				missing = append(missing, lib)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		slices.Sort(missing)
+		findings = append(findings, LintFinding{Severity: LintWarning, Message: fmt.Sprintf(
+			"lib/%s lacks %s, shipped for other ABIs", abi, strings.Join(missing, ", "))})
+	}
+	return findings
+}
@@ -0,0 +1,182 @@
+package apk
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// lintInfo is an APKInfo that passes every check, for a zip with entries.
+func lintInfo(t *testing.T, entries map[string]string) *APKInfo {
+	t.Helper()
+	return &APKInfo{
+		PackageID:        "com.example.app",
+		VersionName:      "1.0.0",
+		MinSDK:           24,
+		TargetSDK:        34,
+		SignatureSchemes: []string{"v2"},
+		FilePath:         writeTestZip(t, entries),
+	}
+}
+
+// lintChecksFound returns the check IDs of the report's findings.
+func lintChecksFound(report *LintReport) []string {
+	var ids []string
+	for _, f := range report.Findings {
+		ids = append(ids, f.Check)
+	}
+	return ids
+}
+
+func TestLintChecks(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries map[string]string
+		modify  func(info *APKInfo)
+		want    []string
+	}{
+		{"clean", map[string]string{"classes.dex": "dex"}, nil, nil},
+		{"debuggable", nil, func(i *APKInfo) { i.Debuggable = true }, []string{"debuggable"}},
+		{"test only", nil, func(i *APKInfo) { i.TestOnly = true }, []string{"test-only"}},
+		{"v1 only", map[string]string{"META-INF/CERT.RSA": "sig"}, func(i *APKInfo) { i.SignatureSchemes = []string{"v1"} }, []string{"v1-only-signature"}},
+		{"missing arm64", nil, func(i *APKInfo) { i.Architectures = []string{"x86_64", "armeabi-v7a"} }, []string{"missing-arm64"}},
+		{"arm64 present", nil, func(i *APKInfo) { i.Architectures = []string{"armeabi-v7a", "arm64-v8a"} }, nil},
+		{"placeholders", nil, func(i *APKInfo) {
+			i.Placeholders = []string{"provider@authorities=${applicationId}.files", "meta-data@value=${MAPS_KEY}"}
+		}, []string{"manifest-placeholder", "manifest-placeholder"}},
+		{"no min sdk", nil, func(i *APKInfo) { i.MinSDK = 0 }, []string{"min-sdk"}},
+		{"min above target", nil, func(i *APKInfo) { i.MinSDK = 30; i.TargetSDK = 28 }, []string{"min-sdk"}},
+		{"no target sdk", nil, func(i *APKInfo) { i.TargetSDK = 0 }, []string{"target-sdk"}},
+		{"old target sdk", nil, func(i *APKInfo) { i.MinSDK = 16; i.TargetSDK = 22 }, []string{"target-sdk"}},
+		{"abi mismatch", map[string]string{
+			"lib/arm64-v8a/libapp.so":   "elf",
+			"lib/arm64-v8a/libextra.so": "elf",
+			"lib/x86_64/libapp.so":      "elf",
+		}, func(i *APKInfo) { i.Architectures = []string{"arm64-v8a", "x86_64"} }, []string{"abi-mismatch"}},
+		{"same libs per abi", map[string]string{
+			"lib/arm64-v8a/libapp.so": "elf",
+			"lib/x86_64/libapp.so":    "elf",
+		}, func(i *APKInfo) { i.Architectures = []string{"arm64-v8a", "x86_64"} }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := lintInfo(t, tt.entries)
+			if tt.modify != nil {
+				tt.modify(info)
+			}
+			report, err := Lint(info, LintOptions{})
+			if err != nil {
+				t.Fatalf("Lint() error: %v", err)
+			}
+			if got := lintChecksFound(report); !slices.Equal(got, tt.want) {
+				t.Errorf("findings %v, want %v: %+v", got, tt.want, report.Findings)
+			}
+		})
+	}
+}
+
+func TestLintLargeEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.apk")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("assets/model.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(bytes.Repeat([]byte{0}, LargeEntrySize+1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	info := lintInfo(t, nil)
+	info.FilePath = path
+	report, err := Lint(info, LintOptions{})
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Check != "large-entry" || !strings.Contains(report.Findings[0].Message, "assets/model.bin is 50 MiB") {
+		t.Errorf("findings = %+v, want one large-entry for assets/model.bin", report.Findings)
+	}
+}
+
+func TestLintIgnoreScoreAndThreshold(t *testing.T) {
+	info := lintInfo(t, nil)
+	info.Debuggable = true // error
+	info.MinSDK = 0        // warning
+
+	report, err := Lint(info, LintOptions{})
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if report.Score != 65 {
+		t.Errorf("Score = %d, want 65", report.Score)
+	}
+	for threshold, want := range map[LintSeverity]bool{LintInfo: true, LintWarning: true, LintError: true, LintNone: false} {
+		if got := report.Fails(threshold); got != want {
+			t.Errorf("Fails(%s) = %v, want %v", threshold, got, want)
+		}
+	}
+
+	report, err = Lint(info, LintOptions{Ignore: []string{"debuggable"}})
+	if err != nil {
+		t.Fatalf("Lint() error: %v", err)
+	}
+	if got := lintChecksFound(report); !slices.Equal(got, []string{"min-sdk"}) || report.Score != 90 {
+		t.Errorf("with debuggable ignored: findings %v, score %d; want [min-sdk], 90", got, report.Score)
+	}
+	if !slices.Equal(report.Ignored, []string{"debuggable"}) {
+		t.Errorf("Ignored = %v, want [debuggable]", report.Ignored)
+	}
+	if report.Fails(LintError) || !report.Fails(LintWarning) {
+		t.Errorf("with only a warning: Fails(error) = %v, Fails(warning) = %v", report.Fails(LintError), report.Fails(LintWarning))
+	}
+
+	if _, err := Lint(info, LintOptions{Ignore: []string{"no-such-check"}}); err == nil {
+		t.Error("expected an error for an unknown check")
+	}
+}
+
+func TestParseLintSeverity(t *testing.T) {
+	for input, want := range map[string]LintSeverity{"error": LintError, "Warning": LintWarning, " info ": LintInfo, "none": LintNone} {
+		if got, err := ParseLintSeverity(input); err != nil || got != want {
+			t.Errorf("ParseLintSeverity(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseLintSeverity("fatal"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestManifestCollectorLintFields(t *testing.T) {
+	attr := func(name, value string) xml.Attr {
+		return xml.Attr{Name: xml.Name{Space: "http://schemas.android.com/apk/res/android", Local: name}, Value: value}
+	}
+	c := &manifestCollector{}
+	for _, start := range []xml.StartElement{
+		{Name: xml.Name{Local: "manifest"}, Attr: []xml.Attr{{Name: xml.Name{Local: "package"}, Value: "com.example.app"}}},
+		{Name: xml.Name{Local: "application"}, Attr: []xml.Attr{attr("debuggable", "true"), attr("testOnly", "false")}},
+		{Name: xml.Name{Local: "provider"}, Attr: []xml.Attr{attr("authorities", "${applicationId}.files")}},
+	} {
+		if err := c.EncodeToken(start); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !c.info.Debuggable || c.info.TestOnly {
+		t.Errorf("Debuggable = %v, TestOnly = %v; want true, false", c.info.Debuggable, c.info.TestOnly)
+	}
+	if want := []string{"provider@authorities=${applicationId}.files"}; !slices.Equal(c.info.Placeholders, want) {
+		t.Errorf("Placeholders = %v, want %v", c.info.Placeholders, want)
+	}
+}
@@ -0,0 +1,144 @@
+package apk
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Resource table chunk types (ResChunk_header.type in the Android framework).
+const (
+	resTableChunk        = 0x0002
+	resTablePackageChunk = 0x0200
+	resTableTypeChunk    = 0x0201
+)
+
+// resTableTypeConfigOffset is where ResTable_config starts in a type chunk:
+// the chunk header, id, flags, reserved, entryCount and entriesStart.
+const resTableTypeConfigOffset = 20
+
+// pseudoLocales are the locales Android build tools generate for layout
+// testing, which say nothing about the app's translations.
+var pseudoLocales = map[string]bool{"en-XA": true, "ar-XB": true}
+
+// extractLocales lists the locales the APK has resources for, read from the
+// config qualifiers of resources.arsc (e.g. ["de", "pt-BR"]), sorted and
+// without duplicates. Locales pulled in with library resources (such as AndroidX)
+// are included unless the build filters them. Returns nil when there is no
+// resource table or it cannot be read.
+func extractLocales(apkPath string) []string {
+	r, err := zip.OpenReader(apkPath)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name != "resources.arsc" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			return nil
+		}
+		return resourceTableLocales(data)
+	}
+	return nil
+}
+
+// resourceTableLocales walks the chunks of a resource table and collects the
+// locales of its type chunks.
+func resourceTableLocales(data []byte) []string {
+	chunkType, headerSize, size, ok := readChunkHeader(data, 0)
+	if !ok || chunkType != resTableChunk {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	forEachChunk(data[:size], headerSize, func(pkg []byte, chunkType uint16, headerSize int) {
+		if chunkType != resTablePackageChunk {
+			return
+		}
+		forEachChunk(pkg, headerSize, func(chunk []byte, chunkType uint16, _ int) {
+			// The config must reach past language and country
+			if chunkType != resTableTypeChunk || len(chunk) < resTableTypeConfigOffset+12 {
+				return
+			}
+			if locale := configLocale(chunk[resTableTypeConfigOffset:]); locale != "" && !pseudoLocales[locale] {
+				seen[locale] = true
+			}
+		})
+	})
+
+	var locales []string
+	for locale := range seen {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+// forEachChunk calls fn for each child chunk of parent, whose children start
+// at offset. Chunks that run past parent end the walk.
+func forEachChunk(parent []byte, offset int, fn func(chunk []byte, chunkType uint16, headerSize int)) {
+	for offset < len(parent) {
+		chunkType, headerSize, size, ok := readChunkHeader(parent, offset)
+		if !ok {
+			return
+		}
+		fn(parent[offset:offset+size], chunkType, headerSize)
+		offset += size
+	}
+}
+
+// readChunkHeader reads the ResChunk_header at offset, checking that the
+// chunk fits in data.
+func readChunkHeader(data []byte, offset int) (chunkType uint16, headerSize, size int, ok bool) {
+	if offset < 0 || len(data)-offset < 8 {
+		return 0, 0, 0, false
+	}
+	chunkType = binary.LittleEndian.Uint16(data[offset:])
+	headerSize = int(binary.LittleEndian.Uint16(data[offset+2:]))
+	size = int(binary.LittleEndian.Uint32(data[offset+4:]))
+	if headerSize < 8 || size < headerSize || size > len(data)-offset {
+		return 0, 0, 0, false
+	}
+	return chunkType, headerSize, size, true
+}
+
+// configLocale returns the locale of a ResTable_config as a BCP 47 style tag
+// ("de", "pt-BR", "es-419"), or "" for the default config.
+func configLocale(config []byte) string {
+	// size (4), mcc (2), mnc (2), language (2), country (2)
+	language := unpackLocaleCode(config[8:10], 'a')
+	if language == "" {
+		return ""
+	}
+	if region := unpackLocaleCode(config[10:12], '0'); region != "" {
+		return strings.ToLower(language) + "-" + strings.ToUpper(region)
+	}
+	return strings.ToLower(language)
+}
+
+// unpackLocaleCode decodes a language or region code. Two-letter codes are
+// stored as is; three-letter codes (and numeric regions) are packed into 5-bit
+// values relative to base, with the high bit set.
+func unpackLocaleCode(in []byte, base byte) string {
+	if in[0] == 0 {
+		return ""
+	}
+	if in[0]&0x80 == 0 {
+		return string(in[:2])
+	}
+	first := in[1] & 0x1f
+	second := (in[1]&0xe0)>>5 | (in[0]&0x03)<<3
+	third := (in[0] & 0x7c) >> 2
+	return string([]byte{base + first, base + second, base + third})
+}
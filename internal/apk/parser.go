@@ -8,6 +8,7 @@ import (
 	"crypto/x509"
 	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
@@ -16,6 +17,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -41,9 +43,10 @@ type APKInfo struct {
 	VersionName string
 	VersionCode int64
 
-	// SDK versions
+	// SDK versions. MaxSDK is 0 unless the manifest sets maxSdkVersion.
 	MinSDK    int32
 	TargetSDK int32
+	MaxSDK    int32
 
 	// App metadata
 	Label string // App name
@@ -57,20 +60,88 @@ type APKInfo struct {
 	// Required device features declared by the manifest.
 	Features []string
 
-	// Certificate SHA-256 fingerprint (hex encoded, lowercase)
+	// OptionalFeatures are uses-feature elements with android:required="false".
+	OptionalFeatures []string
+
+	// UnsupportedScreens lists the screen sizes supports-screens excludes
+	// ("small", "normal", "large", "xlarge").
+	UnsupportedScreens []string
+
+	// Debuggable and TestOnly are the application's android:debuggable and
+	// android:testOnly flags, which release builds should not set.
+	Debuggable bool
+	TestOnly   bool
+
+	// Placeholders lists manifest attributes whose value still holds a build
+	// placeholder such as ${applicationId}, as "element@attribute=value".
+	Placeholders []string
+
+	// ParseWarnings describes what a lenient parse (ParseOptions.Lenient) could
+	// not decode. Nil when the manifest decoded normally.
+	ParseWarnings []string
+
+	// Locales with resources in resources.arsc (e.g. ["de", "pt-BR"]), sorted
+	Locales []string
+
+	// Certificate SHA-256 fingerprint (hex encoded, lowercase) of the current
+	// signer. With v3 key rotation this is the newest certificate of the lineage.
 	CertFingerprint string
 
+	// CertLineage lists the SHA-256 fingerprints of a v3 key rotation lineage,
+	// oldest first and ending with CertFingerprint. Nil when the key was never rotated.
+	CertLineage []string
+
+	// Signature schemes present, oldest first (e.g. ["v1", "v2", "v3"])
+	SignatureSchemes []string
+
 	// Icon PNG bytes (nil if not found or extraction failed)
 	Icon []byte
 
+	// IconDensity is the requested ParseOptions.IconDensity when the icon was
+	// extracted at that density, or "" when it was auto-picked.
+	IconDensity string
+
+	// Source provenance embedded by F-Droid or reproducible-build tooling (nil if none)
+	BuildInfo *BuildInfo
+
 	// File information
 	FilePath string
 	FileSize int64
 	SHA256   string
 }
 
+// ParseOptions controls optional parts of APK parsing.
+type ParseOptions struct {
+	// IconDensity selects the launcher icon raster: a density qualifier such as
+	// "xxxhdpi", or "max" for the largest raster in the APK. Empty auto-picks,
+	// which may render an adaptive icon instead.
+	IconDensity string
+
+	// Lenient recovers the package ID and version from a manifest that fails
+	// to decode instead of failing; what was lost is listed in ParseWarnings.
+	Lenient bool
+}
+
+// iconDensities maps density qualifiers to their dpi, highest first.
+var iconDensities = []struct {
+	name string
+	dpi  uint16
+}{
+	{"xxxhdpi", 640},
+	{"xxhdpi", 480},
+	{"xhdpi", 320},
+	{"hdpi", 240},
+	{"mdpi", 160},
+	{"ldpi", 120},
+}
+
 // Parse extracts metadata from an APK file.
 func Parse(path string) (*APKInfo, error) {
+	return ParseWithOptions(path, ParseOptions{})
+}
+
+// ParseWithOptions extracts metadata from an APK file.
+func ParseWithOptions(path string, opts ParseOptions) (*APKInfo, error) {
 	// Get file info
 	fi, err := os.Stat(path)
 	if err != nil {
@@ -78,7 +149,7 @@ func Parse(path string) (*APKInfo, error) {
 	}
 
 	// Calculate SHA256
-	sha256Hash, err := hashFile(path)
+	sha256Hash, err := HashFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash APK: %w", err)
 	}
@@ -86,6 +157,10 @@ func Parse(path string) (*APKInfo, error) {
 	// Parse the manifest with apkparser. Unlike androidbinary, it supports
 	// manifests produced by current Android build tools.
 	manifest, err := parseManifest(path)
+	var parseWarnings []string
+	if err != nil && opts.Lenient {
+		manifest, parseWarnings, err = recoverManifest(path, manifest, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse APK manifest: %w", err)
 	}
@@ -96,28 +171,58 @@ func Parse(path string) (*APKInfo, error) {
 		VersionCode: manifest.VersionCode,
 		MinSDK:      manifest.MinSDK,
 		TargetSDK:   manifest.TargetSDK,
+		MaxSDK:      manifest.MaxSDK,
 		Label:       manifest.Label,
 		Permissions: manifest.Permissions,
 		Features:    manifest.Features,
 		FilePath:    path,
 		FileSize:    fi.Size(),
 		SHA256:      sha256Hash,
+
+		OptionalFeatures:   manifest.OptionalFeatures,
+		UnsupportedScreens: manifest.UnsupportedScreens,
+
+		Debuggable:   manifest.Debuggable,
+		TestOnly:     manifest.TestOnly,
+		Placeholders: manifest.Placeholders,
+
+		ParseWarnings: parseWarnings,
 	}
 
 	// Extract native architectures from lib/ directory
 	info.Architectures = extractArchitectures(path)
 
+	// Collect translated locales from the resource table configs
+	info.Locales = extractLocales(path)
+
+	// Recover source repository/commit from embedded build metadata
+	info.BuildInfo = extractBuildInfo(path)
+
 	// Verify signature and extract certificate fingerprint
-	certFingerprint, err := verifyCertificate(path)
+	certs, schemes, err := verifyCertificate(path)
 	if err != nil {
 		return nil, fmt.Errorf("signature verification failed: %w", err)
 	}
-	info.CertFingerprint = certFingerprint
+	info.CertFingerprint = certFingerprint(certs[len(certs)-1])
+	if len(certs) > 1 {
+		for _, cert := range certs {
+			info.CertLineage = append(info.CertLineage, certFingerprint(cert))
+		}
+	}
+	info.SignatureSchemes = schemes
 
-	// Extract icon. Icon extraction failure is not fatal.
-	icon, err := extractIcon(path, manifest.Icon)
-	if err == nil {
-		info.Icon = icon
+	// Extract icon. Icon extraction failure is not fatal, and a density the APK
+	// has no raster for falls back to the automatic pick.
+	if opts.IconDensity != "" {
+		if icon, err := extractIconAtDensity(path, manifest.Icon, opts.IconDensity); err == nil {
+			info.Icon = icon
+			info.IconDensity = opts.IconDensity
+		}
+	}
+	if info.Icon == nil {
+		if icon, err := extractIcon(path, manifest.Icon); err == nil {
+			info.Icon = icon
+		}
 	}
 
 	return info, nil
@@ -129,10 +234,18 @@ type manifestInfo struct {
 	VersionCode int64
 	MinSDK      int32
 	TargetSDK   int32
+	MaxSDK      int32
 	Label       string
 	Icon        string
 	Permissions []string
 	Features    []string
+
+	OptionalFeatures   []string
+	UnsupportedScreens []string
+
+	Debuggable   bool
+	TestOnly     bool
+	Placeholders []string
 }
 
 // manifestCollector records the fields zsp needs from an Android manifest.
@@ -148,6 +261,12 @@ func (c *manifestCollector) EncodeToken(token xml.Token) error {
 		return nil
 	}
 
+	for _, attr := range start.Attr {
+		if strings.Contains(attr.Value, "${") {
+			c.info.Placeholders = append(c.info.Placeholders, start.Name.Local+"@"+attr.Name.Local+"="+attr.Value)
+		}
+	}
+
 	switch start.Name.Local {
 	case "manifest":
 		c.info.PackageID = attribute(start, "package")
@@ -156,17 +275,31 @@ func (c *manifestCollector) EncodeToken(token xml.Token) error {
 	case "uses-sdk":
 		c.info.MinSDK = int32(parseManifestInt(attribute(start, "minSdkVersion")))
 		c.info.TargetSDK = int32(parseManifestInt(attribute(start, "targetSdkVersion")))
+		c.info.MaxSDK = int32(parseManifestInt(attribute(start, "maxSdkVersion")))
 	case "application":
 		c.info.Label = attribute(start, "label")
 		c.info.Icon = attribute(start, "icon")
+		c.info.Debuggable = attribute(start, "debuggable") == "true"
+		c.info.TestOnly = attribute(start, "testOnly") == "true"
 	case "uses-permission", "uses-permission-sdk-23", "uses-permission-sdk-m":
 		if permission := attribute(start, "name"); permission != "" {
 			c.info.Permissions = append(c.info.Permissions, permission)
 		}
 	case "uses-feature":
-		if feature := attribute(start, "name"); feature != "" {
+		feature := attribute(start, "name")
+		switch {
+		case feature == "":
+		case attribute(start, "required") == "false":
+			c.info.OptionalFeatures = append(c.info.OptionalFeatures, feature)
+		default:
 			c.info.Features = append(c.info.Features, feature)
 		}
+	case "supports-screens":
+		for _, size := range []string{"small", "normal", "large", "xlarge"} {
+			if attribute(start, size+"Screens") == "false" {
+				c.info.UnsupportedScreens = append(c.info.UnsupportedScreens, size)
+			}
+		}
 	}
 
 	return nil
@@ -176,6 +309,8 @@ func (c *manifestCollector) Flush() error {
 	return nil
 }
 
+// parseManifest decodes the APK's AndroidManifest.xml. When decoding fails
+// part way, the fields read before the error are returned with it.
 func parseManifest(path string) (manifestInfo, error) {
 	collector := &manifestCollector{}
 	zipErr, _, manifestErr := apkparser.ParseApk(path, collector)
@@ -183,10 +318,10 @@ func parseManifest(path string) (manifestInfo, error) {
 		return manifestInfo{}, fmt.Errorf("open APK: %w", zipErr)
 	}
 	if manifestErr != nil {
-		return manifestInfo{}, fmt.Errorf("parse AndroidManifest.xml: %w", manifestErr)
+		return collector.info, fmt.Errorf("parse AndroidManifest.xml: %w", manifestErr)
 	}
 	if collector.info.PackageID == "" {
-		return manifestInfo{}, fmt.Errorf("parse AndroidManifest.xml: package is missing")
+		return collector.info, fmt.Errorf("parse AndroidManifest.xml: package is missing")
 	}
 	if isResourceReference(collector.info.Label) {
 		if label, err := resolveResourceString(path, collector.info.Label); err == nil && label != "" {
@@ -292,8 +427,8 @@ func parseManifestInt(value string) int64 {
 	return parsed
 }
 
-// hashFile calculates SHA256 hash of a file.
-func hashFile(path string) (string, error) {
+// HashFile calculates the hex SHA256 hash of a file.
+func HashFile(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return "", err
@@ -362,39 +497,172 @@ func isValidZipEntryPath(path string) bool {
 	return true
 }
 
-// verifyCertificate verifies the APK signature and returns the certificate fingerprint.
-func verifyCertificate(path string) (string, error) {
-	res, err := apkverifier.Verify(path, nil)
+// verifyCertificate verifies the APK signature and returns the signing certificate
+// lineage (see signerCertificates) and the signature schemes present.
+func verifyCertificate(path string) ([]*x509.Certificate, []string, error) {
+	res, err := verifyAPK(path)
 	if err != nil {
-		return "", fmt.Errorf("APK verification failed: %w", err)
+		return nil, nil, err
 	}
-
-	// Pick the best certificate (prefers v3 > v2 > v1)
-	_, cert := apkverifier.PickBestApkCert(res.SignerCerts)
-	if cert == nil {
-		return "", fmt.Errorf("failed to extract certificate: no valid certificate found")
+	certs, err := signerCertificates(res)
+	if err != nil {
+		return nil, nil, err
 	}
+	return certs, signatureSchemes(path, res), nil
+}
 
-	// Calculate SHA256 fingerprint of the certificate
+// certFingerprint returns the lowercase hex SHA-256 of a certificate.
+func certFingerprint(cert *x509.Certificate) string {
 	fingerprint := sha256.Sum256(cert.Raw)
-	return hex.EncodeToString(fingerprint[:]), nil
+	return hex.EncodeToString(fingerprint[:])
 }
 
+// signerCertificates returns the APK's signing certificates, oldest first. The
+// last one is the current signer, which Android checks updates against. With
+// v3 key rotation the list is the rotation lineage, ending in the newest
+// certificate; otherwise it holds the single best signer certificate (the
+// signing block's over v1).
+func signerCertificates(res apkverifier.Result) ([]*x509.Certificate, error) {
+	_, best := apkverifier.PickBestApkCert(res.SignerCerts)
+	if best == nil {
+		return nil, fmt.Errorf("failed to extract certificate: no valid certificate found")
+	}
+
+	block := res.SigningBlockResult
+	if block == nil {
+		return []*x509.Certificate{best}, nil
+	}
+	lineage := block.SigningLineage
+	if lineage == nil {
+		// v3.1 keeps the rotation in the v3 block it extends
+		if v3 := block.ExtraResults[3]; v3 != nil {
+			lineage = v3.SigningLineage
+		}
+	}
+	if lineage == nil || len(lineage.Nodes) < 2 {
+		return []*x509.Certificate{best}, nil
+	}
+
+	certs := make([]*x509.Certificate, 0, len(lineage.Nodes))
+	for _, node := range lineage.Nodes {
+		certs = append(certs, node.SigningCert)
+	}
+	// Only trust a lineage that ends in a certificate that actually signed the APK
+	current := certs[len(certs)-1]
+	for _, chain := range res.SignerCerts {
+		if len(chain) > 0 && chain[0].Equal(current) {
+			return certs, nil
+		}
+	}
+	return []*x509.Certificate{best}, nil
+}
+
+// signatureSchemes lists the signature schemes in an APK: v1 when META-INF holds a
+// JAR signature file, plus the scheme of the APK signing block (v2, v3 or v3.1).
+func signatureSchemes(path string, res apkverifier.Result) []string {
+	var schemes []string
+	if hasJARSignature(path) {
+		schemes = append(schemes, "v1")
+	}
+	if res.SigningBlockResult != nil {
+		switch res.SigningSchemeId {
+		case 2:
+			schemes = append(schemes, "v2")
+		case 3:
+			schemes = append(schemes, "v3")
+		case 31:
+			schemes = append(schemes, "v3", "v3.1")
+		}
+	}
+	if len(schemes) == 0 && res.SigningSchemeId == 1 {
+		schemes = append(schemes, "v1")
+	}
+	return schemes
+}
+
+// hasJARSignature reports whether the APK contains a v1 (JAR) signature file.
+func hasJARSignature(path string) bool {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if strings.HasPrefix(f.Name, "META-INF/") && strings.HasSuffix(strings.ToUpper(f.Name), ".SF") {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrV1OnlySignature is returned when an APK has no v2 or v3 signature.
+var ErrV1OnlySignature = errors.New("APK is signed only with the legacy v1 (JAR) scheme; Android 11+ refuses to install such APKs when they target API 30+")
+
 // ExtractCertificate extracts the signing certificate from an APK file.
-// Returns the x509 certificate used to sign the APK.
+// Returns the x509 certificate of the current signer, the same one
+// APKInfo.CertFingerprint identifies.
 func ExtractCertificate(path string) (*x509.Certificate, error) {
-	res, err := apkverifier.Verify(path, nil)
+	certs, err := ExtractCertificateLineage(path)
 	if err != nil {
-		return nil, fmt.Errorf("APK verification failed: %w", err)
+		return nil, err
 	}
+	return certs[len(certs)-1], nil
+}
 
-	// Pick the best certificate (prefers v3 > v2 > v1)
-	_, cert := apkverifier.PickBestApkCert(res.SignerCerts)
-	if cert == nil {
-		return nil, fmt.Errorf("failed to extract certificate: no valid certificate found")
+// ExtractCertificateLineage extracts the signing certificates from an APK file,
+// oldest first and ending with the current signer. It holds more than one
+// certificate only when the signing key was rotated (v3 lineage).
+func ExtractCertificateLineage(path string) ([]*x509.Certificate, error) {
+	res, err := verifyAPK(path)
+	if err != nil {
+		return nil, err
+	}
+	return signerCertificates(res)
+}
+
+// ErrTamperedAPK is returned when the APK's signed digests do not match its contents,
+// i.e. the file was modified after it was signed.
+var ErrTamperedAPK = errors.New("APK signature does not match contents (possible tampering)")
+
+// digestMismatchMarkers are apkverifier error fragments that mean file contents
+// differ from what was signed (v2/v3 signing block or v1 JAR manifest digests).
+var digestMismatchMarkers = []string{
+	"digest of contents did not verify", // v2/v3/v3.1 signing block
+	"No matching hash for",              // v1 entry digest
+	"Invalid hash of manifest entry",    // v1 signature file entry digest
+	"Invalid whole manifest hash",       // v1 signature file manifest digest
+}
+
+// verifyAPK runs full signature verification, reporting digest mismatches as ErrTamperedAPK.
+func verifyAPK(path string) (apkverifier.Result, error) {
+	res, err := apkverifier.Verify(path, nil)
+	if err == nil {
+		return res, nil
 	}
 
-	return cert, nil
+	if isDigestMismatch(err) {
+		return res, fmt.Errorf("%w: %v", ErrTamperedAPK, err)
+	}
+	if res.SigningBlockResult != nil {
+		for _, blockErr := range res.SigningBlockResult.Errors {
+			if isDigestMismatch(blockErr) {
+				return res, fmt.Errorf("%w: %v", ErrTamperedAPK, blockErr)
+			}
+		}
+	}
+	return res, fmt.Errorf("APK verification failed: %w", err)
+}
+
+// isDigestMismatch reports whether a verification error means contents changed after signing.
+func isDigestMismatch(err error) bool {
+	msg := err.Error()
+	for _, marker := range digestMismatchMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 // extractIcon extracts the app icon from the APK as PNG bytes.
@@ -458,6 +726,112 @@ func extractIcon(path, iconResource string) ([]byte, error) {
 	return extractIconManually(path)
 }
 
+// extractIconAtDensity extracts the launcher icon raster for a density qualifier
+// such as "xxxhdpi", or the largest raster across all densities for "max".
+// Adaptive (XML) icons are never rendered here; their legacy rasters are used.
+func extractIconAtDensity(path, iconResource, density string) ([]byte, error) {
+	var dpis []uint16
+	for _, d := range iconDensities {
+		if density == "max" || d.name == density {
+			dpis = append(dpis, d.dpi)
+		}
+	}
+	if len(dpis) == 0 {
+		return nil, fmt.Errorf("unknown icon density %q", density)
+	}
+
+	var best image.Image
+	consider := func(img image.Image) {
+		if img != nil && (best == nil || img.Bounds().Dx() > best.Bounds().Dx()) {
+			best = img
+		}
+	}
+
+	// The resource table resolves the icon for each density config, which also
+	// covers APKs whose resource paths were shortened by the build.
+	if pkg, err := apk.OpenFile(path); err == nil {
+		for _, dpi := range dpis {
+			if img, err := pkg.Icon(&androidbinary.ResTableConfig{Density: dpi}); err == nil {
+				consider(img)
+			}
+		}
+		pkg.Close()
+	}
+
+	// Density-qualified files named like the icon resource, e.g.
+	// res/mipmap-xxxhdpi-v4/ic_launcher.png for an anydpi-v26 adaptive icon
+	for _, img := range densityIconRasters(path, iconResource, density) {
+		consider(img)
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("no %s icon raster found", density)
+	}
+	return encodePNG(best)
+}
+
+// densityIconRasters decodes the PNG/WebP files in res/ named like the icon
+// resource whose directory has the density qualifier (any density for "max").
+func densityIconRasters(path, iconResource, density string) []image.Image {
+	if isResourceReference(iconResource) {
+		resolved, err := resolveResourceString(path, iconResource)
+		if err != nil {
+			return nil
+		}
+		iconResource = resolved
+	}
+	name := strings.TrimSuffix(filepath.Base(iconResource), filepath.Ext(iconResource))
+	if name == "" || name == "." {
+		return nil
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil
+	}
+	defer r.Close()
+
+	var images []image.Image
+	for _, f := range r.File {
+		if !isValidZipEntryPath(f.Name) || strings.HasSuffix(f.Name, ".9.png") {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(f.Name))
+		if (ext != ".png" && ext != ".webp") || strings.TrimSuffix(filepath.Base(f.Name), filepath.Ext(f.Name)) != name {
+			continue
+		}
+		if !hasDensityQualifier(f.Name, density) {
+			continue
+		}
+		data, err := readZipIcon(f)
+		if err != nil {
+			continue
+		}
+		if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+// hasDensityQualifier reports whether a zip entry such as
+// "res/mipmap-xxxhdpi-v4/ic_launcher.png" sits in a directory with the density
+// qualifier, or with any raster density qualifier when density is "max".
+func hasDensityQualifier(name, density string) bool {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 || parts[0] != "res" {
+		return false
+	}
+	for _, qualifier := range strings.Split(parts[1], "-")[1:] {
+		for _, d := range iconDensities {
+			if qualifier == d.name && (density == "max" || density == d.name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // extractIconFromResource handles adaptive icons whose manifest resource is an
 // XML document rather than a directly decodable bitmap.
 func extractIconFromResource(path, iconResource string) ([]byte, error) {
@@ -829,6 +1203,11 @@ func (a *APKInfo) IsArm64() bool {
 	return len(a.Architectures) == 0
 }
 
+// IsV1OnlySigned returns true if the APK has a v1 (JAR) signature but no v2/v3 signature.
+func (a *APKInfo) IsV1OnlySigned() bool {
+	return len(a.SignatureSchemes) == 1 && a.SignatureSchemes[0] == "v1"
+}
+
 // IsWatch returns true if the APK declares the standard Android watch device
 // feature used by Wear OS applications.
 func (a *APKInfo) IsWatch() bool {
@@ -840,6 +1219,53 @@ func (a *APKInfo) IsWatch() bool {
 	return false
 }
 
+// Device classes published for APKs built for one kind of device.
+const (
+	DeviceTV     = "tv"
+	DeviceTablet = "tablet"
+	DeviceWear   = "wear"
+)
+
+// DeviceClasses returns the device classes the APK is restricted to, or nil
+// for a general Android app: tv when it requires android.software.leanback,
+// wear when it requires the watch feature, and tablet when supports-screens
+// excludes small and normal screens.
+func (a *APKInfo) DeviceClasses() []string {
+	var classes []string
+	if a.hasFeature("android.software.leanback") || a.hasFeature("android.hardware.type.television") {
+		classes = append(classes, DeviceTV)
+	}
+	if slices.Contains(a.UnsupportedScreens, "small") && slices.Contains(a.UnsupportedScreens, "normal") {
+		classes = append(classes, DeviceTablet)
+	}
+	if a.IsWatch() {
+		classes = append(classes, DeviceWear)
+	}
+	return classes
+}
+
+// DeviceConflicts describes manifest requirements no device meets together,
+// so the APK cannot be installed anywhere.
+func (a *APKInfo) DeviceConflicts() []string {
+	var conflicts []string
+	classes := a.DeviceClasses()
+	if slices.Contains(classes, DeviceTV) && a.hasFeature("android.hardware.touchscreen") {
+		conflicts = append(conflicts, "the manifest requires both android.software.leanback and android.hardware.touchscreen, but TVs have no touchscreen")
+	}
+	if len(classes) > 1 {
+		conflicts = append(conflicts, fmt.Sprintf("the manifest restricts the APK to several device classes at once (%s)", strings.Join(classes, ", ")))
+	}
+	if a.MaxSDK > 0 && a.MaxSDK < a.MinSDK {
+		conflicts = append(conflicts, fmt.Sprintf("maxSdkVersion %d is below minSdkVersion %d", a.MaxSDK, a.MinSDK))
+	}
+	return conflicts
+}
+
+// hasFeature reports whether the manifest requires feature.
+func (a *APKInfo) hasFeature(feature string) bool {
+	return slices.Contains(a.Features, feature)
+}
+
 // HasGoogleDependency checks if the APK might have Google Play dependencies
 // based on the package ID patterns.
 func (a *APKInfo) HasGoogleDependency() bool {
@@ -856,8 +1282,21 @@ func (a *APKInfo) String() string {
 	fmt.Fprintf(&buf, "Version: %s (%d)\n", a.VersionName, a.VersionCode)
 	fmt.Fprintf(&buf, "Label: %s\n", a.Label)
 	fmt.Fprintf(&buf, "Min SDK: %d, Target SDK: %d\n", a.MinSDK, a.TargetSDK)
+	if a.MaxSDK > 0 {
+		fmt.Fprintf(&buf, "Max SDK: %d\n", a.MaxSDK)
+	}
+	if classes := a.DeviceClasses(); len(classes) > 0 {
+		fmt.Fprintf(&buf, "Devices: %s\n", strings.Join(classes, ", "))
+	}
 	fmt.Fprintf(&buf, "Architectures: %v\n", a.Architectures)
+	if len(a.Locales) > 0 {
+		fmt.Fprintf(&buf, "Locales: %s\n", strings.Join(a.Locales, ", "))
+	}
 	fmt.Fprintf(&buf, "Certificate: %s\n", a.CertFingerprint)
+	if len(a.CertLineage) > 1 {
+		fmt.Fprintf(&buf, "Certificate lineage: %s\n", strings.Join(a.CertLineage, " -> "))
+	}
+	fmt.Fprintf(&buf, "Signature schemes: %s\n", strings.Join(a.SignatureSchemes, ", "))
 	fmt.Fprintf(&buf, "Size: %d bytes\n", a.FileSize)
 	fmt.Fprintf(&buf, "SHA256: %s\n", a.SHA256)
 	if a.Icon != nil {
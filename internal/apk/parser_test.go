@@ -1,11 +1,26 @@
 package apk
 
 import (
+	"archive/zip"
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
 	"image"
+	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/avast/apkverifier"
+	"github.com/avast/apkverifier/signingblock"
 )
 
 func TestParse(t *testing.T) {
@@ -208,6 +223,64 @@ func TestIsWatch(t *testing.T) {
 	}
 }
 
+func TestManifestCollectorDeviceFields(t *testing.T) {
+	attr := func(name, value string) xml.Attr {
+		return xml.Attr{Name: xml.Name{Space: "http://schemas.android.com/apk/res/android", Local: name}, Value: value}
+	}
+	c := &manifestCollector{}
+	for _, start := range []xml.StartElement{
+		{Name: xml.Name{Local: "uses-sdk"}, Attr: []xml.Attr{attr("minSdkVersion", "21"), attr("maxSdkVersion", "28")}},
+		{Name: xml.Name{Local: "supports-screens"}, Attr: []xml.Attr{attr("smallScreens", "false"), attr("normalScreens", "false"), attr("largeScreens", "true")}},
+		{Name: xml.Name{Local: "uses-feature"}, Attr: []xml.Attr{attr("name", "android.software.leanback")}},
+		{Name: xml.Name{Local: "uses-feature"}, Attr: []xml.Attr{attr("name", "android.hardware.touchscreen"), attr("required", "false")}},
+	} {
+		if err := c.EncodeToken(start); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.info.MaxSDK != 28 {
+		t.Errorf("MaxSDK = %d, want 28", c.info.MaxSDK)
+	}
+	if want := []string{"small", "normal"}; !slices.Equal(c.info.UnsupportedScreens, want) {
+		t.Errorf("UnsupportedScreens = %v, want %v", c.info.UnsupportedScreens, want)
+	}
+	if want := []string{"android.software.leanback"}; !slices.Equal(c.info.Features, want) {
+		t.Errorf("Features = %v, want %v", c.info.Features, want)
+	}
+	if want := []string{"android.hardware.touchscreen"}; !slices.Equal(c.info.OptionalFeatures, want) {
+		t.Errorf("OptionalFeatures = %v, want %v", c.info.OptionalFeatures, want)
+	}
+}
+
+func TestDeviceClasses(t *testing.T) {
+	tests := []struct {
+		name      string
+		info      APKInfo
+		want      []string
+		conflicts int
+	}{
+		{"phone", APKInfo{Features: []string{"android.hardware.camera"}}, nil, 0},
+		{"optional leanback", APKInfo{OptionalFeatures: []string{"android.software.leanback"}}, nil, 0},
+		{"TV", APKInfo{Features: []string{"android.software.leanback"}}, []string{DeviceTV}, 0},
+		{"TV requiring a touchscreen", APKInfo{Features: []string{"android.software.leanback", "android.hardware.touchscreen"}}, []string{DeviceTV}, 1},
+		{"tablet", APKInfo{UnsupportedScreens: []string{"small", "normal"}}, []string{DeviceTablet}, 0},
+		{"no small screens", APKInfo{UnsupportedScreens: []string{"small"}}, nil, 0},
+		{"watch", APKInfo{Features: []string{"android.hardware.type.watch"}}, []string{DeviceWear}, 0},
+		{"max below min", APKInfo{MinSDK: 26, MaxSDK: 23}, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.info.DeviceClasses(); !slices.Equal(got, tt.want) {
+				t.Errorf("DeviceClasses() = %v, want %v", got, tt.want)
+			}
+			if got := tt.info.DeviceConflicts(); len(got) != tt.conflicts {
+				t.Errorf("DeviceConflicts() = %v, want %d conflict(s)", got, tt.conflicts)
+			}
+		})
+	}
+}
+
 func TestHashFile(t *testing.T) {
 	// Create a temporary file with known content
 	tmpDir := t.TempDir()
@@ -217,14 +290,328 @@ func TestHashFile(t *testing.T) {
 		t.Fatalf("failed to create test file: %v", err)
 	}
 
-	hash, err := hashFile(tmpFile)
+	hash, err := HashFile(tmpFile)
 	if err != nil {
-		t.Fatalf("hashFile() error: %v", err)
+		t.Fatalf("HashFile() error: %v", err)
 	}
 
 	// SHA256 of "hello world"
 	expected := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
 	if hash != expected {
-		t.Errorf("hashFile() = %q, want %q", hash, expected)
+		t.Errorf("HashFile() = %q, want %q", hash, expected)
+	}
+}
+
+func TestIsDigestMismatch(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("1 digest of contents did not verify."), true},
+		{errors.New("No matching hash for 'classes.dex'!"), true},
+		{errors.New("Invalid hash of manifest entry for res/a.xml"), true},
+		{errors.New("Invalid whole manifest hash!"), true},
+		{errors.New("No signers found"), false},
+		{errors.New("zip: not a valid zip file"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := isDigestMismatch(tt.err); got != tt.want {
+				t.Errorf("isDigestMismatch(%q) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBuildInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantRepo   string
+		wantCommit string
+	}{
+		{
+			name:       "built-from with commit",
+			data:       "Manifest-Version: 1.0\nBuilt-From: https://github.com/user/app@a1b2c3d4e5f6\n",
+			wantRepo:   "https://github.com/user/app",
+			wantCommit: "a1b2c3d4e5f6",
+		},
+		{
+			name:       "buildinfo json",
+			data:       `{"repository": "https://gitlab.com/user/app.git", "commit": "0123456789abcdef"}`,
+			wantRepo:   "https://gitlab.com/user/app",
+			wantCommit: "0123456789abcdef",
+		},
+		{
+			name:     "properties style",
+			data:     "repo=https://codeberg.org/user/app\nrevision=v1.2.3\n",
+			wantRepo: "https://codeberg.org/user/app",
+		},
+		{
+			name: "not a url",
+			data: "Built-By: Gradle 8.5\nRepository: internal\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := parseBuildInfo([]byte(tt.data))
+			if tt.wantRepo == "" {
+				if info != nil {
+					t.Fatalf("parseBuildInfo() = %+v, want nil", info)
+				}
+				return
+			}
+			if info == nil {
+				t.Fatal("parseBuildInfo() = nil")
+			}
+			if info.Repository != tt.wantRepo || info.Commit != tt.wantCommit {
+				t.Errorf("parseBuildInfo() = %+v, want repo %q commit %q", info, tt.wantRepo, tt.wantCommit)
+			}
+		})
+	}
+}
+
+// writeTestZip writes a zip archive with the given entries and returns its path.
+func writeTestZip(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.apk")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExtractBuildInfo(t *testing.T) {
+	path := writeTestZip(t, map[string]string{
+		"classes.dex":                          "dex",
+		"META-INF/org.fdroid.fdroid.buildinfo": "Built-From: https://github.com/user/app a1b2c3d\n",
+	})
+
+	info := extractBuildInfo(path)
+	if info == nil {
+		t.Fatal("extractBuildInfo() = nil")
+	}
+	if info.Repository != "https://github.com/user/app" || info.Commit != "a1b2c3d" {
+		t.Errorf("extractBuildInfo() = %+v", info)
+	}
+	if info.Entry != "META-INF/org.fdroid.fdroid.buildinfo" {
+		t.Errorf("Entry = %q", info.Entry)
+	}
+}
+
+func TestHasJARSignature(t *testing.T) {
+	signed := writeTestZip(t, map[string]string{"classes.dex": "dex", "META-INF/CERT.SF": "sf", "META-INF/CERT.RSA": "rsa"})
+	if !hasJARSignature(signed) {
+		t.Error("hasJARSignature() = false for APK with META-INF/CERT.SF")
+	}
+	unsigned := writeTestZip(t, map[string]string{"classes.dex": "dex", "META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n"})
+	if hasJARSignature(unsigned) {
+		t.Error("hasJARSignature() = true for APK without a .SF file")
+	}
+}
+
+func TestIsV1OnlySigned(t *testing.T) {
+	tests := []struct {
+		schemes []string
+		want    bool
+	}{
+		{[]string{"v1"}, true},
+		{[]string{"v1", "v2"}, false},
+		{[]string{"v3"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		info := &APKInfo{SignatureSchemes: tt.schemes}
+		if got := info.IsV1OnlySigned(); got != tt.want {
+			t.Errorf("IsV1OnlySigned(%v) = %v, want %v", tt.schemes, got, tt.want)
+		}
+	}
+}
+
+// testCertificate returns a self-signed certificate with the given common name.
+func testCertificate(t *testing.T, name string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestSignerCertificatesLineage(t *testing.T) {
+	oldCert, newCert := testCertificate(t, "old"), testCertificate(t, "new")
+	lineage := &signingblock.V3SigningLineage{Nodes: signingblock.V3LineageSigningCertificateNodeList{
+		{SigningCert: oldCert},
+		{SigningCert: newCert},
+	}}
+	names := func(certs []*x509.Certificate) []string {
+		var out []string
+		for _, c := range certs {
+			out = append(out, c.Subject.CommonName)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		res  apkverifier.Result
+		want []string
+	}{
+		{
+			name: "no rotation",
+			res:  apkverifier.Result{SignerCerts: [][]*x509.Certificate{{oldCert}}, SigningBlockResult: &signingblock.VerificationResult{}},
+			want: []string{"old"},
+		},
+		{
+			name: "v3 rotation",
+			res:  apkverifier.Result{SignerCerts: [][]*x509.Certificate{{newCert}}, SigningBlockResult: &signingblock.VerificationResult{SigningLineage: lineage}},
+			want: []string{"old", "new"},
+		},
+		{
+			name: "v3.1 rotation recorded in the v3 block",
+			res: apkverifier.Result{SignerCerts: [][]*x509.Certificate{{newCert}}, SigningBlockResult: &signingblock.VerificationResult{
+				ExtraResults: map[int]*signingblock.VerificationResult{3: {SigningLineage: lineage}},
+			}},
+			want: []string{"old", "new"},
+		},
+		{
+			name: "lineage not ending in the signer is ignored",
+			res:  apkverifier.Result{SignerCerts: [][]*x509.Certificate{{oldCert}}, SigningBlockResult: &signingblock.VerificationResult{SigningLineage: lineage}},
+			want: []string{"old"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certs, err := signerCertificates(tt.res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(certs); !slices.Equal(got, tt.want) {
+				t.Errorf("signerCertificates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := signerCertificates(apkverifier.Result{}); err == nil {
+		t.Error("signerCertificates() without signer certificates succeeded")
+	}
+}
+
+func TestExtractIconAtDensity(t *testing.T) {
+	square := func(size int) string {
+		data, err := encodePNG(image.NewRGBA(image.Rect(0, 0, size, size)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	path := writeTestZip(t, map[string]string{
+		"res/mipmap-anydpi-v26/ic_launcher.xml": "<adaptive-icon/>",
+		"res/mipmap-hdpi-v4/ic_launcher.png":    square(72),
+		"res/mipmap-xxhdpi-v4/ic_launcher.png":  square(144),
+		"res/mipmap-xxxhdpi-v4/ic_launcher.png": square(192),
+		"res/mipmap-xxxhdpi-v4/other.png":       square(512),
+	})
+	const resource = "res/mipmap-anydpi-v26/ic_launcher.xml"
+
+	tests := []struct {
+		density string
+		want    int
+	}{
+		{"hdpi", 72},
+		{"xxhdpi", 144},
+		{"max", 192},
+	}
+	for _, tt := range tests {
+		data, err := extractIconAtDensity(path, resource, tt.density)
+		if err != nil {
+			t.Fatalf("extractIconAtDensity(%q) error = %v", tt.density, err)
+		}
+		icon, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("decode %s icon: %v", tt.density, err)
+		}
+		if got := icon.Bounds().Dx(); got != tt.want {
+			t.Errorf("%s icon width = %d, want %d", tt.density, got, tt.want)
+		}
+	}
+
+	if _, err := extractIconAtDensity(path, resource, "mdpi"); err == nil {
+		t.Error("expected an error for a density the APK has no raster for")
+	}
+}
+
+// testResourceTable builds a minimal resources.arsc with one package holding
+// an empty type chunk per language/country pair.
+func testResourceTable(configs [][2][2]byte) []byte {
+	chunk := func(chunkType uint16, headerSize int, header, body []byte) []byte {
+		b := binary.LittleEndian.AppendUint16(nil, chunkType)
+		b = binary.LittleEndian.AppendUint16(b, uint16(headerSize))
+		b = binary.LittleEndian.AppendUint32(b, uint32(8+len(header)+len(body)))
+		return append(append(b, header...), body...)
+	}
+
+	var types []byte
+	for _, c := range configs {
+		header := make([]byte, 12+64) // id..entriesStart, then ResTable_config
+		binary.LittleEndian.PutUint32(header[12:], 64)
+		copy(header[20:], c[0][:])
+		copy(header[22:], c[1][:])
+		types = append(types, chunk(resTableTypeChunk, 8+len(header), header, nil)...)
+	}
+	pkg := chunk(resTablePackageChunk, 8+4, make([]byte, 4), types)
+	return chunk(resTableChunk, 12, make([]byte, 4), pkg)
+}
+
+func TestExtractLocales(t *testing.T) {
+	table := testResourceTable([][2][2]byte{
+		{},                         // default config
+		{{'d', 'e'}},               // de
+		{{'p', 't'}, {'B', 'R'}},   // pt-BR
+		{{'d', 'e'}},               // de again, for another type
+		{{'e', 'n'}, {'X', 'A'}},   // pseudo-locale
+		{{0xad, 0x05}},             // fil, packed
+		{{'e', 's'}, {0xa4, 0x24}}, // es-419, packed region
+	})
+	path := writeTestZip(t, map[string]string{"resources.arsc": string(table)})
+
+	want := []string{"de", "es-419", "fil", "pt-BR"}
+	if got := extractLocales(path); !slices.Equal(got, want) {
+		t.Errorf("extractLocales() = %v, want %v", got, want)
+	}
+
+	if got := extractLocales(writeTestZip(t, map[string]string{"resources.arsc": "garbage"})); got != nil {
+		t.Errorf("extractLocales() on a corrupt table = %v, want nil", got)
 	}
 }
@@ -0,0 +1,154 @@
+// Package badge builds "published on Zapstore" badges for an app's website: a
+// shields.io endpoint JSON document and a static SVG rendering of it.
+package badge
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"text/template"
+	"unicode/utf8"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/nostr"
+)
+
+//go:embed templates/badge.svg
+var svgTemplateText string
+
+var svgTemplate = template.Must(template.New("badge").Funcs(template.FuncMap{
+	"esc": html.EscapeString,
+}).Parse(svgTemplateText))
+
+// Badge colors: named shields.io colors and the hex values the SVG uses for them.
+const (
+	ColorVerified   = "brightgreen"
+	ColorUnverified = "orange"
+)
+
+var colorHex = map[string]string{
+	ColorVerified:   "#4c1",
+	ColorUnverified: "#fe7d37",
+}
+
+// Endpoint is a shields.io endpoint badge document. shields.io reads the
+// first four fields; the rest describe the release for other consumers, such
+// as a download page rendering its own badge.
+type Endpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+
+	Package     string `json:"package"`
+	Version     string `json:"version"`
+	Channel     string `json:"channel"`
+	Verified    bool   `json:"verified"` // Release and assets carry valid signatures
+	NAddr       string `json:"naddr"`    // NIP-19 address of the app event
+	URL         string `json:"url"`      // App page on zapstore.dev
+	PublishedAt int64  `json:"published_at,omitempty"`
+}
+
+// New describes the published release of packageID. The release counts as
+// verified when it and at least one asset it references carry valid signatures.
+// relayHints go into the naddr.
+func New(packageID, channel, label string, release *nostr.PublishedRelease, relayHints []string) (*Endpoint, error) {
+	version := tagValue(release.Event, "version")
+	naddr, err := nip19.EncodeEntity(release.Event.PubKey, nostr.KindAppMetadata, packageID, relayHints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode naddr: %w", err)
+	}
+
+	e := &Endpoint{
+		SchemaVersion: 1,
+		Label:         label,
+		Message:       version,
+		Color:         ColorUnverified,
+		Package:       packageID,
+		Version:       version,
+		Channel:       channel,
+		Verified:      verified(release),
+		NAddr:         naddr,
+		URL:           "https://zapstore.dev/apps/" + packageID,
+	}
+	if e.Verified {
+		e.Color = ColorVerified
+	}
+	if ts, err := strconv.ParseInt(tagValue(release.Event, "published_at"), 10, 64); err == nil {
+		e.PublishedAt = ts
+	} else {
+		e.PublishedAt = int64(release.Event.CreatedAt)
+	}
+	return e, nil
+}
+
+// verified reports whether the release and all of its found assets have valid
+// signatures, with at least one asset found.
+func verified(release *nostr.PublishedRelease) bool {
+	if ok, err := release.Event.CheckSignature(); !ok || err != nil {
+		return false
+	}
+	if len(release.Assets) == 0 {
+		return false
+	}
+	for _, asset := range release.Assets {
+		if ok, err := asset.CheckSignature(); !ok || err != nil || asset.PubKey != release.Event.PubKey {
+			return false
+		}
+	}
+	return true
+}
+
+// JSON returns the endpoint document, indented, with a trailing newline.
+func (e *Endpoint) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// SVG renders the badge in the shields.io flat style.
+func (e *Endpoint) SVG() ([]byte, error) {
+	labelWidth, messageWidth := textWidth(e.Label), textWidth(e.Message)
+	fill, ok := colorHex[e.Color]
+	if !ok {
+		fill = e.Color
+	}
+	var buf bytes.Buffer
+	err := svgTemplate.Execute(&buf, map[string]any{
+		"Label":        e.Label,
+		"Message":      e.Message,
+		"URL":          e.URL,
+		"Fill":         fill,
+		"Width":        labelWidth + messageWidth,
+		"LabelWidth":   labelWidth,
+		"MessageWidth": messageWidth,
+		"LabelX":       labelWidth / 2,
+		"MessageX":     labelWidth + messageWidth/2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render badge: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// textWidth estimates the width of a badge half: 11px Verdana averages about
+// 7px per character, plus 5px padding on each side.
+func textWidth(s string) int {
+	return 7*utf8.RuneCountInString(s) + 10
+}
+
+// tagValue returns the value of the first tag named key, or "".
+func tagValue(event *gonostr.Event, key string) string {
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == key {
+			return tag[1]
+		}
+	}
+	return ""
+}
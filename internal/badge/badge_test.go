@@ -0,0 +1,79 @@
+package badge
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/nostr/nostrtest"
+)
+
+func TestNew(t *testing.T) {
+	sk := gonostr.GeneratePrivateKey()
+	release := &nostr.PublishedRelease{
+		Event:  nostrtest.SignedEvent(t, sk, nostr.KindRelease, gonostr.Tags{{"d", "com.example.app@1.2.3"}, {"version", "1.2.3"}, {"published_at", "1690000000"}}),
+		Assets: []*gonostr.Event{nostrtest.SignedEvent(t, sk, nostr.KindSoftwareAsset, gonostr.Tags{{"i", "com.example.app"}})},
+	}
+
+	e, err := New("com.example.app", "main", "zapstore", release, []string{"wss://relay.zapstore.dev"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if !e.Verified || e.Color != ColorVerified || e.Message != "1.2.3" || e.PublishedAt != 1690000000 {
+		t.Errorf("New() = %+v, want a verified 1.2.3 badge", e)
+	}
+	prefix, data, err := nip19.Decode(e.NAddr)
+	if err != nil || prefix != "naddr" {
+		t.Fatalf("naddr %q: %v", e.NAddr, err)
+	}
+	if ptr := data.(gonostr.EntityPointer); ptr.Kind != nostr.KindAppMetadata || ptr.Identifier != "com.example.app" || ptr.PublicKey != release.Event.PubKey {
+		t.Errorf("naddr points at %+v", ptr)
+	}
+
+	doc, err := e.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(doc, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"schemaVersion": 1.0, "label": "zapstore", "message": "1.2.3", "color": "brightgreen"} {
+		if fields[key] != want {
+			t.Errorf("JSON %s = %v, want %v", key, fields[key], want)
+		}
+	}
+
+	// A tampered asset, or none, is not verified
+	release.Assets[0].Content = "changed"
+	if e, _ := New("com.example.app", "main", "zapstore", release, nil); e.Verified || e.Color != ColorUnverified {
+		t.Errorf("tampered asset: Verified = %v, Color = %s", e.Verified, e.Color)
+	}
+	release.Assets = nil
+	if e, _ := New("com.example.app", "main", "zapstore", release, nil); e.Verified {
+		t.Error("release without assets is verified")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	e := &Endpoint{Label: "zapstore", Message: "1.2.3 <beta>", Color: ColorVerified, URL: "https://zapstore.dev/apps/com.example.app"}
+	svg, err := e.SVG()
+	if err != nil {
+		t.Fatalf("SVG() error: %v", err)
+	}
+	out := string(svg)
+	for _, want := range []string{
+		`width="160"`, // (7*8+10) + (7*12+10)
+		`fill="#4c1"`,
+		`<text x="33" y="14">zapstore</text>`,
+		`1.2.3 &lt;beta&gt;`,
+		`href="https://zapstore.dev/apps/com.example.app"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("SVG is missing %s:\n%s", want, out)
+		}
+	}
+}
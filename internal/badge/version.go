@@ -0,0 +1,123 @@
+package badge
+
+import (
+	"cmp"
+	"strings"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+)
+
+// CompareVersions compares two version names in semver order and returns -1,
+// 0 or 1. Dot-separated numbers compare numerically (1.10.0 > 1.9.2), a
+// pre-release sorts before its release (1.2.0-beta.1 < 1.2.0), and a leading v
+// and build metadata (+build) are ignored. Other names compare segment by
+// segment, numbers before text, so date versions like 2024.05.01 order too.
+func CompareVersions(a, b string) int {
+	aMain, aPre := splitVersion(a)
+	bMain, bPre := splitVersion(b)
+
+	// Missing components count as 0: 1.2 == 1.2.0
+	aParts, bParts := strings.Split(aMain, "."), strings.Split(bMain, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		x, y := "0", "0"
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if c := compareIdentifiers(x, y); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	// A shorter pre-release sorts first when it is a prefix of the other
+	aParts, bParts = strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < min(len(aParts), len(bParts)); i++ {
+		if c := compareIdentifiers(aParts[i], bParts[i]); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(aParts), len(bParts))
+}
+
+// splitVersion strips a leading v and build metadata and splits off the pre-release.
+func splitVersion(v string) (string, string) {
+	v = strings.TrimSpace(v)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V")
+	v, _, _ = strings.Cut(v, "+")
+	main, pre, _ := strings.Cut(v, "-")
+	return main, pre
+}
+
+// compareIdentifiers compares two version components: numbers by value and
+// before any text, text lexically.
+func compareIdentifiers(a, b string) int {
+	aNum, bNum := isNumeric(a), isNumeric(b)
+	switch {
+	case aNum && bNum:
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if c := cmp.Compare(len(a), len(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// isNumeric reports whether s is a non-empty run of ASCII digits.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Latest returns the release on channel with the highest version, the newer
+// event winning a tie, or nil if there is none. Releases without a c tag are
+// on the main channel.
+func Latest(releases []*gonostr.Event, channel string) *gonostr.Event {
+	if channel == "" {
+		channel = "main"
+	}
+	var latest *gonostr.Event
+	for _, release := range releases {
+		if cmp.Or(tagValue(release, "c"), "main") != channel {
+			continue
+		}
+		version := tagValue(release, "version")
+		if version == "" {
+			continue
+		}
+		if latest == nil {
+			latest = release
+			continue
+		}
+		switch CompareVersions(version, tagValue(latest, "version")) {
+		case 1:
+			latest = release
+		case 0:
+			if release.CreatedAt > latest.CreatedAt {
+				latest = release
+			}
+		}
+	}
+	return latest
+}
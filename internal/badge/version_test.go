@@ -0,0 +1,59 @@
+package badge
+
+import (
+	"testing"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.2", 1},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0-beta.1", "1.2.0", -1},
+		{"1.2.0-beta.2", "1.2.0-beta.10", -1},
+		{"1.2.0-alpha", "1.2.0-alpha.1", -1},
+		{"1.2.0-rc.1", "1.2.0-beta.5", 1},
+		{"1.2.3+build.7", "1.2.3", 0},
+		{"2024.05.10", "2024.5.9", 1},
+		{"10", "9", 1},
+		{"1.0.0", "1.0.0a", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestLatest(t *testing.T) {
+	release := func(version, channel string, createdAt gonostr.Timestamp) *gonostr.Event {
+		tags := gonostr.Tags{{"version", version}}
+		if channel != "" {
+			tags = append(tags, gonostr.Tag{"c", channel})
+		}
+		return &gonostr.Event{Kind: 30063, Tags: tags, CreatedAt: createdAt}
+	}
+	newest := release("1.9.0", "main", 300) // published last, as a backport
+	highest := release("1.10.0", "", 200)
+	republished := release("1.10.0", "main", 250)
+	beta := release("2.0.0-beta.1", "beta", 400)
+	releases := []*gonostr.Event{newest, highest, republished, beta, release("", "main", 500)}
+
+	if got := Latest(releases, ""); got != republished {
+		t.Errorf("Latest(main) = %v, want the newer 1.10.0 event", got.Tags)
+	}
+	if got := Latest(releases, "beta"); got != beta {
+		t.Errorf("Latest(beta) = %v, want 2.0.0-beta.1", got)
+	}
+	if got := Latest(releases, "nightly"); got != nil {
+		t.Errorf("Latest(nightly) = %v, want nil", got)
+	}
+}
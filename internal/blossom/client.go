@@ -16,13 +16,18 @@ import (
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/netpolicy"
 	nostrpkg "github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ratelimit"
 )
 
 const (
 	// DefaultServer is the default Blossom server URL.
 	DefaultServer = "https://cdn.zapstore.dev"
 
+	// DevServer is the local Blossom server `zsp publish --dev` uploads to.
+	DevServer = "http://localhost:3000"
+
 	// AuthExpiration is how long the auth token is valid.
 	AuthExpiration = 5 * time.Minute
 )
@@ -45,17 +50,18 @@ func NewClient(serverURL string) *Client {
 }
 
 // newSecureHTTPClient creates an HTTP client with security best practices.
+// Requests are checked against the network allowlist.
 func newSecureHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{
 		Timeout: timeout,
-		Transport: &http.Transport{
+		Transport: netpolicy.WrapTransport(&http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
 			},
 			MaxIdleConns:        100,
 			MaxIdleConnsPerHost: 10,
 			IdleConnTimeout:     90 * time.Second,
-		},
+		}),
 	}
 }
 
@@ -151,7 +157,8 @@ func (c *Client) Upload(ctx context.Context, filePath string, sha256 string, sig
 	return c.UploadWithAuth(ctx, filePath, sha256, authEvent, onProgress)
 }
 
-// UploadWithAuth uploads a file using a pre-signed auth event.
+// UploadWithAuth uploads a file using a pre-signed auth event. When the server
+// already has the blob, nothing is sent and the result reports Existed.
 func (c *Client) UploadWithAuth(ctx context.Context, filePath string, sha256 string, authEvent *nostr.Event, onProgress ProgressFunc) (*UploadResult, error) {
 	// Check if already exists
 	exists, err := c.Exists(ctx, sha256)
@@ -186,11 +193,11 @@ func (c *Client) UploadWithAuth(ctx context.Context, filePath string, sha256 str
 	}
 	authHeader := "Nostr " + base64.StdEncoding.EncodeToString(authJSON)
 
-	// Create upload request
-	var reader io.Reader = f
+	// Create upload request; reads are throttled by --limit-rate before progress sees them
+	reader := ratelimit.Reader(ctx, ratelimit.Upload, f)
 	if onProgress != nil {
 		reader = &progressReader{
-			reader:     f,
+			reader:     reader,
 			total:      fi.Size(),
 			onProgress: onProgress,
 		}
@@ -216,7 +223,7 @@ func (c *Client) UploadWithAuth(ctx context.Context, filePath string, sha256 str
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, uploadError(resp)
+		return nil, requestError("upload", resp)
 	}
 
 	// Parse response
@@ -292,7 +299,7 @@ func (c *Client) uploadBytesWithAuth(ctx context.Context, data []byte, sha256 st
 
 	// Create upload request
 	url := fmt.Sprintf("%s/upload", c.serverURL)
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, ratelimit.Reader(ctx, ratelimit.Upload, bytes.NewReader(data)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -313,7 +320,7 @@ func (c *Client) uploadBytesWithAuth(ctx context.Context, data []byte, sha256 st
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, uploadError(resp)
+		return nil, requestError("upload", resp)
 	}
 
 	return &UploadResult{
@@ -324,14 +331,105 @@ func (c *Client) uploadBytesWithAuth(ctx context.Context, data []byte, sha256 st
 	}, nil
 }
 
+// BlobDescriptor describes a stored blob, as returned by the BUD-02 list endpoint.
+type BlobDescriptor struct {
+	URL      string `json:"url"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Type     string `json:"type,omitempty"`
+	Uploaded int64  `json:"uploaded"` // unix seconds
+}
+
+// List returns the blobs the signer's pubkey has uploaded (BUD-02 GET /list/<pubkey>).
+// The request carries a signed list authorization for servers that require one.
+func (c *Client) List(ctx context.Context, signer nostrpkg.Signer) ([]BlobDescriptor, error) {
+	authEvent := nostrpkg.BuildBlossomListAuthEvent(signer.PublicKey(), time.Now().Add(AuthExpiration))
+	signCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := signer.Sign(signCtx, authEvent); err != nil {
+		return nil, fmt.Errorf("failed to sign auth event: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/list/%s", c.serverURL, signer.PublicKey())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := setAuthHeader(req, authEvent); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, requestError("list", resp)
+	}
+
+	var blobs []BlobDescriptor
+	if err := json.NewDecoder(resp.Body).Decode(&blobs); err != nil {
+		return nil, fmt.Errorf("invalid list response: %w", err)
+	}
+	return blobs, nil
+}
+
+// SignDeleteAuth signs a single BUD-01 delete authorization covering all hashes,
+// to be passed to Delete for each of them.
+func (c *Client) SignDeleteAuth(ctx context.Context, hashes []string, signer nostrpkg.Signer) (*nostr.Event, error) {
+	authEvent := nostrpkg.BuildBlossomDeleteAuthEvent(hashes, signer.PublicKey(), time.Now().Add(AuthExpiration))
+	signCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := signer.Sign(signCtx, authEvent); err != nil {
+		return nil, fmt.Errorf("failed to sign auth event: %w", err)
+	}
+	return authEvent, nil
+}
+
+// Delete removes a blob from the server (BUD-01 DELETE /<sha256>). The auth event
+// must carry an x tag for the hash; see SignDeleteAuth.
+func (c *Client) Delete(ctx context.Context, sha256 string, authEvent *nostr.Event) error {
+	url := fmt.Sprintf("%s/%s", c.serverURL, sha256)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := setAuthHeader(req, authEvent); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return requestError("delete", resp)
+	}
+	return nil
+}
+
+// setAuthHeader sets the BUD-01 "Nostr <base64 event>" Authorization header.
+func setAuthHeader(req *http.Request, authEvent *nostr.Event) error {
+	authJSON, err := json.Marshal(authEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal auth event: %w", err)
+	}
+	req.Header.Set("Authorization", "Nostr "+base64.StdEncoding.EncodeToString(authJSON))
+	return nil
+}
+
 // ServerURL returns the configured server URL.
 func (c *Client) ServerURL() string {
 	return c.serverURL
 }
 
-// uploadError builds an error from a non-2xx upload response, preferring the
-// X-Reason header and falling back to the response body.
-func uploadError(resp *http.Response) error {
+// requestError builds an error from a non-2xx response to the named operation,
+// preferring the X-Reason header and falling back to the response body.
+func requestError(op string, resp *http.Response) error {
 	reason := strings.TrimSpace(resp.Header.Get("X-Reason"))
 	if reason == "" {
 		if body, err := io.ReadAll(io.LimitReader(resp.Body, 512)); err == nil {
@@ -339,9 +437,9 @@ func uploadError(resp *http.Response) error {
 		}
 	}
 	if reason == "" {
-		return fmt.Errorf("upload failed with status %d", resp.StatusCode)
+		return fmt.Errorf("%s failed with status %d", op, resp.StatusCode)
 	}
-	return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, reason)
+	return fmt.Errorf("%s failed with status %d: %s", op, resp.StatusCode, reason)
 }
 
 // progressReader wraps a reader to track progress.
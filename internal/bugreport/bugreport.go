@@ -0,0 +1,196 @@
+// Package bugreport assembles a redacted diagnostic bundle after a failed run
+// (--bug-report) that users can attach to an issue. Nothing is ever sent over
+// the network; the bundle is only written to a local file.
+package bugreport
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/zapstore/zsp/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// secretEnvVars hold credentials; their values are removed wherever they appear.
+var secretEnvVars = []string{"SIGN_WITH", "KEYSTORE_PASSWORD", "GITHUB_TOKEN", "GITLAB_TOKEN", "GITEA_TOKEN"}
+
+// Report is the diagnostic information collected about a failed run.
+type Report struct {
+	Version    string
+	Args       []string // command line, without the program name
+	Stage      string   // step that was running when the error occurred
+	Err        error
+	Config     *config.Config
+	RelayURLs  []string
+	BlossomURL string
+	Warnings   []string
+	Log        []string // recent output lines, oldest first
+	Time       time.Time
+}
+
+// Render formats the report as plain text with every section redacted.
+func (r *Report) Render() string {
+	var b strings.Builder
+	section := func(title string) {
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+	}
+
+	b.WriteString("# zsp bug report\n\n")
+	fmt.Fprintf(&b, "Generated: %s\n", r.Time.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version:   %s\n", r.Version)
+	fmt.Fprintf(&b, "Go:        %s\n", runtime.Version())
+	fmt.Fprintf(&b, "Platform:  %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "Command:   zsp %s\n", strings.Join(r.Args, " "))
+	if r.Stage != "" {
+		fmt.Fprintf(&b, "Stage:     %s\n", r.Stage)
+	}
+
+	section("Error")
+	for i, msg := range ErrorChain(r.Err) {
+		fmt.Fprintf(&b, "%s%s\n", strings.Repeat("  ", i), msg)
+	}
+
+	section("Endpoints")
+	for _, url := range r.RelayURLs {
+		fmt.Fprintf(&b, "relay:   %s\n", url)
+	}
+	if r.BlossomURL != "" {
+		fmt.Fprintf(&b, "blossom: %s\n", r.BlossomURL)
+	}
+
+	section("Environment")
+	for _, name := range secretEnvVars {
+		state := "unset"
+		if value := config.GetEnv(name); value != "" {
+			state = "set (" + credentialKind(value) + ")"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, state)
+	}
+
+	if r.Config != nil {
+		section("Config")
+		if data, err := yaml.Marshal(r.Config); err == nil {
+			b.Write(data)
+		} else {
+			fmt.Fprintf(&b, "(could not encode config: %v)\n", err)
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		section("Warnings")
+		for _, w := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+	}
+
+	if len(r.Log) > 0 {
+		section(fmt.Sprintf("Log (last %d lines)", len(r.Log)))
+		for _, line := range r.Log {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return Redact(b.String())
+}
+
+// Write renders the report to zsp-bug-report-<time>.txt in dir and returns its path.
+// The file is readable by the owner only.
+func Write(dir string, r *Report) (string, error) {
+	path := filepath.Join(dir, "zsp-bug-report-"+r.Time.UTC().Format("20060102-150405")+".txt")
+	if err := os.WriteFile(path, []byte(r.Render()), 0600); err != nil {
+		return "", fmt.Errorf("failed to write bug report: %w", err)
+	}
+	return path, nil
+}
+
+// ErrorChain lists err and each error it wraps, outermost first. Wrapped
+// messages repeat their causes, so each entry is trimmed to what it adds.
+func ErrorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		msg := err.Error()
+		next := errors.Unwrap(err)
+		if joined, ok := err.(interface{ Unwrap() []error }); ok && next == nil {
+			for _, e := range joined.Unwrap() {
+				chain = append(chain, ErrorChain(e)...)
+			}
+			if len(chain) == 0 {
+				chain = append(chain, msg)
+			}
+			return chain
+		}
+		if next != nil {
+			msg = strings.TrimSuffix(strings.TrimSuffix(msg, next.Error()), ": ")
+		}
+		if msg != "" {
+			// Name the concrete type unless it is a plain errors.New/fmt.Errorf error
+			if t := fmt.Sprintf("%T", err); t != "*errors.errorString" && t != "*fmt.wrapError" {
+				msg += " (" + t + ")"
+			}
+			chain = append(chain, msg)
+		}
+		err = next
+	}
+	return chain
+}
+
+// credentialKind names the kind of SIGN_WITH-style value without revealing it.
+func credentialKind(value string) string {
+	switch {
+	case strings.HasPrefix(value, "nsec1"):
+		return "nsec"
+	case strings.HasPrefix(value, "npub1"):
+		return "npub"
+	case strings.HasPrefix(value, "bunker://"):
+		return "bunker"
+	case strings.HasPrefix(value, "keychain:"):
+		return "keychain"
+	case value == "browser":
+		return "browser"
+	case hexKeyPattern.MatchString(value):
+		return "hex key"
+	}
+	return "redacted"
+}
+
+var (
+	hexKeyPattern    = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+	nsecPattern      = regexp.MustCompile(`nsec1[02-9ac-hj-np-z]+`)
+	ncryptsecPattern = regexp.MustCompile(`ncryptsec1[02-9ac-hj-np-z]+`)
+	tokenPattern     = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,}|glpat-[A-Za-z0-9_\-]{20,})`)
+	authPattern      = regexp.MustCompile(`(?i)\b(authorization:\s*)[^\r\n]+`)
+	bearerPattern    = regexp.MustCompile(`(?i)\b(bearer\s+|token\s+)[A-Za-z0-9._~+/=\-]{8,}`)
+	secretParam      = regexp.MustCompile(`(?i)([?&](?:secret|token|access_token|password)=)[^&\s"']+`)
+	userinfoPattern  = regexp.MustCompile(`(://)[^/@\s:]+:[^/@\s]+@`)
+	homePathPattern  = regexp.MustCompile(`(/home/|/Users/|[A-Za-z]:\\Users\\)[^/\\\s"':]+`)
+)
+
+// Redact removes credentials and usernames from s: the values of the secret
+// environment variables, nsec and ncryptsec keys, GitHub/GitLab tokens,
+// authorization headers, secret URL parameters and URL passwords, and the user
+// name in home directory paths.
+func Redact(s string) string {
+	for _, name := range secretEnvVars {
+		if value := config.GetEnv(name); len(value) >= 4 && value != "browser" {
+			s = strings.ReplaceAll(s, value, "[REDACTED "+name+"]")
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+	s = nsecPattern.ReplaceAllString(s, "nsec1[REDACTED]")
+	s = ncryptsecPattern.ReplaceAllString(s, "ncryptsec1[REDACTED]")
+	s = tokenPattern.ReplaceAllString(s, "[REDACTED TOKEN]")
+	s = authPattern.ReplaceAllString(s, "${1}[REDACTED]")
+	s = bearerPattern.ReplaceAllString(s, "${1}[REDACTED]")
+	s = secretParam.ReplaceAllString(s, "${1}[REDACTED]")
+	s = userinfoPattern.ReplaceAllString(s, "${1}[REDACTED]@")
+	s = homePathPattern.ReplaceAllString(s, "${1}[USER]")
+	return s
+}
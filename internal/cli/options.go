@@ -4,6 +4,8 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -18,6 +20,13 @@ const (
 	CommandPublish  Command = "publish"
 	CommandIdentity Command = "identity"
 	CommandUtils    Command = "utils"
+	CommandConfig   Command = "config"
+	CommandHistory  Command = "history"
+	CommandAPK      Command = "apk"
+	CommandBlossom  Command = "blossom"
+	CommandRelay    Command = "relay"
+	CommandKeys     Command = "keys"
+	CommandExport   Command = "export"
 )
 
 // GlobalOptions holds flags available at root level and shared across subcommands.
@@ -27,6 +36,7 @@ type GlobalOptions struct {
 	Version bool
 	Help    bool
 	JSON    bool // Machine-readable output: errors as {"error":"..."} to stderr, events/results as JSONL to stdout
+	Trace   bool // Log every outbound HTTP request and response to stderr
 }
 
 // PublishOptions holds flags specific to the publish subcommand.
@@ -36,24 +46,76 @@ type PublishOptions struct {
 	ReleaseSource string
 	Metadata      []string
 	Match         string
+	MatchLabel    string
+	Variant       string   // Publish only the APK matching this variant's pattern from the config's variants: map
+	Only          []string // App names from the config's apps: list to publish (--only, repeatable or comma-separated)
+	BaseDir       string   // Directory that relative config paths resolve against, overriding the config file's directory
 
 	// Release-specific options (CLI-only, not in config)
-	Commit  string // Git commit hash for reproducible builds
-	Channel string // Release channel: main (default), beta, nightly, dev
+	Commit      string   // Git commit hash for reproducible builds
+	Channel     string   // Release channel: main (default), beta, nightly, dev
+	Platforms   []string // Platform identifiers overriding the detected f tags (--platform, repeatable)
+	PublishedAt string   // Override for the release published_at tag (RFC3339, YYYY-MM-DD, or unix seconds)
 
 	// Behavior flags
 	Offline                bool // Sign events without uploading/publishing (outputs to stdout)
+	SignOnly               bool // Sign events for blobs already on the Blossom server and output them, contacting no relays
 	Quiet                  bool // No prompts, no spinners, auto-yes to all confirmations
 	SkipPreview            bool
+	PreviewURLOnly         bool // Serve the preview and print its URL without asking or opening a browser (SSH tunnels)
+	NoPreviewImages        bool // Preview without fetching screenshots; images are downloaded after the preview
 	OverwriteRelease       bool
+	OnlyNewAssets          bool   // Add the APK to the published release for its version, keeping the assets it references
+	IgnoreReleaseAge       bool   // Publish releases younger than min_release_age
+	OverwriteApp           string // kind 32267 update strategy: merge (default) or replace
+	Relays                 string // Relay discovery mode: "" (RELAY_URLS/community), nip65, or nip65-only
+	MinRelaySuccess        int    // Relays that must accept each event for success (0 = all)
+	MetricsOut             string // Path of a Prometheus textfile-collector metrics file to update at exit
+	BugReport              bool   // Write a redacted diagnostic bundle to the current directory if publishing fails
+	LimitRate              string // Bandwidth limit shared by APK downloads and Blossom uploads, e.g. 2M (default: ZSP_LIMIT_RATE)
+	MaxDownloadRate        string // Bandwidth limit for APK downloads only, overriding LimitRate for them
+	MaxUploadRate          string // Bandwidth limit for Blossom uploads only, overriding LimitRate for them
+	ManifestJSON           string // Write the upload manifest as JSON to this file ("-" for stdout) with --offline or an npub signer
+	BlossomAuthOut         string // Write unsigned Blossom upload auth events as JSONL to this file ("-" for stdout) with an npub signer
+	ProgressJSON           bool   // Emit newline-delimited JSON progress events instead of the human UI
+	ProgressFD             string // File descriptor number or named pipe for --progress-json events (default: stderr)
+	AnswersFile            string // JSON file answering --progress-json prompts by ID; unanswered prompts are read from stdin
+	IconDensity            string // APK icon raster density to extract: ldpi..xxxhdpi, or max ("" auto-picks)
+	ChangelogFrom          string // Generate release notes from: git (commits since the previous tag)
+	Delegation             string // NIP-26 delegation tag (JSON) added to every event, publishing on the delegator's behalf
+	AddToSet               string // naddr of a NIP-51 app curation set to add the app to after publishing
+	EphemeralSecret        string // nsec generated for --ephemeral-key (set at startup, not a flag)
 	IncludePreReleases     bool
+	PickRelease            bool // Choose the release to publish from the recent ones (forge sources, interactive)
 	SkipMetadata           bool
 	AppCreatedAtRelease    bool // Use release timestamp for kind 32267 created_at
 	SkipAppEvent           bool // Publish only release events (kind 30063/3063), skip kind 32267
 	SkipCertificateLinking bool // Skip certificate-to-identity linking check
 	NoCompress             bool // Preserve original icon and screenshot bytes
+	NoBlurhash             bool // Omit the icon blurhash (imeta tag) from the app event
+	StrictImages           bool // Fail instead of dropping screenshots that are unreachable or not images
+	StrictVersioning       bool // Fail instead of warning when the versionCode does not exceed every published channel's
+	StrictRedirects        bool // Fail instead of warning when the asset URL redirects to a different host than at the last publish
+	AllowDifferentAuthor   bool // Publish even though relays list the app under a different pubkey than the signer's
+	NoRetractOnPartial     bool // Keep the events the required relay stored when it rejected a required one
+	ConfirmCertChange      bool // Publish although the APK is signed by a different certificate than the last published asset
+	PartialAssets          bool // Upload before publishing, dropping failed screenshots/icon instead of aborting
+	KeepGoing              bool // With several config files, publish the rest after one fails
+	ChannelSuffix          bool // Suffix the app identifier with the channel for non-main channels (com.example.app~beta)
+	VerifyAfterPublish     bool // Read replaceable events back from the Zapstore (or first) relay after publishing
+	RelayInfo              bool // Print each relay's NIP-11 information before publishing
+	AllowV1Only            bool // Publish (or pass --check) APKs signed only with the v1 scheme
+	LenientParse           bool // Recover package and version from a manifest that fails to decode, with warnings
+	TrustLocalClock        bool // Use the local clock for created_at even when network time disagrees
+	Dev                    bool // Publish to local dev infrastructure with the dev test key
+	EphemeralKey           bool // Publish with a throwaway key generated for this run
 	Wizard                 bool
 	Check                  bool // Verify config fetches arm64-v8a APK (exit 0=success)
+	ExplainSelection       bool // Print why each release asset was or wasn't selected, without publishing
+	Explain                bool // Print a plain-language outline of the publish steps, without running them
+
+	// Wait this long for another publish of the same package to finish (0: fail at once)
+	WaitLock time.Duration
 
 	// Server options
 	Port int
@@ -64,6 +126,57 @@ type UtilsOptions struct {
 	Operation string // "extract-apk", "has-new-release"
 }
 
+// ConfigOptions holds flags specific to the config subcommand.
+type ConfigOptions struct {
+	Operation string // "migrate" or "validate"
+	DryRun    bool   // Print the migrated config to stdout instead of writing the file
+	Offline   bool   // validate: skip URL reachability checks
+}
+
+// HistoryOptions holds flags specific to the history subcommand.
+type HistoryOptions struct {
+	Interactive bool // Select a recorded release to view or re-broadcast
+}
+
+// APKOptions holds flags specific to the apk subcommand.
+type APKOptions struct {
+	CompareCert bool     // Check an APK's signing certificate against an npub's identity proof or a certificate file
+	Relays      []string // Relays to fetch identity proofs from
+	Lint        bool     // Check an APK for common packaging mistakes
+	FailOn      string   // Lowest lint finding severity that fails: info, warning, error or none
+}
+
+// BlossomOptions holds flags specific to the blossom subcommand.
+type BlossomOptions struct {
+	Operation string // "list", "prune"
+	DryRun    bool   // prune: list what would be deleted without deleting
+	KeepLast  int    // prune: keep the N most recently uploaded blobs of each package
+	Yes       bool   // prune: skip the confirmation prompt
+}
+
+// RelayOptions holds flags specific to the relay subcommand.
+type RelayOptions struct {
+	Operation string // "info"
+}
+
+// KeysOptions holds flags specific to the keys subcommand.
+type KeysOptions struct {
+	Operation string // "generate" or "show"
+	Keyring   bool   // generate: store the nsec in the OS keyring instead of printing it
+	Quiet     bool   // Print only the key data, no status lines
+}
+
+// ExportOptions holds flags specific to the export subcommand.
+type ExportOptions struct {
+	Operation string // "badge"
+	Package   string // Package ID whose latest release the badge shows
+	Out       string // Path of the shields.io endpoint JSON ("" or "-" for stdout)
+	SVG       string // Path of a static SVG badge to write as well
+	Channel   string // Release channel to report (default: main)
+	Label     string // Left-hand badge text
+	Pubkey    string // Publisher npub or hex pubkey (default: from SIGN_WITH)
+}
+
 // IdentityOptions holds flags specific to the identity subcommand.
 type IdentityOptions struct {
 	LinkKey       string   // Path to certificate file (.p12, .pfx, .pem, .crt)
@@ -82,7 +195,7 @@ type Options struct {
 	// Distinct from Global.Help: callers must exit 1 without treating this as a help request.
 	FlagParseError error
 
-	// UnknownSubcommand is the token the user passed when it is not a known command (publish, identity, utils).
+	// UnknownSubcommand is the token the user passed when it is not a known command (publish, identity, utils, config, history, apk, blossom, relay, keys, export).
 	// When non-empty, Global.Help is also set; callers should show help and exit 1.
 	UnknownSubcommand string
 
@@ -90,6 +203,13 @@ type Options struct {
 	Publish  PublishOptions
 	Identity IdentityOptions
 	Utils    UtilsOptions
+	Config   ConfigOptions
+	History  HistoryOptions
+	APK      APKOptions
+	Blossom  BlossomOptions
+	Relay    RelayOptions
+	Keys     KeysOptions
+	Export   ExportOptions
 }
 
 // stringSliceFlag implements flag.Value to accumulate multiple flag values.
@@ -176,6 +296,27 @@ func ParseCommand() *Options {
 	case "utils":
 		opts.Command = CommandUtils
 		parseUtilsArgs(opts, args[1:])
+	case "config":
+		opts.Command = CommandConfig
+		parseConfigArgs(opts, args[1:])
+	case "history":
+		opts.Command = CommandHistory
+		parseHistoryArgs(opts, args[1:])
+	case "apk":
+		opts.Command = CommandAPK
+		parseAPKArgs(opts, args[1:])
+	case "blossom":
+		opts.Command = CommandBlossom
+		parseBlossomArgs(opts, args[1:])
+	case "relay":
+		opts.Command = CommandRelay
+		parseRelayArgs(opts, args[1:])
+	case "keys":
+		opts.Command = CommandKeys
+		parseKeysArgs(opts, args[1:])
+	case "export":
+		opts.Command = CommandExport
+		parseExportArgs(opts, args[1:])
 	default:
 		// Unknown subcommand - show help
 		opts.Global.Help = true
@@ -192,29 +333,81 @@ func parsePublishFlags(opts *Options, args []string) {
 	fs.SetOutput(os.Stderr)
 
 	var metadataFlags stringSliceFlag
+	var platformFlags stringSliceFlag
+	var onlyFlags stringSliceFlag
 
 	fs.StringVar(&opts.Publish.RepoURL, "r", "", "Repository URL (GitHub/GitLab/F-Droid)")
 	fs.StringVar(&opts.Publish.ReleaseSource, "s", "", "Release source URL (defaults to -r)")
 	fs.Var(&metadataFlags, "m", "Fetch metadata from source (repeatable: -m github -m fdroid)")
 	fs.StringVar(&opts.Publish.Match, "match", "", "Regex pattern to filter APK assets")
+	fs.StringVar(&opts.Publish.MatchLabel, "match-label", "", "Forge asset label (exact or regex) or asset ID to select")
+	fs.StringVar(&opts.Publish.Variant, "variant", "", "Publish only the APK matching this variant from the config's variants")
+	fs.StringVar(&opts.Publish.BaseDir, "base-dir", "", "Directory relative paths in the config resolve against (default: config file directory)")
+	fs.Var(&onlyFlags, "only", "Publish only these apps from the config's apps: list (repeatable or comma-separated)")
 	fs.StringVar(&opts.Publish.Commit, "commit", "", "Git commit hash for reproducible builds")
+	fs.StringVar(&opts.Publish.ChangelogFrom, "changelog-from", "", "Generate release notes from: git (conventional commits since the previous tag)")
 	fs.StringVar(&opts.Publish.Channel, "channel", "main", "Release channel: main, beta, nightly, dev")
+	fs.BoolVar(&opts.Publish.ChannelSuffix, "channel-suffix", false, "Publish non-main channels as a separate app entry (com.example.app~beta)")
+	fs.Var(&platformFlags, "platform", "Platform identifier for the f tag, overriding detection (repeatable)")
+	fs.StringVar(&opts.Publish.PublishedAt, "published-at", "", "Override release published_at (RFC3339, YYYY-MM-DD, or unix seconds)")
 	fs.BoolVar(&opts.Publish.Offline, "offline", false, "Sign events without uploading/publishing (outputs JSON to stdout)")
+	fs.BoolVar(&opts.Publish.SignOnly, "sign-only", false, "Sign events for blobs already on the Blossom server and output them as JSONL, without uploading or contacting relays")
 	fs.BoolVar(&opts.Publish.Quiet, "quiet", false, "No prompts, no spinners, auto-yes to all confirmations")
 	fs.BoolVar(&opts.Publish.Quiet, "q", false, "Alias for --quiet")
 	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.Trace, "trace", false, "Log every outbound HTTP request and response to stderr (auth redacted)")
 	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
 	fs.BoolVar(&opts.Publish.SkipPreview, "skip-preview", false, "Skip the browser preview prompt")
+	fs.BoolVar(&opts.Publish.PreviewURLOnly, "preview-url-only", false, "Start the preview server and print its URL without opening a browser, e.g. to open it through an SSH tunnel")
+	fs.BoolVar(&opts.Publish.NoPreviewImages, "no-preview-images", false, "Show screenshot placeholders in the preview instead of downloading images")
 	fs.IntVar(&opts.Publish.Port, "port", 0, "Custom port for browser preview/signing")
 	fs.BoolVar(&opts.Publish.OverwriteRelease, "overwrite-release", false, "Bypass cache and re-publish even if release unchanged")
+	fs.BoolVar(&opts.Publish.OnlyNewAssets, "only-new-assets", false, "Add the APK to the published release, keeping its other assets")
+	fs.BoolVar(&opts.Publish.IgnoreReleaseAge, "ignore-release-age", false, "Publish even if the release is younger than min_release_age")
+	fs.StringVar(&opts.Publish.OverwriteApp, "overwrite-app", "merge", "App metadata update strategy: merge (keep existing fields) or replace")
+	fs.IntVar(&opts.Publish.MinRelaySuccess, "min-relay-success", 0, "Succeed when at least N relays accept each event (default: all)")
+	fs.StringVar(&opts.Publish.LimitRate, "limit-rate", "", "Limit download and upload bandwidth, in bytes per second with K/M/G suffixes (e.g. 2M)")
+	fs.StringVar(&opts.Publish.MaxDownloadRate, "max-download-rate", "", "Limit APK download bandwidth only (e.g. 2MB/s), overriding --limit-rate")
+	fs.StringVar(&opts.Publish.MaxUploadRate, "max-upload-rate", "", "Limit Blossom upload bandwidth only (e.g. 512K), overriding --limit-rate")
+	fs.StringVar(&opts.Publish.ManifestJSON, "manifest-json", "", "Write the Blossom upload manifest as JSON to this file (- for stdout) with --offline or an npub signer")
+	fs.StringVar(&opts.Publish.BlossomAuthOut, "blossom-auth-out", "", "Write unsigned Blossom upload auth events as JSONL to this file (- for stdout) with an npub signer")
+	fs.BoolVar(&opts.Publish.ProgressJSON, "progress-json", false, "Emit JSON progress events to stderr instead of the human UI")
+	fs.StringVar(&opts.Publish.ProgressFD, "progress-fd", "", "Write --progress-json events to this file descriptor or named pipe")
+	fs.StringVar(&opts.Publish.AnswersFile, "answers-file", "", "Answer --progress-json prompts from this JSON file")
+	fs.StringVar(&opts.Publish.MetricsOut, "metrics-out", "", "Write Prometheus textfile-collector metrics to this file at exit")
+	fs.BoolVar(&opts.Publish.BugReport, "bug-report", false, "Write a redacted diagnostic bundle if publishing fails")
+	fs.StringVar(&opts.Publish.Relays, "relays", "", "Publish relays: nip65 (signer's write relays + relay.zapstore.dev) or nip65-only")
 	fs.BoolVar(&opts.Publish.IncludePreReleases, "pre-release", false, "Include pre-releases when fetching the latest release")
+	fs.BoolVar(&opts.Publish.PickRelease, "pick-release", false, "Choose which of the recent releases to publish (interactive, forge sources)")
 	fs.BoolVar(&opts.Publish.SkipMetadata, "skip-metadata", false, "Skip fetching metadata from external sources")
 	fs.BoolVar(&opts.Publish.Wizard, "wizard", false, "Run interactive wizard (uses existing config as defaults)")
 	fs.BoolVar(&opts.Publish.AppCreatedAtRelease, "app-created-at-release", false, "Use release date for kind 32267 created_at (indexer compatibility)")
 	fs.BoolVar(&opts.Publish.SkipAppEvent, "skip-app-event", false, "Publish only release events, skip app metadata (kind 32267)")
 	fs.BoolVar(&opts.Publish.SkipCertificateLinking, "skip-certificate-linking", false, "Skip certificate-to-identity linking check")
 	fs.BoolVar(&opts.Publish.NoCompress, "no-compress", false, "Preserve original icon and screenshot bytes")
+	fs.BoolVar(&opts.Publish.StrictImages, "strict-images", false, "Fail if a screenshot is unreachable or not an image")
+	fs.BoolVar(&opts.Publish.StrictVersioning, "strict-versioning", false, "Fail if the versionCode is not above every version published on any channel")
+	fs.BoolVar(&opts.Publish.AllowDifferentAuthor, "allow-different-author", false, "Publish even if the app exists under a different pubkey")
+	fs.BoolVar(&opts.Publish.ConfirmCertChange, "confirm-cert-change", false, "Publish although the APK's signing certificate differs from the last published one")
+	fs.BoolVar(&opts.Publish.StrictRedirects, "strict-redirects", false, "Fail if the asset URL redirects to a different host than at the last publish")
+	fs.BoolVar(&opts.Publish.NoBlurhash, "no-blurhash", false, "Omit the icon blurhash from the app event")
+	fs.BoolVar(&opts.Publish.PartialAssets, "partial-assets", false, "Keep uploading after a failed upload and publish without the failed screenshots/icon")
+	fs.BoolVar(&opts.Publish.VerifyAfterPublish, "verify-after-publish", true, "Read app and release events back from the Zapstore (or first) relay after publishing")
+	fs.BoolVar(&opts.Publish.RelayInfo, "relay-info", false, "Print each relay's NIP-11 information before publishing")
+	fs.BoolVar(&opts.Publish.NoRetractOnPartial, "no-retract-on-partial", false, "Keep events the Zapstore (or first) relay stored when it rejected the app, release or every asset")
+	fs.BoolVar(&opts.Publish.KeepGoing, "keep-going", false, "With several config files, keep publishing the rest after one fails")
+	fs.StringVar(&opts.Publish.Delegation, "delegation", "", "NIP-26 delegation tag (JSON) to publish on the delegator's behalf")
+	fs.StringVar(&opts.Publish.AddToSet, "add-to-set", "", "After publishing, add the app to this app curation set (naddr, owned by SIGN_WITH)")
+	fs.DurationVar(&opts.Publish.WaitLock, "wait-lock", 0, "Wait up to this long (e.g. 10m) for another publish of the same package to finish")
+	fs.StringVar(&opts.Publish.IconDensity, "icon-density", "", "APK icon density to extract: ldpi, mdpi, hdpi, xhdpi, xxhdpi, xxxhdpi or max")
+	fs.BoolVar(&opts.Publish.AllowV1Only, "allow-v1-only", false, "Allow APKs signed only with the legacy v1 (JAR) scheme")
+	fs.BoolVar(&opts.Publish.LenientParse, "lenient-parse", false, "On manifest decode errors, recover the package and version by scanning the manifest instead of failing")
+	fs.BoolVar(&opts.Publish.Dev, "dev", false, "Publish to a local dev relay and Blossom server with the dev test key")
+	fs.BoolVar(&opts.Publish.EphemeralKey, "ephemeral-key", false, "Publish with a throwaway key generated for this run; SIGN_WITH, if set, signs an attestation linking it")
+	fs.BoolVar(&opts.Publish.TrustLocalClock, "trust-local-clock", false, "Use the local clock for created_at even when it disagrees with network time")
 	fs.BoolVar(&opts.Publish.Check, "check", false, "Verify config fetches arm64-v8a APK (exit 0=success)")
+	fs.BoolVar(&opts.Publish.ExplainSelection, "explain-selection", false, "Explain APK asset selection without publishing")
+	fs.BoolVar(&opts.Publish.Explain, "explain", false, "Outline what publishing would do without doing it")
 	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (errors as JSON to stderr, events as JSONL to stdout)")
 
 	// Help flag
@@ -223,9 +416,7 @@ func parsePublishFlags(opts *Options, args []string) {
 	fs.BoolVar(&showHelp, "help", false, "Show help")
 
 	// Reorder args to put flags before positional arguments
-	reorderedArgs := reorderArgsForFlagSet(args, map[string]bool{
-		"-r": true, "-s": true, "-m": true, "--match": true, "--commit": true, "--channel": true, "--port": true,
-	})
+	reorderedArgs := reorderArgsForFlagSet(fs, args)
 
 	if err := fs.Parse(reorderedArgs); err != nil {
 		opts.FlagParseError = err
@@ -238,6 +429,14 @@ func parsePublishFlags(opts *Options, args []string) {
 	}
 
 	opts.Publish.Metadata = metadataFlags
+	opts.Publish.Platforms = platformFlags
+	for _, v := range onlyFlags {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				opts.Publish.Only = append(opts.Publish.Only, name)
+			}
+		}
+	}
 	opts.Args = fs.Args()
 }
 
@@ -263,9 +462,7 @@ func parseIdentityFlags(opts *Options, args []string) {
 	fs.BoolVar(&showHelp, "help", false, "Show help")
 
 	// Reorder args
-	reorderedArgs := reorderArgsForFlagSet(args, map[string]bool{
-		"--link-key": true, "--link-key-expiry": true, "--verify": true, "--relays": true,
-	})
+	reorderedArgs := reorderArgsForFlagSet(fs, args)
 
 	if err := fs.Parse(reorderedArgs); err != nil {
 		opts.FlagParseError = err
@@ -310,51 +507,334 @@ func parseUtilsArgs(opts *Options, args []string) {
 	fs := flag.NewFlagSet("utils "+opts.Utils.Operation, flag.ContinueOnError)
 	fs.SetOutput(os.Stderr)
 	fs.BoolVar(&opts.Publish.IncludePreReleases, "pre-release", false, "Include pre-releases when fetching the latest release")
+	fs.StringVar(&opts.Publish.IconDensity, "icon-density", "", "APK icon density to extract (extract-apk): ldpi..xxxhdpi or max")
 	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.Trace, "trace", false, "Log every outbound HTTP request and response to stderr (auth redacted)")
 	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
 	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (errors as JSON to stderr)")
 
 	// Reorder so flags come before positional args
-	reorderedArgs := reorderArgsForFlagSet(remaining, map[string]bool{})
+	reorderedArgs := reorderArgsForFlagSet(fs, remaining)
+	if err := fs.Parse(reorderedArgs); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+
+	opts.Args = fs.Args()
+}
+
+// parseConfigArgs parses positional args for the config subcommand.
+// The first positional arg is the operation: "migrate" or "validate".
+func parseConfigArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		opts.Global.Help = true
+		return
+	}
+
+	opts.Config.Operation = args[0]
+
+	fs := flag.NewFlagSet("config "+opts.Config.Operation, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&opts.Config.DryRun, "dry-run", false, "Print the migrated config to stdout instead of writing it")
+	fs.BoolVar(&opts.Config.Offline, "offline", false, "Skip URL reachability checks")
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (errors as JSON to stderr)")
+
+	reorderedArgs := reorderArgsForFlagSet(fs, args[1:])
+	if err := fs.Parse(reorderedArgs); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+
+	opts.Args = fs.Args()
+}
+
+// parseHistoryArgs parses flags for the history subcommand.
+// The optional positional arg filters entries by package ID.
+func parseHistoryArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&opts.History.Interactive, "interactive", false, "Select a release to view or re-broadcast")
+	fs.BoolVar(&opts.History.Interactive, "i", false, "Select a release to view or re-broadcast")
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (entries as JSONL to stdout)")
+
+	reorderedArgs := reorderArgsForFlagSet(fs, args)
+	if err := fs.Parse(reorderedArgs); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+
+	opts.Args = fs.Args()
+}
+
+// parseAPKArgs parses flags for the apk subcommand.
+func parseAPKArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	var relaysFlag stringSliceFlag
+
+	fs := flag.NewFlagSet("apk", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&opts.APK.CompareCert, "compare-cert", false, "Check the APK's signing certificate against an npub or certificate")
+	fs.Var(&relaysFlag, "relays", "Relays for identity proofs (repeatable, overrides defaults)")
+	fs.BoolVar(&opts.APK.Lint, "lint", false, "Check the APK for common packaging mistakes")
+	fs.StringVar(&opts.APK.FailOn, "fail-on", "", "Lowest lint severity that fails: info, warning, error (default) or none")
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (result as JSON to stdout)")
+
+	reorderedArgs := reorderArgsForFlagSet(fs, args)
 	if err := fs.Parse(reorderedArgs); err != nil {
 		opts.FlagParseError = err
 		return
 	}
 
+	if len(relaysFlag) > 0 {
+		opts.APK.Relays = relaysFlag
+	} else {
+		opts.APK.Relays = DefaultIdentityRelays
+	}
 	opts.Args = fs.Args()
+
+	if opts.APK.CompareCert == opts.APK.Lint {
+		opts.Global.Help = true
+	}
 }
 
-// reorderArgsForFlagSet moves flags before positional arguments.
-func reorderArgsForFlagSet(args []string, valuedFlags map[string]bool) []string {
+// parseBlossomArgs parses positional args for the blossom subcommand.
+// The first positional arg is the operation: "list" or "prune".
+func parseBlossomArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		opts.Global.Help = true
+		return
+	}
+
+	opts.Blossom.Operation = args[0]
+
+	fs := flag.NewFlagSet("blossom "+opts.Blossom.Operation, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&opts.Blossom.DryRun, "dry-run", false, "List the blobs prune would delete without deleting them")
+	fs.IntVar(&opts.Blossom.KeepLast, "keep-last", 0, "Keep the N most recently uploaded blobs of each package")
+	fs.BoolVar(&opts.Blossom.Yes, "yes", false, "Delete without asking for confirmation")
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.Trace, "trace", false, "Log every outbound HTTP request and response to stderr (auth redacted)")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (blobs as JSONL to stdout)")
+
+	reorderedArgs := reorderArgsForFlagSet(fs, args[1:])
+	if err := fs.Parse(reorderedArgs); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+	opts.Args = fs.Args()
+}
+
+// parseRelayArgs parses the relay subcommand's operation and flags.
+// Positional arguments after the operation are relay URLs.
+func parseRelayArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		opts.Global.Help = true
+		return
+	}
+
+	opts.Relay.Operation = args[0]
+
+	fs := flag.NewFlagSet("relay "+opts.Relay.Operation, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output (one JSON object per relay)")
+
+	if err := fs.Parse(reorderArgsForFlagSet(fs, args[1:])); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+	opts.Args = fs.Args()
+}
+
+// parseKeysArgs parses the keys subcommand's operation and flags.
+func parseKeysArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		opts.Global.Help = true
+		return
+	}
+
+	opts.Keys.Operation = args[0]
+
+	fs := flag.NewFlagSet("keys "+opts.Keys.Operation, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.BoolVar(&opts.Keys.Keyring, "keyring", false, "Store the new nsec in the OS keyring")
+	fs.BoolVar(&opts.Keys.Quiet, "quiet", false, "Print only the key data")
+	fs.BoolVar(&opts.Keys.Quiet, "q", false, "Alias for --quiet")
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output")
+
+	if err := fs.Parse(reorderArgsForFlagSet(fs, args[1:])); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+	opts.Args = fs.Args()
+}
+
+// parseExportArgs parses arguments for the export subcommand.
+func parseExportArgs(opts *Options, args []string) {
+	for _, a := range args {
+		if a == "-h" || a == "--help" || a == "-help" {
+			opts.Global.Help = true
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		opts.Global.Help = true
+		return
+	}
+
+	opts.Export.Operation = args[0]
+
+	fs := flag.NewFlagSet("export "+opts.Export.Operation, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.StringVar(&opts.Export.Package, "package", "", "Package ID of the app")
+	fs.StringVar(&opts.Export.Out, "out", "", "Write the shields.io endpoint JSON to this file (default: stdout)")
+	fs.StringVar(&opts.Export.SVG, "svg", "", "Also write a static SVG badge to this file")
+	fs.StringVar(&opts.Export.Channel, "channel", "main", "Release channel: main, beta, nightly, dev")
+	fs.StringVar(&opts.Export.Label, "label", "zapstore", "Badge label")
+	fs.StringVar(&opts.Export.Pubkey, "pubkey", "", "Publisher npub or hex pubkey (default: from SIGN_WITH)")
+	fs.BoolVar(&opts.Global.Verbose, "verbose", false, "Debug output")
+	fs.BoolVar(&opts.Global.NoColor, "no-color", false, "Disable colored output")
+	fs.BoolVar(&opts.Global.JSON, "json", false, "Machine-readable output")
+
+	if err := fs.Parse(reorderArgsForFlagSet(fs, args[1:])); err != nil {
+		opts.FlagParseError = err
+		return
+	}
+	opts.Args = fs.Args()
+}
+
+// reorderArgsForFlagSet moves flags before positional arguments, so flags may
+// follow them (zsp publish app.apk --quiet). A flag that takes a value keeps
+// the next argument even when it starts with a dash (-m -weird, --port -1),
+// unless the value is joined with = (--port=17010). Everything after a "--"
+// terminator is positional, as is a lone "-". Which flags take a value comes
+// from fs, so fs must be fully defined.
+func reorderArgsForFlagSet(fs *flag.FlagSet, args []string) []string {
 	var flags, positional []string
 
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
-		if strings.HasPrefix(arg, "-") {
-			flags = append(flags, arg)
-			// Check if this flag takes a value
-			if valuedFlags[arg] && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
-				i++
-				flags = append(flags, args[i])
-			}
-		} else {
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
 			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if !strings.Contains(name, "=") && flagTakesValue(fs, name) && i+1 < len(args) {
+			i++
+			flags = append(flags, args[i])
 		}
 	}
 
+	// The terminator keeps positional arguments that look like flags as they are
+	if len(positional) > 0 {
+		flags = append(flags, "--")
+	}
 	return append(flags, positional...)
 }
 
+// flagTakesValue reports whether the flag name in fs needs a value argument,
+// the way the flag package decides: every flag but booleans. Unknown flags
+// take none and are left for fs.Parse to reject.
+func flagTakesValue(fs *flag.FlagSet, name string) bool {
+	f := fs.Lookup(name)
+	if f == nil {
+		return false
+	}
+	if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+		return false
+	}
+	return true
+}
+
 // IsInteractive returns true if the CLI should show interactive prompts.
-// False when --quiet or --json is active.
+// False when --quiet or --json is active, and with --dev, which auto-confirms.
 func (o *Options) IsInteractive() bool {
-	return !o.Publish.Quiet && !o.Global.JSON
+	return !o.Publish.Quiet && !o.Global.JSON && !o.Publish.Dev
 }
 
 // ShouldShowSpinners returns true if spinners/progress should be shown.
-// False when --quiet or --json is active (both require clean stderr).
+// False when --quiet or --json is active (both require clean stderr), and
+// with --progress-json, which reports progress as events instead.
 func (o *Options) ShouldShowSpinners() bool {
-	return !o.Publish.Quiet && !o.Global.JSON
+	return !o.Publish.Quiet && !o.Global.JSON && !o.Publish.ProgressJSON
+}
+
+// Validate runs every publish flag check and returns the first error.
+func (o *PublishOptions) Validate() error {
+	checks := []func() error{
+		o.ValidateChannel,
+		o.ValidatePublishedAt,
+		o.ValidateOverwriteApp,
+		o.ValidateRelays,
+		o.ValidateMinRelaySuccess,
+		o.ValidatePlatforms,
+		o.ValidateIconDensity,
+		o.ValidateChangelogFrom,
+		o.ValidateEphemeralKey,
+	}
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ValidateChannel returns an error if the channel is invalid.
@@ -366,6 +846,173 @@ func (o *PublishOptions) ValidateChannel() error {
 	return nil
 }
 
+// ValidatePlatforms checks that every --platform value is an accepted platform identifier.
+func (o *PublishOptions) ValidatePlatforms() error {
+	validPlatforms := map[string]bool{
+		"android-arm64-v8a": true, "android-armeabi-v7a": true, "android-x86": true, "android-x86_64": true,
+	}
+	for _, platform := range o.Platforms {
+		if !validPlatforms[platform] {
+			return fmt.Errorf("invalid --platform %q: must be one of android-arm64-v8a, android-armeabi-v7a, android-x86, android-x86_64", platform)
+		}
+	}
+	return nil
+}
+
+// ValidateOverwriteApp checks that --overwrite-app is a known strategy.
+func (o *PublishOptions) ValidateOverwriteApp() error {
+	switch o.OverwriteApp {
+	case "merge", "replace":
+		return nil
+	}
+	return fmt.Errorf("invalid --overwrite-app %q: must be merge or replace", o.OverwriteApp)
+}
+
+// ValidateEphemeralKey checks that --ephemeral-key is not combined with flags
+// that overwrite published events or need the real key to sign.
+func (o *PublishOptions) ValidateEphemeralKey() error {
+	if !o.EphemeralKey {
+		return nil
+	}
+	switch {
+	case o.OverwriteRelease:
+		return fmt.Errorf("--ephemeral-key cannot be combined with --overwrite-release")
+	case o.OverwriteApp == "replace":
+		return fmt.Errorf("--ephemeral-key cannot be combined with --overwrite-app replace")
+	case o.Dev:
+		return fmt.Errorf("--ephemeral-key cannot be combined with --dev, which uses the dev test key")
+	case o.AddToSet != "":
+		return fmt.Errorf("--ephemeral-key cannot be combined with --add-to-set, which needs the set owner's key")
+	case o.Delegation != "":
+		return fmt.Errorf("--ephemeral-key cannot be combined with --delegation")
+	}
+	return nil
+}
+
+// ValidateIconDensity checks that --icon-density, if set, is a density qualifier or max.
+func (o *PublishOptions) ValidateIconDensity() error {
+	switch o.IconDensity {
+	case "", "ldpi", "mdpi", "hdpi", "xhdpi", "xxhdpi", "xxxhdpi", "max":
+		return nil
+	}
+	return fmt.Errorf("invalid --icon-density %q: must be ldpi, mdpi, hdpi, xhdpi, xxhdpi, xxxhdpi or max", o.IconDensity)
+}
+
+// ValidateChangelogFrom checks that --changelog-from, if set, is a known generator.
+func (o *PublishOptions) ValidateChangelogFrom() error {
+	switch o.ChangelogFrom {
+	case "", "git":
+		return nil
+	}
+	return fmt.Errorf("invalid --changelog-from %q: must be git", o.ChangelogFrom)
+}
+
+// ValidateRelays checks that --relays, if set, is a known discovery mode.
+func (o *PublishOptions) ValidateRelays() error {
+	switch o.Relays {
+	case "", "nip65", "nip65-only":
+		return nil
+	}
+	return fmt.Errorf("invalid --relays %q: must be nip65 or nip65-only", o.Relays)
+}
+
+// ValidateProgressJSON checks that --progress-fd and --answers-file come with
+// --progress-json, which cannot be combined with --json.
+func (o *Options) ValidateProgressJSON() error {
+	if !o.Publish.ProgressJSON {
+		if o.Publish.ProgressFD != "" {
+			return fmt.Errorf("--progress-fd requires --progress-json")
+		}
+		if o.Publish.AnswersFile != "" {
+			return fmt.Errorf("--answers-file requires --progress-json")
+		}
+		return nil
+	}
+	if o.Global.JSON {
+		return fmt.Errorf("--progress-json cannot be combined with --json")
+	}
+	return nil
+}
+
+// ValidateDev checks that --dev is not combined with production infrastructure:
+// relayURLs (RELAY_URLS plus the dev relay) and blossomURL must all be local, and
+// --relays is refused since NIP-65 discovery adds the signer's public relays.
+// The config's relays: mode is ignored under --dev for the same reason.
+func (o *PublishOptions) ValidateDev(relayURLs []string, blossomURL string) error {
+	if !o.Dev {
+		return nil
+	}
+	if o.Relays != "" {
+		return fmt.Errorf("--dev cannot be combined with --relays %s", o.Relays)
+	}
+	for _, u := range relayURLs {
+		if !IsLocalURL(u) {
+			return fmt.Errorf("--dev cannot publish to %s: only local relays are allowed in dev mode (unset RELAY_URLS)", u)
+		}
+	}
+	if blossomURL != "" && !IsLocalURL(blossomURL) {
+		return fmt.Errorf("--dev cannot upload to %s: only a local Blossom server is allowed in dev mode (unset BLOSSOM_URL)", blossomURL)
+	}
+	return nil
+}
+
+// IsLocalURL reports whether rawURL points at this machine or a private network:
+// localhost, *.localhost, *.local, loopback or private IP addresses.
+func IsLocalURL(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "" {
+		return false
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// ValidateMinRelaySuccess checks that --min-relay-success is not negative.
+func (o *PublishOptions) ValidateMinRelaySuccess() error {
+	if o.MinRelaySuccess < 0 {
+		return fmt.Errorf("invalid --min-relay-success %d: must be 0 (all relays) or a positive count", o.MinRelaySuccess)
+	}
+	return nil
+}
+
+// ValidatePublishedAt checks that --published-at, if set, is a valid timestamp.
+func (o *PublishOptions) ValidatePublishedAt() error {
+	if o.PublishedAt == "" {
+		return nil
+	}
+	if _, err := ParseTimestamp(o.PublishedAt); err != nil {
+		return fmt.Errorf("invalid --published-at: %w", err)
+	}
+	return nil
+}
+
+// ParseTimestamp parses a timestamp in RFC3339, YYYY-MM-DD (UTC), or unix seconds.
+func ParseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty timestamp")
+	}
+
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp format: %s (use RFC3339, YYYY-MM-DD, or unix seconds)", s)
+}
+
 // ParseExpiryDuration parses a human-friendly duration string.
 // Supports: y (years), mo (months), d (days), h (hours).
 // Note: Use "mo" for months to avoid conflict with Go's "m" for minutes.
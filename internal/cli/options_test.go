@@ -2,7 +2,9 @@ package cli
 
 import (
 	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParseCommand_InvalidPublishFlagSetsFlagParseError(t *testing.T) {
@@ -32,3 +34,396 @@ func TestParseCommand_UnknownSubcommandSetsHelpAndMarker(t *testing.T) {
 		t.Fatalf("UnknownSubcommand = %q, want typo", opts.UnknownSubcommand)
 	}
 }
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"1577934245", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2020-01-02T03:04:05Z", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), false},
+		{"2020-01-02", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimestamp(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommand_RepeatablePlatform(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "publish", "app.apk", "--platform", "android-arm64-v8a", "--platform", "android-x86_64"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil {
+		t.Fatalf("unexpected FlagParseError: %v", opts.FlagParseError)
+	}
+	if len(opts.Publish.Platforms) != 2 || opts.Publish.Platforms[1] != "android-x86_64" {
+		t.Fatalf("Platforms = %v", opts.Publish.Platforms)
+	}
+	if err := opts.Publish.ValidatePlatforms(); err != nil {
+		t.Errorf("ValidatePlatforms() error: %v", err)
+	}
+
+	opts.Publish.Platforms = []string{"android-armeabi"}
+	if err := opts.Publish.ValidatePlatforms(); err == nil {
+		t.Error("expected error for android-armeabi")
+	}
+}
+
+func TestParseCommand_KeepGoingBatch(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "publish", "configs/a.yaml", "--keep-going", "configs/b.yaml", "-q"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil {
+		t.Fatalf("unexpected FlagParseError: %v", opts.FlagParseError)
+	}
+	if !opts.Publish.KeepGoing || !opts.Publish.Quiet {
+		t.Errorf("KeepGoing = %v, Quiet = %v; want both set", opts.Publish.KeepGoing, opts.Publish.Quiet)
+	}
+	if len(opts.Args) != 2 || opts.Args[0] != "configs/a.yaml" || opts.Args[1] != "configs/b.yaml" {
+		t.Errorf("Args = %v, want [configs/a.yaml configs/b.yaml]", opts.Args)
+	}
+}
+
+func TestParseCommand_ConfigValidate(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "config", "validate", "zapstore.yaml", "--offline", "--json"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil {
+		t.Fatalf("unexpected FlagParseError: %v", opts.FlagParseError)
+	}
+	if opts.Command != CommandConfig || opts.Config.Operation != "validate" {
+		t.Errorf("Command = %q, Operation = %q; want config validate", opts.Command, opts.Config.Operation)
+	}
+	if !opts.Config.Offline || !opts.Global.JSON {
+		t.Errorf("Offline = %v, JSON = %v; want both set", opts.Config.Offline, opts.Global.JSON)
+	}
+	if len(opts.Args) != 1 || opts.Args[0] != "zapstore.yaml" {
+		t.Errorf("Args = %v, want [zapstore.yaml]", opts.Args)
+	}
+}
+
+func TestParseCommand_History(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "history", "com.example.app", "-i"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil {
+		t.Fatalf("unexpected FlagParseError: %v", opts.FlagParseError)
+	}
+	if opts.Command != CommandHistory || !opts.History.Interactive {
+		t.Fatalf("Command = %q, Interactive = %v", opts.Command, opts.History.Interactive)
+	}
+	if len(opts.Args) != 1 || opts.Args[0] != "com.example.app" {
+		t.Errorf("Args = %v, want [com.example.app]", opts.Args)
+	}
+}
+
+func TestParseCommand_Dev(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "publish", "app.apk", "--dev"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil {
+		t.Fatalf("unexpected FlagParseError: %v", opts.FlagParseError)
+	}
+	if !opts.Publish.Dev || opts.IsInteractive() {
+		t.Fatalf("Dev = %v, IsInteractive() = %v; want dev mode without prompts", opts.Publish.Dev, opts.IsInteractive())
+	}
+	if !opts.ShouldShowSpinners() {
+		t.Error("--dev should keep normal output")
+	}
+
+	local := []string{"ws://localhost:10547", "ws://127.0.0.1:7777", "ws://192.168.1.5", "ws://relay.local"}
+	if err := opts.Publish.ValidateDev(local, "http://localhost:3000"); err != nil {
+		t.Errorf("ValidateDev(local) error: %v", err)
+	}
+	if err := opts.Publish.ValidateDev(append(local, "wss://relay.zapstore.dev"), ""); err == nil {
+		t.Error("expected error for production relay")
+	}
+	if err := opts.Publish.ValidateDev(local, "https://cdn.zapstore.dev"); err == nil {
+		t.Error("expected error for production Blossom server")
+	}
+	opts.Publish.Relays = "nip65"
+	if err := opts.Publish.ValidateDev(local, ""); err == nil {
+		t.Error("expected error for --relays nip65")
+	}
+}
+
+func TestParseCommand_EphemeralKey(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "publish", "app.apk", "--ephemeral-key"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil {
+		t.Fatalf("unexpected FlagParseError: %v", opts.FlagParseError)
+	}
+	if !opts.Publish.EphemeralKey {
+		t.Fatal("EphemeralKey = false")
+	}
+	if err := opts.Publish.ValidateEphemeralKey(); err != nil {
+		t.Errorf("ValidateEphemeralKey() error: %v", err)
+	}
+
+	refused := []func(*PublishOptions){
+		func(o *PublishOptions) { o.OverwriteRelease = true },
+		func(o *PublishOptions) { o.OverwriteApp = "replace" },
+		func(o *PublishOptions) { o.Dev = true },
+		func(o *PublishOptions) { o.AddToSet = "naddr1..." },
+	}
+	for i, set := range refused {
+		o := opts.Publish
+		set(&o)
+		if err := o.ValidateEphemeralKey(); err == nil {
+			t.Errorf("case %d: expected an error", i)
+		}
+	}
+}
+
+func TestParseCommand_APKCompareCert(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "apk", "app.apk", "npub1xyz", "--compare-cert", "--relays", "wss://relay.example.com"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil || opts.Global.Help {
+		t.Fatalf("FlagParseError = %v, Help = %v", opts.FlagParseError, opts.Global.Help)
+	}
+	if opts.Command != CommandAPK || !opts.APK.CompareCert {
+		t.Fatalf("Command = %q, CompareCert = %v", opts.Command, opts.APK.CompareCert)
+	}
+	if len(opts.Args) != 2 || opts.Args[0] != "app.apk" || opts.Args[1] != "npub1xyz" {
+		t.Errorf("Args = %v", opts.Args)
+	}
+	if len(opts.APK.Relays) != 1 || opts.APK.Relays[0] != "wss://relay.example.com" {
+		t.Errorf("Relays = %v", opts.APK.Relays)
+	}
+}
+
+func TestParseCommand_APKLint(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "apk", "--lint", "app.apk", "--fail-on", "warning", "--json"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil || opts.Global.Help {
+		t.Fatalf("FlagParseError = %v, Help = %v", opts.FlagParseError, opts.Global.Help)
+	}
+	if !opts.APK.Lint || opts.APK.CompareCert || opts.APK.FailOn != "warning" || !opts.Global.JSON {
+		t.Errorf("Lint = %v, CompareCert = %v, FailOn = %q, JSON = %v", opts.APK.Lint, opts.APK.CompareCert, opts.APK.FailOn, opts.Global.JSON)
+	}
+	if len(opts.Args) != 1 || opts.Args[0] != "app.apk" {
+		t.Errorf("Args = %v", opts.Args)
+	}
+
+	// Only one operation at a time
+	os.Args = []string{"zsp", "apk", "--lint", "--compare-cert", "app.apk"}
+	if opts := ParseCommand(); !opts.Global.Help {
+		t.Error("--lint with --compare-cert should show help")
+	}
+}
+
+func TestParseCommand_BlossomPrune(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "blossom", "prune", "--keep-last", "3", "--dry-run"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil || opts.Global.Help {
+		t.Fatalf("FlagParseError = %v, Help = %v", opts.FlagParseError, opts.Global.Help)
+	}
+	if opts.Command != CommandBlossom || opts.Blossom.Operation != "prune" {
+		t.Fatalf("Command = %q, Operation = %q", opts.Command, opts.Blossom.Operation)
+	}
+	if opts.Blossom.KeepLast != 3 || !opts.Blossom.DryRun {
+		t.Errorf("KeepLast = %d, DryRun = %v", opts.Blossom.KeepLast, opts.Blossom.DryRun)
+	}
+}
+
+func TestParseCommand_RelayInfo(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "relay", "info", "wss://relay.example.com", "--json"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil || opts.Global.Help {
+		t.Fatalf("FlagParseError = %v, Help = %v", opts.FlagParseError, opts.Global.Help)
+	}
+	if opts.Command != CommandRelay || opts.Relay.Operation != "info" {
+		t.Fatalf("Command = %q, Operation = %q", opts.Command, opts.Relay.Operation)
+	}
+	if !opts.Global.JSON || len(opts.Args) != 1 || opts.Args[0] != "wss://relay.example.com" {
+		t.Errorf("JSON = %v, Args = %v", opts.Global.JSON, opts.Args)
+	}
+}
+
+func TestParseCommand_KeysGenerate(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "keys", "generate", "--keyring", "--json"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil || opts.Global.Help {
+		t.Fatalf("FlagParseError = %v, Help = %v", opts.FlagParseError, opts.Global.Help)
+	}
+	if opts.Command != CommandKeys || opts.Keys.Operation != "generate" {
+		t.Fatalf("Command = %q, Operation = %q", opts.Command, opts.Keys.Operation)
+	}
+	if !opts.Keys.Keyring || !opts.Global.JSON {
+		t.Errorf("Keyring = %v, JSON = %v", opts.Keys.Keyring, opts.Global.JSON)
+	}
+}
+
+func TestParseCommand_ExportBadge(t *testing.T) {
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	os.Args = []string{"zsp", "export", "badge", "--package", "com.example.app", "--out", "badge.json", "--svg", "badge.svg"}
+
+	opts := ParseCommand()
+	if opts.FlagParseError != nil || opts.Global.Help {
+		t.Fatalf("FlagParseError = %v, Help = %v", opts.FlagParseError, opts.Global.Help)
+	}
+	if opts.Command != CommandExport || opts.Export.Operation != "badge" {
+		t.Fatalf("Command = %q, Operation = %q", opts.Command, opts.Export.Operation)
+	}
+	e := opts.Export
+	if e.Package != "com.example.app" || e.Out != "badge.json" || e.SVG != "badge.svg" || e.Channel != "main" || e.Label != "zapstore" {
+		t.Errorf("Export = %+v", e)
+	}
+}
+
+func TestParseCommand_PublishArgPermutations(t *testing.T) {
+	tests := []struct {
+		name     string
+		argv     []string
+		check    func(o *Options) bool
+		wantArgs []string
+	}{
+		{
+			name:     "joined value",
+			argv:     []string{"--port=17010", "config.yaml"},
+			check:    func(o *Options) bool { return o.Publish.Port == 17010 },
+			wantArgs: []string{"config.yaml"},
+		},
+		{
+			name:     "joined value after positional",
+			argv:     []string{"config.yaml", "--port=17010", "-q"},
+			check:    func(o *Options) bool { return o.Publish.Port == 17010 && o.Publish.Quiet },
+			wantArgs: []string{"config.yaml"},
+		},
+		{
+			name:     "separate value",
+			argv:     []string{"app.apk", "--channel", "beta"},
+			check:    func(o *Options) bool { return o.Publish.Channel == "beta" },
+			wantArgs: []string{"app.apk"},
+		},
+		{
+			name: "value starting with a dash",
+			argv: []string{"-m", "-weird", "-r", "https://github.com/acme/app"},
+			check: func(o *Options) bool {
+				return len(o.Publish.Metadata) == 1 && o.Publish.Metadata[0] == "-weird" && o.Publish.RepoURL == "https://github.com/acme/app"
+			},
+		},
+		{
+			name:     "negative number",
+			argv:     []string{"--min-relay-success", "-1", "config.yaml"},
+			check:    func(o *Options) bool { return o.Publish.MinRelaySuccess == -1 },
+			wantArgs: []string{"config.yaml"},
+		},
+		{
+			name: "valued flag before a bool",
+			argv: []string{"--base-dir", "dir", "-q", "config.yaml"},
+			check: func(o *Options) bool {
+				return o.Publish.BaseDir == "dir" && o.Publish.Quiet
+			},
+			wantArgs: []string{"config.yaml"},
+		},
+		{
+			name:     "single-dash long flag",
+			argv:     []string{"config.yaml", "-channel", "nightly"},
+			check:    func(o *Options) bool { return o.Publish.Channel == "nightly" },
+			wantArgs: []string{"config.yaml"},
+		},
+		{
+			name:     "terminator",
+			argv:     []string{"-q", "--", "-weird.apk", "--quiet"},
+			check:    func(o *Options) bool { return o.Publish.Quiet },
+			wantArgs: []string{"-weird.apk", "--quiet"},
+		},
+		{
+			name:     "terminator keeps order",
+			argv:     []string{"a.apk", "--offline", "--", "-b.apk"},
+			check:    func(o *Options) bool { return o.Publish.Offline },
+			wantArgs: []string{"a.apk", "-b.apk"},
+		},
+		{
+			name:     "stdin before flags",
+			argv:     []string{"-", "--offline"},
+			check:    func(o *Options) bool { return o.Publish.Offline },
+			wantArgs: []string{"-"},
+		},
+		{
+			name:     "bool with value",
+			argv:     []string{"--verify-after-publish=false", "config.yaml"},
+			check:    func(o *Options) bool { return !o.Publish.VerifyAfterPublish },
+			wantArgs: []string{"config.yaml"},
+		},
+	}
+
+	oldArgs := os.Args
+	t.Cleanup(func() { os.Args = oldArgs })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Args = append([]string{"zsp", "publish"}, tt.argv...)
+			opts := ParseCommand()
+			if opts.FlagParseError != nil {
+				t.Fatalf("%v: unexpected FlagParseError: %v", tt.argv, opts.FlagParseError)
+			}
+			if !tt.check(opts) {
+				t.Errorf("%v: options not parsed as expected: %+v", tt.argv, opts.Publish)
+			}
+			if !reflect.DeepEqual(opts.Args, tt.wantArgs) && (len(opts.Args) != 0 || len(tt.wantArgs) != 0) {
+				t.Errorf("%v: Args = %q, want %q", tt.argv, opts.Args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPublishOptionsValidate(t *testing.T) {
+	valid := PublishOptions{Channel: "main", OverwriteApp: "merge"}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() = %v, want nil", err)
+	}
+	for name, mutate := range map[string]func(*PublishOptions){
+		"channel":       func(o *PublishOptions) { o.Channel = "stable" },
+		"relays":        func(o *PublishOptions) { o.Relays = "all" },
+		"icon density":  func(o *PublishOptions) { o.IconDensity = "huge" },
+		"ephemeral key": func(o *PublishOptions) { o.EphemeralKey, o.OverwriteRelease = true, true },
+	} {
+		opts := valid
+		mutate(&opts)
+		if err := opts.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+	}
+}
@@ -4,14 +4,21 @@ package config
 import (
 	"fmt"
 	"io"
+	"maps"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/media"
+	"github.com/zapstore/zsp/internal/netpolicy"
 	"gopkg.in/yaml.v3"
 )
 
@@ -31,8 +38,20 @@ type Config struct {
 	// Release filtering (optional, filters releases by tag name)
 	ReleaseFilter string `yaml:"release_filter,omitempty"`
 
+	// MinReleaseAge refuses to publish a release that changed more recently than
+	// this duration (e.g. "1h", "2d"), so assets re-uploaded shortly after
+	// tagging are not picked up. A release changes when it is published and
+	// whenever an asset is uploaded. Overridden by --ignore-release-age.
+	MinReleaseAge string `yaml:"min_release_age,omitempty"`
+
+	// IncludePreReleases lets the latest release be a pre-release: GitHub and
+	// Gitea prereleases, GitLab upcoming releases, and F-Droid versions newer
+	// than the suggested one. Same as --pre-release.
+	IncludePreReleases bool `yaml:"include_pre_releases,omitempty"`
+
 	// Asset matching (optional, overrides auto-detection)
-	Match string `yaml:"match,omitempty"`
+	Match      string `yaml:"match,omitempty"`
+	MatchLabel string `yaml:"match_label,omitempty"` // Forge asset label (exact or regex) or asset ID
 
 	// App metadata (all optional, overrides APK-extracted values)
 	Name        string   `yaml:"name,omitempty"`
@@ -42,15 +61,43 @@ type Config struct {
 	License     string   `yaml:"license,omitempty"`
 	Website     string   `yaml:"website,omitempty"`
 
-	// Media (optional)
+	// Media (optional). Entries are local paths, URLs, or repo:<path> for a
+	// file in the repository at the release tag; icon: apk: selects the APK icon.
 	Icon   string   `yaml:"icon,omitempty"`
 	Images []string `yaml:"images,omitempty"`
 
+	// ImagesExclude removes screenshots after metadata is fetched (optional).
+	// Entries are glob patterns matched against the image URL or filename,
+	// or a 1-based position in the image list.
+	// Example: images_exclude: ["*feature*", "*promo*", 3]
+	ImagesExclude []string `yaml:"images_exclude,omitempty"`
+
+	// IconMinSize is the smallest icon width and height accepted (default 192).
+	// Icons must also be square; IconAutofix pads non-square icons onto a
+	// transparent square instead of failing. Icons are never upscaled.
+	IconMinSize int  `yaml:"icon_min_size,omitempty"`
+	IconAutofix bool `yaml:"icon_autofix,omitempty"`
+
+	// IconRasterize renders an SVG icon to a square PNG of IconRasterSize
+	// pixels (default 512), which is uploaded instead of the SVG.
+	IconRasterize  bool `yaml:"icon_rasterize,omitempty"`
+	IconRasterSize int  `yaml:"icon_raster_size,omitempty"`
+
 	// Release notes: local file path or URL (optional, if not set uses remote release notes)
 	// If URL, contents are fetched. If markdown follows Keep a Changelog format,
 	// only the section for this release is extracted.
 	ReleaseNotes string `yaml:"release_notes,omitempty"`
 
+	// AppendReleaseLink appends a link to the release's discussion, or to its
+	// release page when there is none, to the release notes.
+	AppendReleaseLink bool `yaml:"append_release_link,omitempty"`
+
+	// ReleaseNotesEvent publishes the full release notes as a long-form article
+	// (kind 30023) that the release references, keeping only an excerpt in the
+	// release itself: ReleaseNotesExcerpt, or the first paragraph of the notes.
+	ReleaseNotesEvent   bool   `yaml:"release_notes_event,omitempty"`
+	ReleaseNotesExcerpt string `yaml:"release_notes_excerpt,omitempty"`
+
 	// Changelog is deprecated, use ReleaseNotes instead
 	Changelog string `yaml:"changelog,omitempty"`
 
@@ -73,6 +120,22 @@ type Config struct {
 	// then fall back to their native repository metadata.
 	MetadataSources []string `yaml:"metadata_sources,omitempty"`
 
+	// MetadataProvenance adds a provenance tag to the kind 32267 event for each
+	// metadata source fetched: source name, URL and a hash of the fetched
+	// description and screenshot set, so listing content can be traced.
+	MetadataProvenance bool `yaml:"metadata_provenance,omitempty"`
+
+	// EmbedBuildInfo adds a build tag to every published event naming the CI
+	// system and the URL of the workflow run that published it, when detected.
+	// Off by default, since it links the events to the CI run.
+	EmbedBuildInfo bool `yaml:"embed_build_info,omitempty"`
+
+	// FDroidRepoOut adds each published APK to an unsigned F-Droid repo in
+	// this directory: the APK and icon are copied into repo/ and
+	// repo/index-v1.json is created or updated. Signing and hosting the repo
+	// are left to fdroidserver. Relative paths resolve against the config file.
+	FDroidRepoOut string `yaml:"fdroid_repo_out,omitempty"`
+
 	// Pubkey is the npub of the developer who publishes this app.
 	// Used by the relay for auto-whitelisting via repo verification.
 	Pubkey string `yaml:"pubkey,omitempty"`
@@ -85,9 +148,54 @@ type Config struct {
 	// Example (multiple): communities: [acfeaea6e51420e8068fac446ca9d17d7a9ef6a5d20d93894e50fee3d4902a84, fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210]
 	Communities []string `yaml:"communities,omitempty"`
 
+	// NetworkAllowlist restricts outbound connections to matching hosts
+	// (e.g. "github.com", "*.githubusercontent.com", "relay.zapstore.dev").
+	// Merged with ZSP_ALLOWED_HOSTS. Empty means unrestricted.
+	NetworkAllowlist []string `yaml:"network_allowlist,omitempty"`
+
+	// Relays selects publish relay discovery. "nip65" uses the signer's NIP-65
+	// write relays plus relay.zapstore.dev; "nip65-only" omits relay.zapstore.dev.
+	// Overridden by --relays.
+	Relays string `yaml:"relays,omitempty"`
+
+	// RelayRoutes also publishes events of the listed kinds to extra relays.
+	// With replace: true those kinds go only to the route's relays.
+	// Example: relay_routes: [{kinds: [30509], relays: [wss://proofs.example.com], replace: true}]
+	RelayRoutes []RelayRoute `yaml:"relay_routes,omitempty"`
+
+	// BlossomURL is the Blossom server uploads go to, e.g. "https://cdn.example.com".
+	// BLOSSOM_URL overrides it; when neither is set, the community's Blossom
+	// server or the Zapstore CDN is used.
+	BlossomURL string `yaml:"blossom_url,omitempty"`
+
+	// BlossomPublicURL is where clients download uploaded blobs when that differs
+	// from the upload server, e.g. "https://cdn.example.com" or a template such as
+	// "https://cdn.example.com/blobs/{hash}". Event URLs are rewritten to it.
+	BlossomPublicURL string `yaml:"blossom_public_url,omitempty"`
+
+	// DetachedSignature also signs each APK with minisign or GPG, uploads the
+	// signature to Blossom and references it from the asset event.
+	// Example: detached_signature: {type: minisign, key_env: MINISIGN_KEY}
+	DetachedSignature *DetachedSignature `yaml:"detached_signature,omitempty"`
+
+	// APKLint configures zsp apk --lint: checks to skip, and the lowest finding
+	// severity that makes it exit non-zero (overridden by --fail-on).
+	// Example: apk_lint: {ignore: [large-entry], fail_on: warning}
+	APKLint *APKLintConfig `yaml:"apk_lint,omitempty"`
+
+	// Apps lists per-app configs for monorepos that build several APKs per release.
+	// Each entry accepts the top-level fields and inherits any it does not set;
+	// release_source, release_filter, min_release_age and include_pre_releases are shared and may only be set at the top level.
+	// Example: apps: [{name: Shop, match: "shop-.*\\.apk$"}, {name: Kiosk, match: "kiosk-.*\\.apk$"}]
+	Apps    []*Config `yaml:"-"`
+	AppsRaw yaml.Node `yaml:"apps,omitempty"`
+
 	// BaseDir is the directory containing the config file (for relative paths).
 	// Not parsed from YAML, set by Load().
 	BaseDir string `yaml:"-"`
+
+	// Path is the absolute path of the config file. Not parsed from YAML, set by Load().
+	Path string `yaml:"-"`
 }
 
 // NIP34RepoPointer represents a parsed NIP-34 repository naddr.
@@ -97,6 +205,26 @@ type NIP34RepoPointer struct {
 	Relays     []string // Relay hints
 }
 
+// DetachedSignature configures detached APK signatures (detached_signature).
+type DetachedSignature struct {
+	Type        string `yaml:"type"`                   // "minisign" or "gpg"
+	KeyEnv      string `yaml:"key_env"`                // Variable holding the minisign secret key (contents or path) or the gpg key ID
+	PasswordEnv string `yaml:"password_env,omitempty"` // Variable holding the key's password, if it is encrypted
+}
+
+// APKLintConfig configures zsp apk --lint (apk_lint).
+type APKLintConfig struct {
+	Ignore []string `yaml:"ignore,omitempty"`  // Check IDs to skip, e.g. large-entry
+	FailOn string   `yaml:"fail_on,omitempty"` // "info", "warning", "error" (default) or "none"
+}
+
+// RelayRoute sends events of some kinds to extra relays (relay_routes).
+type RelayRoute struct {
+	Kinds   []int    `yaml:"kinds"`             // Event kinds to route, e.g. 3063 for assets
+	Relays  []string `yaml:"relays"`            // ws:// or wss:// relay URLs
+	Replace bool     `yaml:"replace,omitempty"` // Skip the default relays for these kinds
+}
+
 // ReleaseSource represents a release source configuration.
 // It can be a simple URL string, a local file path, or a web source config with version extractors.
 type ReleaseSource struct {
@@ -135,6 +263,28 @@ type ReleaseSource struct {
 	// If Version is not set, version is extracted from the downloaded APK.
 	// Mutually exclusive with AssetURL.
 	Asset *VersionExtractor
+
+	// Referer is sent with the APK download request (for hotlink-protected hosts).
+	Referer string
+
+	// Headers are extra HTTP headers sent with the APK download request.
+	Headers map[string]string
+}
+
+// DownloadHeaders returns the headers to send with the APK download request,
+// combining Headers and Referer. Returns nil when none are configured.
+func (r *ReleaseSource) DownloadHeaders() map[string]string {
+	if r == nil || (r.Referer == "" && len(r.Headers) == 0) {
+		return nil
+	}
+	headers := make(map[string]string, len(r.Headers)+1)
+	for k, v := range r.Headers {
+		headers[k] = v
+	}
+	if r.Referer != "" {
+		headers["Referer"] = r.Referer
+	}
+	return headers
 }
 
 // IsLocal returns true if this release source is a local file path.
@@ -173,6 +323,8 @@ type webReleaseSource struct {
 	AssetURL string            `yaml:"asset_url,omitempty"`
 	Version  *VersionExtractor `yaml:"version,omitempty"`
 	Asset    *VersionExtractor `yaml:"asset,omitempty"`
+	Referer  string            `yaml:"referer,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty"`
 }
 
 // HasVersionExtractor returns true if a version extractor is configured.
@@ -266,31 +418,87 @@ func Load(path string) (*Config, error) {
 	// Set base directory for relative path resolution
 	absPath, err := filepath.Abs(path)
 	if err == nil {
-		cfg.BaseDir = filepath.Dir(absPath)
+		for _, c := range append([]*Config{cfg}, cfg.Apps...) {
+			c.BaseDir = filepath.Dir(absPath)
+			c.Path = absPath
+		}
 	}
 
 	// Pubkey mismatch check: if zapstore.yaml has a pubkey, it must match the signer.
-	if cfg.Pubkey != "" {
-		if signWith := GetSignWith(); signWith != "" {
-			signerNpub := ResolvePubkeyFromSignWith(signWith)
-			if signerNpub != "" && signerNpub != cfg.Pubkey {
-				return nil, fmt.Errorf(
-					"pubkey mismatch: zapstore.yaml has pubkey %s but SIGN_WITH resolves to %s.\n"+
-						"Either update zapstore.yaml or set the correct SIGN_WITH.",
-					cfg.Pubkey, signerNpub,
-				)
-			}
+	for _, c := range append([]*Config{cfg}, cfg.Apps...) {
+		if err := c.checkPubkey(); err != nil {
+			return nil, err
 		}
 	}
 
 	return cfg, nil
 }
 
+// SetBaseDir makes relative paths (icon, images, release_notes, local
+// release_source) resolve against dir instead of the config file's directory.
+// It applies to every app of an apps: list.
+func (c *Config) SetBaseDir(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid base directory: %w", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("invalid base directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid base directory: %s is not a directory", dir)
+	}
+	for _, app := range append([]*Config{c}, c.Apps...) {
+		app.BaseDir = abs
+	}
+	return nil
+}
+
+// checkPubkey returns an error when the config's pubkey does not match SIGN_WITH.
+func (c *Config) checkPubkey() error {
+	if c.Pubkey == "" {
+		return nil
+	}
+	signWith := GetSignWith()
+	if signWith == "" {
+		return nil
+	}
+	signerNpub := ResolvePubkeyFromSignWith(signWith)
+	if signerNpub != "" && signerNpub != c.Pubkey {
+		return fmt.Errorf(
+			"pubkey mismatch: zapstore.yaml has pubkey %s but SIGN_WITH resolves to %s.\n"+
+				"Either update zapstore.yaml or set the correct SIGN_WITH.",
+			c.Pubkey, signerNpub,
+		)
+	}
+	return nil
+}
+
 // Parse reads and parses config from a reader.
 func Parse(r io.Reader) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	cfg, err := parseNode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand the apps: list of a monorepo config
+	if err := cfg.parseApps(&doc); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// parseNode decodes a YAML node into a Config and resolves its derived fields.
+func parseNode(node *yaml.Node) (*Config, error) {
 	var cfg Config
-	decoder := yaml.NewDecoder(r)
-	if err := decoder.Decode(&cfg); err != nil {
+	if err := node.Decode(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
@@ -312,6 +520,123 @@ func Parse(r io.Reader) (*Config, error) {
 	return &cfg, nil
 }
 
+// appSharedKeys are top-level keys an apps: entry may not override, because all
+// apps are selected from the same fetched release.
+var appSharedKeys = map[string]bool{
+	"release_source":       true,
+	"release_filter":       true,
+	"min_release_age":      true,
+	"include_pre_releases": true,
+}
+
+// parseApps expands the apps: list into Apps. Each entry is merged over the
+// top-level mapping, so any field it does not set is inherited.
+func (c *Config) parseApps(doc *yaml.Node) error {
+	if c.AppsRaw.Kind == 0 {
+		return nil
+	}
+	if c.AppsRaw.Kind != yaml.SequenceNode {
+		return fmt.Errorf("apps must be a list")
+	}
+
+	top := doc
+	if top.Kind == yaml.DocumentNode && len(top.Content) > 0 {
+		top = top.Content[0]
+	}
+
+	seen := make(map[string]bool)
+	for i, entry := range c.AppsRaw.Content {
+		if entry.Kind != yaml.MappingNode {
+			return fmt.Errorf("apps[%d] must be a mapping", i)
+		}
+
+		own := make(map[string]bool)
+		for j := 0; j+1 < len(entry.Content); j += 2 {
+			key := entry.Content[j].Value
+			if key == "apps" {
+				return fmt.Errorf("apps[%d]: apps cannot be nested", i)
+			}
+			if appSharedKeys[key] {
+				return fmt.Errorf("apps[%d]: %s is shared by all apps and must be set at the top level", i, key)
+			}
+			// Without release_source, repository is where releases are fetched from
+			if key == "repository" && c.ReleaseSource == nil {
+				return fmt.Errorf("apps[%d]: repository is the shared release source; set release_source at the top level to override it per app", i)
+			}
+			own[key] = true
+		}
+		if !own["name"] {
+			return fmt.Errorf("apps[%d]: name is required", i)
+		}
+
+		merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for j := 0; j+1 < len(top.Content); j += 2 {
+			key := top.Content[j].Value
+			if key == "apps" || own[key] {
+				continue
+			}
+			merged.Content = append(merged.Content, top.Content[j], top.Content[j+1])
+		}
+		merged.Content = append(merged.Content, entry.Content...)
+
+		app, err := parseNode(merged)
+		if err != nil {
+			return fmt.Errorf("apps[%d]: %w", i, err)
+		}
+		if seen[app.Name] {
+			return fmt.Errorf("apps[%d]: duplicate app name %q", i, app.Name)
+		}
+		seen[app.Name] = true
+		c.Apps = append(c.Apps, app)
+	}
+	return nil
+}
+
+// SelectApps returns the apps named in only (all apps when only is empty).
+// Names are matched case-insensitively; an unknown name is an error.
+func (c *Config) SelectApps(only []string) ([]*Config, error) {
+	if len(only) == 0 {
+		return c.Apps, nil
+	}
+	if len(c.Apps) == 0 {
+		return nil, fmt.Errorf("--only requires an apps: list in the config")
+	}
+
+	var selected []*Config
+	for _, name := range only {
+		var found *Config
+		for _, app := range c.Apps {
+			if strings.EqualFold(app.Name, name) {
+				found = app
+				break
+			}
+		}
+		if found == nil {
+			names := make([]string, len(c.Apps))
+			for i, app := range c.Apps {
+				names[i] = app.Name
+			}
+			return nil, fmt.Errorf("no app named %q (apps: %s)", name, strings.Join(names, ", "))
+		}
+		if !slices.Contains(selected, found) {
+			selected = append(selected, found)
+		}
+	}
+	return selected, nil
+}
+
+// VariantPattern returns the pattern of the named variant (--variant).
+func (c *Config) VariantPattern(name string) (string, error) {
+	if pattern, ok := c.Variants[name]; ok {
+		return pattern, nil
+	}
+	if len(c.Variants) == 0 {
+		return "", fmt.Errorf("--variant %s requires a variants: map in the config", name)
+	}
+	names := slices.Sorted(maps.Keys(c.Variants))
+	return "", fmt.Errorf("no variant named %q (variants: %s)", name, strings.Join(names, ", "))
+}
+
 // parseRepository parses the repository field, which can be a URL or NIP-34 naddr.
 func (c *Config) parseRepository() error {
 	if c.Repository == "" {
@@ -408,6 +733,8 @@ func (c *Config) parseReleaseSource() error {
 			AssetURL:    web.AssetURL,
 			Version:     web.Version,
 			Asset:       web.Asset,
+			Referer:     web.Referer,
+			Headers:     web.Headers,
 		}
 
 	default:
@@ -439,47 +766,253 @@ func isLocalPath(value string) bool {
 }
 
 // Validate checks if the config has required fields and valid URLs.
+// It returns the first problem found; ValidateAll reports all of them.
 func (c *Config) Validate() error {
+	if errs := c.ValidateAll(); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateAll checks the config like Validate and returns every problem found.
+func (c *Config) ValidateAll() []error {
+	var errs []error
 	if c.Repository == "" && c.ReleaseSource == nil {
-		return fmt.Errorf("no source specified: need 'repository' or 'release_source'")
+		errs = append(errs, fmt.Errorf("no source specified: need 'repository' or 'release_source'"))
 	}
 
 	// Validate repository URL if provided (skip if it's an naddr)
 	if c.Repository != "" && c.NIP34Repo == nil {
 		if err := ValidateURL(c.Repository); err != nil {
-			return fmt.Errorf("invalid repository URL: %w", err)
+			errs = append(errs, fmt.Errorf("invalid repository URL: %w", err))
 		}
 	}
 
 	// Validate release_source URL if it's a simple string URL
 	if c.ReleaseSource != nil && !c.ReleaseSource.IsWebSource && c.ReleaseSource.URL != "" {
 		if err := ValidateURL(c.ReleaseSource.URL); err != nil {
-			return fmt.Errorf("invalid release_source URL: %w", err)
+			errs = append(errs, fmt.Errorf("invalid release_source URL: %w", err))
+		}
+	}
+
+	// Validate download headers
+	if c.ReleaseSource != nil {
+		headers := c.ReleaseSource.DownloadHeaders()
+		for _, name := range slices.Sorted(maps.Keys(headers)) {
+			if value := headers[name]; name == "" || strings.ContainsAny(name, " :\r\n") || strings.ContainsAny(value, "\r\n") {
+				errs = append(errs, fmt.Errorf("invalid release_source header %q", name))
+			}
 		}
 	}
 
 	// Validate web source version extractors
 	if c.ReleaseSource != nil && c.ReleaseSource.IsWebSource {
 		if err := c.ReleaseSource.Validate(); err != nil {
-			return fmt.Errorf("invalid release_source: %w", err)
+			errs = append(errs, fmt.Errorf("invalid release_source: %w", err))
+		}
+	}
+
+	// Validate match regex pattern
+	if c.Match != "" {
+		if _, err := CompilePattern("match", c.Match); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
 	// Validate variants regex patterns
-	for name, pattern := range c.Variants {
-		if _, err := regexp.Compile(pattern); err != nil {
-			return fmt.Errorf("invalid variant %q regex pattern %q: %w", name, pattern, err)
+	for _, name := range slices.Sorted(maps.Keys(c.Variants)) {
+		if _, err := CompilePattern(fmt.Sprintf("variant %q", name), c.Variants[name]); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
 	// Validate release_filter regex pattern
 	if c.ReleaseFilter != "" {
 		if _, err := regexp.Compile(c.ReleaseFilter); err != nil {
-			return fmt.Errorf("invalid release_filter pattern %q: %w", c.ReleaseFilter, err)
+			errs = append(errs, fmt.Errorf("invalid release_filter pattern %q: %w", c.ReleaseFilter, err))
 		}
 	}
 
-	return nil
+	// Validate the release age window
+	if _, err := c.MinReleaseAgeDuration(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Validate images_exclude glob patterns
+	for _, pattern := range c.ImagesExclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid images_exclude pattern %q: %w", pattern, err))
+		}
+	}
+
+	// Validate apk: and repo: media references
+	if path, ok := RepoFilePath(c.Icon); ok {
+		if err := c.validateRepoFile("icon", path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, image := range c.Images {
+		if image == IconFromAPK {
+			errs = append(errs, fmt.Errorf("invalid images entry %q: only icon can use the APK's embedded image", image))
+		}
+		if path, ok := RepoFilePath(image); ok {
+			if err := c.validateRepoFile("images", path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// Validate network allowlist host patterns
+	for _, pattern := range c.NetworkAllowlist {
+		if err := netpolicy.ValidatePattern(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid network_allowlist entry: %w", err))
+		}
+	}
+
+	// Validate icon size limit; icons are downscaled to media.IconMaxWidth
+	if c.IconMinSize < 0 || c.IconMinSize > media.IconMaxWidth {
+		errs = append(errs, fmt.Errorf("invalid icon_min_size %d: must be between 1 and %d", c.IconMinSize, media.IconMaxWidth))
+	}
+
+	if c.IconRasterSize < 0 || c.IconRasterSize > 2048 {
+		errs = append(errs, fmt.Errorf("invalid icon_raster_size %d: must be between 1 and 2048", c.IconRasterSize))
+	}
+
+	// Validate relay discovery mode
+	switch c.Relays {
+	case "", "nip65", "nip65-only":
+	default:
+		errs = append(errs, fmt.Errorf("invalid relays %q: must be nip65 or nip65-only", c.Relays))
+	}
+
+	// Validate per-kind relay routes
+	for i, route := range c.RelayRoutes {
+		if len(route.Kinds) == 0 {
+			errs = append(errs, fmt.Errorf("relay_routes[%d] requires kinds", i))
+		}
+		for _, kind := range route.Kinds {
+			if kind < 0 || kind > 65535 {
+				errs = append(errs, fmt.Errorf("relay_routes[%d]: invalid event kind %d", i, kind))
+			}
+		}
+		if len(route.Relays) == 0 {
+			errs = append(errs, fmt.Errorf("relay_routes[%d] requires relays", i))
+		}
+		for _, relay := range route.Relays {
+			if u, err := url.Parse(relay); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("relay_routes[%d]: invalid relay URL %q: must be ws:// or wss://", i, relay))
+			}
+		}
+	}
+
+	// Validate Blossom server URL
+	if c.BlossomURL != "" {
+		if err := ValidateURL(c.BlossomURL); err != nil {
+			errs = append(errs, fmt.Errorf("invalid blossom_url: %w", err))
+		}
+	}
+	if c.BlossomPublicURL != "" {
+		if err := ValidateURL(strings.ReplaceAll(c.BlossomPublicURL, "{hash}", "hash")); err != nil {
+			errs = append(errs, fmt.Errorf("invalid blossom_public_url: %w", err))
+		}
+	}
+
+	if c.ReleaseNotesExcerpt != "" && !c.ReleaseNotesEvent {
+		errs = append(errs, fmt.Errorf("release_notes_excerpt requires release_notes_event: true"))
+	}
+
+	// Validate detached signature settings
+	if sig := c.DetachedSignature; sig != nil {
+		switch sig.Type {
+		case "minisign", "gpg":
+		default:
+			errs = append(errs, fmt.Errorf("invalid detached_signature type %q: must be minisign or gpg", sig.Type))
+		}
+		if sig.KeyEnv == "" {
+			errs = append(errs, fmt.Errorf("detached_signature requires key_env"))
+		}
+	}
+
+	if c.APKLint != nil {
+		switch c.APKLint.FailOn {
+		case "", "info", "warning", "error", "none":
+		default:
+			errs = append(errs, fmt.Errorf("invalid apk_lint fail_on %q: must be info, warning, error or none", c.APKLint.FailOn))
+		}
+	}
+
+	return errs
+}
+
+// MinReleaseAgeDuration parses min_release_age: a Go duration such as "90m" or
+// "1h30m", or a whole number of days such as "2d". Returns 0 when unset.
+func (c *Config) MinReleaseAgeDuration() (time.Duration, error) {
+	if c.MinReleaseAge == "" {
+		return 0, nil
+	}
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(c.MinReleaseAge, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(c.MinReleaseAge)
+	}
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid min_release_age %q: use a duration such as 30m, 1h or 2d", c.MinReleaseAge)
+	}
+	return d, nil
+}
+
+// AllowedHosts returns the network allowlist from config merged with ZSP_ALLOWED_HOSTS.
+func (c *Config) AllowedHosts() []string {
+	hosts := netpolicy.ParsePatterns(GetEnv(netpolicy.EnvAllowedHosts))
+	return append(hosts, c.NetworkAllowlist...)
+}
+
+// ApplyImagesExclude removes images matching ImagesExclude from Images.
+// Returns the removed images.
+func (c *Config) ApplyImagesExclude() []string {
+	if len(c.ImagesExclude) == 0 || len(c.Images) == 0 {
+		return nil
+	}
+
+	var kept, removed []string
+	for i, image := range c.Images {
+		if c.isImageExcluded(i, image) {
+			removed = append(removed, image)
+		} else {
+			kept = append(kept, image)
+		}
+	}
+	c.Images = kept
+	return removed
+}
+
+// isImageExcluded reports whether the image at index i matches an images_exclude entry.
+func (c *Config) isImageExcluded(i int, image string) bool {
+	name := image
+	if u, err := url.Parse(image); err == nil && u.Path != "" {
+		name = u.Path
+	}
+	name = path.Base(name)
+
+	for _, entry := range c.ImagesExclude {
+		if n, err := strconv.Atoi(entry); err == nil {
+			if n == i+1 {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(entry, image); ok {
+			return true
+		}
+		if ok, _ := path.Match(entry, name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // Validate checks if the ReleaseSource configuration is valid.
@@ -771,6 +1304,39 @@ func GetFDroidPackageID(rawURL string) string {
 	return ""
 }
 
+// IconFromAPK as the icon selects the icon embedded in the APK. Otherwise the
+// APK icon is only a fallback when neither the config nor a metadata source
+// supplies one.
+const IconFromAPK = "apk:"
+
+// RepoFileScheme prefixes an icon or images entry naming a file in the
+// repository, fetched at the release tag (repo:fastlane/metadata/android/en-US/images/icon.png).
+const RepoFileScheme = "repo:"
+
+// RepoFilePath returns the repository path of a repo: reference, and whether
+// ref is one.
+func RepoFilePath(ref string) (string, bool) {
+	path, ok := strings.CutPrefix(ref, RepoFileScheme)
+	if !ok {
+		return "", false
+	}
+	return strings.Trim(path, "/"), true
+}
+
+// validateRepoFile checks the path of a repo: reference used by field.
+func (c *Config) validateRepoFile(field, path string) error {
+	if path == "" {
+		return fmt.Errorf("invalid %s %q: missing repository path", field, RepoFileScheme)
+	}
+	if slices.Contains(strings.Split(path, "/"), "..") {
+		return fmt.Errorf("invalid %s %q: path must stay inside the repository", field, RepoFileScheme+path)
+	}
+	if c.Repository == "" && (c.ReleaseSource == nil || c.ReleaseSource.IsLocal() || c.ReleaseSource.IsWebSource) {
+		return fmt.Errorf("invalid %s %q: repo: paths need a repository", field, RepoFileScheme+path)
+	}
+	return nil
+}
+
 // GetGitHubRepo extracts owner/repo from a GitHub URL.
 func GetGitHubRepo(url string) string {
 	// Handle: https://github.com/owner/repo
@@ -851,12 +1417,20 @@ func GetRelayURLs() string {
 	return GetEnv("RELAY_URLS")
 }
 
+// KeychainSignerPrefix prefixes a SIGN_WITH value naming an npub whose nsec
+// is stored in the OS keyring (see zsp keys generate --keyring).
+const KeychainSignerPrefix = "keychain:"
+
 // ResolvePubkeyFromSignWith derives the npub from a SIGN_WITH value.
-// Supports nsec1... and npub1... synchronously.
+// Supports nsec1..., npub1... and keychain:npub1... synchronously.
 // Returns empty string for bunker/browser (requires async resolution).
 func ResolvePubkeyFromSignWith(signWith string) string {
 	signWith = strings.TrimSpace(signWith)
 
+	if npub, ok := strings.CutPrefix(signWith, KeychainSignerPrefix); ok {
+		signWith = npub
+	}
+
 	if strings.HasPrefix(signWith, "npub1") {
 		return signWith
 	}
@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParse(t *testing.T) {
@@ -290,6 +291,14 @@ func TestValidate(t *testing.T) {
 			config:  Config{ReleaseSource: &ReleaseSource{LocalPath: "./app.apk"}},
 			wantErr: false,
 		},
+		{
+			name: "invalid images_exclude pattern",
+			config: Config{
+				Repository:    "https://github.com/user/app",
+				ImagesExclude: []string{"[promo"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -302,6 +311,342 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestApplyImagesExclude(t *testing.T) {
+	images := []string{
+		"https://example.com/shots/01-main.png",
+		"https://example.com/shots/feature-graphic.png?w=1024",
+		"https://example.com/shots/02-settings.png",
+		"./screenshots/promo_banner.jpg",
+	}
+
+	tests := []struct {
+		name    string
+		exclude []string
+		want    []string
+	}{
+		{
+			name: "no patterns",
+			want: images,
+		},
+		{
+			name:    "glob on filename",
+			exclude: []string{"*feature*", "*promo*"},
+			want:    []string{images[0], images[2]},
+		},
+		{
+			name:    "by position",
+			exclude: []string{"1", "3"},
+			want:    []string{images[1], images[3]},
+		},
+		{
+			name:    "glob on full url",
+			exclude: []string{"https://example.com/shots/0*"},
+			want:    []string{images[1], images[3]},
+		},
+		{
+			name:    "no matches",
+			exclude: []string{"*.webp", "9"},
+			want:    images,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Images:        append([]string(nil), images...),
+				ImagesExclude: tt.exclude,
+			}
+			removed := cfg.ApplyImagesExclude()
+			if len(cfg.Images) != len(tt.want) {
+				t.Fatalf("Images = %v, want %v", cfg.Images, tt.want)
+			}
+			for i := range tt.want {
+				if cfg.Images[i] != tt.want[i] {
+					t.Errorf("Images[%d] = %q, want %q", i, cfg.Images[i], tt.want[i])
+				}
+			}
+			if len(removed)+len(cfg.Images) != len(images) {
+				t.Errorf("removed %d images, kept %d, total %d", len(removed), len(cfg.Images), len(images))
+			}
+		})
+	}
+}
+
+func TestParseImagesExclude(t *testing.T) {
+	cfg, err := Parse(strings.NewReader(`repository: https://github.com/user/app
+images_exclude: ["*promo*", 2]
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(cfg.ImagesExclude) != 2 || cfg.ImagesExclude[0] != "*promo*" || cfg.ImagesExclude[1] != "2" {
+		t.Errorf("ImagesExclude = %v", cfg.ImagesExclude)
+	}
+}
+
+func TestParseApps(t *testing.T) {
+	cfg, err := Parse(strings.NewReader(`repository: https://github.com/acme/monorepo
+license: MIT
+tags: [shop]
+apps:
+  - name: Shop
+    match: "shop-.*\\.apk$"
+  - name: Kiosk
+    match: "kiosk-.*\\.apk$"
+    tags: [kiosk, pos]
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(cfg.Apps) != 2 {
+		t.Fatalf("got %d apps, want 2", len(cfg.Apps))
+	}
+	shop, kiosk := cfg.Apps[0], cfg.Apps[1]
+	if shop.Name != "Shop" || shop.Match != `shop-.*\.apk$` || shop.License != "MIT" || shop.Repository != cfg.Repository {
+		t.Errorf("shop app = %+v, want inherited license and repository", shop)
+	}
+	if len(kiosk.Tags) != 2 || kiosk.Tags[0] != "kiosk" || len(shop.Tags) != 1 {
+		t.Errorf("tags: shop %v, kiosk %v", shop.Tags, kiosk.Tags)
+	}
+	if len(kiosk.Apps) != 0 {
+		t.Error("app entries should not carry the apps list")
+	}
+
+	selected, err := cfg.SelectApps([]string{"kiosk", "Kiosk"})
+	if err != nil || len(selected) != 1 || selected[0] != kiosk {
+		t.Errorf("SelectApps(kiosk) = %v, %v", selected, err)
+	}
+	if _, err := cfg.SelectApps([]string{"admin"}); err == nil {
+		t.Error("SelectApps() with unknown name should fail")
+	}
+}
+
+func TestSetBaseDir(t *testing.T) {
+	cfg, err := Parse(strings.NewReader("repository: https://github.com/a/b\napps:\n  - name: A\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := cfg.SetBaseDir(dir); err != nil {
+		t.Fatalf("SetBaseDir() error: %v", err)
+	}
+	if cfg.BaseDir != dir || cfg.Apps[0].BaseDir != dir {
+		t.Errorf("BaseDir = %q, app BaseDir = %q, want %q", cfg.BaseDir, cfg.Apps[0].BaseDir, dir)
+	}
+	if err := cfg.SetBaseDir(dir + "/missing"); err == nil {
+		t.Error("SetBaseDir() with a missing directory should fail")
+	}
+}
+
+func TestVariantPattern(t *testing.T) {
+	cfg := &Config{Variants: map[string]string{"fdroid": `-fdroid-`, "google": `-google-`}}
+	if pattern, err := cfg.VariantPattern("google"); err != nil || pattern != `-google-` {
+		t.Errorf("VariantPattern(google) = %q, %v", pattern, err)
+	}
+	if _, err := cfg.VariantPattern("huawei"); err == nil || !strings.Contains(err.Error(), "fdroid, google") {
+		t.Errorf("expected unknown variant error listing variants, got %v", err)
+	}
+	if _, err := (&Config{}).VariantPattern("google"); err == nil {
+		t.Error("expected error without a variants map")
+	}
+}
+
+func TestMinReleaseAgeDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, true},
+		{"90m", 90 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"2d", 48 * time.Hour, true},
+		{"0s", 0, true},
+		{"-1h", 0, false},
+		{"1.5d", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Repository: "https://github.com/user/app", MinReleaseAge: tt.value}
+		got, err := cfg.MinReleaseAgeDuration()
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("MinReleaseAgeDuration(%q) = %v, %v; want %v, ok=%v", tt.value, got, err, tt.want, tt.ok)
+		}
+		if err := cfg.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate() with min_release_age %q: %v", tt.value, err)
+		}
+	}
+}
+
+func TestParseAppsErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing name":         "repository: https://github.com/a/b\napps:\n  - match: x\n",
+		"duplicate name":       "repository: https://github.com/a/b\napps:\n  - name: A\n  - name: A\n",
+		"shared release":       "repository: https://github.com/a/b\napps:\n  - name: A\n    release_source: https://f-droid.org/packages/a\n",
+		"repository as source": "repository: https://github.com/a/b\napps:\n  - name: A\n    repository: https://github.com/a/c\n",
+		"not a list":           "repository: https://github.com/a/b\napps: {name: A}\n",
+		"shared release age":   "repository: https://github.com/a/b\napps:\n  - name: A\n    min_release_age: 1h\n",
+	}
+	for name, yml := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(yml)); err == nil {
+				t.Error("Parse() succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestParseReleaseSourceDownloadHeaders(t *testing.T) {
+	cfg, err := Parse(strings.NewReader(`repository: https://github.com/user/app
+release_source:
+  url: https://cdn.example.com/app.apk
+  referer: https://example.com/download
+  headers:
+    X-Token: abc
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	headers := cfg.ReleaseSource.DownloadHeaders()
+	if headers["Referer"] != "https://example.com/download" || headers["X-Token"] != "abc" {
+		t.Errorf("DownloadHeaders() = %v", headers)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	cfg.ReleaseSource.Headers["Bad Name"] = "x"
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected Validate() to reject header name with space")
+	}
+}
+
+func TestValidateRelays(t *testing.T) {
+	for _, mode := range []string{"", "nip65", "nip65-only"} {
+		cfg := &Config{Repository: "https://github.com/user/app", Relays: mode}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with relays %q: %v", mode, err)
+		}
+	}
+	cfg := &Config{Repository: "https://github.com/user/app", Relays: "nip66"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected Validate() to reject unknown relays mode")
+	}
+}
+
+func TestValidateBlossomURL(t *testing.T) {
+	for _, u := range []string{"https://cdn.example.com", "http://localhost:3000"} {
+		cfg := &Config{Repository: "https://github.com/user/app", BlossomURL: u}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with blossom_url %q: %v", u, err)
+		}
+	}
+	for _, u := range []string{"cdn.example.com", "ftp://cdn.example.com", "http://cdn.example.com"} {
+		cfg := &Config{Repository: "https://github.com/user/app", BlossomURL: u}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "blossom_url") {
+			t.Errorf("Validate() with blossom_url %q = %v, want a blossom_url error", u, err)
+		}
+	}
+}
+
+func TestValidateDetachedSignature(t *testing.T) {
+	tests := []struct {
+		sig     DetachedSignature
+		wantErr string
+	}{
+		{DetachedSignature{Type: "minisign", KeyEnv: "MINISIGN_KEY"}, ""},
+		{DetachedSignature{Type: "gpg", KeyEnv: "GPG_KEY", PasswordEnv: "GPG_PASSWORD"}, ""},
+		{DetachedSignature{Type: "signify", KeyEnv: "KEY"}, "must be minisign or gpg"},
+		{DetachedSignature{Type: "minisign"}, "requires key_env"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Repository: "https://github.com/user/app", DetachedSignature: &tt.sig}
+		err := cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate() with %+v: %v", tt.sig, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate() with %+v = %v, want error containing %q", tt.sig, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateRelayRoutes(t *testing.T) {
+	tests := []struct {
+		route   RelayRoute
+		wantErr string
+	}{
+		{RelayRoute{Kinds: []int{3063}, Relays: []string{"wss://archive.example.com"}}, ""},
+		{RelayRoute{Kinds: []int{30509}, Relays: []string{"ws://localhost:7777"}, Replace: true}, ""},
+		{RelayRoute{Relays: []string{"wss://archive.example.com"}}, "requires kinds"},
+		{RelayRoute{Kinds: []int{3063}}, "requires relays"},
+		{RelayRoute{Kinds: []int{-1}, Relays: []string{"wss://archive.example.com"}}, "invalid event kind"},
+		{RelayRoute{Kinds: []int{3063}, Relays: []string{"https://archive.example.com"}}, "must be ws:// or wss://"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Repository: "https://github.com/user/app", RelayRoutes: []RelayRoute{tt.route}}
+		err := cfg.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate() with %+v: %v", tt.route, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate() with %+v = %v, want error containing %q", tt.route, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateMediaRefs(t *testing.T) {
+	valid := []*Config{
+		{Repository: "https://github.com/user/app", Icon: "apk:"},
+		{Repository: "https://github.com/user/app", Icon: "repo:fastlane/metadata/android/en-US/images/icon.png"},
+		{ReleaseSource: &ReleaseSource{URL: "https://codeberg.org/user/app"}, Images: []string{"repo:/docs/shot.png", "shot2.png"}},
+	}
+	for _, cfg := range valid {
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() icon %q images %v: %v", cfg.Icon, cfg.Images, err)
+		}
+	}
+
+	invalid := []struct {
+		cfg  *Config
+		want string
+	}{
+		{&Config{Repository: "https://github.com/user/app", Icon: "repo:"}, "missing repository path"},
+		{&Config{Repository: "https://github.com/user/app", Images: []string{"repo:../secret.png"}}, "inside the repository"},
+		{&Config{Repository: "https://github.com/user/app", Images: []string{"apk:"}}, "only icon"},
+		{&Config{ReleaseSource: &ReleaseSource{LocalPath: "./app.apk"}, Icon: "repo:icon.png"}, "need a repository"},
+	}
+	for _, tt := range invalid {
+		err := tt.cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate() icon %q images %v = %v, want error containing %q", tt.cfg.Icon, tt.cfg.Images, err, tt.want)
+		}
+	}
+}
+
+func TestRepoFilePath(t *testing.T) {
+	tests := []struct {
+		ref    string
+		want   string
+		wantOK bool
+	}{
+		{"repo:fastlane/metadata/android/en-US/images/icon.png", "fastlane/metadata/android/en-US/images/icon.png", true},
+		{"repo:/assets/icon.png", "assets/icon.png", true},
+		{"repo:", "", true},
+		{"apk:", "", false},
+		{"assets/icon.png", "", false},
+		{"https://example.com/repo:icon.png", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := RepoFilePath(tt.ref)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("RepoFilePath(%q) = %q, %v; want %q, %v", tt.ref, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
 func TestDetectSourceType(t *testing.T) {
 	tests := []struct {
 		url  string
@@ -1103,6 +1448,52 @@ func TestValidateConfigCases(t *testing.T) {
 }
 
 // TestSourceTypeString covers SourceType.String() method
+func TestValidateAllReportsEveryProblem(t *testing.T) {
+	cfg := Config{
+		Repository:    "ftp://github.com/user/app",
+		Match:         "([",
+		Variants:      map[string]string{"b": "[", "a": "("},
+		ReleaseFilter: "*",
+		Relays:        "all",
+	}
+	errs := cfg.ValidateAll()
+	if len(errs) != 6 {
+		t.Fatalf("ValidateAll() returned %d errors, want 6: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[2].Error(), `variant "a"`) {
+		t.Errorf("variants should be checked in name order, got %v", errs[2])
+	}
+	if err := cfg.Validate(); err == nil || err.Error() != errs[0].Error() {
+		t.Errorf("Validate() = %v, want the first ValidateAll error %v", err, errs[0])
+	}
+}
+
+func TestLint(t *testing.T) {
+	clean := Config{
+		License:       "GPL-3.0-or-later OR (Apache-2.0 WITH LLVM-exception)",
+		Tags:          []string{"nostr", "social"},
+		SupportedNIPs: []string{"01", "44", "7D"},
+	}
+	if warnings := clean.Lint(); len(warnings) != 0 {
+		t.Errorf("Lint() on a clean config = %v, want none", warnings)
+	}
+
+	cfg := Config{
+		License:       "GNU GPL v3",
+		Tags:          []string{"#nostr", "two words", "Nostr"},
+		SupportedNIPs: []string{"1", "NIP-44", "07"},
+		Changelog:     "CHANGELOG.md",
+	}
+	var fields []string
+	for _, w := range cfg.Lint() {
+		fields = append(fields, w.Field)
+	}
+	want := []string{"license", "tags[0]", "tags[1]", "tags[2]", "supported_nips[0]", "supported_nips[1]", "changelog"}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("Lint() fields = %v, want %v", fields, want)
+	}
+}
+
 func TestSourceTypeString(t *testing.T) {
 	tests := []struct {
 		st   SourceType
@@ -1280,3 +1671,19 @@ func TestLoadAllFixtures(t *testing.T) {
 		})
 	}
 }
+
+func TestResolvePubkeyFromSignWith(t *testing.T) {
+	const npub = "npub180cvv07tjdrrgpa0j7j7tmnyl2yr6yr7l8j4s3evf6u64th6gkwsyjh6w6"
+	tests := map[string]string{
+		npub:                              npub,
+		"keychain:" + npub:                npub,
+		" " + KeychainSignerPrefix + npub: npub,
+		"bunker://abc?relay=wss://r":      "",
+		"browser":                         "",
+	}
+	for signWith, want := range tests {
+		if got := ResolvePubkeyFromSignWith(signWith); got != want {
+			t.Errorf("ResolvePubkeyFromSignWith(%q) = %q, want %q", signWith, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zapstore.yaml")
+	input := "# my app\nrepository: https://github.com/user/app\nname: acme-wallet-android # from GitHub\ntags:\n  - old\n"
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := SetFields(path, map[string]string{
+		"name":    "Acme Wallet",
+		"tags":    "bitcoin, wallet",
+		"summary": "A wallet",
+	})
+	if err != nil {
+		t.Fatalf("SetFields() error: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "Acme Wallet" || cfg.Summary != "A wallet" || strings.Join(cfg.Tags, ",") != "bitcoin,wallet" {
+		t.Errorf("after SetFields: name %q summary %q tags %v", cfg.Name, cfg.Summary, cfg.Tags)
+	}
+
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# my app", "# from GitHub"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("comment %q not preserved:\n%s", want, data)
+		}
+	}
+}
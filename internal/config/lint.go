@@ -0,0 +1,84 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Warning describes a config value that is accepted but likely wrong.
+type Warning struct {
+	Field   string // e.g. "license", "tags[2]"
+	Message string
+}
+
+// spdxIDPattern matches a single SPDX license identifier or LicenseRef.
+var spdxIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.+-]*$`)
+
+// nipPattern matches a NIP identifier as listed in the NIPs repository ("01", "44", "7D").
+var nipPattern = regexp.MustCompile(`^[0-9A-F]{2}$`)
+
+// Lint reports values that pass Validate but are probably mistakes:
+// a license that is not an SPDX expression, malformed or duplicate tags,
+// and supported_nips entries that are not NIP identifiers.
+func (c *Config) Lint() []Warning {
+	var warnings []Warning
+
+	if c.License != "" && !isSPDXExpression(c.License) {
+		warnings = append(warnings, Warning{"license", fmt.Sprintf("%q is not an SPDX license expression (e.g. MIT, GPL-3.0-or-later, Apache-2.0 OR MIT)", c.License)})
+	}
+
+	seen := make(map[string]bool)
+	for i, tag := range c.Tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		switch {
+		case strings.HasPrefix(tag, "#"):
+			warnings = append(warnings, Warning{field, fmt.Sprintf("%q starts with #; tags are published without it", tag)})
+		case strings.ContainsAny(tag, " \t"):
+			warnings = append(warnings, Warning{field, fmt.Sprintf("%q contains whitespace; use one tag per entry", tag)})
+		}
+		key := strings.ToLower(strings.TrimPrefix(tag, "#"))
+		if seen[key] {
+			warnings = append(warnings, Warning{field, fmt.Sprintf("duplicate tag %q", tag)})
+		}
+		seen[key] = true
+	}
+
+	for i, nip := range c.SupportedNIPs {
+		if !nipPattern.MatchString(nip) {
+			warnings = append(warnings, Warning{fmt.Sprintf("supported_nips[%d]", i), fmt.Sprintf("%q is not a NIP identifier (e.g. \"01\", \"44\", \"7D\")", nip)})
+		}
+	}
+
+	if c.Changelog != "" {
+		warnings = append(warnings, Warning{"changelog", "is deprecated, use release_notes (zsp config migrate rewrites it)"})
+	}
+
+	return warnings
+}
+
+// isSPDXExpression reports whether s looks like an SPDX license expression:
+// identifiers joined by AND, OR or WITH, optionally parenthesized.
+func isSPDXExpression(s string) bool {
+	s = strings.NewReplacer("(", " ", ")", " ").Replace(s)
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return false
+	}
+	expectID := true
+	for _, f := range fields {
+		if expectID {
+			if !spdxIDPattern.MatchString(f) || isSPDXOperator(f) {
+				return false
+			}
+		} else if !isSPDXOperator(f) {
+			return false
+		}
+		expectID = !expectID
+	}
+	return !expectID
+}
+
+func isSPDXOperator(s string) bool {
+	return s == "AND" || s == "OR" || s == "WITH"
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// exampleMatchPattern is shown in pattern errors as a known-good regex.
+const exampleMatchPattern = `.*arm64.*\.apk$`
+
+// IsGlobPattern reports whether a pattern looks like a shell glob rather than a regex.
+// A pattern is glob-like when it only uses the metacharacters * ? . and either starts
+// with * or has a * or ? that does not follow a . (as it would in .* or .?).
+func IsGlobPattern(pattern string) bool {
+	if pattern == "" {
+		return false
+	}
+	if strings.ContainsAny(pattern, `\^$+()[]{}|`) {
+		return false
+	}
+	if pattern[0] == '*' {
+		return true
+	}
+	for i := 1; i < len(pattern); i++ {
+		if (pattern[i] == '*' || pattern[i] == '?') && pattern[i-1] != '.' {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobToRegex translates a glob pattern into an anchored regex.
+// * matches any run of characters, ? matches one character, everything else is literal.
+func GlobToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// CompilePattern compiles a regex from a config field, returning an error that names
+// the field, the pattern, the position of the problem, and an example of a valid pattern.
+func CompilePattern(field, pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err == nil {
+		return re, nil
+	}
+
+	var synErr *syntax.Error
+	if errors.As(err, &synErr) {
+		// Expr is the offending fragment; when it is the whole pattern there is no useful position
+		pos := strings.Index(pattern, synErr.Expr)
+		if pos >= 0 && synErr.Expr != pattern {
+			return nil, fmt.Errorf("invalid %s pattern %q: %s at position %d (%q); patterns are regular expressions, e.g. '%s'",
+				field, pattern, synErr.Code, pos, synErr.Expr, exampleMatchPattern)
+		}
+		return nil, fmt.Errorf("invalid %s pattern %q: %s; patterns are regular expressions, e.g. '%s'",
+			field, pattern, synErr.Code, exampleMatchPattern)
+	}
+	return nil, fmt.Errorf("invalid %s pattern %q: %w", field, pattern, err)
+}
+
+// NormalizePatterns translates glob-looking match and variant patterns into anchored
+// regexes in place. Returns a notice for each pattern that was translated.
+func (c *Config) NormalizePatterns() []string {
+	var notices []string
+
+	if IsGlobPattern(c.Match) {
+		translated := GlobToRegex(c.Match)
+		notices = append(notices, fmt.Sprintf("match %q looks like a glob; using regex %q", c.Match, translated))
+		c.Match = translated
+	}
+
+	// Sort variant names for deterministic notices
+	names := make([]string, 0, len(c.Variants))
+	for name := range c.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern := c.Variants[name]
+		if IsGlobPattern(pattern) {
+			translated := GlobToRegex(pattern)
+			notices = append(notices, fmt.Sprintf("variant %q pattern %q looks like a glob; using regex %q", name, pattern, translated))
+			c.Variants[name] = translated
+		}
+	}
+
+	return notices
+}
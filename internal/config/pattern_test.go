@@ -0,0 +1,132 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestIsGlobPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{"*arm64*.apk", true},
+		{"app-*.apk", true},
+		{"app-v?.apk", true},
+		{"*", true},
+		{"arm64", false},
+		{".*arm64.*", false},
+		{`.*arm64.*\.apk$`, false},
+		{"^app-(arm64|universal)\\.apk$", false},
+		{"[a-z]*.apk", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := IsGlobPattern(tt.pattern); got != tt.want {
+				t.Errorf("IsGlobPattern(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlobToRegex(t *testing.T) {
+	tests := []struct {
+		glob     string
+		want     string
+		matches  []string
+		excludes []string
+	}{
+		{
+			glob:     "*arm64*.apk",
+			want:     `^.*arm64.*\.apk$`,
+			matches:  []string{"app-arm64-v8a.apk", "arm64.apk"},
+			excludes: []string{"app-arm64-v8a.apk.sig", "app-x86.apk", "app-arm64xapk"},
+		},
+		{
+			glob:     "app-?.apk",
+			want:     `^app-.\.apk$`,
+			matches:  []string{"app-1.apk"},
+			excludes: []string{"app-12.apk", "myapp-1.apk"},
+		},
+		{
+			glob:     "*-release+fdroid.apk",
+			want:     `^.*-release\+fdroid\.apk$`,
+			matches:  []string{"app-release+fdroid.apk"},
+			excludes: []string{"app-releasefdroid.apk"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			got := GlobToRegex(tt.glob)
+			if got != tt.want {
+				t.Fatalf("GlobToRegex(%q) = %q, want %q", tt.glob, got, tt.want)
+			}
+			re := regexp.MustCompile(got)
+			for _, name := range tt.matches {
+				if !re.MatchString(name) {
+					t.Errorf("%q should match %q", got, name)
+				}
+			}
+			for _, name := range tt.excludes {
+				if re.MatchString(name) {
+					t.Errorf("%q should not match %q", got, name)
+				}
+			}
+		})
+	}
+}
+
+func TestCompilePatternError(t *testing.T) {
+	_, err := CompilePattern("match", "app-[arm64.apk")
+	if err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+	msg := err.Error()
+	for _, want := range []string{`"app-[arm64.apk"`, "position 4", exampleMatchPattern} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q should contain %q", msg, want)
+		}
+	}
+}
+
+func TestNormalizePatterns(t *testing.T) {
+	cfg := &Config{
+		Repository: "https://github.com/user/app",
+		Match:      "*arm64*.apk",
+		Variants: map[string]string{
+			"fdroid": "*-fdroid-*.apk",
+			"google": `.*-google-.*\.apk$`,
+		},
+	}
+
+	notices := cfg.NormalizePatterns()
+	if len(notices) != 2 {
+		t.Fatalf("expected 2 notices, got %v", notices)
+	}
+	if cfg.Match != `^.*arm64.*\.apk$` {
+		t.Errorf("Match = %q", cfg.Match)
+	}
+	if cfg.Variants["fdroid"] != `^.*-fdroid-.*\.apk$` {
+		t.Errorf("fdroid variant = %q", cfg.Variants["fdroid"])
+	}
+	if cfg.Variants["google"] != `.*-google-.*\.apk$` {
+		t.Errorf("google variant should be unchanged, got %q", cfg.Variants["google"])
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() after normalize: %v", err)
+	}
+}
+
+func TestValidateMatchPattern(t *testing.T) {
+	cfg := &Config{
+		Repository: "https://github.com/user/app",
+		Match:      "(arm64",
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected Validate() to reject invalid match pattern")
+	}
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// deprecatedKeys maps deprecated top-level config keys to their current names.
+var deprecatedKeys = map[string]string{
+	"changelog": "release_notes",
+}
+
+// UpgradeConfig rewrites config YAML to the current canonical shape: zapstore-cli
+// configs are converted, deprecated keys are renamed, and release_source is collapsed
+// to its shortest equivalent form. Comments and key order are preserved.
+// Returns the rewritten YAML and a description of each change (none if already current).
+func UpgradeConfig(data []byte) ([]byte, []string, error) {
+	var changes []string
+
+	if NeedsMigration(data) {
+		result, err := MigrateConfig(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		var buf bytes.Buffer
+		if err := WriteMigratedConfigTo(&buf, result.Config); err != nil {
+			return nil, nil, err
+		}
+		data = buf.Bytes()
+		changes = append(changes, "converted zapstore-cli format")
+		changes = append(changes, result.Warnings...)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config must be a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	changes = append(changes, renameDeprecatedKeys(root)...)
+	changes = append(changes, normalizeReleaseSource(root)...)
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to write YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to write YAML: %w", err)
+	}
+
+	// Guard against a rewrite that changes what the config means
+	before, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("upgraded config does not parse: %w", err)
+	}
+	if effectiveSource(before) != effectiveSource(after) {
+		return nil, nil, fmt.Errorf("upgraded config would change the release source; please update it by hand")
+	}
+	if before.ReleaseNotes != after.ReleaseNotes {
+		return nil, nil, fmt.Errorf("upgraded config would change the release notes; please update it by hand")
+	}
+
+	return buf.Bytes(), changes, nil
+}
+
+// UpgradeConfigFile upgrades the config at path. With dryRun the file is left untouched.
+// Returns the upgraded YAML and the list of changes.
+func UpgradeConfigFile(path string, dryRun bool) ([]byte, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	out, changes, err := UpgradeConfig(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dryRun || len(changes) == 0 {
+		return out, changes, nil
+	}
+
+	backupPath := path + ".bak"
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to create backup at %s: %w", backupPath, err)
+	}
+	if err := os.WriteFile(path, out, 0644); err != nil {
+		return nil, nil, fmt.Errorf("failed to write config: %w", err)
+	}
+	return out, changes, nil
+}
+
+// MigrateFile runs zsp config migrate: it upgrades the config at path and
+// reports the changes on stderr, or nothing with jsonOutput. With dryRun the
+// upgraded YAML goes to stdout and the file is left untouched.
+func MigrateFile(path string, dryRun, jsonOutput bool) error {
+	out, changes, err := UpgradeConfigFile(path, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		os.Stdout.Write(out)
+	}
+
+	if jsonOutput {
+		return nil
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(os.Stderr, "%s is already up to date\n", path)
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Fprintf(os.Stderr, "  - %s\n", change)
+	}
+	if !dryRun {
+		fmt.Fprintf(os.Stderr, "Updated %s (backup at %s.bak)\n", path, path)
+	}
+	return nil
+}
+
+// renameDeprecatedKeys renames deprecated top-level keys in place. A deprecated key is
+// dropped when its replacement is already set.
+func renameDeprecatedKeys(root *yaml.Node) []string {
+	var changes []string
+	for i := 0; i+1 < len(root.Content); {
+		key := root.Content[i]
+		newName, deprecated := deprecatedKeys[key.Value]
+		if !deprecated {
+			i += 2
+			continue
+		}
+		if mappingValue(root, newName) != nil {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			changes = append(changes, fmt.Sprintf("removed deprecated %s (%s is already set)", key.Value, newName))
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("renamed %s to %s", key.Value, newName))
+		key.Value = newName
+		i += 2
+	}
+	return changes
+}
+
+// normalizeReleaseSource collapses release_source to its shortest equivalent form:
+// a mapping holding only url (or only asset_url for an unknown host) becomes a plain
+// string, and a release_source equal to repository is removed.
+func normalizeReleaseSource(root *yaml.Node) []string {
+	var changes []string
+
+	rs := mappingValue(root, "release_source")
+	if rs == nil {
+		return nil
+	}
+
+	if rs.Kind == yaml.MappingNode && len(rs.Content) == 2 && rs.Content[1].Kind == yaml.ScalarNode {
+		field, value := rs.Content[0].Value, rs.Content[1].Value
+		collapse := false
+		switch field {
+		case "url":
+			collapse = !isLocalPath(value) && DetectSourceType(value) != SourceUnknown
+		case "asset_url":
+			collapse = !isLocalPath(value) && DetectSourceType(value) == SourceUnknown
+		}
+		if collapse {
+			*rs = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, HeadComment: rs.HeadComment, LineComment: rs.LineComment}
+			changes = append(changes, fmt.Sprintf("release_source: {%s: ...} shortened to a plain URL", field))
+		}
+	}
+
+	if repo := mappingValue(root, "repository"); repo != nil && rs.Kind == yaml.ScalarNode &&
+		rs.Value == repo.Value && DetectSourceType(rs.Value) != SourceUnknown {
+		removeMappingKey(root, "release_source")
+		changes = append(changes, "removed release_source (same as repository)")
+	}
+
+	return changes
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeMappingKey deletes key and its value from a mapping node.
+func removeMappingKey(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// effectiveSource summarizes where a config fetches APKs from, for comparing rewrites.
+func effectiveSource(c *Config) string {
+	var assetURL, localPath string
+	if c.ReleaseSource != nil {
+		assetURL, localPath = c.ReleaseSource.AssetURL, c.ReleaseSource.LocalPath
+	}
+	return fmt.Sprintf("%s|%s|%s|%s", c.GetSourceType(), c.GetAPKSourceURL(), assetURL, localPath)
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		wantChanges int
+		contains    []string
+		excludes    []string
+	}{
+		{
+			name:        "already current",
+			input:       "repository: https://github.com/user/app\nrelease_notes: CHANGELOG.md\n",
+			wantChanges: 0,
+			contains:    []string{"release_notes: CHANGELOG.md"},
+		},
+		{
+			name:        "changelog renamed, comments kept",
+			input:       "# app config\nrepository: https://github.com/user/app\nchangelog: CHANGELOG.md # notes\n",
+			wantChanges: 1,
+			contains:    []string{"# app config", "release_notes: CHANGELOG.md # notes"},
+			excludes:    []string{"changelog:"},
+		},
+		{
+			name:        "changelog dropped when release_notes set",
+			input:       "repository: https://github.com/user/app\nchangelog: OLD.md\nrelease_notes: NEW.md\n",
+			wantChanges: 1,
+			contains:    []string{"release_notes: NEW.md"},
+			excludes:    []string{"OLD.md"},
+		},
+		{
+			name:        "release_source url mapping collapsed",
+			input:       "repository: https://github.com/user/app\nrelease_source:\n  url: https://gitlab.com/user/app\n",
+			wantChanges: 1,
+			contains:    []string{"release_source: https://gitlab.com/user/app"},
+		},
+		{
+			name:        "asset_url mapping collapsed",
+			input:       "release_source:\n  asset_url: https://example.com/app.apk\n",
+			wantChanges: 1,
+			contains:    []string{"release_source: https://example.com/app.apk"},
+		},
+		{
+			name:        "release_source equal to repository removed",
+			input:       "repository: https://github.com/user/app\nrelease_source: https://github.com/user/app\n",
+			wantChanges: 1,
+			excludes:    []string{"release_source"},
+		},
+		{
+			name:        "typed mapping left alone",
+			input:       "release_source:\n  url: https://git.example.com/user/app\n  type: gitea\n",
+			wantChanges: 0,
+			contains:    []string{"type: gitea"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, changes, err := UpgradeConfig([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("UpgradeConfig() error = %v", err)
+			}
+			if len(changes) != tt.wantChanges {
+				t.Errorf("changes = %v, want %d", changes, tt.wantChanges)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(string(out), unwanted) {
+					t.Errorf("output should not contain %q:\n%s", unwanted, out)
+				}
+			}
+		})
+	}
+}
+
+func TestUpgradeConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zapstore.yaml")
+	original := "repository: https://github.com/user/app\nchangelog: CHANGELOG.md\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := UpgradeConfigFile(path, true); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Error("dry run must not modify the file")
+	}
+
+	if _, _, err := UpgradeConfigFile(path, false); err != nil {
+		t.Fatalf("UpgradeConfigFile() error = %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Changelog != "" || cfg.ReleaseNotes != "CHANGELOG.md" {
+		t.Errorf("Changelog = %q, ReleaseNotes = %q", cfg.Changelog, cfg.ReleaseNotes)
+	}
+	if backup, _ := os.ReadFile(path + ".bak"); string(backup) != original {
+		t.Error("expected backup of the original config")
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zapstore.yaml")
+	if err := MigrateFile(path, false, true); err == nil {
+		t.Error("MigrateFile() on a missing file = nil, want an error")
+	}
+
+	if err := os.WriteFile(path, []byte("repository: https://github.com/user/app\nchangelog: CHANGELOG.md\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := MigrateFile(path, false, true); err != nil {
+		t.Fatalf("MigrateFile() error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "release_notes: CHANGELOG.md") {
+		t.Errorf("migrated config = %q, want release_notes", data)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zapstore/zsp/internal/netpolicy"
 	"github.com/zapstore/zsp/internal/ui"
 	"gopkg.in/yaml.v3"
 )
@@ -59,6 +61,10 @@ type WizardOptions struct {
 	// CheckAppExists checks whether an app already exists on the relay.
 	// If set and the app is found, the pubkey step is skipped (app already published).
 	CheckAppExists AppExistsChecker
+
+	// StatePath is where progress is saved for resuming an interrupted wizard.
+	// Defaults to WizardStatePath().
+	StatePath string
 }
 
 // MetadataSourceOption represents a metadata source that can be selected in the wizard.
@@ -76,6 +82,8 @@ func RunWizard(defaults *Config) (*Config, error) {
 }
 
 // RunWizardWithOptions runs the wizard with additional options.
+// Progress is saved after each answer; if a previous run was interrupted, the
+// user is offered to resume it. The state file is removed once the config is saved.
 func RunWizardWithOptions(defaults *Config, opts WizardOptions) (*Config, error) {
 	fmt.Print(ui.RenderLogo())
 	if defaults != nil {
@@ -86,6 +94,61 @@ func RunWizardWithOptions(defaults *Config, opts WizardOptions) (*Config, error)
 	}
 	fmt.Println()
 
+	statePath := opts.StatePath
+	if statePath == "" {
+		statePath = WizardStatePath()
+	}
+	state, err := resumeWizardState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	session := &wizardSession{state: state, path: statePath}
+
+	cfg, err := runWizard(defaults, opts, session)
+	switch {
+	case errors.Is(err, ErrWizardComplete):
+		if clearErr := ClearWizardState(statePath); clearErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove wizard state: %v\n", clearErr)
+		}
+	case err != nil && len(state.Answered) > 0 && !session.saveErred:
+		fmt.Println()
+		fmt.Println(ui.Dim("Your answers so far are saved. Run zsp publish --wizard to resume."))
+	}
+	return cfg, err
+}
+
+// resumeWizardState loads saved progress from path and asks whether to resume it.
+// It returns empty state when there is nothing to resume or the user starts over.
+func resumeWizardState(path string) (*WizardState, error) {
+	cwd, _ := os.Getwd()
+	fresh := &WizardState{Dir: cwd}
+
+	saved, err := LoadWizardState(path)
+	if err != nil {
+		fmt.Printf("%s Ignoring unreadable wizard state: %v\n\n", ui.Warning("⚠"), err)
+		return fresh, ClearWizardState(path)
+	}
+	if saved == nil || len(saved.Answered) == 0 {
+		return fresh, nil
+	}
+
+	fmt.Printf("%s Found an unfinished wizard session from %s (%d answers)\n",
+		ui.Info("ℹ"), saved.SavedAt.Local().Format("2006-01-02 15:04"), len(saved.Answered))
+	resume, err := ui.Confirm("Resume where you left off?", true)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Println()
+	if !resume {
+		return fresh, ClearWizardState(path)
+	}
+	return saved, nil
+}
+
+// runWizard asks the wizard questions, skipping those answered in session's saved state.
+func runWizard(defaults *Config, opts WizardOptions, session *wizardSession) (*Config, error) {
+	st := session.state
+
 	// Initialize config from defaults or empty
 	cfg := &Config{}
 	if defaults != nil {
@@ -99,151 +162,159 @@ func RunWizardWithOptions(defaults *Config, opts WizardOptions) (*Config, error)
 	var sourceType SourceType
 	var needsReleaseSource bool // True if we need to prompt for -s
 
-	defaultRepo := cfg.Repository
-	if defaultRepo != "" {
-		fmt.Println(ui.Dim("Enter a space to clear (closed-source app), or press Enter to keep."))
+	if st.HasAnswered(wizardStepRepository) {
+		sourceType, needsReleaseSource = st.restoreRepository(cfg)
+		fmt.Printf("%s Repository: %s\n", ui.Success("✓"), st.describeRepository())
 	} else {
-		fmt.Println(ui.Dim("Press Enter to skip if this is a closed-source app."))
-	}
-
-repoLoop:
-	for {
-		source, err := ui.PromptDefault("Repository URL (optional)", defaultRepo)
-		if err != nil {
-			return nil, err
+		defaultRepo := cfg.Repository
+		if defaultRepo != "" {
+			fmt.Println(ui.Dim("Enter a space to clear (closed-source app), or press Enter to keep."))
+		} else {
+			fmt.Println(ui.Dim("Press Enter to skip if this is a closed-source app."))
 		}
 
-		// A single space means "clear this field" (used when editing to skip/remove the repo)
-		if source == " " {
-			source = ""
-		}
+	repoLoop:
+		for {
+			source, err := ui.PromptDefault("Repository URL (optional)", defaultRepo)
+			if err != nil {
+				return nil, err
+			}
 
-		// Repository is optional
-		if source == "" {
-			needsReleaseSource = true
-			fmt.Printf("%s No repository - will need a release source\n", ui.Info("ℹ"))
-			break repoLoop
-		}
+			// A single space means "clear this field" (used when editing to skip/remove the repo)
+			if source == " " {
+				source = ""
+			}
 
-		// Reset config for retry
-		cfg.Repository = ""
-		cfg.ReleaseSource = nil
-
-		// Detect source type
-		sourceType = DetectSourceType(source)
-		if sourceType == SourceUnknown {
-			// Check if it's a local path
-			if _, err := os.Stat(source); err == nil {
-				cfg.ReleaseSource = &ReleaseSource{LocalPath: source}
-				sourceType = SourceLocal
-			} else if strings.Contains(source, "*") {
-				// Glob pattern
-				cfg.ReleaseSource = &ReleaseSource{LocalPath: source}
-				sourceType = SourceLocal
+			// Repository is optional
+			if source == "" {
+				needsReleaseSource = true
+				fmt.Printf("%s No repository - will need a release source\n", ui.Info("ℹ"))
+				break repoLoop
+			}
+
+			// Reset config for retry
+			cfg.Repository = ""
+			cfg.ReleaseSource = nil
+
+			// Detect source type
+			sourceType = DetectSourceType(source)
+			if sourceType == SourceUnknown {
+				// Check if it's a local path
+				if _, err := os.Stat(source); err == nil {
+					cfg.ReleaseSource = &ReleaseSource{LocalPath: source}
+					sourceType = SourceLocal
+				} else if strings.Contains(source, "*") {
+					// Glob pattern
+					cfg.ReleaseSource = &ReleaseSource{LocalPath: source}
+					sourceType = SourceLocal
+				} else {
+					// Assume it's a URL, add https:// if needed
+					if !strings.Contains(source, "://") {
+						source = "https://" + source
+					}
+					cfg.Repository = source
+					sourceType = DetectSourceType(source)
+				}
 			} else {
-				// Assume it's a URL, add https:// if needed
+				// Ensure URL has scheme
 				if !strings.Contains(source, "://") {
 					source = "https://" + source
 				}
 				cfg.Repository = source
-				sourceType = DetectSourceType(source)
 			}
-		} else {
-			// Ensure URL has scheme
-			if !strings.Contains(source, "://") {
-				source = "https://" + source
-			}
-			cfg.Repository = source
-		}
-
-		fmt.Printf("\n%s Detected: %s\n", ui.Info("ℹ"), sourceType)
-
-		// Web sources (unknown type) are not supported in the wizard
-		if sourceType == SourceUnknown {
-			fmt.Printf("\n%s Web sources require YAML configuration.\n", ui.Warning("⚠"))
-			fmt.Println(ui.Dim("The wizard supports GitHub, GitLab, Gitea, F-Droid, or local paths."))
-			fmt.Println(ui.Dim("For web sources, create a zapstore.yaml with release_source config."))
-			fmt.Println()
-			return nil, fmt.Errorf("unsupported source type for wizard")
-		}
 
-		// Validate repository if GitHub or GitLab
-		hasWarning := false
-		noViableAPKs := false
-		if sourceType == SourceGitHub || sourceType == SourceGitLab {
-			fmt.Printf("%s Checking for releases...\n", ui.Dim("⋯"))
+			fmt.Printf("\n%s Detected: %s\n", ui.Info("ℹ"), sourceType)
 
-			var validation *releaseValidation
-			if sourceType == SourceGitHub {
-				validation = validateGitHubRepo(GetGitHubRepo(cfg.Repository))
-			} else {
-				validation = validateGitLabRepo(GetGitLabRepo(cfg.Repository))
+			// Web sources (unknown type) are not supported in the wizard
+			if sourceType == SourceUnknown {
+				fmt.Printf("\n%s Web sources require YAML configuration.\n", ui.Warning("⚠"))
+				fmt.Println(ui.Dim("The wizard supports GitHub, GitLab, Gitea, F-Droid, or local paths."))
+				fmt.Println(ui.Dim("For web sources, create a zapstore.yaml with release_source config."))
+				fmt.Println()
+				return nil, fmt.Errorf("unsupported source type for wizard")
 			}
 
-			if validation.Error != nil {
-				fmt.Printf("%s Could not validate: %v\n", ui.Warning("⚠"), validation.Error)
-				hasWarning = true
-				noViableAPKs = true
-			} else if !validation.HasReleases {
-				fmt.Printf("%s No releases found\n", ui.Warning("⚠"))
-				hasWarning = true
-				noViableAPKs = true
-			} else if validation.APKCount == 0 {
-				fmt.Printf("%s Release found but no APK assets\n", ui.Warning("⚠"))
-				hasWarning = true
-				noViableAPKs = true
-			} else {
-				// Filter to viable APKs (exclude debug, x86, etc.)
-				viableNames := filterViableAPKNames(validation.APKNames)
+			// Validate repository if GitHub or GitLab
+			hasWarning := false
+			noViableAPKs := false
+			if sourceType == SourceGitHub || sourceType == SourceGitLab {
+				fmt.Printf("%s Checking for releases...\n", ui.Dim("⋯"))
+
+				var validation *releaseValidation
+				if sourceType == SourceGitHub {
+					validation = validateGitHubRepo(GetGitHubRepo(cfg.Repository))
+				} else {
+					validation = validateGitLabRepo(GetGitLabRepo(cfg.Repository))
+				}
 
-				if len(viableNames) == 0 {
-					fmt.Printf("%s Found %d APK(s) but none are viable (all debug/x86/etc)\n", ui.Warning("⚠"), validation.APKCount)
+				if validation.Error != nil {
+					fmt.Printf("%s Could not validate: %v\n", ui.Warning("⚠"), validation.Error)
+					hasWarning = true
+					noViableAPKs = true
+				} else if !validation.HasReleases {
+					fmt.Printf("%s No releases found\n", ui.Warning("⚠"))
+					hasWarning = true
+					noViableAPKs = true
+				} else if validation.APKCount == 0 {
+					fmt.Printf("%s Release found but no APK assets\n", ui.Warning("⚠"))
 					hasWarning = true
 					noViableAPKs = true
 				} else {
-					// Auto-select best APK (picker will handle selection during fetch)
-					bestName := selectBestAPKName(viableNames)
-					fmt.Printf("%s Found APK: %s\n", ui.Success("✓"), bestName)
+					// Filter to viable APKs (exclude debug, x86, etc.)
+					viableNames := filterViableAPKNames(validation.APKNames)
+
+					if len(viableNames) == 0 {
+						fmt.Printf("%s Found %d APK(s) but none are viable (all debug/x86/etc)\n", ui.Warning("⚠"), validation.APKCount)
+						hasWarning = true
+						noViableAPKs = true
+					} else {
+						// Auto-select best APK (picker will handle selection during fetch)
+						bestName := selectBestAPKName(viableNames)
+						fmt.Printf("%s Found APK: %s\n", ui.Success("✓"), bestName)
+					}
 				}
 			}
-		}
 
-		// If warning, ask what to do
-		if hasWarning {
-			if noViableAPKs {
-				// No APKs found - offer to specify release source or retry
-				fmt.Println()
-				options := []string{
-					"Specify a different release source",
-					"Re-enter repository URL",
-					"Continue anyway (repo only for display)",
-				}
-				idx, err := ui.SelectOption("What would you like to do?", options, 0)
-				if err != nil {
-					return nil, err
-				}
-				switch idx {
-				case 0:
-					needsReleaseSource = true
-					break repoLoop
-				case 1:
+			// If warning, ask what to do
+			if hasWarning {
+				if noViableAPKs {
+					// No APKs found - offer to specify release source or retry
 					fmt.Println()
-					defaultRepo = source
-					continue repoLoop
-				case 2:
-					break repoLoop
-				}
-			} else {
-				proceed, _ := ui.Confirm("Proceed anyway?", false)
-				if !proceed {
-					fmt.Println()
-					defaultRepo = source
-					continue repoLoop
+					options := []string{
+						"Specify a different release source",
+						"Re-enter repository URL",
+						"Continue anyway (repo only for display)",
+					}
+					idx, err := ui.SelectOption("What would you like to do?", options, 0)
+					if err != nil {
+						return nil, err
+					}
+					switch idx {
+					case 0:
+						needsReleaseSource = true
+						break repoLoop
+					case 1:
+						fmt.Println()
+						defaultRepo = source
+						continue repoLoop
+					case 2:
+						break repoLoop
+					}
+				} else {
+					proceed, _ := ui.Confirm("Proceed anyway?", false)
+					if !proceed {
+						fmt.Println()
+						defaultRepo = source
+						continue repoLoop
+					}
 				}
 			}
+
+			break repoLoop
 		}
 
-		break repoLoop
+		st.recordRepository(cfg, sourceType, needsReleaseSource)
+		session.answer(wizardStepRepository)
 	}
 
 	fmt.Println()
@@ -255,53 +326,62 @@ repoLoop:
 		defaultReleaseSource = cfg.ReleaseSource.URL
 	}
 
-	if needsReleaseSource {
-		fmt.Println(ui.Dim("Specify where to fetch APK releases from."))
-		fmt.Println(ui.Dim("Examples: github.com/user/repo, f-droid.org/packages/com.app, codeberg.org/user/repo"))
+	if st.HasAnswered(wizardStepReleaseSource) {
+		releaseSourceURL = st.ReleaseSourceURL
+	} else {
+		if needsReleaseSource {
+			fmt.Println(ui.Dim("Specify where to fetch APK releases from."))
+			fmt.Println(ui.Dim("Examples: github.com/user/repo, f-droid.org/packages/com.app, codeberg.org/user/repo"))
 
-		for {
-			source, err := ui.PromptDefault("Release source URL", defaultReleaseSource)
-			if err != nil {
-				return nil, err
-			}
+			for {
+				source, err := ui.PromptDefault("Release source URL", defaultReleaseSource)
+				if err != nil {
+					return nil, err
+				}
 
-			if source == "" {
-				// Release source is required if no repo
-				if cfg.Repository == "" && cfg.ReleaseSource == nil {
-					fmt.Printf("%s Release source is required when no repository is specified\n", ui.Warning("⚠"))
-					continue
+				if source == "" {
+					// Release source is required if no repo
+					if cfg.Repository == "" && cfg.ReleaseSource == nil {
+						fmt.Printf("%s Release source is required when no repository is specified\n", ui.Warning("⚠"))
+						continue
+					}
+					break
 				}
-				break
-			}
 
-			// Ensure URL has scheme
-			if !strings.Contains(source, "://") {
-				source = "https://" + source
-			}
+				// Ensure URL has scheme
+				if !strings.Contains(source, "://") {
+					source = "https://" + source
+				}
 
-			rsType := DetectSourceType(source)
-			fmt.Printf("%s Detected: %s\n", ui.Info("ℹ"), rsType)
+				rsType := DetectSourceType(source)
+				fmt.Printf("%s Detected: %s\n", ui.Info("ℹ"), rsType)
 
-			// Web sources (unknown type) are not supported in the wizard
-			if rsType == SourceUnknown {
-				fmt.Printf("\n%s Web sources require YAML configuration.\n", ui.Warning("⚠"))
-				fmt.Println(ui.Dim("The wizard supports GitHub, GitLab, Gitea, or F-Droid URLs."))
-				fmt.Println(ui.Dim("For web sources, create a zapstore.yaml with release_source config."))
-				fmt.Println()
-				return nil, fmt.Errorf("unsupported source type for wizard")
+				// Web sources (unknown type) are not supported in the wizard
+				if rsType == SourceUnknown {
+					fmt.Printf("\n%s Web sources require YAML configuration.\n", ui.Warning("⚠"))
+					fmt.Println(ui.Dim("The wizard supports GitHub, GitLab, Gitea, or F-Droid URLs."))
+					fmt.Println(ui.Dim("For web sources, create a zapstore.yaml with release_source config."))
+					fmt.Println()
+					return nil, fmt.Errorf("unsupported source type for wizard")
+				}
+
+				releaseSourceURL = source
+				break
 			}
 
-			releaseSourceURL = source
-			break
+			fmt.Println()
 		}
-
-		fmt.Println()
+		st.ReleaseSourceURL = releaseSourceURL
+		session.answer(wizardStepReleaseSource)
 	}
 
 	// Step 3: Fetch APK info (for metadata source availability checking)
 	// Build temporary config for fetching
 	appName := opts.AppName
-	if packageID == "" && opts.FetchAPKInfo != nil {
+	if st.HasAnswered(wizardStepAPKInfo) {
+		packageID = st.PackageID
+		appName = st.AppName
+	} else if packageID == "" && opts.FetchAPKInfo != nil {
 		tempCfg := &Config{
 			Repository:    cfg.Repository,
 			ReleaseSource: cfg.ReleaseSource,
@@ -311,11 +391,18 @@ repoLoop:
 		}
 		// Only fetch if we have a source
 		if tempCfg.Repository != "" || tempCfg.ReleaseSource != nil {
-			if info := opts.FetchAPKInfo(tempCfg, ""); info != nil {
+			info, err := fetchAPKInfoWithRetry(opts.FetchAPKInfo, tempCfg)
+			if err != nil {
+				return nil, err
+			}
+			if info != nil {
 				packageID = info.PackageID
 				appName = info.AppName
 			}
 		}
+		st.PackageID = packageID
+		st.AppName = appName
+		session.answer(wizardStepAPKInfo)
 	}
 
 	// Step 5: Ask about metadata sources (multi-select)
@@ -330,40 +417,49 @@ repoLoop:
 		}
 	}
 
-	// Build available metadata sources based on package ID availability
-	availableSources := BuildAvailableMetadataSources(ctx, packageID, effectiveSourceType)
+	if st.HasAnswered(wizardStepMetadataSources) {
+		selectedMetadataSources = st.MetadataSources
+	} else {
+		// Build available metadata sources based on package ID availability
+		availableSources := BuildAvailableMetadataSources(ctx, packageID, effectiveSourceType)
 
-	if len(availableSources) > 0 {
-		fmt.Println()
+		if len(availableSources) > 0 {
+			fmt.Println()
 
-		sourceNames := make([]string, len(availableSources))
-		for i, s := range availableSources {
-			sourceNames[i] = s.Name
-		}
+			sourceNames := make([]string, len(availableSources))
+			for i, s := range availableSources {
+				sourceNames[i] = s.Name
+			}
 
-		// Determine which sources to pre-select
-		var preselected []int
-		for i, s := range availableSources {
-			// Pre-select the native repository source; Fastlane is optional.
-			if (s.Value == "github" && effectiveSourceType == SourceGitHub) ||
-				(s.Value == "gitlab" && effectiveSourceType == SourceGitLab) {
-				preselected = append(preselected, i)
+			// Determine which sources to pre-select
+			var preselected []int
+			for i, s := range availableSources {
+				// Pre-select the native repository source; Fastlane is optional.
+				if (s.Value == "github" && effectiveSourceType == SourceGitHub) ||
+					(s.Value == "gitlab" && effectiveSourceType == SourceGitLab) {
+					preselected = append(preselected, i)
+				}
 			}
-		}
 
-		fmt.Println(ui.Bold("Metadata available in the following sources"))
-		fmt.Println(ui.Dim("Optionally pull app metadata (description, screenshots) from these."))
-		selectedIndices, err := ui.SelectMultipleWithDefaults("", sourceNames, preselected)
-		if err != nil {
-			// User aborted, continue without metadata sources
-			selectedIndices = nil
-		}
+			fmt.Println(ui.Bold("Metadata available in the following sources"))
+			fmt.Println(ui.Dim("Optionally pull app metadata (description, screenshots) from these."))
+			selectedIndices, err := ui.SelectMultipleWithDefaults("", sourceNames, preselected)
+			if err != nil {
+				// User aborted, continue without metadata sources
+				selectedIndices = nil
+			}
 
-		for _, idx := range selectedIndices {
-			selectedMetadataSources = append(selectedMetadataSources, availableSources[idx].Value)
-		}
+			for _, idx := range selectedIndices {
+				selectedMetadataSources = append(selectedMetadataSources, availableSources[idx].Value)
+			}
 
-		fmt.Println()
+			fmt.Println()
+		}
+		if ui.IsInterrupted() {
+			return nil, ui.ErrInterrupted
+		}
+		st.MetadataSources = selectedMetadataSources
+		session.answer(wizardStepMetadataSources)
 	}
 
 	// Step 4: Build command (kept for reference but config is always written)
@@ -377,9 +473,15 @@ repoLoop:
 	} else {
 		metadataPrompt = "Would you like to provide a name, description, and more now?"
 	}
-	wantMetadataOverrides, err := ui.Confirm(metadataPrompt, false)
-	if err != nil {
-		return nil, err
+	wantMetadataOverrides := st.WantOverrides
+	if !st.HasAnswered(wizardStepOverrides) {
+		var err error
+		wantMetadataOverrides, err = ui.Confirm(metadataPrompt, false)
+		if err != nil {
+			return nil, err
+		}
+		st.WantOverrides = wantMetadataOverrides
+		session.answer(wizardStepOverrides)
 	}
 
 	if wantMetadataOverrides {
@@ -387,13 +489,30 @@ repoLoop:
 		fmt.Println(ui.Dim("These override values fetched from metadata sources. Press Enter to skip."))
 		fmt.Println()
 
+		// prompt asks one override question, replaying the saved answer on resume
+		prompt := func(field, message, defaultValue string) (string, error) {
+			if value, ok := st.override(field); ok {
+				return value, nil
+			}
+			value, err := ui.PromptDefault(message, defaultValue)
+			if err != nil {
+				return "", err
+			}
+			st.setOverride(field, value)
+			session.save()
+			return value, nil
+		}
+
 		// Basic info
 		// Use APK app name as default, but only save to config if user enters something different
 		defaultName := cfg.Name
 		if defaultName == "" {
 			defaultName = appName
 		}
-		name, _ := ui.PromptDefault("App name", defaultName)
+		name, err := prompt("name", "App name", defaultName)
+		if err != nil {
+			return nil, err
+		}
 		if name != "" && name != appName {
 			// Only save if user entered something different from APK name
 			cfg.Name = name
@@ -401,14 +520,20 @@ repoLoop:
 			cfg.Name = ""
 		}
 
-		summary, _ := ui.PromptDefault("Summary (short tagline)", cfg.Summary)
+		summary, err := prompt("summary", "Summary (short tagline)", cfg.Summary)
+		if err != nil {
+			return nil, err
+		}
 		if summary != "" {
 			cfg.Summary = summary
 		} else {
 			cfg.Summary = ""
 		}
 
-		description, _ := ui.PromptDefault("Description", cfg.Description)
+		description, err := prompt("description", "Description", cfg.Description)
+		if err != nil {
+			return nil, err
+		}
 		if description != "" {
 			cfg.Description = description
 		} else {
@@ -416,7 +541,10 @@ repoLoop:
 		}
 
 		defaultTags := strings.Join(cfg.Tags, " ")
-		tagsStr, _ := ui.PromptDefault("Tags (space-separated)", defaultTags)
+		tagsStr, err := prompt("tags", "Tags (space-separated)", defaultTags)
+		if err != nil {
+			return nil, err
+		}
 		if tagsStr != "" {
 			cfg.Tags = strings.Fields(tagsStr)
 		} else {
@@ -426,14 +554,20 @@ repoLoop:
 		fmt.Println()
 
 		// URLs and links
-		website, _ := ui.PromptDefault("Website URL", cfg.Website)
+		website, err := prompt("website", "Website URL", cfg.Website)
+		if err != nil {
+			return nil, err
+		}
 		if website != "" {
 			cfg.Website = website
 		} else {
 			cfg.Website = ""
 		}
 
-		license, _ := ui.PromptDefault("License (e.g., MIT, GPL-3.0, Apache-2.0)", cfg.License)
+		license, err := prompt("license", "License (e.g., MIT, GPL-3.0, Apache-2.0)", cfg.License)
+		if err != nil {
+			return nil, err
+		}
 		if license != "" {
 			cfg.License = license
 		} else {
@@ -443,11 +577,17 @@ repoLoop:
 		fmt.Println()
 
 		// Media
-		icon, _ := ui.PromptDefault("Icon URL or local path (overrides APK icon)", cfg.Icon)
+		icon, err := prompt("icon", "Icon URL, local path or repo:<path> (overrides APK icon)", cfg.Icon)
+		if err != nil {
+			return nil, err
+		}
 		cfg.Icon = icon
 
 		defaultImages := strings.Join(cfg.Images, " ")
-		imagesStr, _ := ui.PromptDefault("Screenshot URLs or local paths (space-separated)", defaultImages)
+		imagesStr, err := prompt("images", "Screenshot URLs or local paths (space-separated)", defaultImages)
+		if err != nil {
+			return nil, err
+		}
 		if imagesStr != "" {
 			cfg.Images = strings.Fields(imagesStr)
 		} else {
@@ -458,7 +598,10 @@ repoLoop:
 
 		// Nostr-specific (optional)
 		defaultNIPs := strings.Join(cfg.SupportedNIPs, " ")
-		nipsStr, _ := ui.PromptDefault("Supported NIPs (space-separated, e.g., 01 07 19)", defaultNIPs)
+		nipsStr, err := prompt("supported_nips", "Supported NIPs (space-separated, e.g., 01 07 19)", defaultNIPs)
+		if err != nil {
+			return nil, err
+		}
 		if nipsStr != "" {
 			cfg.SupportedNIPs = strings.Fields(nipsStr)
 		} else {
@@ -544,6 +687,27 @@ repoLoop:
 	return nil, ErrWizardComplete
 }
 
+// fetchAPKInfoWithRetry runs the APK detection step, offering to retry when it fails
+// (e.g. a network hiccup). Returns nil info if the user continues without it.
+func fetchAPKInfoWithRetry(fetch APKInfoFetcher, cfg *Config) (*APKBasicInfo, error) {
+	for {
+		if info := fetch(cfg, ""); info != nil {
+			return info, nil
+		}
+		if ui.IsInterrupted() {
+			return nil, ui.ErrInterrupted
+		}
+		retry, err := ui.Confirm("Could not detect the app from its APK. Retry?", true)
+		if err != nil {
+			return nil, err
+		}
+		if !retry {
+			fmt.Println(ui.Dim("Continuing without app info; F-Droid and Play Store metadata won't be offered."))
+			return nil, nil
+		}
+	}
+}
+
 // resolveOrPromptPubkey tries to resolve the npub from signWith.
 // For nsec/npub it resolves synchronously. For bunker/browser it calls resolver
 // (if provided) with a spinner. If resolution fails or resolver is nil, it
@@ -719,6 +883,18 @@ func BuildAvailableMetadataSources(ctx context.Context, packageID string, source
 	return sources
 }
 
+// newWizardHTTPClient returns the HTTP client of the wizard's availability and
+// release probes, which honors network_allowlist like every other request.
+func newWizardHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: netpolicy.WrapTransport(&http.Transport{
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+			Proxy:           http.ProxyFromEnvironment,
+		}),
+	}
+}
+
 // CheckFDroidAvailability checks if an app exists on F-Droid.
 func CheckFDroidAvailability(ctx context.Context, packageID string) bool {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -732,7 +908,7 @@ func CheckFDroidAvailability(ctx context.Context, packageID string) bool {
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; zsp/1.0)")
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := newWizardHTTPClient(5 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return false
@@ -755,7 +931,7 @@ func CheckPlayStoreAvailability(ctx context.Context, packageID string) bool {
 
 	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; zsp/1.0)")
 
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := newWizardHTTPClient(5 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return false
@@ -1030,7 +1206,7 @@ func validateGitHubRepo(repoPath string) *releaseValidation {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := newWizardHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return &releaseValidation{Error: err}
@@ -1081,7 +1257,7 @@ func validateGitLabRepo(repoPath string) *releaseValidation {
 		req.Header.Set("PRIVATE-TOKEN", token)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := newWizardHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return &releaseValidation{Error: err}
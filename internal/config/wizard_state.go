@@ -0,0 +1,209 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Wizard questions recorded in WizardState.Answered.
+const (
+	wizardStepRepository      = "repository"
+	wizardStepReleaseSource   = "release_source"
+	wizardStepAPKInfo         = "apk_info"
+	wizardStepMetadataSources = "metadata_sources"
+	wizardStepOverrides       = "overrides"
+)
+
+// WizardState is the wizard's progress, saved after each answered question so an
+// interrupted run can resume. Signing secrets are never stored.
+type WizardState struct {
+	Dir      string    `json:"dir"`      // Working directory the wizard ran in
+	SavedAt  time.Time `json:"saved_at"` // Last save
+	Answered []string  `json:"answered"` // Questions answered so far, in order
+
+	RepositorySkipped  bool     `json:"repository_skipped,omitempty"` // No repository given (closed source)
+	Repository         string   `json:"repository,omitempty"`
+	LocalPath          string   `json:"local_path,omitempty"`
+	NeedsReleaseSource bool     `json:"needs_release_source,omitempty"`
+	ReleaseSourceURL   string   `json:"release_source_url,omitempty"`
+	PackageID          string   `json:"package_id,omitempty"`
+	AppName            string   `json:"app_name,omitempty"`
+	MetadataSources    []string `json:"metadata_sources,omitempty"`
+	WantOverrides      bool     `json:"want_overrides,omitempty"`
+
+	// Overrides holds the raw answers to the metadata override prompts, keyed by field.
+	Overrides map[string]string `json:"overrides,omitempty"`
+}
+
+// WizardStatePath returns the default state file for the current working directory.
+// The file lives in the user's cache directory, one per directory the wizard runs in.
+func WizardStatePath() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(dir))
+	return filepath.Join(cacheDir, "zsp", "wizard", hex.EncodeToString(sum[:6])+".json")
+}
+
+// LoadWizardState reads saved wizard progress. It returns nil without an error
+// when there is no state file, or when the file is not a regular file owned by
+// the current user, since another user could have planted it.
+func LoadWizardState(path string) (*WizardState, error) {
+	fi, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wizard state: %w", err)
+	}
+	if !fi.Mode().IsRegular() || !ownedByCurrentUser(fi) {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wizard state: %w", err)
+	}
+
+	var state WizardState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse wizard state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// Save writes the state to path, replacing any previous state atomically.
+func (s *WizardState) Save(path string) error {
+	s.SavedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// A fresh temp file (O_EXCL, mode 0600) cannot be a pre-planted symlink
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to save wizard state: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".wizard-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save wizard state: %w", err)
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save wizard state: %w", err)
+	}
+	return nil
+}
+
+// ClearWizardState removes the state file. A missing file is not an error.
+func ClearWizardState(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// HasAnswered reports whether question was answered in a previous run.
+func (s *WizardState) HasAnswered(question string) bool {
+	return slices.Contains(s.Answered, question)
+}
+
+// markAnswered records question as answered.
+func (s *WizardState) markAnswered(question string) {
+	if !s.HasAnswered(question) {
+		s.Answered = append(s.Answered, question)
+	}
+}
+
+// recordRepository saves the outcome of the repository question.
+// An unknown source type means the repository was skipped (closed source).
+func (s *WizardState) recordRepository(cfg *Config, sourceType SourceType, needsReleaseSource bool) {
+	s.RepositorySkipped = sourceType == SourceUnknown
+	s.Repository = cfg.Repository
+	s.LocalPath = ""
+	if cfg.ReleaseSource != nil {
+		s.LocalPath = cfg.ReleaseSource.LocalPath
+	}
+	s.NeedsReleaseSource = needsReleaseSource
+}
+
+// restoreRepository applies a saved repository answer to cfg and returns the
+// source type and whether a release source still needs to be asked for.
+func (s *WizardState) restoreRepository(cfg *Config) (SourceType, bool) {
+	if s.RepositorySkipped {
+		return SourceUnknown, s.NeedsReleaseSource
+	}
+	cfg.Repository = s.Repository
+	cfg.ReleaseSource = nil
+	if s.LocalPath != "" {
+		cfg.ReleaseSource = &ReleaseSource{LocalPath: s.LocalPath}
+		return SourceLocal, s.NeedsReleaseSource
+	}
+	return DetectSourceType(s.Repository), s.NeedsReleaseSource
+}
+
+// describeRepository returns the saved repository answer for display.
+func (s *WizardState) describeRepository() string {
+	switch {
+	case s.RepositorySkipped:
+		return "none (closed source)"
+	case s.LocalPath != "":
+		return s.LocalPath
+	default:
+		return s.Repository
+	}
+}
+
+// override returns the saved answer to an override prompt.
+func (s *WizardState) override(field string) (string, bool) {
+	value, ok := s.Overrides[field]
+	return value, ok
+}
+
+// setOverride records the answer to an override prompt.
+func (s *WizardState) setOverride(field, value string) {
+	if s.Overrides == nil {
+		s.Overrides = make(map[string]string)
+	}
+	s.Overrides[field] = value
+}
+
+// wizardSession ties the state to its file and reports save failures once.
+type wizardSession struct {
+	state     *WizardState
+	path      string
+	saveErred bool
+}
+
+// answer marks question as answered and saves the state.
+func (w *wizardSession) answer(question string) {
+	w.state.markAnswered(question)
+	w.save()
+}
+
+// save writes the state, warning on the first failure and continuing without resume support.
+func (w *wizardSession) save() {
+	if err := w.state.Save(w.path); err != nil && !w.saveErred {
+		w.saveErred = true
+		fmt.Fprintf(os.Stderr, "warning: %v (the wizard cannot be resumed if interrupted)\n", err)
+	}
+}
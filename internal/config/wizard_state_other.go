@@ -0,0 +1,11 @@
+//go:build !unix
+
+package config
+
+import "os"
+
+// ownedByCurrentUser reports whether fi belongs to the user running zsp. File
+// owners are not exposed here; the state lives in a per-user directory.
+func ownedByCurrentUser(fi os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withStdin feeds input to the wizard's prompts. Reads past the end of input fail
+// with io.EOF, which the wizard treats like an interrupted prompt.
+func withStdin(t *testing.T, lines ...string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(strings.Join(lines, "")); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	orig := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = orig
+		r.Close()
+	})
+}
+
+// runTestWizard runs the wizard with the given answers and a stub APK info fetcher.
+func runTestWizard(t *testing.T, statePath string, fetches *int, lines ...string) error {
+	t.Helper()
+	withStdin(t, lines...)
+	_, err := RunWizardWithOptions(nil, WizardOptions{
+		StatePath: statePath,
+		FetchAPKInfo: func(cfg *Config, matchPattern string) *APKBasicInfo {
+			*fetches++
+			return &APKBasicInfo{AppName: "Demo"}
+		},
+	})
+	return err
+}
+
+func TestWizardResumeAfterInterrupt(t *testing.T) {
+	t.Setenv("SIGN_WITH", "npub1wizardtest")
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := os.WriteFile(apkPath, []byte("apk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	answers := []string{
+		apkPath + "\n",   // repository (local path)
+		"y\n",            // provide overrides
+		"Demo Pro\n",     // name
+		"A demo app\n",   // summary
+		"Longer text\n",  // description
+		"tools nostr\n",  // tags
+		"https://x.io\n", // website
+		"MIT\n",          // license
+		"\n",             // icon
+		"\n",             // images
+		"01 07\n",        // supported NIPs
+	}
+
+	// Reference: one uninterrupted run
+	refDir := t.TempDir()
+	t.Chdir(refDir)
+	var fetches int
+	if err := runTestWizard(t, filepath.Join(refDir, "state.json"), &fetches, answers...); !errors.Is(err, ErrWizardComplete) {
+		t.Fatalf("uninterrupted run: got %v, want ErrWizardComplete", err)
+	}
+	want, err := os.ReadFile(filepath.Join(refDir, "zapstore.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupted after the summary answer
+	dir := t.TempDir()
+	t.Chdir(dir)
+	statePath := filepath.Join(dir, "state.json")
+	fetches = 0
+	if err := runTestWizard(t, statePath, &fetches, answers[:4]...); err == nil || errors.Is(err, ErrWizardComplete) {
+		t.Fatalf("interrupted run: got %v, want an error", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "zapstore.yaml")); !os.IsNotExist(err) {
+		t.Fatal("interrupted run should not write zapstore.yaml")
+	}
+	state, err := LoadWizardState(statePath)
+	if err != nil || state == nil {
+		t.Fatalf("LoadWizardState() = %v, %v; want saved state", state, err)
+	}
+	if got := state.Overrides["summary"]; got != "A demo app" {
+		t.Errorf("saved summary = %q, want %q", got, "A demo app")
+	}
+	if _, ok := state.Overrides["description"]; ok {
+		t.Error("unanswered description should not be saved")
+	}
+
+	// Resume and answer the rest
+	resumed := append([]string{"y\n"}, answers[4:]...)
+	if err := runTestWizard(t, statePath, &fetches, resumed...); !errors.Is(err, ErrWizardComplete) {
+		t.Fatalf("resumed run: got %v, want ErrWizardComplete", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "zapstore.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("resumed config differs:\n got:\n%s\nwant:\n%s", got, want)
+	}
+	if fetches != 1 {
+		t.Errorf("APK info fetched %d times across interrupted and resumed runs, want 1", fetches)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Error("state file should be removed after the wizard completes")
+	}
+}
+
+func TestWizardDiscardSavedState(t *testing.T) {
+	t.Setenv("SIGN_WITH", "npub1wizardtest")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	statePath := filepath.Join(dir, "state.json")
+
+	stale := &WizardState{
+		Answered:   []string{wizardStepRepository},
+		Repository: "https://github.com/old/app",
+	}
+	if err := stale.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+
+	apkPath := filepath.Join(dir, "app.apk")
+	if err := os.WriteFile(apkPath, []byte("apk"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var fetches int
+	err := runTestWizard(t, statePath, &fetches, "n\n", apkPath+"\n", "n\n")
+	if !errors.Is(err, ErrWizardComplete) {
+		t.Fatalf("got %v, want ErrWizardComplete", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "zapstore.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "old/app") {
+		t.Errorf("discarded state leaked into config:\n%s", data)
+	}
+}
+
+func TestFetchAPKInfoWithRetry(t *testing.T) {
+	calls := 0
+	fetch := func(cfg *Config, matchPattern string) *APKBasicInfo {
+		calls++
+		if calls < 2 {
+			return nil // transient failure
+		}
+		return &APKBasicInfo{PackageID: "com.example.app"}
+	}
+
+	withStdin(t, "\n") // accept the default: retry
+	info, err := fetchAPKInfoWithRetry(fetch, &Config{})
+	if err != nil {
+		t.Fatalf("fetchAPKInfoWithRetry() error: %v", err)
+	}
+	if info == nil || info.PackageID != "com.example.app" || calls != 2 {
+		t.Errorf("got info %+v after %d calls, want com.example.app after 2", info, calls)
+	}
+}
+
+func TestWizardStatePathIsPerUser(t *testing.T) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		t.Skip("no user cache directory")
+	}
+	path := WizardStatePath()
+	if filepath.Dir(path) != filepath.Join(cacheDir, "zsp", "wizard") {
+		t.Errorf("WizardStatePath() = %s, want it under %s", path, cacheDir)
+	}
+}
+
+func TestWizardStateIgnoresPlantedFiles(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "wizard", "state.json")
+
+	// A symlink planted where a predictable temp name would go is left alone
+	victim := filepath.Join(dir, "victim")
+	if err := os.WriteFile(victim, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(victim, statePath+".tmp"); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	state := &WizardState{Repository: "https://github.com/user/app"}
+	if err := state.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(victim); string(data) != "keep" {
+		t.Errorf("Save() wrote through a planted symlink: %q", data)
+	}
+	if loaded, err := LoadWizardState(statePath); err != nil || loaded == nil || loaded.Repository != state.Repository {
+		t.Fatalf("LoadWizardState() = %v, %v; want the saved state", loaded, err)
+	}
+
+	// A state file that is a symlink is not offered for resuming
+	linked := filepath.Join(dir, "wizard", "linked.json")
+	if err := os.Symlink(statePath, linked); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadWizardState(linked); err != nil || loaded != nil {
+		t.Errorf("LoadWizardState(symlink) = %v, %v; want nil, nil", loaded, err)
+	}
+}
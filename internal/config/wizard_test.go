@@ -0,0 +1,29 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/zapstore/zsp/internal/netpolicy"
+)
+
+func TestWizardProbesHonorAllowlist(t *testing.T) {
+	netpolicy.SetAllowlist([]string{"relay.zapstore.dev"})
+	t.Cleanup(func() { netpolicy.SetAllowlist(nil) })
+
+	req, err := http.NewRequest(http.MethodHead, "https://play.google.com/store/apps/details?id=com.example.app", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = newWizardHTTPClient(time.Second).Do(req)
+	var blocked *netpolicy.BlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("Play Store probe error = %v, want a blocked connection", err)
+	}
+	if CheckPlayStoreAvailability(context.Background(), "com.example.app") {
+		t.Error("CheckPlayStoreAvailability() = true under an allowlist without play.google.com")
+	}
+}
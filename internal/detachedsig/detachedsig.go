@@ -0,0 +1,71 @@
+// Package detachedsig produces conventional detached signatures of APKs
+// (detached_signature) so tooling without Nostr support can verify them:
+// minisign signatures are made in Go, GPG signatures by the gpg binary.
+// Every signature is verified before it is returned.
+package detachedsig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// Signature types accepted by detached_signature.
+const (
+	TypeMinisign = "minisign"
+	TypeGPG      = "gpg"
+)
+
+// ErrNoKey is returned when the environment variable naming the key is empty.
+var ErrNoKey = errors.New("no signing key")
+
+// Options selects the key Sign uses.
+type Options struct {
+	Type     string // TypeMinisign or TypeGPG
+	Key      string // minisign: secret key file contents or path; gpg: key ID, fingerprint or email
+	KeyEnv   string // Environment variable Key was read from, for error messages
+	Password string // Password of an encrypted key ("" for none, or the gpg agent)
+	Filename string // Name recorded in the minisign trusted comment (default: base of the path)
+}
+
+// Signature is a verified detached signature of a file.
+type Signature struct {
+	Type     string // TypeMinisign or TypeGPG
+	Data     []byte // Contents of the signature file
+	SHA256   string // Hash of Data (hex), which is also its Blossom blob hash
+	MimeType string
+	Ext      string // Conventional file extension: .minisig or .asc
+}
+
+// Sign makes a detached signature of the file at path and verifies it.
+func Sign(ctx context.Context, path string, opts Options) (*Signature, error) {
+	if opts.Key == "" {
+		return nil, fmt.Errorf("%w: %s is not set", ErrNoKey, opts.KeyEnv)
+	}
+	if opts.Filename == "" {
+		opts.Filename = filepath.Base(path)
+	}
+
+	sig := &Signature{Type: opts.Type}
+	var err error
+	switch opts.Type {
+	case TypeMinisign:
+		sig.Data, err = signMinisign(path, opts)
+		sig.MimeType, sig.Ext = "text/plain", ".minisig"
+	case TypeGPG:
+		sig.Data, err = signGPG(ctx, path, opts)
+		sig.MimeType, sig.Ext = "application/pgp-signature", ".asc"
+	default:
+		return nil, fmt.Errorf("unsupported signature type %q: must be %s or %s", opts.Type, TypeMinisign, TypeGPG)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(sig.Data)
+	sig.SHA256 = hex.EncodeToString(sum[:])
+	return sig, nil
+}
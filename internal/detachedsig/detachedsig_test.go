@@ -0,0 +1,179 @@
+package detachedsig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testMinisignKey builds a minisign secret key file, encrypted with password
+// when it is non-empty (with scrypt limits small enough for tests).
+func testMinisignKey(t *testing.T, password string) (string, *minisignKey) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &minisignKey{key: priv}
+	rand.Read(key.id[:])
+
+	keynum := append(key.id[:], priv...)
+	keynum = append(keynum, key.checksum()...)
+
+	raw := []byte(minisignSigAlg + "\x00\x00" + minisignChkAlg)
+	salt := make([]byte, 32)
+	rand.Read(salt)
+	raw = append(raw, salt...)
+	raw = binary.LittleEndian.AppendUint64(raw, 32768)
+	raw = binary.LittleEndian.AppendUint64(raw, 1<<24)
+	if password != "" {
+		copy(raw[2:4], minisignKDFAlg)
+		stream, err := minisignKDF(password, salt, 32768, 1<<24, len(keynum))
+		if err != nil {
+			t.Fatal(err)
+		}
+		subtle.XORBytes(keynum, keynum, stream)
+	}
+	raw = append(raw, keynum...)
+
+	text := "untrusted comment: minisign secret key\n" + base64.StdEncoding.EncodeToString(raw) + "\n"
+	return text, key
+}
+
+func writeTestFile(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app-release.apk")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSignMinisign(t *testing.T) {
+	path := writeTestFile(t, "apk contents")
+	text, key := testMinisignKey(t, "")
+
+	sig, err := Sign(context.Background(), path, Options{Type: TypeMinisign, Key: text, KeyEnv: "MINISIGN_KEY"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if sig.Ext != ".minisig" || sig.Type != TypeMinisign {
+		t.Errorf("Sign() = type %q ext %q, want minisign .minisig", sig.Type, sig.Ext)
+	}
+	sum := sha256.Sum256(sig.Data)
+	if sig.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("SHA256 = %s, want hash of the signature file", sig.SHA256)
+	}
+	if !strings.Contains(string(sig.Data), "file:app-release.apk\thashed") {
+		t.Errorf("trusted comment missing file name:\n%s", sig.Data)
+	}
+
+	pub := key.key.Public().(ed25519.PublicKey)
+	if err := verifyMinisign(sig.Data, pub, key.id, path); err != nil {
+		t.Errorf("verifyMinisign() error = %v", err)
+	}
+
+	// A modified file or trusted comment must not verify
+	tampered := writeTestFile(t, "apk contents!")
+	if err := verifyMinisign(sig.Data, pub, key.id, tampered); err == nil {
+		t.Error("verifyMinisign() accepted a modified file")
+	}
+	forged := strings.Replace(string(sig.Data), "app-release.apk", "other.apk", 1)
+	if err := verifyMinisign([]byte(forged), pub, key.id, path); err == nil {
+		t.Error("verifyMinisign() accepted a modified trusted comment")
+	}
+}
+
+func TestSignMinisignKeyFile(t *testing.T) {
+	path := writeTestFile(t, "apk contents")
+	text, _ := testMinisignKey(t, "")
+	keyPath := filepath.Join(t.TempDir(), "minisign.key")
+	if err := os.WriteFile(keyPath, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Sign(context.Background(), path, Options{Type: TypeMinisign, Key: keyPath, KeyEnv: "MINISIGN_KEY"}); err != nil {
+		t.Errorf("Sign() with key path error = %v", err)
+	}
+}
+
+func TestSignMinisignEncrypted(t *testing.T) {
+	path := writeTestFile(t, "apk contents")
+	text, _ := testMinisignKey(t, "hunter2")
+	opts := Options{Type: TypeMinisign, Key: text, KeyEnv: "MINISIGN_KEY"}
+
+	if _, err := Sign(context.Background(), path, opts); err == nil || !strings.Contains(err.Error(), "password_env") {
+		t.Errorf("Sign() without password = %v, want password_env hint", err)
+	}
+
+	opts.Password = "wrong"
+	if _, err := Sign(context.Background(), path, opts); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("Sign() with wrong password = %v, want ErrWrongPassword", err)
+	}
+
+	opts.Password = "hunter2"
+	if _, err := Sign(context.Background(), path, opts); err != nil {
+		t.Errorf("Sign() with password error = %v", err)
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	path := writeTestFile(t, "apk contents")
+
+	_, err := Sign(context.Background(), path, Options{Type: TypeMinisign, KeyEnv: "MINISIGN_KEY"})
+	if !errors.Is(err, ErrNoKey) || !strings.Contains(err.Error(), "MINISIGN_KEY") {
+		t.Errorf("Sign() without key = %v, want ErrNoKey naming MINISIGN_KEY", err)
+	}
+
+	if _, err := Sign(context.Background(), path, Options{Type: TypeMinisign, Key: "not a key\n", KeyEnv: "MINISIGN_KEY"}); err == nil {
+		t.Error("Sign() accepted a malformed key")
+	}
+
+	if _, err := Sign(context.Background(), path, Options{Type: "signify", Key: "k"}); err == nil {
+		t.Error("Sign() accepted an unsupported type")
+	}
+}
+
+func TestSignGPG(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not installed")
+	}
+	home, err := os.MkdirTemp("", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		os.RemoveAll(home)
+	})
+	t.Setenv("GNUPGHOME", home)
+
+	gen := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "zsp test <test@example.com>", "ed25519", "sign", "never")
+	if out, err := gen.CombinedOutput(); err != nil {
+		t.Skipf("gpg key generation failed: %v: %s", err, out)
+	}
+
+	path := writeTestFile(t, "apk contents")
+	sig, err := Sign(context.Background(), path, Options{Type: TypeGPG, Key: "test@example.com", KeyEnv: "GPG_KEY"})
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if sig.Ext != ".asc" || !strings.Contains(string(sig.Data), "BEGIN PGP SIGNATURE") {
+		t.Errorf("Sign() = ext %q data %q, want an armored .asc signature", sig.Ext, sig.Data)
+	}
+
+	if _, err := Sign(context.Background(), path, Options{Type: TypeGPG, Key: "nobody@example.com", KeyEnv: "GPG_KEY"}); err == nil {
+		t.Error("Sign() with an unknown gpg key succeeded")
+	}
+}
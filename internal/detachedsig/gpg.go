@@ -0,0 +1,73 @@
+package detachedsig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/tempdir"
+)
+
+// signGPG makes an ASCII-armored detached signature with the gpg binary and
+// verifies it, returning the contents of the .asc file. The key is opts.Key;
+// with opts.Password set, gpg reads the passphrase from stdin instead of asking
+// the agent.
+func signGPG(ctx context.Context, path string, opts Options) ([]byte, error) {
+	gpg, err := exec.LookPath("gpg")
+	if err != nil {
+		return nil, fmt.Errorf("detached_signature type gpg needs the gpg binary: %w", err)
+	}
+
+	args := []string{"--batch", "--yes", "--armor", "--detach-sign", "--local-user", opts.Key, "--output", "-"}
+	if opts.Password != "" {
+		args = append(args, "--pinentry-mode", "loopback", "--passphrase-fd", "0")
+	}
+	args = append(args, path)
+
+	cmd := exec.CommandContext(ctx, gpg, args...)
+	if opts.Password != "" {
+		cmd.Stdin = strings.NewReader(opts.Password + "\n")
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	sig, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("gpg signing with key from %s failed: %w: %s", opts.KeyEnv, err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := verifyGPG(ctx, gpg, sig, path); err != nil {
+		return nil, fmt.Errorf("gpg signature failed verification: %w", err)
+	}
+	return sig, nil
+}
+
+// verifyGPG checks a detached signature of the file at path with gpg.
+func verifyGPG(ctx context.Context, gpg string, sig []byte, path string) error {
+	tmp, err := os.CreateTemp(tempdir.Dir(), "*.asc")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(sig); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, gpg, "--batch", "--status-fd", "1", "--verify", tmp.Name(), path)
+	cmd.Stderr = &stderr
+	status, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if !bytes.Contains(status, []byte("[GNUPG:] VALIDSIG ")) {
+		return fmt.Errorf("gpg reported no valid signature")
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package detachedsig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+// minisign algorithm identifiers, see https://jedisct1.github.io/minisign/
+const (
+	minisignSigAlg     = "Ed" // Ed25519 over the file contents (legacy)
+	minisignHashedAlg  = "ED" // Ed25519 over the BLAKE2b-512 hash of the file
+	minisignKDFAlg     = "Sc" // scrypt-encrypted secret key
+	minisignChkAlg     = "B2" // BLAKE2b key checksum
+	minisignKeyIDSize  = 8
+	minisignSecretSize = 2 + 2 + 2 + 32 + 8 + 8 + minisignKeyIDSize + ed25519.PrivateKeySize + 32
+)
+
+// ErrWrongPassword is returned when an encrypted minisign key does not decrypt.
+var ErrWrongPassword = errors.New("wrong password or corrupt minisign secret key")
+
+// minisignKey is a decoded minisign secret key.
+type minisignKey struct {
+	id  [minisignKeyIDSize]byte
+	key ed25519.PrivateKey
+}
+
+// signMinisign signs the file at path with the minisign secret key in opts and
+// verifies the result, returning the contents of the .minisig file.
+func signMinisign(path string, opts Options) ([]byte, error) {
+	text := opts.Key
+	if !strings.Contains(text, "\n") {
+		if data, err := os.ReadFile(text); err == nil {
+			text = string(data)
+		}
+	}
+	key, err := parseMinisignSecretKey(text, opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", opts.KeyEnv, err)
+	}
+
+	digest, err := blake2bFile(path)
+	if err != nil {
+		return nil, err
+	}
+	trusted := fmt.Sprintf("timestamp:%d\tfile:%s\thashed", time.Now().Unix(), opts.Filename)
+	data := key.sign(digest, trusted)
+
+	if err := verifyMinisign(data, key.key.Public().(ed25519.PublicKey), key.id, path); err != nil {
+		return nil, fmt.Errorf("minisign signature failed verification: %w", err)
+	}
+	return data, nil
+}
+
+// parseMinisignSecretKey decodes a minisign secret key given as the key file's
+// contents or its base64 line, decrypting it with password when it is encrypted.
+// Encrypted keys use minisign's scrypt parameters, which need about 1 GiB of
+// memory; keys created with "minisign -G -W" are not encrypted.
+func parseMinisignSecretKey(text, password string) (*minisignKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(keyLine(text))
+	if err != nil || len(raw) != minisignSecretSize {
+		return nil, fmt.Errorf("not a minisign secret key")
+	}
+	if string(raw[0:2]) != minisignSigAlg || string(raw[4:6]) != minisignChkAlg {
+		return nil, fmt.Errorf("unsupported minisign secret key algorithm %q", raw[0:2])
+	}
+	kdf, salt := raw[2:4], raw[6:38]
+	opsLimit := binary.LittleEndian.Uint64(raw[38:46])
+	memLimit := binary.LittleEndian.Uint64(raw[46:54])
+	keynum := bytes.Clone(raw[54:])
+
+	switch {
+	case string(kdf) == minisignKDFAlg:
+		if password == "" {
+			return nil, fmt.Errorf("the minisign secret key is encrypted; set password_env in detached_signature")
+		}
+		stream, err := minisignKDF(password, salt, opsLimit, memLimit, len(keynum))
+		if err != nil {
+			return nil, err
+		}
+		subtle.XORBytes(keynum, keynum, stream)
+	case kdf[0] == 0 && kdf[1] == 0:
+	default:
+		return nil, fmt.Errorf("unsupported minisign key derivation %q", kdf)
+	}
+
+	key := &minisignKey{key: ed25519.PrivateKey(keynum[minisignKeyIDSize : minisignKeyIDSize+ed25519.PrivateKeySize])}
+	copy(key.id[:], keynum[:minisignKeyIDSize])
+	if !bytes.Equal(key.checksum(), keynum[minisignKeyIDSize+ed25519.PrivateKeySize:]) {
+		return nil, ErrWrongPassword
+	}
+	return key, nil
+}
+
+// checksum is the BLAKE2b-256 hash minisign stores to detect a wrong password.
+func (k *minisignKey) checksum() []byte {
+	h, _ := blake2b.New256(nil)
+	h.Write([]byte(minisignSigAlg))
+	h.Write(k.id[:])
+	h.Write(k.key)
+	return h.Sum(nil)
+}
+
+// sign builds a .minisig file for a file with the given BLAKE2b-512 digest.
+func (k *minisignKey) sign(digest []byte, trustedComment string) []byte {
+	sig := ed25519.Sign(k.key, digest)
+	global := ed25519.Sign(k.key, append(bytes.Clone(sig), trustedComment...))
+
+	sigLine := append([]byte(minisignHashedAlg), k.id[:]...)
+	sigLine = append(sigLine, sig...)
+
+	var b bytes.Buffer
+	b.WriteString("untrusted comment: signature from minisign secret key\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(sigLine) + "\n")
+	b.WriteString("trusted comment: " + trustedComment + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(global) + "\n")
+	return b.Bytes()
+}
+
+// verifyMinisign checks a .minisig file against the file at path and the public
+// key with the given key ID, including the signature over the trusted comment.
+func verifyMinisign(sigFile []byte, pub ed25519.PublicKey, keyID [minisignKeyIDSize]byte, path string) error {
+	lines := strings.Split(strings.TrimRight(string(sigFile), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "untrusted comment: ") {
+		return fmt.Errorf("malformed signature file")
+	}
+	trusted, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return fmt.Errorf("malformed signature file: missing trusted comment")
+	}
+	sigLine, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sigLine) != 2+minisignKeyIDSize+ed25519.SignatureSize {
+		return fmt.Errorf("malformed signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed trusted comment signature")
+	}
+	if !bytes.Equal(sigLine[2:2+minisignKeyIDSize], keyID[:]) {
+		return fmt.Errorf("signature was made with a different key")
+	}
+
+	var message []byte
+	switch string(sigLine[:2]) {
+	case minisignHashedAlg:
+		message, err = blake2bFile(path)
+	case minisignSigAlg:
+		message, err = os.ReadFile(path)
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", sigLine[:2])
+	}
+	if err != nil {
+		return err
+	}
+
+	sig := sigLine[2+minisignKeyIDSize:]
+	if !ed25519.Verify(pub, message, sig) {
+		return fmt.Errorf("signature does not match the file")
+	}
+	if !ed25519.Verify(pub, append(bytes.Clone(sig), trusted...), global) {
+		return fmt.Errorf("trusted comment signature does not match")
+	}
+	return nil
+}
+
+// minisignKDF derives the keystream that encrypts a minisign secret key: libsodium's
+// crypto_pwhash_scryptsalsa208sha256, which turns opsLimit and memLimit into
+// scrypt's N, r and p.
+func minisignKDF(password string, salt []byte, opsLimit, memLimit uint64, size int) ([]byte, error) {
+	if opsLimit < 32768 {
+		opsLimit = 32768
+	}
+	r := uint64(8)
+	var nLog2, p uint64
+	if opsLimit < memLimit/32 {
+		p = 1
+		maxN := opsLimit / (r * 4)
+		for nLog2 = 1; nLog2 < 63; nLog2++ {
+			if uint64(1)<<nLog2 > maxN/2 {
+				break
+			}
+		}
+	} else {
+		maxN := memLimit / (r * 128)
+		for nLog2 = 1; nLog2 < 63; nLog2++ {
+			if uint64(1)<<nLog2 > maxN/2 {
+				break
+			}
+		}
+		maxRP := min((opsLimit/4)/(uint64(1)<<nLog2), 0x3fffffff)
+		p = maxRP / r
+	}
+	if nLog2 > 30 || p == 0 {
+		return nil, fmt.Errorf("unsupported minisign key derivation parameters")
+	}
+	return scrypt.Key([]byte(password), salt, 1<<nLog2, int(r), int(p), size)
+}
+
+// keyLine returns the base64 line of a minisign key or signature file, skipping
+// the comment lines.
+func keyLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "untrusted comment:") {
+			return line
+		}
+	}
+	return ""
+}
+
+// blake2bFile returns the BLAKE2b-512 hash of the file at path.
+func blake2bFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h, _ := blake2b.New512(nil)
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return h.Sum(nil), nil
+}
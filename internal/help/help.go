@@ -73,7 +73,14 @@ func RootHelp() string {
 	b.WriteString(renderBold("COMMANDS") + "\n")
 	b.WriteString("  " + renderAccent("publish") + "     " + renderWhite("Publish APK releases to Nostr relays") + "\n")
 	b.WriteString("  " + renderAccent("identity") + "    " + renderWhite("Manage cryptographic identity proofs (NIP-C1)") + "\n")
-	b.WriteString("  " + renderAccent("utils") + "       " + renderWhite("Operational utilities (extract-apk, has-new-release)") + "\n\n")
+	b.WriteString("  " + renderAccent("utils") + "       " + renderWhite("Operational utilities (extract-apk, has-new-release)") + "\n")
+	b.WriteString("  " + renderAccent("config") + "      " + renderWhite("Config file maintenance (migrate, validate)") + "\n")
+	b.WriteString("  " + renderAccent("history") + "     " + renderWhite("List published releases; view or re-broadcast them") + "\n")
+	b.WriteString("  " + renderAccent("apk") + "         " + renderWhite("Inspect APKs (compare signing certificate, lint packaging)") + "\n")
+	b.WriteString("  " + renderAccent("blossom") + "     " + renderWhite("List your Blossom blobs; prune those no event references") + "\n")
+	b.WriteString("  " + renderAccent("relay") + "       " + renderWhite("Show relays' NIP-11 limits and restrictions (info)") + "\n")
+	b.WriteString("  " + renderAccent("keys") + "        " + renderWhite("Generate a Nostr key or show the SIGN_WITH npub") + "\n")
+	b.WriteString("  " + renderAccent("export") + "      " + renderWhite("Write a website badge for the latest published release") + "\n\n")
 
 	b.WriteString(renderBold("EXAMPLES") + "\n")
 	writeExample(&b, "zsp publish --wizard", "Interactive wizard (recommended for first-time setup)")
@@ -85,10 +92,15 @@ func RootHelp() string {
 	b.WriteString("\n")
 
 	b.WriteString(renderBold("ENVIRONMENT") + "\n")
-	b.WriteString("  " + renderAccent("SIGN_WITH") + "       " + renderWhite("Signing method (nsec1..., npub1..., bunker://..., browser)") + "\n")
+	b.WriteString("  " + renderAccent("SIGN_WITH") + "       " + renderWhite("Signing method (nsec1..., npub1..., keychain:npub1..., bunker://..., browser)") + "\n")
+	b.WriteString("                  " + renderGreyDark("Comma-separated bunker:// URLs fail over in order") + "\n")
 	b.WriteString("  " + renderAccent("GITHUB_TOKEN") + "    " + renderWhite("GitHub API token (optional, avoids rate limits)") + "\n")
 	b.WriteString("  " + renderAccent("RELAY_URLS") + "      " + renderWhite("Custom relay URLs (default: wss://relay.zapstore.dev)") + "\n")
-	b.WriteString("  " + renderAccent("BLOSSOM_URL") + "     " + renderWhite("Custom CDN server (default: https://cdn.zapstore.dev)") + "\n\n")
+	b.WriteString("  " + renderAccent("BLOSSOM_URL") + "     " + renderWhite("Custom CDN server (default: https://cdn.zapstore.dev)") + "\n")
+	b.WriteString("  " + renderAccent("ZSP_ALLOWED_HOSTS") + " " + renderWhite("Comma-separated host allowlist for all network access (default: unrestricted)") + "\n")
+	b.WriteString("  " + renderAccent("ZSP_DEV_RELAY") + "   " + renderWhite("Local relay for publish --dev (default: ws://localhost:10547)") + "\n")
+	b.WriteString("  " + renderAccent("ZSP_LIMIT_RATE") + "  " + renderWhite("Bandwidth limit for publish transfers, e.g. 2M (default: unlimited)") + "\n")
+	b.WriteString("  " + renderAccent("NIP07_TIMEOUT") + "   " + renderWhite("How long browser signing waits for the extension, e.g. 5m (default: 2m)") + "\n\n")
 
 	b.WriteString(renderBold("GLOBAL FLAGS") + "\n")
 	b.WriteString("  " + renderAccent("-h, --help") + "      " + renderWhite("Show help") + "\n")
@@ -119,11 +131,12 @@ func PublishHelp() string {
 	b.WriteString("\n")
 
 	b.WriteString(renderBold("USAGE") + "\n")
-	b.WriteString("  " + renderAccent("zsp publish") + " [options] [config.yaml | app.apk]\n\n")
+	b.WriteString("  " + renderAccent("zsp publish") + " [options] [config.yaml | app.apk | adb://<package>]\n\n")
 
 	b.WriteString(renderGreyDark("  With no arguments, runs the interactive wizard (unless zapstore.yaml exists).") + "\n")
 	b.WriteString(renderGreyDark("  With a config file, publishes according to that configuration.") + "\n")
-	b.WriteString(renderGreyDark("  With an APK file, publishes that APK directly.") + "\n\n")
+	b.WriteString(renderGreyDark("  With an APK file, publishes that APK directly.") + "\n")
+	b.WriteString(renderGreyDark("  With adb://<package>, pulls the APK installed on a connected device (requires adb).") + "\n\n")
 
 	// Source flags
 	b.WriteString(renderBold("SOURCE FLAGS") + "\n")
@@ -133,24 +146,100 @@ func PublishHelp() string {
 	b.WriteString("                            " + renderGreyDark("Use alone (no -r) for closed-source apps") + "\n")
 	writeFlag(&b, "-m <source>", "Fetch metadata from source (repeatable: -m fastlane -m github)")
 	b.WriteString("                            " + renderGreyDark("Fastlane is tried automatically for GitHub/GitLab/Codeberg repositories") + "\n")
+	b.WriteString("                            " + renderGreyDark("A local fastlane/metadata/android directory is read from disk") + "\n")
 	writeFlag(&b, "--match <pattern>", "Regex pattern to filter APK assets (rarely needed)")
+	b.WriteString("                            " + renderGreyDark("Glob-style patterns like *arm64*.apk are translated to regex") + "\n")
+	writeFlag(&b, "--match-label <label>", "Select APK assets by forge label (exact or regex) or asset ID")
+	writeFlag(&b, "--variant <name>", "Publish only the APK matching this variant from variants:")
+	b.WriteString("                            " + renderGreyDark("Applied after match; --explain-selection shows each APK's variant") + "\n")
+	writeFlag(&b, "--base-dir <dir>", "Resolve relative icon/images/release_notes paths against dir")
+	b.WriteString("                            " + renderGreyDark("Defaults to the config file's directory (cwd for stdin and -r)") + "\n")
+	writeFlag(&b, "--only <app>", "Publish only the named apps from the config's apps: list")
+	b.WriteString("                            " + renderGreyDark("Repeatable or comma-separated: --only shop,kiosk") + "\n")
 	b.WriteString("\n")
 
 	// Release-specific flags (CLI only)
 	b.WriteString(renderBold("RELEASE FLAGS") + "\n")
 	writeFlag(&b, "--commit <hash>", "Git commit hash for reproducible builds")
+	writeFlag(&b, "--changelog-from git", "Release notes from conventional commits since the previous tag")
 	writeFlag(&b, "--channel <name>", "Release channel: main, beta, nightly, dev (default: main)")
+	writeFlag(&b, "--channel-suffix", "Publish non-main channels as their own app entry")
+	b.WriteString("                            " + renderGreyDark("Identifier gets the channel appended: com.example.app~beta") + "\n")
+	writeFlag(&b, "--platform <id>", "Set the f tag platforms instead of detecting them (repeatable)")
+	b.WriteString("                            " + renderGreyDark("android-arm64-v8a, android-armeabi-v7a, android-x86, android-x86_64") + "\n")
+	writeFlag(&b, "--published-at <date>", "Override release published_at (RFC3339, YYYY-MM-DD, unix)")
+	b.WriteString("                            " + renderGreyDark("Defaults to the source release's publish date") + "\n")
 	b.WriteString("\n")
 
 	// Behavior flags
 	b.WriteString(renderBold("BEHAVIOR FLAGS") + "\n")
 	writeFlag(&b, "--offline", "Sign events without uploading/publishing (outputs JSON)")
+	writeFlag(&b, "--sign-only", "Sign events for blobs already on Blossom (outputs JSONL)")
+	b.WriteString("                            " + renderGreyDark("(no uploads, no relays; fails if a blob is missing)") + "\n")
 	b.WriteString("                            " + renderGreyDark("Events go to stdout, upload manifest to stderr") + "\n")
+	writeFlag(&b, "--manifest-json <file>", "Also write the upload manifest as a JSON array (- for stdout)")
+	b.WriteString("                            " + renderGreyDark("With --offline or an npub signer, for tools that upload the blobs") + "\n")
+	writeFlag(&b, "--blossom-auth-out <file>", "Write unsigned Blossom upload auth events (JSONL, - for stdout)")
+	b.WriteString("                            " + renderGreyDark("With an npub signer; sign them externally to upload the manifest files") + "\n")
 	writeFlag(&b, "-q, --quiet", "No prompts, no spinners, auto-yes to all confirmations")
+	writeFlag(&b, "--dev", "Publish to a local relay and Blossom server with the test key")
+	b.WriteString("                            " + renderGreyDark("ws://localhost:10547 (ZSP_DEV_RELAY) and http://localhost:3000; auto-yes") + "\n")
+	writeFlag(&b, "--ephemeral-key", "Test publish with a throwaway key, printed at the end")
+	b.WriteString("                            " + renderGreyDark("SIGN_WITH, if set, signs a label linking it as your test publisher") + "\n")
 	writeFlag(&b, "--wizard", "Run interactive wizard (uses existing config as defaults)")
 	writeFlag(&b, "--skip-preview", "Skip the browser preview prompt")
+	writeFlag(&b, "--preview-url-only", "Serve the preview and print its URL without opening a browser")
+	b.WriteString("                            " + renderGreyDark("(open it through an SSH tunnel on a machine without a display)") + "\n")
+	writeFlag(&b, "--no-preview-images", "Preview metadata with screenshot placeholders")
+	b.WriteString("                            " + renderGreyDark("Remote images are downloaded after the preview, before upload") + "\n")
 	writeFlag(&b, "--port <port>", "Custom port for browser preview/signing")
+	b.WriteString("                            " + renderGreyDark("Must be free; without it a busy default moves to the next free port") + "\n")
+	writeFlag(&b, "--min-relay-success <n>", "Succeed when at least N relays accept each event")
+	b.WriteString("                            " + renderGreyDark("Default: every relay must accept; controls release cache commit") + "\n")
+	writeFlag(&b, "--verify-after-publish", "Read app and release events back after publishing (default: on)")
+	b.WriteString("                            " + renderGreyDark("Fails if relay.zapstore.dev (or the first relay) kept another event; =false to skip") + "\n")
+	writeFlag(&b, "--relay-info", "Print each relay's NIP-11 limits and restrictions before publishing")
+	b.WriteString("                            " + renderGreyDark("Likely rejections (size limits, paid or restricted relays) are always warned about") + "\n")
+	writeFlag(&b, "--relays <mode>", "Publish to signer's NIP-65 write relays: nip65 or nip65-only")
+	b.WriteString("                            " + renderGreyDark("nip65 also adds relay.zapstore.dev; RELAY_URLS are the bootstrap relays") + "\n")
+	writeFlag(&b, "--limit-rate <rate>", "Limit APK download and Blossom upload bandwidth, e.g. 2M or 500K")
+	b.WriteString("                            " + renderGreyDark("Bytes per second shared by all transfers; overrides ZSP_LIMIT_RATE") + "\n")
+	writeFlag(&b, "--max-download-rate <rate>", "Limit APK download bandwidth only, e.g. 2MB/s")
+	writeFlag(&b, "--max-upload-rate <rate>", "Limit Blossom upload bandwidth only, e.g. 512K")
+	b.WriteString("                            " + renderGreyDark("Each replaces --limit-rate for its direction") + "\n")
+	writeFlag(&b, "--metrics-out <file>", "Update a Prometheus textfile-collector metrics file at exit")
+	b.WriteString("                            " + renderGreyDark("Counters accumulate across runs that share the file") + "\n")
+	writeFlag(&b, "--bug-report", "On failure, write a redacted diagnostic bundle to attach to an issue")
+	b.WriteString("                            " + renderGreyDark("Interactive runs offer it after an error; nothing is sent anywhere") + "\n")
 	writeFlag(&b, "--no-compress", "Preserve original icon and screenshot bytes")
+	writeFlag(&b, "--strict-images", "Fail if a screenshot is unreachable or not an image")
+	b.WriteString("                            " + renderGreyDark("By default broken screenshots are dropped with a warning") + "\n")
+	writeFlag(&b, "--strict-versioning", "Fail if the versionCode is not above every channel's published ones")
+	b.WriteString("                            " + renderGreyDark("Without it, a lower versionCode than main/beta/... is only a warning") + "\n")
+	writeFlag(&b, "--strict-redirects", "Fail if the asset URL redirects to a new host")
+	writeFlag(&b, "--allow-different-author", "Publish even if relays list the app under another pubkey")
+	b.WriteString("                            " + renderGreyDark("Without it, a signer that is not the app's author is refused") + "\n")
+	writeFlag(&b, "--confirm-cert-change", "Publish although the APK is signed with a new certificate")
+	b.WriteString("                            " + renderGreyDark("Without it, a certificate change not covered by v3 key rotation is refused") + "\n")
+	writeFlag(&b, "--no-blurhash", "Omit the icon blurhash placeholder (imeta tag) from the app event")
+	writeFlag(&b, "--partial-assets", "Upload before publishing; drop failed screenshots/icon instead of aborting")
+	b.WriteString("                            " + renderGreyDark("A failed APK upload still aborts, with nothing published") + "\n")
+	writeFlag(&b, "--keep-going", "With several config files, publish the rest after one fails")
+	b.WriteString("                            " + renderGreyDark("Prints a summary; exits non-zero if any config failed") + "\n")
+	writeFlag(&b, "--no-retract-on-partial", "Keep events stored by a relay that rejected a required one")
+	writeFlag(&b, "--delegation <tag>", "Publish on behalf of a NIP-26 delegator (JSON delegation tag)")
+	writeFlag(&b, "--add-to-set <naddr>", "After publishing, add the app to a curation set you own")
+	b.WriteString("                            " + renderGreyDark("NIP-51 kind 30267 set, re-signed with SIGN_WITH") + "\n")
+	writeFlag(&b, "--wait-lock <duration>", "Wait (e.g. 10m) for another publish of the same package to finish")
+	b.WriteString("                            " + renderGreyDark("Without it, a run that finds one in progress exits with code 75") + "\n")
+	writeFlag(&b, "--icon-density <dpi>", "Extract the APK icon at ldpi..xxxhdpi, or max for the largest raster")
+	writeFlag(&b, "--lenient-parse", "Recover package and version from a manifest that fails to decode")
+	b.WriteString("                            " + renderGreyDark("For nonstandard binary XML; other manifest fields may be missing") + "\n")
+	b.WriteString("                            " + renderGreyDark("Falls back to the automatic pick if the APK has no such raster") + "\n")
+	writeFlag(&b, "--allow-v1-only", "Allow APKs signed only with the legacy v1 (JAR) scheme")
+	b.WriteString("                            " + renderGreyDark("Such APKs may not install on Android 11+; --check fails without it") + "\n")
+	writeFlag(&b, "--trust-local-clock", "Use the local clock for created_at even if it looks skewed")
+	b.WriteString("                            " + renderGreyDark("By default, skew over 5 minutes from HTTPS Date headers uses network time") + "\n")
 	writeFlag(&b, "--app-created-at-release", "Use release date for kind 32267 created_at")
 	writeFlag(&b, "--skip-app-event", "Publish only release events, skip kind 32267 app metadata")
 	b.WriteString("                            " + renderGreyDark("Used by indexer after copying developer's 32267") + "\n")
@@ -159,12 +248,17 @@ func PublishHelp() string {
 	// Source behavior flags
 	b.WriteString(renderBold("SOURCE BEHAVIOR FLAGS") + "\n")
 	writeFlag(&b, "--pre-release", "Include pre-releases when fetching the latest release")
+	writeFlag(&b, "--pick-release", "Choose which of the recent releases to publish")
 	writeFlag(&b, "--skip-certificate-linking", "Skip certificate-to-identity linking check")
 	b.WriteString("\n")
 
 	// Cache flags
 	b.WriteString(renderBold("CACHE FLAGS") + "\n")
 	writeFlag(&b, "--overwrite-release", "Bypass cache and re-publish even if release unchanged")
+	writeFlag(&b, "--only-new-assets", "Add the APK to the published release, keeping its other assets")
+	writeFlag(&b, "--ignore-release-age", "Publish even if the release is younger than min_release_age")
+	writeFlag(&b, "--overwrite-app <mode>", "App metadata update: merge (default) or replace")
+	b.WriteString("                            " + renderGreyDark("merge keeps published fields this build leaves empty") + "\n")
 	writeFlag(&b, "--skip-metadata", "Skip fetching metadata from external sources")
 	b.WriteString("                            " + renderGreyDark("Useful for apps with frequent releases") + "\n")
 	b.WriteString("\n")
@@ -173,10 +267,20 @@ func PublishHelp() string {
 	b.WriteString(renderBold("OTHER FLAGS") + "\n")
 	writeFlag(&b, "--check", "Verify config fetches arm64-v8a APK (exit 0=success)")
 	b.WriteString("                            " + renderGreyDark("Outputs {\"package_id\":\"...\"} on success") + "\n")
+	writeFlag(&b, "--explain", "Outline what each step would do, then exit")
+	b.WriteString("                            " + renderGreyDark("Reads only the config and environment; nothing is fetched or published") + "\n")
+	writeFlag(&b, "--explain-selection", "Show why each release asset was or wasn't selected")
+	b.WriteString("                            " + renderGreyDark("Does not download or publish; JSON to stdout with --json") + "\n")
 	writeFlag(&b, "--json", "Machine-readable output (implies --no-color, no prompts, no spinners)")
 	b.WriteString("                            " + renderGreyDark("Errors: {\"error\":\"...\"} to stderr; events: JSONL to stdout") + "\n")
 	b.WriteString("                            " + renderGreyDark("Nothing to do: silent exit 0") + "\n")
+	writeFlag(&b, "--progress-json", "Progress, prompts and result as JSON lines on stderr")
+	b.WriteString("                            " + renderGreyDark("Replaces the human UI, for wrapping UIs (schema v1)") + "\n")
+	writeFlag(&b, "--progress-fd <fd|path>", "Send --progress-json events to a descriptor or named pipe")
+	writeFlag(&b, "--answers-file <file>", "Answer --progress-json prompts by ID; others are read from stdin")
 	writeFlag(&b, "--verbose", "Debug output")
+	writeFlag(&b, "--trace", "Log every HTTP request and response to stderr")
+	b.WriteString("                            " + renderGreyDark("Sources, CDNs, images and Blossom; credentials redacted") + "\n")
 	writeFlag(&b, "--no-color", "Disable colored output")
 	writeFlag(&b, "-h, --help", "Show this help")
 	b.WriteString("\n")
@@ -192,6 +296,8 @@ func PublishHelp() string {
 
 	b.WriteString(renderGreyDark("  # Publish local APK with repository metadata") + "\n")
 	b.WriteString("  " + renderAccent("zsp publish app-release.apk -r github.com/user/app") + "\n\n")
+	b.WriteString(renderGreyDark("  # Publish the build installed on a USB-connected device") + "\n")
+	b.WriteString("  " + renderAccent("zsp publish adb://com.example.app -r github.com/user/app") + "\n\n")
 
 	b.WriteString(renderGreyDark("  # Fetch latest release from GitHub and publish") + "\n")
 	b.WriteString("  " + renderAccent("zsp publish -r github.com/AeonBTC/mempal") + "\n\n")
@@ -240,6 +346,7 @@ func PublishHelp() string {
 	b.WriteString(renderBold("EXIT CODES") + "\n")
 	b.WriteString("  " + renderAccent("0") + "   Success (or nothing to do — release already published)\n")
 	b.WriteString("  " + renderAccent("1") + "   Error (config invalid, source unreachable, signing failed, etc.)\n")
+	b.WriteString("  " + renderAccent("75") + "  Another publish of the same package is in progress\n")
 	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
 
 	return b.String()
@@ -319,7 +426,7 @@ func IdentityHelp() string {
 	// Environment variables
 	b.WriteString(renderBold("ENVIRONMENT") + "\n")
 	b.WriteString(renderGreyDark("  Variables can be set in environment or .env file") + "\n\n")
-	b.WriteString("  " + renderAccent("SIGN_WITH") + "           " + renderWhite("Signing method (nsec1..., npub1..., bunker://..., browser)") + "\n")
+	b.WriteString("  " + renderAccent("SIGN_WITH") + "           " + renderWhite("Signing method (nsec1..., npub1..., keychain:npub1..., bunker://..., browser)") + "\n")
 	b.WriteString("  " + renderAccent("KEYSTORE_PASSWORD") + "   " + renderWhite("PKCS12 keystore password (avoids prompt, required for piping)") + "\n\n")
 
 	b.WriteString(renderGreyDark("  Note: JKS format is not directly supported. Convert with:") + "\n")
@@ -369,8 +476,10 @@ func UtilsHelp() string {
 
 	b.WriteString(renderBold("FLAGS") + "\n")
 	writeFlag(&b, "--pre-release", "Include pre-releases when checking for a new release")
+	writeFlag(&b, "--icon-density <dpi>", "extract-apk: icon density to extract (ldpi..xxxhdpi, max)")
 	writeFlag(&b, "--json", "Machine-readable output (errors as JSON to stderr)")
 	writeFlag(&b, "--verbose", "Debug output")
+	writeFlag(&b, "--trace", "Log every HTTP request and response to stderr")
 	writeFlag(&b, "--no-color", "Disable colored output")
 	writeFlag(&b, "-h, --help", "Show this help")
 	b.WriteString("\n")
@@ -382,6 +491,316 @@ func UtilsHelp() string {
 	return b.String()
 }
 
+// ConfigHelp returns colorful help for the config subcommand.
+func ConfigHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp config") + " " + renderWhite("— Config file maintenance") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp config") + " <operation> [args]\n\n")
+
+	b.WriteString(renderBold("OPERATIONS") + "\n")
+	writeFlag(&b, "migrate <config.yaml>", "Rewrite deprecated keys to the current config format")
+	b.WriteString("                            " + renderGreyDark("changelog -> release_notes, zapstore-cli format, release_source shorthands") + "\n")
+	b.WriteString("                            " + renderGreyDark("Writes a backup to <config.yaml>.bak") + "\n")
+	writeFlag(&b, "validate [config.yaml]", "Check a config without downloading APKs or signing")
+	b.WriteString("                            " + renderGreyDark("Patterns, license/tags/NIPs, relay field limits, URL reachability") + "\n")
+	b.WriteString("                            " + renderGreyDark("Defaults to ./zapstore.yaml") + "\n")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Preview the migrated config without writing it") + "\n")
+	b.WriteString("  " + renderAccent("zsp config migrate --dry-run zapstore.yaml") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # CI gate: validate without network access, findings as JSON") + "\n")
+	b.WriteString("  " + renderAccent("zsp config validate --offline --json zapstore.yaml") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "--dry-run", "Print the migrated config to stdout, leave the file untouched")
+	writeFlag(&b, "--offline", "validate: skip URL reachability checks")
+	writeFlag(&b, "--json", "Machine-readable output (validate: findings as JSON to stdout)")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Success\n")
+	b.WriteString("  " + renderAccent("1") + "   Error (unreadable or invalid config; validate found errors)\n")
+
+	return b.String()
+}
+
+// HistoryHelp returns colorful help for the history subcommand.
+func HistoryHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp history") + " " + renderWhite("— Releases published from this machine") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp history") + " [options] [package-id]\n\n")
+
+	b.WriteString(renderBold("DESCRIPTION") + "\n")
+	b.WriteString("  " + renderWhite("Each successful publish records its signed events locally (last 200 releases).") + "\n")
+	b.WriteString("  " + renderWhite("Interactive mode re-broadcasts them without re-signing or a config file.") + "\n\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # List every recorded release, newest first") + "\n")
+	b.WriteString("  " + renderAccent("zsp history") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Pick a release of one app and re-broadcast it") + "\n")
+	b.WriteString("  " + renderAccent("zsp history -i com.example.app") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "-i, --interactive", "Select a release to view its events or re-broadcast it")
+	writeFlag(&b, "--json", "Print entries (with their events) as JSONL to stdout")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Success\n")
+	b.WriteString("  " + renderAccent("1") + "   Error (unreadable history, publish failed)\n")
+	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
+
+	return b.String()
+}
+
+// APKHelp returns colorful help for the apk subcommand.
+func APKHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp apk") + " " + renderWhite("— Inspect APK files") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp apk --compare-cert") + " <file.apk> <npub|certificate>\n")
+	b.WriteString("  " + renderAccent("zsp apk --lint") + " <file.apk> [zapstore.yaml]\n\n")
+
+	b.WriteString(renderBold("DESCRIPTION") + "\n")
+	b.WriteString("  " + renderWhite("Checks whether an APK was signed by a known signer. With an npub, the APK's") + "\n")
+	b.WriteString("  " + renderWhite("certificate must have a valid, active NIP-C1 identity proof (kind 30509)") + "\n")
+	b.WriteString("  " + renderWhite("from that npub. With a certificate file, the certificates must be the same.") + "\n\n")
+	b.WriteString("  " + renderWhite("--lint checks an APK for packaging mistakes (debuggable, testOnly, v1-only") + "\n")
+	b.WriteString("  " + renderWhite("signature, missing arm64, manifest placeholders, large entries, SDK levels,") + "\n")
+	b.WriteString("  " + renderWhite("ABI mismatches) and prints a scored report. Checks are skipped with the") + "\n")
+	b.WriteString("  " + renderWhite("config's apk_lint: {ignore: [...]}.") + "\n\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Was this APK signed by a certificate the developer vouches for?") + "\n")
+	b.WriteString("  " + renderAccent("zsp apk --compare-cert app.apk npub1...") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Compare against a certificate you already trust") + "\n")
+	b.WriteString("  " + renderAccent("zsp apk --compare-cert app.apk release-cert.pem") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Lint every CI build, failing on warnings too") + "\n")
+	b.WriteString("  " + renderAccent("zsp apk --lint app.apk --fail-on warning") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "--compare-cert", "Compare the APK's signing certificate with an npub or certificate")
+	writeFlag(&b, "--relays <url>", "Relays to fetch identity proofs from (repeatable)")
+	writeFlag(&b, "--lint", "Check the APK for common packaging mistakes")
+	writeFlag(&b, "--fail-on <severity>", "Lint exits 1 on findings at or above: info, warning, error, none")
+	b.WriteString("                            " + renderGreyDark("Defaults to the config's apk_lint fail_on, or error") + "\n")
+	writeFlag(&b, "--json", "Print the result as JSON to stdout")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Verified, active match\n")
+	b.WriteString("  " + renderAccent("1") + "   No match, missing/expired/revoked proof, failing lint findings, or error\n")
+	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
+
+	return b.String()
+}
+
+// BlossomHelp returns colorful help for the blossom subcommand.
+func BlossomHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp blossom") + " " + renderWhite("— Manage your blobs on the Blossom server") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp blossom") + " <operation> [options]\n\n")
+
+	b.WriteString(renderBold("OPERATIONS") + "\n")
+	writeFlag(&b, "list", "List blobs uploaded by the SIGN_WITH key, newest first")
+	b.WriteString("                            " + renderGreyDark("Shows hash, size, upload date, package and referencing events") + "\n")
+	writeFlag(&b, "prune", "Delete blobs that no relay event references")
+	b.WriteString("                            " + renderGreyDark("Lists exactly what will be deleted and asks before deleting") + "\n")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("DESCRIPTION") + "\n")
+	b.WriteString("  " + renderWhite("Blobs are listed from BLOSSOM_URL (default: https://cdn.zapstore.dev). A blob is") + "\n")
+	b.WriteString("  " + renderWhite("referenced when an event on RELAY_URLS carries its hash in an x tag or a URL.") + "\n")
+	b.WriteString("  " + renderWhite("Referenced blobs are never deleted.") + "\n\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # See what prune would delete") + "\n")
+	b.WriteString("  " + renderAccent("zsp blossom prune --dry-run") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Delete orphaned blobs, sparing the 3 newest of each app") + "\n")
+	b.WriteString("  " + renderAccent("zsp blossom prune --keep-last 3") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "--dry-run", "prune: list the blobs that would be deleted, delete nothing")
+	writeFlag(&b, "--keep-last <n>", "prune: keep the n most recently uploaded blobs of each package")
+	b.WriteString("                            " + renderGreyDark("Protects uploads whose events are not on the relays yet") + "\n")
+	writeFlag(&b, "--yes", "prune: delete without asking (required with --json)")
+	writeFlag(&b, "--json", "Print blobs as JSONL to stdout")
+	writeFlag(&b, "--trace", "Log every HTTP request and response to stderr")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Success\n")
+	b.WriteString("  " + renderAccent("1") + "   Error (server or relays unreachable, a delete failed)\n")
+	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
+
+	return b.String()
+}
+
+// RelayHelp returns colorful help for the relay subcommand.
+func RelayHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp relay") + " " + renderWhite("— Inspect the relays zsp publishes to") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp relay info") + " [relay-url...] [options]\n\n")
+
+	b.WriteString(renderBold("OPERATIONS") + "\n")
+	writeFlag(&b, "info", "Show each relay's NIP-11 information document")
+	b.WriteString("                            " + renderGreyDark("Name, software, supported NIPs, limits, AUTH/payment requirements") + "\n")
+	b.WriteString("                            " + renderGreyDark("and restrictions on kinds 32267, 30063 and 3063") + "\n")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("DESCRIPTION") + "\n")
+	b.WriteString("  " + renderWhite("Without relay URLs, the RELAY_URLS relays (default: wss://relay.zapstore.dev) are") + "\n")
+	b.WriteString("  " + renderWhite("shown. zsp publish checks the same document before publishing and warns about") + "\n")
+	b.WriteString("  " + renderWhite("events a relay is likely to reject.") + "\n\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Check the default publish relays") + "\n")
+	b.WriteString("  " + renderAccent("zsp relay info") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Check specific relays") + "\n")
+	b.WriteString("  " + renderAccent("zsp relay info wss://relay.zapstore.dev wss://nos.lol") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "--json", "Print one JSON object per relay to stdout")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Success\n")
+	b.WriteString("  " + renderAccent("1") + "   Error (a relay's information could not be fetched)\n")
+	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
+
+	return b.String()
+}
+
+// KeysHelp returns colorful help for the keys subcommand.
+func KeysHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp keys") + " " + renderWhite("— Create and inspect Nostr keys") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp keys generate") + " [--keyring] [options]\n")
+	b.WriteString("  " + renderAccent("zsp keys show") + " [options]\n\n")
+
+	b.WriteString(renderBold("OPERATIONS") + "\n")
+	writeFlag(&b, "generate", "Create a new keypair and print its npub")
+	b.WriteString("                            " + renderGreyDark("With --keyring the nsec is stored in the OS keyring and signed") + "\n")
+	b.WriteString("                            " + renderGreyDark("with via SIGN_WITH=keychain:npub1...; otherwise it is printed") + "\n")
+	writeFlag(&b, "show", "Print the npub of the configured SIGN_WITH")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("DESCRIPTION") + "\n")
+	b.WriteString("  " + renderWhite("The OS keyring is the macOS login keychain (security) or the Secret Service") + "\n")
+	b.WriteString("  " + renderWhite("on Linux (secret-tool, from libsecret). show connects to bunker and browser") + "\n")
+	b.WriteString("  " + renderWhite("signers to ask for their public key.") + "\n\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Create a key kept in the OS keyring") + "\n")
+	b.WriteString("  " + renderAccent("zsp keys generate --keyring") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Check which npub SIGN_WITH signs as") + "\n")
+	b.WriteString("  " + renderAccent("SIGN_WITH=keychain:npub1... zsp keys show") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "--keyring", "generate: store the nsec in the OS keyring instead of printing it")
+	writeFlag(&b, "-q, --quiet", "Print only the key data; warnings still go to stderr")
+	writeFlag(&b, "--json", "Print the result as a JSON object to stdout")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Success\n")
+	b.WriteString("  " + renderAccent("1") + "   Error (keyring unavailable, SIGN_WITH unset or invalid)\n")
+	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
+
+	return b.String()
+}
+
+// ExportHelp returns colorful help for the export subcommand.
+func ExportHelp() string {
+	var b strings.Builder
+
+	b.WriteString(renderBold("zsp export") + " " + renderWhite("— Export published release data for websites") + "\n\n")
+
+	b.WriteString(renderBold("USAGE") + "\n")
+	b.WriteString("  " + renderAccent("zsp export badge") + " --package <id> [--out badge.json] [--svg badge.svg] [options]\n\n")
+
+	b.WriteString(renderBold("OPERATIONS") + "\n")
+	writeFlag(&b, "badge", "Write a shields.io endpoint JSON for the latest release")
+	b.WriteString("                            " + renderGreyDark("The highest version (semver order) on the channel published by") + "\n")
+	b.WriteString("                            " + renderGreyDark("the pubkey on RELAY_URLS; --svg writes a static badge too") + "\n")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("DESCRIPTION") + "\n")
+	b.WriteString("  " + renderWhite("The badge reads \"zapstore | <version>\" and is green when the release and") + "\n")
+	b.WriteString("  " + renderWhite("its assets carry valid signatures. The JSON also holds the app's naddr and") + "\n")
+	b.WriteString("  " + renderWhite("zapstore.dev URL. Commit the files to a gh-pages branch after each publish.") + "\n\n")
+
+	b.WriteString(renderBold("EXAMPLES") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Endpoint JSON for https://img.shields.io/endpoint?url=...") + "\n")
+	b.WriteString("  " + renderAccent("zsp export badge --package com.example.app --out badge.json") + "\n\n")
+
+	b.WriteString(renderGreyDark("  # Static SVG for a publisher other than SIGN_WITH") + "\n")
+	b.WriteString("  " + renderAccent("zsp export badge --package com.example.app --pubkey npub1... --svg badge.svg") + "\n\n")
+
+	b.WriteString(renderBold("FLAGS") + "\n")
+	writeFlag(&b, "--package <id>", "Package ID of the app (required)")
+	writeFlag(&b, "--out <file>", "Write the endpoint JSON to this file (default: stdout)")
+	writeFlag(&b, "--svg <file>", "Also write a static SVG badge")
+	writeFlag(&b, "--channel <name>", "Release channel (default: main)")
+	writeFlag(&b, "--label <text>", "Badge label (default: zapstore)")
+	writeFlag(&b, "--pubkey <npub>", "Publisher (default: the SIGN_WITH npub)")
+	writeFlag(&b, "--json", "Print errors as JSON")
+	writeFlag(&b, "--no-color", "Disable colored output")
+	writeFlag(&b, "-h, --help", "Show this help")
+	b.WriteString("\n")
+
+	b.WriteString(renderBold("EXIT CODES") + "\n")
+	b.WriteString("  " + renderAccent("0") + "   Success\n")
+	b.WriteString("  " + renderAccent("1") + "   Error (no release found, relays unreachable, unwritable file)\n")
+	b.WriteString("  " + renderAccent("130") + " Cancelled (Ctrl+C)\n")
+
+	return b.String()
+}
+
 // HandleHelp processes help for a command.
 func HandleHelp(cmd cli.Command, args []string) {
 	// Show command-specific help
@@ -392,6 +811,20 @@ func HandleHelp(cmd cli.Command, args []string) {
 		fmt.Fprint(os.Stdout, IdentityHelp())
 	case cli.CommandUtils:
 		fmt.Fprint(os.Stdout, UtilsHelp())
+	case cli.CommandConfig:
+		fmt.Fprint(os.Stdout, ConfigHelp())
+	case cli.CommandHistory:
+		fmt.Fprint(os.Stdout, HistoryHelp())
+	case cli.CommandAPK:
+		fmt.Fprint(os.Stdout, APKHelp())
+	case cli.CommandBlossom:
+		fmt.Fprint(os.Stdout, BlossomHelp())
+	case cli.CommandRelay:
+		fmt.Fprint(os.Stdout, RelayHelp())
+	case cli.CommandKeys:
+		fmt.Fprint(os.Stdout, KeysHelp())
+	case cli.CommandExport:
+		fmt.Fprint(os.Stdout, ExportHelp())
 	default:
 		fmt.Fprint(os.Stdout, RootHelp())
 	}
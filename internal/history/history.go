@@ -0,0 +1,110 @@
+// Package history keeps a local record of published releases and their signed events.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// maxEntries is how many releases the history file keeps; older ones are dropped.
+const maxEntries = 200
+
+// maxLineSize bounds a single history line (an entry holds a few signed events).
+const maxLineSize = 4 * 1024 * 1024
+
+// Entry is one published release.
+type Entry struct {
+	PackageID   string         `json:"package_id"`
+	Version     string         `json:"version"`
+	PublishedAt time.Time      `json:"published_at"`
+	Relays      []string       `json:"relays"`
+	Events      []*nostr.Event `json:"events"` // signed app, release and asset events
+}
+
+// Path returns the history file location.
+func Path() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "zsp", "history.jsonl")
+}
+
+// Load returns the recorded entries, newest first. A missing file yields no entries.
+func Load() ([]Entry, error) {
+	f, err := os.Open(Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue // skip a truncated or foreign line rather than losing the rest
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+// Append records an entry, keeping at most maxEntries.
+func Append(entry Entry) error {
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+	entries = append([]Entry{entry}, entries...)
+	if len(entries) > maxEntries {
+		entries = entries[:maxEntries]
+	}
+
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".history-*")
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if err := enc.Encode(entries[i]); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	entries, err := Load()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Load() on missing file = %v, %v", entries, err)
+	}
+
+	for _, v := range []string{"1.0.0", "1.1.0"} {
+		err := Append(Entry{
+			PackageID:   "com.example.app",
+			Version:     v,
+			PublishedAt: time.Unix(1700000000, 0).UTC(),
+			Relays:      []string{"wss://relay.example.com"},
+			Events:      []*nostr.Event{{Kind: 30063, Tags: nostr.Tags{{"d", "com.example.app@" + v}}}},
+		})
+		if err != nil {
+			t.Fatalf("Append(%s) error: %v", v, err)
+		}
+	}
+
+	entries, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Version != "1.1.0" || entries[1].Version != "1.0.0" {
+		t.Fatalf("entries = %+v, want 1.1.0 then 1.0.0", entries)
+	}
+	if d := entries[0].Events[0].Tags.GetD(); d != "com.example.app@1.1.0" {
+		t.Errorf("stored event d tag = %q", d)
+	}
+}
+
+func TestAppendKeepsNewestEntries(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	for i := 0; i < maxEntries+5; i++ {
+		if err := Append(Entry{PackageID: "com.example.app", Version: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxEntries || entries[0].Version != fmt.Sprint(maxEntries+4) {
+		t.Errorf("got %d entries, newest %q", len(entries), entries[0].Version)
+	}
+}
+
+func TestLoadSkipsCorruptLines(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	if err := Append(Entry{PackageID: "com.example.app", Version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(Path(), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{truncated\n")
+	f.Close()
+
+	entries, err := Load()
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Load() = %d entries, %v; want 1", len(entries), err)
+	}
+}
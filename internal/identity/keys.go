@@ -0,0 +1,33 @@
+package identity
+
+import (
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// NostrKey is a Nostr keypair in hex and NIP-19 form.
+type NostrKey struct {
+	Pubkey string // hex
+	Npub   string
+	Nsec   string
+}
+
+// GenerateNostrKey creates a new random Nostr keypair.
+func GenerateNostrKey() (*NostrKey, error) {
+	sk := nostr.GeneratePrivateKey()
+	pk, err := nostr.GetPublicKey(sk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive public key: %w", err)
+	}
+	nsec, err := nip19.EncodePrivateKey(sk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	npub, err := nip19.EncodePublicKey(pk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	return &NostrKey{Pubkey: pk, Npub: npub, Nsec: nsec}, nil
+}
@@ -0,0 +1,21 @@
+package identity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestGenerateNostrKey(t *testing.T) {
+	key, err := GenerateNostrKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key.Npub, "npub1") || !strings.HasPrefix(key.Nsec, "nsec1") {
+		t.Fatalf("GenerateNostrKey() = %+v, want npub and nsec", key)
+	}
+	if _, pk, err := nip19.Decode(key.Npub); err != nil || pk != key.Pubkey {
+		t.Errorf("npub decodes to %v (%v), want %s", pk, err, key.Pubkey)
+	}
+}
@@ -0,0 +1,91 @@
+// Package keyring stores secrets in the operating system's credential store:
+// the login keychain on macOS (security) and the Secret Service on Linux and
+// the BSDs (secret-tool from libsecret, backed by GNOME Keyring or KWallet).
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Service is the service name zsp stores its secrets under.
+const Service = "zsp"
+
+var (
+	// ErrUnsupported is returned when no OS keyring tool is available.
+	ErrUnsupported = errors.New("no OS keyring available")
+
+	// ErrNotFound is returned by Get when the keyring has no secret for the account.
+	ErrNotFound = errors.New("not found in the OS keyring")
+)
+
+// runCommand runs name with args, feeding stdin, and returns its trimmed
+// stdout and exit code. Replaced in tests.
+var runCommand = func(stdin, name string, args ...string) (string, int, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", 0, fmt.Errorf("%w: %s not found", ErrUnsupported, name)
+	}
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = exitErr.Error()
+		}
+		return "", exitErr.ExitCode(), fmt.Errorf("%s: %s", name, msg)
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("running %s: %w", name, err)
+	}
+	return strings.TrimSpace(stdout.String()), 0, nil
+}
+
+// goos is runtime.GOOS, replaced in tests.
+var goos = runtime.GOOS
+
+// Set stores secret for account, replacing any secret already stored.
+func Set(account, secret string) error {
+	switch goos {
+	case "darwin":
+		// add-generic-password only takes the secret as an argument, which any
+		// local user can read with ps, so the command goes through security -i
+		if strings.ContainsAny(account+secret, "\"\\\n") {
+			return fmt.Errorf("cannot store a secret or account containing quotes, backslashes or newlines")
+		}
+		command := fmt.Sprintf("add-generic-password -U -s %q -a %q -w %q\n", Service, account, secret)
+		_, _, err := runCommand(command, "security", "-i")
+		return err
+	case "linux", "freebsd", "openbsd", "netbsd":
+		_, _, err := runCommand(secret, "secret-tool", "store", "--label", Service+" "+account, "service", Service, "account", account)
+		return err
+	}
+	return fmt.Errorf("%w on %s", ErrUnsupported, goos)
+}
+
+// Get returns the secret stored for account, or ErrNotFound.
+func Get(account string) (string, error) {
+	switch goos {
+	case "darwin":
+		secret, code, err := runCommand("", "security", "find-generic-password", "-s", Service, "-a", account, "-w")
+		if code == 44 { // errSecItemNotFound
+			return "", fmt.Errorf("%s: %w", account, ErrNotFound)
+		}
+		return secret, err
+	case "linux", "freebsd", "openbsd", "netbsd":
+		// secret-tool exits 1 with no output when nothing matches
+		secret, code, err := runCommand("", "secret-tool", "lookup", "service", Service, "account", account)
+		if code == 1 || (err == nil && secret == "") {
+			return "", fmt.Errorf("%s: %w", account, ErrNotFound)
+		}
+		return secret, err
+	}
+	return "", fmt.Errorf("%w on %s", ErrUnsupported, goos)
+}
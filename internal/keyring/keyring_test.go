@@ -0,0 +1,112 @@
+package keyring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeKeyring stubs runCommand with an in-memory store keyed by account,
+// speaking the security and secret-tool command lines.
+func fakeKeyring(t *testing.T, os string) map[string]string {
+	t.Helper()
+	store := map[string]string{}
+	oldRun, oldGOOS := runCommand, goos
+	t.Cleanup(func() { runCommand, goos = oldRun, oldGOOS })
+	goos = os
+
+	arg := func(args []string, name string) string {
+		for i, a := range args {
+			if a == name && i+1 < len(args) {
+				return args[i+1]
+			}
+		}
+		return ""
+	}
+	runCommand = func(stdin, name string, args ...string) (string, int, error) {
+		switch name + " " + args[0] {
+		case "security -i":
+			fields := strings.Fields(stdin)
+			if len(fields) == 0 || fields[0] != "add-generic-password" {
+				t.Fatalf("unexpected security -i input %q", stdin)
+			}
+			for i := range fields {
+				fields[i] = strings.Trim(fields[i], `"`)
+			}
+			store[arg(fields, "-a")] = arg(fields, "-w")
+			return "", 0, nil
+		case "security find-generic-password":
+			if secret, ok := store[arg(args, "-a")]; ok {
+				return secret, 0, nil
+			}
+			return "", 44, errors.New("security: item not found")
+		case "secret-tool store":
+			store[arg(args, "account")] = stdin
+			return "", 0, nil
+		case "secret-tool lookup":
+			if secret, ok := store[arg(args, "account")]; ok {
+				return secret, 0, nil
+			}
+			return "", 1, errors.New("secret-tool: exit status 1")
+		}
+		t.Fatalf("unexpected command %s %s", name, strings.Join(args, " "))
+		return "", 0, nil
+	}
+	return store
+}
+
+func TestSetGet(t *testing.T) {
+	for _, os := range []string{"darwin", "linux"} {
+		t.Run(os, func(t *testing.T) {
+			fakeKeyring(t, os)
+
+			if _, err := Get("npub1a"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get() before Set error = %v, want ErrNotFound", err)
+			}
+			if err := Set("npub1a", "nsec1old"); err != nil {
+				t.Fatal(err)
+			}
+			if err := Set("npub1a", "nsec1new"); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Get("npub1a")
+			if err != nil || got != "nsec1new" {
+				t.Errorf("Get() = %q, %v, want the replaced secret", got, err)
+			}
+		})
+	}
+}
+
+func TestSetKeepsSecretOutOfArgs(t *testing.T) {
+	for _, os := range []string{"darwin", "linux"} {
+		t.Run(os, func(t *testing.T) {
+			fakeKeyring(t, os)
+			run := runCommand
+			runCommand = func(stdin, name string, args ...string) (string, int, error) {
+				for _, a := range args {
+					if strings.Contains(a, "nsec1secret") {
+						t.Errorf("secret passed as an argument to %s: %v", name, args)
+					}
+				}
+				return run(stdin, name, args...)
+			}
+
+			if err := Set("npub1a", "nsec1secret"); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := Get("npub1a"); got != "nsec1secret" {
+				t.Errorf("Get() = %q, want the stored secret", got)
+			}
+		})
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	fakeKeyring(t, "plan9")
+	if err := Set("npub1a", "nsec1"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Set() error = %v, want ErrUnsupported", err)
+	}
+	if _, err := Get("npub1a"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Get() error = %v, want ErrUnsupported", err)
+	}
+}
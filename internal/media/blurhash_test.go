@@ -0,0 +1,54 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestBlurhash(t *testing.T) {
+	encode := func(fill func(x, y int) color.Color) []byte {
+		img := image.NewNRGBA(image.Rect(0, 0, 48, 48))
+		for y := 0; y < 48; y++ {
+			for x := 0; x < 48; x++ {
+				img.Set(x, y, fill(x, y))
+			}
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	white, err := Blurhash(encode(func(int, int) color.Color { return color.White }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Size flag "U" (4x4 components), then the DC color #ffffff as "TSUA"
+	if len(white) != 6+2*15 || white[0] != 'U' || white[2:6] != "TSUA" {
+		t.Errorf("Blurhash(white) = %q", white)
+	}
+
+	transparent, err := Blurhash(encode(func(int, int) color.Color { return color.Transparent }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transparent != white {
+		t.Errorf("transparent icon hash %q, want it rendered on white (%q)", transparent, white)
+	}
+
+	gradient, err := Blurhash(encode(func(x, _ int) color.Color { return color.Gray{Y: uint8(x * 5)} }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gradient) != 6+2*15 || gradient == white {
+		t.Errorf("Blurhash(gradient) = %q", gradient)
+	}
+
+	if _, err := Blurhash([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>")); err == nil {
+		t.Error("expected error for SVG input")
+	}
+}
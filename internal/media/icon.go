@@ -0,0 +1,98 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// IconMinSize is the default minimum icon width and height (icon_min_size).
+	IconMinSize = 192
+
+	// ScreenshotMinWidth and ScreenshotMaxAspect bound screenshots that render
+	// acceptably in app stores; screenshots outside them only get warnings.
+	ScreenshotMinWidth  = 320
+	ScreenshotMaxAspect = 3.0
+
+	// iconSquareTolerance is how far an icon's sides may differ, as a fraction
+	// of the longer side, and still count as square.
+	iconSquareTolerance = 0.02
+)
+
+var (
+	// ErrIconNotSquare is returned by CheckIcon for icons that are not square.
+	ErrIconNotSquare = errors.New("icon is not square")
+
+	// ErrIconTooSmall is returned by CheckIcon for icons below the minimum size.
+	ErrIconTooSmall = errors.New("icon is too small")
+)
+
+// CheckIcon checks that a raster icon is square, within a small tolerance, and
+// at least minSize pixels per side. Non-square icons report ErrIconNotSquare
+// before size is considered. SVG icons always pass.
+func CheckIcon(data []byte, minSize int) error {
+	if IsSVG(data) {
+		return nil
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decoding icon: %w", err)
+	}
+	long, short := max(cfg.Width, cfg.Height), min(cfg.Width, cfg.Height)
+	if float64(long-short) > float64(long)*iconSquareTolerance {
+		return fmt.Errorf("%w: %dx%d", ErrIconNotSquare, cfg.Width, cfg.Height)
+	}
+	if short < minSize {
+		return fmt.Errorf("%w: %dx%d, need at least %dx%d", ErrIconTooSmall, cfg.Width, cfg.Height, minSize, minSize)
+	}
+	return nil
+}
+
+// PadIconToSquare centers an icon on a transparent square canvas as wide as
+// its longer side and encodes the result as PNG. The image is never scaled.
+func PadIconToSquare(data []byte) (Result, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("decoding icon: %w", err)
+	}
+	b := src.Bounds()
+	side := max(b.Dx(), b.Dy())
+	dst := image.NewNRGBA(image.Rect(0, 0, side, side))
+	offset := image.Pt((side-b.Dx())/2, (side-b.Dy())/2)
+	draw.Draw(dst, b.Sub(b.Min).Add(offset), src, b.Min, draw.Src)
+
+	var output bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&output, dst); err != nil {
+		return Result{}, fmt.Errorf("encoding PNG image: %w", err)
+	}
+	return withHash(Result{
+		Data:         output.Bytes(),
+		MimeType:     "image/png",
+		OriginalSize: len(data),
+		Changed:      true,
+	}), nil
+}
+
+// CheckScreenshot describes a screenshot that is narrower than
+// ScreenshotMinWidth or more elongated than ScreenshotMaxAspect, or returns ""
+// when it is fine or cannot be inspected (such as SVG).
+func CheckScreenshot(data []byte) string {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width == 0 || cfg.Height == 0 {
+		return ""
+	}
+	if cfg.Width < ScreenshotMinWidth {
+		return fmt.Sprintf("%dx%d is narrower than %d px", cfg.Width, cfg.Height, ScreenshotMinWidth)
+	}
+	long, short := max(cfg.Width, cfg.Height), min(cfg.Width, cfg.Height)
+	if float64(long) > float64(short)*ScreenshotMaxAspect {
+		return fmt.Sprintf("%dx%d is more elongated than 1:%g", cfg.Width, cfg.Height, ScreenshotMaxAspect)
+	}
+	return ""
+}
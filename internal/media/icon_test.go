@@ -0,0 +1,97 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"testing"
+)
+
+func TestCheckIcon(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"square 512", encodePNGTestImage(512, 512), nil},
+		{"square at minimum", encodePNGTestImage(192, 192), nil},
+		{"nearly square", encodePNGTestImage(512, 505), nil},
+		{"tiny", encodePNGTestImage(48, 48), ErrIconTooSmall},
+		{"wide", encodePNGTestImage(512, 256), ErrIconNotSquare},
+		{"tall JPEG", encodeJPEGTestImage(200, 300), ErrIconNotSquare},
+		{"small and wide", encodePNGTestImage(96, 48), ErrIconNotSquare},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="10" height="20"/>`), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckIcon(tt.data, IconMinSize)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("CheckIcon() = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckIcon() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPadIconToSquare(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantSide int
+	}{
+		{"wide PNG", encodePNGTestImage(400, 200), 400},
+		{"tall JPEG", encodeJPEGTestImage(150, 300), 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := PadIconToSquare(tt.data)
+			if err != nil {
+				t.Fatalf("PadIconToSquare() error = %v", err)
+			}
+			if result.MimeType != "image/png" || result.Hash != hashBytes(result.Data) {
+				t.Errorf("PadIconToSquare() = %s with hash %s, want a hashed PNG", result.MimeType, result.Hash)
+			}
+			img, err := png.Decode(bytes.NewReader(result.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantSide || b.Dy() != tt.wantSide {
+				t.Fatalf("padded to %dx%d, want %dx%d (never scaled)", b.Dx(), b.Dy(), tt.wantSide, tt.wantSide)
+			}
+			if err := CheckIcon(result.Data, IconMinSize); err != nil {
+				t.Errorf("CheckIcon(padded) = %v", err)
+			}
+			// The corner is padding, the center is the original image
+			if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+				t.Errorf("corner alpha = %d, want transparent padding", a)
+			}
+			center := img.At(tt.wantSide/2, tt.wantSide/2)
+			if _, _, _, a := center.RGBA(); a == 0 {
+				t.Error("center pixel is transparent, want the original image")
+			}
+		})
+	}
+}
+
+func TestCheckScreenshot(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"phone portrait", encodePNGTestImage(1080, 1920), false},
+		{"tablet landscape", encodeJPEGTestImage(1280, 800), false},
+		{"too narrow", encodePNGTestImage(240, 400), true},
+		{"banner", encodePNGTestImage(1600, 400), true},
+		{"not an image", []byte("nope"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckScreenshot(tt.data); (got != "") != tt.want {
+				t.Errorf("CheckScreenshot() = %q, want warning: %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
+	"regexp"
 	"strings"
 
 	_ "image/gif"
@@ -21,8 +23,60 @@ const (
 	IconMaxWidth       = 512
 	ScreenshotMaxWidth = 1440
 	jpegQuality        = 88
+
+	// Limits on decoded dimensions, so a small file cannot expand into a pixel flood.
+	maxImageDimension = 16384
+	maxImagePixels    = 50_000_000
 )
 
+// ErrUnsafeImage is returned by Preflight for content that must not be published.
+var ErrUnsafeImage = errors.New("unsafe image")
+
+// svgActiveContent matches SVG constructs that can run code in a viewer: script
+// elements, event handler attributes, javascript: URLs and embedded HTML.
+var svgActiveContent = regexp.MustCompile(`(?i)<script|\son[a-z]+\s*=|javascript:|<foreignobject`)
+
+// Preflight checks untrusted image bytes before they are processed or uploaded.
+// It rejects SVGs with active content, images whose dimensions exceed sane limits,
+// content that is not an image, and content whose sniffed type differs from
+// declaredMimeType (derived from the file extension; empty or
+// application/octet-stream skips that check). Returns the sniffed MIME type.
+func Preflight(data []byte, declaredMimeType string) (string, error) {
+	var mimeType string
+	if IsSVG(data) {
+		if m := svgActiveContent.Find(data); m != nil {
+			return "", fmt.Errorf("%w: SVG contains active content (%q)", ErrUnsafeImage, strings.TrimSpace(string(m)))
+		}
+		mimeType = "image/svg+xml"
+	} else {
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("%w: content is not a recognized image format", ErrUnsafeImage)
+		}
+		if cfg.Width > maxImageDimension || cfg.Height > maxImageDimension || cfg.Width*cfg.Height > maxImagePixels {
+			return "", fmt.Errorf("%w: dimensions %dx%d exceed the limit (%d px per side, %d MP)",
+				ErrUnsafeImage, cfg.Width, cfg.Height, maxImageDimension, maxImagePixels/1_000_000)
+		}
+		mimeType = "image/" + format
+	}
+
+	declared := normalizeMimeType(declaredMimeType)
+	if declared != "application/octet-stream" && declared != mimeType {
+		return "", fmt.Errorf("%w: file extension says %s but content is %s", ErrUnsafeImage, declared, mimeType)
+	}
+	return mimeType, nil
+}
+
+// IsSVG reports whether data looks like an SVG document.
+func IsSVG(data []byte) bool {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = bytes.TrimSpace(bytes.TrimPrefix(head, []byte("\xef\xbb\xbf")))
+	return bytes.HasPrefix(head, []byte("<")) && bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
 // Result contains the final bytes and metadata for an image asset.
 type Result struct {
 	Data         []byte
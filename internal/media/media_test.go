@@ -2,10 +2,14 @@ package media
 
 import (
 	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
 	"image"
 	"image/color"
 	"image/jpeg"
 	"image/png"
+	"strings"
 	"testing"
 )
 
@@ -122,3 +126,58 @@ func hashBytes(data []byte) string {
 	result, _ := Process(data, "image/png", 0, false)
 	return result.Hash
 }
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		declared string
+		wantMIME string
+		wantErr  string
+	}{
+		{name: "PNG", data: encodePNGTestImage(64, 64), declared: "image/png", wantMIME: "image/png"},
+		{name: "unknown extension", data: encodeJPEGTestImage(64, 64), declared: "", wantMIME: "image/jpeg"},
+		{name: "plain SVG", data: []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><rect width="1" height="1"/></svg>`), declared: "image/svg+xml", wantMIME: "image/svg+xml"},
+		{name: "SVG script", data: []byte(`<svg><script>alert(1)</script></svg>`), declared: "image/svg+xml", wantErr: "active content"},
+		{name: "SVG event handler", data: []byte(`<svg onload="alert(1)"></svg>`), declared: "image/svg+xml", wantErr: "active content"},
+		{name: "SVG javascript URL", data: []byte(`<svg><a href="JavaScript:alert(1)">x</a></svg>`), declared: "", wantErr: "active content"},
+		{name: "pixel flood", data: pngHeader(100000, 100000), declared: "image/png", wantErr: "exceed the limit"},
+		{name: "extension mismatch", data: encodeJPEGTestImage(64, 64), declared: "image/png", wantErr: "extension says image/png but content is image/jpeg"},
+		{name: "not an image", data: []byte("<html><body>404</body></html>"), declared: "image/png", wantErr: "not a recognized image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mimeType, err := Preflight(tt.data, tt.declared)
+			if tt.wantErr != "" {
+				if !errors.Is(err, ErrUnsafeImage) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Preflight() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Preflight() error = %v", err)
+			}
+			if mimeType != tt.wantMIME {
+				t.Errorf("mimeType = %q, want %q", mimeType, tt.wantMIME)
+			}
+		})
+	}
+}
+
+// pngHeader returns the PNG signature and IHDR chunk for an image of the given size,
+// enough for DecodeConfig without allocating the pixels.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	ihdr[12], ihdr[13] = 8, 6 // 8-bit RGBA
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	binary.Write(&buf, binary.BigEndian, uint32(13))
+	buf.Write(ihdr)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+	return buf.Bytes()
+}
@@ -0,0 +1,57 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+// IconRasterSize is the default width and height of rasterized SVG icons.
+const IconRasterSize = 512
+
+// RasterizeSVG renders an SVG image to a size x size PNG on a transparent
+// background, scaled to fit and centered. SVG features the renderer does not
+// support are skipped; an error means nothing usable could be drawn.
+func RasterizeSVG(data []byte, size int) (result Result, err error) {
+	if !IsSVG(data) {
+		return Result{}, fmt.Errorf("not an SVG image")
+	}
+	icon, err := oksvg.ReadIconStream(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing SVG: %w", err)
+	}
+	vb := icon.ViewBox
+	if vb.W <= 0 || vb.H <= 0 {
+		return Result{}, fmt.Errorf("SVG has no width, height or viewBox")
+	}
+
+	// The renderer panics on some malformed path data
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = Result{}, fmt.Errorf("rendering SVG: %v", r)
+		}
+	}()
+
+	scale := float64(size) / max(vb.W, vb.H)
+	w, h := vb.W*scale, vb.H*scale
+	icon.SetTarget((float64(size)-w)/2, (float64(size)-h)/2, w, h)
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	scanner := rasterx.NewScannerGV(size, size, img, img.Bounds())
+	icon.Draw(rasterx.NewDasher(size, size, scanner), 1)
+
+	var output bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&output, img); err != nil {
+		return Result{}, fmt.Errorf("encoding PNG image: %w", err)
+	}
+	return withHash(Result{
+		Data:         output.Bytes(),
+		MimeType:     "image/png",
+		OriginalSize: len(data),
+		Changed:      true,
+	}), nil
+}
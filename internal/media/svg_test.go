@@ -0,0 +1,51 @@
+package media
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+)
+
+func TestRasterizeSVG(t *testing.T) {
+	// A wide red bar: fitted into the square, it leaves transparent bands above and below
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 200 100"><rect x="0" y="0" width="200" height="100" fill="#ff0000"/></svg>`)
+
+	result, err := RasterizeSVG(svg, 256)
+	if err != nil {
+		t.Fatalf("RasterizeSVG() error = %v", err)
+	}
+	if result.MimeType != "image/png" || result.Hash != hashBytes(result.Data) {
+		t.Errorf("RasterizeSVG() = %s with hash %s, want a hashed PNG", result.MimeType, result.Hash)
+	}
+	img, err := png.Decode(bytes.NewReader(result.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+		t.Fatalf("rasterized to %dx%d, want 256x256", b.Dx(), b.Dy())
+	}
+	if r, _, _, a := img.At(128, 128).RGBA(); a == 0 || r == 0 {
+		t.Error("center pixel is not red, want the rendered rectangle")
+	}
+	if _, _, _, a := img.At(128, 10).RGBA(); a != 0 {
+		t.Errorf("top band alpha = %d, want transparent", a)
+	}
+}
+
+func TestRasterizeSVGErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not SVG", encodePNGTestImage(10, 10)},
+		{"malformed XML", []byte(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><rect`)},
+		{"no size", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="10" height="10"/></svg>`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RasterizeSVG(tt.data, IconRasterSize); err == nil {
+				t.Error("RasterizeSVG() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package metrics records publish counters and stage timings and writes them as a
+// Prometheus textfile-collector snapshot (--metrics-out).
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric names written to the snapshot.
+const (
+	PublishAttempted = "zsp_publish_attempted_total"
+	PublishSucceeded = "zsp_publish_succeeded_total"
+	PublishSkipped   = "zsp_publish_skipped_total"
+	PublishFailed    = "zsp_publish_failed_total"
+	StageDuration    = "zsp_stage_duration_seconds"
+	UploadedBytes    = "zsp_uploaded_bytes_total"
+	RelayFailures    = "zsp_relay_failures_total"
+)
+
+// Stages timed with ObserveDuration.
+const (
+	StageDownload = "download"
+	StageUpload   = "upload"
+	StagePublish  = "publish"
+)
+
+// metricInfo holds the HELP and TYPE lines for a metric family.
+var metricInfo = map[string][2]string{
+	PublishAttempted: {"Publish runs started, by package.", "counter"},
+	PublishSucceeded: {"Publish runs that completed successfully, by package.", "counter"},
+	PublishSkipped:   {"Publish runs skipped because the release was already published, by package.", "counter"},
+	PublishFailed:    {"Publish runs that failed, by package.", "counter"},
+	StageDuration:    {"Time spent in each publish stage.", "summary"},
+	UploadedBytes:    {"Bytes uploaded to Blossom servers.", "counter"},
+	RelayFailures:    {"Events rejected by a relay, by relay.", "counter"},
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	series  = make(map[string]float64) // full series ("name{labels}") -> value
+)
+
+// Enable turns on recording. Until it is called, all recording functions are no-ops.
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+}
+
+// Enabled reports whether metrics are being recorded.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled
+}
+
+// Add adds v to the series identified by name and label key/value pairs.
+func Add(name string, v float64, labels ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return
+	}
+	series[seriesKey(name, labels)] += v
+}
+
+// Inc adds one to the series identified by name and label key/value pairs.
+func Inc(name string, labels ...string) {
+	Add(name, 1, labels...)
+}
+
+// ObserveDuration records one observation of d for stage.
+func ObserveDuration(stage string, d time.Duration) {
+	Add(StageDuration+"_sum", d.Seconds(), "stage", stage)
+	Add(StageDuration+"_count", 1, "stage", stage)
+}
+
+// Since records the time elapsed since start for stage. Intended for use with defer.
+func Since(stage string, start time.Time) {
+	ObserveDuration(stage, time.Since(start))
+}
+
+// WriteFile writes the recorded metrics to path in the Prometheus text format.
+// Series already in the file are added to, so one file accumulates across runs
+// (e.g. a nightly job invoking zsp once per app). The file is replaced atomically.
+func WriteFile(path string) error {
+	mu.Lock()
+	merged := make(map[string]float64, len(series))
+	for k, v := range series {
+		merged[k] = v
+	}
+	mu.Unlock()
+
+	existing, err := readSeries(path)
+	if err != nil {
+		return err
+	}
+	for k, v := range existing {
+		merged[k] += v
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*.prom")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	writeSeries(w, merged)
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// writeSeries writes series grouped by metric family, with HELP and TYPE lines.
+func writeSeries(w *bufio.Writer, values map[string]float64) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lastFamily := ""
+	for _, k := range keys {
+		family := familyOf(seriesName(k))
+		if family != lastFamily {
+			if info, ok := metricInfo[family]; ok {
+				fmt.Fprintf(w, "# HELP %s %s\n", family, info[0])
+				fmt.Fprintf(w, "# TYPE %s %s\n", family, info[1])
+			}
+			lastFamily = family
+		}
+		fmt.Fprintf(w, "%s %s\n", k, strconv.FormatFloat(values[k], 'g', -1, 64))
+	}
+}
+
+// readSeries parses sample lines from an existing metrics file.
+// A missing file yields no series.
+func readSeries(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing metrics: %w", err)
+	}
+
+	out := make(map[string]float64)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.LastIndexByte(line, ' ')
+		if idx <= 0 {
+			continue
+		}
+		v, err := strconv.ParseFloat(line[idx+1:], 64)
+		if err != nil {
+			continue
+		}
+		out[strings.TrimSpace(line[:idx])] += v
+	}
+	return out, nil
+}
+
+// seriesKey formats name and label pairs as a Prometheus series, with labels sorted.
+func seriesKey(name string, labels []string) string {
+	if len(labels) < 2 {
+		return name
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", labels[i], strconv.Quote(labels[i+1])))
+	}
+	sort.Strings(pairs)
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// seriesName returns the metric name of a series key.
+func seriesName(key string) string {
+	if idx := strings.IndexByte(key, '{'); idx >= 0 {
+		return key[:idx]
+	}
+	return key
+}
+
+// familyOf maps summary _sum/_count samples to their metric family.
+func familyOf(name string) string {
+	for _, suffix := range []string{"_sum", "_count"} {
+		if base := strings.TrimSuffix(name, suffix); base != name {
+			if _, ok := metricInfo[base]; ok {
+				return base
+			}
+		}
+	}
+	return name
+}
+
+// reset clears recorded state (for tests).
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	series = make(map[string]float64)
+}
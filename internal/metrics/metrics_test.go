@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordingDisabledByDefault(t *testing.T) {
+	reset()
+	Inc(PublishAttempted, "package", "com.example")
+	if len(series) != 0 {
+		t.Errorf("expected no series while disabled, got %v", series)
+	}
+}
+
+func TestWriteFileAccumulatesAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+
+	for i := 0; i < 2; i++ {
+		reset()
+		Enable()
+		Inc(PublishAttempted, "package", "com.example")
+		Inc(PublishSucceeded, "package", "com.example")
+		ObserveDuration(StageDownload, 1500*time.Millisecond)
+		Add(UploadedBytes, 1024)
+		Inc(RelayFailures, "relay", "wss://relay.example.com")
+		if err := WriteFile(path); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	reset()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"# TYPE zsp_publish_attempted_total counter",
+		`zsp_publish_attempted_total{package="com.example"} 2`,
+		`zsp_publish_succeeded_total{package="com.example"} 2`,
+		"# TYPE zsp_stage_duration_seconds summary",
+		`zsp_stage_duration_seconds_sum{stage="download"} 3`,
+		`zsp_stage_duration_seconds_count{stage="download"} 2`,
+		"zsp_uploaded_bytes_total 2048",
+		`zsp_relay_failures_total{relay="wss://relay.example.com"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics file missing %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "# TYPE zsp_stage_duration_seconds "); n != 1 {
+		t.Errorf("expected one TYPE line for the duration summary, got %d", n)
+	}
+}
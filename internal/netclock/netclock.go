@@ -0,0 +1,129 @@
+// Package netclock estimates the local clock's skew from the Date headers of HTTPS responses.
+package netclock
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zapstore/zsp/internal/netpolicy"
+)
+
+// DefaultMaxSkew is the difference between local and network time above which
+// the local clock is considered wrong.
+const DefaultMaxSkew = 5 * time.Minute
+
+// probeTimeout bounds each relay information request made by Probe.
+const probeTimeout = 5 * time.Second
+
+var (
+	mu      sync.Mutex
+	offsets []time.Duration // network time minus local time, one per observed response
+	hosts   = map[string]bool{}
+	now     = time.Now
+)
+
+// Observe records the offset between resp's Date header and the local clock.
+// Only HTTPS responses count, and each host is sampled once.
+func Observe(resp *http.Response) {
+	if resp == nil || resp.Request == nil || resp.Request.URL.Scheme != "https" {
+		return
+	}
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+	local := now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	host := resp.Request.URL.Hostname()
+	if hosts[host] {
+		return
+	}
+	hosts[host] = true
+	offsets = append(offsets, date.Sub(local))
+}
+
+// Samples returns how many responses have been observed.
+func Samples() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(offsets)
+}
+
+// Skew returns the median offset of network time from the local clock (positive when
+// the local clock is behind) and the number of samples it is based on.
+func Skew() (time.Duration, int) {
+	mu.Lock()
+	sorted := append([]time.Duration(nil), offsets...)
+	mu.Unlock()
+	if len(sorted) == 0 {
+		return 0, 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2], len(sorted)
+}
+
+// Probe requests the NIP-11 relay information document from each relay URL
+// (over HTTPS) to collect Date headers. Failures are ignored.
+func Probe(ctx context.Context, relayURLs []string) {
+	client := &http.Client{
+		Timeout: probeTimeout,
+		Transport: netpolicy.WrapTransport(&http.Transport{
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+		}),
+	}
+	var wg sync.WaitGroup
+	for _, relayURL := range relayURLs {
+		infoURL := relayInfoURL(relayURL)
+		if infoURL == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, infoURL, nil)
+			if err != nil {
+				return
+			}
+			req.Header.Set("Accept", "application/nostr+json")
+			resp, err := client.Do(req)
+			if err != nil {
+				return
+			}
+			resp.Body.Close()
+			Observe(resp)
+		}()
+	}
+	wg.Wait()
+}
+
+// relayInfoURL maps a wss:// relay URL to the https:// URL serving its NIP-11 document.
+// Plaintext ws:// relays are skipped, since their Date header could be spoofed.
+func relayInfoURL(relayURL string) string {
+	u, err := url.Parse(strings.TrimSpace(relayURL))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	switch u.Scheme {
+	case "wss", "https":
+		u.Scheme = "https"
+		return u.String()
+	default:
+		return ""
+	}
+}
+
+// reset clears recorded samples. Used by tests.
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	offsets = nil
+	hosts = map[string]bool{}
+}
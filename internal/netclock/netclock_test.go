@@ -0,0 +1,59 @@
+package netclock
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func response(rawURL string, date time.Time) *http.Response {
+	u, _ := url.Parse(rawURL)
+	resp := &http.Response{Header: http.Header{}, Request: &http.Request{URL: u}}
+	resp.Header.Set("Date", date.UTC().Format(http.TimeFormat))
+	return resp
+}
+
+func TestSkewIsMedianOfHTTPSSamples(t *testing.T) {
+	reset()
+	local := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	now = func() time.Time { return local }
+	defer func() { now = time.Now }()
+
+	Observe(response("https://relay.one.example/", local.Add(-6*time.Hour)))
+	Observe(response("https://relay.one.example/again", local)) // same host, ignored
+	Observe(response("https://api.github.com/repos", local.Add(-6*time.Hour-time.Second)))
+	Observe(response("https://relay.two.example/", local.Add(-5*time.Hour)))
+	Observe(response("http://plain.example/", local)) // not HTTPS, ignored
+
+	skew, n := Skew()
+	if n != 3 {
+		t.Fatalf("samples = %d, want 3", n)
+	}
+	if skew != -6*time.Hour {
+		t.Errorf("skew = %v, want -6h", skew)
+	}
+}
+
+func TestObserveIgnoresMissingDate(t *testing.T) {
+	reset()
+	u, _ := url.Parse("https://relay.example/")
+	Observe(&http.Response{Header: http.Header{}, Request: &http.Request{URL: u}})
+	if _, n := Skew(); n != 0 {
+		t.Errorf("samples = %d, want 0", n)
+	}
+}
+
+func TestRelayInfoURL(t *testing.T) {
+	tests := map[string]string{
+		"wss://relay.zapstore.dev":      "https://relay.zapstore.dev",
+		"wss://relay.example.com/nostr": "https://relay.example.com/nostr",
+		"ws://localhost:3334":           "",
+		"not a url":                     "",
+	}
+	for in, want := range tests {
+		if got := relayInfoURL(in); got != want {
+			t.Errorf("relayInfoURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
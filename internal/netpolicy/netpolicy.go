@@ -0,0 +1,151 @@
+// Package netpolicy enforces the optional outbound network allowlist.
+package netpolicy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/zapstore/zsp/internal/nettrace"
+)
+
+// EnvAllowedHosts is the environment variable holding a comma-separated host allowlist.
+const EnvAllowedHosts = "ZSP_ALLOWED_HOSTS"
+
+var (
+	mu        sync.RWMutex
+	allowlist []string
+)
+
+// BlockedError is returned when a request targets a host outside the allowlist.
+type BlockedError struct {
+	Host   string
+	Reason string // empty for host mismatches, set for e.g. plaintext schemes
+}
+
+func (e *BlockedError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("blocked connection to %s: %s (network allowlist is active)", e.Host, e.Reason)
+	}
+	return fmt.Sprintf("blocked connection to %s: host is not allowed; add it to network_allowlist in the config or %s", e.Host, EnvAllowedHosts)
+}
+
+// SetAllowlist installs the host patterns to enforce. An empty list removes all restrictions.
+func SetAllowlist(patterns []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	allowlist = nil
+	for _, p := range patterns {
+		if p = normalizePattern(p); p != "" {
+			allowlist = append(allowlist, p)
+		}
+	}
+}
+
+// Active reports whether an allowlist is being enforced.
+func Active() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return len(allowlist) > 0
+}
+
+// ParsePatterns splits a comma-separated pattern list (as used by ZSP_ALLOWED_HOSTS).
+func ParsePatterns(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ValidatePattern checks that a host pattern is well formed.
+func ValidatePattern(pattern string) error {
+	p := normalizePattern(pattern)
+	if p == "" {
+		return fmt.Errorf("empty host pattern %q", pattern)
+	}
+	if _, err := path.Match(p, ""); err != nil {
+		return fmt.Errorf("invalid host pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// MatchHost reports whether host matches pattern. "*.example.com" matches any
+// subdomain of example.com; other patterns use glob matching on the whole host.
+func MatchHost(pattern, host string) bool {
+	pattern = normalizePattern(pattern)
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	ok, _ := path.Match(pattern, host)
+	return ok
+}
+
+// CheckURL returns a *BlockedError if rawURL is not allowed under the active allowlist.
+// With an allowlist active, plaintext http:// and ws:// URLs are also refused.
+func CheckURL(rawURL string) error {
+	mu.RLock()
+	patterns := allowlist
+	mu.RUnlock()
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return &BlockedError{Host: rawURL, Reason: "URL has no host"}
+	}
+	host := u.Hostname()
+	switch u.Scheme {
+	case "https", "wss":
+	default:
+		return &BlockedError{Host: host, Reason: fmt.Sprintf("%s:// is not allowed, use TLS", u.Scheme)}
+	}
+	for _, p := range patterns {
+		if MatchHost(p, host) {
+			return nil
+		}
+	}
+	return &BlockedError{Host: host}
+}
+
+// Transport checks every request (including each redirect hop) against the allowlist
+// before handing it to the base transport.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// WrapTransport returns base wrapped with allowlist enforcement and, with
+// --trace, request logging.
+func WrapTransport(base http.RoundTripper) http.RoundTripper {
+	return &Transport{Base: nettrace.Wrap(base)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := CheckURL(req.URL.String()); err != nil {
+		return nil, err
+	}
+	return t.Base.RoundTrip(req)
+}
+
+// normalizePattern lowercases a pattern and reduces a URL or host:port to its host.
+func normalizePattern(p string) string {
+	p = strings.ToLower(strings.TrimSpace(p))
+	if strings.Contains(p, "://") {
+		if u, err := url.Parse(p); err == nil {
+			p = u.Host
+		}
+	}
+	if host, _, err := net.SplitHostPort(p); err == nil {
+		p = host
+	}
+	return strings.TrimSuffix(p, ".")
+}
@@ -0,0 +1,70 @@
+package netpolicy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"github.com", "github.com", true},
+		{"github.com", "GitHub.com", true},
+		{"github.com", "api.github.com", false},
+		{"*.githubusercontent.com", "objects.githubusercontent.com", true},
+		{"*.githubusercontent.com", "githubusercontent.com", false},
+		{"*.githubusercontent.com", "evilgithubusercontent.com", false},
+		{"https://cdn.zapstore.dev", "cdn.zapstore.dev", true},
+		{"relay.zapstore.dev:443", "relay.zapstore.dev", true},
+		{"cdn?.example.com", "cdn1.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.host, func(t *testing.T) {
+			if got := MatchHost(tt.pattern, tt.host); got != tt.want {
+				t.Errorf("MatchHost(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	SetAllowlist([]string{"github.com", "*.githubusercontent.com", "relay.zapstore.dev"})
+	t.Cleanup(func() { SetAllowlist(nil) })
+
+	tests := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://github.com/user/app/releases/download/v1/app.apk", true},
+		{"https://objects.githubusercontent.com/asset", true},
+		{"wss://relay.zapstore.dev", true},
+		{"https://play.google.com/store/apps/details?id=com.example", false},
+		{"https://githuub.com/user/app", false},
+		{"http://github.com/user/app", false},
+		{"ws://relay.zapstore.dev", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := CheckURL(tt.url)
+			if (err == nil) != tt.allowed {
+				t.Fatalf("CheckURL(%q) = %v, allowed %v", tt.url, err, tt.allowed)
+			}
+			var blocked *BlockedError
+			if err != nil && !errors.As(err, &blocked) {
+				t.Errorf("expected *BlockedError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestCheckURLUnrestricted(t *testing.T) {
+	SetAllowlist(nil)
+	if err := CheckURL("http://anything.example.com"); err != nil {
+		t.Errorf("CheckURL() with no allowlist = %v", err)
+	}
+}
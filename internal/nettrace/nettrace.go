@@ -0,0 +1,171 @@
+// Package nettrace logs outbound HTTP requests for --trace: method, URL, status,
+// key headers and timing, one line per request and redirect hop. Credentials in
+// headers and URL query parameters are redacted.
+package nettrace
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+var (
+	mu        sync.Mutex
+	out       io.Writer // nil while tracing is off
+	clearLine bool      // out is a terminal: clear a spinner before each line
+)
+
+// requestHeaders and responseHeaders are logged when present.
+var (
+	requestHeaders  = []string{"Authorization", "Range", "If-None-Match", "If-Modified-Since", "Referer", "X-SHA-256", "Content-Type", "Content-Length"}
+	responseHeaders = []string{"Location", "Content-Type", "Content-Length", "ETag", "Last-Modified", "X-Reason", "Retry-After", "X-RateLimit-Remaining"}
+)
+
+// redactedHeaders hold credentials; only their presence is logged.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Private-Token":       true,
+	"X-Api-Key":           true,
+}
+
+// Enable turns tracing on, writing to w (os.Stderr when nil).
+func Enable(w io.Writer) {
+	if w == nil {
+		w = os.Stderr
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+	clearLine = false
+	if f, ok := w.(*os.File); ok {
+		clearLine = term.IsTerminal(int(f.Fd()))
+	}
+}
+
+// Disable turns tracing off.
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	out = nil
+}
+
+// Enabled reports whether tracing is on.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// Transport logs each request it sends through Base while tracing is on.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+// Wrap returns base wrapped with request tracing. Tracing is checked per request,
+// so clients created before Enable are traced too.
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if _, ok := base.(*Transport); ok {
+		return base
+	}
+	return &Transport{Base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Enabled() {
+		return t.Base.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.Base.RoundTrip(req)
+	log(FormatLine(req, resp, err, time.Since(start)))
+	return resp, err
+}
+
+// FormatLine renders one trace line for a request and its response or error.
+func FormatLine(req *http.Request, resp *http.Response, err error, elapsed time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "trace: %s %s", req.Method, RedactURL(req.URL))
+	writeHeaders(&b, req.Header, requestHeaders)
+	switch {
+	case err != nil:
+		fmt.Fprintf(&b, " -> error: %v", err)
+	default:
+		fmt.Fprintf(&b, " -> %s", resp.Status)
+		writeHeaders(&b, resp.Header, responseHeaders)
+	}
+	fmt.Fprintf(&b, " (%s)", elapsed.Round(time.Millisecond))
+	return b.String()
+}
+
+// writeHeaders appends the named headers that are set, redacting credentials.
+func writeHeaders(b *strings.Builder, h http.Header, names []string) {
+	for _, name := range names {
+		value := h.Get(name)
+		if value == "" {
+			continue
+		}
+		if redactedHeaders[name] {
+			value = "[redacted]"
+		} else if name == "Location" {
+			if u, err := url.Parse(value); err == nil {
+				value = RedactURL(u)
+			}
+		}
+		fmt.Fprintf(b, " %s=%q", strings.ToLower(name), value)
+	}
+}
+
+// RedactURL returns u with user info and the values of credential-like query
+// parameters (tokens, signatures, keys) replaced.
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User("redacted")
+	}
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for key := range query {
+			if isSecretParam(key) {
+				query.Set(key, "redacted")
+			}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+// isSecretParam reports whether a query parameter name looks like a credential.
+func isSecretParam(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"token", "sig", "key", "auth", "secret", "credential", "password"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// log writes a trace line.
+func log(line string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	if clearLine {
+		line = "\r\033[K" + line
+	}
+	fmt.Fprintln(out, line)
+}
@@ -0,0 +1,41 @@
+package nostr
+
+import (
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// KindLabel is the NIP-32 label event kind.
+const KindLabel = 1985
+
+// TestPublisherLabel is the NIP-32 label (in the zapstore namespace) an owner
+// key puts on the throwaway key of an --ephemeral-key run.
+const TestPublisherLabel = "test-publisher"
+
+// testPublisherNamespace is the NIP-32 label namespace of test publisher attestations.
+const testPublisherNamespace = "zapstore"
+
+// BuildTestPublisherAttestation returns an unsigned label event, to be signed
+// by ownerPubkey, marking ephemeralPubkey as its test publisher. Relays and
+// moderators can tell test events from abuse by following the p tag back to
+// the owner.
+func BuildTestPublisherAttestation(ephemeralPubkey, ownerPubkey string) (*nostr.Event, error) {
+	owner, err := nip19.EncodePublicKey(ownerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid owner pubkey: %w", err)
+	}
+	return &nostr.Event{
+		Kind:      KindLabel,
+		PubKey:    ownerPubkey,
+		CreatedAt: nostr.Now(),
+		Tags: nostr.Tags{
+			{"L", testPublisherNamespace},
+			{"l", TestPublisherLabel, testPublisherNamespace},
+			{"p", ephemeralPubkey},
+			ClientTag(),
+		},
+		Content: "test publisher for " + owner,
+	}, nil
+}
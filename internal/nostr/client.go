@@ -0,0 +1,74 @@
+package nostr
+
+import (
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ClientName identifies zsp in the client tag of the events it builds.
+const ClientName = "zsp"
+
+// clientVersion is the zsp version recorded in the client tag.
+var clientVersion = "dev"
+
+// SetClientVersion sets the zsp version recorded in the client tag of every
+// event built afterwards.
+func SetClientVersion(v string) {
+	if v != "" {
+		clientVersion = v
+	}
+}
+
+// ClientTag returns the NIP-89 style client tag naming the zsp version that
+// built an event: ["client", "zsp", "<version>"].
+func ClientTag() nostr.Tag {
+	return nostr.Tag{"client", ClientName, clientVersion}
+}
+
+// CIBuild identifies the CI run that published an event (embed_build_info).
+type CIBuild struct {
+	System string // github-actions, forgejo-actions, gitea-actions, gitlab-ci, woodpecker or ci
+	RunURL string // Web URL of the workflow run or pipeline
+}
+
+// Tag returns the build tag: ["build", "<system>", "<run url>"].
+func (b *CIBuild) Tag() nostr.Tag {
+	return nostr.Tag{"build", b.System, b.RunURL}
+}
+
+// DetectCIBuild identifies the CI run from the standard environment variables
+// of GitHub Actions (and the Forgejo and Gitea runners, which set the same
+// ones), GitLab CI and Woodpecker. Returns nil outside CI.
+func DetectCIBuild(getenv func(string) string) *CIBuild {
+	if server, repo, runID := getenv("GITHUB_SERVER_URL"), getenv("GITHUB_REPOSITORY"), getenv("GITHUB_RUN_ID"); server != "" && repo != "" && runID != "" {
+		system := "github-actions"
+		switch {
+		case getenv("FORGEJO_ACTIONS") == "true":
+			system = "forgejo-actions"
+		case getenv("GITEA_ACTIONS") == "true":
+			system = "gitea-actions"
+		}
+		return &CIBuild{System: system, RunURL: strings.TrimRight(server, "/") + "/" + repo + "/actions/runs/" + runID}
+	}
+	if pipeline := getenv("CI_PIPELINE_URL"); pipeline != "" {
+		system := "ci"
+		switch {
+		case getenv("GITLAB_CI") == "true":
+			system = "gitlab-ci"
+		case getenv("CI_SYSTEM_NAME") == "woodpecker" || getenv("WOODPECKER") != "":
+			system = "woodpecker"
+		}
+		return &CIBuild{System: system, RunURL: pipeline}
+	}
+	return nil
+}
+
+// appendClientTags appends the client tag and, when build is set, the build tag.
+func appendClientTags(tags nostr.Tags, build *CIBuild) nostr.Tags {
+	tags = append(tags, ClientTag())
+	if build != nil {
+		tags = append(tags, build.Tag())
+	}
+	return tags
+}
@@ -0,0 +1,53 @@
+package nostr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectCIBuild(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want *CIBuild
+	}{
+		{
+			name: "github actions",
+			env: map[string]string{
+				"GITHUB_SERVER_URL": "https://github.com", "GITHUB_REPOSITORY": "owner/app", "GITHUB_RUN_ID": "42",
+			},
+			want: &CIBuild{System: "github-actions", RunURL: "https://github.com/owner/app/actions/runs/42"},
+		},
+		{
+			name: "forgejo actions",
+			env: map[string]string{
+				"GITHUB_SERVER_URL": "https://codeberg.org/", "GITHUB_REPOSITORY": "owner/app", "GITHUB_RUN_ID": "7",
+				"FORGEJO_ACTIONS": "true",
+			},
+			want: &CIBuild{System: "forgejo-actions", RunURL: "https://codeberg.org/owner/app/actions/runs/7"},
+		},
+		{
+			name: "gitlab ci",
+			env:  map[string]string{"GITLAB_CI": "true", "CI_PIPELINE_URL": "https://gitlab.com/group/app/-/pipelines/9"},
+			want: &CIBuild{System: "gitlab-ci", RunURL: "https://gitlab.com/group/app/-/pipelines/9"},
+		},
+		{
+			name: "woodpecker",
+			env:  map[string]string{"CI_SYSTEM_NAME": "woodpecker", "CI_PIPELINE_URL": "https://ci.example.com/repos/1/pipeline/3"},
+			want: &CIBuild{System: "woodpecker", RunURL: "https://ci.example.com/repos/1/pipeline/3"},
+		},
+		{
+			name: "incomplete github env",
+			env:  map[string]string{"GITHUB_SERVER_URL": "https://github.com", "GITHUB_REPOSITORY": "owner/app"},
+		},
+		{name: "not in ci", env: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectCIBuild(func(key string) string { return tt.env[key] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectCIBuild() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
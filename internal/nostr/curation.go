@@ -0,0 +1,110 @@
+package nostr
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// KindAppCurationSet is the NIP-51 app curation set kind: a list of Software
+// Application (kind 32267) addresses in a tags.
+const KindAppCurationSet = 30267
+
+// CurationSet is the address of a NIP-51 app curation set.
+type CurationSet struct {
+	Pubkey     string
+	Identifier string   // d tag
+	Relays     []string // relay hints from the naddr
+}
+
+// ParseCurationSet parses the naddr of an app curation set.
+func ParseCurationSet(naddr string) (*CurationSet, error) {
+	prefix, data, err := nip19.Decode(naddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode naddr: %w", err)
+	}
+	if prefix != "naddr" {
+		return nil, fmt.Errorf("expected naddr prefix, got %s", prefix)
+	}
+	ep, ok := data.(nostr.EntityPointer)
+	if !ok {
+		return nil, fmt.Errorf("unexpected naddr data type: %T", data)
+	}
+	if ep.Kind != KindAppCurationSet {
+		return nil, fmt.Errorf("expected kind %d (app curation set), got %d", KindAppCurationSet, ep.Kind)
+	}
+	return &CurationSet{Pubkey: ep.PublicKey, Identifier: ep.Identifier, Relays: ep.Relays}, nil
+}
+
+// FetchCurationSet returns the newest version of the set on the publisher's
+// relays and the set's relay hints, or nil if none has it. Returns an error
+// only if no relay answered.
+func (p *Publisher) FetchCurationSet(ctx context.Context, set *CurationSet) (*nostr.Event, error) {
+	relays := slices.Clone(p.relayURLs)
+	for _, hint := range set.Relays {
+		if !slices.Contains(relays, hint) {
+			relays = append(relays, hint)
+		}
+	}
+	events, err := NewPublisher(relays).queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindAppCurationSet},
+		Authors: []string{set.Pubkey},
+		Tags:    nostr.TagMap{"d": []string{set.Identifier}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var latest *nostr.Event
+	for _, event := range events {
+		if latest == nil || event.CreatedAt > latest.CreatedAt {
+			latest = event
+		}
+	}
+	return latest, nil
+}
+
+// AddToCurationSet returns an unsigned copy of the set event with an a tag
+// for the app address ("32267:<pubkey>:<identifier>") appended, or nil when
+// the set already lists the app. Other tags and the content, which may hold
+// encrypted private items, are kept as they are. created_at moves past the
+// current version so relays replace it.
+func AddToCurationSet(set *nostr.Event, appAddress, relayHint string) *nostr.Event {
+	for _, tag := range set.Tags {
+		if len(tag) >= 2 && tag[0] == "a" && tag[1] == appAddress {
+			return nil
+		}
+	}
+
+	tags := make(nostr.Tags, 0, len(set.Tags)+1)
+	for _, tag := range set.Tags {
+		if len(tag) > 0 && tag[0] == "client" {
+			continue // replaced with this zsp's client tag
+		}
+		tags = append(tags, slices.Clone(tag))
+	}
+	tag := nostr.Tag{"a", appAddress}
+	if relayHint != "" {
+		tag = append(tag, relayHint)
+	}
+	tags = append(tags, tag, ClientTag())
+
+	createdAt := nostr.Now()
+	if createdAt <= set.CreatedAt {
+		createdAt = set.CreatedAt + 1
+	}
+	return &nostr.Event{
+		Kind:      KindAppCurationSet,
+		PubKey:    set.PubKey,
+		CreatedAt: createdAt,
+		Tags:      tags,
+		Content:   set.Content,
+	}
+}
+
+// AppAddress returns the a tag value of a Software Application event.
+func AppAddress(pubkey, identifier string) string {
+	return fmt.Sprintf("%d:%s:%s", KindAppMetadata, pubkey, identifier)
+}
@@ -0,0 +1,106 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/nostr/nostrtest"
+)
+
+func TestParseCurationSet(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+
+	naddr, _ := nip19.EncodeEntity(pk, KindAppCurationSet, "favorites", []string{"wss://relay.example.com"})
+	set, err := ParseCurationSet(naddr)
+	if err != nil {
+		t.Fatalf("ParseCurationSet() error: %v", err)
+	}
+	if set.Pubkey != pk || set.Identifier != "favorites" || len(set.Relays) != 1 {
+		t.Errorf("ParseCurationSet() = %+v", set)
+	}
+
+	naddr, _ = nip19.EncodeEntity(pk, 30000, "favorites", nil)
+	if _, err := ParseCurationSet(naddr); err == nil {
+		t.Error("ParseCurationSet() accepted a follow set")
+	}
+	npub, _ := nip19.EncodePublicKey(pk)
+	if _, err := ParseCurationSet(npub); err == nil {
+		t.Error("ParseCurationSet() accepted an npub")
+	}
+}
+
+func TestAddToCurationSet(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	listed := AppAddress(pk, "com.example.listed")
+	set := nostrtest.SignedEvent(t, sk, KindAppCurationSet, nostr.Tags{
+		{"d", "favorites"},
+		{"title", "Favorites"},
+		{"a", listed},
+		{"client", "other"},
+	})
+	set.Content = "encrypted private items"
+	set.CreatedAt = nostr.Now() + 60
+
+	if updated := AddToCurationSet(set, listed, ""); updated != nil {
+		t.Errorf("AddToCurationSet() = %v, want nil for an app already in the set", updated)
+	}
+
+	app := AppAddress(pk, "com.example.app")
+	updated := AddToCurationSet(set, app, "wss://relay.example.com")
+	if updated == nil {
+		t.Fatal("AddToCurationSet() = nil")
+	}
+	if updated.Content != set.Content || updated.PubKey != pk || updated.Kind != KindAppCurationSet {
+		t.Errorf("AddToCurationSet() did not keep the set's content and address: %+v", updated)
+	}
+	if updated.CreatedAt <= set.CreatedAt {
+		t.Errorf("created_at %d does not replace %d", updated.CreatedAt, set.CreatedAt)
+	}
+	if updated.Tags.FindWithValue("d", "favorites") == nil || updated.Tags.FindWithValue("a", listed) == nil {
+		t.Errorf("existing tags were dropped: %v", updated.Tags)
+	}
+	if tag := updated.Tags.FindWithValue("a", app); len(tag) != 3 || tag[2] != "wss://relay.example.com" {
+		t.Errorf("a tag = %v, want the app address with the relay hint", tag)
+	}
+	if tagValue(updated, "client") != ClientTag()[1] {
+		t.Errorf("client tag = %q, want zsp's", tagValue(updated, "client"))
+	}
+	if len(set.Tags) != 4 {
+		t.Errorf("the original set's tags were modified: %v", set.Tags)
+	}
+}
+
+func TestFetchCurationSet(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pk, _ := nostr.GetPublicKey(sk)
+	older := nostrtest.SignedEvent(t, sk, KindAppCurationSet, nostr.Tags{{"d", "favorites"}})
+	older.CreatedAt -= 100
+	if err := older.Sign(sk); err != nil {
+		t.Fatal(err)
+	}
+	newer := nostrtest.SignedEvent(t, sk, KindAppCurationSet, nostr.Tags{{"d", "favorites"}, {"a", AppAddress(pk, "com.example.app")}})
+	other := nostrtest.SignedEvent(t, sk, KindAppCurationSet, nostr.Tags{{"d", "other"}})
+
+	// The newer version is only on the naddr's relay hint
+	configured := newMockRelay(t, older, other)
+	hinted := newMockRelay(t, newer)
+
+	set := &CurationSet{Pubkey: pk, Identifier: "favorites", Relays: []string{hinted}}
+	event, err := NewPublisher([]string{configured}).FetchCurationSet(context.Background(), set)
+	if err != nil {
+		t.Fatalf("FetchCurationSet() error: %v", err)
+	}
+	if event == nil || event.ID != newer.ID {
+		t.Errorf("FetchCurationSet() = %v, want the newest version", event)
+	}
+
+	set.Identifier = "missing"
+	event, err = NewPublisher([]string{configured}).FetchCurationSet(context.Background(), set)
+	if err != nil || event != nil {
+		t.Errorf("FetchCurationSet() = %v, %v; want nil, nil", event, err)
+	}
+}
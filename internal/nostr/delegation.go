@@ -0,0 +1,148 @@
+package nostr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// Delegation is a NIP-26 delegation token: the delegator's signature allowing
+// another key (the delegatee, zsp's signer) to publish events matching its
+// conditions on the delegator's behalf.
+type Delegation struct {
+	Delegator  string // Hex pubkey of the delegator
+	Conditions string // Query string, e.g. kind=30063&created_at<1767225600
+	Token      string // Hex Schnorr signature of the delegation string
+
+	kinds  []int           // kind= conditions; any of them matches (empty allows all kinds)
+	after  nostr.Timestamp // created_at> condition (0 for none)
+	before nostr.Timestamp // created_at< condition (0 for none)
+}
+
+// ParseDelegation parses a delegation tag given as JSON:
+// ["delegation", "<delegator pubkey or npub>", "<conditions>", "<token>"].
+func ParseDelegation(s string) (*Delegation, error) {
+	var tag []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(s)), &tag); err != nil {
+		return nil, fmt.Errorf(`expected a JSON delegation tag ["delegation", "<pubkey>", "<conditions>", "<token>"]: %w`, err)
+	}
+	if len(tag) != 4 || tag[0] != "delegation" {
+		return nil, fmt.Errorf(`expected a JSON delegation tag ["delegation", "<pubkey>", "<conditions>", "<token>"]`)
+	}
+
+	d := &Delegation{Delegator: tag[1], Conditions: tag[2], Token: strings.ToLower(tag[3])}
+	if strings.HasPrefix(d.Delegator, "npub1") {
+		prefix, data, err := nip19.Decode(d.Delegator)
+		if err != nil || prefix != "npub" {
+			return nil, fmt.Errorf("invalid delegator npub %q", d.Delegator)
+		}
+		d.Delegator = data.(string)
+	}
+	d.Delegator = strings.ToLower(d.Delegator)
+	if !nostr.IsValid32ByteHex(d.Delegator) {
+		return nil, fmt.Errorf("invalid delegator pubkey %q", d.Delegator)
+	}
+	if sig, err := hex.DecodeString(d.Token); err != nil || len(sig) != 64 {
+		return nil, fmt.Errorf("invalid delegation token: must be a 128-character hex signature")
+	}
+	if err := d.parseConditions(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// parseConditions reads the kind= and created_at conditions.
+func (d *Delegation) parseConditions() error {
+	if d.Conditions == "" {
+		return nil
+	}
+	for _, cond := range strings.Split(d.Conditions, "&") {
+		key, op, value := cond, "", ""
+		if i := strings.IndexAny(cond, "=<>"); i > 0 {
+			key, op, value = cond[:i], cond[i:i+1], cond[i+1:]
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid delegation condition %q", cond)
+		}
+		switch key + op {
+		case "kind=":
+			d.kinds = append(d.kinds, int(n))
+		case "created_at>":
+			d.after = nostr.Timestamp(n)
+		case "created_at<":
+			d.before = nostr.Timestamp(n)
+		default:
+			return fmt.Errorf("unsupported delegation condition %q (want kind=, created_at> or created_at<)", cond)
+		}
+	}
+	return nil
+}
+
+// Tag returns the delegation tag added to delegated events.
+func (d *Delegation) Tag() nostr.Tag {
+	return nostr.Tag{"delegation", d.Delegator, d.Conditions, d.Token}
+}
+
+// Check verifies that the token is the delegator's signature delegating to
+// delegatee (a hex pubkey).
+func (d *Delegation) Check(delegatee string) error {
+	pk, err := hex.DecodeString(d.Delegator)
+	if err != nil {
+		return fmt.Errorf("invalid delegator pubkey: %w", err)
+	}
+	pubkey, err := schnorr.ParsePubKey(pk)
+	if err != nil {
+		return fmt.Errorf("invalid delegator pubkey: %w", err)
+	}
+	raw, err := hex.DecodeString(d.Token)
+	if err != nil {
+		return fmt.Errorf("invalid delegation token: %w", err)
+	}
+	sig, err := schnorr.ParseSignature(raw)
+	if err != nil {
+		return fmt.Errorf("invalid delegation token: %w", err)
+	}
+	hash := sha256.Sum256([]byte(delegationString(delegatee, d.Conditions)))
+	if !sig.Verify(hash[:], pubkey) {
+		return fmt.Errorf("delegation token is not signed by %s for the signer %s", NpubOrHex(d.Delegator), NpubOrHex(delegatee))
+	}
+	return nil
+}
+
+// Allows reports, as an error, an event kind or created_at outside the
+// conditions. Several kind= conditions allow any of those kinds, since the
+// events of one release differ in kind.
+func (d *Delegation) Allows(kind int, createdAt nostr.Timestamp) error {
+	if len(d.kinds) > 0 && !slices.Contains(d.kinds, kind) {
+		return fmt.Errorf("kind %d is not delegated (conditions %q)", kind, d.Conditions)
+	}
+	if d.after != 0 && createdAt <= d.after {
+		return fmt.Errorf("kind %d created_at %d is not after %d (conditions %q)", kind, createdAt, d.after, d.Conditions)
+	}
+	if d.before != 0 && createdAt >= d.before {
+		return fmt.Errorf("kind %d created_at %d is not before %d; the delegation has expired (conditions %q)", kind, createdAt, d.before, d.Conditions)
+	}
+	return nil
+}
+
+// delegationString is the message a delegator signs per NIP-26.
+func delegationString(delegatee, conditions string) string {
+	return "nostr:delegation:" + delegatee + ":" + conditions
+}
+
+// NpubOrHex encodes a hex pubkey as an npub, or returns it unchanged if it is not valid.
+func NpubOrHex(pubkey string) string {
+	if npub, err := nip19.EncodePublicKey(pubkey); err == nil {
+		return npub
+	}
+	return pubkey
+}
@@ -0,0 +1,156 @@
+package nostr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/config"
+)
+
+// signDelegation returns the JSON delegation tag in which the key sk delegates
+// to delegatee under conditions.
+func signDelegation(t *testing.T, sk, delegatee, conditions string) string {
+	t.Helper()
+	raw, err := hex.DecodeString(sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, _ := btcec.PrivKeyFromBytes(raw)
+	hash := sha256.Sum256([]byte(delegationString(delegatee, conditions)))
+	sig, err := schnorr.Sign(priv, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, _ := nostr.GetPublicKey(sk)
+	tag, _ := json.Marshal([]string{"delegation", pub, conditions, hex.EncodeToString(sig.Serialize())})
+	return string(tag)
+}
+
+func TestParseDelegation(t *testing.T) {
+	delegatorSK := nostr.GeneratePrivateKey()
+	delegator, _ := nostr.GetPublicKey(delegatorSK)
+	delegatee, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	token := signDelegation(t, delegatorSK, delegatee, "kind=30063&created_at>1700000000&created_at<1800000000")
+
+	d, err := ParseDelegation(token)
+	if err != nil {
+		t.Fatalf("ParseDelegation() error: %v", err)
+	}
+	if d.Delegator != delegator {
+		t.Errorf("Delegator = %s, want %s", d.Delegator, delegator)
+	}
+	if err := d.Check(delegatee); err != nil {
+		t.Errorf("Check(delegatee) error: %v", err)
+	}
+	if err := d.Check(delegator); err == nil {
+		t.Error("Check() accepted a delegation made for another key")
+	}
+
+	// The delegator may be given as an npub
+	npub, _ := nip19.EncodePublicKey(delegator)
+	if d, err := ParseDelegation(strings.Replace(token, delegator, npub, 1)); err != nil || d.Delegator != delegator {
+		t.Errorf("ParseDelegation(npub) = %v, %v", d, err)
+	}
+
+	for _, bad := range []string{
+		`delegation`,
+		`["delegation", "` + delegator + `", "kind=1"]`,
+		`["p", "` + delegator + `", "kind=1", "` + strings.Repeat("ab", 64) + `"]`,
+		`["delegation", "abc", "kind=1", "` + strings.Repeat("ab", 64) + `"]`,
+		`["delegation", "` + delegator + `", "kind=1", "abc"]`,
+		`["delegation", "` + delegator + `", "kind=x", "` + strings.Repeat("ab", 64) + `"]`,
+		`["delegation", "` + delegator + `", "tag=t", "` + strings.Repeat("ab", 64) + `"]`,
+	} {
+		if _, err := ParseDelegation(bad); err == nil {
+			t.Errorf("ParseDelegation(%s) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestDelegationAllows(t *testing.T) {
+	d := &Delegation{Conditions: "kind=30063&kind=3063&created_at>100&created_at<200"}
+	if err := d.parseConditions(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		kind      int
+		createdAt nostr.Timestamp
+		ok        bool
+	}{
+		{30063, 150, true},
+		{3063, 150, true},
+		{32267, 150, false},
+		{30063, 100, false},
+		{30063, 200, false},
+	}
+	for _, tt := range tests {
+		if err := d.Allows(tt.kind, tt.createdAt); (err == nil) != tt.ok {
+			t.Errorf("Allows(%d, %d) = %v, want ok=%v", tt.kind, tt.createdAt, err, tt.ok)
+		}
+	}
+
+	if err := (&Delegation{}).Allows(1, 1); err != nil {
+		t.Errorf("Allows() without conditions = %v, want nil", err)
+	}
+}
+
+func TestBuildEventSetDelegation(t *testing.T) {
+	delegatorSK := nostr.GeneratePrivateKey()
+	delegator, _ := nostr.GetPublicKey(delegatorSK)
+	delegatee, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	build := func(conditions, signer string) (*EventSet, error) {
+		d, err := ParseDelegation(signDelegation(t, delegatorSK, delegatee, conditions))
+		if err != nil {
+			t.Fatalf("ParseDelegation() error: %v", err)
+		}
+		return BuildEventSet(BuildEventSetParams{
+			APKInfo:    &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"},
+			Config:     &config.Config{},
+			Pubkey:     signer,
+			Delegation: d,
+		})
+	}
+
+	events, err := build("kind=32267&kind=30063&kind=3063&created_at<4102444800", delegatee)
+	if err != nil {
+		t.Fatalf("BuildEventSet() error: %v", err)
+	}
+	for _, event := range []*nostr.Event{events.AppMetadata, events.Release, events.SoftwareAssets[0]} {
+		tags := filterExactTag(event.Tags, "delegation")
+		if len(tags) != 1 || !reflect.DeepEqual(tags[0][:3], nostr.Tag{"delegation", delegator, "kind=32267&kind=30063&kind=3063&created_at<4102444800"}) {
+			t.Errorf("kind %d delegation tags = %v, want one", event.Kind, tags)
+		}
+	}
+
+	var vErr *ValidationError
+	for name, tc := range map[string]struct{ conditions, signer string }{
+		"expired":        {"created_at<1700000000", delegatee},
+		"kind not given": {"kind=30063&kind=3063", delegatee},
+		"other signer":   {"", "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"},
+	} {
+		if _, err := build(tc.conditions, tc.signer); !errors.As(err, &vErr) {
+			t.Errorf("%s: BuildEventSet() error = %v, want a ValidationError", name, err)
+		}
+	}
+}
+
+func TestNpubOrHex(t *testing.T) {
+	pubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	if got := NpubOrHex(pubkey); !strings.HasPrefix(got, "npub1") {
+		t.Errorf("NpubOrHex(%s) = %q, want an npub", pubkey, got)
+	}
+	if got := NpubOrHex("not-a-key"); got != "not-a-key" {
+		t.Errorf("NpubOrHex(not-a-key) = %q, want it unchanged", got)
+	}
+}
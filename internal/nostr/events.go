@@ -2,8 +2,11 @@
 package nostr
 
 import (
+	"fmt"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -22,24 +25,38 @@ const (
 	KindSoftwareAsset = 3063  // Software Asset (hash, size, URLs, cert hash, platforms)
 	KindBlossomAuth   = 24242 // Blossom upload authorization
 	KindIdentityProof = 30509 // NIP-C1 Cryptographic Identity Proof (SPKI)
+	KindLongForm      = 30023 // NIP-23 long-form content (release notes with release_notes_event)
+	KindDeletion      = 5     // NIP-09 deletion request (retracting a partial publish)
 )
 
 // AppMetadata contains Software Application metadata (kind 32267).
 type AppMetadata struct {
-	PackageID   string
-	Name        string
-	Description string
-	Summary     string
-	Website     string
-	License     string
-	Repository  string   // Repository URL (for display)
-	NIP34Repo   string   // NIP-34 repository pointer (a tag): "30617:pubkey:identifier"
-	NIP34Relay  string   // Relay hint for NIP-34 pointer
-	Tags        []string // Category tags
-	IconURL     string   // Blossom URL for icon
-	ImageURLs   []string // Screenshot URLs
-	Platforms   []string // Platform identifiers (e.g., "android-arm64-v8a")
-	Communities []string // h tag values; defaults to [DefaultCommunity] if empty
+	PackageID    string
+	Name         string
+	Description  string
+	Summary      string
+	Website      string
+	License      string
+	Repository   string   // Repository URL (for display)
+	NIP34Repo    string   // NIP-34 repository pointer (a tag): "30617:pubkey:identifier"
+	NIP34Relay   string   // Relay hint for NIP-34 pointer
+	Tags         []string // Category tags
+	IconURL      string   // Blossom URL for icon
+	IconBlurhash string   // Blurhash placeholder for the icon (imeta tag)
+	ImageURLs    []string // Screenshot URLs
+	Platforms    []string // Platform identifiers (e.g., "android-arm64-v8a")
+	Communities  []string // h tag values; defaults to [DefaultCommunity] if empty
+	Languages    []string // ISO 639-1 codes of translated languages (NIP-32 l tags)
+	Provenance   []MetadataProvenance
+	Build        *CIBuild // CI run that published the event (build tag, nil omits it)
+}
+
+// MetadataProvenance records one external source the listing metadata came from.
+// Each becomes a ["provenance", source, url, sha256] tag on the kind 32267 event.
+type MetadataProvenance struct {
+	Source string // e.g. "fastlane", "fdroid"
+	URL    string // What was fetched (API endpoint or page)
+	SHA256 string // Hash of the fetched description and screenshot set
 }
 
 // ReleaseMetadata contains Software Release metadata (kind 30063).
@@ -47,12 +64,26 @@ type ReleaseMetadata struct {
 	PackageID      string
 	Version        string
 	VersionCode    int64
-	Changelog      string   // Release notes (content field)
-	Channel        string   // Release channel: main, beta, nightly, dev
-	AssetEventIDs  []string // Event IDs of asset events (kind 3063)
-	AssetRelayHint string   // Optional relay hint for asset events
-	Commit         string   // Git commit hash
-	Platforms      []string // Platform identifiers (e.g., "android-arm64-v8a")
+	Changelog      string    // Release notes (content field)
+	Channel        string    // Release channel: main, beta, nightly, dev
+	AssetEventIDs  []string  // Event IDs of asset events (kind 3063)
+	AssetRelayHint string    // Optional relay hint for asset events
+	Commit         string    // Git commit hash
+	TagName        string    // Upstream git tag of the release (tag_name tag, empty omits it)
+	Platforms      []string  // Platform identifiers (e.g., "android-arm64-v8a")
+	PublishedAt    time.Time // Human-facing publish date (published_at tag, zero omits it)
+	NotesAddress   string    // Address of the long-form release notes (a tag, empty omits it)
+	Build          *CIBuild  // CI run that published the event (build tag, nil omits it)
+}
+
+// ReleaseNotesMetadata contains long-form release notes (kind 30023).
+type ReleaseNotesMetadata struct {
+	Identifier  string    // d tag, the same as the release's
+	Title       string    // e.g. "My App 1.2.0"
+	Summary     string    // Excerpt kept in the release content
+	Content     string    // Full Markdown release notes
+	PublishedAt time.Time // published_at tag (zero omits it)
+	Build       *CIBuild  // CI run that published the event (build tag, nil omits it)
 }
 
 // AssetMetadata contains Software Asset metadata (kind 3063).
@@ -66,13 +97,26 @@ type AssetMetadata struct {
 	CertFingerprint       string   // APK signing certificate SHA256
 	MinSDK                int32
 	TargetSDK             int32
+	MaxSDK                int32    // maxSdkVersion (0 omits max_platform_version)
 	Platforms             []string // Full platform identifiers (e.g., "android-arm64-v8a")
+	DeviceClasses         []string // Device classes the APK is restricted to (tv, tablet, wear)
 	Filename              string   // Original filename (for variant detection)
 	Variant               string   // Explicit variant name (e.g., "fdroid", "google")
 	Commit                string   // Git commit hash for reproducible builds
 	SupportedNIPs         []string // Supported Nostr NIPs
 	MinAllowedVersion     string   // Minimum allowed version string
 	MinAllowedVersionCode int64    // Minimum allowed version code
+	DetachedSignature     *DetachedSignature
+	Build                 *CIBuild // CI run that published the event (build tag, nil omits it)
+}
+
+// DetachedSignature points to a conventional detached signature of the APK
+// (detached_signature). It becomes a ["signature", type, url, sha256] tag on the
+// kind 3063 event.
+type DetachedSignature struct {
+	Type   string // "minisign" or "gpg"
+	URL    string // Blossom URL of the signature file
+	SHA256 string // Hash of the signature file
 }
 
 // EventSet contains all events to be published for an app release.
@@ -81,6 +125,7 @@ type EventSet struct {
 	Release        *nostr.Event
 	SoftwareAssets []*nostr.Event // Multiple assets (e.g., different APK variants)
 	IdentityProof  *nostr.Event  // Optional NIP-C1 identity proof (kind 30509)
+	ReleaseNotes   *nostr.Event  // Optional long-form release notes (kind 30023, release_notes_event)
 }
 
 // BuildAppMetadataEvent creates a Software Application event (kind 32267).
@@ -95,6 +140,10 @@ func BuildAppMetadataEvent(meta *AppMetadata, pubkey string) *nostr.Event {
 	}
 	if meta.IconURL != "" {
 		tags = append(tags, nostr.Tag{"icon", meta.IconURL})
+		// NIP-92 media metadata, so clients can show a placeholder while the icon loads
+		if meta.IconBlurhash != "" {
+			tags = append(tags, nostr.Tag{"imeta", "url " + meta.IconURL, "blurhash " + meta.IconBlurhash})
+		}
 	}
 	for _, url := range meta.ImageURLs {
 		tags = append(tags, nostr.Tag{"image", url})
@@ -123,6 +172,13 @@ func BuildAppMetadataEvent(meta *AppMetadata, pubkey string) *nostr.Event {
 	if meta.License != "" {
 		tags = append(tags, nostr.Tag{"license", meta.License})
 	}
+	// NIP-32 language labels
+	if len(meta.Languages) > 0 {
+		tags = append(tags, nostr.Tag{"L", languageLabelNamespace})
+		for _, lang := range meta.Languages {
+			tags = append(tags, nostr.Tag{"l", lang, languageLabelNamespace})
+		}
+	}
 
 	// h tags: community identifiers
 	communities := meta.Communities
@@ -132,6 +188,10 @@ func BuildAppMetadataEvent(meta *AppMetadata, pubkey string) *nostr.Event {
 	for _, c := range communities {
 		tags = append(tags, nostr.Tag{"h", c})
 	}
+	for _, p := range meta.Provenance {
+		tags = append(tags, nostr.Tag{"provenance", p.Source, p.URL, p.SHA256})
+	}
+	tags = appendClientTags(tags, meta.Build)
 
 	return &nostr.Event{
 		Kind:      KindAppMetadata,
@@ -159,11 +219,26 @@ func BuildReleaseEvent(meta *ReleaseMetadata, pubkey string) *nostr.Event {
 		nostr.Tag{"c", channel},
 	)
 
+	// Exact upstream tag, which may differ from the normalized version (e.g. v1.2.3-android)
+	if meta.TagName != "" {
+		tags = append(tags, nostr.Tag{"tag_name", meta.TagName})
+	}
+
+	// Publish date shown to users, independent of created_at ordering
+	if !meta.PublishedAt.IsZero() {
+		tags = append(tags, nostr.Tag{"published_at", strconv.FormatInt(meta.PublishedAt.Unix(), 10)})
+	}
+
 	// Platform identifiers (f tags) - same as kind 32267
 	for _, platform := range meta.Platforms {
 		tags = append(tags, nostr.Tag{"f", platform})
 	}
 
+	// Full release notes published separately (release_notes_event)
+	if meta.NotesAddress != "" {
+		tags = append(tags, nostr.Tag{"a", meta.NotesAddress})
+	}
+
 	// Asset event references (e tags)
 	for _, eventID := range meta.AssetEventIDs {
 		if meta.AssetRelayHint != "" {
@@ -172,6 +247,7 @@ func BuildReleaseEvent(meta *ReleaseMetadata, pubkey string) *nostr.Event {
 			tags = append(tags, nostr.Tag{"e", eventID})
 		}
 	}
+	tags = appendClientTags(tags, meta.Build)
 
 	return &nostr.Event{
 		Kind:      KindRelease,
@@ -182,6 +258,54 @@ func BuildReleaseEvent(meta *ReleaseMetadata, pubkey string) *nostr.Event {
 	}
 }
 
+// BuildReleaseNotesEvent creates a long-form content event (kind 30023) holding
+// the full release notes, which the release references with an a tag.
+func BuildReleaseNotesEvent(meta *ReleaseNotesMetadata, pubkey string) *nostr.Event {
+	tags := nostr.Tags{
+		nostr.Tag{"d", meta.Identifier},
+		nostr.Tag{"title", meta.Title},
+	}
+	// Tags are single-line, while an excerpt may span several
+	if summary := strings.Join(strings.Fields(meta.Summary), " "); summary != "" {
+		tags = append(tags, nostr.Tag{"summary", summary})
+	}
+	if !meta.PublishedAt.IsZero() {
+		tags = append(tags, nostr.Tag{"published_at", strconv.FormatInt(meta.PublishedAt.Unix(), 10)})
+	}
+	tags = appendClientTags(tags, meta.Build)
+
+	return &nostr.Event{
+		Kind:      KindLongForm,
+		PubKey:    pubkey,
+		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		Tags:      tags,
+		Content:   meta.Content,
+	}
+}
+
+// ReleaseNotesExcerpt returns the short form of release notes that stays in
+// the release content with release_notes_event: configured when set, otherwise
+// the first paragraph of notes, skipping headings, rules and images.
+func ReleaseNotesExcerpt(notes, configured string) string {
+	if excerpt := strings.TrimSpace(configured); excerpt != "" {
+		return excerpt
+	}
+	var paragraph []string
+	for _, line := range strings.Split(strings.ReplaceAll(notes, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		skip := strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "![") ||
+			strings.HasPrefix(trimmed, "[![") || strings.Trim(trimmed, "-*_ ") == ""
+		if skip {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, strings.TrimRight(line, " \t"))
+	}
+	return strings.Join(paragraph, "\n")
+}
+
 // BuildSoftwareAssetEvent creates a Software Asset event (kind 3063).
 func BuildSoftwareAssetEvent(meta *AssetMetadata, pubkey string) *nostr.Event {
 	tags := nostr.Tags{}
@@ -217,6 +341,14 @@ func BuildSoftwareAssetEvent(meta *AssetMetadata, pubkey string) *nostr.Event {
 	if meta.TargetSDK > 0 {
 		tags = append(tags, nostr.Tag{"target_platform_version", strconv.Itoa(int(meta.TargetSDK))})
 	}
+	if meta.MaxSDK > 0 {
+		tags = append(tags, nostr.Tag{"max_platform_version", strconv.Itoa(int(meta.MaxSDK))})
+	}
+
+	// Device classes, so clients can hide TV-only or tablet-only builds on phones
+	for _, class := range meta.DeviceClasses {
+		tags = append(tags, nostr.Tag{"device", class})
+	}
 
 	// Filename for variant detection (fallback when no explicit variant)
 	if meta.Filename != "" {
@@ -254,6 +386,12 @@ func BuildSoftwareAssetEvent(meta *AssetMetadata, pubkey string) *nostr.Event {
 		tags = append(tags, nostr.Tag{"apk_certificate_hash", meta.CertFingerprint})
 	}
 
+	// Detached signature for verification outside Nostr
+	if sig := meta.DetachedSignature; sig != nil {
+		tags = append(tags, nostr.Tag{"signature", sig.Type, sig.URL, sig.SHA256})
+	}
+	tags = appendClientTags(tags, meta.Build)
+
 	return &nostr.Event{
 		Kind:      KindSoftwareAsset,
 		PubKey:    pubkey,
@@ -280,6 +418,68 @@ func BuildBlossomAuthEvent(fileHash string, pubkey string, expiration time.Time)
 	}
 }
 
+// BuildBlossomListAuthEvent creates a kind 24242 event authorizing a BUD-02 blob list.
+func BuildBlossomListAuthEvent(pubkey string, expiration time.Time) *nostr.Event {
+	return &nostr.Event{
+		Kind:      KindBlossomAuth,
+		PubKey:    pubkey,
+		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		Tags: nostr.Tags{
+			{"t", "list"},
+			{"expiration", strconv.FormatInt(expiration.Unix(), 10)},
+		},
+		Content: "List blobs",
+	}
+}
+
+// BuildBlossomDeleteAuthEvent creates a kind 24242 event authorizing deletion of
+// the given blobs. One event carries an x tag per hash, so a batch needs one signature.
+func BuildBlossomDeleteAuthEvent(fileHashes []string, pubkey string, expiration time.Time) *nostr.Event {
+	tags := nostr.Tags{{"t", "delete"}}
+	for _, hash := range fileHashes {
+		tags = append(tags, nostr.Tag{"x", hash})
+	}
+	tags = append(tags, nostr.Tag{"expiration", strconv.FormatInt(expiration.Unix(), 10)})
+
+	content := "Delete " + strconv.Itoa(len(fileHashes)) + " blobs"
+	if len(fileHashes) == 1 {
+		content = "Delete " + fileHashes[0]
+	}
+	return &nostr.Event{
+		Kind:      KindBlossomAuth,
+		PubKey:    pubkey,
+		CreatedAt: nostr.Timestamp(time.Now().Unix()),
+		Tags:      tags,
+		Content:   content,
+	}
+}
+
+// BuildDeletionEvent creates a NIP-09 deletion request (kind 5) for events, with
+// an e tag per event and a k tag per kind. It is dated no earlier than the
+// newest of them, so relays do not take it for a request older than the events.
+func BuildDeletionEvent(events []*nostr.Event, pubkey, reason string) *nostr.Event {
+	createdAt := nostr.Now()
+	var tags nostr.Tags
+	var kinds []int
+	for _, event := range events {
+		tags = append(tags, nostr.Tag{"e", event.ID})
+		if !slices.Contains(kinds, event.Kind) {
+			kinds = append(kinds, event.Kind)
+		}
+		createdAt = max(createdAt, event.CreatedAt)
+	}
+	for _, kind := range kinds {
+		tags = append(tags, nostr.Tag{"k", strconv.Itoa(kind)})
+	}
+	return &nostr.Event{
+		Kind:      KindDeletion,
+		PubKey:    pubkey,
+		CreatedAt: createdAt,
+		Tags:      appendClientTags(tags, nil),
+		Content:   reason,
+	}
+}
+
 // archToPlatform converts Android architecture names to NIP-82 platform identifiers.
 func archToPlatform(arch string) string {
 	switch arch {
@@ -303,26 +503,116 @@ type BuildEventSetParams struct {
 	Pubkey           string
 	OriginalURL      string // Original download URL (from release source)
 	BlossomServer    string // Blossom server URL (fallback when OriginalURL is empty)
+	// BlossomPublicURL is where clients download blobs uploaded to BlossomServer
+	// (blossom_public_url): a base URL, or a template with {hash}. URLs on
+	// BlossomServer in the events are rewritten to it; empty keeps them.
+	BlossomPublicURL string
 	IconURL          string
+	IconBlurhash     string // Blurhash of the icon, added as an imeta tag (empty omits it)
 	ImageURLs        []string
 	Changelog        string    // Release notes (from remote source or local file)
 	Variant          string    // Explicit variant name (from config variants map)
 	Commit           string    // Git commit hash for reproducible builds
+	TagName          string    // Upstream git tag of the release (empty for sources without tags)
 	Channel          string    // Release channel: main (default), beta, nightly, dev
+	ChannelSuffix    bool      // Suffix the app identifier with the channel (see AppIdentifier)
 	ReleaseTimestamp time.Time // Release publish date (zero means use current time)
+	PublishedAt      time.Time // Value for the release published_at tag (zero omits the tag)
 	// UseReleaseTimestampForApp sets kind 32267 created_at to ReleaseTimestamp.
 	// When false, app metadata keeps current-time created_at.
 	UseReleaseTimestampForApp bool
+	// ExistingApp is the publisher's current kind 32267 event. When set, fields the
+	// new build leaves empty are preserved from it (--overwrite-app=merge).
+	ExistingApp *nostr.Event
+	// ReplaceApp builds the app event from this build alone, ignoring ExistingApp
+	// (--overwrite-app=replace).
+	ReplaceApp bool
 	// MinReleaseTimestamp ensures Release.CreatedAt is strictly greater than this value.
 	// Used with --overwrite-release to guarantee NIP-33 replacement when the relay
 	// has an existing event with the same or newer timestamp.
 	MinReleaseTimestamp time.Time
+	// Platforms, when set, replaces the platform identifiers (f tags) derived from
+	// the APK's architectures (--platform).
+	Platforms []string
+	// Now is the current time used for created_at. Zero means the local clock;
+	// set it to network time when the local clock is known to be skewed.
+	Now time.Time
+	// Provenance adds a provenance tag per metadata source (metadata_provenance).
+	Provenance []MetadataProvenance
+	// DetachedSignature adds a signature tag to the asset event (detached_signature).
+	DetachedSignature *DetachedSignature
+	// KeepAssets are published Software Assets the release keeps referencing
+	// alongside the new one (--only-new-assets). Their platforms are added to
+	// the release and app events.
+	KeepAssets []*nostr.Event
+	// CIBuild adds a build tag naming the CI run to every event (embed_build_info).
+	CIBuild *CIBuild
+	// Delegation adds a NIP-26 delegation tag to every event, so they count as
+	// published by the delegator (--delegation). It must delegate to Pubkey and
+	// its conditions must allow each event's kind and created_at.
+	Delegation *Delegation
+}
+
+// PublicBlossomURL rewrites a blob URL on the Blossom server uploads go to
+// (server/<hash>) to the public form given by public: a base URL the hash is
+// appended to, or a template whose {hash} is replaced. Other URLs, and all URLs
+// when public is empty, are returned unchanged.
+func PublicBlossomURL(blobURL, server, public string) string {
+	if public == "" || server == "" {
+		return blobURL
+	}
+	hash, ok := strings.CutPrefix(blobURL, strings.TrimRight(server, "/")+"/")
+	if !ok || hash == "" || strings.Contains(hash, "/") {
+		return blobURL
+	}
+	if strings.Contains(public, "{hash}") {
+		return strings.ReplaceAll(public, "{hash}", hash)
+	}
+	return strings.TrimRight(public, "/") + "/" + hash
 }
 
+// languageLabelNamespace is the NIP-32 label namespace of app language tags.
+const languageLabelNamespace = "ISO-639-1"
+
+// localeLanguages reduces APK locales ("pt-BR", "de") to their ISO 639-1
+// language codes, sorted and without duplicates. Three-letter languages have
+// no ISO 639-1 code and are left out.
+func localeLanguages(locales []string) []string {
+	var languages []string
+	for _, locale := range locales {
+		lang, _, _ := strings.Cut(locale, "-")
+		if len(lang) == 2 {
+			languages = append(languages, strings.ToLower(lang))
+		}
+	}
+	slices.Sort(languages)
+	return slices.Compact(languages)
+}
+
+// AppIdentifier returns the identifier of the app entry for packageID on channel:
+// the d tag of the app event and the i tag of its releases. With suffix set,
+// channels other than main get "~channel" appended, so a beta published to the
+// same relay is a separate entry instead of replacing the main one. Asset events
+// keep the bare package ID, which is what Android installs.
+func AppIdentifier(packageID, channel string, suffix bool) string {
+	if !suffix || channel == "" || channel == "main" {
+		return packageID
+	}
+	return packageID + "~" + channel
+}
+
+// maxReleaseBumpAhead limits how far into the future the MinReleaseTimestamp bump
+// may push Release.CreatedAt, since relays reject events too far in the future.
+const maxReleaseBumpAhead = 5 * time.Second
+
 // BuildEventSet creates all events for an APK release.
 // The Release event's asset references (e tags) are populated by SignEventSet
-// after the asset event is signed.
-func BuildEventSet(params BuildEventSetParams) *EventSet {
+// after the asset event is signed. Inputs that would produce events relays reject
+// return a *ValidationError (see BuildEventSetParams.Validate).
+func BuildEventSet(params BuildEventSetParams) (*EventSet, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
 	apkInfo := params.APKInfo
 	cfg := params.Config
 
@@ -343,7 +633,18 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 	// Always include Blossom URL as fallback (or primary if no original URL)
 	if params.BlossomServer != "" && apkInfo.SHA256 != "" {
 		blossomURL := params.BlossomServer + "/" + apkInfo.SHA256
-		apkURLs = append(apkURLs, blossomURL)
+		apkURLs = append(apkURLs, PublicBlossomURL(blossomURL, params.BlossomServer, params.BlossomPublicURL))
+	}
+	iconURL := PublicBlossomURL(params.IconURL, params.BlossomServer, params.BlossomPublicURL)
+	var imageURLs []string
+	for _, u := range params.ImageURLs {
+		imageURLs = append(imageURLs, PublicBlossomURL(u, params.BlossomServer, params.BlossomPublicURL))
+	}
+	detachedSig := params.DetachedSignature
+	if detachedSig != nil {
+		sig := *detachedSig
+		sig.URL = PublicBlossomURL(sig.URL, params.BlossomServer, params.BlossomPublicURL)
+		detachedSig = &sig
 	}
 
 	// Convert architectures to platform identifiers
@@ -355,6 +656,21 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 	if len(platforms) == 0 {
 		platforms = []string{"android-arm64-v8a", "android-armeabi-v7a", "android-x86", "android-x86_64"}
 	}
+	if len(params.Platforms) > 0 {
+		platforms = append([]string(nil), params.Platforms...)
+	}
+
+	// The release and app cover the platforms of every asset the release references
+	releasePlatforms := slices.Clone(platforms)
+	keptAssetIDs := []string{}
+	for _, asset := range params.KeepAssets {
+		keptAssetIDs = append(keptAssetIDs, asset.ID)
+		for _, tag := range asset.Tags {
+			if len(tag) >= 2 && tag[0] == "f" && !slices.Contains(releasePlatforms, tag[1]) {
+				releasePlatforms = append(releasePlatforms, tag[1])
+			}
+		}
+	}
 
 	// Build NIP-34 repository pointer if available
 	var nip34Repo, nip34Relay string
@@ -366,9 +682,16 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 		}
 	}
 
+	// Determine release channel (default: main)
+	channel := params.Channel
+	if channel == "" {
+		channel = "main"
+	}
+	identifier := AppIdentifier(apkInfo.PackageID, channel, params.ChannelSuffix)
+
 	// Software Application event
 	appMeta := &AppMetadata{
-		PackageID:      apkInfo.PackageID,
+		PackageID:      identifier,
 		Name:           name,
 		Description:    cfg.Description,
 		Summary:        cfg.Summary,
@@ -378,29 +701,49 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 		NIP34Repo:      nip34Repo,
 		NIP34Relay:     nip34Relay,
 		Tags:           cfg.Tags,
-		IconURL:        params.IconURL,
-		ImageURLs:      params.ImageURLs,
-		Platforms:      platforms,
+		IconURL:        iconURL,
+		IconBlurhash:   params.IconBlurhash,
+		ImageURLs:      imageURLs,
+		Platforms:      releasePlatforms,
 		Communities: cfg.Communities,
+		Languages:   localeLanguages(apkInfo.Locales),
+		Provenance:  params.Provenance,
+		Build:       params.CIBuild,
 	}
 
-	// Determine release channel (default: main)
-	channel := params.Channel
-	if channel == "" {
-		channel = "main"
+	// Long-form release notes (release_notes_event): the full notes become a
+	// kind 30023 article and the release keeps an excerpt and an a tag to it
+	changelog := params.Changelog
+	var notesMeta *ReleaseNotesMetadata
+	if cfg.ReleaseNotesEvent && strings.TrimSpace(changelog) != "" {
+		notesMeta = &ReleaseNotesMetadata{
+			Identifier:  identifier + "@" + apkInfo.VersionName,
+			Title:       name + " " + apkInfo.VersionName,
+			Summary:     ReleaseNotesExcerpt(changelog, cfg.ReleaseNotesExcerpt),
+			Content:     changelog,
+			PublishedAt: params.PublishedAt,
+			Build:       params.CIBuild,
+		}
+		changelog = notesMeta.Summary
 	}
 
 	// Software Release event
-	// AssetEventIDs will be populated by SignEventSet after asset is signed
+	// The new asset's ID is added by SignEventSet after the asset is signed
 	releaseMeta := &ReleaseMetadata{
-		PackageID:     apkInfo.PackageID,
+		PackageID:     identifier,
 		Version:       apkInfo.VersionName,
 		VersionCode:   apkInfo.VersionCode,
-		Changelog:     params.Changelog,
+		Changelog:     changelog,
 		Channel:       channel,
-		AssetEventIDs: []string{}, // Populated after signing
+		AssetEventIDs: keptAssetIDs,
 		Commit:        params.Commit,
-		Platforms:     platforms,
+		TagName:       params.TagName,
+		Platforms:     releasePlatforms,
+		PublishedAt:   params.PublishedAt,
+		Build:         params.CIBuild,
+	}
+	if notesMeta != nil {
+		releaseMeta.NotesAddress = fmt.Sprintf("%d:%s:%s", KindLongForm, params.Pubkey, notesMeta.Identifier)
 	}
 
 	// Software Asset event
@@ -414,13 +757,17 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 		CertFingerprint:       apkInfo.CertFingerprint,
 		MinSDK:                apkInfo.MinSDK,
 		TargetSDK:             apkInfo.TargetSDK,
+		MaxSDK:                apkInfo.MaxSDK,
 		Platforms:             platforms,
+		DeviceClasses:         apkInfo.DeviceClasses(),
 		Filename:              filepath.Base(apkInfo.FilePath),
 		Variant:               params.Variant,
 		Commit:                params.Commit,
 		SupportedNIPs:         cfg.SupportedNIPs,
 		MinAllowedVersion:     cfg.MinAllowedVersion,
 		MinAllowedVersionCode: cfg.MinAllowedVersionCode,
+		DetachedSignature:     detachedSig,
+		Build:                 params.CIBuild,
 	}
 
 	eventSet := &EventSet{
@@ -429,6 +776,22 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 		SoftwareAssets: []*nostr.Event{BuildSoftwareAssetEvent(assetMeta, params.Pubkey)},
 	}
 
+	now := params.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	nowTS := nostr.Timestamp(now.Unix())
+	eventSet.AppMetadata.CreatedAt = nowTS
+	eventSet.Release.CreatedAt = nowTS
+	for _, asset := range eventSet.SoftwareAssets {
+		asset.CreatedAt = nowTS
+	}
+
+	// Preserve curated fields from the existing app event that this build leaves empty
+	if params.ExistingApp != nil && !params.ReplaceApp {
+		MergeAppMetadata(eventSet.AppMetadata, params.ExistingApp)
+	}
+
 	// If a release timestamp is provided, use it for release and asset events
 	// by default. Optionally, app metadata can also use the release timestamp.
 	if !params.ReleaseTimestamp.IsZero() {
@@ -444,10 +807,15 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 
 	// When overwriting a release, ensure created_at is strictly greater than the
 	// existing event's timestamp so the relay's NIP-33 replacement guard fires.
+	// The bump never goes more than a few seconds past now: an existing event
+	// from the future cannot be replaced before its own created_at anyway.
 	if !params.MinReleaseTimestamp.IsZero() {
 		minTS := nostr.Timestamp(params.MinReleaseTimestamp.Unix())
 		if eventSet.Release.CreatedAt <= minTS {
 			bumpTS := minTS + 1
+			if limit := nostr.Timestamp(now.Add(maxReleaseBumpAhead).Unix()); bumpTS > limit {
+				bumpTS = max(limit, eventSet.Release.CreatedAt)
+			}
 			eventSet.Release.CreatedAt = bumpTS
 			for _, asset := range eventSet.SoftwareAssets {
 				asset.CreatedAt = bumpTS
@@ -455,7 +823,41 @@ func BuildEventSet(params BuildEventSetParams) *EventSet {
 		}
 	}
 
-	return eventSet
+	// The article is replaced along with the release it belongs to
+	if notesMeta != nil {
+		eventSet.ReleaseNotes = BuildReleaseNotesEvent(notesMeta, params.Pubkey)
+		eventSet.ReleaseNotes.CreatedAt = eventSet.Release.CreatedAt
+	}
+
+	if params.Delegation != nil {
+		if err := eventSet.applyDelegation(params.Delegation); err != nil {
+			return nil, err
+		}
+	}
+
+	return eventSet, nil
+}
+
+// applyDelegation adds the delegation tag to every event, failing with a
+// *ValidationError for an event its conditions do not allow.
+func (es *EventSet) applyDelegation(d *Delegation) error {
+	events := []*nostr.Event{es.AppMetadata, es.Release, es.ReleaseNotes}
+	events = append(events, es.SoftwareAssets...)
+	var v validator
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		if err := d.Allows(event.Kind, event.CreatedAt); err != nil {
+			v.add("delegation", "%s", err)
+			continue
+		}
+		event.Tags = append(event.Tags, d.Tag())
+	}
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
 }
 
 // AddAssetReference adds an asset event ID reference to the Release event.
@@ -476,6 +878,42 @@ func (es *EventSet) AddAssetReferences(relayHint string) {
 	}
 }
 
+// CheckIDs verifies that every event's ID is the hash of its content and that
+// the Release references every Software Asset by that ID. Unsigned events handed
+// to an external signer must pass, or the signed release would point at assets
+// that were never published under those IDs.
+func (es *EventSet) CheckIDs() error {
+	events := []*nostr.Event{es.Release}
+	if es.AppMetadata != nil {
+		events = append(events, es.AppMetadata)
+	}
+	events = append(events, es.SoftwareAssets...)
+	if es.IdentityProof != nil {
+		events = append(events, es.IdentityProof)
+	}
+	if es.ReleaseNotes != nil {
+		events = append(events, es.ReleaseNotes)
+	}
+	for _, e := range events {
+		if e.ID != e.GetID() {
+			return fmt.Errorf("kind %d event ID %q does not match its content", e.Kind, e.ID)
+		}
+	}
+
+	referenced := make(map[string]bool)
+	for _, tag := range es.Release.Tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			referenced[tag[1]] = true
+		}
+	}
+	for _, asset := range es.SoftwareAssets {
+		if !referenced[asset.ID] {
+			return fmt.Errorf("release does not reference Software Asset event %s", asset.ID)
+		}
+	}
+	return nil
+}
+
 // UpdateReleasePlatforms aggregates platform identifiers (f tags) from all Software Assets
 // and updates the Release event. This should be called after all assets are added to the EventSet
 // but before the Release event is signed. This is useful when publishing multiple APK variants
@@ -518,3 +956,87 @@ func (es *EventSet) UpdateReleasePlatforms() {
 
 	es.Release.Tags = newTags
 }
+
+// mergeableAppTags lists kind 32267 tag keys that are preserved from an existing
+// event when a new build leaves them empty. Structural tags (d, name, f, h) are
+// always taken from the new build.
+var mergeableAppTags = []string{"summary", "icon", "image", "t", "url", "repository", "a", "license"}
+
+// MergeAppMetadata copies fields from an existing kind 32267 event into event
+// for every field event leaves empty. Returns the names of preserved fields
+// ("description" for content, tag keys otherwise).
+func MergeAppMetadata(event, existing *nostr.Event) []string {
+	if event == nil || existing == nil {
+		return nil
+	}
+
+	var preserved []string
+	for _, key := range mergeableAppTags {
+		if len(filterTags(event.Tags, key)) > 0 {
+			continue
+		}
+		old := filterTags(existing.Tags, key)
+		if len(old) == 0 {
+			continue
+		}
+		for _, tag := range old {
+			event.Tags = append(event.Tags, append(nostr.Tag{}, tag...))
+		}
+		preserved = append(preserved, key)
+	}
+
+	if event.Content == "" && existing.Content != "" {
+		event.Content = existing.Content
+		preserved = append(preserved, "description")
+	}
+
+	return preserved
+}
+
+// FieldChange describes how one kind 32267 field differs between two events.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// DiffAppMetadata returns the field-level changes from old to new for kind 32267 events.
+// Multi-valued tags are compared as comma-separated lists.
+func DiffAppMetadata(old, new *nostr.Event) []FieldChange {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	var changes []FieldChange
+	for _, key := range append([]string{"name"}, mergeableAppTags...) {
+		o := joinTagValues(filterTags(old.Tags, key))
+		n := joinTagValues(filterTags(new.Tags, key))
+		if o != n {
+			changes = append(changes, FieldChange{Field: key, Old: o, New: n})
+		}
+	}
+	if old.Content != new.Content {
+		changes = append(changes, FieldChange{Field: "description", Old: old.Content, New: new.Content})
+	}
+	return changes
+}
+
+// filterTags returns tags whose key exactly matches (Tags.GetAll does prefix matching).
+func filterTags(tags nostr.Tags, key string) nostr.Tags {
+	var result nostr.Tags
+	for _, tag := range tags {
+		if len(tag) > 1 && tag[0] == key {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// joinTagValues joins the first value of each tag with ", ".
+func joinTagValues(tags nostr.Tags) string {
+	values := make([]string, len(tags))
+	for i, tag := range tags {
+		values[i] = tag[1]
+	}
+	return strings.Join(values, ", ")
+}
@@ -1,10 +1,15 @@
 package nostr
 
 import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/zapstore/zsp/internal/apk"
 	"github.com/zapstore/zsp/internal/config"
 )
@@ -21,6 +26,15 @@ func filterExactTag(tags nostr.Tags, key string) nostr.Tags {
 	return result
 }
 
+func mustBuildEventSet(t *testing.T, params BuildEventSetParams) *EventSet {
+	t.Helper()
+	events, err := BuildEventSet(params)
+	if err != nil {
+		t.Fatalf("BuildEventSet() error: %v", err)
+	}
+	return events
+}
+
 func TestBuildAppMetadataEvent(t *testing.T) {
 	meta := &AppMetadata{
 		PackageID:   "com.example.app",
@@ -251,6 +265,41 @@ func TestBuildSoftwareAssetEvent(t *testing.T) {
 	}
 }
 
+func TestBuildSoftwareAssetEventDetachedSignature(t *testing.T) {
+	meta := &AssetMetadata{Identifier: "com.example.app", Version: "1.0.0", SHA256: "abc123"}
+	if tag := BuildSoftwareAssetEvent(meta, "pubkey").Tags.GetFirst([]string{"signature"}); tag != nil {
+		t.Errorf("unexpected signature tag without a detached signature: %v", *tag)
+	}
+
+	meta.DetachedSignature = &DetachedSignature{Type: "minisign", URL: "https://cdn.example.com/def456", SHA256: "def456"}
+	tag := BuildSoftwareAssetEvent(meta, "pubkey").Tags.GetFirst([]string{"signature"})
+	want := nostr.Tag{"signature", "minisign", "https://cdn.example.com/def456", "def456"}
+	if tag == nil || !reflect.DeepEqual(*tag, want) {
+		t.Errorf("signature tag = %v, want %v", tag, want)
+	}
+}
+
+func TestBuildSoftwareAssetEventDeviceClasses(t *testing.T) {
+	meta := &AssetMetadata{Identifier: "com.example.app", Version: "1.0.0", SHA256: "abc123", MinSDK: 21}
+	event := BuildSoftwareAssetEvent(meta, "pubkey")
+	if tag := event.Tags.GetFirst([]string{"max_platform_version"}); tag != nil {
+		t.Errorf("unexpected max_platform_version tag without maxSdkVersion: %v", *tag)
+	}
+	if tags := filterExactTag(event.Tags, "device"); len(tags) != 0 {
+		t.Errorf("unexpected device tags for a general app: %v", tags)
+	}
+
+	meta.MaxSDK = 28
+	meta.DeviceClasses = []string{"tv"}
+	event = BuildSoftwareAssetEvent(meta, "pubkey")
+	if tag := event.Tags.GetFirst([]string{"max_platform_version"}); tag == nil || (*tag)[1] != "28" {
+		t.Errorf("max_platform_version tag = %v, want 28", tag)
+	}
+	if tags := filterExactTag(event.Tags, "device"); len(tags) != 1 || tags[0][1] != "tv" {
+		t.Errorf("device tags = %v, want [device tv]", tags)
+	}
+}
+
 func TestBuildEventSet(t *testing.T) {
 	apkInfo := &apk.APKInfo{
 		PackageID:       "com.example.app",
@@ -276,7 +325,7 @@ func TestBuildEventSet(t *testing.T) {
 	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 	originalURL := "https://github.com/example/app/releases/download/v1.0.0/app.apk"
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo:     apkInfo,
 		Config:      cfg,
 		Pubkey:      pubkey,
@@ -344,7 +393,7 @@ func TestBuildEventSetFallbackToLabel(t *testing.T) {
 
 	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo: apkInfo,
 		Config:  cfg,
 		Pubkey:  pubkey,
@@ -372,7 +421,7 @@ func TestBuildEventSetArchitectureIndependent(t *testing.T) {
 	cfg := &config.Config{}
 	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo: apkInfo,
 		Config:  cfg,
 		Pubkey:  pubkey,
@@ -385,6 +434,197 @@ func TestBuildEventSetArchitectureIndependent(t *testing.T) {
 	}
 }
 
+func TestBuildEventSetPlatformOverride(t *testing.T) {
+	apkInfo := &apk.APKInfo{
+		PackageID:     "com.example.app",
+		VersionName:   "1.0.0",
+		VersionCode:   1,
+		SHA256:        "abc123",
+		Architectures: []string{"armeabi"},
+	}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	events := mustBuildEventSet(t, BuildEventSetParams{
+		APKInfo:   apkInfo,
+		Config:    &config.Config{},
+		Pubkey:    pubkey,
+		Platforms: []string{"android-armeabi-v7a"},
+	})
+
+	for name, event := range map[string]*nostr.Event{
+		"app":   events.AppMetadata,
+		"asset": events.SoftwareAssets[0],
+	} {
+		fTags := filterExactTag(event.Tags, "f")
+		if len(fTags) != 1 || fTags[0][1] != "android-armeabi-v7a" {
+			t.Errorf("%s f tags = %v, want only android-armeabi-v7a", name, fTags)
+		}
+	}
+}
+
+func TestBuildEventSetKeepAssets(t *testing.T) {
+	apkInfo := &apk.APKInfo{
+		PackageID:     "com.example.app",
+		VersionName:   "1.0.0",
+		VersionCode:   1,
+		SHA256:        "abc123",
+		Architectures: []string{"x86_64"},
+	}
+	kept := &nostr.Event{ID: "feed", Kind: KindSoftwareAsset, Tags: nostr.Tags{{"x", "def456"}, {"f", "android-arm64-v8a"}, {"f", "android-x86_64"}}}
+
+	events := mustBuildEventSet(t, BuildEventSetParams{
+		APKInfo:    apkInfo,
+		Config:     &config.Config{},
+		Pubkey:     "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		KeepAssets: []*nostr.Event{kept},
+	})
+	events.AddAssetReferences("")
+
+	eTags := filterExactTag(events.Release.Tags, "e")
+	if len(eTags) != 2 || eTags[0][1] != "feed" || eTags[1][1] != events.SoftwareAssets[0].ID {
+		t.Errorf("release e tags = %v, want the kept asset then the new one", eTags)
+	}
+	for name, event := range map[string]*nostr.Event{"app": events.AppMetadata, "release": events.Release} {
+		fTags := filterExactTag(event.Tags, "f")
+		if len(fTags) != 2 || fTags[0][1] != "android-x86_64" || fTags[1][1] != "android-arm64-v8a" {
+			t.Errorf("%s f tags = %v, want android-x86_64 and android-arm64-v8a", name, fTags)
+		}
+	}
+	if fTags := filterExactTag(events.SoftwareAssets[0].Tags, "f"); len(fTags) != 1 {
+		t.Errorf("asset f tags = %v, want only the APK's own platform", fTags)
+	}
+}
+
+func TestBuildEventSetLanguages(t *testing.T) {
+	events := mustBuildEventSet(t, BuildEventSetParams{
+		APKInfo: &apk.APKInfo{
+			PackageID:   "com.example.app",
+			VersionName: "1.0.0",
+			VersionCode: 1,
+			SHA256:      strings.Repeat("a", 64),
+			Locales:     []string{"de", "fil", "pt", "pt-BR"},
+		},
+		Config: &config.Config{},
+		Pubkey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	})
+
+	tags := events.AppMetadata.Tags
+	if ns := filterExactTag(tags, "L"); len(ns) != 1 || ns[0][1] != "ISO-639-1" {
+		t.Errorf("L tags = %v, want one ISO-639-1 namespace", ns)
+	}
+	var languages []string
+	for _, tag := range filterExactTag(tags, "l") {
+		if len(tag) != 3 || tag[2] != "ISO-639-1" {
+			t.Errorf("l tag = %v, want the ISO-639-1 namespace", tag)
+		}
+		languages = append(languages, tag[1])
+	}
+	if want := []string{"de", "pt"}; !slices.Equal(languages, want) {
+		t.Errorf("languages = %v, want %v", languages, want)
+	}
+
+	events = mustBuildEventSet(t, BuildEventSetParams{
+		APKInfo: &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: strings.Repeat("a", 64)},
+		Config:  &config.Config{},
+		Pubkey:  "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	})
+	if ns := filterExactTag(events.AppMetadata.Tags, "L"); len(ns) != 0 {
+		t.Errorf("L tags without locales = %v, want none", ns)
+	}
+}
+
+func TestPublicBlossomURL(t *testing.T) {
+	const server = "http://blossom.internal"
+	hash := strings.Repeat("b", 64)
+	tests := []struct {
+		name, blobURL, public, want string
+	}{
+		{"base URL", server + "/" + hash, "https://cdn.example.com", "https://cdn.example.com/" + hash},
+		{"base URL with trailing slash", server + "/" + hash, "https://cdn.example.com/", "https://cdn.example.com/" + hash},
+		{"template", server + "/" + hash, "https://cdn.example.com/{hash}.apk", "https://cdn.example.com/" + hash + ".apk"},
+		{"other host is kept", "https://github.com/acme/app.apk", "https://cdn.example.com", "https://github.com/acme/app.apk"},
+		{"nested path is kept", server + "/x/" + hash, "https://cdn.example.com", server + "/x/" + hash},
+		{"no public URL", server + "/" + hash, "", server + "/" + hash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PublicBlossomURL(tt.blobURL, server+"/", tt.public); got != tt.want {
+				t.Errorf("PublicBlossomURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildEventSetBlossomPublicURL(t *testing.T) {
+	hash := strings.Repeat("a", 64)
+	apkInfo := &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: hash}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	for public, want := range map[string]string{
+		"https://cdn.example.com/":             "https://cdn.example.com/" + hash,
+		"https://cdn.example.com/b/{hash}.apk": "https://cdn.example.com/b/" + hash + ".apk",
+		"":                                     "http://blossom.internal/" + hash,
+	} {
+		events := mustBuildEventSet(t, BuildEventSetParams{
+			APKInfo:          apkInfo,
+			Config:           &config.Config{},
+			Pubkey:           pubkey,
+			OriginalURL:      "https://github.com/acme/app/releases/download/v1.0.0/app.apk",
+			BlossomServer:    "http://blossom.internal",
+			BlossomPublicURL: public,
+			IconURL:          "http://blossom.internal/" + hash,
+			ImageURLs:        []string{"https://example.com/shot.png"},
+		})
+		urls := filterExactTag(events.SoftwareAssets[0].Tags, "url")
+		if len(urls) != 2 || urls[0][1] != "https://github.com/acme/app/releases/download/v1.0.0/app.apk" || urls[1][1] != want {
+			t.Errorf("public %q: asset url tags = %v, want the original URL and %s", public, urls, want)
+		}
+		if icon := filterExactTag(events.AppMetadata.Tags, "icon"); len(icon) != 1 || icon[0][1] != want {
+			t.Errorf("public %q: icon = %v, want %s", public, icon, want)
+		}
+		if images := filterExactTag(events.AppMetadata.Tags, "image"); len(images) != 1 || images[0][1] != "https://example.com/shot.png" {
+			t.Errorf("public %q: image = %v, want the external URL unchanged", public, images)
+		}
+	}
+}
+
+func TestBuildEventSetChannelSuffix(t *testing.T) {
+	apkInfo := &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		channel string
+		suffix  bool
+		wantID  string
+	}{
+		{"beta", false, "com.example.app"},
+		{"main", true, "com.example.app"},
+		{"", true, "com.example.app"},
+		{"beta", true, "com.example.app~beta"},
+	}
+	for _, tt := range tests {
+		events := mustBuildEventSet(t, BuildEventSetParams{
+			APKInfo:       apkInfo,
+			Config:        &config.Config{},
+			Pubkey:        pubkey,
+			Channel:       tt.channel,
+			ChannelSuffix: tt.suffix,
+		})
+		if got := tagValue(events.AppMetadata, "d"); got != tt.wantID {
+			t.Errorf("channel %q, suffix %v: app d = %q, want %q", tt.channel, tt.suffix, got, tt.wantID)
+		}
+		if got := tagValue(events.Release, "i"); got != tt.wantID {
+			t.Errorf("channel %q, suffix %v: release i = %q, want %q", tt.channel, tt.suffix, got, tt.wantID)
+		}
+		if got := tagValue(events.Release, "d"); got != tt.wantID+"@1.0.0" {
+			t.Errorf("channel %q, suffix %v: release d = %q, want %q", tt.channel, tt.suffix, got, tt.wantID+"@1.0.0")
+		}
+		if got := tagValue(events.SoftwareAssets[0], "i"); got != "com.example.app" {
+			t.Errorf("channel %q, suffix %v: asset i = %q, want the bare package ID", tt.channel, tt.suffix, got)
+		}
+	}
+}
+
 // TestBuildAppMetadataEmptyOptionalFields tests that empty optional fields are handled gracefully
 func TestBuildAppMetadataEmptyOptionalFields(t *testing.T) {
 	meta := &AppMetadata{
@@ -547,7 +787,7 @@ func TestBuildEventSetWithChangelog(t *testing.T) {
 	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 	changelog := "Fixed critical bug in payment processing"
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo:   apkInfo,
 		Config:    cfg,
 		Pubkey:    pubkey,
@@ -575,7 +815,7 @@ func TestBuildEventSetMultipleArchitectures(t *testing.T) {
 	cfg := &config.Config{}
 	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo: apkInfo,
 		Config:  cfg,
 		Pubkey:  pubkey,
@@ -621,7 +861,7 @@ func TestBuildEventSetReleaseTimestampDefaultsToReleaseAndAssetOnly(t *testing.T
 	releaseTS := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
 	expectedTS := nostr.Timestamp(releaseTS.Unix())
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo:          apkInfo,
 		Config:           cfg,
 		Pubkey:           pubkey,
@@ -657,7 +897,7 @@ func TestBuildEventSetReleaseTimestampCanAlsoApplyToAppMetadata(t *testing.T) {
 	releaseTS := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
 	expectedTS := nostr.Timestamp(releaseTS.Unix())
 
-	events := BuildEventSet(BuildEventSetParams{
+	events := mustBuildEventSet(t, BuildEventSetParams{
 		APKInfo:                   apkInfo,
 		Config:                    cfg,
 		Pubkey:                    pubkey,
@@ -678,3 +918,465 @@ func TestBuildEventSetReleaseTimestampCanAlsoApplyToAppMetadata(t *testing.T) {
 		t.Errorf("expected app metadata created_at %d, got %d", expectedTS, events.AppMetadata.CreatedAt)
 	}
 }
+
+func TestBuildEventSetPublishedAt(t *testing.T) {
+	apkInfo := &apk.APKInfo{
+		PackageID:   "com.example.app",
+		VersionName: "1.0.0",
+		VersionCode: 1,
+		Label:       "Test App",
+		SHA256:      "abc123",
+		FilePath:    "/path/to/app.apk",
+	}
+
+	cfg := &config.Config{}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	releaseTS := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	publishedAt := time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		publishedAt time.Time
+		want        string
+	}{
+		{"distinct from created_at", publishedAt, "1577750400"},
+		{"omitted when zero", time.Time{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := mustBuildEventSet(t, BuildEventSetParams{
+				APKInfo:          apkInfo,
+				Config:           cfg,
+				Pubkey:           pubkey,
+				ReleaseTimestamp: releaseTS,
+				PublishedAt:      tt.publishedAt,
+			})
+
+			if events.Release.CreatedAt != nostr.Timestamp(releaseTS.Unix()) {
+				t.Errorf("expected release created_at %d, got %d", releaseTS.Unix(), events.Release.CreatedAt)
+			}
+
+			tags := filterExactTag(events.Release.Tags, "published_at")
+			if tt.want == "" {
+				if len(tags) != 0 {
+					t.Errorf("expected no published_at tag, got %v", tags)
+				}
+				return
+			}
+			if len(tags) != 1 || len(tags[0]) < 2 {
+				t.Fatalf("expected one published_at tag, got %v", tags)
+			}
+			if tags[0][1] != tt.want {
+				t.Errorf("published_at = %s, want %s", tags[0][1], tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildEventSetReleaseTagName(t *testing.T) {
+	apkInfo := &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.2.3", VersionCode: 1, SHA256: "abc123"}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	events := mustBuildEventSet(t, BuildEventSetParams{APKInfo: apkInfo, Config: &config.Config{}, Pubkey: pubkey, TagName: "v1.2.3-android"})
+	if tags := filterExactTag(events.Release.Tags, "tag_name"); len(tags) != 1 || tags[0][1] != "v1.2.3-android" {
+		t.Errorf("tag_name tags = %v, want [tag_name v1.2.3-android]", tags)
+	}
+	if version := events.Release.Tags.GetFirst([]string{"version"}); version == nil || (*version)[1] != "1.2.3" {
+		t.Errorf("version tag = %v, want 1.2.3", version)
+	}
+	for _, asset := range events.SoftwareAssets {
+		if tags := filterExactTag(asset.Tags, "tag_name"); len(tags) != 0 {
+			t.Errorf("asset event has tag_name tags %v", tags)
+		}
+	}
+
+	events = mustBuildEventSet(t, BuildEventSetParams{APKInfo: apkInfo, Config: &config.Config{}, Pubkey: pubkey})
+	if tags := filterExactTag(events.Release.Tags, "tag_name"); len(tags) != 0 {
+		t.Errorf("expected no tag_name tag without a source tag, got %v", tags)
+	}
+}
+
+func TestEventSetCheckIDs(t *testing.T) {
+	apkInfo := &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.2.3", VersionCode: 1, SHA256: "abc123"}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	build := func() *EventSet {
+		events := mustBuildEventSet(t, BuildEventSetParams{APKInfo: apkInfo, Config: &config.Config{}, Pubkey: pubkey})
+		if err := SignEventSet(context.Background(), &NpubSigner{publicKey: pubkey}, events, ""); err != nil {
+			t.Fatal(err)
+		}
+		return events
+	}
+
+	if err := build().CheckIDs(); err != nil {
+		t.Errorf("CheckIDs() on npub-signed events: %v", err)
+	}
+
+	events := build()
+	events.SoftwareAssets[0].Content = "changed"
+	if err := events.CheckIDs(); err == nil {
+		t.Error("CheckIDs() accepted an asset edited after its ID was computed")
+	}
+
+	events = build()
+	var tags nostr.Tags
+	for _, tag := range events.Release.Tags {
+		if tag[0] != "e" {
+			tags = append(tags, tag)
+		}
+	}
+	events.Release.Tags = tags
+	events.Release.ID = events.Release.GetID()
+	if err := events.CheckIDs(); err == nil || !strings.Contains(err.Error(), "does not reference") {
+		t.Errorf("CheckIDs() = %v, want a missing asset reference error", err)
+	}
+}
+
+func TestBuildEventSetNowAndReleaseBumpClamp(t *testing.T) {
+	apkInfo := &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	nowTS := nostr.Timestamp(now.Unix())
+
+	tests := []struct {
+		name       string
+		minRelease time.Time
+		want       nostr.Timestamp
+	}{
+		{"no overwrite", time.Time{}, nowTS},
+		{"existing in the past", now.Add(-time.Hour), nowTS},
+		{"existing at now", now, nowTS + 1},
+		{"existing in the future", now.Add(6 * time.Hour), nowTS + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := mustBuildEventSet(t, BuildEventSetParams{
+				APKInfo:             apkInfo,
+				Config:              &config.Config{},
+				Pubkey:              pubkey,
+				Now:                 now,
+				MinReleaseTimestamp: tt.minRelease,
+			})
+			if events.AppMetadata.CreatedAt != nowTS {
+				t.Errorf("app created_at = %d, want %d", events.AppMetadata.CreatedAt, nowTS)
+			}
+			if events.Release.CreatedAt != tt.want {
+				t.Errorf("release created_at = %d, want %d", events.Release.CreatedAt, tt.want)
+			}
+			if events.SoftwareAssets[0].CreatedAt != tt.want {
+				t.Errorf("asset created_at = %d, want %d", events.SoftwareAssets[0].CreatedAt, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildEventSetProvenance(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	params := BuildEventSetParams{
+		APKInfo: &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"},
+		Config:  &config.Config{},
+		Pubkey:  "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Provenance: []MetadataProvenance{
+			{Source: "fdroid", URL: "https://f-droid.org/en/packages/com.example.app/", SHA256: hash},
+		},
+	}
+	events := mustBuildEventSet(t, params)
+	got := events.AppMetadata.Tags.GetAll([]string{"provenance"})
+	if len(got) != 1 || !reflect.DeepEqual([]string(got[0]), []string{"provenance", "fdroid", "https://f-droid.org/en/packages/com.example.app/", hash}) {
+		t.Errorf("provenance tags = %v", got)
+	}
+
+	params.Provenance[0].SHA256 = "not-a-hash"
+	if _, err := BuildEventSet(params); err == nil {
+		t.Error("expected error for malformed provenance hash")
+	}
+}
+
+func TestBuildEventSetClientTags(t *testing.T) {
+	old := clientVersion
+	t.Cleanup(func() { clientVersion = old })
+	SetClientVersion("v1.2.3")
+
+	params := BuildEventSetParams{
+		APKInfo:   &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"},
+		Config:    &config.Config{ReleaseNotesEvent: true},
+		Pubkey:    "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Changelog: "Faster sync",
+	}
+	events := mustBuildEventSet(t, params)
+	all := []*nostr.Event{events.AppMetadata, events.Release, events.ReleaseNotes, events.SoftwareAssets[0]}
+	for _, event := range all {
+		client := filterExactTag(event.Tags, "client")
+		if len(client) != 1 || !reflect.DeepEqual([]string(client[0]), []string{"client", "zsp", "v1.2.3"}) {
+			t.Errorf("kind %d client tags = %v, want one naming zsp v1.2.3", event.Kind, client)
+		}
+		if build := filterExactTag(event.Tags, "build"); len(build) != 0 {
+			t.Errorf("kind %d has build tags %v without embed_build_info", event.Kind, build)
+		}
+	}
+
+	params.CIBuild = &CIBuild{System: "github-actions", RunURL: "https://github.com/owner/app/actions/runs/42"}
+	events = mustBuildEventSet(t, params)
+	all = []*nostr.Event{events.AppMetadata, events.Release, events.ReleaseNotes, events.SoftwareAssets[0]}
+	for _, event := range all {
+		build := filterExactTag(event.Tags, "build")
+		if len(build) != 1 || !reflect.DeepEqual([]string(build[0]), []string{"build", "github-actions", "https://github.com/owner/app/actions/runs/42"}) {
+			t.Errorf("kind %d build tags = %v", event.Kind, build)
+		}
+	}
+
+	proof := BuildIdentityProofEvent(nostr.Tags{{"d", "spki"}}, params.Pubkey, 1700000000)
+	if got := filterExactTag(proof.Tags, "client"); len(got) != 1 {
+		t.Errorf("identity proof client tags = %v, want one", got)
+	}
+}
+
+func TestBuildEventSetIconBlurhash(t *testing.T) {
+	params := BuildEventSetParams{
+		APKInfo: &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"},
+		Config:  &config.Config{},
+		Pubkey:  "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		IconURL: "https://cdn.zapstore.dev/icon",
+	}
+	if imeta := mustBuildEventSet(t, params).AppMetadata.Tags.GetFirst([]string{"imeta"}); imeta != nil {
+		t.Errorf("imeta tag without blurhash: %v", *imeta)
+	}
+
+	params.IconBlurhash = "LEHV6nWB2yk8pyo0adR*.7kCMdnj"
+	imeta := mustBuildEventSet(t, params).AppMetadata.Tags.GetFirst([]string{"imeta"})
+	want := []string{"imeta", "url https://cdn.zapstore.dev/icon", "blurhash LEHV6nWB2yk8pyo0adR*.7kCMdnj"}
+	if imeta == nil || !reflect.DeepEqual([]string(*imeta), want) {
+		t.Errorf("imeta tag = %v, want %v", imeta, want)
+	}
+}
+
+func TestBuildEventSetOverwriteAppMergeVsReplace(t *testing.T) {
+	apkInfo := &apk.APKInfo{
+		PackageID:   "com.example.app",
+		VersionName: "1.0.0",
+		VersionCode: 1,
+		Label:       "Test App",
+		SHA256:      "abc123",
+		FilePath:    "/path/to/app.apk",
+	}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	existing := &nostr.Event{
+		Kind: KindAppMetadata,
+		Tags: nostr.Tags{
+			{"d", "com.example.app"},
+			{"name", "Old Name"},
+			{"icon", "https://cdn.example.com/old-icon"},
+			{"image", "https://cdn.example.com/shot1"},
+			{"image", "https://cdn.example.com/shot2"},
+			{"t", "curated"},
+			{"t", "nostr"},
+		},
+		Content: "A curated description",
+	}
+
+	tests := []struct {
+		name            string
+		cfg             *config.Config
+		iconURL         string
+		existing        *nostr.Event
+		replace         bool
+		wantTags        []string
+		wantImages      int
+		wantIcon        string
+		wantDescription string
+	}{
+		{
+			name:            "merge preserves fields left empty",
+			cfg:             &config.Config{},
+			existing:        existing,
+			wantTags:        []string{"curated", "nostr"},
+			wantImages:      2,
+			wantIcon:        "https://cdn.example.com/old-icon",
+			wantDescription: "A curated description",
+		},
+		{
+			name:            "merge keeps fields set by new build",
+			cfg:             &config.Config{Tags: []string{"wallet"}, Description: "New description"},
+			iconURL:         "https://cdn.example.com/new-icon",
+			existing:        existing,
+			wantTags:        []string{"wallet"},
+			wantImages:      2,
+			wantIcon:        "https://cdn.example.com/new-icon",
+			wantDescription: "New description",
+		},
+		{
+			name:       "replace drops fields left empty",
+			cfg:        &config.Config{},
+			existing:   existing,
+			replace:    true,
+			wantTags:   nil,
+			wantImages: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := mustBuildEventSet(t, BuildEventSetParams{
+				APKInfo:     apkInfo,
+				Config:      tt.cfg,
+				Pubkey:      pubkey,
+				IconURL:     tt.iconURL,
+				ExistingApp: tt.existing,
+				ReplaceApp:  tt.replace,
+			})
+			app := events.AppMetadata
+
+			var gotTags []string
+			for _, tag := range filterExactTag(app.Tags, "t") {
+				gotTags = append(gotTags, tag[1])
+			}
+			if strings.Join(gotTags, ",") != strings.Join(tt.wantTags, ",") {
+				t.Errorf("t tags = %v, want %v", gotTags, tt.wantTags)
+			}
+			if n := len(filterExactTag(app.Tags, "image")); n != tt.wantImages {
+				t.Errorf("image tags = %d, want %d", n, tt.wantImages)
+			}
+			icons := filterExactTag(app.Tags, "icon")
+			if tt.wantIcon == "" {
+				if len(icons) != 0 {
+					t.Errorf("expected no icon tag, got %v", icons)
+				}
+			} else if len(icons) != 1 || icons[0][1] != tt.wantIcon {
+				t.Errorf("icon tags = %v, want %s", icons, tt.wantIcon)
+			}
+			if app.Content != tt.wantDescription {
+				t.Errorf("content = %q, want %q", app.Content, tt.wantDescription)
+			}
+			if name := filterExactTag(app.Tags, "name"); len(name) != 1 || name[0][1] != "Test App" {
+				t.Errorf("name should come from new build, got %v", name)
+			}
+		})
+	}
+}
+
+func TestDiffAppMetadata(t *testing.T) {
+	old := &nostr.Event{
+		Tags:    nostr.Tags{{"name", "App"}, {"t", "a"}, {"icon", "https://x/icon"}},
+		Content: "Old",
+	}
+	updated := &nostr.Event{
+		Tags:    nostr.Tags{{"name", "App"}, {"t", "a"}, {"t", "b"}},
+		Content: "Old",
+	}
+
+	changes := DiffAppMetadata(old, updated)
+	got := make(map[string]FieldChange)
+	for _, c := range changes {
+		got[c.Field] = c
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", changes)
+	}
+	if got["t"].Old != "a" || got["t"].New != "a, b" {
+		t.Errorf("t change = %+v", got["t"])
+	}
+	if got["icon"].Old != "https://x/icon" || got["icon"].New != "" {
+		t.Errorf("icon change = %+v", got["icon"])
+	}
+}
+
+func TestReleaseNotesExcerpt(t *testing.T) {
+	tests := []struct {
+		name, notes, configured, want string
+	}{
+		{"configured", "## 1.0\n\nLong notes.", "  Short.  ", "Short."},
+		{"first paragraph", "# 1.0.0\n\n![banner](banner.png)\n\nFaster sync\nand dark theme.\n\n- fix crash", "", "Faster sync\nand dark theme."},
+		{"list", "### Features\n\n- one\n- two\n\n### Fixes\n\n- three", "", "- one\n- two"},
+		{"only headings", "# 1.0.0\n\n---", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ReleaseNotesExcerpt(tt.notes, tt.configured); got != tt.want {
+			t.Errorf("%s: ReleaseNotesExcerpt = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildEventSetReleaseNotesEvent(t *testing.T) {
+	apkInfo := &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"}
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	notes := "# 1.0.0\n\nFaster sync\nand a dark theme.\n\n![shot](https://cdn.example.com/shot.png)"
+	publishedAt := time.Unix(1700000000, 0)
+
+	events := mustBuildEventSet(t, BuildEventSetParams{
+		APKInfo:     apkInfo,
+		Config:      &config.Config{Name: "Example", ReleaseNotesEvent: true},
+		Pubkey:      pubkey,
+		Changelog:   notes,
+		PublishedAt: publishedAt,
+	})
+	article := events.ReleaseNotes
+	if article == nil {
+		t.Fatal("ReleaseNotes = nil, want a kind 30023 event")
+	}
+	if article.Kind != KindLongForm || article.Content != notes {
+		t.Errorf("article kind %d, content %q; want %d with the full notes", article.Kind, article.Content, KindLongForm)
+	}
+	for key, want := range map[string]string{
+		"d":            "com.example.app@1.0.0",
+		"title":        "Example 1.0.0",
+		"summary":      "Faster sync and a dark theme.",
+		"published_at": "1700000000",
+	} {
+		if got := tagValue(article, key); got != want {
+			t.Errorf("article %s = %q, want %q", key, got, want)
+		}
+	}
+	if article.CreatedAt != events.Release.CreatedAt {
+		t.Errorf("article created_at %d, want the release's %d", article.CreatedAt, events.Release.CreatedAt)
+	}
+	if events.Release.Content != "Faster sync\nand a dark theme." {
+		t.Errorf("release content = %q, want the excerpt", events.Release.Content)
+	}
+	if got := tagValue(events.Release, "a"); got != "30023:"+pubkey+":com.example.app@1.0.0" {
+		t.Errorf("release a tag = %q, want the article address", got)
+	}
+
+	// Disabled, or without notes, nothing changes
+	for _, params := range []BuildEventSetParams{
+		{APKInfo: apkInfo, Config: &config.Config{}, Pubkey: pubkey, Changelog: notes},
+		{APKInfo: apkInfo, Config: &config.Config{ReleaseNotesEvent: true}, Pubkey: pubkey},
+	} {
+		events := mustBuildEventSet(t, params)
+		if events.ReleaseNotes != nil || tagValue(events.Release, "a") != "" || events.Release.Content != params.Changelog {
+			t.Errorf("release_notes_event %v with notes %q: got an article or a changed release", params.Config.ReleaseNotesEvent, params.Changelog)
+		}
+	}
+}
+
+func TestBuildTestPublisherAttestation(t *testing.T) {
+	ownerSK := nostr.GeneratePrivateKey()
+	owner, _ := nostr.GetPublicKey(ownerSK)
+	ephemeral, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+
+	event, err := BuildTestPublisherAttestation(ephemeral, owner)
+	if err != nil {
+		t.Fatalf("BuildTestPublisherAttestation() error: %v", err)
+	}
+	if event.Kind != KindLabel || event.PubKey != owner {
+		t.Errorf("kind %d by %s, want a label by the owner", event.Kind, event.PubKey)
+	}
+	if tag := event.Tags.FindWithValue("l", TestPublisherLabel); len(tag) != 3 || tag[2] != "zapstore" {
+		t.Errorf("l tag = %v", tag)
+	}
+	if event.Tags.FindWithValue("p", ephemeral) == nil {
+		t.Errorf("missing p tag for the ephemeral key: %v", event.Tags)
+	}
+	npub, _ := nip19.EncodePublicKey(owner)
+	if event.Content != "test publisher for "+npub {
+		t.Errorf("content = %q", event.Content)
+	}
+	if err := event.Sign(ownerSK); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := BuildTestPublisherAttestation(ephemeral, "not hex"); err == nil {
+		t.Error("expected an error for an invalid owner pubkey")
+	}
+}
@@ -0,0 +1,154 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// failoverAttemptTimeout bounds each connect or sign attempt against a single bunker,
+// so an unreachable bunker does not block the release. It leaves time for the user
+// to approve a request on their signer.
+const failoverAttemptTimeout = 60 * time.Second
+
+// FailoverSigner signs with the first of several bunkers that responds. If a bunker
+// fails to connect or sign, the next one is tried. All bunkers must sign for the same pubkey.
+type FailoverSigner struct {
+	urls       []string
+	connect    func(ctx context.Context, url string) (Signer, error)
+	onFailover func(message string)
+
+	current   Signer
+	next      int    // index of the next URL to connect to
+	publicKey string // pubkey of the first bunker that connected
+}
+
+// ParseBunkerList splits a comma-separated SIGN_WITH value into bunker URLs.
+// Returns nil unless there are at least two entries and every entry is a bunker:// URL.
+func ParseBunkerList(signWith string) []string {
+	if !strings.Contains(signWith, ",") {
+		return nil
+	}
+	var urls []string
+	for _, part := range strings.Split(signWith, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.HasPrefix(part, "bunker://") {
+			return nil
+		}
+		urls = append(urls, part)
+	}
+	if len(urls) < 2 {
+		return nil
+	}
+	return urls
+}
+
+// NewFailoverSigner connects to the first reachable bunker in urls.
+// onFailover, if set, is called with a message each time a bunker is skipped.
+func NewFailoverSigner(ctx context.Context, urls []string, onFailover func(message string)) (*FailoverSigner, error) {
+	return newFailoverSigner(ctx, urls, func(ctx context.Context, url string) (Signer, error) {
+		return NewBunkerSigner(ctx, url)
+	}, onFailover)
+}
+
+func newFailoverSigner(ctx context.Context, urls []string, connect func(context.Context, string) (Signer, error), onFailover func(string)) (*FailoverSigner, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no bunker URLs")
+	}
+	s := &FailoverSigner{urls: urls, connect: connect, onFailover: onFailover}
+	if err := s.connectNext(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// connectNext connects to the next bunker in the list, checking that it signs for
+// the same pubkey as the bunkers before it.
+func (s *FailoverSigner) connectNext(ctx context.Context) error {
+	var errs []error
+	for s.next < len(s.urls) {
+		i := s.next
+		s.next++
+
+		attemptCtx, cancel := context.WithTimeout(ctx, failoverAttemptTimeout)
+		signer, err := s.connect(attemptCtx, s.urls[i])
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("bunker %d: %w", i+1, err))
+			s.failover(fmt.Sprintf("bunker %d of %d unavailable (%v)", i+1, len(s.urls), err))
+			continue
+		}
+
+		pubkey := signer.PublicKey()
+		if s.publicKey == "" {
+			s.publicKey = pubkey
+		} else if pubkey != s.publicKey {
+			signer.Close()
+			return fmt.Errorf("bunker %d signs for pubkey %s, but bunker 1 signed for %s; all SIGN_WITH bunkers must use the same key",
+				i+1, shortPubkey(pubkey), shortPubkey(s.publicKey))
+		}
+		s.current = signer
+		return nil
+	}
+	return fmt.Errorf("no bunker could sign: %w", errors.Join(errs...))
+}
+
+// failover reports that a bunker was skipped.
+func (s *FailoverSigner) failover(message string) {
+	if s.onFailover != nil && s.next < len(s.urls) {
+		s.onFailover(message + ", trying the next one")
+	}
+}
+
+func (s *FailoverSigner) Type() SignerType {
+	return SignerBunker
+}
+
+func (s *FailoverSigner) PublicKey() string {
+	return s.publicKey
+}
+
+// Sign signs with the current bunker, moving on to the next one if it fails.
+func (s *FailoverSigner) Sign(ctx context.Context, event *nostr.Event) error {
+	for {
+		if s.current == nil {
+			if err := s.connectNext(ctx); err != nil {
+				return err
+			}
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, failoverAttemptTimeout)
+		err := s.current.Sign(attemptCtx, event)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		s.current.Close()
+		s.current = nil
+		if s.next >= len(s.urls) {
+			return fmt.Errorf("no bunker could sign: %w", err)
+		}
+		s.failover(fmt.Sprintf("bunker %d of %d failed to sign (%v)", s.next, len(s.urls), err))
+	}
+}
+
+func (s *FailoverSigner) Close() error {
+	if s.current != nil {
+		return s.current.Close()
+	}
+	return nil
+}
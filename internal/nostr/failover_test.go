@@ -0,0 +1,127 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/netpolicy"
+)
+
+// flakySigner wraps an NsecSigner and fails every Sign call when broken is set.
+type flakySigner struct {
+	*NsecSigner
+	broken bool
+}
+
+func (s *flakySigner) Sign(ctx context.Context, event *nostr.Event) error {
+	if s.broken {
+		return errors.New("signer offline")
+	}
+	return s.NsecSigner.Sign(ctx, event)
+}
+
+func newTestNsecSigner(t *testing.T, hexKey string) *NsecSigner {
+	t.Helper()
+	signer, err := NewSignerWithOptions(context.Background(), hexKey, SignerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.(*NsecSigner)
+}
+
+func TestParseBunkerList(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"bunker://a?relay=wss://r1, bunker://b?relay=wss://r2", []string{"bunker://a?relay=wss://r1", "bunker://b?relay=wss://r2"}},
+		{"bunker://a?relay=wss://r1", nil},
+		{"bunker://a,nsec1abc", nil},
+		{"bunker://a,", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseBunkerList(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseBunkerList(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFailoverSigner(t *testing.T) {
+	// Separate signer instances for the same key, since Close zeroes an NsecSigner's key
+	hexKey := nostr.GeneratePrivateKey()
+	first := &flakySigner{NsecSigner: newTestNsecSigner(t, hexKey)}
+	second := &flakySigner{NsecSigner: newTestNsecSigner(t, hexKey)}
+	signers := map[string]Signer{"bunker://down": nil, "bunker://first": first, "bunker://second": second}
+	connect := func(_ context.Context, url string) (Signer, error) {
+		if s := signers[url]; s != nil {
+			return s, nil
+		}
+		return nil, errors.New("connection refused")
+	}
+
+	var messages []string
+	s, err := newFailoverSigner(context.Background(), []string{"bunker://down", "bunker://first", "bunker://second"}, connect,
+		func(m string) { messages = append(messages, m) })
+	if err != nil {
+		t.Fatalf("newFailoverSigner() error: %v", err)
+	}
+	if s.PublicKey() != first.PublicKey() || len(messages) != 1 {
+		t.Fatalf("expected to skip the down bunker, got pubkey %s, messages %v", s.PublicKey(), messages)
+	}
+
+	// The connected bunker stops responding: signing moves on to the next one
+	first.broken = true
+	event := &nostr.Event{Kind: 1, Content: "hello"}
+	if err := s.Sign(context.Background(), event); err != nil {
+		t.Fatalf("Sign() error: %v", err)
+	}
+	if ok, _ := event.CheckSignature(); !ok {
+		t.Error("event signature does not verify")
+	}
+	if len(messages) != 2 {
+		t.Errorf("expected a failover message for the broken bunker, got %v", messages)
+	}
+
+	// No bunkers left
+	second.broken = true
+	if err := s.Sign(context.Background(), &nostr.Event{Kind: 1}); err == nil || !strings.Contains(err.Error(), "no bunker could sign") {
+		t.Errorf("Sign() error = %v, want no bunker could sign", err)
+	}
+}
+
+func TestFailoverSignerRejectsMismatchedPubkeys(t *testing.T) {
+	first := &flakySigner{NsecSigner: newTestNsecSigner(t, nostr.GeneratePrivateKey())}
+	other := newTestNsecSigner(t, nostr.GeneratePrivateKey())
+	connect := func(_ context.Context, url string) (Signer, error) {
+		if url == "bunker://first" {
+			return first, nil
+		}
+		return other, nil
+	}
+
+	s, err := newFailoverSigner(context.Background(), []string{"bunker://first", "bunker://other"}, connect, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.broken = true
+	err = s.Sign(context.Background(), &nostr.Event{Kind: 1})
+	if err == nil || !strings.Contains(err.Error(), "same key") {
+		t.Errorf("Sign() error = %v, want pubkey mismatch", err)
+	}
+}
+
+func TestNewBunkerSignerHonorsAllowlist(t *testing.T) {
+	netpolicy.SetAllowlist([]string{"relay.zapstore.dev"})
+	t.Cleanup(func() { netpolicy.SetAllowlist(nil) })
+
+	pubkey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	_, err := NewBunkerSigner(context.Background(), "bunker://"+pubkey+"?relay=wss://relay.nsec.app")
+	var blocked *netpolicy.BlockedError
+	if !errors.As(err, &blocked) || blocked.Host != "relay.nsec.app" {
+		t.Fatalf("NewBunkerSigner() error = %v, want the bunker relay blocked", err)
+	}
+}
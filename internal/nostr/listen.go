@@ -0,0 +1,120 @@
+package nostr
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// PortScanRange is how many ports after a default port are tried when it is
+// taken (17008–17018 for the preview, 17007–17017 for the browser signer).
+const PortScanRange = 10
+
+// takeoverHeader must be set on takeover requests. A custom header cannot be
+// sent cross-origin without a CORS preflight, which the local servers never
+// grant, so a web page cannot shut down a running zsp server.
+const takeoverHeader = "X-Zsp-Takeover"
+
+// localProbeClient is used to identify servers already listening on a local port.
+var localProbeClient = &http.Client{Timeout: 2 * time.Second}
+
+// listenLocal binds 127.0.0.1 on port. A fixed port (--port, or one the user
+// entered) must be free; otherwise, if port is in use, the next ports up to
+// port+PortScanRange are tried. Returns the listener together with the port it
+// actually bound.
+func listenLocal(port int, fixed bool) (net.Listener, int, error) {
+	if fixed {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			return nil, 0, fmt.Errorf("port %d is not available (choose another with --port, or omit it to pick a free one): %w", port, err)
+		}
+		return listener, port, nil
+	}
+
+	var firstErr error
+	for candidate := port; candidate <= port+PortScanRange; candidate++ {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", candidate))
+		if err == nil {
+			return listener, candidate, nil
+		}
+		// The "in use" errno differs per OS (WSAEADDRINUSE on Windows), so any
+		// bind failure moves on to the next candidate.
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, 0, fmt.Errorf("no free port in %d-%d (choose one with --port): %w", port, port+PortScanRange, firstErr)
+}
+
+// IsStaleServer reports whether a zsp preview or browser signing server, typically
+// left behind by an earlier run, is answering on the given local port.
+func IsStaleServer(port int) bool {
+	probes := []struct {
+		path string
+		key  string
+	}{
+		{"/api/poll", "close"},  // preview server
+		{"/api/state", "nonce"}, // NIP-07 signing server
+	}
+	for _, probe := range probes {
+		resp, err := localProbeClient.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, probe.path))
+		if err != nil {
+			return false // nothing listening, or not speaking HTTP
+		}
+		var body map[string]any
+		decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || decodeErr != nil {
+			continue
+		}
+		if _, ok := body[probe.key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TakeOverStaleServer asks the zsp server on the given local port to shut down
+// and waits for the port to be released. Servers from zsp versions without the
+// takeover endpoint return an error; callers then fall back to another port.
+func TakeOverStaleServer(port int) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://127.0.0.1:%d/api/takeover", port), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(takeoverHeader, "1")
+	resp, err := localProbeClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach server on port %d: %w", port, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server on port %d refused takeover (HTTP %d)", port, resp.StatusCode)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err == nil {
+			listener.Close()
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("port %d is still in use after takeover", port)
+}
+
+// takeoverHandler returns a handler that shuts the server down via shutdown
+// once the response has been written.
+func takeoverHandler(shutdown func()) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get(takeoverHeader) == "" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		go shutdown()
+	}
+}
@@ -0,0 +1,84 @@
+package nostr
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestListenLocalFallsBackWhenPortTaken(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	taken := busy.Addr().(*net.TCPAddr).Port
+
+	listener, port, err := listenLocal(taken, false)
+	if err != nil {
+		t.Fatalf("listenLocal(%d) error: %v", taken, err)
+	}
+	defer listener.Close()
+
+	if port <= taken || port > taken+PortScanRange {
+		t.Errorf("bound port %d, want a port in %d-%d", port, taken+1, taken+PortScanRange)
+	}
+	if got := listener.Addr().(*net.TCPAddr).Port; got != port {
+		t.Errorf("listener is on %d, reported %d", got, port)
+	}
+}
+
+func TestListenLocalFixedPortMustBeFree(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	taken := busy.Addr().(*net.TCPAddr).Port
+
+	if listener, _, err := listenLocal(taken, true); err == nil {
+		listener.Close()
+		t.Fatalf("listenLocal(%d, fixed) succeeded on a busy port", taken)
+	} else if !strings.Contains(err.Error(), "--port") {
+		t.Errorf("error should point at --port: %v", err)
+	}
+
+	busy.Close()
+	listener, port, err := listenLocal(taken, true)
+	if err != nil {
+		t.Fatalf("listenLocal(%d, fixed) error on a free port: %v", taken, err)
+	}
+	defer listener.Close()
+	if port != taken {
+		t.Errorf("bound port %d, want %d", port, taken)
+	}
+}
+
+func TestStaleServerTakeover(t *testing.T) {
+	stale := NewPreviewServer(&PreviewData{AppName: "Stale", PackageID: "com.example.stale"}, "", "", 0)
+	if _, err := stale.Start(); err != nil {
+		t.Fatalf("failed to start preview server: %v", err)
+	}
+	defer stale.Close()
+	port := stale.Port()
+
+	if !IsStaleServer(port) {
+		t.Fatalf("IsStaleServer(%d) = false for a running preview server", port)
+	}
+	if err := TakeOverStaleServer(port); err != nil {
+		t.Fatalf("TakeOverStaleServer(%d) error: %v", port, err)
+	}
+	if IsStaleServer(port) {
+		t.Errorf("server on port %d still answering after takeover", port)
+	}
+
+	// A plain listener is not a zsp server
+	other, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if IsStaleServer(other.Addr().(*net.TCPAddr).Port) {
+		t.Error("IsStaleServer() = true for a non-HTTP listener")
+	}
+}
@@ -11,10 +11,14 @@ import (
 	"net/http"
 	"os/exec"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ui"
 )
 
 //go:embed templates/nip07.html
@@ -23,10 +27,19 @@ var nip07HTML string
 // DefaultNIP07Port is the default port for the NIP-07 browser signer.
 const DefaultNIP07Port = 17007
 
+// EnvNIP07Timeout is the environment variable holding how long the browser
+// signer waits for the extension to answer, as a duration ("90s", "5m") or seconds.
+const EnvNIP07Timeout = "NIP07_TIMEOUT"
+
+// DefaultNIP07Timeout is how long the browser signer waits for the public key
+// and for each batch of signatures when NIP07_TIMEOUT is not set.
+const DefaultNIP07Timeout = 2 * time.Minute
+
 // NIP07Signer signs events via browser NIP-07 extension.
 type NIP07Signer struct {
 	publicKey string
 	port      int
+	fixedPort bool // port was chosen by the user and must not be swapped for a free one
 	server    *http.Server
 	listener  net.Listener
 
@@ -35,8 +48,11 @@ type NIP07Signer struct {
 	eventsToSign  []map[string]any
 	pubkeyResult  chan string
 	signingResult chan []map[string]any
+	pageError     chan error // Failures reported by the signing page
 	shouldClose   bool
 	browserOpened bool
+	pageSeen      bool          // The signing page has polled the server at least once
+	timeout       time.Duration // Wait for the extension, per request
 
 	// Security: Session nonce to prevent replay attacks and CSRF
 	sessionNonce string
@@ -44,16 +60,44 @@ type NIP07Signer struct {
 
 // NIP07SignerOptions contains options for creating a NIP-07 signer.
 type NIP07SignerOptions struct {
-	Port int // Custom port (0 = use default)
+	Port     int              // Custom port, which must be free (0 = default, or the next free port)
+	OnListen func(url string) // Called once the server is bound, before the browser opens
+	Timeout  time.Duration    // Wait for the extension (0 = NIP07_TIMEOUT or DefaultNIP07Timeout)
 }
 
 // NewNIP07Signer creates and initializes a NIP-07 browser signer.
-// If port is 0, the default port (17007) is used.
+// If port is 0, the default port (17007) is used, or the next free one when it is taken.
 func NewNIP07Signer(ctx context.Context, port int) (*NIP07Signer, error) {
+	return NewNIP07SignerWithOptions(ctx, NIP07SignerOptions{Port: port})
+}
+
+// NewNIP07SignerWithOptions creates and initializes a NIP-07 browser signer.
+// If the default port is taken, the next free port in the scan range is used;
+// a custom port fails when busy.
+func NewNIP07SignerWithOptions(ctx context.Context, opts NIP07SignerOptions) (*NIP07Signer, error) {
+	port := opts.Port
 	if port == 0 {
 		port = DefaultNIP07Port
 	}
 
+	timeout := opts.Timeout
+	if timeout == 0 {
+		var err error
+		if timeout, err = ParseNIP07Timeout(config.GetEnv(EnvNIP07Timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse before binding a port nobody can reach
+	if !ui.HasDisplay() {
+		return nil, fmt.Errorf("browser signing needs a graphical display, and none was found (no DISPLAY or WAYLAND_DISPLAY): " +
+			"use SIGN_WITH=bunker://... or an nsec on headless machines")
+	}
+	if _, err := exec.LookPath(browserCommand()); err != nil {
+		return nil, fmt.Errorf("browser signing cannot open a browser: %q not found: "+
+			"use SIGN_WITH=bunker://... or an nsec instead", browserCommand())
+	}
+
 	// Security: Generate a random session nonce to prevent CSRF and replay attacks
 	nonceBytes := make([]byte, 16)
 	if _, err := rand.Read(nonceBytes); err != nil {
@@ -63,9 +107,12 @@ func NewNIP07Signer(ctx context.Context, port int) (*NIP07Signer, error) {
 
 	s := &NIP07Signer{
 		port:          port,
+		fixedPort:     opts.Port != 0,
 		mode:          "idle",
 		pubkeyResult:  make(chan string, 1),
 		signingResult: make(chan []map[string]any, 1),
+		pageError:     make(chan error, 1),
+		timeout:       timeout,
 		sessionNonce:  sessionNonce,
 	}
 
@@ -73,6 +120,9 @@ func NewNIP07Signer(ctx context.Context, port int) (*NIP07Signer, error) {
 	if err := s.startServer(); err != nil {
 		return nil, fmt.Errorf("failed to start NIP-07 server: %w", err)
 	}
+	if opts.OnListen != nil {
+		opts.OnListen(fmt.Sprintf("http://localhost:%d/", s.port))
+	}
 
 	// Get public key to verify extension is available
 	pubkey, err := s.getPublicKey(ctx)
@@ -121,31 +171,73 @@ func (s *NIP07Signer) SignBatch(ctx context.Context, events []*nostr.Event) erro
 	s.mu.Unlock()
 
 	// Wait for result
-	select {
-	case signedEvents := <-s.signingResult:
-		if len(signedEvents) != len(events) {
-			return fmt.Errorf("expected %d signed events, got %d", len(events), len(signedEvents))
-		}
+	signedEvents, err := waitForPage(ctx, s, s.signingResult, "signatures")
+	s.mu.Lock()
+	s.mode = "idle"
+	s.eventsToSign = nil
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
-		// Update events with signed values
-		for i, signed := range signedEvents {
-			events[i].ID = signed["id"].(string)
-			events[i].PubKey = signed["pubkey"].(string)
-			events[i].Sig = signed["sig"].(string)
-		}
+	if len(signedEvents) != len(events) {
+		return fmt.Errorf("expected %d signed events, got %d", len(events), len(signedEvents))
+	}
 
-		s.mu.Lock()
-		s.mode = "idle"
-		s.eventsToSign = nil
-		s.mu.Unlock()
+	// Update events with signed values
+	for i, signed := range signedEvents {
+		events[i].ID = signed["id"].(string)
+		events[i].PubKey = signed["pubkey"].(string)
+		events[i].Sig = signed["sig"].(string)
+	}
+	return nil
+}
 
-		return nil
+// waitForPage waits for the signing page to deliver a result on ch, failing
+// with an actionable error when the page reports a problem, the timeout
+// passes, or ctx is cancelled. what names the awaited result in errors.
+func waitForPage[T any](ctx context.Context, s *NIP07Signer, ch <-chan T, what string) (T, error) {
+	var zero T
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
 
+	select {
+	case result := <-ch:
+		return result, nil
+	case err := <-s.pageError:
+		return zero, err
 	case <-ctx.Done():
-		return ctx.Err()
+		return zero, ctx.Err()
+	case <-timer.C:
+		s.mu.Lock()
+		pageSeen := s.pageSeen
+		s.mu.Unlock()
+		if !pageSeen {
+			return zero, fmt.Errorf("timed out after %s: the signing page at http://localhost:%d/ was never opened; "+
+				"open it in the browser that has your Nostr extension", s.timeout, s.port)
+		}
+		return zero, fmt.Errorf("timed out after %s waiting for %s from the browser extension: "+
+			"make sure it is unlocked and approve the request (set %s to wait longer)", s.timeout, what, EnvNIP07Timeout)
 	}
 }
 
+// ParseNIP07Timeout parses a NIP07_TIMEOUT value: a Go duration ("90s", "5m")
+// or a number of seconds. Empty returns DefaultNIP07Timeout.
+func ParseNIP07Timeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DefaultNIP07Timeout, nil
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs > 0 {
+			return time.Duration(secs) * time.Second, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid %s %q: use a duration like 90s or 5m, or a number of seconds", EnvNIP07Timeout, value)
+}
+
 func (s *NIP07Signer) Close() error {
 	if s == nil {
 		return nil
@@ -157,12 +249,21 @@ func (s *NIP07Signer) Close() error {
 	// Give browser time to detect shutdown
 	time.Sleep(500 * time.Millisecond)
 
+	s.shutdownServer()
+	return nil
+}
+
+// Port returns the port the signing server is bound to.
+func (s *NIP07Signer) Port() int {
+	return s.port
+}
+
+func (s *NIP07Signer) shutdownServer() {
 	if s.server != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 		defer cancel()
 		s.server.Shutdown(ctx)
 	}
-	return nil
 }
 
 func (s *NIP07Signer) getPublicKey(ctx context.Context) (string, error) {
@@ -174,25 +275,20 @@ func (s *NIP07Signer) getPublicKey(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	select {
-	case pubkey := <-s.pubkeyResult:
-		s.mu.Lock()
-		s.mode = "idle"
-		s.mu.Unlock()
-		return pubkey, nil
-	case <-ctx.Done():
-		return "", ctx.Err()
-	case <-time.After(120 * time.Second):
-		return "", fmt.Errorf("timeout waiting for public key from NIP-07 extension")
-	}
+	pubkey, err := waitForPage(ctx, s, s.pubkeyResult, "the public key")
+	s.mu.Lock()
+	s.mode = "idle"
+	s.mu.Unlock()
+	return pubkey, err
 }
 
 func (s *NIP07Signer) startServer() error {
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.port))
+	listener, port, err := listenLocal(s.port, s.fixedPort)
 	if err != nil {
 		return err
 	}
 	s.listener = listener
+	s.port = port
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.handleIndex)
@@ -200,6 +296,8 @@ func (s *NIP07Signer) startServer() error {
 	mux.HandleFunc("/api/shutdown", s.securityMiddleware(s.handleShutdown))
 	mux.HandleFunc("/public-key", s.securityMiddleware(s.handlePublicKey))
 	mux.HandleFunc("/signed-events", s.securityMiddleware(s.handleSignedEvents))
+	mux.HandleFunc("/api/error", s.securityMiddleware(s.handlePageError))
+	mux.HandleFunc("/api/takeover", s.securityMiddleware(takeoverHandler(s.shutdownServer)))
 
 	s.server = &http.Server{Handler: mux}
 
@@ -240,6 +338,7 @@ func (s *NIP07Signer) handleIndex(w http.ResponseWriter, r *http.Request) {
 
 func (s *NIP07Signer) handleState(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
+	s.pageSeen = true
 	state := map[string]any{
 		"mode":  s.mode,
 		"data":  s.eventsToSign,
@@ -352,6 +451,70 @@ func (s *NIP07Signer) handleSignedEvents(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// handlePageError receives failures the signing page detects: no extension
+// injected, or the extension refusing a request.
+func (s *NIP07Signer) handlePageError(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Security: Verify session nonce from request header
+	requestNonce := r.Header.Get("X-Session-Nonce")
+	if requestNonce != s.sessionNonce {
+		http.Error(w, "Invalid session nonce", http.StatusForbidden)
+		return
+	}
+
+	var data struct {
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch data.Reason {
+	case "no-extension":
+		err = fmt.Errorf("no NIP-07 extension found in the browser: install one (e.g. Alby, nos2x, Flamingo) " +
+			"in the browser that opened, or use SIGN_WITH=bunker://... instead")
+	case "rejected":
+		message := data.Message
+		if message == "" {
+			message = "request denied"
+		}
+		if len(message) > 200 {
+			message = message[:200]
+		}
+		err = fmt.Errorf("the browser extension refused the request (%s): unlock it and approve the request, then run again", message)
+	default:
+		http.Error(w, "Unknown reason", http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	// Non-blocking send
+	select {
+	case s.pageError <- err:
+	default:
+	}
+}
+
+// browserCommand returns the program that opens URLs on this platform.
+func browserCommand() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "cmd"
+	default:
+		return "xdg-open"
+	}
+}
+
 func (s *NIP07Signer) openBrowser() error {
 	s.mu.Lock()
 	if s.browserOpened {
@@ -363,19 +526,16 @@ func (s *NIP07Signer) openBrowser() error {
 
 	url := fmt.Sprintf("http://localhost:%d/", s.port)
 
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
+	cmd := exec.Command(browserCommand(), url)
+	if runtime.GOOS == "windows" {
 		cmd = exec.Command("cmd", "/c", "start", url)
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
 	}
-
-	return cmd.Start()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w (open %s manually)", err, url)
+	}
+	// Reap the opener; the browser outlives it
+	go cmd.Wait()
+	return nil
 }
 
 
@@ -0,0 +1,88 @@
+package nostr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseNIP07Timeout(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":      DefaultNIP07Timeout,
+		"90":    90 * time.Second,
+		"5m":    5 * time.Minute,
+		" 30s ": 30 * time.Second,
+	}
+	for in, want := range tests {
+		got, err := ParseNIP07Timeout(in)
+		if err != nil || got != want {
+			t.Errorf("ParseNIP07Timeout(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"0", "-5", "soon", "-1m"} {
+		if _, err := ParseNIP07Timeout(in); err == nil {
+			t.Errorf("ParseNIP07Timeout(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func newTestNIP07Signer(timeout time.Duration) *NIP07Signer {
+	return &NIP07Signer{
+		port:          DefaultNIP07Port,
+		mode:          "publicKey",
+		pubkeyResult:  make(chan string, 1),
+		signingResult: make(chan []map[string]any, 1),
+		pageError:     make(chan error, 1),
+		timeout:       timeout,
+		sessionNonce:  "nonce",
+	}
+}
+
+func TestWaitForPageReportsPageErrors(t *testing.T) {
+	s := newTestNIP07Signer(time.Minute)
+
+	tests := map[string]string{
+		`{"reason":"no-extension"}`:                       "no NIP-07 extension found",
+		`{"reason":"rejected","message":"User rejected"}`: "refused the request (User rejected)",
+	}
+	for body, want := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/error", strings.NewReader(body))
+		req.Header.Set("X-Session-Nonce", "nonce")
+		rec := httptest.NewRecorder()
+		s.handlePageError(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("handlePageError(%s) status = %d", body, rec.Code)
+		}
+
+		_, err := waitForPage(context.Background(), s, s.pubkeyResult, "the public key")
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("waitForPage() error = %v, want it to contain %q", err, want)
+		}
+	}
+
+	// Reports need the session nonce
+	req := httptest.NewRequest(http.MethodPost, "/api/error", strings.NewReader(`{"reason":"rejected"}`))
+	rec := httptest.NewRecorder()
+	s.handlePageError(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("handlePageError without nonce: status = %d, want 403", rec.Code)
+	}
+}
+
+func TestWaitForPageTimeout(t *testing.T) {
+	s := newTestNIP07Signer(20 * time.Millisecond)
+
+	_, err := waitForPage(context.Background(), s, s.pubkeyResult, "the public key")
+	if err == nil || !strings.Contains(err.Error(), "was never opened") {
+		t.Errorf("before the page polls: error = %v, want a never-opened timeout", err)
+	}
+
+	s.handleState(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/state", nil))
+	_, err = waitForPage(context.Background(), s, s.pubkeyResult, "the public key")
+	if err == nil || !strings.Contains(err.Error(), "make sure it is unlocked") || !strings.Contains(err.Error(), EnvNIP07Timeout) {
+		t.Errorf("after the page polls: error = %v, want an unlock hint mentioning %s", err, EnvNIP07Timeout)
+	}
+}
@@ -0,0 +1,285 @@
+package nostr
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/netpolicy"
+)
+
+// RelayInfoTimeout bounds each NIP-11 information document request.
+const RelayInfoTimeout = 5 * time.Second
+
+// maxRelayInfoSize caps the NIP-11 document read from a relay.
+const maxRelayInfoSize = 1 << 20
+
+// PublishedKinds are the event kinds zsp publishes for a release.
+var PublishedKinds = []int{KindAppMetadata, KindRelease, KindSoftwareAsset}
+
+// RelayInfo is the part of a relay's NIP-11 information document zsp reports on.
+type RelayInfo struct {
+	Name          string           `json:"name,omitempty"`
+	Description   string           `json:"description,omitempty"`
+	Software      string           `json:"software,omitempty"`
+	Version       string           `json:"version,omitempty"`
+	SupportedNIPs []any            `json:"supported_nips,omitempty"` // numbers, or strings on some relays
+	Limitation    *RelayLimitation `json:"limitation,omitempty"`
+	Retention     []RelayRetention `json:"retention,omitempty"`
+	Fees          *RelayFees       `json:"fees,omitempty"`
+	PaymentsURL   string           `json:"payments_url,omitempty"`
+}
+
+// RelayLimitation holds the NIP-11 limits that can make a relay reject an event.
+type RelayLimitation struct {
+	MaxMessageLength int  `json:"max_message_length,omitempty"`
+	MaxContentLength int  `json:"max_content_length,omitempty"`
+	MaxEventTags     int  `json:"max_event_tags,omitempty"`
+	MinPowDifficulty int  `json:"min_pow_difficulty,omitempty"`
+	AuthRequired     bool `json:"auth_required,omitempty"`
+	PaymentRequired  bool `json:"payment_required,omitempty"`
+	RestrictedWrites bool `json:"restricted_writes,omitempty"`
+}
+
+// RelayRetention is a NIP-11 retention rule. A nil Time or Count means no
+// limit; zero means events of these kinds are not stored at all.
+type RelayRetention struct {
+	Kinds KindSet `json:"kinds,omitempty"` // empty means every kind
+	Time  *int64  `json:"time,omitempty"`
+	Count *int    `json:"count,omitempty"`
+}
+
+// RelayFees lists the NIP-11 publication fees, the only fees tied to event kinds.
+type RelayFees struct {
+	Publication []struct {
+		Kinds  []int  `json:"kinds,omitempty"`
+		Amount int    `json:"amount"`
+		Unit   string `json:"unit"`
+	} `json:"publication,omitempty"`
+}
+
+// KindSet is a NIP-11 kind list: single kinds and [from, to] ranges.
+type KindSet [][2]int
+
+// UnmarshalJSON accepts a mix of numbers and two-element ranges.
+func (k *KindSet) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*k = nil
+	for _, item := range items {
+		var kind int
+		if err := json.Unmarshal(item, &kind); err == nil {
+			*k = append(*k, [2]int{kind, kind})
+			continue
+		}
+		var r []int
+		if err := json.Unmarshal(item, &r); err != nil || len(r) != 2 {
+			return fmt.Errorf("invalid kind range %s", item)
+		}
+		*k = append(*k, [2]int{r[0], r[1]})
+	}
+	return nil
+}
+
+// MarshalJSON writes single kinds as numbers and ranges as pairs.
+func (k KindSet) MarshalJSON() ([]byte, error) {
+	items := make([]any, len(k))
+	for i, r := range k {
+		if r[0] == r[1] {
+			items[i] = r[0]
+		} else {
+			items[i] = r
+		}
+	}
+	return json.Marshal(items)
+}
+
+// Contains reports whether kind is in the set.
+func (k KindSet) Contains(kind int) bool {
+	for _, r := range k {
+		if kind >= r[0] && kind <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// relayInfoURL maps a ws:// or wss:// relay URL to the http(s):// URL serving its NIP-11 document.
+func relayInfoURL(relayURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(relayURL))
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid relay URL %q", relayURL)
+	}
+	switch u.Scheme {
+	case "wss":
+		u.Scheme = "https"
+	case "ws":
+		u.Scheme = "http"
+	default:
+		return "", fmt.Errorf("invalid relay URL %q: must start with wss:// or ws://", relayURL)
+	}
+	return u.String(), nil
+}
+
+// FetchRelayInfo requests the NIP-11 information document of a relay.
+func FetchRelayInfo(ctx context.Context, client *http.Client, relayURL string) (*RelayInfo, error) {
+	infoURL, err := relayInfoURL(relayURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, infoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/nostr+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch relay information: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("relay information request returned %s", resp.Status)
+	}
+
+	var info RelayInfo
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxRelayInfoSize)).Decode(&info); err != nil {
+		return nil, fmt.Errorf("invalid relay information document: %w", err)
+	}
+	return &info, nil
+}
+
+// RelayInfoResult is the NIP-11 document of one relay, or why it could not be fetched.
+type RelayInfoResult struct {
+	URL  string
+	Info *RelayInfo
+	Err  error
+}
+
+// FetchRelayInfos fetches the NIP-11 documents of relayURLs concurrently.
+// Results are in relayURLs order.
+func FetchRelayInfos(ctx context.Context, relayURLs []string) []RelayInfoResult {
+	client := &http.Client{
+		Timeout: RelayInfoTimeout,
+		Transport: netpolicy.WrapTransport(&http.Transport{
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+			Proxy:           http.ProxyFromEnvironment,
+		}),
+	}
+	results := make([]RelayInfoResult, len(relayURLs))
+	var wg sync.WaitGroup
+	for i, relayURL := range relayURLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info, err := FetchRelayInfo(ctx, client, relayURL)
+			results[i] = RelayInfoResult{URL: relayURL, Info: info, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// AccessRequirements lists what the relay requires before it accepts events
+// from an arbitrary publisher, e.g. "auth required".
+func (info *RelayInfo) AccessRequirements() []string {
+	var reqs []string
+	if l := info.Limitation; l != nil {
+		if l.AuthRequired {
+			reqs = append(reqs, "auth required")
+		}
+		if l.PaymentRequired {
+			reqs = append(reqs, "payment required")
+		}
+		if l.RestrictedWrites {
+			reqs = append(reqs, "restricted writes")
+		}
+		if l.MinPowDifficulty > 0 {
+			reqs = append(reqs, fmt.Sprintf("proof of work %d", l.MinPowDifficulty))
+		}
+	}
+	return reqs
+}
+
+// KindRestrictions reports the kinds the relay advertises it will not store
+// (a retention rule with time or count 0) or charges for publishing.
+func (info *RelayInfo) KindRestrictions(kinds []int) []string {
+	var restrictions []string
+	for _, kind := range kinds {
+		if r := info.retentionRule(kind); r != nil && ((r.Time != nil && *r.Time == 0) || (r.Count != nil && *r.Count == 0)) {
+			restrictions = append(restrictions, fmt.Sprintf("kind %d is not stored (retention policy)", kind))
+		}
+		if info.Fees != nil {
+			for _, fee := range info.Fees.Publication {
+				if len(fee.Kinds) == 0 || slices.Contains(fee.Kinds, kind) {
+					restrictions = append(restrictions, fmt.Sprintf("kind %d costs %d %s to publish", kind, fee.Amount, fee.Unit))
+					break
+				}
+			}
+		}
+	}
+	return restrictions
+}
+
+// retentionRule returns the retention rule for kind: the first rule listing
+// it, else the first rule without kinds. Nil when no rule applies.
+func (info *RelayInfo) retentionRule(kind int) *RelayRetention {
+	var general *RelayRetention
+	for i, r := range info.Retention {
+		if r.Kinds.Contains(kind) {
+			return &info.Retention[i]
+		}
+		if len(r.Kinds) == 0 && general == nil {
+			general = &info.Retention[i]
+		}
+	}
+	return general
+}
+
+// EventProblems reports why the relay would likely reject events: access
+// requirements, kind restrictions, and events over its advertised size,
+// content or tag limits.
+func (info *RelayInfo) EventProblems(events []*nostr.Event) []string {
+	problems := info.AccessRequirements()
+
+	var kinds []int
+	seen := make(map[int]bool)
+	for _, e := range events {
+		if !seen[e.Kind] {
+			seen[e.Kind] = true
+			kinds = append(kinds, e.Kind)
+		}
+	}
+	problems = append(problems, info.KindRestrictions(kinds)...)
+
+	l := info.Limitation
+	if l == nil {
+		return problems
+	}
+	for _, e := range events {
+		if l.MaxMessageLength > 0 {
+			// The relay limits the whole ["EVENT", {...}] message
+			if size := len(e.String()) + len(`["EVENT",]`); size > l.MaxMessageLength {
+				problems = append(problems, fmt.Sprintf("kind %d event is %d bytes, over the %d byte message limit", e.Kind, size, l.MaxMessageLength))
+			}
+		}
+		if l.MaxContentLength > 0 && len(e.Content) > l.MaxContentLength {
+			problems = append(problems, fmt.Sprintf("kind %d content is %d characters, over the %d limit", e.Kind, len(e.Content), l.MaxContentLength))
+		}
+		if l.MaxEventTags > 0 && len(e.Tags) > l.MaxEventTags {
+			problems = append(problems, fmt.Sprintf("kind %d event has %d tags, over the %d limit", e.Kind, len(e.Tags), l.MaxEventTags))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,101 @@
+package nostr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestKindSetUnmarshalMixed(t *testing.T) {
+	var k KindSet
+	if err := json.Unmarshal([]byte(`[0, 1, [5, 7], [30000, 39999]]`), &k); err != nil {
+		t.Fatal(err)
+	}
+	want := KindSet{{0, 0}, {1, 1}, {5, 7}, {30000, 39999}}
+	if !reflect.DeepEqual(k, want) {
+		t.Fatalf("KindSet = %v, want %v", k, want)
+	}
+	if !k.Contains(6) || !k.Contains(KindAppMetadata) || k.Contains(KindSoftwareAsset) {
+		t.Error("Contains gave the wrong answer")
+	}
+	data, err := json.Marshal(k)
+	if err != nil || string(data) != `[0,1,[5,7],[30000,39999]]` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+	if err := json.Unmarshal([]byte(`[[1, 2, 3]]`), &k); err == nil {
+		t.Error("expected error for a three-element range")
+	}
+}
+
+func TestKindRestrictions(t *testing.T) {
+	var info RelayInfo
+	doc := `{
+		"retention": [
+			{"time": 3600},
+			{"kinds": [[30000, 39999]], "count": 0},
+			{"kinds": [3063], "time": null}
+		],
+		"fees": {"publication": [{"kinds": [30063], "amount": 100, "unit": "msats"}]}
+	}`
+	if err := json.Unmarshal([]byte(doc), &info); err != nil {
+		t.Fatal(err)
+	}
+	got := info.KindRestrictions(PublishedKinds)
+	want := []string{
+		"kind 32267 is not stored (retention policy)",
+		"kind 30063 is not stored (retention policy)",
+		"kind 30063 costs 100 msats to publish",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("KindRestrictions = %q, want %q", got, want)
+	}
+}
+
+func TestEventProblems(t *testing.T) {
+	event := &nostr.Event{Kind: KindSoftwareAsset, Content: strings.Repeat("x", 100), Tags: nostr.Tags{{"x", "1"}, {"x", "2"}}}
+	size := len(event.String()) + len(`["EVENT",]`)
+
+	info := RelayInfo{Limitation: &RelayLimitation{MaxMessageLength: size, MaxContentLength: 100, MaxEventTags: 2}}
+	if problems := info.EventProblems([]*nostr.Event{event}); len(problems) != 0 {
+		t.Errorf("events at the limits: problems = %q", problems)
+	}
+
+	info = RelayInfo{Limitation: &RelayLimitation{MaxMessageLength: size - 1, MaxContentLength: 99, MaxEventTags: 1, AuthRequired: true}}
+	problems := info.EventProblems([]*nostr.Event{event})
+	if len(problems) != 4 || problems[0] != "auth required" {
+		t.Errorf("events over the limits: problems = %q", problems)
+	}
+}
+
+func TestFetchRelayInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/nostr+json" {
+			http.Error(w, "not a NIP-11 request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/nostr+json")
+		w.Write([]byte(`{"name": "Test", "supported_nips": [1, 11, "42"], "limitation": {"auth_required": true}}`))
+	}))
+	defer server.Close()
+
+	info, err := FetchRelayInfo(context.Background(), server.Client(), "ws"+strings.TrimPrefix(server.URL, "http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Test" || len(info.SupportedNIPs) != 3 {
+		t.Errorf("info = %+v", info)
+	}
+	if reqs := info.AccessRequirements(); !reflect.DeepEqual(reqs, []string{"auth required"}) {
+		t.Errorf("AccessRequirements = %q", reqs)
+	}
+
+	if _, err := FetchRelayInfo(context.Background(), server.Client(), "https://relay.example.com"); err == nil {
+		t.Error("expected error for a non-websocket URL")
+	}
+}
@@ -0,0 +1,123 @@
+package nostr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// KindRelayList is the NIP-65 relay list metadata event kind.
+const KindRelayList = 10002
+
+// relayListCache holds write relays discovered per pubkey for the lifetime of the process.
+var (
+	relayListMu    sync.Mutex
+	relayListCache = make(map[string][]string)
+)
+
+// DiscoverWriteRelays returns the write relays from pubkey's NIP-65 relay list (kind 10002),
+// fetched from bootstrapRelays (DefaultBootstrapRelays if empty). The newest event across
+// relays wins. When includeDefault is set, DefaultRelay is appended if not already listed.
+// Results are cached for the session. Returns an error if no relay list is found.
+func DiscoverWriteRelays(ctx context.Context, pubkey string, bootstrapRelays []string, includeDefault bool) ([]string, error) {
+	relayListMu.Lock()
+	relays, ok := relayListCache[pubkey]
+	relayListMu.Unlock()
+
+	if !ok {
+		if len(bootstrapRelays) == 0 {
+			bootstrapRelays = DefaultBootstrapRelays
+		}
+		event, err := fetchRelayList(ctx, NewPublisher(bootstrapRelays), pubkey)
+		if err != nil {
+			return nil, err
+		}
+		if event == nil {
+			return nil, fmt.Errorf("no NIP-65 relay list (kind %d) found for pubkey %s on %d bootstrap relays", KindRelayList, shortPubkey(pubkey), len(bootstrapRelays))
+		}
+		relays = parseWriteRelays(event)
+		if len(relays) == 0 {
+			return nil, fmt.Errorf("NIP-65 relay list for pubkey %s has no write relays", shortPubkey(pubkey))
+		}
+
+		relayListMu.Lock()
+		relayListCache[pubkey] = relays
+		relayListMu.Unlock()
+	}
+
+	out := append([]string(nil), relays...)
+	if includeDefault && !containsRelay(out, DefaultRelay) {
+		out = append(out, DefaultRelay)
+	}
+	return out, nil
+}
+
+// fetchRelayList queries bootstrap relays for pubkey's newest kind 10002 event.
+// Returns nil if not found; returns an error only if every relay failed.
+func fetchRelayList(ctx context.Context, bootstrap *Publisher, pubkey string) (*nostr.Event, error) {
+	filter := nostr.Filter{
+		Kinds:   []int{KindRelayList},
+		Authors: []string{pubkey},
+		Limit:   1,
+	}
+
+	var newest *nostr.Event
+	var lastErr error
+	failures := 0
+	for _, url := range bootstrap.relayURLs {
+		event, err := bootstrap.queryRelay(ctx, url, filter)
+		if err != nil {
+			lastErr = err
+			failures++
+			continue
+		}
+		if event != nil && (newest == nil || event.CreatedAt > newest.CreatedAt) {
+			newest = event
+		}
+	}
+
+	if newest == nil && failures == len(bootstrap.relayURLs) && lastErr != nil {
+		return nil, fmt.Errorf("fetching relay list: %w", lastErr)
+	}
+	return newest, nil
+}
+
+// parseWriteRelays extracts write relays from a kind 10002 event's "r" tags.
+// Tags without a marker are both read and write; "read"-marked tags are skipped.
+func parseWriteRelays(event *nostr.Event) []string {
+	var relays []string
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "r" || tag[1] == "" {
+			continue
+		}
+		if len(tag) >= 3 && tag[2] == "read" {
+			continue
+		}
+		url := nostr.NormalizeURL(tag[1])
+		if !containsRelay(relays, url) {
+			relays = append(relays, url)
+		}
+	}
+	return relays
+}
+
+// containsRelay reports whether url is in relays, ignoring normalization differences.
+func containsRelay(relays []string, url string) bool {
+	url = nostr.NormalizeURL(url)
+	for _, r := range relays {
+		if nostr.NormalizeURL(r) == url {
+			return true
+		}
+	}
+	return false
+}
+
+// shortPubkey abbreviates a hex pubkey for error messages.
+func shortPubkey(pubkey string) string {
+	if len(pubkey) > 8 {
+		return pubkey[:8]
+	}
+	return pubkey
+}
@@ -0,0 +1,57 @@
+package nostr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestParseWriteRelays(t *testing.T) {
+	event := &nostr.Event{
+		Kind: KindRelayList,
+		Tags: nostr.Tags{
+			{"r", "wss://both.example.com"},
+			{"r", "wss://write.example.com", "write"},
+			{"r", "wss://read.example.com", "read"},
+			{"r", "wss://both.example.com/"},
+			{"p", "deadbeef"},
+			{"r"},
+		},
+	}
+
+	got := parseWriteRelays(event)
+	want := []string{"wss://both.example.com", "wss://write.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseWriteRelays() = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverWriteRelaysUsesSessionCache(t *testing.T) {
+	pubkey := "cafe000000000000000000000000000000000000000000000000000000000000"
+	relayListMu.Lock()
+	relayListCache[pubkey] = []string{"wss://write.example.com"}
+	relayListMu.Unlock()
+	t.Cleanup(func() {
+		relayListMu.Lock()
+		delete(relayListCache, pubkey)
+		relayListMu.Unlock()
+	})
+
+	got, err := DiscoverWriteRelays(context.Background(), pubkey, nil, true)
+	if err != nil {
+		t.Fatalf("DiscoverWriteRelays() error = %v", err)
+	}
+	if want := []string{"wss://write.example.com", DefaultRelay}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoverWriteRelays(includeDefault) = %v, want %v", got, want)
+	}
+
+	got, err = DiscoverWriteRelays(context.Background(), pubkey, nil, false)
+	if err != nil {
+		t.Fatalf("DiscoverWriteRelays() error = %v", err)
+	}
+	if want := []string{"wss://write.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiscoverWriteRelays() = %v, want %v", got, want)
+	}
+}
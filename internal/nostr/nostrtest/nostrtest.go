@@ -0,0 +1,18 @@
+// Package nostrtest holds Nostr event fixtures shared by tests.
+package nostrtest
+
+import (
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// SignedEvent signs an event of the given kind and tags with sk.
+func SignedEvent(t testing.TB, sk string, kind int, tags nostr.Tags) *nostr.Event {
+	t.Helper()
+	event := &nostr.Event{Kind: kind, Tags: tags, CreatedAt: nostr.Now()}
+	if err := event.Sign(sk); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
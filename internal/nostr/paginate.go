@@ -0,0 +1,95 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+const (
+	// existencePageSize is how many events an existence check asks for per REQ.
+	existencePageSize = 100
+
+	// maxQueryPages bounds the REQs one paginated query sends to a relay.
+	maxQueryPages = 20
+)
+
+// ErrExistenceUnknown is returned by existence checks when a relay kept
+// returning full pages without a match, so absence could not be established.
+var ErrExistenceUnknown = errors.New("the relay capped its results before the history was exhausted")
+
+// ErrHistoryIncomplete is returned, along with the events read, by queries of
+// a publisher's history when a relay could not be read to the end, so the
+// events must not be taken as all there are.
+var ErrHistoryIncomplete = errors.New("a relay capped its results before its history was read to the end")
+
+// queryPages reads the events matching filter from one relay, newest first, a
+// page of filter.Limit events at a time, moving until back to the oldest event
+// seen so far. Relays cap REQ results, often below the limit asked for and
+// without saying so, so paging continues while a page brings new events rather
+// than only while pages are full. It stops early at the first event match
+// accepts (match may be nil).
+//
+// complete is false when the history could not be read to the end: the page
+// budget ran out, or a full page of events sharing one created_at second left
+// no until to move back to.
+func (p *Publisher) queryPages(ctx context.Context, url string, filter nostr.Filter, match func(*nostr.Event) bool) (events []*nostr.Event, found *nostr.Event, complete bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, RelayTimeout)
+	defer cancel()
+
+	relay, err := connectRelay(ctx, url)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer relay.Close()
+
+	seen := make(map[string]bool)
+	for range maxQueryPages {
+		page, err := relay.QuerySync(ctx, filter)
+		if err != nil {
+			return events, nil, false, fmt.Errorf("failed to query: %w", err)
+		}
+
+		fresh := 0
+		var oldest nostr.Timestamp
+		for _, event := range page {
+			if match != nil && match(event) {
+				return events, event, true, nil
+			}
+			if seen[event.ID] {
+				continue
+			}
+			seen[event.ID] = true
+			events = append(events, event)
+			fresh++
+			if oldest == 0 || event.CreatedAt < oldest {
+				oldest = event.CreatedAt
+			}
+		}
+
+		// Without a limit the relay returns what it has in one go
+		if filter.Limit == 0 {
+			return events, nil, true, nil
+		}
+		if fresh == 0 {
+			// A full page of already seen events: more may share their second
+			stuck := len(page) >= filter.Limit && filter.Until != nil && allAt(page, *filter.Until)
+			return events, nil, !stuck, nil
+		}
+		// until is inclusive, so events sharing the oldest second are not skipped
+		filter.Until = &oldest
+	}
+	return events, nil, false, nil
+}
+
+// allAt reports whether every event was created at ts.
+func allAt(events []*nostr.Event, ts nostr.Timestamp) bool {
+	for _, event := range events {
+		if event.CreatedAt != ts {
+			return false
+		}
+	}
+	return true
+}
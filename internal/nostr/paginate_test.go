@@ -0,0 +1,207 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coder/websocket"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// cappedRelay is a mock relay that returns at most capacity events per REQ,
+// newest first, and like most relays ignores multi-letter tag filters unless
+// indexTags is set.
+type cappedRelay struct {
+	URL string
+
+	mu        sync.Mutex
+	reqs      int
+	indexTags bool
+}
+
+func newCappedRelay(t *testing.T, capacity int, events []*nostr.Event) *cappedRelay {
+	t.Helper()
+	sorted := slices.Clone(events)
+	slices.SortStableFunc(sorted, func(a, b *nostr.Event) int { return int(b.CreatedAt - a.CreatedAt) })
+
+	cr := &cappedRelay{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := r.Context()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			req, ok := nostr.ParseMessage(string(data)).(*nostr.ReqEnvelope)
+			if !ok {
+				continue
+			}
+			cr.mu.Lock()
+			cr.reqs++
+			indexTags := cr.indexTags
+			cr.mu.Unlock()
+
+			for _, filter := range req.Filters {
+				for key := range filter.Tags {
+					if len(key) > 1 && !indexTags {
+						delete(filter.Tags, key)
+					}
+				}
+				limit := capacity
+				if filter.Limit > 0 && filter.Limit < limit {
+					limit = filter.Limit
+				}
+				sent := 0
+				for _, event := range sorted {
+					if sent == limit {
+						break
+					}
+					if !filter.Matches(event) {
+						continue
+					}
+					msg, _ := nostr.EventEnvelope{SubscriptionID: &req.SubscriptionID, Event: *event}.MarshalJSON()
+					conn.Write(ctx, websocket.MessageText, msg)
+					sent++
+				}
+			}
+			eose, _ := nostr.EOSEEnvelope(req.SubscriptionID).MarshalJSON()
+			conn.Write(ctx, websocket.MessageText, eose)
+		}
+	}))
+	t.Cleanup(server.Close)
+	cr.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	return cr
+}
+
+// assetHistory returns n assets of com.example.app, version 1.0.<i> created at
+// base+i, or all at base when sameSecond is set.
+func assetHistory(t *testing.T, sk string, n int, base nostr.Timestamp, sameSecond bool) []*nostr.Event {
+	t.Helper()
+	events := make([]*nostr.Event, n)
+	for i := range events {
+		createdAt := base + nostr.Timestamp(i)
+		if sameSecond {
+			createdAt = base
+		}
+		events[i] = &nostr.Event{
+			Kind:      KindSoftwareAsset,
+			CreatedAt: createdAt,
+			Tags:      nostr.Tags{{"i", "com.example.app"}, {"version", "1.0." + strconv.Itoa(i)}},
+		}
+		if err := events[i].Sign(sk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return events
+}
+
+func TestCheckExistingAssetPaginates(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	history := assetHistory(t, sk, 250, 1700000000, false)
+
+	// The oldest asset is beyond two pages of a relay capped at 100 results
+	relay := newCappedRelay(t, 100, history)
+	publisher := NewPublisher([]string{relay.URL})
+	existing, err := publisher.CheckExistingAsset(context.Background(), pubkey, "com.example.app", "1.0.0")
+	if err != nil {
+		t.Fatalf("CheckExistingAsset() error: %v", err)
+	}
+	if existing == nil || existing.Event.ID != history[0].ID {
+		t.Fatalf("CheckExistingAsset() = %v, want the oldest asset", existing)
+	}
+
+	existing, err = publisher.CheckExistingAsset(context.Background(), pubkey, "com.example.app", "2.0.0")
+	if err != nil || existing != nil {
+		t.Fatalf("CheckExistingAsset() = %v, %v; want nil, nil once the history is exhausted", existing, err)
+	}
+}
+
+func TestCheckExistingAssetCappedIsUnknown(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+
+	// A full page of one second gives no until to page back from
+	relay := newCappedRelay(t, 100, assetHistory(t, sk, 150, 1700000000, true))
+	publisher := NewPublisher([]string{relay.URL})
+	existing, err := publisher.CheckExistingAsset(context.Background(), pubkey, "com.example.app", "2.0.0")
+	if existing != nil || !errors.Is(err, ErrExistenceUnknown) {
+		t.Fatalf("CheckExistingAsset() = %v, %v; want ErrExistenceUnknown", existing, err)
+	}
+}
+
+func TestCheckExistingAssetTrustsVersionIndex(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+
+	// A relay that honors #version and returns nothing has no such asset
+	relay := newCappedRelay(t, 100, assetHistory(t, sk, 250, 1700000000, false))
+	relay.indexTags = true
+	publisher := NewPublisher([]string{relay.URL})
+	existing, err := publisher.CheckExistingAsset(context.Background(), pubkey, "com.example.app", "2.0.0")
+	if err != nil || existing != nil {
+		t.Fatalf("CheckExistingAsset() = %v, %v; want nil, nil", existing, err)
+	}
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	if relay.reqs > 3 {
+		t.Errorf("relay received %d REQs, want the #version query and the index probe only", relay.reqs)
+	}
+}
+
+func TestQueryAllReportsIncompleteHistory(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+
+	// A full page of one second gives no until to page back from
+	relay := newCappedRelay(t, 100, assetHistory(t, sk, 150, 1700000000, true))
+	events, err := NewPublisher([]string{relay.URL}).queryAll(context.Background(), nostr.Filter{
+		Kinds:   []int{KindSoftwareAsset},
+		Authors: []string{pubkey},
+		Limit:   100,
+	})
+	if !errors.Is(err, ErrHistoryIncomplete) {
+		t.Fatalf("queryAll() error = %v, want ErrHistoryIncomplete", err)
+	}
+	if len(events) != 100 {
+		t.Errorf("queryAll() returned %d events, want the 100 read", len(events))
+	}
+}
+
+func TestQueryAllPaginates(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+
+	// The relay caps results below the limit asked for
+	relay := newCappedRelay(t, 40, assetHistory(t, sk, 90, 1700000000, false))
+	publisher := NewPublisher([]string{relay.URL})
+	events, err := publisher.queryAll(context.Background(), nostr.Filter{
+		Kinds:   []int{KindSoftwareAsset},
+		Authors: []string{pubkey},
+		Limit:   500,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 90 {
+		t.Errorf("queryAll() returned %d events, want all 90", len(events))
+	}
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	if relay.reqs > maxQueryPages {
+		t.Errorf("relay received %d REQs, want at most %d", relay.reqs, maxQueryPages)
+	}
+}
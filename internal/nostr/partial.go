@@ -0,0 +1,75 @@
+package nostr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// PartialPublish describes a relay that rejected a required event of a
+// release: the app event (unless --skip-app-event left it out), the release,
+// or every asset. Without them the relay shows a half-published app.
+type PartialPublish struct {
+	RelayURL string
+	Rejected []string // Required PublishEventSet result keys the relay rejected
+	Accepted []string // Keys the relay newly stored; duplicates it already had are left out
+}
+
+// FindPartialPublish checks relayURL's outcomes in PublishEventSet results and
+// returns nil when it accepted every required event it was sent. Release notes
+// are not required: the release still carries an excerpt.
+func FindPartialPublish(results map[string][]PublishResult, relayURL string) *PartialPublish {
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return eventKeyRank(keys[i]) < eventKeyRank(keys[j]) })
+
+	partial := &PartialPublish{RelayURL: relayURL}
+	var failedAssets []string
+	assetSent, assetAccepted := false, false
+	for _, key := range keys {
+		for _, r := range results[key] {
+			if r.RelayURL != relayURL {
+				continue
+			}
+			isAsset := strings.HasPrefix(key, "software_asset")
+			if isAsset {
+				assetSent = true
+				assetAccepted = assetAccepted || r.Success
+			}
+			switch {
+			case r.Success && !r.IsDuplicate:
+				partial.Accepted = append(partial.Accepted, key)
+			case r.Success:
+			case isAsset:
+				failedAssets = append(failedAssets, key)
+			case key == "software_application" || key == "software_release":
+				partial.Rejected = append(partial.Rejected, key)
+			}
+		}
+	}
+	if assetSent && !assetAccepted {
+		partial.Rejected = append(partial.Rejected, failedAssets...)
+	}
+	if len(partial.Rejected) == 0 {
+		return nil
+	}
+	return partial
+}
+
+// Retract asks relayURL, and only it, to delete events with a NIP-09 deletion
+// request signed by signer.
+func (p *Publisher) Retract(ctx context.Context, signer Signer, relayURL string, events []*nostr.Event, reason string) error {
+	deletion := BuildDeletionEvent(events, signer.PublicKey(), reason)
+	if err := signer.Sign(ctx, deletion); err != nil {
+		return fmt.Errorf("failed to sign deletion request: %w", err)
+	}
+	if r := p.publishToRelay(ctx, relayURL, deletion); !r.Success {
+		return r.Error
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package nostr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/nostr/nostrtest"
+)
+
+func TestFindPartialPublish(t *testing.T) {
+	const relay, other = "wss://relay.zapstore.dev", "wss://other.example"
+	ok := PublishResult{RelayURL: relay, Success: true}
+	dup := PublishResult{RelayURL: relay, Success: true, IsDuplicate: true}
+	failed := PublishResult{RelayURL: relay}
+
+	tests := []struct {
+		name    string
+		results map[string][]PublishResult
+		want    *PartialPublish
+	}{
+		{
+			name: "all accepted",
+			results: map[string][]PublishResult{
+				"software_application": {ok},
+				"software_release":     {ok},
+				"software_asset":       {ok},
+			},
+		},
+		{
+			name: "app rejected",
+			results: map[string][]PublishResult{
+				"software_application": {failed, {RelayURL: other, Success: true}},
+				"software_release":     {ok},
+				"software_asset":       {dup},
+			},
+			want: &PartialPublish{RelayURL: relay, Rejected: []string{"software_application"}, Accepted: []string{"software_release"}},
+		},
+		{
+			name: "one of two assets rejected",
+			results: map[string][]PublishResult{
+				"software_release": {ok},
+				"software_asset_1": {failed},
+				"software_asset_2": {ok},
+			},
+		},
+		{
+			name: "every asset rejected",
+			results: map[string][]PublishResult{
+				"release_notes":    {ok},
+				"software_release": {ok},
+				"software_asset_1": {failed},
+				"software_asset_2": {failed},
+			},
+			want: &PartialPublish{RelayURL: relay, Rejected: []string{"software_asset_1", "software_asset_2"}, Accepted: []string{"release_notes", "software_release"}},
+		},
+		{
+			name: "release notes rejected",
+			results: map[string][]PublishResult{
+				"release_notes":    {failed},
+				"software_release": {ok},
+				"software_asset":   {ok},
+			},
+		},
+		{
+			name: "not sent to the relay",
+			results: map[string][]PublishResult{
+				"software_application": {{RelayURL: other}},
+				"software_release":     {ok},
+				"software_asset":       {ok},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindPartialPublish(tt.results, relay); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindPartialPublish() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetractPartialPublish(t *testing.T) {
+	relay := newPublishRelay(t, 100, 0, map[int]string{KindAppMetadata: "invalid: unsupported platform"})
+	sk := nostr.GeneratePrivateKey()
+	events := &EventSet{
+		AppMetadata: nostrtest.SignedEvent(t, sk, KindAppMetadata, nostr.Tags{{"d", "com.example.app"}}),
+		Release:     nostrtest.SignedEvent(t, sk, KindRelease, nostr.Tags{{"d", "com.example.app@1.0.0"}}),
+		SoftwareAssets: []*nostr.Event{
+			nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", "com.example.app"}}),
+		},
+	}
+
+	publisher := NewPublisher([]string{relay.URL})
+	results, err := publisher.PublishEventSet(context.Background(), events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partial := FindPartialPublish(results, relay.URL)
+	if partial == nil || !reflect.DeepEqual(partial.Rejected, []string{"software_application"}) ||
+		!reflect.DeepEqual(partial.Accepted, []string{"software_release", "software_asset"}) {
+		t.Fatalf("FindPartialPublish() = %+v", partial)
+	}
+
+	nsec, _ := nip19.EncodePrivateKey(sk)
+	signer, err := NewNsecSigner(nsec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retracted := []*nostr.Event{events.EventByKey("software_release"), events.EventByKey("software_asset")}
+	if err := publisher.Retract(context.Background(), signer, relay.URL, retracted, "rolled back"); err != nil {
+		t.Fatalf("Retract() error: %v", err)
+	}
+
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	if relay.total != 4 {
+		t.Errorf("relay received %d events, want the 3 published and a deletion request", relay.total)
+	}
+
+	deletion := BuildDeletionEvent(retracted, signer.PublicKey(), "rolled back")
+	want := nostr.Tags{
+		{"e", events.Release.ID}, {"e", events.SoftwareAssets[0].ID},
+		{"k", "30063"}, {"k", "3063"},
+	}
+	if deletion.Kind != KindDeletion || !reflect.DeepEqual(deletion.Tags[:4], want) {
+		t.Errorf("deletion = kind %d tags %v, want kind 5 tags %v", deletion.Kind, deletion.Tags, want)
+	}
+	if deletion.CreatedAt < events.Release.CreatedAt {
+		t.Errorf("deletion created_at %d is before the release's %d", deletion.CreatedAt, events.Release.CreatedAt)
+	}
+}
@@ -8,11 +8,13 @@ import (
 	"html"
 	"net"
 	"net/http"
+	"os"
 	"os/exec"
 	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -25,13 +27,16 @@ const DefaultPreviewPort = 17008
 
 // AssetPreviewData contains data for a single software asset.
 type AssetPreviewData struct {
-	SHA256          string
-	FileSize        int64
-	Filename        string
-	CertFingerprint string
-	MinSDK          int32
-	TargetSDK       int32
-	Platforms       []string // Platform identifiers for this specific asset
+	SHA256           string
+	FileSize         int64
+	Filename         string
+	CertFingerprint  string
+	SignatureSchemes []string
+	MinSDK           int32
+	TargetSDK        int32
+	MaxSDK           int32
+	Platforms        []string // Platform identifiers for this specific asset
+	DeviceClasses    []string // Device classes the APK is restricted to (nil for any device)
 }
 
 // PreviewImageData holds pre-downloaded image data for local serving.
@@ -55,6 +60,7 @@ type PreviewData struct {
 	IconURL     string             // URL if using remote icon
 	ImageURLs   []string           // Screenshot URLs (remote or will be replaced with local)
 	ImageData   []PreviewImageData // Pre-downloaded screenshot data (served locally)
+	NoImages    bool               // Show placeholders instead of screenshots (nothing is fetched)
 	Platforms   []string           // All platforms (union of all assets)
 
 	// Software Release
@@ -62,6 +68,9 @@ type PreviewData struct {
 	VersionCode int64
 	Channel     string
 	Changelog   string
+	// ReleaseNotesExcerpt is the release content with release_notes_event. When
+	// set, the release shows it with a link to the full notes at /release-notes.
+	ReleaseNotesExcerpt string
 
 	// Software Assets (multiple)
 	Assets []AssetPreviewData
@@ -122,13 +131,16 @@ func BuildPreviewDataFromAPKs(apkInfos []*apk.APKInfo, cfg *config.Config, chang
 		}
 
 		assets = append(assets, AssetPreviewData{
-			SHA256:          apkInfo.SHA256,
-			FileSize:        apkInfo.FileSize,
-			Filename:        apkInfo.FilePath,
-			CertFingerprint: apkInfo.CertFingerprint,
-			MinSDK:          apkInfo.MinSDK,
-			TargetSDK:       apkInfo.TargetSDK,
-			Platforms:       assetPlatforms,
+			SHA256:           apkInfo.SHA256,
+			FileSize:         apkInfo.FileSize,
+			Filename:         apkInfo.FilePath,
+			CertFingerprint:  apkInfo.CertFingerprint,
+			SignatureSchemes: apkInfo.SignatureSchemes,
+			MinSDK:           apkInfo.MinSDK,
+			TargetSDK:        apkInfo.TargetSDK,
+			MaxSDK:           apkInfo.MaxSDK,
+			Platforms:        assetPlatforms,
+			DeviceClasses:    apkInfo.DeviceClasses(),
 		})
 	}
 
@@ -138,25 +150,31 @@ func BuildPreviewDataFromAPKs(apkInfos []*apk.APKInfo, cfg *config.Config, chang
 		platforms = append(platforms, p)
 	}
 
+	var excerpt string
+	if cfg.ReleaseNotesEvent && strings.TrimSpace(changelog) != "" {
+		excerpt = ReleaseNotesExcerpt(changelog, cfg.ReleaseNotesExcerpt)
+	}
+
 	return &PreviewData{
-		AppName:       name,
-		PackageID:     firstAPK.PackageID,
-		Summary:       cfg.Summary,
-		Description:   cfg.Description,
-		Website:       cfg.Website,
-		Repository:    cfg.Repository,
-		License:       cfg.License,
-		Tags:          cfg.Tags,
-		IconData:      firstAPK.Icon,
-		ImageURLs:     cfg.Images,
-		Platforms:     platforms,
-		Version:       firstAPK.VersionName,
-		VersionCode:   firstAPK.VersionCode,
-		Channel:       "main",
-		Changelog:     changelog,
-		Assets:        assets,
-		BlossomServer: blossomURL,
-		RelayURLs:     relayURLs,
+		AppName:             name,
+		PackageID:           firstAPK.PackageID,
+		Summary:             cfg.Summary,
+		Description:         cfg.Description,
+		Website:             cfg.Website,
+		Repository:          cfg.Repository,
+		License:             cfg.License,
+		Tags:                cfg.Tags,
+		IconData:            firstAPK.Icon,
+		ImageURLs:           cfg.Images,
+		Platforms:           platforms,
+		Version:             firstAPK.VersionName,
+		VersionCode:         firstAPK.VersionCode,
+		Channel:             "main",
+		Changelog:           changelog,
+		Assets:              assets,
+		ReleaseNotesExcerpt: excerpt,
+		BlossomServer:       blossomURL,
+		RelayURLs:           relayURLs,
 	}
 }
 
@@ -187,6 +205,7 @@ func BuildPreviewDataFromEvents(apkInfos []*apk.APKInfo, cfg *config.Config, eve
 // PreviewServer serves the HTML preview.
 type PreviewServer struct {
 	port        int
+	fixedPort   bool // port was chosen by the user and must not be swapped for a free one
 	server      *http.Server
 	listener    net.Listener
 	data        *PreviewData
@@ -195,11 +214,15 @@ type PreviewServer struct {
 	changelog   string
 	iconURL     string
 	iconDataB64 string
+	noBrowser   bool // print the URL for the user to open instead of opening a browser
+	closeOnce   sync.Once
+	closeErr    error
 }
 
 // NewPreviewServer creates a preview server on the specified port.
-// If port is 0, it uses the default port.
+// If port is 0, it uses the default port, or the next free one when that is taken.
 func NewPreviewServer(data *PreviewData, changelog, iconURL string, port int) *PreviewServer {
+	fixedPort := port != 0
 	if port == 0 {
 		port = DefaultPreviewPort
 	}
@@ -212,6 +235,7 @@ func NewPreviewServer(data *PreviewData, changelog, iconURL string, port int) *P
 
 	return &PreviewServer{
 		port:        port,
+		fixedPort:   fixedPort,
 		data:        data,
 		done:        make(chan struct{}),
 		cliConfirm:  make(chan struct{}),
@@ -221,29 +245,45 @@ func NewPreviewServer(data *PreviewData, changelog, iconURL string, port int) *P
 	}
 }
 
-// Start starts the preview server and opens the browser.
+// SetNoBrowser makes Start leave opening the URL to the user, e.g. through an
+// SSH tunnel from a machine without a display.
+func (s *PreviewServer) SetNoBrowser(noBrowser bool) {
+	s.noBrowser = noBrowser
+}
+
+// Start starts the preview server and opens the browser, unless SetNoBrowser was called.
+// If the default port is taken, the next free port in the scan range is used;
+// the returned URL carries the port actually bound. A port passed to
+// NewPreviewServer is used as is and fails when busy.
 func (s *PreviewServer) Start() (string, error) {
-	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.port))
+	listener, port, err := listenLocal(s.port, s.fixedPort)
 	if err != nil {
 		return "", fmt.Errorf("failed to start preview server: %w", err)
 	}
 	s.listener = listener
+	s.port = port
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.handleIndex)
 	mux.HandleFunc("/api/events", s.handleEvents)
 	mux.HandleFunc("/api/poll", s.handlePoll)
 	mux.HandleFunc("/images/", s.handleImage) // Serve pre-downloaded images
+	mux.HandleFunc("/release-notes", s.handleReleaseNotes)
+	mux.HandleFunc("/api/takeover", takeoverHandler(func() { s.Close() }))
 
 	s.server = &http.Server{Handler: mux}
 	go s.server.Serve(listener)
 
 	url := fmt.Sprintf("http://localhost:%d/", s.port)
 
+	if s.noBrowser {
+		return url, nil
+	}
+
 	// Open browser
 	if err := openBrowser(url); err != nil {
 		// Non-fatal: user can manually open the URL
-		fmt.Printf("Could not open browser automatically. Please open: %s\n", url)
+		fmt.Fprintf(os.Stderr, "Could not open browser automatically. Please open: %s\n", url)
 	}
 
 	return url, nil
@@ -269,15 +309,22 @@ func (s *PreviewServer) handleImage(w http.ResponseWriter, r *http.Request) {
 	w.Write(img.Data)
 }
 
-// Close shuts down the preview server.
+// Port returns the port the server is bound to (the requested port until Start).
+func (s *PreviewServer) Port() int {
+	return s.port
+}
+
+// Close shuts down the preview server. It is safe to call more than once.
 func (s *PreviewServer) Close() error {
-	close(s.done)
-	if s.server != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		return s.server.Shutdown(ctx)
-	}
-	return nil
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if s.server != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			s.closeErr = s.server.Shutdown(ctx)
+		}
+	})
+	return s.closeErr
 }
 
 func (s *PreviewServer) handleIndex(w http.ResponseWriter, r *http.Request) {
@@ -285,6 +332,23 @@ func (s *PreviewServer) handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(s.buildHTML()))
 }
 
+// handleReleaseNotes renders the full release notes, as published in the
+// long-form event with release_notes_event.
+func (s *PreviewServer) handleReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	if s.changelog == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	fmt.Fprintf(w, releaseNotesHTML,
+		html.EscapeString(s.data.AppName),
+		html.EscapeString(s.data.Version),
+		html.EscapeString(s.data.AppName),
+		html.EscapeString(s.data.Version),
+		simpleMarkdownToHTML(s.changelog),
+	)
+}
+
 func (s *PreviewServer) handleEvents(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	// Events may be nil if preview is shown before signing
@@ -344,7 +408,14 @@ func (s *PreviewServer) buildHTML() string {
 
 	// Screenshots HTML - use local URLs for pre-downloaded images
 	screenshotsHTML := ""
-	if len(d.ImageData) > 0 {
+	if d.NoImages && len(d.ImageURLs) > 0 {
+		// Placeholders only, so the browser fetches nothing
+		var placeholders []string
+		for i := range d.ImageURLs {
+			placeholders = append(placeholders, fmt.Sprintf(`<div class="screenshot-placeholder">Screenshot %d</div>`, i+1))
+		}
+		screenshotsHTML = fmt.Sprintf(`<div class="screenshots">%s</div>`, strings.Join(placeholders, ""))
+	} else if len(d.ImageData) > 0 {
 		// Use locally served pre-downloaded images
 		var imgs []string
 		for i := range d.ImageData {
@@ -387,18 +458,28 @@ func (s *PreviewServer) buildHTML() string {
           <div class="label">Target SDK</div>
           <div class="value">%s</div>
         </div>
+        <div class="asset-item" style="grid-column: 1 / -1;">
+          <div class="label">Devices</div>
+          <div class="value">%s</div>
+        </div>
         <div class="asset-item" style="grid-column: 1 / -1;">
           <div class="label">APK Certificate Hash</div>
           <div class="value">%s</div>
         </div>
+        <div class="asset-item" style="grid-column: 1 / -1;">
+          <div class="label">Signature Schemes</div>
+          <div class="value">%s</div>
+        </div>
       </div>
     </div>`,
 			assetNum,
 			html.EscapeString(asset.SHA256),
 			formatBytes(asset.FileSize),
-			strconv.Itoa(int(asset.MinSDK)),
+			formatSDKRange(asset.MinSDK, asset.MaxSDK),
 			strconv.Itoa(int(asset.TargetSDK)),
+			html.EscapeString(formatDeviceClasses(asset.DeviceClasses)),
 			html.EscapeString(asset.CertFingerprint),
+			html.EscapeString(formatSignatureSchemes(asset.SignatureSchemes)),
 		)
 	}
 
@@ -416,6 +497,11 @@ func (s *PreviewServer) buildHTML() string {
 	releaseSectionHTML := ""
 	if s.changelog != "" {
 		changelogHTML := simpleMarkdownToHTML(s.changelog)
+		// With release_notes_event the release only carries the excerpt
+		if d.ReleaseNotesExcerpt != "" {
+			changelogHTML = simpleMarkdownToHTML(d.ReleaseNotesExcerpt) +
+				`<p class="full-notes"><a href="/release-notes" target="_blank">Full release notes (kind 30023) &rarr;</a></p>`
+		}
 		releaseSectionHTML = fmt.Sprintf(`
     <div class="section">
       <h2>Release</h2>
@@ -592,6 +678,34 @@ func buildInfoRow(label, value string, isLink bool) string {
 		label, html.EscapeString(value))
 }
 
+// formatSignatureSchemes renders the scheme list, flagging APKs without a v2/v3 signature.
+func formatSignatureSchemes(schemes []string) string {
+	if len(schemes) == 0 {
+		return "unknown"
+	}
+	list := strings.Join(schemes, ", ")
+	if len(schemes) == 1 && schemes[0] == "v1" {
+		list += " (v1 only: may not install on Android 11+)"
+	}
+	return list
+}
+
+// formatSDKRange formats the minimum SDK, with maxSdkVersion when the APK sets one.
+func formatSDKRange(minSDK, maxSDK int32) string {
+	if maxSDK > 0 {
+		return fmt.Sprintf("%d (max %d)", minSDK, maxSDK)
+	}
+	return strconv.Itoa(int(minSDK))
+}
+
+// formatDeviceClasses describes the devices an asset is published for.
+func formatDeviceClasses(classes []string) string {
+	if len(classes) == 0 {
+		return "any (phones, tablets and other devices)"
+	}
+	return strings.Join(classes, ", ") + " only"
+}
+
 func formatBytes(bytes int64) string {
 	if bytes < 1024 {
 		return fmt.Sprintf("%d B", bytes)
@@ -617,6 +731,38 @@ func openBrowser(url string) error {
 	return cmd.Start()
 }
 
+// releaseNotesHTML is the page of the full release notes: title (app name,
+// version), heading (app name, version) and the notes as HTML.
+const releaseNotesHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>%s %s - Release Notes</title>
+  <style>
+    body {
+      font-family: 'SF Pro Text', -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+      background: #1a1a1e;
+      color: #c8c8d0;
+      line-height: 1.6;
+      max-width: 760px;
+      margin: 0 auto;
+      padding: 32px 24px;
+    }
+    h1 { color: #e0e0e4; margin-bottom: 16px; }
+    h2, h3, h4 { color: #9080a0; margin: 16px 0 8px 0; }
+    li { margin-left: 20px; margin-bottom: 4px; }
+    img { max-width: 100%%; }
+    a { color: #9080a0; }
+  </style>
+</head>
+<body>
+  <h1>%s %s</h1>
+  %s
+</body>
+</html>
+`
+
 const previewHTML = `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -818,6 +964,19 @@ const previewHTML = `<!DOCTYPE html>
       flex-shrink: 0;
     }
     
+    .screenshot-placeholder {
+      height: 400px;
+      width: 225px;
+      display: flex;
+      align-items: center;
+      justify-content: center;
+      border: 1px dashed #4a3a5c;
+      border-radius: 6px;
+      color: #8a8a94;
+      font-size: 0.8rem;
+      flex-shrink: 0;
+    }
+    
     .version-badge {
       display: inline-flex;
       align-items: center;
@@ -868,6 +1027,17 @@ const previewHTML = `<!DOCTYPE html>
       margin-left: 20px;
       margin-bottom: 4px;
     }
+    .changelog .full-notes {
+      margin-top: 12px;
+    }
+    .changelog .full-notes a {
+      color: #9080a0;
+      text-decoration: none;
+    }
+    .changelog .full-notes a:hover {
+      text-decoration: underline;
+      color: #a898b8;
+    }
     
     .asset-grid {
       display: grid;
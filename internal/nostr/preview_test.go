@@ -28,6 +28,7 @@ func TestPreviewServerServesLocalScreenshots(t *testing.T) {
 	}
 
 	server := NewPreviewServer(previewData, "", "", 17018)
+	server.SetNoBrowser(true)
 
 	url, err := server.Start()
 	if err != nil {
@@ -92,6 +93,7 @@ func TestPreviewServerNoScreenshots(t *testing.T) {
 	}
 
 	server := NewPreviewServer(previewData, "", "", 17019)
+	server.SetNoBrowser(true)
 	url, err := server.Start()
 	if err != nil {
 		t.Fatalf("failed to start preview server: %v", err)
@@ -109,3 +111,20 @@ func TestPreviewServerNoScreenshots(t *testing.T) {
 		t.Errorf("expected status 404, got %d", resp.StatusCode)
 	}
 }
+
+func TestPreviewHTMLImagePlaceholders(t *testing.T) {
+	previewData := &PreviewData{
+		AppName:   "Test App",
+		PackageID: "com.example.test",
+		ImageURLs: []string{"https://example.com/1.png", "https://example.com/2.png"},
+		NoImages:  true,
+	}
+
+	page := NewPreviewServer(previewData, "", "", 0).buildHTML()
+	if strings.Contains(page, "example.com") {
+		t.Error("placeholder preview should not reference remote screenshots")
+	}
+	if got := strings.Count(page, `class="screenshot-placeholder"`); got != 2 {
+		t.Errorf("got %d placeholders, want 2", got)
+	}
+}
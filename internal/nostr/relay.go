@@ -2,11 +2,17 @@ package nostr
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/netpolicy"
 )
 
 // KindProfile is the kind for profile metadata events (NIP-01).
@@ -16,6 +22,9 @@ const (
 	// DefaultRelay is the default relay URL.
 	DefaultRelay = "wss://relay.zapstore.dev"
 
+	// DevRelay is the local relay `zsp publish --dev` publishes to.
+	DevRelay = "ws://localhost:10547"
+
 	// RelayTimeout is the timeout for relay operations.
 	RelayTimeout = 30 * time.Second
 )
@@ -23,6 +32,14 @@ const (
 // Publisher handles publishing events to relays.
 type Publisher struct {
 	relayURLs []string
+	routes    []RelayRoute
+}
+
+// RelayRoute sends events of the listed kinds to extra relays.
+type RelayRoute struct {
+	Kinds   []int
+	Relays  []string
+	Replace bool // Publish these kinds only to Relays, not to the default relays
 }
 
 // NewPublisher creates a new publisher.
@@ -51,19 +68,76 @@ func NewPublisherFromEnv(relaysEnv string) *Publisher {
 	return NewPublisher(cleaned)
 }
 
+// ErrConnectionDropped is wrapped by a PublishResult error when the relay
+// closed the connection before answering the event, even after reconnecting.
+var ErrConnectionDropped = errors.New("connection closed before the relay acknowledged the event")
+
 // PublishResult contains the result of publishing to a single relay.
 type PublishResult struct {
 	RelayURL    string
 	Success     bool
 	IsDuplicate bool
 	Error       error
+	Message     string // The relay's OK message, or its last NOTICE when the connection dropped
+}
+
+// SetRoutes sets the per-kind relay routes used when publishing.
+func (p *Publisher) SetRoutes(routes []RelayRoute) {
+	p.routes = routes
+}
+
+// HasRoutes reports whether any per-kind relay routes are set.
+func (p *Publisher) HasRoutes() bool {
+	return len(p.routes) > 0
+}
+
+// RelaysForKind returns the relays events of kind are published to: the
+// configured relays plus those of every route listing the kind, or only the
+// routes' relays when one of them replaces the defaults.
+func (p *Publisher) RelaysForKind(kind int) []string {
+	var routed []string
+	replace := false
+	for _, route := range p.routes {
+		if slices.Contains(route.Kinds, kind) {
+			routed = append(routed, route.Relays...)
+			replace = replace || route.Replace
+		}
+	}
+	if replace {
+		return dedupeRelays(routed)
+	}
+	return dedupeRelays(append(slices.Clone(p.relayURLs), routed...))
+}
+
+// AllRelayURLs returns every relay any event may be published to: the
+// configured relays followed by the relays added by routes.
+func (p *Publisher) AllRelayURLs() []string {
+	all := slices.Clone(p.relayURLs)
+	for _, route := range p.routes {
+		all = append(all, route.Relays...)
+	}
+	return dedupeRelays(all)
+}
+
+// dedupeRelays drops repeated relay URLs, keeping the first occurrence.
+func dedupeRelays(urls []string) []string {
+	seen := make(map[string]bool, len(urls))
+	out := urls[:0]
+	for _, url := range urls {
+		if !seen[url] {
+			seen[url] = true
+			out = append(out, url)
+		}
+	}
+	return out
 }
 
-// Publish publishes an event to all configured relays.
+// Publish publishes an event to the relays its kind is routed to.
 func (p *Publisher) Publish(ctx context.Context, event *nostr.Event) []PublishResult {
-	results := make([]PublishResult, len(p.relayURLs))
+	relays := p.RelaysForKind(event.Kind)
+	results := make([]PublishResult, len(relays))
 
-	for i, url := range p.relayURLs {
+	for i, url := range relays {
 		results[i] = p.publishToRelay(ctx, url, event)
 	}
 
@@ -81,66 +155,326 @@ func isDuplicateError(err error) bool {
 
 // publishToRelay publishes an event to a single relay.
 func (p *Publisher) publishToRelay(ctx context.Context, url string, event *nostr.Event) PublishResult {
-	result := PublishResult{RelayURL: url}
+	return p.publishSetToRelay(ctx, url, []*nostr.Event{event})[0]
+}
 
-	ctx, cancel := context.WithTimeout(ctx, RelayTimeout)
-	defer cancel()
+// PublishEventSet publishes all events in an event set, over one connection per relay.
+// Each relay receives only the events whose kind is routed to it.
+// AppMetadata may be nil when --skip-app-event is used.
+// Results are keyed by event type, with one entry per routed relay in relay order.
+func (p *Publisher) PublishEventSet(ctx context.Context, events *EventSet) (map[string][]PublishResult, error) {
+	keys, set := events.keyed()
+	results := make(map[string][]PublishResult, len(keys))
+	for _, url := range p.AllRelayURLs() {
+		var routedKeys []string
+		var routed []*nostr.Event
+		for i, event := range set {
+			if slices.Contains(p.RelaysForKind(event.Kind), url) {
+				routedKeys = append(routedKeys, keys[i])
+				routed = append(routed, event)
+			}
+		}
+		if len(routed) == 0 {
+			continue
+		}
+		for i, r := range p.publishSetToRelay(ctx, url, routed) {
+			results[routedKeys[i]] = append(results[routedKeys[i]], r)
+		}
+	}
+	return results, nil
+}
 
-	relay, err := nostr.RelayConnect(ctx, url)
-	if err != nil {
-		result.Error = fmt.Errorf("failed to connect: %w", err)
-		return result
+// keyed returns the events in publish order with their PublishEventSet result keys.
+func (es *EventSet) keyed() ([]string, []*nostr.Event) {
+	var keys []string
+	var set []*nostr.Event
+
+	// Software Application (skipped when --skip-app-event is used)
+	if es.AppMetadata != nil {
+		keys = append(keys, "software_application")
+		set = append(set, es.AppMetadata)
 	}
-	defer relay.Close()
 
-	err = relay.Publish(ctx, *event)
-	if err != nil {
-		// Check if this is a duplicate error (event already exists)
-		if isDuplicateError(err) {
-			result.Success = true
-			result.IsDuplicate = true
-			result.Error = err // Keep error for informational purposes
-			return result
+	// Long-form release notes, ahead of the release that references them
+	if es.ReleaseNotes != nil {
+		keys = append(keys, "release_notes")
+		set = append(set, es.ReleaseNotes)
+	}
+
+	// Software Release
+	keys = append(keys, "software_release")
+	set = append(set, es.Release)
+
+	// All Software Assets
+	for i, asset := range es.SoftwareAssets {
+		key := "software_asset"
+		if len(es.SoftwareAssets) > 1 {
+			key = fmt.Sprintf("software_asset_%d", i+1)
 		}
-		result.Error = fmt.Errorf("failed to publish: %w", err)
-		return result
+		keys = append(keys, key)
+		set = append(set, asset)
 	}
+	return keys, set
+}
 
-	result.Success = true
-	return result
+// EventByKey returns the event published under a PublishEventSet result key,
+// or nil if there is none.
+func (es *EventSet) EventByKey(key string) *nostr.Event {
+	keys, set := es.keyed()
+	if i := slices.Index(keys, key); i >= 0 {
+		return set[i]
+	}
+	return nil
 }
 
-// PublishEventSet publishes all events in an event set.
-// AppMetadata may be nil when --skip-app-event is used.
-func (p *Publisher) PublishEventSet(ctx context.Context, events *EventSet) (map[string][]PublishResult, error) {
-	results := make(map[string][]PublishResult)
+// RelayFailureSummaries describes each relay that did not accept every event
+// of a PublishEventSet result, in relay order, e.g.
+// "3/4 events on wss://relay.example (release event failed: blocked: not allowed)".
+func RelayFailureSummaries(results map[string][]PublishResult) []string {
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return eventKeyRank(keys[i]) < eventKeyRank(keys[j]) })
 
-	// Publish Software Application (skipped when --skip-app-event is used)
-	if events.AppMetadata != nil {
-		results["software_application"] = p.Publish(ctx, events.AppMetadata)
+	var relays []string
+	accepted := make(map[string]int)
+	failures := make(map[string][]string)
+	for _, key := range keys {
+		for _, r := range results[key] {
+			if _, ok := accepted[r.RelayURL]; !ok {
+				relays = append(relays, r.RelayURL)
+				accepted[r.RelayURL] = 0
+			}
+			if r.Success {
+				accepted[r.RelayURL]++
+				continue
+			}
+			reason := r.Message
+			switch {
+			case errors.Is(r.Error, ErrConnectionDropped) && r.Message != "":
+				reason = fmt.Sprintf("%v, relay said %q", ErrConnectionDropped, r.Message)
+			case reason == "" && r.Error != nil:
+				reason = r.Error.Error()
+			}
+			failures[r.RelayURL] = append(failures[r.RelayURL], fmt.Sprintf("%s failed: %s", eventKeyLabel(key), reason))
+		}
 	}
 
-	// Publish Software Release
-	results["software_release"] = p.Publish(ctx, events.Release)
+	var summaries []string
+	for _, url := range relays {
+		if len(failures[url]) == 0 {
+			continue
+		}
+		total := accepted[url] + len(failures[url])
+		summaries = append(summaries, fmt.Sprintf("%d/%d events on %s (%s)", accepted[url], total, url, strings.Join(failures[url], "; ")))
+	}
+	return summaries
+}
 
-	// Publish all Software Assets
-	for i, asset := range events.SoftwareAssets {
-		key := "software_asset"
-		if len(events.SoftwareAssets) > 1 {
-			key = fmt.Sprintf("software_asset_%d", i+1)
+// eventKeyRank orders PublishEventSet result keys as they are published:
+// app, release notes, release, then assets by number.
+func eventKeyRank(key string) int {
+	switch key {
+	case "software_application":
+		return 0
+	case "release_notes":
+		return 1
+	case "software_release":
+		return 2
+	case "software_asset":
+		return 3
+	}
+	n, _ := strconv.Atoi(strings.TrimPrefix(key, "software_asset_"))
+	return 3 + n
+}
+
+// eventKeyLabel names a PublishEventSet result key for humans.
+func eventKeyLabel(key string) string {
+	switch key {
+	case "software_application":
+		return "app event"
+	case "release_notes":
+		return "release notes event"
+	case "software_release":
+		return "release event"
+	case "software_asset":
+		return "asset event"
+	}
+	return "asset event " + strings.TrimPrefix(key, "software_asset_")
+}
+
+// publishSetToRelay publishes events to one relay in order over a single
+// connection. If the relay drops the connection part way, it reconnects once
+// and sends the events not yet acknowledged again; one that did land is
+// answered as a duplicate. Returns one result per event.
+func (p *Publisher) publishSetToRelay(ctx context.Context, url string, events []*nostr.Event) []PublishResult {
+	results := make([]PublishResult, len(events))
+	pending := make([]int, len(events))
+	for i := range events {
+		results[i].RelayURL = url
+		pending[i] = i
+	}
+
+	for attempt := 0; attempt < 2 && len(pending) > 0 && ctx.Err() == nil; attempt++ {
+		pending = p.publishOverConnection(ctx, url, events, pending, results)
+	}
+	return results
+}
+
+// publishOverConnection connects to url and publishes events[i] for each i in
+// pending, recording outcomes in results. It returns the indices still
+// unacknowledged when the connection failed, with their errors recorded.
+func (p *Publisher) publishOverConnection(ctx context.Context, url string, events []*nostr.Event, pending []int, results []PublishResult) []int {
+	var (
+		noticeMu sync.Mutex
+		notice   string
+	)
+	lastNotice := func() string {
+		noticeMu.Lock()
+		defer noticeMu.Unlock()
+		return notice
+	}
+
+	connectCtx, cancel := context.WithTimeout(ctx, RelayTimeout)
+	relay, err := connectRelay(connectCtx, url, nostr.WithNoticeHandler(func(n string) {
+		noticeMu.Lock()
+		notice = n
+		noticeMu.Unlock()
+	}))
+	cancel()
+	if err != nil {
+		for _, i := range pending {
+			results[i].Error = fmt.Errorf("failed to connect: %w", err)
 		}
-		results[key] = p.Publish(ctx, asset)
+		return pending
 	}
+	defer relay.Close()
 
-	return results, nil
+	for k, i := range pending {
+		publishCtx, cancel := context.WithTimeout(ctx, RelayTimeout)
+		err := relay.Publish(publishCtx, *events[i])
+		cancel()
+
+		// A dropped connection leaves this event unacknowledged even when
+		// Publish reports no error, so it and the rest are sent again.
+		if !relay.IsConnected() {
+			cause := relay.ConnectionError
+			if cause == nil {
+				cause = context.Cause(relay.Context())
+			}
+			for _, j := range pending[k:] {
+				results[j] = PublishResult{
+					RelayURL: url,
+					Error:    fmt.Errorf("%w: %w", ErrConnectionDropped, cause),
+					Message:  lastNotice(),
+				}
+			}
+			return pending[k:]
+		}
+
+		results[i] = PublishResult{RelayURL: url}
+		if err != nil {
+			results[i].Message = strings.TrimPrefix(err.Error(), "msg: ")
+			// Check if this is a duplicate error (event already exists)
+			if isDuplicateError(err) {
+				results[i].Success = true
+				results[i].IsDuplicate = true
+				results[i].Error = err // Keep error for informational purposes
+				continue
+			}
+			results[i].Error = fmt.Errorf("failed to publish: %w", err)
+			continue
+		}
+		results[i].Success = true
+	}
+	return nil
 }
 
-// PublishIdentityProof publishes a single kind 30509 event to all relays.
+// PublishIdentityProof publishes a single kind 30509 event to the relays that kind is routed to.
 func (p *Publisher) PublishIdentityProof(ctx context.Context, event *nostr.Event) ([]PublishResult, error) {
 	return p.Publish(ctx, event), nil
 }
 
-// RelayURLs returns the configured relay URLs.
+// Read-back retries allow for relays that take a moment to serve a just-published event.
+var (
+	readBackAttempts = 3
+	readBackDelay    = 2 * time.Second
+)
+
+// ReadBackResult is what a relay holds at the address of a published replaceable event.
+type ReadBackResult struct {
+	Sent   *nostr.Event // The event that was published
+	Stored *nostr.Event // The event the relay stores at the same (kind, pubkey, d), nil if none
+	Error  error        // Query failure on the last attempt
+}
+
+// Matches reports whether the relay stores the event that was sent.
+func (r ReadBackResult) Matches() bool {
+	return r.Stored != nil && r.Stored.ID == r.Sent.ID
+}
+
+// ReadBack queries url for the event stored at each replaceable event's address
+// (kind, pubkey, d tag) and reports whether it is the one that was sent. Reads that
+// don't match are retried a few times over a few seconds to allow for propagation
+// delay, except when the relay already holds a newer event, which is final.
+func (p *Publisher) ReadBack(ctx context.Context, url string, events []*nostr.Event) []ReadBackResult {
+	results := make([]ReadBackResult, len(events))
+	for i, event := range events {
+		results[i].Sent = event
+	}
+
+	for attempt := 0; attempt < readBackAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return results
+			case <-time.After(readBackDelay):
+			}
+		}
+
+		pending := false
+		for i := range results {
+			r := &results[i]
+			if r.Matches() || (r.Stored != nil && r.Stored.CreatedAt > r.Sent.CreatedAt) {
+				continue
+			}
+			r.Stored, r.Error = p.readBackEvent(ctx, url, r.Sent)
+			if !r.Matches() && (r.Stored == nil || r.Stored.CreatedAt <= r.Sent.CreatedAt) {
+				pending = true
+			}
+		}
+		if !pending {
+			break
+		}
+	}
+	return results
+}
+
+// readBackEvent returns the newest event url stores at event's replaceable address.
+func (p *Publisher) readBackEvent(ctx context.Context, url string, event *nostr.Event) (*nostr.Event, error) {
+	filter := nostr.Filter{
+		Kinds:   []int{event.Kind},
+		Authors: []string{event.PubKey},
+		Tags: nostr.TagMap{
+			"d": []string{tagValue(event, "d")},
+		},
+	}
+	events, err := p.queryRelayMultiple(ctx, url, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var newest *nostr.Event
+	for _, e := range events {
+		if newest == nil || e.CreatedAt > newest.CreatedAt {
+			newest = e
+		}
+	}
+	return newest, nil
+}
+
+// RelayURLs returns the configured relay URLs, without those added by routes.
 func (p *Publisher) RelayURLs() []string {
 	return p.relayURLs
 }
@@ -189,67 +523,454 @@ type ExistingAsset struct {
 func (p *Publisher) CheckExistingAssetAny(ctx context.Context, identifier, version string) (*ExistingAsset, error) {
 	filter := nostr.Filter{
 		Kinds: []int{KindSoftwareAsset},
-		Tags: nostr.TagMap{
-			"i":       []string{identifier},
-			"version": []string{version},
-		},
-		Limit: 1,
+		Tags:  nostr.TagMap{"i": []string{identifier}},
 	}
-	return p.checkExistingAssetWithFilter(ctx, filter)
+	return p.checkExistingAssetWithFilter(ctx, filter, version)
 }
 
 // CheckExistingAsset queries all relays to check if a Software Asset already exists
 // for the given publisher. It searches for kind 3063 events scoped to pubkey with
 // matching `i` tag (identifier) and `version` tag.
-// Returns the first existing Software Asset found, or nil if none exists.
+// Returns the first existing Software Asset found, or nil if none exists. When a
+// relay capped its results before a match or the end of the publisher's history,
+// the error wraps ErrExistenceUnknown.
 func (p *Publisher) CheckExistingAsset(ctx context.Context, pubkey, identifier, version string) (*ExistingAsset, error) {
 	filter := nostr.Filter{
 		Kinds:   []int{KindSoftwareAsset},
 		Authors: []string{pubkey},
-		Tags: nostr.TagMap{
-			"i":       []string{identifier},
-			"version": []string{version},
-		},
-		Limit: 1,
+		Tags:    nostr.TagMap{"i": []string{identifier}},
+	}
+	return p.checkExistingAssetWithFilter(ctx, filter, version)
+}
+
+// PublishedVersionCode is the highest versionCode published for a package on a channel.
+type PublishedVersionCode struct {
+	Channel     string // "" when no fetched release references the asset
+	Version     string
+	VersionCode int64
+}
+
+// FetchVersionCodesByChannel returns the highest versionCode the publisher has
+// published for a package on each release channel. The channel comes from the
+// Software Release (kind 30063) that references a Software Asset (kind 3063),
+// and the version code from the asset. Returns an error if no relay answered.
+// When a relay's history was cut short, the codes found are returned with an
+// error wrapping ErrHistoryIncomplete.
+func (p *Publisher) FetchVersionCodesByChannel(ctx context.Context, pubkey, identifier string) (map[string]PublishedVersionCode, error) {
+	releases, err := p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindRelease},
+		Authors: []string{pubkey},
+		Tags:    nostr.TagMap{"i": []string{identifier}},
+		Limit:   500,
+	})
+	if err != nil && !errors.Is(err, ErrHistoryIncomplete) {
+		return nil, err
+	}
+	incomplete := err
+	assets, err := p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindSoftwareAsset},
+		Authors: []string{pubkey},
+		Tags:    nostr.TagMap{"i": []string{identifier}},
+		Limit:   500,
+	})
+	if err != nil && !errors.Is(err, ErrHistoryIncomplete) {
+		return nil, err
+	}
+	if incomplete == nil {
+		incomplete = err
+	}
+
+	// Map assets to channels via release e tags, falling back to the version tag
+	channelByAsset := make(map[string]string)
+	channelByVersion := make(map[string]string)
+	for _, release := range releases {
+		channel := tagValue(release, "c")
+		if channel == "" {
+			channel = "main"
+		}
+		for _, tag := range release.Tags {
+			if len(tag) >= 2 && tag[0] == "e" {
+				channelByAsset[tag[1]] = channel
+			}
+		}
+		if version := tagValue(release, "version"); version != "" {
+			channelByVersion[version] = channel
+		}
+	}
+
+	codes := make(map[string]PublishedVersionCode)
+	for _, asset := range assets {
+		code, err := strconv.ParseInt(tagValue(asset, "version_code"), 10, 64)
+		if err != nil {
+			continue
+		}
+		version := tagValue(asset, "version")
+		channel, ok := channelByAsset[asset.ID]
+		if !ok {
+			channel = channelByVersion[version]
+		}
+		if current, ok := codes[channel]; !ok || code > current.VersionCode {
+			codes[channel] = PublishedVersionCode{Channel: channel, Version: version, VersionCode: code}
+		}
+	}
+	return codes, incomplete
+}
+
+// FetchReleases returns the publisher's Software Releases (kind 30063) of
+// identifier on every channel. Returns an error if no relay answered or a
+// relay's history was cut short (ErrHistoryIncomplete).
+func (p *Publisher) FetchReleases(ctx context.Context, pubkey, identifier string) ([]*nostr.Event, error) {
+	return p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindRelease},
+		Authors: []string{pubkey},
+		Tags:    nostr.TagMap{"i": []string{identifier}},
+		Limit:   500,
+	})
+}
+
+// PublishedRelease is a published Software Release and the Software Assets it
+// references that were found on relays.
+type PublishedRelease struct {
+	Event  *nostr.Event
+	Assets []*nostr.Event
+}
+
+// HasAsset reports whether the release references an asset with the given SHA-256.
+func (r *PublishedRelease) HasAsset(sha256 string) bool {
+	for _, asset := range r.Assets {
+		if strings.EqualFold(tagValue(asset, "x"), sha256) {
+			return true
+		}
 	}
-	return p.checkExistingAssetWithFilter(ctx, filter)
+	return false
 }
 
-func (p *Publisher) checkExistingAssetWithFilter(ctx context.Context, filter nostr.Filter) (*ExistingAsset, error) {
+// FetchRelease returns the publisher's newest Software Release (kind 30063) for
+// identifier@version with the Software Assets (kind 3063) it references, or nil
+// if none is published. Returns an error only if no relay answered.
+func (p *Publisher) FetchRelease(ctx context.Context, pubkey, identifier, version string) (*PublishedRelease, error) {
+	releases, err := p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindRelease},
+		Authors: []string{pubkey},
+		Tags:    nostr.TagMap{"d": []string{identifier + "@" + version}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var latest *nostr.Event
+	for _, release := range releases {
+		if latest == nil || release.CreatedAt > latest.CreatedAt {
+			latest = release
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	var ids []string
+	for _, tag := range latest.Tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			ids = append(ids, tag[1])
+		}
+	}
+	result := &PublishedRelease{Event: latest}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	assets, err := p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindSoftwareAsset},
+		Authors: []string{pubkey},
+		IDs:     ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Keep the release's order
+	for _, id := range ids {
+		for _, asset := range assets {
+			if asset.ID == id {
+				result.Assets = append(result.Assets, asset)
+				break
+			}
+		}
+	}
+	return result, nil
+}
 
-	// Query each relay until we find an existing asset
+// PublishedCert is the signing certificate of a published Software Asset.
+type PublishedCert struct {
+	CertHash string // apk_certificate_hash
+	Version  string
+}
+
+// FetchLatestAssetCert returns the signing certificate of the publisher's most
+// recent Software Asset (kind 3063) for a package that carries one, or nil if
+// none does. Returns an error only if no relay answered.
+func (p *Publisher) FetchLatestAssetCert(ctx context.Context, pubkey, identifier string) (*PublishedCert, error) {
+	assets, err := p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindSoftwareAsset},
+		Authors: []string{pubkey},
+		Tags:    nostr.TagMap{"i": []string{identifier}},
+		Limit:   500,
+	})
+	// Pages are read newest first, so a cut-short history still holds the latest asset
+	if err != nil && !errors.Is(err, ErrHistoryIncomplete) {
+		return nil, err
+	}
+
+	var latest *nostr.Event
+	for _, asset := range assets {
+		if tagValue(asset, "apk_certificate_hash") == "" {
+			continue
+		}
+		if latest == nil || asset.CreatedAt > latest.CreatedAt {
+			latest = asset
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	return &PublishedCert{
+		CertHash: strings.ToLower(tagValue(latest, "apk_certificate_hash")),
+		Version:  tagValue(latest, "version"),
+	}, nil
+}
+
+// queryAll queries every relay and returns the matching events, deduplicated by ID.
+// Returns an error if every relay failed. With a limit, each relay is read in
+// pages until its history is exhausted; when a relay's history could not be
+// read to the end, the events read are returned with an error wrapping
+// ErrHistoryIncomplete.
+func (p *Publisher) queryAll(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	var (
+		all     []*nostr.Event
+		lastErr error
+		ok      bool
+		capped  []string
+	)
+	seen := make(map[string]bool)
 	for _, url := range p.relayURLs {
-		event, err := p.queryRelay(ctx, url, filter)
+		events, _, complete, err := p.queryPages(ctx, url, filter, nil)
+		if err != nil && len(events) == 0 {
+			lastErr = err
+			continue
+		}
+		ok = true
+		if !complete {
+			capped = append(capped, url)
+		}
+		for _, event := range events {
+			if !seen[event.ID] {
+				seen[event.ID] = true
+				all = append(all, event)
+			}
+		}
+	}
+	if !ok && lastErr != nil {
+		return nil, fmt.Errorf("no relay answered: %w", lastErr)
+	}
+	if len(capped) > 0 {
+		return all, fmt.Errorf("%w: %s", ErrHistoryIncomplete, strings.Join(capped, ", "))
+	}
+	return all, nil
+}
+
+// tagValue returns the value of the first tag named key, or "".
+func tagValue(event *nostr.Event, key string) string {
+	if tag := event.Tags.GetFirst([]string{key, ""}); tag != nil && len(*tag) >= 2 {
+		return (*tag)[1]
+	}
+	return ""
+}
+
+// BlobReference is a relay event that references a Blossom blob.
+type BlobReference struct {
+	EventID   string
+	Kind      int
+	PackageID string // from the event's i tag, or d tag for app events
+	CreatedAt time.Time
+}
+
+// FetchBlobReferences finds the events referencing each hash, either in an x tag,
+// within any tag value such as a url, icon, image or imeta entry, or in the
+// content, where release notes articles embed image URLs. It reads the
+// publisher's app, release, asset and release notes events, plus events by
+// anyone carrying a matching x tag. Hashes without references are absent from
+// the result. Returns an error if no relay answered or a relay's history was
+// cut short (ErrHistoryIncomplete), so a partial view is never mistaken for a
+// blob having no references.
+func (p *Publisher) FetchBlobReferences(ctx context.Context, pubkey string, hashes []string) (map[string][]BlobReference, error) {
+	refs := make(map[string][]BlobReference)
+	if len(hashes) == 0 {
+		return refs, nil
+	}
+
+	events, err := p.queryAll(ctx, nostr.Filter{
+		Kinds:   []int{KindAppMetadata, KindRelease, KindSoftwareAsset, KindLongForm},
+		Authors: []string{pubkey},
+		Limit:   1000,
+	})
+	if err != nil {
+		return nil, err
+	}
+	tagged, err := p.queryAll(ctx, nostr.Filter{
+		Tags:  nostr.TagMap{"x": hashes},
+		Limit: 1000,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, event := range append(events, tagged...) {
+		if seen[event.ID] {
+			continue
+		}
+		seen[event.ID] = true
+
+		packageID := tagValue(event, "i")
+		switch {
+		case packageID != "":
+		case event.Kind == KindAppMetadata:
+			packageID = tagValue(event, "d")
+		case event.Kind == KindLongForm:
+			// Release notes are addressed as <package>@<version>
+			packageID, _, _ = strings.Cut(tagValue(event, "d"), "@")
+		}
+		for _, hash := range hashes {
+			if !referencesHash(event, hash) {
+				continue
+			}
+			refs[hash] = append(refs[hash], BlobReference{
+				EventID:   event.ID,
+				Kind:      event.Kind,
+				PackageID: packageID,
+				CreatedAt: event.CreatedAt.Time(),
+			})
+		}
+	}
+	return refs, nil
+}
+
+// referencesHash reports whether the content or any tag value of the event
+// contains hash.
+func referencesHash(event *nostr.Event, hash string) bool {
+	if strings.Contains(strings.ToLower(event.Content), hash) {
+		return true
+	}
+	for _, tag := range event.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		for _, value := range tag[1:] {
+			if strings.Contains(strings.ToLower(value), hash) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkExistingAssetWithFilter looks for an asset of version among the events
+// matching filter on each relay. The #version filter is tried first with limit
+// 1. Relays that do not index multi-letter tags ignore it, and the client drops
+// the other assets they return, so an empty answer is only trusted from a relay
+// that versionIndexed shows honors the filter. The relays that ignore it have
+// their assets for the identifier paged through.
+func (p *Publisher) checkExistingAssetWithFilter(ctx context.Context, filter nostr.Filter, version string) (*ExistingAsset, error) {
+	match := func(event *nostr.Event) bool { return tagValue(event, "version") == version }
+
+	tight := withVersion(filter, version)
+	tight.Limit = 1
+
+	filter.Limit = existencePageSize
+	var capped []string
+	for _, url := range p.relayURLs {
+		event, err := p.queryRelay(ctx, url, tight)
 		if err != nil {
 			// Log error but continue to other relays
 			continue
 		}
-		if event != nil {
-			// Extract version from the event for confirmation
-			existingVersion := ""
-			for _, tag := range event.Tags {
-				if len(tag) >= 2 && tag[0] == "version" {
-					existingVersion = tag[1]
-					break
-				}
+		if event == nil {
+			indexed, err := p.versionIndexed(ctx, url, filter)
+			if err != nil || indexed {
+				continue
+			}
+			var complete bool
+			_, event, complete, err = p.queryPages(ctx, url, filter, match)
+			if err != nil {
+				continue
+			}
+			if event == nil && !complete {
+				capped = append(capped, url)
 			}
+		}
+		if event != nil {
 			return &ExistingAsset{
 				Event:    event,
 				RelayURL: url,
-				Version:  existingVersion,
+				Version:  tagValue(event, "version"),
 			}, nil
 		}
 	}
 
+	if len(capped) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrExistenceUnknown, strings.Join(capped, ", "))
+	}
 	return nil, nil
 }
 
+// versionIndexed reports whether the relay honors #version filters on the
+// events matching filter. It reads the two newest events and asks for the
+// older one's version by tag: a relay that ignores the tag answers with the
+// newest event, which the client drops. A relay with no matching events has
+// nothing to page through and counts as indexed.
+func (p *Publisher) versionIndexed(ctx context.Context, url string, filter nostr.Filter) (bool, error) {
+	filter.Limit = 2
+	events, err := p.queryRelayMultiple(ctx, url, filter)
+	if err != nil {
+		return false, err
+	}
+	if len(events) == 0 {
+		return true, nil
+	}
+	if len(events) < 2 {
+		return false, nil
+	}
+	newer, older := events[0], events[1]
+	if older.CreatedAt > newer.CreatedAt {
+		newer, older = older, newer
+	}
+	version := tagValue(older, "version")
+	if newer.CreatedAt == older.CreatedAt || version == "" || version == tagValue(newer, "version") {
+		return false, nil
+	}
+
+	probe := withVersion(filter, version)
+	probe.Limit = 1
+	event, err := p.queryRelay(ctx, url, probe)
+	if err != nil {
+		return false, err
+	}
+	return event != nil, nil
+}
+
+// withVersion returns a copy of filter that also requires the version tag.
+func withVersion(filter nostr.Filter, version string) nostr.Filter {
+	tags := nostr.TagMap{"version": []string{version}}
+	for key, values := range filter.Tags {
+		tags[key] = values
+	}
+	filter.Tags = tags
+	return filter
+}
+
 // queryRelay queries a single relay for events matching the filter.
 func (p *Publisher) queryRelay(ctx context.Context, url string, filter nostr.Filter) (*nostr.Event, error) {
 	ctx, cancel := context.WithTimeout(ctx, RelayTimeout)
 	defer cancel()
 
-	relay, err := nostr.RelayConnect(ctx, url)
+	relay, err := connectRelay(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
@@ -271,6 +992,7 @@ func (p *Publisher) queryRelay(ctx context.Context, url string, filter nostr.Fil
 type ExistingApp struct {
 	Event    *nostr.Event
 	RelayURL string
+	Pubkey   string // Author of Event (hex)
 }
 
 // CheckExistingApp queries all relays to check if an App Metadata event already exists.
@@ -296,6 +1018,7 @@ func (p *Publisher) CheckExistingApp(ctx context.Context, identifier string) (*E
 			return &ExistingApp{
 				Event:    event,
 				RelayURL: url,
+				Pubkey:   event.PubKey,
 			}, nil
 		}
 	}
@@ -303,6 +1026,37 @@ func (p *Publisher) CheckExistingApp(ctx context.Context, identifier string) (*E
 	return nil, nil
 }
 
+// FetchAppMetadata queries relays for the publisher's own kind 32267 event for identifier.
+// Returns the newest event found across relays, or nil if none exists.
+func (p *Publisher) FetchAppMetadata(ctx context.Context, pubkey, identifier string) (*nostr.Event, error) {
+	filter := nostr.Filter{
+		Kinds:   []int{KindAppMetadata},
+		Authors: []string{pubkey},
+		Tags: nostr.TagMap{
+			"d": []string{identifier},
+		},
+		Limit: 1,
+	}
+
+	var newest *nostr.Event
+	var lastErr error
+	for _, url := range p.relayURLs {
+		event, err := p.queryRelay(ctx, url, filter)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if event != nil && (newest == nil || event.CreatedAt > newest.CreatedAt) {
+			newest = event
+		}
+	}
+
+	if newest == nil && lastErr != nil {
+		return nil, lastErr
+	}
+	return newest, nil
+}
+
 // FetchIdentityProof queries relays for a kind 30509 identity proof event.
 // If certHash is provided, looks for that specific identity; otherwise returns any identity proof.
 // Returns nil if no matching event is found.
@@ -362,12 +1116,20 @@ func (p *Publisher) FetchAllIdentityProofs(ctx context.Context, pubkey string) (
 	return allEvents, nil
 }
 
+// connectRelay opens a relay connection after checking the URL against the network allowlist.
+func connectRelay(ctx context.Context, url string, opts ...nostr.RelayOption) (*nostr.Relay, error) {
+	if err := netpolicy.CheckURL(url); err != nil {
+		return nil, err
+	}
+	return nostr.RelayConnect(ctx, url, opts...)
+}
+
 // queryRelayMultiple queries a single relay and returns all matching events.
 func (p *Publisher) queryRelayMultiple(ctx context.Context, url string, filter nostr.Filter) ([]*nostr.Event, error) {
 	ctx, cancel := context.WithTimeout(ctx, RelayTimeout)
 	defer cancel()
 
-	relay, err := nostr.RelayConnect(ctx, url)
+	relay, err := connectRelay(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
@@ -383,7 +1145,7 @@ func BuildIdentityProofEvent(tags nostr.Tags, pubkey string, createdAt int64) *n
 		Kind:      KindIdentityProof,
 		PubKey:    pubkey,
 		CreatedAt: nostr.Timestamp(createdAt),
-		Tags:      tags,
+		Tags:      append(tags, ClientTag()),
 		Content:   "",
 	}
 }
@@ -0,0 +1,502 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/nostr/nostrtest"
+)
+
+// newMockRelay starts a relay that answers every REQ with the stored events
+// matching its filters, followed by EOSE. It returns the relay's ws:// URL.
+func newMockRelay(t *testing.T, events ...*nostr.Event) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := r.Context()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			req, ok := nostr.ParseMessage(string(data)).(*nostr.ReqEnvelope)
+			if !ok {
+				continue
+			}
+			for _, event := range events {
+				if !req.Filters.Match(event) {
+					continue
+				}
+				msg, _ := nostr.EventEnvelope{SubscriptionID: &req.SubscriptionID, Event: *event}.MarshalJSON()
+				conn.Write(ctx, websocket.MessageText, msg)
+			}
+			eose, _ := nostr.EOSEEnvelope(req.SubscriptionID).MarshalJSON()
+			conn.Write(ctx, websocket.MessageText, eose)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// publishRelay is a mock relay that acknowledges EVENTs. It answers events it
+// already stored as duplicates, rejects the kinds in reject with their message,
+// and, on the first dropConnections connections, sends a NOTICE and closes the
+// connection instead of acknowledging once more than dropAfter events arrived.
+type publishRelay struct {
+	URL string
+
+	mu          sync.Mutex
+	stored      map[string]bool
+	received    map[string]int // EVENT messages received per event ID
+	total       int            // EVENT messages received on all connections
+	connections int
+}
+
+func newPublishRelay(t *testing.T, dropAfter, dropConnections int, reject map[int]string) *publishRelay {
+	t.Helper()
+	pr := &publishRelay{stored: make(map[string]bool), received: make(map[string]int)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		pr.mu.Lock()
+		pr.connections++
+		drops := pr.connections <= dropConnections
+		pr.mu.Unlock()
+
+		ctx := r.Context()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			env, ok := nostr.ParseMessage(string(data)).(*nostr.EventEnvelope)
+			if !ok {
+				continue
+			}
+			pr.mu.Lock()
+			pr.total++
+			pr.received[env.Event.ID]++
+			if drops && pr.total > dropAfter {
+				pr.mu.Unlock()
+				notice, _ := nostr.NoticeEnvelope("shutting down for maintenance").MarshalJSON()
+				conn.Write(ctx, websocket.MessageText, notice)
+				conn.Close(websocket.StatusGoingAway, "")
+				return
+			}
+			okEnv := nostr.OKEnvelope{EventID: env.Event.ID, OK: true}
+			switch {
+			case reject[env.Event.Kind] != "":
+				okEnv.OK, okEnv.Reason = false, reject[env.Event.Kind]
+			case pr.stored[env.Event.ID]:
+				okEnv.OK, okEnv.Reason = false, "duplicate: already have this event"
+			default:
+				pr.stored[env.Event.ID] = true
+			}
+			pr.mu.Unlock()
+			msg, _ := okEnv.MarshalJSON()
+			conn.Write(ctx, websocket.MessageText, msg)
+		}
+	}))
+	t.Cleanup(server.Close)
+	pr.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	return pr
+}
+
+// testEventSet returns a signed app, release and two asset events.
+func testEventSet(t *testing.T) *EventSet {
+	sk := nostr.GeneratePrivateKey()
+	return &EventSet{
+		AppMetadata: nostrtest.SignedEvent(t, sk, KindAppMetadata, nostr.Tags{{"d", "com.example.app"}}),
+		Release:     nostrtest.SignedEvent(t, sk, KindRelease, nostr.Tags{{"d", "com.example.app@1.0.0"}}),
+		SoftwareAssets: []*nostr.Event{
+			nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", "com.example.app"}, {"variant", "a"}}),
+			nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", "com.example.app"}, {"variant", "b"}}),
+		},
+	}
+}
+
+func TestPublishEventSetResendsAfterDroppedConnection(t *testing.T) {
+	relay := newPublishRelay(t, 2, 1, nil)
+	events := testEventSet(t)
+
+	results, err := NewPublisher([]string{relay.URL}).PublishEventSet(context.Background(), events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, rs := range results {
+		if len(rs) != 1 || !rs[0].Success || rs[0].IsDuplicate {
+			t.Errorf("%s: results = %+v, want one fresh success", key, rs)
+		}
+	}
+
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	if relay.connections != 2 {
+		t.Errorf("connections = %d, want 2 (one reconnect)", relay.connections)
+	}
+	// Acknowledged events are not sent again; the one in flight when the relay dropped is
+	for i, e := range []*nostr.Event{events.AppMetadata, events.Release, events.SoftwareAssets[0], events.SoftwareAssets[1]} {
+		want := 1
+		if i == 2 {
+			want = 2
+		}
+		if got := relay.received[e.ID]; got != want {
+			t.Errorf("event %d sent %d times, want %d", i, got, want)
+		}
+	}
+}
+
+func TestPublishEventSetReportsUnacknowledgedEvents(t *testing.T) {
+	relay := newPublishRelay(t, 2, 2, map[int]string{KindRelease: "blocked: pubkey not whitelisted"})
+
+	results, err := NewPublisher([]string{relay.URL}).PublishEventSet(context.Background(), testEventSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := results["software_application"][0]; !r.Success {
+		t.Errorf("software_application: %+v, want success", r)
+	}
+	if r := results["software_release"][0]; r.Success || r.Message != "blocked: pubkey not whitelisted" {
+		t.Errorf("software_release: %+v, want rejection with the relay's message", r)
+	}
+	for _, key := range []string{"software_asset_1", "software_asset_2"} {
+		r := results[key][0]
+		if r.Success || r.Error == nil || !strings.Contains(r.Error.Error(), "connection closed") {
+			t.Errorf("%s: %+v, want a connection-closed failure", key, r)
+		}
+		if r.Message != "shutting down for maintenance" {
+			t.Errorf("%s: Message = %q, want the relay's NOTICE", key, r.Message)
+		}
+	}
+}
+
+func TestPublishEventSetRoutesByKind(t *testing.T) {
+	main := newPublishRelay(t, 0, 0, nil)
+	archive := newPublishRelay(t, 0, 0, nil)
+	proofs := newPublishRelay(t, 0, 0, nil)
+	events := testEventSet(t)
+
+	publisher := NewPublisher([]string{main.URL})
+	publisher.SetRoutes([]RelayRoute{
+		{Kinds: []int{KindSoftwareAsset}, Relays: []string{archive.URL}},
+		{Kinds: []int{KindIdentityProof}, Relays: []string{proofs.URL}, Replace: true},
+	})
+
+	results, err := publisher.PublishEventSet(context.Background(), events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]int{"software_application": 1, "software_release": 1, "software_asset_1": 2, "software_asset_2": 2} {
+		if got := len(results[key]); got != want {
+			t.Errorf("%s: %d results, want %d", key, got, want)
+		}
+	}
+
+	archive.mu.Lock()
+	if archive.total != 2 || archive.received[events.SoftwareAssets[0].ID] != 1 || archive.received[events.SoftwareAssets[1].ID] != 1 {
+		t.Errorf("archive relay received %v, want only the two asset events", archive.received)
+	}
+	archive.mu.Unlock()
+	proofs.mu.Lock()
+	if proofs.connections != 0 {
+		t.Errorf("proof relay got %d connections, want none for an event set", proofs.connections)
+	}
+	proofs.mu.Unlock()
+
+	if got := publisher.RelaysForKind(KindIdentityProof); len(got) != 1 || got[0] != proofs.URL {
+		t.Errorf("RelaysForKind(identity proof) = %v, want only the replacing route's relay", got)
+	}
+	if got := publisher.AllRelayURLs(); len(got) != 3 || got[0] != main.URL {
+		t.Errorf("AllRelayURLs() = %v, want the default relay then the routed ones", got)
+	}
+}
+
+func TestRelayFailureSummaries(t *testing.T) {
+	const a, b = "wss://a.example", "wss://b.example"
+	dropped := fmt.Errorf("%w: %w", ErrConnectionDropped, context.Canceled)
+	results := map[string][]PublishResult{
+		"software_application": {{RelayURL: a, Success: true}, {RelayURL: b, Success: true}},
+		"software_release":     {{RelayURL: a, Success: true}, {RelayURL: b, Error: errors.New("failed to publish: msg: blocked: not allowed"), Message: "blocked: not allowed"}},
+		"software_asset_10":    {{RelayURL: a, Error: dropped, Message: "restarting"}, {RelayURL: b, Success: true, IsDuplicate: true}},
+		"software_asset_2":     {{RelayURL: a, Error: dropped}, {RelayURL: b, Success: true}},
+	}
+
+	got := RelayFailureSummaries(results)
+	want := []string{
+		"2/4 events on wss://a.example (asset event 2 failed: " + dropped.Error() + "; asset event 10 failed: " + ErrConnectionDropped.Error() + `, relay said "restarting")`,
+		"3/4 events on wss://b.example (release event failed: blocked: not allowed)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RelayFailureSummaries() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestFetchVersionCodesByChannel(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	const pkg = "com.example.app"
+
+	asset := func(version string, code int) *nostr.Event {
+		return nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{
+			{"i", pkg}, {"version", version}, {"version_code", strconv.Itoa(code)},
+		})
+	}
+	release := func(version, channel string, assets ...*nostr.Event) *nostr.Event {
+		tags := nostr.Tags{{"i", pkg}, {"version", version}, {"d", pkg + "@" + version}}
+		if channel != "" {
+			tags = append(tags, nostr.Tag{"c", channel})
+		}
+		for _, a := range assets {
+			tags = append(tags, nostr.Tag{"e", a.ID})
+		}
+		return nostrtest.SignedEvent(t, sk, KindRelease, tags)
+	}
+
+	main1, main2 := asset("1.0.0", 100), asset("2.0.0", 200)
+	beta := asset("2.1.0-beta", 195)
+	orphan := asset("0.9.0", 90) // referenced by version only
+	other := nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", "com.example.other"}, {"version", "9.0.0"}, {"version_code", "900"}})
+
+	relayURL := newMockRelay(t,
+		main1, main2, beta, orphan, other,
+		release("1.0.0", "", main1), // no c tag means main
+		release("2.0.0", "main", main2),
+		release("2.1.0-beta", "beta", beta),
+		release("0.9.0", "nightly"),
+	)
+
+	codes, err := NewPublisher([]string{relayURL}).FetchVersionCodesByChannel(context.Background(), pubkey, pkg)
+	if err != nil {
+		t.Fatalf("FetchVersionCodesByChannel() error: %v", err)
+	}
+
+	want := map[string]PublishedVersionCode{
+		"main":    {Channel: "main", Version: "2.0.0", VersionCode: 200},
+		"beta":    {Channel: "beta", Version: "2.1.0-beta", VersionCode: 195},
+		"nightly": {Channel: "nightly", Version: "0.9.0", VersionCode: 90},
+	}
+	if len(codes) != len(want) {
+		t.Fatalf("got channels %v, want %v", codes, want)
+	}
+	for channel, w := range want {
+		if codes[channel] != w {
+			t.Errorf("codes[%q] = %+v, want %+v", channel, codes[channel], w)
+		}
+	}
+}
+
+func TestFetchVersionCodesByChannelNoRelay(t *testing.T) {
+	_, err := NewPublisher([]string{"ws://127.0.0.1:1"}).FetchVersionCodesByChannel(context.Background(), testPubkey, "com.example.app")
+	if err == nil {
+		t.Error("expected an error when no relay answers")
+	}
+}
+
+func TestFetchLatestAssetCert(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	const pkg = "com.example.app"
+
+	asset := func(version, cert string, createdAt nostr.Timestamp) *nostr.Event {
+		tags := nostr.Tags{{"i", pkg}, {"version", version}}
+		if cert != "" {
+			tags = append(tags, nostr.Tag{"apk_certificate_hash", cert})
+		}
+		event := &nostr.Event{Kind: KindSoftwareAsset, Tags: tags, CreatedAt: createdAt}
+		if err := event.Sign(sk); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	relayURL := newMockRelay(t,
+		asset("1.0.0", "AAAA", 100),
+		asset("2.0.0", "BBBB", 200),
+		asset("3.0.0", "", 300), // no certificate hash
+	)
+	publisher := NewPublisher([]string{relayURL})
+
+	got, err := publisher.FetchLatestAssetCert(context.Background(), pubkey, pkg)
+	if err != nil {
+		t.Fatalf("FetchLatestAssetCert() error: %v", err)
+	}
+	if want := (PublishedCert{CertHash: "bbbb", Version: "2.0.0"}); got == nil || *got != want {
+		t.Errorf("FetchLatestAssetCert() = %+v, want %+v", got, want)
+	}
+
+	if got, err := publisher.FetchLatestAssetCert(context.Background(), pubkey, "com.example.other"); err != nil || got != nil {
+		t.Errorf("FetchLatestAssetCert() for an unpublished package = %+v, %v; want nil", got, err)
+	}
+}
+
+func TestFetchRelease(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	const pkg = "com.example.app"
+
+	arm64 := nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", pkg}, {"x", "AAAA"}, {"f", "android-arm64-v8a"}})
+	x86 := nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", pkg}, {"x", "bbbb"}, {"f", "android-x86_64"}})
+	release := func(createdAt nostr.Timestamp, assets ...*nostr.Event) *nostr.Event {
+		tags := nostr.Tags{{"i", pkg}, {"d", pkg + "@1.0.0"}}
+		for _, asset := range assets {
+			tags = append(tags, nostr.Tag{"e", asset.ID})
+		}
+		event := &nostr.Event{Kind: KindRelease, Tags: tags, CreatedAt: createdAt}
+		if err := event.Sign(sk); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	latest := release(200, x86, arm64)
+	relayURL := newMockRelay(t, release(100, arm64), latest, arm64, x86)
+	publisher := NewPublisher([]string{relayURL})
+
+	got, err := publisher.FetchRelease(context.Background(), pubkey, pkg, "1.0.0")
+	if err != nil {
+		t.Fatalf("FetchRelease() error: %v", err)
+	}
+	if got == nil || got.Event.ID != latest.ID {
+		t.Fatalf("FetchRelease() = %+v, want the newest release", got)
+	}
+	if len(got.Assets) != 2 || got.Assets[0].ID != x86.ID || got.Assets[1].ID != arm64.ID {
+		t.Errorf("FetchRelease() assets = %v, want x86_64 then arm64 in release order", got.Assets)
+	}
+	if !got.HasAsset("aaaa") || !got.HasAsset("BBBB") || got.HasAsset("cccc") {
+		t.Error("HasAsset() does not match asset hashes case-insensitively")
+	}
+
+	if got, err := publisher.FetchRelease(context.Background(), pubkey, pkg, "2.0.0"); err != nil || got != nil {
+		t.Errorf("FetchRelease() for an unpublished version = %+v, %v; want nil", got, err)
+	}
+}
+
+func TestCheckExistingAppReportsAuthor(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	author, _ := nostr.GetPublicKey(sk)
+	relayURL := newMockRelay(t, nostrtest.SignedEvent(t, sk, KindAppMetadata, nostr.Tags{{"d", "com.example.app"}}))
+	publisher := NewPublisher([]string{relayURL})
+
+	existing, err := publisher.CheckExistingApp(context.Background(), "com.example.app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing == nil || existing.Pubkey != author || existing.RelayURL != relayURL {
+		t.Fatalf("CheckExistingApp() = %+v, want the app authored by %s", existing, author)
+	}
+
+	if existing, err := publisher.CheckExistingApp(context.Background(), "com.example.other"); err != nil || existing != nil {
+		t.Errorf("CheckExistingApp(unknown) = %+v, %v, want nil", existing, err)
+	}
+}
+
+func TestFetchBlobReferences(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+	otherSK := nostr.GeneratePrivateKey()
+
+	apkHash := strings.Repeat("a", 64)
+	iconHash := strings.Repeat("b", 64)
+	mirroredHash := strings.Repeat("c", 64)
+	orphanHash := strings.Repeat("d", 64)
+	notesImageHash := strings.Repeat("e", 64)
+
+	asset := nostrtest.SignedEvent(t, sk, KindSoftwareAsset, nostr.Tags{{"i", "com.example.app"}, {"x", apkHash}})
+	app := nostrtest.SignedEvent(t, sk, KindAppMetadata, nostr.Tags{
+		{"d", "com.example.app"}, {"icon", "https://cdn.example.com/" + iconHash + ".png"},
+	})
+	mirror := nostrtest.SignedEvent(t, otherSK, 1063, nostr.Tags{{"x", mirroredHash}})
+	notes := nostrtest.SignedEvent(t, sk, KindLongForm, nostr.Tags{{"d", "com.example.app@1.0.0"}})
+	notes.Content = "## What's new\n\n![Dark mode](https://cdn.example.com/" + notesImageHash + ".png)"
+	if err := notes.Sign(sk); err != nil {
+		t.Fatal(err)
+	}
+
+	relayURL := newMockRelay(t, asset, app, mirror, notes)
+	refs, err := NewPublisher([]string{relayURL}).FetchBlobReferences(context.Background(), pubkey,
+		[]string{apkHash, iconHash, mirroredHash, notesImageHash, orphanHash})
+	if err != nil {
+		t.Fatalf("FetchBlobReferences() error: %v", err)
+	}
+
+	for hash, want := range map[string]*nostr.Event{apkHash: asset, iconHash: app, mirroredHash: mirror, notesImageHash: notes} {
+		if len(refs[hash]) != 1 || refs[hash][0].EventID != want.ID {
+			t.Errorf("refs[%s...] = %+v, want event %s", hash[:4], refs[hash], want.ID)
+		}
+	}
+	if got := refs[iconHash][0].PackageID; got != "com.example.app" {
+		t.Errorf("icon reference package = %q, want com.example.app", got)
+	}
+	if got := refs[notesImageHash][0].PackageID; got != "com.example.app" {
+		t.Errorf("release notes image package = %q, want com.example.app", got)
+	}
+	if _, ok := refs[orphanHash]; ok {
+		t.Errorf("orphan hash has references: %+v", refs[orphanHash])
+	}
+}
+
+func TestFetchBlobReferencesIncompleteHistory(t *testing.T) {
+	sk := nostr.GeneratePrivateKey()
+	pubkey, _ := nostr.GetPublicKey(sk)
+
+	// More history than the page budget reads from a relay capped at 10 results
+	relay := newCappedRelay(t, 10, assetHistory(t, sk, 250, 1700000000, false))
+	_, err := NewPublisher([]string{relay.URL}).FetchBlobReferences(context.Background(), pubkey, []string{strings.Repeat("a", 64)})
+	if !errors.Is(err, ErrHistoryIncomplete) {
+		t.Fatalf("FetchBlobReferences() error = %v, want ErrHistoryIncomplete", err)
+	}
+}
+
+func TestReadBack(t *testing.T) {
+	readBackDelay = 10 * time.Millisecond
+	t.Cleanup(func() { readBackDelay = 2 * time.Second })
+
+	sk := nostr.GeneratePrivateKey()
+	app := func(d string, createdAt nostr.Timestamp) *nostr.Event {
+		event := &nostr.Event{Kind: KindAppMetadata, Tags: nostr.Tags{{"d", d}}, CreatedAt: createdAt}
+		if err := event.Sign(sk); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+
+	now := nostr.Now()
+	stored := app("com.example.app", now)
+	lost := app("com.example.lost", now)
+	winner := app("com.example.lost", now+60) // another publisher's newer event
+	missing := app("com.example.missing", now)
+
+	relayURL := newMockRelay(t, stored, winner)
+	results := NewPublisher([]string{relayURL}).ReadBack(context.Background(), relayURL,
+		[]*nostr.Event{stored, lost, missing})
+
+	if !results[0].Matches() {
+		t.Errorf("stored event: got %+v, want a match", results[0].Stored)
+	}
+	if results[1].Matches() || results[1].Stored == nil || results[1].Stored.ID != winner.ID {
+		t.Errorf("raced event: stored = %+v, want the newer event %s", results[1].Stored, winner.ID)
+	}
+	if results[2].Matches() || results[2].Stored != nil {
+		t.Errorf("missing event: stored = %+v, want nil", results[2].Stored)
+	}
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/nbd-wtf/go-nostr/nip46"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/keyring"
+	"github.com/zapstore/zsp/internal/netpolicy"
 )
 
 // SignerType represents the type of signer.
@@ -26,6 +29,10 @@ const (
 	SignerNIP07
 )
 
+// DevSecretKey is the built-in test key (hex) used by `zsp publish --dev`.
+// It is publicly known: anything signed with it must never reach a real relay.
+const DevSecretKey = "0000000000000000000000000000000000000000000000000000000000000001"
+
 // Signer handles event signing.
 type Signer interface {
 	// Type returns the signer type.
@@ -43,7 +50,9 @@ type Signer interface {
 
 // SignerOptions contains options for creating a signer.
 type SignerOptions struct {
-	Port int // Custom port for browser signer (0 = default)
+	Port       int                  // Custom port for browser signer, which must be free (0 = default, or the next free port)
+	OnFailover func(message string) // Called when a bunker in a comma-separated list is skipped
+	OnListen   func(url string)     // Called with the browser signer URL once its server is bound
 }
 
 // NewSigner creates a signer from a SIGN_WITH value.
@@ -63,12 +72,21 @@ func NewSignerWithOptions(ctx context.Context, signWith string, opts SignerOptio
 		return NewNpubSigner(signWith)
 	}
 
+	if npub, ok := strings.CutPrefix(signWith, config.KeychainSignerPrefix); ok {
+		return NewKeychainSigner(npub)
+	}
+
+	// Comma-separated bunker URLs: fail over to the next bunker if one is down
+	if urls := ParseBunkerList(signWith); urls != nil {
+		return NewFailoverSigner(ctx, urls, opts.OnFailover)
+	}
+
 	if strings.HasPrefix(signWith, "bunker://") {
 		return NewBunkerSigner(ctx, signWith)
 	}
 
 	if signWith == "browser" {
-		return NewNIP07Signer(ctx, opts.Port)
+		return NewNIP07SignerWithOptions(ctx, NIP07SignerOptions{Port: opts.Port, OnListen: opts.OnListen})
 	}
 
 	// Check if it's a hex private key (pad to 64 hex characters = 32 bytes if shorter)
@@ -83,7 +101,7 @@ func NewSignerWithOptions(ctx context.Context, signWith string, opts SignerOptio
 		return NewNsecSigner(nsec)
 	}
 
-	return nil, fmt.Errorf("invalid SIGN_WITH format: must be nsec1..., npub1..., hex private key, bunker://..., or browser")
+	return nil, fmt.Errorf("invalid SIGN_WITH format: must be nsec1..., npub1..., keychain:npub1..., hex private key, bunker://..., or browser")
 }
 
 // isValidHex checks if a string is valid hexadecimal.
@@ -99,6 +117,26 @@ func isValidHex(s string) bool {
 	return true
 }
 
+// NewKeychainSigner creates a signer from the nsec stored in the OS keyring
+// for npub, checking that the stored key matches.
+func NewKeychainSigner(npub string) (*NsecSigner, error) {
+	if prefix, _, err := nip19.Decode(npub); err != nil || prefix != "npub" {
+		return nil, fmt.Errorf("invalid keychain signer: expected keychain:npub1..., got keychain:%s", npub)
+	}
+	nsec, err := keyring.Get(npub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key from OS keyring: %w", err)
+	}
+	signer, err := NewNsecSigner(nsec)
+	if err != nil {
+		return nil, fmt.Errorf("OS keyring entry for %s: %w", npub, err)
+	}
+	if got, _ := nip19.EncodePublicKey(signer.PublicKey()); got != npub {
+		return nil, fmt.Errorf("OS keyring entry for %s holds the key of %s", npub, got)
+	}
+	return signer, nil
+}
+
 // NsecSigner signs events with a private key.
 type NsecSigner struct {
 	privateKey string // hex
@@ -200,6 +238,11 @@ func NewBunkerSigner(ctx context.Context, bunkerURL string) (*BunkerSigner, erro
 		return nil, fmt.Errorf("invalid bunker URL: %w", err)
 	}
 
+	// The bunker's relays are connected to by nip46, not connectRelay
+	if err := checkBunkerRelays(bunkerURL); err != nil {
+		return nil, err
+	}
+
 	// Get or generate a truly random client secret key for this bunker.
 	// This is persisted to ensure we use the same client key across sessions,
 	// which is necessary because NIP-46 permissions are tied to the client pubkey.
@@ -258,6 +301,21 @@ func extractBunkerTargetPubkey(bunkerURL string) (string, error) {
 	return parsed.Host, nil
 }
 
+// checkBunkerRelays checks the relay= parameters of a bunker URL against the
+// network allowlist.
+func checkBunkerRelays(bunkerURL string) error {
+	parsed, err := url.Parse(bunkerURL)
+	if err != nil {
+		return fmt.Errorf("invalid bunker URL: %w", err)
+	}
+	for _, relay := range parsed.Query()["relay"] {
+		if err := netpolicy.CheckURL(relay); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // getOrCreateBunkerClientKey retrieves an existing client key for a bunker,
 // or generates and persists a new truly random one.
 // Keys are stored in the user's config directory under zsp/bunker-keys/.
@@ -370,6 +428,13 @@ func SignEventSet(ctx context.Context, signer Signer, events *EventSet, relayHin
 		}
 	}
 
+	// 6. Sign the long-form release notes if present (release_notes_event)
+	if events.ReleaseNotes != nil {
+		if err := signer.Sign(ctx, events.ReleaseNotes); err != nil {
+			return fmt.Errorf("failed to sign release notes event: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -395,6 +460,9 @@ func signEventSetBatch(ctx context.Context, batchSigner BatchSigner, events *Eve
 	}
 	allEvents = append(allEvents, events.Release)
 	allEvents = append(allEvents, events.SoftwareAssets...)
+	if events.ReleaseNotes != nil {
+		allEvents = append(allEvents, events.ReleaseNotes)
+	}
 	if err := batchSigner.SignBatch(ctx, allEvents); err != nil {
 		return fmt.Errorf("failed to batch sign events: %w", err)
 	}
@@ -429,5 +497,15 @@ func EventsToJSON(events *EventSet) ([]byte, error) {
 		result = append(result, '\n')
 	}
 
+	// Add the long-form release notes (release_notes_event)
+	if events.ReleaseNotes != nil {
+		data, err := json.Marshal(events.ReleaseNotes)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data...)
+		result = append(result, '\n')
+	}
+
 	return result, nil
 }
@@ -0,0 +1,228 @@
+package nostr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zapstore/zsp/internal/config"
+)
+
+// Limits on event fields. Relays reject oversized events and clients truncate
+// long fields, so BuildEventSet refuses them with an explanation instead.
+const (
+	maxIdentifierLength = 255       // package ID, version
+	maxNameLength       = 100       // app name (runes)
+	maxSummaryLength    = 500       // app summary (runes)
+	maxTagLength        = 64        // category tag (runes)
+	maxLineLength       = 2048      // other single-line fields: URLs, license, commit
+	maxContentLength    = 64 * 1024 // description and changelog (bytes)
+)
+
+// Violation describes one field that breaks a constraint.
+type Violation struct {
+	Field   string // e.g. "summary", "tags[2]"
+	Problem string
+}
+
+// ValidationError lists every constraint the inputs to BuildEventSet violate.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + ": " + v.Problem
+	}
+	return "invalid event data: " + strings.Join(parts, "; ")
+}
+
+// validator accumulates violations.
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) add(field, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Field: field, Problem: fmt.Sprintf(format, args...)})
+}
+
+// required reports an empty or whitespace-only value.
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// line checks a value that goes into a single tag: no control characters
+// (newlines included) and at most maxRunes runes.
+func (v *validator) line(field, value string, maxRunes int) {
+	if strings.IndexFunc(value, unicode.IsControl) >= 0 {
+		v.add(field, "contains a newline or control character")
+	}
+	if n := utf8.RuneCountInString(value); n > maxRunes {
+		v.add(field, "is %d characters, the limit is %d", n, maxRunes)
+	}
+}
+
+// token checks a value that must not contain any whitespace.
+func (v *validator) token(field, value string, maxRunes int) {
+	if strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+		v.add(field, "contains whitespace")
+	} else {
+		v.line(field, value, maxRunes)
+	}
+}
+
+// url checks an optional absolute http(s) URL.
+func (v *validator) url(field, value string) {
+	if value == "" {
+		return
+	}
+	v.token(field, value, maxLineLength)
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(field, "is not an http(s) URL")
+	}
+}
+
+func (v *validator) content(field, value string) {
+	if len(value) > maxContentLength {
+		v.add(field, "is %d bytes, the limit is %d", len(value), maxContentLength)
+	}
+	if !utf8.ValidString(value) {
+		v.add(field, "is not valid UTF-8")
+	}
+}
+
+// metadata checks the config fields that become app event tags and content.
+func (v *validator) metadata(cfg *config.Config, name string) {
+	v.line("name", name, maxNameLength)
+	if n := utf8.RuneCountInString(cfg.Summary); n > maxSummaryLength {
+		v.add("summary", "is %d characters, the limit is %d", n, maxSummaryLength)
+	}
+	v.content("description", cfg.Description)
+	v.content("release_notes_excerpt", cfg.ReleaseNotesExcerpt)
+	v.line("website", cfg.Website, maxLineLength)
+	v.line("repository", cfg.Repository, maxLineLength)
+	v.line("license", cfg.License, maxLineLength)
+
+	for i, tag := range cfg.Tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		if v.required(field, tag) {
+			v.line(field, tag, maxTagLength)
+		}
+	}
+	for i, c := range cfg.Communities {
+		field := fmt.Sprintf("communities[%d]", i)
+		if v.required(field, c) {
+			v.token(field, c, maxLineLength)
+		}
+	}
+	for i, nip := range cfg.SupportedNIPs {
+		field := fmt.Sprintf("supported_nips[%d]", i)
+		if v.required(field, nip) {
+			v.token(field, nip, maxTagLength)
+		}
+	}
+}
+
+// ValidateConfig checks the config fields BuildEventSet will publish against the
+// same limits, without an APK or signer. Fields filled in later from the APK or
+// remote metadata are not covered. It returns a *ValidationError or nil.
+func ValidateConfig(cfg *config.Config) error {
+	var v validator
+	v.metadata(cfg, cfg.Name)
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+// Validate checks the inputs BuildEventSet turns into event tags and content.
+// It returns a *ValidationError listing every violation, or nil.
+func (params BuildEventSetParams) Validate() error {
+	var v validator
+	if params.APKInfo == nil {
+		v.add("apk", "is missing")
+	}
+	if params.Config == nil {
+		v.add("config", "is missing")
+	}
+	if len(params.Pubkey) != 64 || strings.ToLower(params.Pubkey) != params.Pubkey {
+		v.add("pubkey", "must be 64 lowercase hex characters")
+	} else if _, err := hex.DecodeString(params.Pubkey); err != nil {
+		v.add("pubkey", "must be 64 lowercase hex characters")
+	}
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+
+	apkInfo, cfg := params.APKInfo, params.Config
+
+	// Identifiers make up the d and i tags, which must never be empty
+	if v.required("package_id", apkInfo.PackageID) {
+		v.token("package_id", apkInfo.PackageID, maxIdentifierLength)
+	}
+	if v.required("version", apkInfo.VersionName) {
+		v.line("version", apkInfo.VersionName, maxIdentifierLength)
+	}
+	v.token("sha256", apkInfo.SHA256, maxLineLength)
+
+	name := cfg.Name
+	if name == "" {
+		name = apkInfo.Label
+	}
+	v.metadata(cfg, name)
+	v.content("changelog", params.Changelog)
+
+	for i, p := range params.Platforms {
+		field := fmt.Sprintf("platforms[%d]", i)
+		if v.required(field, p) {
+			v.token(field, p, maxIdentifierLength)
+		}
+	}
+
+	v.url("icon", params.IconURL)
+	v.token("icon_blurhash", params.IconBlurhash, maxIdentifierLength)
+	for i, img := range params.ImageURLs {
+		field := fmt.Sprintf("images[%d]", i)
+		if v.required(field, img) {
+			v.url(field, img)
+		}
+	}
+	for i, prov := range params.Provenance {
+		field := fmt.Sprintf("provenance[%d]", i)
+		if v.required(field+".source", prov.Source) {
+			v.token(field+".source", prov.Source, maxIdentifierLength)
+		}
+		v.url(field+".url", prov.URL)
+		if len(prov.SHA256) != 64 || strings.ToLower(prov.SHA256) != prov.SHA256 {
+			v.add(field+".sha256", "must be 64 lowercase hex characters")
+		} else if _, err := hex.DecodeString(prov.SHA256); err != nil {
+			v.add(field+".sha256", "must be 64 lowercase hex characters")
+		}
+	}
+	v.url("url", params.OriginalURL)
+	v.url("blossom_server", params.BlossomServer)
+
+	v.token("channel", params.Channel, maxIdentifierLength)
+	v.line("variant", params.Variant, maxIdentifierLength)
+	v.token("commit", params.Commit, maxLineLength)
+
+	if params.Delegation != nil {
+		if err := params.Delegation.Check(params.Pubkey); err != nil {
+			v.add("delegation", "%s", err)
+		}
+	}
+
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
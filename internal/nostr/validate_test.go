@@ -0,0 +1,155 @@
+package nostr
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/config"
+)
+
+const testPubkey = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func validParams() BuildEventSetParams {
+	return BuildEventSetParams{
+		APKInfo: &apk.APKInfo{PackageID: "com.example.app", VersionName: "1.0.0", VersionCode: 1, SHA256: "abc123"},
+		Config:  &config.Config{Name: "Example", Summary: "An app", Tags: []string{"tools"}},
+		Pubkey:  testPubkey,
+	}
+}
+
+func TestValidateReportsEveryViolation(t *testing.T) {
+	params := validParams()
+	params.APKInfo.PackageID = ""
+	params.Config.Summary = strings.Repeat("x", 10000)
+	params.Config.Tags = []string{"ok", "", "bad\ntag"}
+	params.ImageURLs = []string{"https://cdn.example.com/a.png", ""}
+
+	_, err := BuildEventSet(params)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("BuildEventSet() error = %v, want *ValidationError", err)
+	}
+
+	var fields []string
+	for _, v := range verr.Violations {
+		fields = append(fields, v.Field)
+	}
+	want := []string{"package_id", "summary", "tags[1]", "tags[2]", "images[1]"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("violated fields = %v, want %v", fields, want)
+	}
+	if !strings.Contains(err.Error(), "summary: is 10000 characters, the limit is 500") {
+		t.Errorf("error message %q does not explain the summary limit", err)
+	}
+}
+
+func TestValidateRejectsMissingInputs(t *testing.T) {
+	tests := map[string]func(*BuildEventSetParams){
+		"nil apk":       func(p *BuildEventSetParams) { p.APKInfo = nil },
+		"nil config":    func(p *BuildEventSetParams) { p.Config = nil },
+		"short pubkey":  func(p *BuildEventSetParams) { p.Pubkey = "abc" },
+		"npub pubkey":   func(p *BuildEventSetParams) { p.Pubkey = "npub1" + strings.Repeat("q", 59) },
+		"blank version": func(p *BuildEventSetParams) { p.APKInfo.VersionName = "  " },
+		"relative icon": func(p *BuildEventSetParams) { p.IconURL = "icon.png" },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := validParams()
+			mutate(&params)
+			if _, err := BuildEventSet(params); err == nil {
+				t.Error("BuildEventSet() succeeded, want error")
+			}
+		})
+	}
+}
+
+// adversarialParams generates BuildEventSetParams from a pool of hostile strings.
+type adversarialParams struct {
+	BuildEventSetParams
+}
+
+var hostileStrings = []string{
+	"", " ", "\n", "a\nb", "\x00", "tab\there", "com.example.app", "1.0.0", "ünïcödé", "🚀",
+	"https://cdn.example.com/img.png", "ftp://example.com/x", "//no-scheme", "wss://relay.example.com",
+	strings.Repeat("x", 70), strings.Repeat("y", 600), strings.Repeat("z", 70000), "\xff\xfe",
+}
+
+func (adversarialParams) Generate(r *rand.Rand, _ int) reflect.Value {
+	pick := func() string { return hostileStrings[r.Intn(len(hostileStrings))] }
+	picks := func() []string {
+		out := make([]string, r.Intn(4))
+		for i := range out {
+			out[i] = pick()
+		}
+		return out
+	}
+
+	pubkey := testPubkey
+	if r.Intn(4) == 0 {
+		pubkey = pick()
+	}
+	p := adversarialParams{BuildEventSetParams{
+		APKInfo: &apk.APKInfo{
+			PackageID:     pick(),
+			VersionName:   pick(),
+			Label:         pick(),
+			SHA256:        pick(),
+			FilePath:      pick(),
+			Architectures: picks(),
+		},
+		Config: &config.Config{
+			Name:        pick(),
+			Summary:     pick(),
+			Description: pick(),
+			Website:     pick(),
+			License:     pick(),
+			Repository:  pick(),
+			Tags:        picks(),
+			Communities: picks(),
+		},
+		Pubkey:        pubkey,
+		OriginalURL:   pick(),
+		BlossomServer: pick(),
+		IconURL:       pick(),
+		ImageURLs:     picks(),
+		Changelog:     pick(),
+		Variant:       pick(),
+		Commit:        pick(),
+		Channel:       pick(),
+		Platforms:     picks(),
+	}}
+	return reflect.ValueOf(p)
+}
+
+// TestBuildEventSetAdversarialInputs checks that BuildEventSet never panics and either
+// returns a descriptive error or an event set whose identifying tags are all set.
+func TestBuildEventSetAdversarialInputs(t *testing.T) {
+	property := func(p adversarialParams) bool {
+		events, err := BuildEventSet(p.BuildEventSetParams)
+		if err != nil {
+			var verr *ValidationError
+			return errors.As(err, &verr) && len(verr.Violations) > 0 && verr.Error() != ""
+		}
+		for _, event := range append([]*nostr.Event{events.AppMetadata, events.Release}, events.SoftwareAssets...) {
+			for _, key := range []string{"d", "i"} {
+				if tag := event.Tags.GetFirst([]string{key}); tag != nil && strings.TrimSpace((*tag)[1]) == "" {
+					t.Logf("empty %s tag in kind %d event", key, event.Kind)
+					return false
+				}
+			}
+		}
+		if events.Release.Tags.GetFirst([]string{"d"}) == nil || events.SoftwareAssets[0].Tags.GetFirst([]string{"i"}) == nil {
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
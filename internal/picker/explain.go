@@ -0,0 +1,328 @@
+package picker
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// Selection stages reported in a Rejection.
+const (
+	StageAPK     = "apk"
+	StageMatch   = "match"
+	StageLabel   = "match_label"
+	StageVariant = "variant"
+)
+
+// featureNames maps features to human-readable names for explanations.
+var featureNames = map[Feature]string{
+	FeatureArm64:      "arm64",
+	FeatureFDroid:     "fdroid",
+	FeatureFoss:       "foss",
+	FeatureLibre:      "libre",
+	FeatureOss:        "oss",
+	FeatureRelease:    "release",
+	FeatureUniversal:  "universal",
+	FeatureGoogle:     "google",
+	FeaturePlaystore:  "playstore",
+	FeatureGms:        "gms",
+	FeatureDebug:      "debug",
+	FeatureBeta:       "beta",
+	FeatureAlpha:      "alpha",
+	FeatureRC:         "rc",
+	FeatureX86:        "x86",
+	FeatureX86_64:     "x86_64",
+	FeatureArmeabi:    "armeabi",
+	FeatureArmeabiV7a: "armeabi-v7a",
+}
+
+// String returns the feature name.
+func (f Feature) String() string {
+	if name, ok := featureNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("feature(%d)", int(f))
+}
+
+// Rejection records why an asset was removed during selection.
+type Rejection struct {
+	Asset   *source.Asset `json:"-"`
+	Name    string        `json:"name"`
+	Label   string        `json:"label,omitempty"`
+	Stage   string        `json:"stage"`
+	Reason  string        `json:"reason"`
+	Pattern string        `json:"pattern,omitempty"`
+}
+
+// FeatureContribution is a detected filename feature and its weight.
+type FeatureContribution struct {
+	Feature string  `json:"feature"`
+	Weight  float64 `json:"weight"`
+}
+
+// ExplainedAsset is a ranked candidate with its score breakdown.
+type ExplainedAsset struct {
+	Asset         *source.Asset         `json:"-"`
+	Name          string                `json:"name"`
+	Label         string                `json:"label,omitempty"`
+	Score         float64               `json:"score"`
+	WeightedScore float64               `json:"weighted_score"`
+	Features      []FeatureContribution `json:"features"`
+}
+
+// Explanation is the full decision trail for selecting an APK from a release.
+type Explanation struct {
+	Assets   []string          `json:"assets"`
+	Rejected []Rejection       `json:"rejected"`
+	Ranked   []ExplainedAsset  `json:"ranked"`
+	Selected string            `json:"selected,omitempty"`
+	Variants map[string]string `json:"variants,omitempty"` // APK name to matched variant
+}
+
+// Render formats the explanation for humans: release's assets with their
+// variants, variant patterns that match no APK, the rejections, the ranking
+// with each candidate's features, and the selected APK.
+func (e *Explanation) Render(release *source.Release, variants map[string]string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Release %s (%d assets)\n", release.Version, len(e.Assets))
+	for _, asset := range release.Assets {
+		if variant := e.Variants[asset.Name]; variant != "" {
+			fmt.Fprintf(&b, "  - %s %s\n", assetDisplayName(asset.Name, asset.Label), ui.Dim("(variant: "+variant+")"))
+		} else {
+			fmt.Fprintf(&b, "  - %s\n", assetDisplayName(asset.Name, asset.Label))
+		}
+	}
+	for _, name := range UnmatchedVariants(FilterAPKs(release.Assets), variants) {
+		fmt.Fprintf(&b, "  %s variant %q pattern %q matches no APK\n", ui.Dim("warning:"), name, variants[name])
+	}
+
+	if len(e.Rejected) > 0 {
+		b.WriteString("\nFiltered out:\n")
+		for _, r := range e.Rejected {
+			name := assetDisplayName(r.Name, r.Label)
+			if r.Pattern != "" {
+				fmt.Fprintf(&b, "  - %s [%s] %s: %s\n", name, r.Stage, r.Reason, r.Pattern)
+			} else {
+				fmt.Fprintf(&b, "  - %s [%s] %s\n", name, r.Stage, r.Reason)
+			}
+		}
+	}
+
+	if len(e.Ranked) > 0 {
+		b.WriteString("\nRanked:\n")
+		for i, ea := range e.Ranked {
+			fmt.Fprintf(&b, "  %d. %s (score: %.2f, weighted: %.2f)\n", i+1, assetDisplayName(ea.Name, ea.Label), ea.Score, ea.WeightedScore)
+			for _, fc := range ea.Features {
+				fmt.Fprintf(&b, "       %s %+.1f\n", fc.Feature, fc.Weight)
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	if e.Selected == "" {
+		b.WriteString("Selected: (none)\n")
+	} else if variant := e.Variants[e.Selected]; variant != "" {
+		fmt.Fprintf(&b, "Selected: %s (variant: %s)\n", ui.Bold(e.Selected), variant)
+	} else {
+		fmt.Fprintf(&b, "Selected: %s\n", ui.Bold(e.Selected))
+	}
+	return b.String()
+}
+
+// assetDisplayName formats an asset name with its forge label, when it has one
+// that differs from the name.
+func assetDisplayName(name, label string) string {
+	if label == "" || label == name {
+		return name
+	}
+	return fmt.Sprintf("%s %s", name, ui.Dim("["+label+"]"))
+}
+
+// PartitionAPKs splits assets into APKs and rejections for non-APK files.
+func PartitionAPKs(assets []*source.Asset) ([]*source.Asset, []Rejection) {
+	var kept []*source.Asset
+	var rejected []Rejection
+	for _, asset := range assets {
+		name := strings.ToLower(asset.Name)
+		url := strings.ToLower(asset.URL)
+		if strings.HasSuffix(name, ".apk") || strings.HasSuffix(url, ".apk") {
+			kept = append(kept, asset)
+			continue
+		}
+		reason := "not an .apk file"
+		if asset.ContentType != "" {
+			reason = fmt.Sprintf("not an .apk file (content type %s)", asset.ContentType)
+		}
+		rejected = append(rejected, Rejection{
+			Asset:  asset,
+			Name:   asset.Name,
+			Label:  asset.Label,
+			Stage:  StageAPK,
+			Reason: reason,
+		})
+	}
+	return kept, rejected
+}
+
+// PartitionByMatch splits assets into those matching the regex pattern and rejections.
+func PartitionByMatch(assets []*source.Asset, pattern string) ([]*source.Asset, []Rejection, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid match pattern: %w", err)
+	}
+
+	var kept []*source.Asset
+	var rejected []Rejection
+	for _, asset := range assets {
+		if re.MatchString(asset.Name) {
+			kept = append(kept, asset)
+			continue
+		}
+		rejected = append(rejected, Rejection{
+			Asset:   asset,
+			Name:    asset.Name,
+			Label:   asset.Label,
+			Stage:   StageMatch,
+			Reason:  "name does not match pattern",
+			Pattern: pattern,
+		})
+	}
+	return kept, rejected, nil
+}
+
+// PartitionByLabel splits assets into those whose forge label or ID matches pattern
+// and rejections. An asset matches when its label equals pattern, its ID equals
+// pattern, or pattern is a valid regex that matches its label. Assets without a
+// label only match by ID.
+func PartitionByLabel(assets []*source.Asset, pattern string) ([]*source.Asset, []Rejection) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil // labels like "APK [arm64" are still matched exactly
+	}
+
+	var kept []*source.Asset
+	var rejected []Rejection
+	for _, asset := range assets {
+		if labelMatches(asset, pattern, re) {
+			kept = append(kept, asset)
+			continue
+		}
+		reason := "label does not match"
+		if asset.Label == "" {
+			reason = "asset has no label"
+		}
+		rejected = append(rejected, Rejection{
+			Asset:   asset,
+			Name:    asset.Name,
+			Label:   asset.Label,
+			Stage:   StageLabel,
+			Reason:  reason,
+			Pattern: pattern,
+		})
+	}
+	return kept, rejected
+}
+
+// labelMatches reports whether asset is selected by a match_label pattern.
+func labelMatches(asset *source.Asset, pattern string, re *regexp.Regexp) bool {
+	if asset.ID != 0 && strconv.FormatInt(asset.ID, 10) == pattern {
+		return true
+	}
+	if asset.Label == "" {
+		return false
+	}
+	return asset.Label == pattern || (re != nil && re.MatchString(asset.Label))
+}
+
+// FeatureBreakdown returns the features detected in a filename with their weights.
+func FeatureBreakdown(filename string) []FeatureContribution {
+	features := ExtractFeatures(filename)
+	var out []FeatureContribution
+	for f := Feature(0); f < NumFeatures; f++ {
+		if features[f] == 1.0 {
+			out = append(out, FeatureContribution{Feature: f.String(), Weight: featureWeights[f]})
+		}
+	}
+	return out
+}
+
+// Explain runs the same filtering and ranking as publish and records every decision.
+// The match and matchLabel patterns are optional.
+func (m *Model) Explain(assets []*source.Asset, match, matchLabel string) (*Explanation, error) {
+	return m.ExplainVariants(assets, match, matchLabel, nil, "")
+}
+
+// ExplainVariants is Explain for configs with variants: each APK is tagged with
+// the variant its name matches, and a non-empty variant keeps only the APKs
+// matching that variant's pattern (--variant).
+func (m *Model) ExplainVariants(assets []*source.Asset, match, matchLabel string, variants map[string]string, variant string) (*Explanation, error) {
+	exp := &Explanation{
+		Assets:   make([]string, len(assets)),
+		Rejected: []Rejection{},
+		Ranked:   []ExplainedAsset{},
+	}
+	for i, asset := range assets {
+		exp.Assets[i] = asset.Name
+	}
+
+	candidates, rejected := PartitionAPKs(assets)
+	exp.Rejected = append(exp.Rejected, rejected...)
+
+	if match != "" {
+		var err error
+		candidates, rejected, err = PartitionByMatch(candidates, match)
+		if err != nil {
+			return nil, err
+		}
+		exp.Rejected = append(exp.Rejected, rejected...)
+	}
+
+	if matchLabel != "" {
+		candidates, rejected = PartitionByLabel(candidates, matchLabel)
+		exp.Rejected = append(exp.Rejected, rejected...)
+	}
+
+	if variant != "" {
+		pattern, ok := variants[variant]
+		if !ok {
+			return nil, fmt.Errorf("unknown variant %q", variant)
+		}
+		var err error
+		candidates, rejected, err = PartitionByVariant(candidates, variant, pattern)
+		if err != nil {
+			return nil, err
+		}
+		exp.Rejected = append(exp.Rejected, rejected...)
+	}
+
+	for _, sa := range m.RankAssets(candidates) {
+		exp.Ranked = append(exp.Ranked, ExplainedAsset{
+			Asset:         sa.Asset,
+			Name:          sa.Asset.Name,
+			Label:         sa.Asset.Label,
+			Score:         sa.Score,
+			WeightedScore: ScoreWithWeights(sa.Asset.Name),
+			Features:      FeatureBreakdown(sa.Asset.Name),
+		})
+	}
+	if len(exp.Ranked) > 0 {
+		exp.Selected = exp.Ranked[0].Name
+	}
+
+	if len(variants) > 0 {
+		apks, _ := PartitionAPKs(assets)
+		tagged, err := AssetVariants(apks, variants)
+		if err != nil {
+			return nil, err
+		}
+		if len(tagged) > 0 {
+			exp.Variants = tagged
+		}
+	}
+
+	return exp, nil
+}
@@ -238,13 +238,28 @@ func euclideanDistance(a, b []float64) float64 {
 	return math.Sqrt(sum)
 }
 
+// labelArm64Bonus is added to the score of an asset whose forge label names arm64
+// when its filename does not, so "APK (arm64-v8a)" links with generic filenames
+// win ties against their siblings.
+const labelArm64Bonus = 0.1
+
+// labelBonus returns the score bonus an asset earns from its forge label.
+func labelBonus(asset *source.Asset) float64 {
+	arm64 := featurePatterns[FeatureArm64]
+	if asset.Label != "" && arm64.MatchString(asset.Label) && !arm64.MatchString(asset.Name) {
+		return labelArm64Bonus
+	}
+	return 0
+}
+
 // RankAssets ranks assets by their scores (highest first).
+// Assets whose label names arm64 get a small bonus on top of the filename score.
 func (m *Model) RankAssets(assets []*source.Asset) []ScoredAsset {
 	scored := make([]ScoredAsset, len(assets))
 	for i, asset := range assets {
 		scored[i] = ScoredAsset{
 			Asset: asset,
-			Score: m.Score(asset.Name),
+			Score: m.Score(asset.Name) + labelBonus(asset),
 		}
 	}
 
@@ -268,30 +283,19 @@ func (m *Model) PickBest(assets []*source.Asset) *source.Asset {
 // FilterAPKs filters assets to only include .apk files.
 // Checks both the asset name and URL for .apk extension.
 func FilterAPKs(assets []*source.Asset) []*source.Asset {
-	var apks []*source.Asset
-	for _, asset := range assets {
-		name := strings.ToLower(asset.Name)
-		url := strings.ToLower(asset.URL)
-		if strings.HasSuffix(name, ".apk") || strings.HasSuffix(url, ".apk") {
-			apks = append(apks, asset)
-		}
-	}
+	apks, _ := PartitionAPKs(assets)
 	return apks
 }
 
 // FilterByMatch filters assets using a regex pattern.
 func FilterByMatch(assets []*source.Asset, pattern string) ([]*source.Asset, error) {
-	re, err := regexp.Compile(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("invalid match pattern: %w", err)
-	}
-
-	var matched []*source.Asset
-	for _, asset := range assets {
-		if re.MatchString(asset.Name) {
-			matched = append(matched, asset)
-		}
-	}
-	return matched, nil
+	matched, _, err := PartitionByMatch(assets, pattern)
+	return matched, err
 }
 
+// FilterByLabel filters assets by their forge label or asset ID.
+// See PartitionByLabel for the matching rules.
+func FilterByLabel(assets []*source.Asset, pattern string) []*source.Asset {
+	matched, _ := PartitionByLabel(assets, pattern)
+	return matched
+}
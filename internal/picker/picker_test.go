@@ -1,9 +1,12 @@
 package picker
 
 import (
+	"math"
+	"strings"
 	"testing"
 
 	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/ui"
 )
 
 func TestExtractFeatures(t *testing.T) {
@@ -236,6 +239,221 @@ func TestFilterByMatchInvalidPattern(t *testing.T) {
 	}
 }
 
+func TestFilterByLabel(t *testing.T) {
+	assets := []*source.Asset{
+		{Name: "app-release.apk", Label: "Android (arm64-v8a)", ID: 101},
+		{Name: "app-release-v7.apk", Label: "Android (armeabi-v7a)", ID: 102},
+		{Name: "app-unlabeled.apk", ID: 103},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"exact label", "Android (arm64-v8a)", []string{"app-release.apk"}},
+		{"regex label", `^Android \(armeabi`, []string{"app-release-v7.apk"}},
+		{"asset id", "103", []string{"app-unlabeled.apk"}},
+		{"invalid regex falls back to exact", "Android [", nil},
+		{"no match", "iOS", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched := FilterByLabel(assets, tt.pattern)
+			var got []string
+			for _, a := range matched {
+				got = append(got, a.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FilterByLabel(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+
+	_, rejected := PartitionByLabel(assets, "Android (arm64-v8a)")
+	if len(rejected) != 2 || rejected[1].Reason != "asset has no label" || rejected[0].Stage != StageLabel {
+		t.Errorf("unexpected rejections: %+v", rejected)
+	}
+}
+
+func TestRankAssetsLabelBonus(t *testing.T) {
+	assets := []*source.Asset{
+		{Name: "app-a.apk", Label: "Android"},
+		{Name: "app-b.apk", Label: "Android (arm64-v8a)"},
+	}
+	ranked := DefaultModel.RankAssets(assets)
+	if ranked[0].Asset.Name != "app-b.apk" {
+		t.Errorf("expected the arm64-labelled asset first, got %s", ranked[0].Asset.Name)
+	}
+	if diff := ranked[0].Score - ranked[1].Score; math.Abs(diff-labelArm64Bonus) > 1e-9 {
+		t.Errorf("score difference = %v, want %v", diff, labelArm64Bonus)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	assets := []*source.Asset{
+		{Name: "app-arm64-v8a.apk"},
+		{Name: "app-x86.apk"},
+		{Name: "app-arm64-v8a-debug.apk"},
+		{Name: "checksums.txt", ContentType: "text/plain"},
+	}
+
+	tests := []struct {
+		name         string
+		match        string
+		wantRejected map[string]string // asset name -> stage
+		wantRanked   int
+		wantSelected string
+	}{
+		{
+			name:         "no match pattern",
+			wantRejected: map[string]string{"checksums.txt": StageAPK},
+			wantRanked:   3,
+			wantSelected: "app-arm64-v8a.apk",
+		},
+		{
+			name:  "match pattern removes assets",
+			match: `arm64`,
+			wantRejected: map[string]string{
+				"checksums.txt": StageAPK,
+				"app-x86.apk":   StageMatch,
+			},
+			wantRanked:   2,
+			wantSelected: "app-arm64-v8a.apk",
+		},
+		{
+			name:  "match pattern removes everything",
+			match: `riscv`,
+			wantRejected: map[string]string{
+				"checksums.txt":           StageAPK,
+				"app-arm64-v8a.apk":       StageMatch,
+				"app-x86.apk":             StageMatch,
+				"app-arm64-v8a-debug.apk": StageMatch,
+			},
+			wantRanked:   0,
+			wantSelected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp, err := DefaultModel.Explain(assets, tt.match, "")
+			if err != nil {
+				t.Fatalf("Explain error: %v", err)
+			}
+
+			if len(exp.Assets) != len(assets) {
+				t.Errorf("expected %d assets, got %d", len(assets), len(exp.Assets))
+			}
+			if len(exp.Rejected) != len(tt.wantRejected) {
+				t.Errorf("expected %d rejections, got %d", len(tt.wantRejected), len(exp.Rejected))
+			}
+			for _, r := range exp.Rejected {
+				if stage, ok := tt.wantRejected[r.Name]; !ok || stage != r.Stage {
+					t.Errorf("unexpected rejection %s at stage %s", r.Name, r.Stage)
+				}
+				if r.Stage == StageMatch && r.Pattern != tt.match {
+					t.Errorf("rejection %s: expected pattern %q, got %q", r.Name, tt.match, r.Pattern)
+				}
+			}
+			if len(exp.Ranked) != tt.wantRanked {
+				t.Errorf("expected %d ranked, got %d", tt.wantRanked, len(exp.Ranked))
+			}
+			if exp.Selected != tt.wantSelected {
+				t.Errorf("expected selected %q, got %q", tt.wantSelected, exp.Selected)
+			}
+		})
+	}
+}
+
+func TestAssetVariants(t *testing.T) {
+	assets := []*source.Asset{
+		{Name: "app-fdroid-1.0.apk"},
+		{Name: "app-google-1.0.apk"},
+		{Name: "app-1.0.apk"},
+	}
+	variants := map[string]string{
+		"fdroid": `-fdroid-`,
+		"google": `-google-`,
+		"huawei": `-huawei-`,
+	}
+
+	got, err := AssetVariants(assets, variants)
+	if err != nil {
+		t.Fatalf("AssetVariants error: %v", err)
+	}
+	want := map[string]string{"app-fdroid-1.0.apk": "fdroid", "app-google-1.0.apk": "google"}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for name, variant := range want {
+		if got[name] != variant {
+			t.Errorf("%s: got variant %q, want %q", name, got[name], variant)
+		}
+	}
+
+	if unmatched := UnmatchedVariants(assets, variants); len(unmatched) != 1 || unmatched[0] != "huawei" {
+		t.Errorf("UnmatchedVariants = %v, want [huawei]", unmatched)
+	}
+
+	// Overlapping patterns make the variant ambiguous
+	variants["all"] = `\.apk$`
+	_, err = AssetVariants(assets, variants)
+	if err == nil || !strings.Contains(err.Error(), "app-fdroid-1.0.apk") || !strings.Contains(err.Error(), "must not overlap") {
+		t.Errorf("expected overlap error naming the asset, got %v", err)
+	}
+}
+
+func TestExplainVariants(t *testing.T) {
+	assets := []*source.Asset{
+		{Name: "app-fdroid-arm64-v8a.apk"},
+		{Name: "app-google-arm64-v8a.apk"},
+		{Name: "app-google-x86.apk"},
+	}
+	variants := map[string]string{"fdroid": `-fdroid-`, "google": `-google-`}
+
+	exp, err := DefaultModel.ExplainVariants(assets, `arm64`, "", variants, "google")
+	if err != nil {
+		t.Fatalf("ExplainVariants error: %v", err)
+	}
+	if exp.Selected != "app-google-arm64-v8a.apk" {
+		t.Errorf("expected google build selected, got %q", exp.Selected)
+	}
+	stages := make(map[string]string)
+	for _, r := range exp.Rejected {
+		stages[r.Name] = r.Stage
+	}
+	if stages["app-google-x86.apk"] != StageMatch || stages["app-fdroid-arm64-v8a.apk"] != StageVariant {
+		t.Errorf("unexpected rejections: %v", stages)
+	}
+	if exp.Variants["app-fdroid-arm64-v8a.apk"] != "fdroid" || exp.Variants["app-google-x86.apk"] != "google" {
+		t.Errorf("expected every APK tagged with its variant, got %v", exp.Variants)
+	}
+
+	if _, err := DefaultModel.ExplainVariants(assets, "", "", variants, "huawei"); err == nil {
+		t.Error("expected error for unknown variant")
+	}
+}
+
+func TestFeatureBreakdown(t *testing.T) {
+	breakdown := FeatureBreakdown("app-arm64-v8a-debug.apk")
+
+	got := make(map[string]float64)
+	for _, fc := range breakdown {
+		got[fc.Feature] = fc.Weight
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 features, got %v", got)
+	}
+	if got["arm64"] != featureWeights[FeatureArm64] {
+		t.Errorf("arm64 weight = %v, want %v", got["arm64"], featureWeights[FeatureArm64])
+	}
+	if got["debug"] != featureWeights[FeatureDebug] {
+		t.Errorf("debug weight = %v, want %v", got["debug"], featureWeights[FeatureDebug])
+	}
+}
+
 func TestTrainingDataLoaded(t *testing.T) {
 	if DefaultModel == nil {
 		t.Fatal("DefaultModel is nil")
@@ -538,3 +756,30 @@ func TestFilterAPKsEdgeCases(t *testing.T) {
 	}
 }
 
+func TestExplanationRender(t *testing.T) {
+	ui.SetNoColor(true)
+	release := &source.Release{Version: "1.2.0", Assets: []*source.Asset{
+		{Name: "app-fdroid-arm64-v8a.apk"},
+		{Name: "app-google-x86.apk"},
+		{Name: "checksums.txt"},
+	}}
+	variants := map[string]string{"fdroid": `-fdroid-`, "huawei": `-huawei-`}
+
+	exp, err := DefaultModel.ExplainVariants(release.Assets, `arm64`, "", variants, "fdroid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := exp.Render(release, variants)
+	for _, want := range []string{
+		"Release 1.2.0 (3 assets)",
+		"app-fdroid-arm64-v8a.apk (variant: fdroid)",
+		`variant "huawei" pattern "-huawei-" matches no APK`,
+		"Filtered out:",
+		"Ranked:",
+		"Selected: app-fdroid-arm64-v8a.apk (variant: fdroid)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package picker
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/zapstore/zsp/internal/source"
+)
+
+// compileVariants compiles variant patterns, keyed by variant name.
+func compileVariants(variants map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp, len(variants))
+	for name, pattern := range variants {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variant %q pattern: %w", name, err)
+		}
+		compiled[name] = re
+	}
+	return compiled, nil
+}
+
+// AssetVariants maps each asset name to the variant whose pattern matches it.
+// Assets matching no variant are left out. Two variants matching the same asset
+// is an error, since the release could not be tagged unambiguously.
+func AssetVariants(assets []*source.Asset, variants map[string]string) (map[string]string, error) {
+	compiled, err := compileVariants(variants)
+	if err != nil {
+		return nil, err
+	}
+	names := slices.Sorted(maps.Keys(compiled))
+
+	result := make(map[string]string)
+	for _, asset := range assets {
+		var matched []string
+		for _, name := range names {
+			if compiled[name].MatchString(asset.Name) {
+				matched = append(matched, name)
+			}
+		}
+		switch len(matched) {
+		case 0:
+		case 1:
+			result[asset.Name] = matched[0]
+		default:
+			return nil, fmt.Errorf("%s matches variants %q and %q; variant patterns must not overlap",
+				asset.Name, matched[0], matched[1])
+		}
+	}
+	return result, nil
+}
+
+// UnmatchedVariants returns the sorted names of variants whose pattern matches
+// none of the assets, which usually means the pattern no longer fits the
+// release's file names.
+func UnmatchedVariants(assets []*source.Asset, variants map[string]string) []string {
+	var unmatched []string
+	for _, name := range slices.Sorted(maps.Keys(variants)) {
+		re, err := regexp.Compile(variants[name])
+		if err != nil {
+			continue
+		}
+		if !slices.ContainsFunc(assets, func(a *source.Asset) bool { return re.MatchString(a.Name) }) {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return unmatched
+}
+
+// PartitionByVariant splits assets into those matching the named variant's
+// pattern and rejections (--variant).
+func PartitionByVariant(assets []*source.Asset, name, pattern string) ([]*source.Asset, []Rejection, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid variant %q pattern: %w", name, err)
+	}
+
+	var kept []*source.Asset
+	var rejected []Rejection
+	for _, asset := range assets {
+		if re.MatchString(asset.Name) {
+			kept = append(kept, asset)
+			continue
+		}
+		rejected = append(rejected, Rejection{
+			Asset:   asset,
+			Name:    asset.Name,
+			Label:   asset.Label,
+			Stage:   StageVariant,
+			Reason:  fmt.Sprintf("name does not match variant %q", name),
+			Pattern: pattern,
+		})
+	}
+	return kept, rejected, nil
+}
@@ -0,0 +1,205 @@
+// Package publock serializes publishes of the same package across zsp
+// processes, such as two webhook-triggered runs seconds apart, with a lock
+// file per package in the zsp cache dir. A lock whose process is gone, or that
+// is older than StaleAfter, is taken over.
+package publock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// StaleAfter is how old a lock must be before it is taken over even though its
+// process still seems to run (the pid may have been reused).
+const StaleAfter = 2 * time.Hour
+
+// ErrLocked is returned when another publish of the package holds the lock.
+var ErrLocked = errors.New("another publish of this package is in progress")
+
+// pollInterval is how often a waiting Acquire retries. Replaced in tests.
+var pollInterval = 500 * time.Millisecond
+
+// Owner describes the process holding a lock.
+type Owner struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (o Owner) String() string {
+	if o.PID == 0 {
+		return "holder unknown"
+	}
+	return fmt.Sprintf("pid %d on %s since %s", o.PID, o.Host, o.StartedAt.Local().Format("15:04:05"))
+}
+
+// Lock is a held package lock.
+type Lock struct {
+	path  string
+	owner []byte // file content written at acquisition
+}
+
+// Dir returns the directory holding the lock files.
+func Dir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "zsp", "locks")
+}
+
+// Acquire takes the lock for packageID in dir, waiting up to wait for another
+// publish to release it; onWait is called once, with the holder, when it has to
+// wait. It reports whether it waited, so the caller can re-check what the other
+// publish did. When the lock is still held after wait (immediately for a zero
+// wait), the error wraps ErrLocked.
+func Acquire(ctx context.Context, dir, packageID string, wait time.Duration, onWait func(Owner)) (*Lock, bool, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, false, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	path := filepath.Join(dir, lockName(packageID))
+	deadline := time.Now().Add(wait)
+	waited := false
+	for {
+		lock, owner, err := tryAcquire(path)
+		if lock != nil || err != nil {
+			return lock, waited, err
+		}
+		if !time.Now().Before(deadline) {
+			if waited {
+				return nil, true, fmt.Errorf("%w (%s), still held after waiting %s", ErrLocked, owner, wait)
+			}
+			return nil, false, fmt.Errorf("%w (%s)", ErrLocked, owner)
+		}
+		if !waited && onWait != nil {
+			onWait(owner)
+		}
+		waited = true
+		select {
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		case <-time.After(min(pollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// tryAcquire creates the lock file, taking over a stale one. When another
+// process holds the lock, it returns its owner and no lock.
+func tryAcquire(path string) (*Lock, Owner, error) {
+	for attempt := 0; attempt < 3; attempt++ {
+		host, _ := os.Hostname()
+		content, _ := json.Marshal(Owner{PID: os.Getpid(), Host: host, StartedAt: time.Now()})
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_, werr := f.Write(content)
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+			if werr != nil {
+				os.Remove(path)
+				return nil, Owner{}, fmt.Errorf("failed to write lock file: %w", werr)
+			}
+			return &Lock{path: path, owner: content}, Owner{}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, Owner{}, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		held, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue // released in between
+		}
+		if err != nil {
+			return nil, Owner{}, fmt.Errorf("failed to read lock file: %w", err)
+		}
+		var owner Owner
+		if json.Unmarshal(held, &owner) != nil {
+			// Being written by its owner, or left truncated by a crash: judge by age
+			info, statErr := os.Stat(path)
+			if statErr != nil || time.Since(info.ModTime()) < time.Minute {
+				return nil, Owner{}, nil
+			}
+		} else if !stale(owner) {
+			return nil, owner, nil
+		}
+		removeStale(path, held)
+	}
+	return nil, Owner{}, fmt.Errorf("failed to take over stale lock file %s", path)
+}
+
+// stale reports whether owner's lock can be taken over.
+func stale(owner Owner) bool {
+	if time.Since(owner.StartedAt) > StaleAfter {
+		return true
+	}
+	host, _ := os.Hostname()
+	return owner.Host == host && !processAlive(owner.PID)
+}
+
+// removeStale removes the stale lock file at path, unless another process has
+// already replaced it with a lock other than held. The file is renamed aside
+// first so that only one of several processes finding the same stale lock
+// removes it.
+func removeStale(path string, held []byte) {
+	aside := fmt.Sprintf("%s.stale-%d", path, os.Getpid())
+	if os.Rename(path, aside) != nil {
+		return
+	}
+	if content, err := os.ReadFile(aside); err == nil && string(content) != string(held) {
+		// Another process took over between reading and renaming: put its lock back
+		if os.Link(aside, path) == nil {
+			os.Remove(aside)
+		}
+		return
+	}
+	os.Remove(aside)
+}
+
+// processAlive reports whether a process with pid runs on this host. Where that
+// cannot be checked, the process is assumed to run and only StaleAfter applies.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
+
+// Release removes the lock file, if it still holds this lock.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	content, err := os.ReadFile(l.path)
+	if err != nil || string(content) != string(l.owner) {
+		return nil // taken over as stale
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
+
+// lockName returns the lock file name for packageID.
+func lockName(packageID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, packageID)
+	return name + ".lock"
+}
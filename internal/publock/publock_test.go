@@ -0,0 +1,154 @@
+package publock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAcquireExclusive(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	lock, waited, err := Acquire(ctx, dir, "com.example.app", 0, nil)
+	if err != nil || waited {
+		t.Fatalf("Acquire() = %v, waited %v", err, waited)
+	}
+	if _, _, err := Acquire(ctx, dir, "com.example.app", 0, nil); !errors.Is(err, ErrLocked) {
+		t.Fatalf("second Acquire() error = %v, want ErrLocked", err)
+	}
+	other, _, err := Acquire(ctx, dir, "com.example.other", 0, nil)
+	if err != nil {
+		t.Fatalf("Acquire() of another package: %v", err)
+	}
+	other.Release()
+
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := Acquire(ctx, dir, "com.example.app", 0, nil)
+	if err != nil {
+		t.Fatalf("Acquire() after Release: %v", err)
+	}
+	again.Release()
+}
+
+func TestAcquireConcurrent(t *testing.T) {
+	old := pollInterval
+	pollInterval = 5 * time.Millisecond
+	defer func() { pollInterval = old }()
+
+	dir := t.TempDir()
+	var mu sync.Mutex
+	held, maxHeld := 0, 0
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, _, err := Acquire(context.Background(), dir, "com.example.app", 10*time.Second, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			held++
+			maxHeld = max(maxHeld, held)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			held--
+			mu.Unlock()
+			lock.Release()
+		}()
+	}
+	wg.Wait()
+
+	if maxHeld != 1 {
+		t.Errorf("%d publishes held the lock at once, want 1", maxHeld)
+	}
+}
+
+func TestAcquireWaits(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	lock, _, err := Acquire(ctx, dir, "com.example.app", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var holder Owner
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Release()
+	}()
+	second, waited, err := Acquire(ctx, dir, "com.example.app", 5*time.Second, func(o Owner) { holder = o })
+	if err != nil || !waited {
+		t.Fatalf("Acquire() = %v, waited %v; want the lock after waiting", err, waited)
+	}
+	second.Release()
+	if holder.PID != os.Getpid() {
+		t.Errorf("onWait holder pid = %d, want %d", holder.PID, os.Getpid())
+	}
+
+	third, _, _ := Acquire(ctx, dir, "com.example.app", 0, nil)
+	defer third.Release()
+	_, waited, err = Acquire(ctx, dir, "com.example.app", 20*time.Millisecond, nil)
+	if !errors.Is(err, ErrLocked) || !waited {
+		t.Errorf("Acquire() past the wait = %v, waited %v; want ErrLocked", err, waited)
+	}
+}
+
+func TestAcquireTakesOverStaleLock(t *testing.T) {
+	host, _ := os.Hostname()
+	tests := map[string]Owner{
+		"dead process": {PID: deadPID(t), Host: host, StartedAt: time.Now()},
+		"too old":      {PID: os.Getpid(), Host: "elsewhere", StartedAt: time.Now().Add(-StaleAfter - time.Minute)},
+	}
+	for name, owner := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			data, _ := json.Marshal(owner)
+			if err := os.WriteFile(filepath.Join(dir, lockName("com.example.app")), data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			lock, _, err := Acquire(context.Background(), dir, "com.example.app", 0, nil)
+			if err != nil {
+				t.Fatalf("Acquire() over stale lock: %v", err)
+			}
+			lock.Release()
+		})
+	}
+
+	// A recent lock on another host cannot be checked and is respected
+	dir := t.TempDir()
+	data, _ := json.Marshal(Owner{PID: 1, Host: "elsewhere", StartedAt: time.Now()})
+	os.WriteFile(filepath.Join(dir, lockName("com.example.app")), data, 0644)
+	if _, _, err := Acquire(context.Background(), dir, "com.example.app", 0, nil); !errors.Is(err, ErrLocked) {
+		t.Errorf("Acquire() over another host's lock = %v, want ErrLocked", err)
+	}
+}
+
+// deadPID returns the pid of a process that has exited.
+func deadPID(t *testing.T) int {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Skip(err)
+	}
+	proc, err := os.StartProcess(exe, []string{exe, "-test.run=^$"}, &os.ProcAttr{})
+	if err != nil {
+		t.Skip(err)
+	}
+	state, err := proc.Wait()
+	if err != nil {
+		t.Skip(err)
+	}
+	return state.Pid()
+}
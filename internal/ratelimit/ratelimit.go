@@ -0,0 +1,190 @@
+// Package ratelimit throttles transfer streams to bandwidth limits, one for
+// downloads and one for uploads, which may be a single limit shared by both.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvLimitRate is the environment variable holding the default bandwidth limit.
+const EnvLimitRate = "ZSP_LIMIT_RATE"
+
+// maxChunk caps the bytes read per Read so waits stay short and transfers interleave fairly.
+const maxChunk = 32 * 1024
+
+// Bucket is a token bucket shared by every stream it wraps, so concurrent
+// transfers together stay within its rate.
+type Bucket struct {
+	rate   float64 // bytes per second
+	burst  float64 // bucket capacity in bytes
+	chunk  int     // largest read passed to the wrapped reader
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewBucket returns a bucket allowing bytesPerSec bytes per second.
+func NewBucket(bytesPerSec int64) *Bucket {
+	rate := float64(bytesPerSec)
+	// Keep each read to about a quarter second of budget, so a slow limit
+	// does not hold a single read long enough to trip stall timeouts.
+	chunk := min(maxChunk, max(1, int(bytesPerSec/4)))
+	return &Bucket{
+		rate:   rate,
+		burst:  float64(chunk),
+		chunk:  chunk,
+		tokens: float64(chunk),
+		last:   time.Now(),
+	}
+}
+
+// wait takes n bytes from the bucket, blocking until the rate allows them.
+func (b *Bucket) wait(ctx context.Context, n int) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// Reader wraps r so reads from it draw from the bucket.
+func (b *Bucket) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &reader{ctx: ctx, r: r, bucket: b}
+}
+
+// reader is a rate-limited io.Reader.
+type reader struct {
+	ctx    context.Context
+	r      io.Reader
+	bucket *Bucket
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > r.bucket.chunk {
+		p = p[:r.bucket.chunk]
+	}
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := r.bucket.wait(r.ctx, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Direction is the way a transfer flows. Each direction has its own limit,
+// which may be one bucket shared by both (SetLimit).
+type Direction int
+
+const (
+	Download Direction = iota // APK downloads from release sources
+	Upload                    // Blossom uploads
+)
+
+var (
+	mu      sync.RWMutex
+	buckets [2]*Bucket
+	limits  [2]int64
+)
+
+// SetLimit installs one bandwidth limit shared by downloads and uploads
+// (--limit-rate). Zero removes it.
+func SetLimit(bytesPerSec int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	var b *Bucket
+	if bytesPerSec > 0 {
+		b = NewBucket(bytesPerSec)
+	}
+	buckets = [2]*Bucket{b, b}
+	limits = [2]int64{bytesPerSec, bytesPerSec}
+}
+
+// SetDirectionLimit installs a bandwidth limit for one direction only
+// (--max-download-rate, --max-upload-rate), replacing the shared limit for
+// it. Zero removes it.
+func SetDirectionLimit(dir Direction, bytesPerSec int64) {
+	mu.Lock()
+	defer mu.Unlock()
+	buckets[dir] = nil
+	if bytesPerSec > 0 {
+		buckets[dir] = NewBucket(bytesPerSec)
+	}
+	limits[dir] = bytesPerSec
+}
+
+// Limit returns the active limit for dir in bytes per second, or zero when unlimited.
+func Limit(dir Direction) int64 {
+	mu.RLock()
+	defer mu.RUnlock()
+	return limits[dir]
+}
+
+// Reader wraps r with the limit for dir. Without a limit, r is returned unchanged.
+func Reader(ctx context.Context, dir Direction, r io.Reader) io.Reader {
+	mu.RLock()
+	b := buckets[dir]
+	mu.RUnlock()
+	if b == nil {
+		return r
+	}
+	return b.Reader(ctx, r)
+}
+
+// ParseRate parses a rate such as "2M", "500k" or "1048576" into bytes per second.
+// K, M and G are powers of 1024, as in curl's --limit-rate; a trailing "B" or
+// "/s" is accepted.
+func ParseRate(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	v = strings.TrimSuffix(v, "/S")
+	v = strings.TrimSuffix(v, "B")
+
+	multiplier := 1.0
+	if v != "" {
+		switch v[len(v)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			v = v[:len(v)-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || !(n > 0) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid rate %q: use a positive number of bytes per second, optionally with K, M or G (e.g. 2M)", s)
+	}
+	bytesPerSec := int64(n * multiplier)
+	if bytesPerSec < 1 {
+		return 0, fmt.Errorf("invalid rate %q: below 1 byte per second", s)
+	}
+	return bytesPerSec, nil
+}
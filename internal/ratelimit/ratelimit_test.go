@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"2M", 2 << 20},
+		{"500k", 500 << 10},
+		{"1.5M", 3 << 19},
+		{"1G", 1 << 30},
+		{"1048576", 1 << 20},
+		{"2MB", 2 << 20},
+		{"2M/s", 2 << 20},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "fast", "-1M", "0", "M", "inf", "NaN", "2T"} {
+		if _, err := ParseRate(in); err == nil {
+			t.Errorf("ParseRate(%q) succeeded, want an error", in)
+		}
+	}
+}
+
+// TestBucketThrottles copies 384 KiB through a 1 MiB/s limit. The first 32 KiB
+// pass as burst, so the copy takes about a third of a second: long enough to
+// show the limiter engaged, short enough to keep the test fast.
+func TestBucketThrottles(t *testing.T) {
+	const size = 384 << 10
+	b := NewBucket(1 << 20)
+
+	start := time.Now()
+	n, err := io.Copy(io.Discard, b.Reader(context.Background(), bytes.NewReader(make([]byte, size))))
+	elapsed := time.Since(start)
+	if err != nil || n != size {
+		t.Fatalf("copied %d bytes, err %v; want %d", n, err, size)
+	}
+	if elapsed < 250*time.Millisecond {
+		t.Errorf("copy took %v, want the limit to slow it to about 330ms", elapsed)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("copy took %v, far slower than the limit allows", elapsed)
+	}
+}
+
+// TestBucketSharedAcrossStreams checks that concurrent streams split one budget
+// rather than each getting the full rate.
+func TestBucketSharedAcrossStreams(t *testing.T) {
+	const size = 96 << 10
+	b := NewBucket(1 << 20)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			io.Copy(io.Discard, b.Reader(context.Background(), bytes.NewReader(make([]byte, size))))
+		}()
+	}
+	wg.Wait()
+
+	// 384 KiB in total: about 330ms shared, about 60ms if each stream had its own budget
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("concurrent copies took %v, want them to share the limit (about 330ms)", elapsed)
+	}
+}
+
+func TestReaderWithoutLimit(t *testing.T) {
+	SetLimit(0)
+	r := bytes.NewReader(nil)
+	if got := Reader(context.Background(), Download, r); got != io.Reader(r) {
+		t.Error("Reader() should return the reader unchanged without a limit")
+	}
+
+	SetLimit(1 << 20)
+	t.Cleanup(func() { SetLimit(0) })
+	for _, dir := range []Direction{Download, Upload} {
+		if got := Reader(context.Background(), dir, r); got == io.Reader(r) {
+			t.Errorf("Reader(%d) should wrap the reader when a limit is set", dir)
+		}
+		if Limit(dir) != 1<<20 {
+			t.Errorf("Limit(%d) = %d, want %d", dir, Limit(dir), 1<<20)
+		}
+	}
+}
+
+func TestSetDirectionLimit(t *testing.T) {
+	t.Cleanup(func() { SetLimit(0) })
+	SetLimit(1 << 20)
+	SetDirectionLimit(Download, 5<<20)
+	if Limit(Download) != 5<<20 || Limit(Upload) != 1<<20 {
+		t.Errorf("Limit(Download) = %d, Limit(Upload) = %d; want %d, %d", Limit(Download), Limit(Upload), 5<<20, 1<<20)
+	}
+
+	SetLimit(0)
+	SetDirectionLimit(Upload, 512<<10)
+	r := bytes.NewReader(nil)
+	if got := Reader(context.Background(), Download, r); got != io.Reader(r) {
+		t.Error("downloads should not be limited by an upload limit")
+	}
+	if got := Reader(context.Background(), Upload, r); got == io.Reader(r) {
+		t.Error("uploads should be limited")
+	}
+}
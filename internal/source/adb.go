@@ -0,0 +1,93 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/tempdir"
+)
+
+// ADBScheme prefixes a positional argument naming a package installed on a device
+// connected over adb (e.g. adb://com.example.app).
+const ADBScheme = "adb://"
+
+// androidPackageRegex matches valid Android application IDs.
+var androidPackageRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z][A-Za-z0-9_]*)+$`)
+
+// IsADBRef reports whether arg is an adb://<package> reference.
+func IsADBRef(arg string) bool {
+	return strings.HasPrefix(arg, ADBScheme)
+}
+
+// PullFromADB copies the installed APK for ref (adb://<package>) from the connected
+// device into a new temporary directory. The caller must call cleanup when done.
+// Set ANDROID_SERIAL to pick a device when several are connected.
+func PullFromADB(ctx context.Context, ref string) (apkPath string, cleanup func(), err error) {
+	packageID := strings.TrimSuffix(strings.TrimPrefix(ref, ADBScheme), "/")
+	if !androidPackageRegex.MatchString(packageID) {
+		return "", nil, fmt.Errorf("invalid package name %q in %s", packageID, ref)
+	}
+
+	adb, err := exec.LookPath("adb")
+	if err != nil {
+		return "", nil, fmt.Errorf("adb not found on PATH (install Android platform-tools to publish from a device)")
+	}
+
+	out, err := runADB(ctx, adb, "shell", "pm", "path", packageID)
+	if err != nil {
+		return "", nil, err
+	}
+	paths := parsePMPath(out)
+	switch {
+	case len(paths) == 0:
+		return "", nil, fmt.Errorf("package %s is not installed on the device", packageID)
+	case len(paths) > 1:
+		return "", nil, fmt.Errorf("package %s is installed as %d split APKs; split APKs are not supported, publish a universal APK instead", packageID, len(paths))
+	}
+
+	dir, err := os.MkdirTemp(tempdir.Dir(), "adb-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	apkPath = filepath.Join(dir, packageID+".apk")
+	if _, err := runADB(ctx, adb, "pull", paths[0], apkPath); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return apkPath, cleanup, nil
+}
+
+// runADB runs an adb command and returns its stdout.
+func runADB(ctx context.Context, adb string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, adb, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("adb %s failed: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("adb %s failed: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// parsePMPath extracts APK paths from `pm path` output ("package:/data/app/.../base.apk" lines).
+func parsePMPath(out []byte) []string {
+	var paths []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if p, ok := strings.CutPrefix(line, "package:"); ok && p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,101 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParsePMPath(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want []string
+	}{
+		{"single", "package:/data/app/com.example-1/base.apk\n", []string{"/data/app/com.example-1/base.apk"}},
+		{"split", "package:/data/app/x/base.apk\r\npackage:/data/app/x/split_config.arm64_v8a.apk\r\n", []string{"/data/app/x/base.apk", "/data/app/x/split_config.arm64_v8a.apk"}},
+		{"not installed", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePMPath([]byte(tt.out)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePMPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeADB installs a shell script named adb on PATH that answers `pm path` with pmOutput
+// and copies a placeholder file on `pull`.
+func fakeADB(t *testing.T, pmOutput string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake adb script requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"case \"$1\" in\n" +
+		"shell) printf '%s' '" + pmOutput + "' ;;\n" +
+		"pull) echo apk > \"$3\" ;;\n" +
+		"esac\n"
+	if err := os.WriteFile(filepath.Join(dir, "adb"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestPullFromADB(t *testing.T) {
+	fakeADB(t, "package:/data/app/com.example.app-1/base.apk\n")
+
+	apkPath, cleanup, err := PullFromADB(context.Background(), "adb://com.example.app")
+	if err != nil {
+		t.Fatalf("PullFromADB() error: %v", err)
+	}
+	defer cleanup()
+
+	if filepath.Base(apkPath) != "com.example.app.apk" {
+		t.Errorf("apkPath = %q, want com.example.app.apk", apkPath)
+	}
+	if data, err := os.ReadFile(apkPath); err != nil || strings.TrimSpace(string(data)) != "apk" {
+		t.Errorf("pulled file = %q, %v", data, err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(apkPath); !os.IsNotExist(err) {
+		t.Errorf("cleanup did not remove %s", apkPath)
+	}
+}
+
+func TestPullFromADBErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		ref      string
+		pmOutput string
+		wantErr  string
+	}{
+		{"invalid package", "adb://not a package", "", "invalid package name"},
+		{"not installed", "adb://com.example.app", "", "not installed"},
+		{"split APKs", "adb://com.example.app", "package:/a/base.apk\npackage:/a/split_config.arm64_v8a.apk\n", "split APKs"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeADB(t, tt.pmOutput)
+			_, _, err := PullFromADB(context.Background(), tt.ref)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("PullFromADB() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("adb missing", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		_, _, err := PullFromADB(context.Background(), "adb://com.example.app")
+		if err == nil || !strings.Contains(err.Error(), "adb not found") {
+			t.Errorf("PullFromADB() error = %v, want adb not found", err)
+		}
+	})
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -32,7 +34,12 @@ type fastlaneEntry struct {
 // fetchFastlaneMetadata fetches Android store metadata maintained alongside the
 // source repository. GitHub, GitLab, and Gitea-compatible forges (Codeberg,
 // Forgejo, self-hosted Gitea) are supported.
+// A fastlane/metadata/android directory next to the config is read from disk
+// instead, and also supplies changelogs/<versionCode>.txt as release notes.
 func (f *MetadataFetcher) fetchFastlaneMetadata(ctx context.Context) (*AppMetadata, error) {
+	if hasLocalFastlane(f.cfg) {
+		return f.fetchLocalFastlaneMetadata()
+	}
 	switch repositoryMetadataHost(f.cfg) {
 	case config.SourceGitHub:
 		return f.fetchGitHubFastlaneMetadata(ctx)
@@ -45,6 +52,89 @@ func (f *MetadataFetcher) fetchFastlaneMetadata(ctx context.Context) (*AppMetada
 	}
 }
 
+// hasLocalFastlane reports whether cfg's BaseDir contains a Fastlane Android
+// metadata directory.
+func hasLocalFastlane(cfg *config.Config) bool {
+	if cfg == nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(cfg.BaseDir, fastlaneMetadataPath))
+	return err == nil && info.IsDir()
+}
+
+// fetchLocalFastlaneMetadata reads fastlane/metadata/android under BaseDir.
+// Icon, screenshot and release notes paths are relative to BaseDir, like the
+// paths written in a config.
+func (f *MetadataFetcher) fetchLocalFastlaneMetadata() (*AppMetadata, error) {
+	listDirectory := func(path string) ([]fastlaneEntry, error) {
+		return listLocalFastlaneDir(f.cfg.BaseDir, path)
+	}
+	root, err := listDirectory(fastlaneMetadataPath)
+	if err != nil {
+		return nil, err
+	}
+	locale, err := selectFastlaneLocale(root)
+	if err != nil {
+		return nil, err
+	}
+	basePath := fastlaneMetadataPath + "/" + locale
+
+	readText := func(name string) (string, error) {
+		data, err := os.ReadFile(filepath.Join(f.cfg.BaseDir, basePath, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	mediaURL := func(entry fastlaneEntry) string { return entry.Path }
+
+	meta, err := f.buildFastlaneMetadata(basePath, basePath, readText, listDirectory, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// changelogs/<versionCode>.txt, or default.txt for every version
+	var changelogs []string
+	if f.VersionCode > 0 {
+		changelogs = append(changelogs, fmt.Sprintf("%d.txt", f.VersionCode))
+	}
+	for _, name := range append(changelogs, "default.txt") {
+		path := basePath + "/changelogs/" + name
+		if info, err := os.Stat(filepath.Join(f.cfg.BaseDir, path)); err == nil && !info.IsDir() {
+			meta.ReleaseNotes = path
+			break
+		}
+	}
+	return meta, nil
+}
+
+// listLocalFastlaneDir lists path (relative to baseDir) in the shape of a forge
+// directory listing, skipping hidden files. A missing directory is errFastlaneUnavailable.
+func listLocalFastlaneDir(baseDir, path string) ([]fastlaneEntry, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(baseDir, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", errFastlaneUnavailable, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading Fastlane directory: %w", err)
+	}
+	var entries []fastlaneEntry
+	for _, d := range dirEntries {
+		if strings.HasPrefix(d.Name(), ".") {
+			continue
+		}
+		entryType := "file"
+		if d.IsDir() {
+			entryType = "dir"
+		}
+		entries = append(entries, fastlaneEntry{Name: d.Name(), Path: path + "/" + d.Name(), Type: entryType})
+	}
+	return entries, nil
+}
+
 func (f *MetadataFetcher) fetchGitHubFastlaneMetadata(ctx context.Context) (*AppMetadata, error) {
 	repoPath := config.GetGitHubRepo(f.cfg.Repository)
 	if repoPath == "" {
@@ -78,7 +168,8 @@ func (f *MetadataFetcher) fetchGitHubFastlaneMetadata(ctx context.Context) (*App
 	}
 	mediaURL := func(entry fastlaneEntry) string { return entry.DownloadURL }
 
-	return f.buildFastlaneMetadata(ctx, basePath, textURL, listDirectory, mediaURL)
+	sourceURL := fmt.Sprintf("https://api.github.com/repos/%s/contents/%s", repoPath, basePath)
+	return f.buildFastlaneMetadata(sourceURL, basePath, f.remoteFastlaneText(ctx, textURL), listDirectory, mediaURL)
 }
 
 func (f *MetadataFetcher) githubContents(ctx context.Context, repoPath, path string) ([]fastlaneEntry, error) {
@@ -150,7 +241,8 @@ func (f *MetadataFetcher) fetchGiteaFastlaneMetadata(ctx context.Context) (*AppM
 	}
 	mediaURL := func(entry fastlaneEntry) string { return entry.DownloadURL }
 
-	return f.buildFastlaneMetadata(ctx, basePath, textURL, listDirectory, mediaURL)
+	sourceURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/contents/%s", baseURL, owner, repo, basePath)
+	return f.buildFastlaneMetadata(sourceURL, basePath, f.remoteFastlaneText(ctx, textURL), listDirectory, mediaURL)
 }
 
 // giteaContents lists a directory via the Gitea/Forgejo contents API.
@@ -222,7 +314,9 @@ func (f *MetadataFetcher) fetchGitLabFastlaneMetadata(ctx context.Context) (*App
 		return f.gitLabRawURL(baseURL, projectPath, entry.Path)
 	}
 
-	return f.buildFastlaneMetadata(ctx, basePath, textURL, listDirectory, mediaURL)
+	sourceURL := fmt.Sprintf("%s/api/v4/projects/%s/repository/tree?path=%s&per_page=100&ref=HEAD",
+		baseURL, projectPath, url.QueryEscape(basePath))
+	return f.buildFastlaneMetadata(sourceURL, basePath, f.remoteFastlaneText(ctx, textURL), listDirectory, mediaURL)
 }
 
 func (f *MetadataFetcher) gitLabTree(ctx context.Context, baseURL, projectPath, path string) ([]fastlaneEntry, error) {
@@ -258,14 +352,18 @@ func (f *MetadataFetcher) gitLabRawURL(baseURL, projectPath, path string) string
 		baseURL, projectPath, url.PathEscape(path))
 }
 
+// buildFastlaneMetadata reads the locale directory at basePath; sourceURL is the
+// directory listing it was found in, recorded as the metadata's provenance.
+// readText returns the contents of a file in the locale directory, or "" when
+// it does not exist.
 func (f *MetadataFetcher) buildFastlaneMetadata(
-	ctx context.Context,
+	sourceURL string,
 	basePath string,
-	textURL func(string) string,
+	readText func(string) (string, error),
 	listDirectory func(string) ([]fastlaneEntry, error),
 	mediaURL func(fastlaneEntry) string,
 ) (*AppMetadata, error) {
-	meta := &AppMetadata{}
+	meta := &AppMetadata{SourceURL: sourceURL}
 	fields := []struct {
 		name string
 		set  func(string)
@@ -275,11 +373,11 @@ func (f *MetadataFetcher) buildFastlaneMetadata(
 		{"full_description.txt", func(value string) { meta.Description = value }},
 	}
 	for _, field := range fields {
-		if rawURL := textURL(field.name); rawURL != "" {
-			content, err := f.fetchFastlaneText(ctx, rawURL)
-			if err != nil {
-				return nil, fmt.Errorf("fetching Fastlane %s: %w", field.name, err)
-			}
+		content, err := readText(field.name)
+		if err != nil {
+			return nil, fmt.Errorf("fetching Fastlane %s: %w", field.name, err)
+		}
+		if content != "" {
 			field.set(strings.TrimSpace(content))
 		}
 	}
@@ -317,6 +415,18 @@ func (f *MetadataFetcher) buildFastlaneMetadata(
 	return meta, nil
 }
 
+// remoteFastlaneText returns a buildFastlaneMetadata text reader that downloads
+// the file textURL finds in a forge directory listing.
+func (f *MetadataFetcher) remoteFastlaneText(ctx context.Context, textURL func(string) string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		rawURL := textURL(name)
+		if rawURL == "" {
+			return "", nil
+		}
+		return f.fetchFastlaneText(ctx, rawURL)
+	}
+}
+
 func (f *MetadataFetcher) fetchFastlaneText(ctx context.Context, rawURL string) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
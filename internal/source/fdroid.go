@@ -12,9 +12,12 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ratelimit"
+	"github.com/zapstore/zsp/internal/tempdir"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,18 +26,21 @@ import (
 type fdroidIndexCache struct {
 	ETag                          string                            `json:"etag"`
 	Packages                      map[string][]fdroidPackageVersion `json:"packages"`
+	Suggested                     map[string]int64                  `json:"suggested,omitempty"` // suggestedVersionCode by package
 	LatestPublishedReleaseVersion string                            `json:"latest_published_release_version,omitempty"`
 }
 
 // FDroid implements Source for F-Droid compatible repositories.
 // Supports: f-droid.org, IzzyOnDroid (apt.izzysoft.de), and other F-Droid repos.
 type FDroid struct {
-	cfg               *config.Config
-	repoInfo          *config.FDroidRepoInfo
-	client            *http.Client
-	cacheDir          string
-	SkipCache         bool
-	SkipDownloadCache bool // Set to true to skip saving APKs to download cache
+	cfg                *config.Config
+	repoInfo           *config.FDroidRepoInfo
+	client             *http.Client
+	cacheDir           string
+	SkipCache          bool
+	IncludePreReleases bool              // Set to true to allow versions newer than the suggested one (--pre-release)
+	SkipDownloadCache  bool              // Set to true to skip saving APKs to download cache
+	DownloadHeaders    map[string]string // Extra headers for APK downloads (release_source referer/headers)
 
 	// pending holds cache data from the last fetch, not yet committed to disk.
 	pending *fdroidIndexCache
@@ -122,11 +128,11 @@ func (f *FDroid) GetCachedRelease() *Release {
 	if cache == nil {
 		return nil
 	}
-	version, err := f.selectVersion(cache.Packages)
+	version, err := f.selectVersion(cache.Packages, cache.Suggested)
 	if err != nil {
 		return nil
 	}
-	return f.buildRelease(version)
+	return f.buildRelease(version, cache.Suggested)
 }
 
 // Type returns the source type.
@@ -136,9 +142,31 @@ func (f *FDroid) Type() config.SourceType {
 
 // fdroidIndex represents the F-Droid repo index.
 type fdroidIndex struct {
+	Apps     []fdroidApp                       `json:"apps"`
 	Packages map[string][]fdroidPackageVersion `json:"packages"`
 }
 
+// fdroidApp is the part of an index app entry used to tell stable versions apart.
+type fdroidApp struct {
+	PackageName string `json:"packageName"`
+	// SuggestedVersionCode is the version F-Droid clients install by default;
+	// newer versions are only offered to users who opt into unstable updates.
+	// A string in index-v1, a number in some third-party indexes.
+	SuggestedVersionCode json.RawMessage `json:"suggestedVersionCode"`
+}
+
+// suggestedVersionCodes maps each app in the index to its suggested versionCode.
+func (index *fdroidIndex) suggestedVersionCodes() map[string]int64 {
+	suggested := make(map[string]int64)
+	for _, app := range index.Apps {
+		raw := strings.Trim(string(app.SuggestedVersionCode), `"`)
+		if code, err := strconv.ParseInt(raw, 10, 64); err == nil && code > 0 {
+			suggested[app.PackageName] = code
+		}
+	}
+	return suggested
+}
+
 // fdroidPackageVersion represents a package version in the index.
 type fdroidPackageVersion struct {
 	VersionCode      int64    `json:"versionCode"`
@@ -174,18 +202,19 @@ type fdroidMetadata struct {
 // For others (IzzyOnDroid), fetches the shared index with ETag caching to avoid
 // re-downloading the full 14–50 MB file when unchanged.
 func (f *FDroid) FetchLatestRelease(ctx context.Context) (*Release, error) {
-	version, err := f.fetchLatestVersion(ctx)
+	version, suggested, err := f.fetchLatestVersion(ctx)
 	if err != nil {
 		return nil, err
 	}
 	if f.pending != nil && version != nil {
 		f.pending.LatestPublishedReleaseVersion = version.VersionName
 	}
-	return f.buildRelease(version), nil
+	return f.buildRelease(version, suggested), nil
 }
 
-// buildRelease constructs a Release from a parsed package version entry.
-func (f *FDroid) buildRelease(version *fdroidPackageVersion) *Release {
+// buildRelease constructs a Release from a parsed package version entry. It is a
+// pre-release when the version is newer than the index's suggested version.
+func (f *FDroid) buildRelease(version *fdroidPackageVersion, suggested map[string]int64) *Release {
 	apkURL := fmt.Sprintf("%s/%s_%d.apk", f.repoInfo.RepoURL, f.repoInfo.PackageID, version.VersionCode)
 	apkName := fmt.Sprintf("%s_%d.apk", f.repoInfo.PackageID, version.VersionCode)
 
@@ -198,27 +227,30 @@ func (f *FDroid) buildRelease(version *fdroidPackageVersion) *Release {
 		Version: version.VersionName,
 		Assets: []*Asset{
 			{
-				Name: apkName,
-				URL:  apkURL,
-				Size: version.Size,
+				Name:      apkName,
+				URL:       apkURL,
+				Size:      version.Size,
+				UpdatedAt: createdAt,
 			},
 		},
-		CreatedAt: createdAt,
+		CreatedAt:  createdAt,
+		PreRelease: suggested[f.repoInfo.PackageID] > 0 && version.VersionCode > suggested[f.repoInfo.PackageID],
 	}
 }
 
 // fetchLatestVersion fetches the latest version for this package from the shared repo
 // index, using a disk-cached ETag to avoid re-downloading the full index when unchanged.
-func (f *FDroid) fetchLatestVersion(ctx context.Context) (*fdroidPackageVersion, error) {
+func (f *FDroid) fetchLatestVersion(ctx context.Context) (*fdroidPackageVersion, map[string]int64, error) {
 	return f.fetchLatestVersionFromIndex(ctx)
 }
 
 // fetchLatestVersionFromIndex fetches the latest version from the shared repo index,
 // using a disk-cached ETag to avoid re-downloading the full 14–50 MB file when unchanged.
-func (f *FDroid) fetchLatestVersionFromIndex(ctx context.Context) (*fdroidPackageVersion, error) {
+// It also returns the index's suggested versionCodes.
+func (f *FDroid) fetchLatestVersionFromIndex(ctx context.Context) (*fdroidPackageVersion, map[string]int64, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", f.repoInfo.IndexURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Send If-None-Match if we have a cached ETag (unless skipping cache).
@@ -232,18 +264,19 @@ func (f *FDroid) fetchLatestVersionFromIndex(ctx context.Context) (*fdroidPackag
 
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch repo index: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch repo index: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle 304 Not Modified with ETag cache
 	if resp.StatusCode == http.StatusNotModified && cached != nil {
-		return f.selectVersion(cached.Packages)
+		version, err := f.selectVersion(cached.Packages, cached.Suggested)
+		return version, cached.Suggested, err
 	}
 
 	// Validate HTTP status
 	if err := checkHTTPStatus(resp, "F-Droid repository"); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Wrap body with stall timeout. F-Droid indexes are large (48+ MB) and can be
@@ -260,33 +293,48 @@ func (f *FDroid) fetchLatestVersionFromIndex(ctx context.Context) (*fdroidPackag
 	body, err := io.ReadAll(reader)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
-			return nil, fmt.Errorf("timed out reading repo index (no data received for 30s): %w", err)
+			return nil, nil, fmt.Errorf("timed out reading repo index (no data received for 30s): %w", err)
 		}
-		return nil, fmt.Errorf("failed to read repo index: %w", err)
+		return nil, nil, fmt.Errorf("failed to read repo index: %w", err)
 	}
 
 	var index fdroidIndex
 	if err := json.Unmarshal(body, &index); err != nil {
-		return nil, fmt.Errorf("failed to parse repo index: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse repo index: %w", err)
 	}
+	suggested := index.suggestedVersionCodes()
 
 	// Stage cache for commit after successful publish.
 	etag := resp.Header.Get("ETag")
 	if etag != "" {
-		f.pending = &fdroidIndexCache{ETag: etag, Packages: index.Packages}
+		f.pending = &fdroidIndexCache{ETag: etag, Packages: index.Packages, Suggested: suggested}
 	}
 
-	return f.selectVersion(index.Packages)
+	version, err := f.selectVersion(index.Packages, suggested)
+	return version, suggested, err
 }
 
 // selectVersion picks the best available version for this package from a packages map.
-// Prefers arm64-v8a builds; falls back to architecture-independent builds.
-func (f *FDroid) selectVersion(packages map[string][]fdroidPackageVersion) (*fdroidPackageVersion, error) {
+// Prefers arm64-v8a builds; falls back to architecture-independent builds. Versions
+// newer than the suggested one are unstable and skipped unless IncludePreReleases is set.
+func (f *FDroid) selectVersion(packages map[string][]fdroidPackageVersion, suggested map[string]int64) (*fdroidPackageVersion, error) {
 	versions, ok := packages[f.repoInfo.PackageID]
 	if !ok || len(versions) == 0 {
 		return nil, fmt.Errorf("package %s not found in repository", f.repoInfo.PackageID)
 	}
 
+	if ceiling := suggested[f.repoInfo.PackageID]; ceiling > 0 && !f.IncludePreReleases {
+		var stable []fdroidPackageVersion
+		for _, v := range versions {
+			if v.VersionCode <= ceiling {
+				stable = append(stable, v)
+			}
+		}
+		if len(stable) > 0 {
+			versions = stable
+		}
+	}
+
 	// F-Droid publishes separate APKs for each architecture, each with a different
 	// versionCode (e.g., arm64-v8a=25060102, x86=25060103, x86_64=25060104).
 	// Filter to arm64-v8a first, then find the highest versionCode among those.
@@ -343,7 +391,7 @@ func (f *FDroid) Download(ctx context.Context, asset *Asset, destDir string, pro
 
 	// Create destination directory if needed
 	if destDir == "" {
-		destDir = os.TempDir()
+		destDir = tempdir.Dir()
 	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create destination directory: %w", err)
@@ -360,6 +408,7 @@ func (f *FDroid) Download(ctx context.Context, asset *Asset, destDir string, pro
 	if err != nil {
 		return "", err
 	}
+	setDownloadHeaders(req, f.DownloadHeaders)
 
 	resp, err := DoWithTorFallback(ctx, dlClient, req)
 	if err != nil {
@@ -390,6 +439,7 @@ func (f *FDroid) Download(ctx context.Context, asset *Asset, destDir string, pro
 		Reader:  resp.Body,
 		Timeout: downloadStallTimeout,
 	}
+	reader = ratelimit.Reader(ctx, ratelimit.Download, reader) // --limit-rate, --max-download-rate
 
 	// Wrap with progress tracking if callback provided
 	if progress != nil && total > 0 {
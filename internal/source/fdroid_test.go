@@ -1,6 +1,9 @@
 package source
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/zapstore/zsp/internal/config"
@@ -58,3 +61,33 @@ func TestFDroidCacheRoundtrip(t *testing.T) {
 		t.Fatalf("CommitCache() with nil pending should not error: %v", err)
 	}
 }
+
+func TestFDroidFetchLatestReleaseSuggestedVersion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+  "apps": [{"packageName": "com.example.app", "suggestedVersionCode": "10"}],
+  "packages": {"com.example.app": [
+    {"versionCode": 11, "versionName": "1.1.0-beta", "nativecode": ["arm64-v8a"]},
+    {"versionCode": 10, "versionName": "1.0.0", "nativecode": ["arm64-v8a"]}
+  ]}
+}`))
+	}))
+	defer srv.Close()
+
+	for _, include := range []bool{false, true} {
+		f := &FDroid{
+			repoInfo:           &config.FDroidRepoInfo{RepoURL: srv.URL, IndexURL: srv.URL + "/index-v1.json", PackageID: "com.example.app"},
+			client:             srv.Client(),
+			cacheDir:           t.TempDir(),
+			IncludePreReleases: include,
+		}
+		release, err := f.FetchLatestRelease(context.Background())
+		if err != nil {
+			t.Fatalf("IncludePreReleases=%v: %v", include, err)
+		}
+		want := map[bool]string{false: "1.0.0", true: "1.1.0-beta"}[include]
+		if release.Version != want || release.PreRelease != include {
+			t.Errorf("IncludePreReleases=%v: got %s (pre-release %v), want %s", include, release.Version, release.PreRelease, want)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ratelimit"
+	"github.com/zapstore/zsp/internal/tempdir"
 )
 
 // giteaCache stores the last successfully published release version.
@@ -31,8 +33,9 @@ type Gitea struct {
 	client             *http.Client
 	cacheDir           string
 	pendingVersion     string
-	IncludePreReleases bool // Set to true to include pre-releases (--pre-release)
-	SkipDownloadCache  bool // Set to true to skip saving APKs to download cache
+	IncludePreReleases bool              // Set to true to include pre-releases (--pre-release)
+	SkipDownloadCache  bool              // Set to true to skip saving APKs to download cache
+	DownloadHeaders    map[string]string // Extra headers for APK downloads (release_source referer/headers)
 }
 
 // NewGitea creates a new Gitea source.
@@ -139,6 +142,7 @@ type giteaAsset struct {
 	Size               int64  `json:"size"`
 	DownloadCount      int64  `json:"download_count"`
 	BrowserDownloadURL string `json:"browser_download_url"`
+	CreatedAt          string `json:"created_at"` // attachments are immutable; re-uploads get a new one
 }
 
 // FetchLatestRelease fetches the latest release from a Gitea-compatible forge that contains valid APKs.
@@ -151,7 +155,23 @@ func (g *Gitea) FetchLatestRelease(ctx context.Context) (*Release, error) {
 
 // fetchLatestFromList fetches releases and returns the first one with valid APKs.
 func (g *Gitea) fetchLatestFromList(ctx context.Context) (*Release, error) {
-	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases?limit=%d", g.baseURL, g.owner, g.repo, maxReleasesToCheck)
+	releases, err := g.ListReleases(ctx, maxReleasesToCheck)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) > 0 {
+		g.pendingVersion = releases[0].Version
+		return releases[0], nil
+	}
+
+	return nil, fmt.Errorf("no releases with valid APKs found in the last %d releases for %s/%s", maxReleasesToCheck, g.owner, g.repo)
+}
+
+// ListReleases returns up to n of the most recent releases with valid APKs,
+// newest first, skipping drafts, pre-releases (unless included) and releases
+// outside release_filter. Unlike FetchLatestRelease it leaves the cache alone.
+func (g *Gitea) ListReleases(ctx context.Context, n int) ([]*Release, error) {
+	apiURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/releases?limit=%d", g.baseURL, g.owner, g.repo, min(n, 50))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
@@ -187,7 +207,7 @@ func (g *Gitea) fetchLatestFromList(ctx context.Context) (*Release, error) {
 		return nil, fmt.Errorf("no releases found for %s/%s", g.owner, g.repo)
 	}
 
-	// Find the first non-draft release with valid APKs
+	var result []*Release
 	for _, r := range releases {
 		// Skip drafts; skip prereleases unless explicitly included
 		if r.Draft || (r.Prerelease && !g.IncludePreReleases) {
@@ -196,14 +216,11 @@ func (g *Gitea) fetchLatestFromList(ctx context.Context) (*Release, error) {
 		if !g.matchesReleaseFilter(r.TagName) {
 			continue
 		}
-		release := g.convertRelease(&r)
-		if HasValidAPKs(release.Assets) {
-			g.pendingVersion = release.Version
-			return release, nil
+		if release := g.convertRelease(&r); HasValidAPKs(release.Assets) {
+			result = append(result, release)
 		}
 	}
-
-	return nil, fmt.Errorf("no releases with valid APKs found in the last %d releases for %s/%s", maxReleasesToCheck, g.owner, g.repo)
+	return result, nil
 }
 
 // convertRelease converts a Gitea release to our Release type.
@@ -211,9 +228,11 @@ func (g *Gitea) convertRelease(gtRelease *giteaRelease) *Release {
 	assets := make([]*Asset, 0, len(gtRelease.Assets))
 	for _, a := range gtRelease.Assets {
 		assets = append(assets, &Asset{
-			Name: a.Name,
-			URL:  a.BrowserDownloadURL,
-			Size: a.Size,
+			Name:      a.Name,
+			URL:       a.BrowserDownloadURL,
+			Size:      a.Size,
+			ID:        a.ID,
+			UpdatedAt: parseRFC3339(a.CreatedAt),
 		})
 	}
 
@@ -264,7 +283,7 @@ func (g *Gitea) Download(ctx context.Context, asset *Asset, destDir string, prog
 
 	// Create destination directory if needed
 	if destDir == "" {
-		destDir = os.TempDir()
+		destDir = tempdir.Dir()
 	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create destination directory: %w", err)
@@ -291,6 +310,7 @@ func (g *Gitea) Download(ctx context.Context, asset *Asset, destDir string, prog
 	if err != nil {
 		return "", err
 	}
+	setDownloadHeaders(req, g.DownloadHeaders)
 
 	if g.token != "" {
 		req.Header.Set("Authorization", "token "+g.token)
@@ -324,11 +344,12 @@ func (g *Gitea) Download(ctx context.Context, asset *Asset, destDir string, prog
 		Reader:  resp.Body,
 		Timeout: downloadStallTimeout,
 	}
+	reader = ratelimit.Reader(ctx, ratelimit.Download, reader) // --limit-rate, --max-download-rate
 
 	// Wrap with progress tracking if callback provided
 	if progress != nil && total > 0 {
 		reader = &ProgressReader{
-			Reader:     resp.Body,
+			Reader:     reader,
 			Total:      total,
 			OnProgress: progress,
 		}
@@ -1,7 +1,12 @@
 package source
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/zapstore/zsp/internal/config"
 )
 
 func TestGiteaCacheRoundtrip(t *testing.T) {
@@ -51,3 +56,29 @@ func TestGiteaCacheRoundtrip(t *testing.T) {
 		t.Fatalf("GetPublishedVersion() after no-op CommitCache = %q, want %q", got, "0.80.0")
 	}
 }
+
+func TestGiteaFetchLatestReleasePreReleases(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/acme/app/releases" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+  {"tag_name": "v1.1.0-rc1", "prerelease": true, "assets": [{"name": "app-1.1.0-rc1.apk", "browser_download_url": "https://example.com/app-1.1.0-rc1.apk"}]},
+  {"tag_name": "v1.0.0", "assets": [{"name": "app-1.0.0.apk", "browser_download_url": "https://example.com/app-1.0.0.apk"}]}
+]`))
+	}))
+	defer srv.Close()
+
+	for _, include := range []bool{false, true} {
+		g := &Gitea{cfg: &config.Config{}, baseURL: srv.URL, owner: "acme", repo: "app", client: srv.Client(), cacheDir: t.TempDir(), IncludePreReleases: include}
+		release, err := g.FetchLatestRelease(context.Background())
+		if err != nil {
+			t.Fatalf("IncludePreReleases=%v: %v", include, err)
+		}
+		want := map[bool]string{false: "v1.0.0", true: "v1.1.0-rc1"}[include]
+		if release.TagName != want || release.PreRelease != include {
+			t.Errorf("IncludePreReleases=%v: got %s (pre-release %v), want %s", include, release.TagName, release.PreRelease, want)
+		}
+	}
+}
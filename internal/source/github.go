@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ratelimit"
+	"github.com/zapstore/zsp/internal/tempdir"
 )
 
 // ErrNotModified is returned when the release hasn't changed since the last check.
@@ -41,9 +43,10 @@ type GitHub struct {
 	token              string
 	client             *http.Client
 	cacheDir           string
-	SkipCache          bool // Set to true to bypass ETag cache (--overwrite-release)
-	IncludePreReleases bool // Set to true to include pre-releases (--pre-release)
-	SkipDownloadCache  bool // Set to true to skip saving APKs to download cache
+	SkipCache          bool              // Set to true to bypass ETag cache (--overwrite-release)
+	IncludePreReleases bool              // Set to true to include pre-releases (--pre-release)
+	SkipDownloadCache  bool              // Set to true to skip saving APKs to download cache
+	DownloadHeaders    map[string]string // Extra headers for APK downloads (release_source referer/headers)
 
 	// pending holds cache data from the last fetch, not yet committed to disk.
 	// Call CommitCache() after successful publishing to persist it.
@@ -168,22 +171,27 @@ func (g *GitHub) CommitCache() error {
 
 // githubRelease represents a GitHub release API response.
 type githubRelease struct {
-	TagName     string        `json:"tag_name"`
-	Name        string        `json:"name"`
-	Body        string        `json:"body"`
-	Prerelease  bool          `json:"prerelease"`
-	Draft       bool          `json:"draft"`
-	PublishedAt string        `json:"published_at"`
-	HTMLURL     string        `json:"html_url"`
-	Assets      []githubAsset `json:"assets"`
+	TagName       string        `json:"tag_name"`
+	Name          string        `json:"name"`
+	Body          string        `json:"body"`
+	Prerelease    bool          `json:"prerelease"`
+	Draft         bool          `json:"draft"`
+	PublishedAt   string        `json:"published_at"`
+	HTMLURL       string        `json:"html_url"`
+	DiscussionURL string        `json:"discussion_url,omitempty"`
+	Assets        []githubAsset `json:"assets"`
 }
 
 // githubAsset represents a GitHub release asset.
 type githubAsset struct {
+	ID                 int64  `json:"id"`
 	Name               string `json:"name"`
+	Label              string `json:"label"`
 	Size               int64  `json:"size"`
 	BrowserDownloadURL string `json:"browser_download_url"`
 	ContentType        string `json:"content_type"`
+	UpdatedAt          string `json:"updated_at"`
+	Digest             string `json:"digest,omitempty"` // "sha256:<hex>", on assets uploaded since mid-2025
 }
 
 // FetchLatestRelease fetches the latest release from GitHub that contains valid APKs.
@@ -198,11 +206,21 @@ type githubAsset struct {
 // prereleases from that endpoint by design. When IncludePreReleases is set we skip
 // straight to the list endpoint so that a prerelease newer than the latest stable
 // release is not missed.
+//
+// With GITHUB_TOKEN set, the fast path uses a single GraphQL query that also
+// returns the repository metadata (see latestFromGraphQL), falling back to REST
+// if GraphQL fails.
 func (g *GitHub) FetchLatestRelease(ctx context.Context) (*Release, error) {
 	if g.IncludePreReleases {
 		return g.fetchLatestFromList(ctx)
 	}
 
+	if g.useGraphQL() {
+		if release, ok, err := g.latestFromGraphQL(ctx); ok {
+			return release, err
+		}
+	}
+
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", g.owner, g.repo)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -275,7 +293,42 @@ func (g *GitHub) FetchLatestRelease(ctx context.Context) (*Release, error) {
 // ETag is intentionally not cached here: the cached ETag is bound to /releases/latest,
 // and mixing endpoints would cause the conditional-request optimisation to stop working.
 func (g *GitHub) fetchLatestFromList(ctx context.Context) (*Release, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=%d", g.owner, g.repo, maxReleasesToCheck)
+	releases, err := g.ListReleases(ctx, maxReleasesToCheck)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) > 0 {
+		return releases[0], nil
+	}
+
+	return nil, fmt.Errorf("no releases with valid APKs found in the last %d releases for %s/%s", maxReleasesToCheck, g.owner, g.repo)
+}
+
+// ListReleases returns up to n of the most recent releases with valid APKs,
+// newest first, skipping drafts, pre-releases (unless included) and releases
+// outside release_filter. Release caches are left untouched.
+func (g *GitHub) ListReleases(ctx context.Context, n int) ([]*Release, error) {
+	ghReleases, err := g.fetchReleaseList(ctx, min(n, 100))
+	if err != nil {
+		return nil, err
+	}
+	var releases []*Release
+	for i := range ghReleases {
+		ghRelease := &ghReleases[i]
+		if ghRelease.Draft || (ghRelease.Prerelease && !g.IncludePreReleases) || !g.matchesReleaseFilter(ghRelease.TagName) {
+			continue
+		}
+		if release := g.convertRelease(ghRelease); HasValidAPKs(release.Assets) {
+			releases = append(releases, release)
+		}
+	}
+	return releases, nil
+}
+
+// fetchReleaseList fetches up to perPage releases, newest first. It fails when
+// the repository has none.
+func (g *GitHub) fetchReleaseList(ctx context.Context, perPage int) ([]githubRelease, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases?per_page=%d", g.owner, g.repo, perPage)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -316,22 +369,7 @@ func (g *GitHub) fetchLatestFromList(ctx context.Context) (*Release, error) {
 	if len(releases) == 0 {
 		return nil, fmt.Errorf("no releases found for %s/%s", g.owner, g.repo)
 	}
-
-	for i := range releases {
-		ghRelease := &releases[i]
-		if ghRelease.Draft || (ghRelease.Prerelease && !g.IncludePreReleases) {
-			continue
-		}
-		if !g.matchesReleaseFilter(ghRelease.TagName) {
-			continue
-		}
-		release := g.convertRelease(ghRelease)
-		if HasValidAPKs(release.Assets) {
-			return release, nil
-		}
-	}
-
-	return nil, fmt.Errorf("no releases with valid APKs found in the last %d releases for %s/%s", maxReleasesToCheck, g.owner, g.repo)
+	return releases, nil
 }
 
 // convertRelease converts a GitHub release to our Release type.
@@ -343,6 +381,10 @@ func (g *GitHub) convertRelease(ghRelease *githubRelease) *Release {
 			URL:         a.BrowserDownloadURL,
 			Size:        a.Size,
 			ContentType: a.ContentType,
+			Label:       a.Label,
+			ID:          a.ID,
+			UpdatedAt:   parseRFC3339(a.UpdatedAt),
+			SHA256:      githubAssetSHA256(a.Digest),
 		})
 	}
 
@@ -364,13 +406,14 @@ func (g *GitHub) convertRelease(ghRelease *githubRelease) *Release {
 	}
 
 	return &Release{
-		Version:    version,
-		TagName:    ghRelease.TagName,
-		Changelog:  ghRelease.Body,
-		Assets:     assets,
-		PreRelease: ghRelease.Prerelease,
-		URL:        ghRelease.HTMLURL,
-		CreatedAt:  createdAt,
+		Version:       version,
+		TagName:       ghRelease.TagName,
+		Changelog:     ghRelease.Body,
+		Assets:        assets,
+		PreRelease:    ghRelease.Prerelease,
+		URL:           ghRelease.HTMLURL,
+		DiscussionURL: ghRelease.DiscussionURL,
+		CreatedAt:     createdAt,
 	}
 }
 
@@ -389,7 +432,7 @@ func (g *GitHub) Download(ctx context.Context, asset *Asset, destDir string, pro
 
 	// Create destination directory if needed
 	if destDir == "" {
-		destDir = os.TempDir()
+		destDir = tempdir.Dir()
 	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create destination directory: %w", err)
@@ -416,6 +459,7 @@ func (g *GitHub) Download(ctx context.Context, asset *Asset, destDir string, pro
 	if err != nil {
 		return "", err
 	}
+	setDownloadHeaders(req, g.DownloadHeaders)
 
 	if g.token != "" {
 		req.Header.Set("Authorization", "Bearer "+g.token)
@@ -449,6 +493,7 @@ func (g *GitHub) Download(ctx context.Context, asset *Asset, destDir string, pro
 		Reader:  resp.Body,
 		Timeout: downloadStallTimeout,
 	}
+	reader = ratelimit.Reader(ctx, ratelimit.Download, reader) // --limit-rate, --max-download-rate
 
 	// Wrap with progress tracking if callback provided
 	if progress != nil && total > 0 {
@@ -479,6 +524,16 @@ func (g *GitHub) Download(ctx context.Context, asset *Asset, destDir string, pro
 	return destPath, nil
 }
 
+// githubAssetSHA256 returns the hex digest of a "sha256:<hex>" asset digest, or
+// "" for other algorithms and assets without one.
+func githubAssetSHA256(digest string) string {
+	sum, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok || len(sum) != 64 {
+		return ""
+	}
+	return strings.ToLower(sum)
+}
+
 // matchesReleaseFilter checks if a tag name matches the configured release_filter.
 // Returns true if no filter is configured or if the tag matches the filter.
 func (g *GitHub) matchesReleaseFilter(tagName string) bool {
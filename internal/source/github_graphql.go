@@ -0,0 +1,202 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// githubGraphQLURL is GitHub's GraphQL endpoint, which requires a token.
+const githubGraphQLURL = "https://api.github.com/graphql"
+
+// githubLatestReleaseQuery fetches the repository metadata, topics and latest
+// release with its assets in one request, where REST needs three.
+const githubLatestReleaseQuery = `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    name
+    description
+    homepageUrl
+    licenseInfo { spdxId }
+    repositoryTopics(first: 20) { nodes { topic { name } } }
+    latestRelease {
+      tagName
+      name
+      description
+      isPrerelease
+      isDraft
+      publishedAt
+      url
+      releaseAssets(first: 100) {
+        nodes { name size downloadUrl contentType updatedAt }
+      }
+    }
+  }
+}`
+
+// githubGraphQLRepository is the repository part of a githubLatestReleaseQuery response.
+type githubGraphQLRepository struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	HomepageURL string `json:"homepageUrl"`
+	LicenseInfo *struct {
+		SPDXID string `json:"spdxId"`
+	} `json:"licenseInfo"`
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string `json:"name"`
+			} `json:"topic"`
+		} `json:"nodes"`
+	} `json:"repositoryTopics"`
+	LatestRelease *struct {
+		TagName       string `json:"tagName"`
+		Name          string `json:"name"`
+		Description   string `json:"description"`
+		IsPrerelease  bool   `json:"isPrerelease"`
+		IsDraft       bool   `json:"isDraft"`
+		PublishedAt   string `json:"publishedAt"`
+		URL           string `json:"url"`
+		ReleaseAssets struct {
+			Nodes []struct {
+				Name        string `json:"name"`
+				Size        int64  `json:"size"`
+				DownloadURL string `json:"downloadUrl"`
+				ContentType string `json:"contentType"`
+				UpdatedAt   string `json:"updatedAt"`
+			} `json:"nodes"`
+		} `json:"releaseAssets"`
+	} `json:"latestRelease"`
+}
+
+// useGraphQL reports whether the latest release can be fetched with GraphQL.
+// It needs a token; asset labels and IDs (match_label) and release discussions
+// (append_release_link) are only in the REST API.
+func (g *GitHub) useGraphQL() bool {
+	return g.token != "" && g.cfg != nil && g.cfg.MatchLabel == "" && !g.cfg.AppendReleaseLink
+}
+
+// fetchLatestGraphQL fetches the latest release and the repository metadata in
+// one GraphQL request. The release is returned in REST form so it converts and
+// caches like one from /releases/latest; it is nil when the repository has no
+// release. The metadata is attached to the converted release by the caller.
+func (g *GitHub) fetchLatestGraphQL(ctx context.Context) (*githubRelease, *AppMetadata, error) {
+	payload, err := json.Marshal(map[string]any{
+		"query":     githubLatestReleaseQuery,
+		"variables": map[string]string{"owner": g.owner, "name": g.repo},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", githubGraphQLURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.token)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GitHub GraphQL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, nil, fmt.Errorf("GitHub GraphQL error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result struct {
+		Data struct {
+			Repository *githubGraphQLRepository `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse GitHub GraphQL response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, nil, fmt.Errorf("GitHub GraphQL error: %s", result.Errors[0].Message)
+	}
+	repo := result.Data.Repository
+	if repo == nil {
+		return nil, nil, fmt.Errorf("GitHub GraphQL returned no repository %s/%s", g.owner, g.repo)
+	}
+
+	meta := &AppMetadata{
+		Name:        repo.Name,
+		Description: repo.Description,
+		Website:     repo.HomepageURL,
+		SourceURL:   fmt.Sprintf("https://api.github.com/repos/%s/%s", g.owner, g.repo),
+	}
+	for _, node := range repo.RepositoryTopics.Nodes {
+		meta.Tags = append(meta.Tags, node.Topic.Name)
+	}
+	if repo.LicenseInfo != nil && repo.LicenseInfo.SPDXID != "" && repo.LicenseInfo.SPDXID != "NOASSERTION" {
+		meta.License = repo.LicenseInfo.SPDXID
+	}
+
+	latest := repo.LatestRelease
+	if latest == nil {
+		return nil, meta, nil
+	}
+	release := &githubRelease{
+		TagName:     latest.TagName,
+		Name:        latest.Name,
+		Body:        latest.Description,
+		Prerelease:  latest.IsPrerelease,
+		Draft:       latest.IsDraft,
+		PublishedAt: latest.PublishedAt,
+		HTMLURL:     latest.URL,
+	}
+	for _, a := range latest.ReleaseAssets.Nodes {
+		release.Assets = append(release.Assets, githubAsset{
+			Name:               a.Name,
+			Size:               a.Size,
+			BrowserDownloadURL: a.DownloadURL,
+			ContentType:        a.ContentType,
+			UpdatedAt:          a.UpdatedAt,
+		})
+	}
+	return release, meta, nil
+}
+
+// latestFromGraphQL is the GraphQL counterpart of the /releases/latest fast
+// path. ok is false when GraphQL failed and REST should be used instead.
+// GraphQL has no ETags, so an unchanged release is detected by comparing it
+// with the cached one.
+func (g *GitHub) latestFromGraphQL(ctx context.Context) (release *Release, ok bool, err error) {
+	ghRelease, meta, err := g.fetchLatestGraphQL(ctx)
+	if err != nil {
+		return nil, false, nil
+	}
+	if ghRelease == nil {
+		return nil, true, fmt.Errorf("no releases found for %s/%s", g.owner, g.repo)
+	}
+
+	if !ghRelease.Draft && !ghRelease.Prerelease && g.matchesReleaseFilter(ghRelease.TagName) {
+		converted := g.convertRelease(ghRelease)
+		if HasValidAPKs(converted.Assets) {
+			if !g.SkipCache {
+				if cache := g.loadCache(); cache != nil && reflect.DeepEqual(cache.Release, ghRelease) {
+					return nil, true, ErrNotModified
+				}
+			}
+			g.pending = &pendingCache{Release: ghRelease, LatestPublishedReleaseVersion: converted.Version}
+			converted.Repository = meta
+			return converted, true, nil
+		}
+	}
+
+	// Like the REST fast path, fall back to scanning the release list
+	release, err = g.fetchLatestFromList(ctx)
+	if release != nil {
+		release.Repository = meta
+	}
+	return release, true, err
+}
@@ -1,7 +1,12 @@
 package source
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/zapstore/zsp/internal/config"
 )
@@ -132,3 +137,210 @@ func TestGitHub_matchesReleaseFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestGitHubConvertReleaseLabels(t *testing.T) {
+	payload := `{
+		"tag_name": "v1.4.0",
+		"assets": [
+			{"id": 101, "name": "app-release.apk", "label": "Android (arm64-v8a)", "size": 2048, "updated_at": "2026-03-01T12:30:00Z",
+			 "browser_download_url": "https://github.com/o/r/releases/download/v1.4.0/app-release.apk"},
+			{"id": 102, "name": "app-release-legacy.apk", "label": "", "size": 1024,
+			 "browser_download_url": "https://github.com/o/r/releases/download/v1.4.0/app-release-legacy.apk"}
+		]
+	}`
+	var ghRelease githubRelease
+	if err := json.Unmarshal([]byte(payload), &ghRelease); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	release := (&GitHub{}).convertRelease(&ghRelease)
+	if len(release.Assets) != 2 {
+		t.Fatalf("got %d assets, want 2", len(release.Assets))
+	}
+	if got := release.Assets[0]; got.Label != "Android (arm64-v8a)" || got.ID != 101 {
+		t.Errorf("asset 0 = label %q id %d, want %q 101", got.Label, got.ID, "Android (arm64-v8a)")
+	}
+	if got := release.Assets[1]; got.Label != "" || got.ID != 102 {
+		t.Errorf("asset 1 = label %q id %d, want empty label and id 102", got.Label, got.ID)
+	}
+	if want := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC); !release.Assets[0].UpdatedAt.Equal(want) || !release.Assets[1].UpdatedAt.IsZero() {
+		t.Errorf("UpdatedAt = %v, %v; want %v and zero", release.Assets[0].UpdatedAt, release.Assets[1].UpdatedAt, want)
+	}
+}
+
+func TestGitHubFetchLatestReleasePreReleases(t *testing.T) {
+	const stable = `{"tag_name": "v1.0.0", "assets": [{"name": "app-1.0.0.apk", "browser_download_url": "https://example.com/app-1.0.0.apk"}]}`
+	const pre = `{"tag_name": "v1.1.0-beta", "prerelease": true, "assets": [{"name": "app-1.1.0-beta.apk", "browser_download_url": "https://example.com/app-1.1.0-beta.apk"}]}`
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/repos/acme/app/releases/latest":
+			return testResponse(http.StatusOK, stable), nil
+		case "/repos/acme/app/releases":
+			return testResponse(http.StatusOK, "["+pre+","+stable+"]"), nil
+		}
+		return testResponse(http.StatusNotFound, ""), nil
+	})}
+
+	for _, include := range []bool{false, true} {
+		g := &GitHub{cfg: &config.Config{}, owner: "acme", repo: "app", client: client, cacheDir: t.TempDir(), SkipCache: true, IncludePreReleases: include}
+		release, err := g.FetchLatestRelease(context.Background())
+		if err != nil {
+			t.Fatalf("IncludePreReleases=%v: %v", include, err)
+		}
+		want := map[bool]string{false: "v1.0.0", true: "v1.1.0-beta"}[include]
+		if release.TagName != want || release.PreRelease != include {
+			t.Errorf("IncludePreReleases=%v: got %s (pre-release %v), want %s", include, release.TagName, release.PreRelease, want)
+		}
+	}
+}
+
+func TestGitHubListReleases(t *testing.T) {
+	var perPage string
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/repos/acme/app/releases" {
+			return testResponse(http.StatusNotFound, ""), nil
+		}
+		perPage = req.URL.Query().Get("per_page")
+		return testResponse(http.StatusOK, `[
+			{"tag_name": "v1.3.0", "draft": true, "assets": [{"name": "app.apk", "browser_download_url": "https://example.com/1.3.0/app.apk"}]},
+			{"tag_name": "v1.2.0-beta", "prerelease": true, "assets": [{"name": "app.apk", "browser_download_url": "https://example.com/1.2.0/app.apk"}]},
+			{"tag_name": "desktop-1.1.0", "assets": [{"name": "app.dmg", "browser_download_url": "https://example.com/1.1.0/app.dmg"}]},
+			{"tag_name": "v1.1.0", "published_at": "2026-03-01T12:00:00Z", "assets": [{"name": "app.apk", "browser_download_url": "https://example.com/1.1.0/app.apk"}]},
+			{"tag_name": "v1.0.0", "assets": [{"name": "app.apk", "browser_download_url": "https://example.com/1.0.0/app.apk"}]}
+		]`), nil
+	})}
+	g := &GitHub{cfg: &config.Config{}, owner: "acme", repo: "app", client: client, cacheDir: t.TempDir()}
+
+	releases, err := g.ListReleases(context.Background(), 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, r := range releases {
+		tags = append(tags, r.TagName)
+	}
+	if strings.Join(tags, " ") != "v1.1.0 v1.0.0" || perPage != "20" {
+		t.Errorf("ListReleases(20) = %v (per_page=%s), want the published APK releases newest first", tags, perPage)
+	}
+	if releases[0].CreatedAt.IsZero() {
+		t.Error("release date not set")
+	}
+	if g.pending != nil {
+		t.Error("ListReleases() staged a cache update")
+	}
+}
+
+// githubGraphQLResponse is a recorded response to githubLatestReleaseQuery.
+const githubGraphQLResponse = `{"data":{"repository":{
+	"name":"app","description":"A tiny app","homepageUrl":"https://app.example.com",
+	"licenseInfo":{"spdxId":"GPL-3.0"},
+	"repositoryTopics":{"nodes":[{"topic":{"name":"android"}},{"topic":{"name":"nostr"}}]},
+	"latestRelease":{"tagName":"v2.0.0","name":"2.0.0","description":"Fixes","isPrerelease":false,"isDraft":false,
+		"publishedAt":"2026-04-01T10:00:00Z","url":"https://github.com/acme/app/releases/tag/v2.0.0",
+		"releaseAssets":{"nodes":[{"name":"app-2.0.0.apk","size":4096,"contentType":"application/vnd.android.package-archive",
+			"updatedAt":"2026-04-01T10:05:00Z","downloadUrl":"https://github.com/acme/app/releases/download/v2.0.0/app-2.0.0.apk"}]}}}}}`
+
+func TestGitHubFetchLatestReleaseGraphQL(t *testing.T) {
+	var requests []string
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		if req.URL.Path == "/graphql" && req.Header.Get("Authorization") == "Bearer tok" {
+			return testResponse(http.StatusOK, githubGraphQLResponse), nil
+		}
+		return testResponse(http.StatusNotFound, ""), nil
+	})}
+	g := &GitHub{cfg: &config.Config{}, owner: "acme", repo: "app", token: "tok", client: client, cacheDir: t.TempDir()}
+
+	release, err := g.FetchLatestRelease(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0] != "POST /graphql" {
+		t.Errorf("requests = %v, want a single GraphQL query", requests)
+	}
+	if release.TagName != "v2.0.0" || release.Changelog != "Fixes" || len(release.Assets) != 1 || release.Assets[0].Size != 4096 {
+		t.Errorf("release = %+v, want v2.0.0 with its APK", release)
+	}
+	if want := time.Date(2026, 4, 1, 10, 5, 0, 0, time.UTC); !release.Assets[0].UpdatedAt.Equal(want) {
+		t.Errorf("asset UpdatedAt = %v, want %v", release.Assets[0].UpdatedAt, want)
+	}
+	repo := release.Repository
+	if repo == nil || repo.Description != "A tiny app" || repo.License != "GPL-3.0" || len(repo.Tags) != 2 || repo.SourceURL != "https://api.github.com/repos/acme/app" {
+		t.Errorf("Repository = %+v, want the repository metadata from the same query", repo)
+	}
+
+	// Without ETags, an unchanged release is recognized from the committed cache
+	if err := g.CommitCache(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.FetchLatestRelease(context.Background()); err != ErrNotModified {
+		t.Errorf("second fetch error = %v, want ErrNotModified", err)
+	}
+}
+
+func TestGitHubFetchLatestReleaseGraphQLFallback(t *testing.T) {
+	const latest = `{"tag_name": "v1.0.0", "html_url": "https://github.com/acme/app/releases/tag/v1.0.0",
+		"discussion_url": "https://github.com/acme/app/discussions/7",
+		"assets": [{"id": 5, "name": "app.apk", "label": "Android", "browser_download_url": "https://example.com/app.apk"}]}`
+	newClient := func(graphQLStatus int, requests *[]string) *http.Client {
+		return &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req.Method+" "+req.URL.Path)
+			switch req.URL.Path {
+			case "/graphql":
+				return testResponse(graphQLStatus, `{"errors":[{"message":"Something went wrong"}]}`), nil
+			case "/repos/acme/app/releases/latest":
+				return testResponse(http.StatusOK, latest), nil
+			}
+			return testResponse(http.StatusNotFound, ""), nil
+		})}
+	}
+
+	tests := []struct {
+		name         string
+		cfg          *config.Config
+		token        string
+		status       int
+		wantRequests int
+	}{
+		{"GraphQL error falls back to REST", &config.Config{}, "tok", http.StatusOK, 2},
+		{"GraphQL outage falls back to REST", &config.Config{}, "tok", http.StatusBadGateway, 2},
+		{"no token uses REST", &config.Config{}, "", http.StatusOK, 1},
+		{"match_label needs REST asset labels", &config.Config{MatchLabel: "Android"}, "tok", http.StatusOK, 1},
+		{"append_release_link needs the REST discussion", &config.Config{AppendReleaseLink: true}, "tok", http.StatusOK, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			g := &GitHub{cfg: tt.cfg, owner: "acme", repo: "app", token: tt.token, client: newClient(tt.status, &requests), cacheDir: t.TempDir(), SkipCache: true}
+			release, err := g.FetchLatestRelease(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(requests) != tt.wantRequests || requests[len(requests)-1] != "GET /repos/acme/app/releases/latest" {
+				t.Errorf("requests = %v, want %d ending with the REST call", requests, tt.wantRequests)
+			}
+			if release.DiscussionURL != "https://github.com/acme/app/discussions/7" || release.ReleaseLink() != release.DiscussionURL {
+				t.Errorf("DiscussionURL = %q, ReleaseLink() = %q, want the discussion", release.DiscussionURL, release.ReleaseLink())
+			}
+			if release.Repository != nil {
+				t.Error("REST release carries repository metadata")
+			}
+		})
+	}
+}
+
+func TestGitHubAssetSHA256(t *testing.T) {
+	const sum = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	tests := map[string]string{
+		"sha256:" + sum:                  sum,
+		"sha256:" + strings.ToUpper(sum): sum,
+		"sha512:" + sum:                  "",
+		"sha256:abc":                     "",
+		"":                               "",
+	}
+	for digest, want := range tests {
+		if got := githubAssetSHA256(digest); got != want {
+			t.Errorf("githubAssetSHA256(%q) = %q, want %q", digest, got, want)
+		}
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"time"
 
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ratelimit"
+	"github.com/zapstore/zsp/internal/tempdir"
 )
 
 // gitlabArchRegex extracts architecture from GitLab asset names like "APK (arm64-v8a)"
@@ -41,14 +43,16 @@ type gitlabCache struct {
 // GitLab implements Source for GitLab releases.
 // Supports both gitlab.com and self-hosted GitLab instances.
 type GitLab struct {
-	cfg               *config.Config
-	baseURL           string // e.g., "https://gitlab.com" or self-hosted URL
-	projectID         string // URL-encoded project path (e.g., "user%2Frepo")
-	numericProjectID  int    // GitLab numeric project id (needed for /-/project/:id/uploads/ URLs)
-	client            *http.Client
-	cacheDir          string
-	pendingVersion    string
-	SkipDownloadCache bool // Set to true to skip saving APKs to download cache
+	cfg                *config.Config
+	baseURL            string // e.g., "https://gitlab.com" or self-hosted URL
+	projectID          string // URL-encoded project path (e.g., "user%2Frepo")
+	numericProjectID   int    // GitLab numeric project id (needed for /-/project/:id/uploads/ URLs)
+	client             *http.Client
+	cacheDir           string
+	pendingVersion     string
+	IncludePreReleases bool              // Set to true to include upcoming releases (--pre-release)
+	SkipDownloadCache  bool              // Set to true to skip saving APKs to download cache
+	DownloadHeaders    map[string]string // Extra headers for APK downloads (release_source referer/headers)
 }
 
 // NewGitLab creates a new GitLab source.
@@ -144,6 +148,7 @@ type gitlabRelease struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	ReleasedAt  string `json:"released_at"`
+	Upcoming    bool   `json:"upcoming_release"` // released_at is in the future
 	Links       struct {
 		Self string `json:"self"` // Release page URL
 	} `json:"_links"`
@@ -154,6 +159,7 @@ type gitlabRelease struct {
 
 // gitlabAssetLink represents a GitLab release asset link.
 type gitlabAssetLink struct {
+	ID             int64  `json:"id"`
 	Name           string `json:"name"`
 	URL            string `json:"url"`
 	DirectAssetURL string `json:"direct_asset_url"` // Contains the actual filename
@@ -164,6 +170,23 @@ type gitlabAssetLink struct {
 // Iterates through up to 10 releases to find one with APK assets (for repos that
 // publish desktop and mobile releases separately).
 func (g *GitLab) FetchLatestRelease(ctx context.Context) (*Release, error) {
+	releases, err := g.ListReleases(ctx, maxReleasesToCheck)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) > 0 {
+		g.pendingVersion = releases[0].Version
+		return releases[0], nil
+	}
+
+	return nil, fmt.Errorf("no releases with valid APKs found in the last %d releases", maxReleasesToCheck)
+}
+
+// ListReleases returns up to n of the most recent releases with valid APKs,
+// newest first, skipping upcoming releases (unless pre-releases are included)
+// and releases outside release_filter. Unlike FetchLatestRelease it leaves the
+// cache alone.
+func (g *GitLab) ListReleases(ctx context.Context, n int) ([]*Release, error) {
 	// Numeric id is required to resolve markdown /uploads/ attachments.
 	if err := g.ensureNumericProjectID(ctx); err != nil {
 		return nil, err
@@ -171,7 +194,7 @@ func (g *GitLab) FetchLatestRelease(ctx context.Context) (*Release, error) {
 
 	// GitLab API: GET /projects/:id/releases
 	// Returns releases sorted by released_at descending
-	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/releases?per_page=%d", g.baseURL, g.projectID, maxReleasesToCheck)
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/releases?per_page=%d", g.baseURL, g.projectID, min(n, 100))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
@@ -202,19 +225,21 @@ func (g *GitLab) FetchLatestRelease(ctx context.Context) (*Release, error) {
 		return nil, fmt.Errorf("no releases found")
 	}
 
-	// Find the first release with valid APKs
+	// GitLab has no prerelease flag, so upcoming releases (released_at in the
+	// future) count as pre-releases
+	var result []*Release
 	for _, glRelease := range releases {
+		if glRelease.Upcoming && !g.IncludePreReleases {
+			continue
+		}
 		if !g.matchesReleaseFilter(glRelease.TagName) {
 			continue
 		}
-		release := g.convertRelease(&glRelease)
-		if HasValidAPKs(release.Assets) {
-			g.pendingVersion = release.Version
-			return release, nil
+		if release := g.convertRelease(&glRelease); HasValidAPKs(release.Assets) {
+			result = append(result, release)
 		}
 	}
-
-	return nil, fmt.Errorf("no releases with valid APKs found in the last %d releases", maxReleasesToCheck)
+	return result, nil
 }
 
 // ensureNumericProjectID loads the project's numeric id from the GitLab API once.
@@ -315,9 +340,12 @@ func (g *GitLab) convertRelease(glRelease *gitlabRelease) *Release {
 			}
 		}
 
+		// Release links carry no timestamps, so UpdatedAt stays zero
 		assets = append(assets, &Asset{
-			Name: assetName,
-			URL:  downloadURL,
+			Name:  assetName,
+			URL:   downloadURL,
+			Label: link.Name,
+			ID:    link.ID,
 		})
 	}
 
@@ -340,12 +368,13 @@ func (g *GitLab) convertRelease(glRelease *gitlabRelease) *Release {
 	}
 
 	return &Release{
-		Version:   version,
-		TagName:   glRelease.TagName,
-		Changelog: glRelease.Description,
-		Assets:    assets,
-		URL:       glRelease.Links.Self,
-		CreatedAt: createdAt,
+		Version:    version,
+		TagName:    glRelease.TagName,
+		Changelog:  glRelease.Description,
+		Assets:     assets,
+		URL:        glRelease.Links.Self,
+		CreatedAt:  createdAt,
+		PreRelease: glRelease.Upcoming,
 	}
 }
 
@@ -364,7 +393,7 @@ func (g *GitLab) Download(ctx context.Context, asset *Asset, destDir string, pro
 
 	// Create destination directory if needed
 	if destDir == "" {
-		destDir = os.TempDir()
+		destDir = tempdir.Dir()
 	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create destination directory: %w", err)
@@ -411,6 +440,7 @@ func (g *GitLab) Download(ctx context.Context, asset *Asset, destDir string, pro
 		Reader:  resp.Body,
 		Timeout: downloadStallTimeout,
 	}
+	reader = ratelimit.Reader(ctx, ratelimit.Download, reader) // --limit-rate, --max-download-rate
 
 	// Wrap with progress tracking if callback provided
 	if progress != nil && total > 0 {
@@ -442,7 +472,7 @@ func (g *GitLab) Download(ctx context.Context, asset *Asset, destDir string, pro
 // doAssetDownload GETs url and, when GitLab returns its external-redirect
 // interstitial (HTTP 200 HTML, no Location), follows the embedded href once.
 func (g *GitLab) doAssetDownload(ctx context.Context, client *http.Client, downloadURL string) (*http.Response, error) {
-	resp, err := getOK(ctx, client, downloadURL)
+	resp, err := getOK(ctx, client, downloadURL, g.DownloadHeaders)
 	if err != nil {
 		return nil, err
 	}
@@ -457,7 +487,7 @@ func (g *GitLab) doAssetDownload(ctx context.Context, client *http.Client, downl
 	}
 
 	resp.Body.Close()
-	resp, err = getOK(ctx, client, externalURL)
+	resp, err = getOK(ctx, client, externalURL, g.DownloadHeaders)
 	if err != nil {
 		return nil, fmt.Errorf("follow GitLab external redirect: %w", err)
 	}
@@ -474,11 +504,12 @@ func (g *GitLab) doAssetDownload(ctx context.Context, client *http.Client, downl
 	return resp, nil
 }
 
-func getOK(ctx context.Context, client *http.Client, downloadURL string) (*http.Response, error) {
+func getOK(ctx context.Context, client *http.Client, downloadURL string, headers map[string]string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	setDownloadHeaders(req, headers)
 	resp, err := DoWithTorFallback(ctx, client, req)
 	if err != nil {
 		return nil, err
@@ -425,3 +425,32 @@ func TestFetchLatestReleaseUsesDescriptionUploads(t *testing.T) {
 		t.Fatalf("numericProjectID = %d, want 6922885", g.numericProjectID)
 	}
 }
+
+func TestGitLabFetchLatestReleaseUpcoming(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects/acme%2Fapp", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":42}`))
+	})
+	mux.HandleFunc("/api/v4/projects/acme%2Fapp/releases", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+  {"tag_name": "v1.1.0", "released_at": "2099-01-01T00:00:00Z", "upcoming_release": true,
+   "assets": {"links": [{"name": "app-1.1.0.apk", "url": "https://example.com/app-1.1.0.apk"}]}},
+  {"tag_name": "v1.0.0", "released_at": "2025-01-01T00:00:00Z",
+   "assets": {"links": [{"name": "app-1.0.0.apk", "url": "https://example.com/app-1.0.0.apk"}]}}
+]`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, include := range []bool{false, true} {
+		g := &GitLab{cfg: &config.Config{}, baseURL: srv.URL, projectID: "acme%2Fapp", client: srv.Client(), cacheDir: t.TempDir(), IncludePreReleases: include}
+		release, err := g.FetchLatestRelease(context.Background())
+		if err != nil {
+			t.Fatalf("IncludePreReleases=%v: %v", include, err)
+		}
+		want := map[bool]string{false: "v1.0.0", true: "v1.1.0"}[include]
+		if release.TagName != want || release.PreRelease != include {
+			t.Errorf("IncludePreReleases=%v: got %s (pre-release %v), want %s", include, release.TagName, release.PreRelease, want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// GitLogReleaseNotes is the release_notes value (also set by --changelog-from
+// git) that generates release notes from the git commits since the previous tag.
+const GitLogReleaseNotes = "{git_log}"
+
+// conventionalCommitRe matches a Conventional Commits subject:
+// type(scope)!: description.
+var conventionalCommitRe = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// gitLogGroups orders the sections of generated release notes by commit type.
+// Commits of other conventional types go under "Other changes"; plain subjects
+// do too, as they are not known to be maintenance.
+var gitLogGroups = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug fixes"},
+	{"perf", "Performance"},
+	{"", "Other changes"},
+}
+
+// gitLogSkipped are maintenance commit types left out of release notes.
+var gitLogSkipped = map[string]bool{
+	"chore": true, "ci": true, "build": true, "test": true, "style": true, "docs": true,
+}
+
+// GitLogNotes generates Markdown release notes from the commits in the git
+// working tree at dir (the current directory when empty) between the previous
+// tag and the tag for version, grouped by Conventional Commits type. Without a
+// tag for version, commits since the latest tag up to HEAD are used; without
+// any earlier tag, the whole history is.
+func GitLogNotes(ctx context.Context, dir, version string) (string, error) {
+	if _, err := runGit(ctx, dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return "", fmt.Errorf("release notes from git log: %w", err)
+	}
+
+	current := "HEAD"
+	if tag := findVersionTag(ctx, dir, version); tag != "" {
+		current = tag
+	}
+	// The previous tag is the nearest one before current; for HEAD that may be
+	// a tag on HEAD itself, whose commits are already released
+	base := current
+	if current != "HEAD" {
+		base = current + "^"
+	}
+	rangeSpec := current
+	if out, err := runGit(ctx, dir, "describe", "--tags", "--abbrev=0", base); err == nil {
+		rangeSpec = strings.TrimSpace(string(out)) + ".." + current
+	}
+
+	out, err := runGit(ctx, dir, "log", "--no-merges", "--format=%s", rangeSpec)
+	if err != nil {
+		return "", fmt.Errorf("release notes from git log: %w", err)
+	}
+	return formatGitLog(strings.Split(strings.TrimSpace(string(out)), "\n")), nil
+}
+
+// findVersionTag returns the tag named version or v+version, or "" if neither exists.
+func findVersionTag(ctx context.Context, dir, version string) string {
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		return ""
+	}
+	for _, tag := range []string{"v" + version, version} {
+		if _, err := runGit(ctx, dir, "rev-parse", "-q", "--verify", "refs/tags/"+tag); err == nil {
+			return tag
+		}
+	}
+	return ""
+}
+
+// formatGitLog groups commit subjects (newest first) into Markdown sections.
+// Breaking changes (type!) are marked. It returns "" when no commit is kept.
+func formatGitLog(subjects []string) string {
+	sections := make(map[string][]string)
+	for _, subject := range subjects {
+		subject = strings.TrimSpace(subject)
+		if subject == "" {
+			continue
+		}
+		group, entry := "", subject
+		if m := conventionalCommitRe.FindStringSubmatch(subject); m != nil {
+			commitType := strings.ToLower(m[1])
+			if gitLogSkipped[commitType] {
+				continue
+			}
+			entry = m[4]
+			if m[2] != "" {
+				entry = "**" + m[2] + ":** " + entry
+			}
+			if m[3] != "" {
+				entry = "**Breaking:** " + entry
+			}
+			group = commitType
+		}
+		if !isGitLogGroup(group) {
+			group = ""
+		}
+		sections[group] = append(sections[group], "- "+entry)
+	}
+
+	var b strings.Builder
+	for _, g := range gitLogGroups {
+		entries := sections[g.Type]
+		if len(entries) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("### " + g.Title + "\n\n")
+		b.WriteString(strings.Join(entries, "\n") + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// isGitLogGroup reports whether commitType has its own release notes section.
+func isGitLogGroup(commitType string) bool {
+	for _, g := range gitLogGroups {
+		if g.Type == commitType {
+			return true
+		}
+	}
+	return false
+}
+
+// runGit runs a git command in dir and returns its stdout.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	name := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s failed: %s", name, msg)
+		}
+		return nil, fmt.Errorf("git %s failed: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
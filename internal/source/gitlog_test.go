@@ -0,0 +1,98 @@
+package source
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatGitLog(t *testing.T) {
+	got := formatGitLog([]string{
+		"feat(ui): dark mode",
+		"fix: crash on start",
+		"chore: bump deps",
+		"docs: typo",
+		"feat!: drop Android 7",
+		"Update translations",
+		"refactor: simplify sync",
+	})
+	want := `### Features
+
+- **ui:** dark mode
+- **Breaking:** drop Android 7
+
+### Bug fixes
+
+- crash on start
+
+### Other changes
+
+- Update translations
+- simplify sync`
+	if got != want {
+		t.Errorf("formatGitLog() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := formatGitLog([]string{"chore: release", ""}); got != "" {
+		t.Errorf("formatGitLog() of maintenance commits = %q, want empty", got)
+	}
+}
+
+func TestGitLogNotes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(subject string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "log.txt"), []byte(subject), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "log.txt")
+		git("commit", "-q", "-m", subject)
+	}
+
+	git("init", "-q")
+	commit("feat: first release")
+	git("tag", "v1.0.0")
+	commit("fix: login loop")
+	commit("feat: widgets")
+	git("tag", "v1.1.0")
+	commit("perf: faster sync")
+
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.1.0", "### Features\n\n- widgets\n\n### Bug fixes\n\n- login loop"},
+		{"v1.0.0", "### Features\n\n- first release"},
+		{"1.2.0", "### Performance\n\n- faster sync"}, // untagged: since the latest tag
+	}
+	for _, tt := range tests {
+		got, err := GitLogNotes(context.Background(), dir, tt.version)
+		if err != nil {
+			t.Fatalf("GitLogNotes(%q) error = %v", tt.version, err)
+		}
+		if got != tt.want {
+			t.Errorf("GitLogNotes(%q) =\n%s\nwant\n%s", tt.version, got, tt.want)
+		}
+	}
+
+	// release_notes: "{git_log}" goes through FetchReleaseNotes
+	if got, err := FetchReleaseNotes(context.Background(), GitLogReleaseNotes, "1.0.0", dir); err != nil || got != "### Features\n\n- first release" {
+		t.Errorf("FetchReleaseNotes(%q) = %q, %v", GitLogReleaseNotes, got, err)
+	}
+
+	if _, err := GitLogNotes(context.Background(), t.TempDir(), "1.0.0"); err == nil {
+		t.Error("GitLogNotes() outside a git working tree succeeded, want an error")
+	}
+}
@@ -89,6 +89,7 @@ func (l *Local) FetchLatestRelease(ctx context.Context) (*Release, error) {
 			Name:      filepath.Base(absPath),
 			LocalPath: absPath,
 			Size:      fi.Size(),
+			UpdatedAt: fi.ModTime(),
 		})
 	}
 
@@ -3,6 +3,8 @@ package source
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,22 +23,41 @@ import (
 
 // AppMetadata contains enriched app metadata from external sources.
 type AppMetadata struct {
-	Name        string
-	Description string
-	Summary     string
-	Website     string
-	License     string
-	Tags        []string
-	ImageURLs   []string
-	IconURL     string // URL to app icon (from Play Store or F-Droid)
+	Name         string
+	Description  string
+	Summary      string
+	Website      string
+	License      string
+	Tags         []string
+	ImageURLs    []string
+	IconURL      string // URL to app icon (from Play Store or F-Droid)
+	SourceURL    string // Where the metadata was fetched from (API endpoint or page)
+	ReleaseNotes string // Release notes file for this version, as release_notes (local Fastlane changelogs)
+}
+
+// ContentHash returns the hex SHA-256 of the fetched description and screenshot
+// set, so the listing content a source provided can be verified later.
+func (m *AppMetadata) ContentHash() string {
+	h := sha256.New()
+	h.Write([]byte(m.Description))
+	for _, u := range m.ImageURLs {
+		h.Write([]byte("\n" + u))
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // MetadataFetcher fetches metadata from external sources.
 type MetadataFetcher struct {
-	cfg       *config.Config
-	client    *http.Client
-	PackageID string // App package ID (e.g., "com.example.app") - set from APK parsing
-	APKName   string // App name from APK - takes priority over metadata sources
+	cfg         *config.Config
+	client      *http.Client
+	PackageID   string // App package ID (e.g., "com.example.app") - set from APK parsing
+	APKName     string // App name from APK - takes priority over metadata sources
+	VersionCode int64  // APK version code, selects the Fastlane changelog
+
+	// GitHubRepo is repository metadata already fetched with the release
+	// (Release.Repository). It replaces the repository API request when it is
+	// for the configured repository.
+	GitHubRepo *AppMetadata
 }
 
 // NewMetadataFetcher creates a new metadata fetcher.
@@ -103,6 +125,10 @@ func DefaultMetadataSources(cfg *config.Config) []string {
 		// No native Gitea repo-metadata source yet — Fastlane only.
 		return []string{"fastlane"}
 	default:
+		// A local Fastlane directory needs no repository
+		if hasLocalFastlane(cfg) {
+			return []string{"fastlane"}
+		}
 		return nil
 	}
 }
@@ -153,6 +179,41 @@ type MetadataResult struct {
 	// Errors contains non-fatal errors from individual sources.
 	// The fetch continues even if some sources fail.
 	Errors []*MetadataError
+
+	// Fetched holds each successful source's metadata, in fetch order.
+	Fetched []SourceMetadata
+
+	// Config holds the values the config set before fetched metadata was merged.
+	Config AppMetadata
+
+	// APKName is the app name from the APK, used for the name field.
+	APKName string
+}
+
+// SourceMetadata is the metadata returned by one source.
+type SourceMetadata struct {
+	Source string
+	Meta   *AppMetadata
+}
+
+// Provenance records one metadata source that was fetched.
+type Provenance struct {
+	Source string // e.g. "fastlane", "fdroid"
+	URL    string
+	SHA256 string // AppMetadata.ContentHash of what the source returned
+}
+
+// Provenance lists every successfully fetched source, in fetch order.
+func (r *MetadataResult) Provenance() []Provenance {
+	out := make([]Provenance, 0, len(r.Fetched))
+	for _, fetched := range r.Fetched {
+		out = append(out, Provenance{
+			Source: fetched.Source,
+			URL:    fetched.Meta.SourceURL,
+			SHA256: fetched.Meta.ContentHash(),
+		})
+	}
+	return out
 }
 
 // HasErrors returns true if any metadata sources failed.
@@ -171,7 +232,7 @@ func (f *MetadataFetcher) FetchMetadata(ctx context.Context, sources []string) e
 
 // FetchMetadataWithResult fetches metadata and returns detailed results including partial failures.
 func (f *MetadataFetcher) FetchMetadataWithResult(ctx context.Context, sources []string) *MetadataResult {
-	result := &MetadataResult{}
+	result := f.newResult()
 
 	for _, source := range sources {
 		source = strings.TrimSpace(strings.ToLower(source))
@@ -183,11 +244,10 @@ func (f *MetadataFetcher) FetchMetadataWithResult(ctx context.Context, sources [
 			})
 			continue
 		}
-
-		// Merge metadata into config (only fill empty fields)
-		f.mergeMetadata(meta)
+		result.Fetched = append(result.Fetched, SourceMetadata{Source: source, Meta: meta})
 	}
 
+	f.mergeFetched(result)
 	return result
 }
 
@@ -201,10 +261,11 @@ func (f *MetadataFetcher) FetchAutomaticMetadata(ctx context.Context, fallback s
 // FetchAutomaticMetadataWithResult uses Fastlane metadata when present and
 // returns individual source failures without making them fatal.
 func (f *MetadataFetcher) FetchAutomaticMetadataWithResult(ctx context.Context, fallback string) *MetadataResult {
-	result := &MetadataResult{}
+	result := f.newResult()
 	meta, err := f.fetchMetadataSource(ctx, "fastlane")
 	if err == nil {
-		f.mergeMetadata(meta)
+		result.Fetched = append(result.Fetched, SourceMetadata{Source: "fastlane", Meta: meta})
+		f.mergeFetched(result)
 		return result
 	}
 	if !errors.Is(err, errFastlaneUnavailable) {
@@ -217,10 +278,33 @@ func (f *MetadataFetcher) FetchAutomaticMetadataWithResult(ctx context.Context,
 		result.Errors = append(result.Errors, &MetadataError{Source: fallback, Err: err})
 		return result
 	}
-	f.mergeMetadata(meta)
+	result.Fetched = append(result.Fetched, SourceMetadata{Source: fallback, Meta: meta})
+	f.mergeFetched(result)
 	return result
 }
 
+// newResult starts a result, recording the config's values before anything is merged.
+func (f *MetadataFetcher) newResult() *MetadataResult {
+	return &MetadataResult{
+		Config: AppMetadata{
+			Name:        f.cfg.Name,
+			Description: f.cfg.Description,
+			Summary:     f.cfg.Summary,
+			Website:     f.cfg.Website,
+			License:     f.cfg.License,
+			Tags:        f.cfg.Tags,
+		},
+		APKName: f.APKName,
+	}
+}
+
+// mergeFetched merges every fetched source into config in fetch order.
+func (f *MetadataFetcher) mergeFetched(result *MetadataResult) {
+	for _, fetched := range result.Fetched {
+		f.mergeMetadata(fetched.Meta)
+	}
+}
+
 func (f *MetadataFetcher) fetchMetadataSource(ctx context.Context, source string) (*AppMetadata, error) {
 	switch source {
 	case "fastlane":
@@ -260,6 +344,12 @@ func (f *MetadataFetcher) fetchGitHubMetadata(ctx context.Context) (*AppMetadata
 
 	// Fetch repository info
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	if f.GitHubRepo != nil && strings.EqualFold(f.GitHubRepo.SourceURL, url) {
+		meta := *f.GitHubRepo
+		meta.Tags = slices.Clone(meta.Tags)
+		f.extendGitHubDescription(ctx, &meta, owner, repo)
+		return &meta, nil
+	}
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -299,25 +389,30 @@ func (f *MetadataFetcher) fetchGitHubMetadata(ctx context.Context) (*AppMetadata
 		Description: repoInfo.Description,
 		Website:     repoInfo.Homepage,
 		Tags:        repoInfo.Topics,
+		SourceURL:   url,
 	}
 
 	if repoInfo.License != nil && repoInfo.License.SPDXID != "" && repoInfo.License.SPDXID != "NOASSERTION" {
 		meta.License = repoInfo.License.SPDXID
 	}
 
-	// Try to fetch README for a longer description if the repo description is short
-	if len(repoInfo.Description) < 100 {
-		readme, err := f.fetchGitHubReadme(ctx, owner, repo)
-		if err == nil && readme != "" {
-			// Use first paragraph of README as description
-			firstPara := extractFirstParagraph(readme)
-			if len(firstPara) > len(repoInfo.Description) {
-				meta.Description = firstPara
-			}
+	f.extendGitHubDescription(ctx, meta, owner, repo)
+	return meta, nil
+}
+
+// extendGitHubDescription replaces a short repository description with the
+// README's first paragraph when that is longer.
+func (f *MetadataFetcher) extendGitHubDescription(ctx context.Context, meta *AppMetadata, owner, repo string) {
+	if len(meta.Description) >= 100 {
+		return
+	}
+	readme, err := f.fetchGitHubReadme(ctx, owner, repo)
+	if err == nil && readme != "" {
+		// Use first paragraph of README as description
+		if firstPara := extractFirstParagraph(readme); len(firstPara) > len(meta.Description) {
+			meta.Description = firstPara
 		}
 	}
-
-	return meta, nil
 }
 
 // fetchGitHubReadme fetches the README content from GitHub.
@@ -411,6 +506,7 @@ func (f *MetadataFetcher) fetchGitLabMetadata(ctx context.Context) (*AppMetadata
 		Description: projectInfo.Description,
 		Website:     projectInfo.WebURL,
 		Tags:        projectInfo.Topics,
+		SourceURL:   url,
 	}
 
 	if projectInfo.License != nil && projectInfo.License.Key != "" {
@@ -498,6 +594,7 @@ func (f *MetadataFetcher) fetchFDroidMetadata(ctx context.Context) (*AppMetadata
 	meta := &AppMetadata{
 		IconURL:   webMeta.IconURL,
 		ImageURLs: webMeta.ImageURLs,
+		SourceURL: fmt.Sprintf("https://f-droid.org/en/packages/%s/", packageID),
 	}
 
 	// Merge YAML metadata if available
@@ -686,6 +783,7 @@ func (f *MetadataFetcher) fetchPlayStoreMetadata(ctx context.Context) (*AppMetad
 		Description: psMeta.Description,
 		ImageURLs:   psMeta.ImageURLs,
 		IconURL:     psMeta.IconURL,
+		SourceURL:   ps.URL(),
 	}
 
 	return meta, nil
@@ -727,6 +825,118 @@ func (f *MetadataFetcher) mergeMetadata(meta *AppMetadata) {
 	if f.cfg.Icon == "" && meta.IconURL != "" {
 		f.cfg.Icon = meta.IconURL
 	}
+	if f.cfg.ReleaseNotes == "" && meta.ReleaseNotes != "" {
+		f.cfg.ReleaseNotes = meta.ReleaseNotes
+	}
+}
+
+// Metadata fields that can be reconciled when sources disagree.
+const (
+	FieldName        = "name"
+	FieldSummary     = "summary"
+	FieldDescription = "description"
+	FieldWebsite     = "website"
+	FieldLicense     = "license"
+	FieldTags        = "tags"
+)
+
+// reconcilableFields lists the fields Conflicts compares, in display order.
+var reconcilableFields = []string{FieldName, FieldSummary, FieldDescription, FieldWebsite, FieldLicense, FieldTags}
+
+// MetadataCandidate is one source's value for a metadata field.
+type MetadataCandidate struct {
+	Source string // "config", "apk", or a metadata source name
+	Value  string // Tags are joined with ", "
+}
+
+// MetadataConflict is a field for which sources provided different values.
+// Candidates are in precedence order: the first is the value that is used.
+type MetadataConflict struct {
+	Field      string
+	Candidates []MetadataCandidate
+}
+
+// Conflicts returns the fields for which more than one distinct value was available.
+func (r *MetadataResult) Conflicts() []MetadataConflict {
+	var conflicts []MetadataConflict
+	for _, field := range reconcilableFields {
+		var candidates []MetadataCandidate
+		add := func(source, value string) {
+			if value == "" {
+				return
+			}
+			for _, c := range candidates {
+				if c.Value == value {
+					return
+				}
+			}
+			candidates = append(candidates, MetadataCandidate{Source: source, Value: value})
+		}
+
+		add("config", metadataFieldValue(&r.Config, field))
+		if field == FieldName {
+			add("apk", r.APKName)
+		}
+		for _, fetched := range r.Fetched {
+			add(fetched.Source, metadataFieldValue(fetched.Meta, field))
+		}
+
+		if len(candidates) > 1 {
+			conflicts = append(conflicts, MetadataConflict{Field: field, Candidates: candidates})
+		}
+	}
+	return conflicts
+}
+
+// metadataFieldValue returns meta's value for field as a string.
+func metadataFieldValue(meta *AppMetadata, field string) string {
+	if meta == nil {
+		return ""
+	}
+	switch field {
+	case FieldName:
+		return meta.Name
+	case FieldSummary:
+		return meta.Summary
+	case FieldDescription:
+		return meta.Description
+	case FieldWebsite:
+		return meta.Website
+	case FieldLicense:
+		return meta.License
+	case FieldTags:
+		return strings.Join(meta.Tags, ", ")
+	}
+	return ""
+}
+
+// SetMetadataField sets a reconcilable field on cfg from a candidate value.
+func SetMetadataField(cfg *config.Config, field, value string) {
+	switch field {
+	case FieldName:
+		cfg.Name = value
+	case FieldSummary:
+		cfg.Summary = value
+	case FieldDescription:
+		cfg.Description = value
+	case FieldWebsite:
+		cfg.Website = value
+	case FieldLicense:
+		cfg.License = value
+	case FieldTags:
+		cfg.Tags = splitTags(value)
+	}
+}
+
+// splitTags splits a ", "-joined tag list.
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
 }
 
 // extractFirstParagraph extracts the first meaningful paragraph from markdown.
@@ -771,10 +981,15 @@ func extractFirstParagraph(markdown string) string {
 	return result
 }
 
-// FetchReleaseNotes fetches release notes from a URL or local file.
-// If the content follows the Keep a Changelog format and a version is provided,
-// only the section for that version is extracted.
+// FetchReleaseNotes fetches release notes from a URL or local file, or
+// generates them from git commits for GitLogReleaseNotes. If the content
+// follows the Keep a Changelog format and a version is provided, only the
+// section for that version is extracted.
 func FetchReleaseNotes(ctx context.Context, pathOrURL string, version string, baseDir string) (string, error) {
+	if pathOrURL == GitLogReleaseNotes {
+		return GitLogNotes(ctx, baseDir, version)
+	}
+
 	var content string
 
 	if strings.HasPrefix(pathOrURL, "http://") || strings.HasPrefix(pathOrURL, "https://") {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -476,6 +478,77 @@ func TestFetchAutomaticMetadataFallback(t *testing.T) {
 	})
 }
 
+func TestFetchLocalFastlaneMetadata(t *testing.T) {
+	baseDir := t.TempDir()
+	files := map[string]string{
+		"de-DE/title.txt":                         "Geraet",
+		"en-US/title.txt":                         " Gadget \n",
+		"en-US/short_description.txt":             "A short description\n",
+		"en-US/full_description.txt":              "A full description\n",
+		"en-US/images/icon.png":                   "png",
+		"en-US/images/phoneScreenshots/02.png":    "png",
+		"en-US/images/phoneScreenshots/01.png":    "png",
+		"en-US/images/phoneScreenshots/.DS_Store": "",
+		"en-US/changelogs/42.txt":                 "Fixed the widget\n",
+		"en-US/changelogs/default.txt":            "Bug fixes\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(baseDir, fastlaneMetadataPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{Repository: "https://github.com/owner/app", BaseDir: baseDir}
+	fetcher := NewMetadataFetcher(cfg)
+	fetcher.VersionCode = 42
+	fetcher.client = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("local Fastlane metadata must not be fetched remotely: %s", req.URL)
+		return nil, nil
+	})}
+
+	if err := fetcher.FetchMetadata(context.Background(), []string{"fastlane"}); err != nil {
+		t.Fatalf("FetchMetadata() error = %v", err)
+	}
+	locale := fastlaneMetadataPath + "/en-US"
+	if cfg.Name != "Gadget" || cfg.Summary != "A short description" || cfg.Description != "A full description" {
+		t.Errorf("name/summary/description = %q, %q, %q", cfg.Name, cfg.Summary, cfg.Description)
+	}
+	if cfg.Icon != locale+"/images/icon.png" {
+		t.Errorf("Icon = %q", cfg.Icon)
+	}
+	wantImages := []string{locale + "/images/phoneScreenshots/01.png", locale + "/images/phoneScreenshots/02.png"}
+	if strings.Join(cfg.Images, ",") != strings.Join(wantImages, ",") {
+		t.Errorf("Images = %v, want %v", cfg.Images, wantImages)
+	}
+	if cfg.ReleaseNotes != locale+"/changelogs/42.txt" {
+		t.Errorf("ReleaseNotes = %q, want the versionCode changelog", cfg.ReleaseNotes)
+	}
+	notes, err := FetchReleaseNotes(context.Background(), cfg.ReleaseNotes, "1.0.0", cfg.BaseDir)
+	if err != nil || notes != "Fixed the widget\n" {
+		t.Errorf("FetchReleaseNotes() = %q, %v", notes, err)
+	}
+
+	// Without a changelog for the version, default.txt is used
+	fetcher = NewMetadataFetcher(&config.Config{BaseDir: baseDir})
+	fetcher.VersionCode = 43
+	meta, err := fetcher.fetchFastlaneMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("fetchFastlaneMetadata() error = %v", err)
+	}
+	if meta.ReleaseNotes != locale+"/changelogs/default.txt" {
+		t.Errorf("ReleaseNotes = %q, want default.txt", meta.ReleaseNotes)
+	}
+
+	// A local Fastlane directory is used automatically, even without a repository
+	if got := DefaultMetadataSources(&config.Config{BaseDir: baseDir}); strings.Join(got, ",") != "fastlane" {
+		t.Errorf("DefaultMetadataSources() = %v, want [fastlane]", got)
+	}
+}
+
 func TestFetchFastlaneMetadataErrors(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -513,3 +586,66 @@ func TestFetchFastlaneMetadataErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadataResultConflicts(t *testing.T) {
+	result := &MetadataResult{
+		Config:  AppMetadata{Name: "Acme Wallet", License: "MIT"},
+		APKName: "Acme",
+		Fetched: []SourceMetadata{
+			{Source: "github", Meta: &AppMetadata{Name: "acme-wallet-android", License: "MIT", Tags: []string{"bitcoin", "wallet"}}},
+			{Source: "fdroid", Meta: &AppMetadata{Summary: "A wallet", Tags: []string{"wallet"}}},
+		},
+	}
+
+	conflicts := result.Conflicts()
+	if len(conflicts) != 2 {
+		t.Fatalf("Conflicts() = %+v, want name and tags", conflicts)
+	}
+
+	name := conflicts[0]
+	if name.Field != FieldName || len(name.Candidates) != 3 {
+		t.Fatalf("name conflict = %+v", name)
+	}
+	if name.Candidates[0] != (MetadataCandidate{Source: "config", Value: "Acme Wallet"}) ||
+		name.Candidates[1].Source != "apk" || name.Candidates[2].Source != "github" {
+		t.Errorf("name candidates out of precedence order: %+v", name.Candidates)
+	}
+
+	tags := conflicts[1]
+	if tags.Field != FieldTags || tags.Candidates[0].Value != "bitcoin, wallet" {
+		t.Errorf("tags conflict = %+v", tags)
+	}
+
+	cfg := &config.Config{}
+	SetMetadataField(cfg, FieldTags, tags.Candidates[1].Value)
+	SetMetadataField(cfg, FieldName, name.Candidates[2].Value)
+	if len(cfg.Tags) != 1 || cfg.Tags[0] != "wallet" || cfg.Name != "acme-wallet-android" {
+		t.Errorf("SetMetadataField() cfg = name %q tags %v", cfg.Name, cfg.Tags)
+	}
+}
+
+func TestMetadataResultProvenance(t *testing.T) {
+	fdroid := &AppMetadata{
+		Description: "A wallet",
+		ImageURLs:   []string{"https://f-droid.org/repo/1.png", "https://f-droid.org/repo/2.png"},
+		SourceURL:   "https://f-droid.org/en/packages/com.acme.wallet/",
+	}
+	result := &MetadataResult{Fetched: []SourceMetadata{{Source: "fdroid", Meta: fdroid}}}
+
+	prov := result.Provenance()
+	if len(prov) != 1 || prov[0].Source != "fdroid" || prov[0].URL != fdroid.SourceURL {
+		t.Fatalf("Provenance() = %+v", prov)
+	}
+	if len(prov[0].SHA256) != 64 {
+		t.Errorf("SHA256 = %q, want hex digest", prov[0].SHA256)
+	}
+
+	// Reordering screenshots or changing the description changes the hash
+	reordered := *fdroid
+	reordered.ImageURLs = []string{fdroid.ImageURLs[1], fdroid.ImageURLs[0]}
+	edited := *fdroid
+	edited.Description = "A wallet!"
+	if reordered.ContentHash() == prov[0].SHA256 || edited.ContentHash() == prov[0].SHA256 {
+		t.Error("ContentHash() did not change with the content")
+	}
+}
@@ -35,9 +35,14 @@ type PlayStoreMetadata struct {
 	ImageURLs   []string
 }
 
+// URL returns the Play Store listing page the metadata is scraped from.
+func (p *PlayStore) URL() string {
+	return fmt.Sprintf("https://play.google.com/store/apps/details?id=%s&hl=en_US", p.packageID)
+}
+
 // FetchMetadata fetches app metadata from the Google Play Store.
 func (p *PlayStore) FetchMetadata(ctx context.Context) (*PlayStoreMetadata, error) {
-	url := fmt.Sprintf("https://play.google.com/store/apps/details?id=%s&hl=en_US", p.packageID)
+	url := p.URL()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
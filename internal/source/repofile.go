@@ -0,0 +1,129 @@
+package source
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ratelimit"
+)
+
+// ErrRepoFileNotFound is returned by FetchRepoFile when the repository has no
+// file at the requested path and ref. Network and server failures are not
+// wrapped in it.
+var ErrRepoFileNotFound = errors.New("path not found in repository")
+
+// RepoFileURL returns the raw content URL of path at ref (a tag, or "" for the
+// default branch) in the configured repository. GitHub, GitLab, and
+// Gitea-compatible forges are supported; without a repository, a forge
+// release_source is used.
+func RepoFileURL(cfg *config.Config, ref, path string) (string, error) {
+	repoURL, host := cfg.Repository, repositoryMetadataHost(cfg)
+	if host == config.SourceUnknown && cfg.ReleaseSource != nil {
+		switch t := cfg.GetSourceType(); t {
+		case config.SourceGitHub, config.SourceGitLab, config.SourceGitea:
+			repoURL, host = cfg.ReleaseSource.URL, t
+		}
+	}
+
+	switch host {
+	case config.SourceGitHub:
+		repoPath := config.GetGitHubRepo(repoURL)
+		if repoPath == "" {
+			break
+		}
+		return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s",
+			repoPath, url.PathEscape(cmp.Or(ref, "HEAD")), escapeRepoPath(path)), nil
+	case config.SourceGitLab:
+		baseURL, repoPath := config.GetGitLabRepoWithBase(repoURL)
+		if repoPath == "" {
+			break
+		}
+		return fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s/raw?ref=%s",
+			baseURL, url.PathEscape(repoPath), url.PathEscape(path), url.QueryEscape(cmp.Or(ref, "HEAD"))), nil
+	case config.SourceGitea:
+		baseURL, repoPath := config.GetGiteaRepo(repoURL)
+		if repoPath == "" {
+			break
+		}
+		rawURL := fmt.Sprintf("%s/api/v1/repos/%s/raw/%s", baseURL, repoPath, escapeRepoPath(path))
+		if ref != "" {
+			rawURL += "?ref=" + url.QueryEscape(ref)
+		}
+		return rawURL, nil
+	default:
+		return "", fmt.Errorf("repo: paths need a GitHub, GitLab, or Gitea/Codeberg repository")
+	}
+	return "", fmt.Errorf("repo: paths need a repository URL with an owner and name, got %q", repoURL)
+}
+
+// FetchRepoFile downloads path at ref from the configured repository (see
+// RepoFileURL). A missing file is reported as ErrRepoFileNotFound naming the
+// tag, so it reads differently from a failed request.
+func FetchRepoFile(ctx context.Context, cfg *config.Config, ref, path string) ([]byte, error) {
+	rawURL, err := RepoFileURL(cfg, ref, path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating repository file request: %w", err)
+	}
+	switch {
+	case req.URL.Hostname() == "raw.githubusercontent.com":
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	case strings.Contains(rawURL, "/api/v1/repos/"):
+		if token := os.Getenv("GITEA_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "token "+token)
+		}
+	}
+
+	resp, err := newSecureHTTPClient(60 * time.Second).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s from repository: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s at %s", ErrRepoFileNotFound, path, describeRef(ref))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s from repository: server returned %d", path, resp.StatusCode)
+	}
+
+	reader := ratelimit.Reader(ctx, ratelimit.Download, resp.Body) // --limit-rate, --max-download-rate
+	data, err := io.ReadAll(io.LimitReader(reader, MaxRemoteDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s from repository: %w", path, err)
+	}
+	if len(data) > MaxRemoteDownloadSize {
+		return nil, fmt.Errorf("repository file %s exceeds %d bytes", path, MaxRemoteDownloadSize)
+	}
+	return data, nil
+}
+
+func describeRef(ref string) string {
+	if ref == "" {
+		return "HEAD"
+	}
+	return "tag " + ref
+}
+
+// escapeRepoPath escapes each segment of a slash-separated repository path.
+func escapeRepoPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,119 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/config"
+)
+
+func TestRepoFileURL(t *testing.T) {
+	const icon = "fastlane/metadata/android/en-US/images/icon.png"
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		ref  string
+		path string
+		want string
+	}{
+		{
+			name: "github tag",
+			cfg:  &config.Config{Repository: "https://github.com/owner/app"},
+			ref:  "v1.2.0",
+			path: icon,
+			want: "https://raw.githubusercontent.com/owner/app/v1.2.0/" + icon,
+		},
+		{
+			name: "github without tag uses HEAD",
+			cfg:  &config.Config{Repository: "https://github.com/owner/app"},
+			path: "docs/my shot.png",
+			want: "https://raw.githubusercontent.com/owner/app/HEAD/docs/my%20shot.png",
+		},
+		{
+			name: "gitlab",
+			cfg:  &config.Config{Repository: "https://gitlab.com/group/sub/app"},
+			ref:  "v1.2.0",
+			path: icon,
+			want: "https://gitlab.com/api/v4/projects/group%2Fsub%2Fapp/repository/files/" +
+				"fastlane%2Fmetadata%2Fandroid%2Fen-US%2Fimages%2Ficon.png/raw?ref=v1.2.0",
+		},
+		{
+			name: "codeberg",
+			cfg:  &config.Config{Repository: "https://codeberg.org/owner/app"},
+			ref:  "1.0",
+			path: icon,
+			want: "https://codeberg.org/api/v1/repos/owner/app/raw/" + icon + "?ref=1.0",
+		},
+		{
+			name: "self-hosted gitea release source",
+			cfg: &config.Config{ReleaseSource: &config.ReleaseSource{
+				URL: "https://git.example.com/owner/app", Type: "gitea",
+			}},
+			path: "icon.png",
+			want: "https://git.example.com/api/v1/repos/owner/app/raw/icon.png",
+		},
+		{
+			name: "github release source without repository",
+			cfg:  &config.Config{ReleaseSource: &config.ReleaseSource{URL: "https://github.com/owner/app"}},
+			ref:  "v2",
+			path: "icon.png",
+			want: "https://raw.githubusercontent.com/owner/app/v2/icon.png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RepoFileURL(tt.cfg, tt.ref, tt.path)
+			if err != nil {
+				t.Fatalf("RepoFileURL() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RepoFileURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	unsupported := []*config.Config{
+		{Repository: "https://example.com/owner/app"},
+		{ReleaseSource: &config.ReleaseSource{LocalPath: "./app.apk"}},
+		{Repository: "https://github.com/owner"},
+	}
+	for _, cfg := range unsupported {
+		if got, err := RepoFileURL(cfg, "v1", "icon.png"); err == nil {
+			t.Errorf("RepoFileURL(%+v) = %q, want error", cfg, got)
+		}
+	}
+}
+
+func TestFetchRepoFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/app/raw/icon.png" || r.URL.Query().Get("ref") != "v1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{ReleaseSource: &config.ReleaseSource{URL: server.URL + "/owner/app", Type: "gitea"}}
+	ctx := context.Background()
+
+	data, err := FetchRepoFile(ctx, cfg, "v1.0", "icon.png")
+	if err != nil || string(data) != "png" {
+		t.Fatalf("FetchRepoFile() = %q, %v", data, err)
+	}
+
+	_, err = FetchRepoFile(ctx, cfg, "v0.9", "icon.png")
+	if !errors.Is(err, ErrRepoFileNotFound) || !strings.Contains(err.Error(), "icon.png at tag v0.9") {
+		t.Errorf("missing file: got %v, want ErrRepoFileNotFound naming the tag", err)
+	}
+
+	server.Close()
+	_, err = FetchRepoFile(ctx, cfg, "v1.0", "icon.png")
+	if err == nil || errors.Is(err, ErrRepoFileNotFound) {
+		t.Errorf("network failure: got %v, want an error other than ErrRepoFileNotFound", err)
+	}
+}
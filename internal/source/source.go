@@ -19,6 +19,11 @@ import (
 
 	"github.com/zapstore/zsp/internal/apk"
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/netclock"
+	"github.com/zapstore/zsp/internal/netpolicy"
+	"github.com/zapstore/zsp/internal/nettrace"
+	"github.com/zapstore/zsp/internal/ratelimit"
+	"github.com/zapstore/zsp/internal/tempdir"
 	"golang.org/x/net/proxy"
 )
 
@@ -56,12 +61,39 @@ const downloadMaxAttempts = 3
 // downloadRetryBackoff is the base delay between download attempts.
 const downloadRetryBackoff = 1 * time.Second
 
+// maxRedirects caps the redirect chains followed when resolving and downloading assets.
+const maxRedirects = 10
+
+// Redirect policy errors, returned wrapped by asset resolution and downloads.
+var (
+	ErrTooManyRedirects  = errors.New("too many redirects")
+	ErrRedirectDowngrade = errors.New("redirect downgrades from https to http")
+)
+
+// checkRedirect is the CheckRedirect policy for asset URLs: at most maxRedirects
+// hops, and no step from https to http anywhere in the chain.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("%w: stopped after %d", ErrTooManyRedirects, maxRedirects)
+	}
+	if req.URL.Scheme == "http" {
+		for _, prev := range via {
+			if prev.URL.Scheme == "https" {
+				return fmt.Errorf("%w: https://%s redirects to http://%s", ErrRedirectDowngrade, prev.URL.Host, req.URL.Host)
+			}
+		}
+	}
+	return nil
+}
+
 // newDownloadHTTPClient creates an HTTP client for large file downloads.
 // Unlike newSecureHTTPClient, it does NOT set a total request timeout.
 // Instead, the caller should wrap the response body with a StallTimeoutReader
 // to detect stalled downloads. Retries once through Tor on HTTP 403.
+// Redirects follow checkRedirect.
 func newDownloadHTTPClient() *http.Client {
 	return &http.Client{
+		CheckRedirect: checkRedirect,
 		Transport: withTorFallback(&http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
@@ -88,7 +120,7 @@ func newTorHTTPClient() (*http.Client, error) {
 	}
 
 	return &http.Client{
-		Transport: &http.Transport{
+		Transport: nettrace.Wrap(&http.Transport{
 			DialContext: contextDialer.DialContext,
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
@@ -97,7 +129,7 @@ func newTorHTTPClient() (*http.Client, error) {
 			MaxIdleConnsPerHost:   10,
 			IdleConnTimeout:       90 * time.Second,
 			ResponseHeaderTimeout: 30 * time.Second,
-		},
+		}),
 	}, nil
 }
 
@@ -112,23 +144,25 @@ func SetTorHTTPClientForTest(fn func() (*http.Client, error)) func() {
 }
 
 // torFallbackTransport retries a request once through Tor when the direct
-// response is HTTP 403. The Tor retry is unauthenticated.
+// response is HTTP 403. The Tor retry is unauthenticated. Every request is
+// checked against the network allowlist first, so all source clients enforce it.
 type torFallbackTransport struct {
 	base http.RoundTripper
 }
 
 func withTorFallback(base http.RoundTripper) http.RoundTripper {
-	if base == nil {
-		base = http.DefaultTransport
-	}
-	return &torFallbackTransport{base: base}
+	return &torFallbackTransport{base: nettrace.Wrap(base)}
 }
 
 func (t *torFallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := netpolicy.CheckURL(req.URL.String()); err != nil {
+		return nil, err
+	}
 	resp, err := t.base.RoundTrip(req)
 	if err != nil {
 		return nil, err
 	}
+	netclock.Observe(resp)
 	if resp.StatusCode != http.StatusForbidden {
 		return resp, nil
 	}
@@ -253,23 +287,59 @@ const maxReleasesToCheck = 10
 
 // Asset represents a downloadable APK asset.
 type Asset struct {
-	Name        string // Filename
-	URL         string // Download URL (empty for local files)
-	Size        int64  // Size in bytes (0 if unknown)
-	LocalPath   string // Local file path (set after download or for local sources)
-	ContentType string // MIME type (if known)
-	ExcludeURL  bool   // If true, don't include URL in event (use Blossom URL only)
+	Name        string    // Filename
+	URL         string    // Download URL (empty for local files)
+	Size        int64     // Size in bytes (0 if unknown)
+	LocalPath   string    // Local file path (set after download or for local sources)
+	ContentType string    // MIME type (if known)
+	ExcludeURL  bool      // If true, don't include URL in event (use Blossom URL only)
+	Label       string    // Display label set on the forge (GitHub asset label, GitLab link name)
+	ID          int64     // Forge asset ID (0 if unknown)
+	UpdatedAt   time.Time // When the file was last uploaded or modified on the source (zero if unknown)
+	SHA256      string    // Hex SHA-256 digest published by the forge ("" if unknown)
 }
 
 // Release represents a release containing one or more APK assets.
 type Release struct {
-	Version    string    // Version string (e.g., "1.2.3" or "v1.2.3")
-	TagName    string    // Git tag name (if applicable)
-	Changelog  string    // Release notes/changelog
-	Assets     []*Asset  // Available APK assets
-	PreRelease bool      // Whether this is a pre-release
-	URL        string    // Release page URL (e.g., https://github.com/user/repo/releases/tag/v1.0)
-	CreatedAt  time.Time // Release creation/publish date (zero if unknown)
+	Version       string       // Version string (e.g., "1.2.3" or "v1.2.3")
+	TagName       string       // Git tag name (if applicable)
+	Changelog     string       // Release notes/changelog
+	Assets        []*Asset     // Available APK assets
+	PreRelease    bool         // Whether this is a pre-release
+	URL           string       // Release page URL (e.g., https://github.com/user/repo/releases/tag/v1.0)
+	DiscussionURL string       // Discussion announcing the release, if the forge created one
+	CreatedAt     time.Time    // Release creation/publish date (zero if unknown)
+	Repository    *AppMetadata // Repository metadata fetched along with the release (GitHub GraphQL), nil otherwise
+}
+
+// ReleaseLink returns the link append_release_link adds to the release notes:
+// the release's discussion if there is one, else its release page.
+func (r *Release) ReleaseLink() string {
+	if r.DiscussionURL != "" {
+		return r.DiscussionURL
+	}
+	return r.URL
+}
+
+// LastChangedAt returns when the release last changed: the later of its publish
+// date and its newest asset's UpdatedAt. Zero if neither is known.
+func (r *Release) LastChangedAt() time.Time {
+	changed := r.CreatedAt
+	for _, a := range r.Assets {
+		if a.UpdatedAt.After(changed) {
+			changed = a.UpdatedAt
+		}
+	}
+	return changed
+}
+
+// parseRFC3339 parses a forge API timestamp, returning zero when it is empty or invalid.
+func parseRFC3339(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
 }
 
 // Source is the interface for APK sources.
@@ -302,12 +372,17 @@ type Options struct {
 	// SkipCache bypasses ETag cache for GitHub sources (--overwrite-release).
 	SkipCache bool
 
-	// IncludePreReleases includes pre-releases when fetching the latest release (--pre-release).
+	// IncludePreReleases includes pre-releases when fetching the latest release
+	// (--pre-release). The config's include_pre_releases enables it as well.
 	IncludePreReleases bool
 
 	// SkipDownloadCache skips saving downloaded APKs to the download cache.
 	// Used in --quiet mode and for transient operations like --check.
 	SkipDownloadCache bool
+
+	// StrictRedirects fails web sources whose asset URL now redirects to a
+	// different host than at the last publish (--strict-redirects).
+	StrictRedirects bool
 }
 
 // New creates a new source based on the config.
@@ -318,6 +393,8 @@ func New(cfg *config.Config) (Source, error) {
 // NewWithOptions creates a new source with options.
 func NewWithOptions(cfg *config.Config, opts Options) (Source, error) {
 	sourceType := cfg.GetSourceType()
+	downloadHeaders := cfg.ReleaseSource.DownloadHeaders()
+	includePreReleases := opts.IncludePreReleases || cfg.IncludePreReleases
 
 	switch sourceType {
 	case config.SourceLocal:
@@ -332,30 +409,36 @@ func NewWithOptions(cfg *config.Config, opts Options) (Source, error) {
 			return nil, err
 		}
 		gh.SkipCache = opts.SkipCache
-		gh.IncludePreReleases = opts.IncludePreReleases
+		gh.IncludePreReleases = includePreReleases
 		gh.SkipDownloadCache = opts.SkipDownloadCache
+		gh.DownloadHeaders = downloadHeaders
 		return gh, nil
 	case config.SourceGitLab:
 		gl, err := NewGitLab(cfg)
 		if err != nil {
 			return nil, err
 		}
+		gl.IncludePreReleases = includePreReleases
 		gl.SkipDownloadCache = opts.SkipDownloadCache
+		gl.DownloadHeaders = downloadHeaders
 		return gl, nil
 	case config.SourceGitea:
 		gt, err := NewGitea(cfg)
 		if err != nil {
 			return nil, err
 		}
-		gt.IncludePreReleases = opts.IncludePreReleases
+		gt.IncludePreReleases = includePreReleases
 		gt.SkipDownloadCache = opts.SkipDownloadCache
+		gt.DownloadHeaders = downloadHeaders
 		return gt, nil
 	case config.SourceFDroid:
 		fd, err := NewFDroid(cfg)
 		if err != nil {
 			return nil, err
 		}
+		fd.IncludePreReleases = includePreReleases
 		fd.SkipDownloadCache = opts.SkipDownloadCache
+		fd.DownloadHeaders = downloadHeaders
 		return fd, nil
 	case config.SourceWeb:
 		web, err := NewWeb(cfg)
@@ -364,6 +447,8 @@ func NewWithOptions(cfg *config.Config, opts Options) (Source, error) {
 		}
 		web.SkipCache = opts.SkipCache
 		web.SkipDownloadCache = opts.SkipDownloadCache
+		web.StrictRedirects = opts.StrictRedirects
+		web.DownloadHeaders = downloadHeaders
 		return web, nil
 	default:
 		return nil, fmt.Errorf("unsupported source type: %s", sourceType)
@@ -373,6 +458,12 @@ func NewWithOptions(cfg *config.Config, opts Options) (Source, error) {
 // DownloadProgress is called during downloads to report progress.
 type DownloadProgress func(downloaded, total int64)
 
+// ReleaseLister is an optional interface for forge sources that can list their
+// recent releases, newest first. Used by --pick-release.
+type ReleaseLister interface {
+	ListReleases(ctx context.Context, n int) ([]*Release, error)
+}
+
 // CacheClearer is an optional interface for sources that support cache clearing.
 // Sources that cache release data (like GitHub with ETags) should implement this
 // to allow clearing the cache when publishing fails.
@@ -388,6 +479,25 @@ type CachedReleaseProvider interface {
 	GetCachedRelease() *Release
 }
 
+// RedirectHostReporter is an optional interface for sources that pin the host
+// their asset URL redirects to. RedirectHostChange returns the change found by
+// the last fetch, or nil when the host is unchanged or not yet pinned.
+type RedirectHostReporter interface {
+	RedirectHostChange() *RedirectHostChangeError
+}
+
+// RedirectHostChangeError reports an asset URL whose redirect chain now ends at a
+// different host than at the last successful publish.
+type RedirectHostChangeError struct {
+	AssetURL string
+	Previous string // Host pinned at the last publish
+	Current  string // Host the chain ends at now
+}
+
+func (e *RedirectHostChangeError) Error() string {
+	return fmt.Sprintf("%s now redirects to %s instead of %s", e.AssetURL, e.Current, e.Previous)
+}
+
 // CacheSkipper is an optional interface for sources that support bypassing their
 // ETag/version cache. Used as a fallback when ErrNotModified is returned but no
 // cached release is available — the workflow retries with the cache skipped.
@@ -432,6 +542,12 @@ func (pr *ProgressReader) Read(p []byte) (int, error) {
 // Uses stall-based timeout: fails only if no data received for 30s, not after a fixed total time.
 // Transient failures (unexpected EOF, connection reset) are retried up to downloadMaxAttempts.
 func DownloadHTTP(ctx context.Context, client *http.Client, url, destPath string, expectedSize int64, progress DownloadProgress) error {
+	return DownloadHTTPWithHeaders(ctx, client, url, destPath, expectedSize, nil, progress)
+}
+
+// DownloadHTTPWithHeaders is DownloadHTTP with extra request headers (e.g. Referer
+// for hotlink-protected hosts).
+func DownloadHTTPWithHeaders(ctx context.Context, client *http.Client, url, destPath string, expectedSize int64, headers map[string]string, progress DownloadProgress) error {
 	_ = client // kept for API compatibility with callers that pass a configured client
 
 	var lastErr error
@@ -448,7 +564,7 @@ func DownloadHTTP(ctx context.Context, client *http.Client, url, destPath string
 			}
 		}
 
-		err := downloadHTTPOnce(ctx, url, destPath, expectedSize, progress)
+		err := downloadHTTPOnce(ctx, url, destPath, expectedSize, headers, progress)
 		if err == nil {
 			return nil
 		}
@@ -462,13 +578,14 @@ func DownloadHTTP(ctx context.Context, client *http.Client, url, destPath string
 }
 
 // downloadHTTPOnce performs a single download attempt.
-func downloadHTTPOnce(ctx context.Context, url, destPath string, expectedSize int64, progress DownloadProgress) error {
+func downloadHTTPOnce(ctx context.Context, url, destPath string, expectedSize int64, headers map[string]string, progress DownloadProgress) error {
 	dlClient := newDownloadHTTPClient()
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
 	}
+	setDownloadHeaders(req, headers)
 
 	resp, err := DoWithTorFallback(ctx, dlClient, req)
 	if err != nil {
@@ -500,6 +617,7 @@ func downloadHTTPOnce(ctx context.Context, url, destPath string, expectedSize in
 		Reader:  resp.Body,
 		Timeout: downloadStallTimeout,
 	}
+	reader = ratelimit.Reader(ctx, ratelimit.Download, reader) // --limit-rate, --max-download-rate
 
 	if progress != nil {
 		reader = &ProgressReader{
@@ -565,6 +683,13 @@ func isTransientDownloadError(err error) bool {
 	return false
 }
 
+// setDownloadHeaders applies configured release_source download headers to an APK request.
+func setDownloadHeaders(req *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+}
+
 // FilterUnsupportedArchitectures removes APK assets that explicitly indicate
 // unsupported architectures (x86, x86_64, etc.) in their filename.
 // Assets without architecture indicators or with supported architectures (arm64-v8a, armeabi-v7a) are kept.
@@ -663,7 +788,9 @@ func DeleteCachedDownload(downloadURL, filename string) error {
 }
 
 // SaveToDownloadCache saves a downloaded file to the cache.
-// Returns the cached path on success.
+// Returns the cached path on success. The copy is written in the run's temp
+// directory and renamed into place, so an interrupted run never leaves a
+// partial file that GetCachedDownload would take for a cached APK.
 func SaveToDownloadCache(downloadURL, filename, srcPath string) (string, error) {
 	cacheDir := DownloadCacheDir()
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
@@ -680,14 +807,20 @@ func SaveToDownloadCache(downloadURL, filename, srcPath string) (string, error)
 	}
 	defer src.Close()
 
-	dst, err := os.Create(cachedPath)
+	part, err := os.CreateTemp(tempdir.Dir(), "*.part")
 	if err != nil {
 		return "", err
 	}
-	defer dst.Close()
+	defer os.Remove(part.Name())
 
-	if _, err := io.Copy(dst, src); err != nil {
-		os.Remove(cachedPath)
+	if _, err := io.Copy(part, src); err != nil {
+		part.Close()
+		return "", err
+	}
+	if err := part.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(part.Name(), cachedPath); err != nil {
 		return "", err
 	}
 
@@ -13,8 +13,12 @@ import (
 	"strings"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/zapstore/zsp/internal/cli"
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/netpolicy"
+	"github.com/zapstore/zsp/internal/tempdir"
 )
 
 func TestDoWithTorFallback(t *testing.T) {
@@ -324,6 +328,43 @@ func TestNewWithOptions(t *testing.T) {
 	}
 }
 
+func TestNewWithOptionsIncludePreReleases(t *testing.T) {
+	repos := []string{
+		"https://github.com/acme/app",
+		"https://gitlab.com/acme/app",
+		"https://codeberg.org/acme/app",
+		"https://f-droid.org/packages/com.example.app",
+	}
+	included := func(src Source) bool {
+		switch s := src.(type) {
+		case *GitHub:
+			return s.IncludePreReleases
+		case *GitLab:
+			return s.IncludePreReleases
+		case *Gitea:
+			return s.IncludePreReleases
+		case *FDroid:
+			return s.IncludePreReleases
+		}
+		t.Fatalf("unexpected source %T", src)
+		return false
+	}
+
+	for _, repo := range repos {
+		for _, tc := range []struct {
+			fromConfig, fromFlag bool
+		}{{false, false}, {true, false}, {false, true}} {
+			src, err := NewWithOptions(&config.Config{Repository: repo, IncludePreReleases: tc.fromConfig}, Options{IncludePreReleases: tc.fromFlag})
+			if err != nil {
+				t.Fatalf("%s: %v", repo, err)
+			}
+			if got, want := included(src), tc.fromConfig || tc.fromFlag; got != want {
+				t.Errorf("%s (config %v, flag %v): IncludePreReleases = %v, want %v", repo, tc.fromConfig, tc.fromFlag, got, want)
+			}
+		}
+	}
+}
+
 // TestProgressReader tests the progress reader wrapper
 func TestProgressReader(t *testing.T) {
 	data := []byte("hello world")
@@ -409,6 +450,23 @@ func TestReleaseFields(t *testing.T) {
 	}
 }
 
+func TestReleaseLastChangedAt(t *testing.T) {
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	reuploaded := published.Add(20 * time.Minute)
+
+	release := &Release{CreatedAt: published, Assets: []*Asset{{Name: "a.apk"}, {Name: "b.apk", UpdatedAt: published.Add(-time.Hour)}}}
+	if got := release.LastChangedAt(); !got.Equal(published) {
+		t.Errorf("LastChangedAt() = %v, want the publish date %v", got, published)
+	}
+	release.Assets = append(release.Assets, &Asset{Name: "c.apk", UpdatedAt: reuploaded})
+	if got := release.LastChangedAt(); !got.Equal(reuploaded) {
+		t.Errorf("LastChangedAt() = %v, want the re-upload %v", got, reuploaded)
+	}
+	if got := (&Release{Assets: []*Asset{{Name: "a.apk"}}}).LastChangedAt(); !got.IsZero() {
+		t.Errorf("LastChangedAt() without dates = %v, want zero", got)
+	}
+}
+
 // bytesReaderImpl implements io.Reader for testing
 type bytesReaderImpl struct {
 	data []byte
@@ -568,3 +626,117 @@ func TestDownloadHTTPRetriesTransientEOF(t *testing.T) {
 		t.Fatalf("downloaded %q, want %q", got, payload)
 	}
 }
+
+func TestDownloadHTTPWithHeadersSendsReferer(t *testing.T) {
+	var gotReferer string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotReferer = r.Header.Get("Referer")
+		if gotReferer == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("apk"))
+	}))
+	t.Cleanup(srv.Close)
+
+	dest := filepath.Join(t.TempDir(), "app.apk")
+	headers := map[string]string{"Referer": "https://example.com/download"}
+	if err := DownloadHTTPWithHeaders(context.Background(), nil, srv.URL, dest, 0, headers, nil); err != nil {
+		t.Fatalf("DownloadHTTPWithHeaders() error = %v", err)
+	}
+	if gotReferer != "https://example.com/download" {
+		t.Errorf("Referer = %q", gotReferer)
+	}
+}
+
+type staticRoundTripper struct{}
+
+func (staticRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+}
+
+func TestNetworkAllowlistBlocksUnlistedHosts(t *testing.T) {
+	netpolicy.SetAllowlist([]string{"github.com", "*.githubusercontent.com"})
+	t.Cleanup(func() { netpolicy.SetAllowlist(nil) })
+
+	client := &http.Client{Transport: withTorFallback(staticRoundTripper{})}
+
+	// GitHub release download is allowed
+	req, _ := http.NewRequest("GET", "https://github.com/user/app/releases/download/v1.0.0/app.apk", nil)
+	resp, err := DoWithTorFallback(context.Background(), client, req)
+	if err != nil {
+		t.Fatalf("GitHub download blocked: %v", err)
+	}
+	resp.Body.Close()
+
+	// Play Store screenshot fetch is blocked
+	req, _ = http.NewRequest("GET", "https://play-lh.googleusercontent.com/screenshot.png", nil)
+	_, err = DoWithTorFallback(context.Background(), client, req)
+	var blocked *netpolicy.BlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("expected BlockedError for Play Store screenshot, got %v", err)
+	}
+	if blocked.Host != "play-lh.googleusercontent.com" {
+		t.Errorf("blocked host = %q", blocked.Host)
+	}
+}
+
+func TestDownloadInterruptLeavesNoStrayFiles(t *testing.T) {
+	cacheHome, tmp := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	t.Setenv("TMPDIR", tmp)
+	t.Cleanup(tempdir.Cleanup)
+
+	started := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1048576")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(make([]byte, 4096))
+		w.(http.Flusher).Flush()
+		close(started)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(srv.Close)
+
+	sigHandler := cli.NewSignalHandler()
+	defer sigHandler.Stop()
+	sigHandler.OnCleanup(tempdir.Cleanup)
+
+	g := &GitHub{cfg: &config.Config{}, client: http.DefaultClient}
+	asset := &Asset{Name: "app.apk", URL: srv.URL + "/app.apk"}
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.Download(sigHandler.Context(), asset, "", nil)
+		done <- err
+	}()
+
+	<-started
+	self, _ := os.FindProcess(os.Getpid())
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send SIGINT: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Download() succeeded, want it interrupted")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Download() did not stop after SIGINT")
+	}
+
+	var stray []string
+	for _, dir := range []string{tmp, cacheHome} {
+		_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				stray = append(stray, path)
+			}
+			return nil
+		})
+	}
+	if len(stray) > 0 {
+		t.Errorf("files left after interrupted download: %v", stray)
+	}
+	if entries, _ := os.ReadDir(tempdir.Root()); len(entries) > 0 {
+		t.Errorf("run directory %s left after interrupt", entries[0].Name())
+	}
+}
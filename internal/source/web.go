@@ -1,11 +1,13 @@
 package source
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,6 +21,8 @@ import (
 	"github.com/PaesslerAG/jsonpath"
 	"github.com/PuerkitoBio/goquery"
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/netpolicy"
+	"github.com/zapstore/zsp/internal/tempdir"
 )
 
 // Web implements Source for web scraping with version extraction.
@@ -26,8 +30,13 @@ type Web struct {
 	cfg               *config.Config
 	client            *http.Client
 	cacheDir          string
-	SkipCache         bool // Set to true to bypass version/HTTP cache
-	SkipDownloadCache bool // Set to true to skip saving APKs to download cache
+	SkipCache         bool              // Set to true to bypass version/HTTP cache
+	SkipDownloadCache bool              // Set to true to skip saving APKs to download cache
+	DownloadHeaders   map[string]string // Extra headers for APK downloads (release_source referer/headers)
+	StrictRedirects   bool              // Fail instead of warn when the asset URL redirects to a new host
+
+	// hostChange is the redirect host change found by the last fetch (RedirectHostChange).
+	hostChange *RedirectHostChangeError
 
 	// pendingCache holds the cache from the last fetch, not yet committed to disk.
 	// Call CommitCache() after successful publishing to persist it.
@@ -46,6 +55,10 @@ type webCache struct {
 	ContentLength int64  `json:"content_length,omitempty"` // Fallback when ETag/Last-Modified unavailable
 
 	LatestPublishedReleaseVersion string `json:"latest_published_release_version,omitempty"`
+
+	// FinalHost is the host the asset URL's redirect chain ended at, pinned so a
+	// later change of redirect target is noticed.
+	FinalHost string `json:"final_host,omitempty"`
 }
 
 // NewWeb creates a new web scraping source.
@@ -75,16 +88,16 @@ func (w *Web) resolveRedirects(ctx context.Context, url string) (string, error)
 	var finalURL string
 	client := &http.Client{
 		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
+		Transport: netpolicy.WrapTransport(&http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
 			},
-		},
+		}),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			finalURL = req.URL.String()
-			if len(via) >= 10 {
-				return fmt.Errorf("too many redirects")
+			if err := checkRedirect(req, via); err != nil {
+				return err
 			}
+			finalURL = req.URL.String()
 			return nil
 		},
 	}
@@ -108,6 +121,63 @@ func (w *Web) resolveRedirects(ctx context.Context, url string) (string, error)
 	return finalURL, nil
 }
 
+// resolveRedirectHost returns the host the asset URL's redirect chain ends at.
+// A HEAD request the server rejects is not an error, since the download itself
+// still applies the redirect policy; the result is then "".
+func (w *Web) resolveRedirectHost(ctx context.Context, assetURL string) (string, error) {
+	finalURL, err := w.resolveRedirects(ctx, assetURL)
+	if err != nil {
+		if errors.Is(err, ErrRedirectDowngrade) || errors.Is(err, ErrTooManyRedirects) {
+			return "", fmt.Errorf("failed to resolve asset URL: %w", err)
+		}
+		return "", nil
+	}
+	return urlHost(finalURL), nil
+}
+
+// checkRedirectHost compares host with the host pinned at the last publish and
+// returns the host to pin after this one. A change fails with StrictRedirects and
+// is otherwise reported by RedirectHostChange. An empty host keeps the old pin.
+func (w *Web) checkRedirectHost(assetURL, host string) (string, error) {
+	w.hostChange = nil
+	var pinned string
+	if cache := w.loadCache(); cache != nil {
+		pinned = cache.FinalHost
+	}
+	if host == "" || pinned == "" || strings.EqualFold(host, pinned) {
+		return cmp.Or(host, pinned), nil
+	}
+
+	change := &RedirectHostChangeError{AssetURL: redactURL(assetURL), Previous: pinned, Current: host}
+	if w.StrictRedirects {
+		return "", fmt.Errorf("%w; if the new host is expected, publish once without --strict-redirects to pin it", change)
+	}
+	w.hostChange = change
+	return host, nil
+}
+
+// RedirectHostChange implements RedirectHostReporter.
+func (w *Web) RedirectHostChange() *RedirectHostChangeError { return w.hostChange }
+
+// urlHost returns the lowercased host name of rawURL, or "" if it does not parse.
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// redactURL drops the query and fragment of rawURL, which may hold download tokens.
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	parsed.RawQuery, parsed.Fragment, parsed.User = "", "", nil
+	return parsed.String()
+}
+
 // Type returns the source type.
 func (w *Web) Type() config.SourceType {
 	return config.SourceWeb
@@ -230,6 +300,7 @@ func (w *Web) FetchLatestRelease(ctx context.Context) (*Release, error) {
 	var version string
 	var assetURL string
 	var nameURL string // optional override for filename (e.g. resolved redirect target)
+	var finalHost string
 	var newCache *webCache
 
 	if repo.HasAssetExtractor() {
@@ -258,6 +329,12 @@ func (w *Web) FetchLatestRelease(ctx context.Context) (*Release, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract asset URL: %w", err)
 		}
+		if finalHost, err = w.resolveRedirectHost(ctx, assetURL); err != nil {
+			return nil, err
+		}
+		if finalHost, err = w.checkRedirectHost(assetURL, finalHost); err != nil {
+			return nil, err
+		}
 
 		newCache = &webCache{
 			Version:  version, // may be "" if no version extractor
@@ -282,6 +359,13 @@ func (w *Web) FetchLatestRelease(ctx context.Context) (*Release, error) {
 			}
 		}
 
+		if finalHost, err = w.resolveRedirectHost(ctx, assetURL); err != nil {
+			return nil, err
+		}
+		if finalHost, err = w.checkRedirectHost(assetURL, finalHost); err != nil {
+			return nil, err
+		}
+
 		newCache = &webCache{
 			Version:  version,
 			AssetURL: assetURL,
@@ -297,6 +381,9 @@ func (w *Web) FetchLatestRelease(ctx context.Context) (*Release, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to resolve URL: %w", err)
 		}
+		if finalHost, err = w.checkRedirectHost(assetURL, urlHost(finalURL)); err != nil {
+			return nil, err
+		}
 
 		// Check for changes using HTTP caching headers (on the final URL)
 		cache := w.loadCache()
@@ -339,6 +426,7 @@ func (w *Web) FetchLatestRelease(ctx context.Context) (*Release, error) {
 	// Store cache for later commit
 	if newCache != nil {
 		newCache.LatestPublishedReleaseVersion = version
+		newCache.FinalHost = finalHost
 	}
 	w.pendingCache = newCache
 
@@ -521,11 +609,11 @@ func (w *Web) extractVersionHeader(ctx context.Context, v *config.VersionExtract
 	// Don't follow redirects - we want to capture the redirect header
 	client := &http.Client{
 		Timeout: 30 * time.Second,
-		Transport: &http.Transport{
+		Transport: netpolicy.WrapTransport(&http.Transport{
 			TLSClientConfig: &tls.Config{
 				MinVersion: tls.VersionTLS12,
 			},
-		},
+		}),
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse // Stop at first redirect
 		},
@@ -652,7 +740,7 @@ func (w *Web) Download(ctx context.Context, asset *Asset, destDir string, progre
 
 	// Create destination directory if needed
 	if destDir == "" {
-		destDir = os.TempDir()
+		destDir = tempdir.Dir()
 	}
 	if err := os.MkdirAll(destDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create destination directory: %w", err)
@@ -672,7 +760,7 @@ func (w *Web) Download(ctx context.Context, asset *Asset, destDir string, progre
 		return "", fmt.Errorf("invalid destination path: path traversal detected")
 	}
 
-	if err := DownloadHTTP(ctx, w.client, asset.URL, destPath, asset.Size, progress); err != nil {
+	if err := DownloadHTTPWithHeaders(ctx, w.client, asset.URL, destPath, asset.Size, w.DownloadHeaders, progress); err != nil {
 		return "", err
 	}
 
@@ -2,6 +2,7 @@ package source
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -123,3 +124,134 @@ func TestWebDirectURLKeepsOriginalDownloadURL(t *testing.T) {
 		t.Errorf("download URL should not be the tokenized CDN URL: %s", asset.URL)
 	}
 }
+
+// redirectServer serves an APK at /app.apk and redirects /dl to target+"/app.apk".
+func redirectServer(t *testing.T, target *string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dl", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, *target+"/app.apk", http.StatusFound)
+	})
+	mux.HandleFunc("/app.apk", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc123"`)
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestWebPinsRedirectHost(t *testing.T) {
+	// Two hosts for the same server: 127.0.0.1 and localhost
+	var target string
+	srv := redirectServer(t, &target)
+	target = srv.URL
+	otherHost := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+
+	w := &Web{
+		cfg: &config.Config{
+			ReleaseSource: &config.ReleaseSource{IsWebSource: true, AssetURL: srv.URL + "/dl"},
+		},
+		client:    newSecureHTTPClient(5 * time.Second),
+		cacheDir:  t.TempDir(),
+		SkipCache: true,
+	}
+
+	// First publish pins the host
+	if _, err := w.FetchLatestRelease(context.Background()); err != nil {
+		t.Fatalf("FetchLatestRelease() error = %v", err)
+	}
+	if w.RedirectHostChange() != nil {
+		t.Errorf("RedirectHostChange() = %v on first fetch, want nil", w.RedirectHostChange())
+	}
+	if err := w.CommitCache(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.loadCache().FinalHost; got != "127.0.0.1" {
+		t.Fatalf("pinned host = %q, want 127.0.0.1", got)
+	}
+
+	// Same target: no change
+	if _, err := w.FetchLatestRelease(context.Background()); err != nil {
+		t.Fatalf("FetchLatestRelease() error = %v", err)
+	}
+	if w.RedirectHostChange() != nil {
+		t.Errorf("RedirectHostChange() = %v for an unchanged target, want nil", w.RedirectHostChange())
+	}
+
+	// Changed target: strict mode fails and names both hosts
+	target = otherHost
+	w.StrictRedirects = true
+	_, err := w.FetchLatestRelease(context.Background())
+	var change *RedirectHostChangeError
+	if !errors.As(err, &change) || change.Previous != "127.0.0.1" || change.Current != "localhost" {
+		t.Fatalf("FetchLatestRelease() with --strict-redirects = %v, want a 127.0.0.1 -> localhost host change", err)
+	}
+
+	// Without strict mode the change is reported and pinned after the publish
+	w.StrictRedirects = false
+	if _, err := w.FetchLatestRelease(context.Background()); err != nil {
+		t.Fatalf("FetchLatestRelease() error = %v", err)
+	}
+	if change := w.RedirectHostChange(); change == nil || change.Current != "localhost" {
+		t.Fatalf("RedirectHostChange() = %v, want change to localhost", change)
+	}
+	if err := w.CommitCache(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.loadCache().FinalHost; got != "localhost" {
+		t.Errorf("pinned host after publish = %q, want localhost", got)
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	req := func(rawURL string) *http.Request {
+		r, err := http.NewRequest(http.MethodGet, rawURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+
+	// http to https upgrades and same-scheme hops are fine
+	if err := checkRedirect(req("https://cdn.example.com/a.apk"), []*http.Request{req("http://example.com/dl")}); err != nil {
+		t.Errorf("checkRedirect() http -> https = %v, want nil", err)
+	}
+
+	// A downgrade anywhere in the chain is refused
+	via := []*http.Request{req("https://example.com/dl"), req("https://mirror.example.com/dl")}
+	if err := checkRedirect(req("http://cdn.example.com/a.apk"), via); !errors.Is(err, ErrRedirectDowngrade) {
+		t.Errorf("checkRedirect() https -> http = %v, want ErrRedirectDowngrade", err)
+	}
+
+	// Long chains are capped
+	long := make([]*http.Request, maxRedirects)
+	for i := range long {
+		long[i] = req("https://example.com/dl")
+	}
+	if err := checkRedirect(req("https://example.com/a.apk"), long); !errors.Is(err, ErrTooManyRedirects) {
+		t.Errorf("checkRedirect() after %d hops = %v, want ErrTooManyRedirects", maxRedirects, err)
+	}
+}
+
+func TestWebRefusesRedirectDowngrade(t *testing.T) {
+	// An https entry point redirecting to plain http must not be followed
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(plain.Close)
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plain.URL+"/app.apk", http.StatusFound)
+	}))
+	t.Cleanup(secure.Close)
+
+	client := secure.Client()
+	client.CheckRedirect = checkRedirect
+	resp, err := client.Get(secure.URL + "/dl")
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, ErrRedirectDowngrade) {
+		t.Errorf("GET https -> http = %v, want ErrRedirectDowngrade", err)
+	}
+}
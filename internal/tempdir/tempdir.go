@@ -0,0 +1,102 @@
+// Package tempdir keeps a run's temporary files in one directory under the zsp
+// cache dir, so they are removed together on exit or interrupt, and directories
+// left behind by killed runs are swept on a later start.
+package tempdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxAge is how old a run directory must be before Sweep removes it.
+const MaxAge = 24 * time.Hour
+
+var (
+	mu   sync.Mutex
+	dir  string // this run's directory, once created
+	kept bool   // leave dir in place at Cleanup
+)
+
+// Root returns the directory holding the run directories.
+func Root() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "zsp", "tmp")
+}
+
+// Dir returns this run's directory, creating it on first use or when it has
+// been removed since. When it cannot be created, os.TempDir() is returned so
+// callers still have somewhere to write.
+func Dir() string {
+	mu.Lock()
+	defer mu.Unlock()
+	if dir != "" {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+	}
+	runDir := filepath.Join(Root(), fmt.Sprintf("%s-%d", time.Now().UTC().Format("20060102T150405"), os.Getpid()))
+	if err := os.MkdirAll(runDir, 0700); err != nil {
+		return os.TempDir()
+	}
+	dir = runDir
+	return dir
+}
+
+// Keep leaves the run directory in place at Cleanup, for files that outlive the
+// run (such as those an upload manifest points to). Sweep removes it once it
+// is MaxAge old.
+func Keep() {
+	mu.Lock()
+	defer mu.Unlock()
+	kept = true
+}
+
+// Cleanup removes the run directory and everything in it, unless Keep was
+// called. It is safe to call more than once; a later Dir starts a new directory.
+func Cleanup() {
+	mu.Lock()
+	defer mu.Unlock()
+	if dir != "" && !kept {
+		os.RemoveAll(dir)
+	}
+	dir, kept = "", false
+}
+
+// Sweep removes run directories last modified more than maxAge ago, other than
+// this run's, and returns how many it removed.
+func Sweep(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(Root())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	mu.Lock()
+	current := dir
+	mu.Unlock()
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, entry := range entries {
+		path := filepath.Join(Root(), entry.Name())
+		if path == current {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(path); err == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,80 @@
+package tempdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCleanup(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Cleanup(Cleanup)
+
+	run := Dir()
+	if filepath.Dir(run) != Root() {
+		t.Fatalf("Dir() = %s, want a directory in %s", run, Root())
+	}
+	if Dir() != run {
+		t.Error("Dir() changed within a run")
+	}
+	if err := os.WriteFile(filepath.Join(run, "icon.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	Cleanup()
+	if _, err := os.Stat(run); !os.IsNotExist(err) {
+		t.Errorf("run directory still exists after Cleanup: %v", err)
+	}
+
+	Keep()
+	kept := Dir()
+	Cleanup()
+	if _, err := os.Stat(kept); err != nil {
+		t.Errorf("kept run directory removed: %v", err)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Cleanup(Cleanup)
+
+	aged := filepath.Join(Root(), "20260101T000000-42")
+	fresh := filepath.Join(Root(), "20260101T000000-43")
+	for _, dir := range []string{aged, fresh} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "app.apk"), []byte("apk"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-MaxAge - time.Hour)
+	if err := os.Chtimes(aged, old, old); err != nil {
+		t.Fatal(err)
+	}
+	run := Dir()
+	if err := os.Chtimes(run, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := Sweep(MaxAge)
+	if err != nil || removed != 1 {
+		t.Errorf("Sweep() = %d, %v; want 1 removed", removed, err)
+	}
+	if _, err := os.Stat(aged); !os.IsNotExist(err) {
+		t.Error("aged run directory not removed")
+	}
+	for _, dir := range []string{fresh, run} {
+		if _, err := os.Stat(dir); err != nil {
+			t.Errorf("%s removed, want it kept: %v", filepath.Base(dir), err)
+		}
+	}
+}
+
+func TestSweepMissingRoot(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if removed, err := Sweep(MaxAge); err != nil || removed != 0 {
+		t.Errorf("Sweep() = %d, %v; want nothing to do", removed, err)
+	}
+}
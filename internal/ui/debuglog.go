@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DebugLogSize is how many recent output lines are kept for bug reports.
+const DebugLogSize = 200
+
+// The last DebugLogSize lines printed through this package, and the step being
+// run, are kept in memory so a bug report can include them. Nothing is written to disk.
+var (
+	debugMu    sync.Mutex
+	debugLines []string
+	debugNext  int
+	stage      string
+)
+
+// LogDebug records a line in the in-memory debug log without printing it.
+func LogDebug(format string, args ...any) {
+	line := strings.TrimRight(fmt.Sprintf(format, args...), "\n")
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	if len(debugLines) < DebugLogSize {
+		debugLines = append(debugLines, line)
+		return
+	}
+	debugLines[debugNext] = line
+	debugNext = (debugNext + 1) % DebugLogSize
+}
+
+// DebugLog returns the recorded lines, oldest first.
+func DebugLog() []string {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	lines := append([]string(nil), debugLines[debugNext:]...)
+	return append(lines, debugLines[:debugNext]...)
+}
+
+// CurrentStage returns the name of the step last started with StartStep.
+func CurrentStage() string {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	return stage
+}
+
+// setStage records the step being run and logs it.
+func setStage(name string) {
+	debugMu.Lock()
+	stage = name
+	debugMu.Unlock()
+	LogDebug("== %s", name)
+}
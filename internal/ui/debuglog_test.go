@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDebugLogKeepsLastLines(t *testing.T) {
+	for i := 0; i < DebugLogSize+5; i++ {
+		LogDebug("line %d\n", i)
+	}
+	lines := DebugLog()
+	if len(lines) != DebugLogSize {
+		t.Fatalf("len = %d, want %d", len(lines), DebugLogSize)
+	}
+	if lines[0] != "line 5" || lines[len(lines)-1] != fmt.Sprintf("line %d", DebugLogSize+4) {
+		t.Errorf("first = %q, last = %q", lines[0], lines[len(lines)-1])
+	}
+
+	NewStepTracker(1).StartStep("Upload")
+	if CurrentStage() != "Upload" || DebugLog()[DebugLogSize-1] != "== Upload" {
+		t.Errorf("stage = %q, last line = %q", CurrentStage(), DebugLog()[DebugLogSize-1])
+	}
+}
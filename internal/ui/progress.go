@@ -0,0 +1,426 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressSchemaVersion is the version of the --progress-json event schema,
+// sent as "v" in every event. It is increased when a field changes meaning or
+// is removed; new event types and fields may be added without a bump.
+const ProgressSchemaVersion = 1
+
+// ProgressInterval is the minimum time between two byte progress events of one transfer.
+const ProgressInterval = 250 * time.Millisecond
+
+// Progress event types.
+const (
+	EventStart    = "start"    // first event: zsp version
+	EventStage    = "stage"    // a workflow stage began
+	EventProgress = "progress" // bytes transferred by a download or upload
+	EventPrompt   = "prompt"   // input is required; answered from --answers-file or stdin
+	EventWarning  = "warning"  // a warning, as listed in the completion summary
+	EventLog      = "log"      // a line zsp wrote to stderr
+	EventResult   = "result"   // last event: how the run ended
+)
+
+// Workflow stages reported by stage events.
+const (
+	StageFetch    = "fetch"
+	StageMetadata = "metadata"
+	StageSign     = "sign"
+	StageUpload   = "upload"
+	StagePublish  = "publish"
+)
+
+// ProgressEvent is one line of the --progress-json stream.
+type ProgressEvent struct {
+	V          int             `json:"v"`
+	Type       string          `json:"type"`
+	Time       time.Time       `json:"time"`
+	Stage      string          `json:"stage,omitempty"`   // stage: the stage that began
+	Message    string          `json:"message,omitempty"` // stage, warning and log text
+	ZSPVersion string          `json:"zsp_version,omitempty"`
+	Progress   *ByteProgress   `json:"progress,omitempty"`
+	Prompt     *ProgressPrompt `json:"prompt,omitempty"`
+	Result     *ProgressResult `json:"result,omitempty"`
+}
+
+// ByteProgress reports a transfer. Total is 0 when the size is unknown.
+type ByteProgress struct {
+	Operation string `json:"operation"` // download or upload
+	Name      string `json:"name"`
+	Bytes     int64  `json:"bytes"`
+	Total     int64  `json:"total"`
+}
+
+// ProgressPrompt describes a question zsp needs answered. When Answered is
+// false, zsp waits for a {"id": ..., "answer": ...} line on stdin.
+type ProgressPrompt struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"` // text, secret, confirm, select or multiselect
+	Message  string   `json:"message"`
+	Options  []string `json:"options,omitempty"` // select and multiselect choices; answer with indices
+	Default  string   `json:"default,omitempty"` // used for an empty answer
+	Answered bool     `json:"answered"`          // answered from --answers-file
+}
+
+// ProgressResult is how the run ended.
+type ProgressResult struct {
+	Status   string `json:"status"` // success, failed or interrupted
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// progressStream is the state of --progress-json. Nil when it is off.
+type progressStream struct {
+	mu      sync.Mutex
+	out     io.Writer
+	answers map[string]string
+	input   *bufio.Reader
+
+	stderr    *os.File // the real stderr, restored by FinishProgress
+	stderrW   *os.File
+	stderrEOF chan struct{}
+	lastError string
+}
+
+var (
+	progressMu     sync.Mutex
+	activeProgress *progressStream
+	lastSection    string // last PrintSectionHeader name, identifies prompts without a message
+)
+
+// StartProgress turns on --progress-json: events are written to out as JSON
+// lines, and prompts are answered from answers, then from lines read from
+// input. Anything else written to stderr is sent as log events so that the
+// stream stays valid when out is stderr. FinishProgress ends the stream.
+func StartProgress(out io.Writer, answers map[string]string, input io.Reader) error {
+	s := &progressStream{out: out, answers: answers, input: bufio.NewReader(input), stderr: os.Stderr}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture stderr: %w", err)
+	}
+	if out == os.Stderr {
+		s.out = s.stderr
+	}
+	s.stderrW = w
+	s.stderrEOF = make(chan struct{})
+	os.Stderr = w
+	go s.forwardStderr(r)
+
+	progressMu.Lock()
+	activeProgress = s
+	progressMu.Unlock()
+
+	s.emit(ProgressEvent{Type: EventStart, ZSPVersion: Version})
+	return nil
+}
+
+// FinishProgress restores stderr and sends the result event for exitCode.
+// The error is the last "Error: ..." line written to stderr.
+func FinishProgress(exitCode int) {
+	progressMu.Lock()
+	s := activeProgress
+	activeProgress = nil
+	progressMu.Unlock()
+	if s == nil {
+		return
+	}
+
+	os.Stderr = s.stderr
+	s.stderrW.Close()
+	<-s.stderrEOF
+
+	result := &ProgressResult{Status: "success", ExitCode: exitCode}
+	switch exitCode {
+	case 0:
+	case 130:
+		result.Status = "interrupted"
+	default:
+		result.Status = "failed"
+		result.Error = s.lastError
+	}
+	s.emit(ProgressEvent{Type: EventResult, Result: result})
+}
+
+// ProgressEnabled reports whether --progress-json is on.
+func ProgressEnabled() bool {
+	return currentProgress() != nil
+}
+
+func currentProgress() *progressStream {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	return activeProgress
+}
+
+// emit writes one event line.
+func (s *progressStream) emit(e ProgressEvent) {
+	e.V = ProgressSchemaVersion
+	e.Time = time.Now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
+
+// emitProgress sends e if --progress-json is on.
+func emitProgress(e ProgressEvent) {
+	if s := currentProgress(); s != nil {
+		s.emit(e)
+	}
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]|\r`)
+
+// forwardStderr sends each line written to the captured stderr as a log event.
+func (s *progressStream) forwardStderr(r *os.File) {
+	defer close(s.stderrEOF)
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(ansiPattern.ReplaceAllString(scanner.Text(), ""))
+		if line == "" {
+			continue
+		}
+		if msg, ok := strings.CutPrefix(line, "Error: "); ok {
+			s.mu.Lock()
+			s.lastError = msg
+			s.mu.Unlock()
+		}
+		s.emit(ProgressEvent{Type: EventLog, Message: line})
+	}
+}
+
+// ProgressStage reports that a workflow stage began; name is its display name.
+func ProgressStage(stage, name string) {
+	emitProgress(ProgressEvent{Type: EventStage, Stage: stage, Message: name})
+}
+
+// TrackBytes wraps a download or upload progress callback so that it also
+// sends progress events, at most one per ProgressInterval plus the final one.
+// Returns next unchanged when --progress-json is off.
+func TrackBytes(operation, name string, total int64, next func(done, total int64)) func(done, total int64) {
+	if !ProgressEnabled() {
+		return next
+	}
+	var mu sync.Mutex
+	var last time.Time
+	return func(done, size int64) {
+		if next != nil {
+			next(done, size)
+		}
+		if size <= 0 {
+			size = total
+		}
+		mu.Lock()
+		now := time.Now()
+		send := now.Sub(last) >= ProgressInterval || (size > 0 && done >= size)
+		if send {
+			last = now
+		}
+		mu.Unlock()
+		if send {
+			emitProgress(ProgressEvent{Type: EventProgress, Progress: &ByteProgress{Operation: operation, Name: name, Bytes: done, Total: size}})
+		}
+	}
+}
+
+// LoadAnswers reads an --answers-file: a JSON object from prompt ID to answer.
+// Answers may be strings, numbers, booleans or, for multiselect prompts,
+// arrays of option indices.
+func LoadAnswers(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+	}
+	answers := make(map[string]string, len(raw))
+	for id, value := range raw {
+		answers[id] = answerString(value)
+	}
+	return answers, nil
+}
+
+// answerString converts a JSON answer to the text a prompt would read.
+func answerString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = answerString(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+var promptIDPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// promptID derives a stable prompt ID from its message, e.g. "Use this
+// repository?" becomes "use-this-repository". Prompts without a message are
+// named after the section header shown before them, then after their kind.
+func promptID(kind, message string) string {
+	progressMu.Lock()
+	section := lastSection
+	progressMu.Unlock()
+	for _, s := range []string{message, section, kind} {
+		id := strings.Trim(promptIDPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+		if len(id) > 60 {
+			id = strings.TrimRight(id[:60], "-")
+		}
+		if id != "" {
+			return id
+		}
+	}
+	return kind
+}
+
+// askProgress sends a prompt event and returns its answer, or def for an empty one.
+func askProgress(s *progressStream, kind, message string, options []string, def string) (string, error) {
+	if IsInterrupted() {
+		return "", ErrInterrupted
+	}
+	message = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(message), ":"))
+	prompt := &ProgressPrompt{ID: promptID(kind, message), Kind: kind, Message: message, Options: options, Default: def}
+
+	answer, ok := s.answers[prompt.ID]
+	prompt.Answered = ok
+	s.emit(ProgressEvent{Type: EventPrompt, Prompt: prompt})
+	if !ok {
+		var err error
+		if answer, err = s.readAnswer(prompt.ID); err != nil {
+			return "", err
+		}
+	}
+	if strings.TrimSpace(answer) == "" {
+		return def, nil
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// readAnswer reads the {"id": ..., "answer": ...} line answering prompt id.
+func (s *progressStream) readAnswer(id string) (string, error) {
+	type lineResult struct {
+		line string
+		err  error
+	}
+	ch := make(chan lineResult, 1)
+	go func() {
+		line, err := s.input.ReadString('\n')
+		if err == io.EOF && strings.TrimSpace(line) != "" {
+			err = nil
+		}
+		ch <- lineResult{line, err}
+	}()
+
+	var result lineResult
+	select {
+	case <-GetContext().Done():
+		return "", ErrInterrupted
+	case result = <-ch:
+	}
+	if result.err != nil {
+		return "", fmt.Errorf("no answer for prompt %q: add it to --answers-file or send it on stdin", id)
+	}
+
+	var msg struct {
+		ID     string `json:"id"`
+		Answer any    `json:"answer"`
+	}
+	if err := json.Unmarshal([]byte(result.line), &msg); err != nil {
+		return "", fmt.Errorf("invalid answer for prompt %q: %w", id, err)
+	}
+	if msg.ID != id {
+		return "", fmt.Errorf("answer is for prompt %q, expected %q", msg.ID, id)
+	}
+	return answerString(msg.Answer), nil
+}
+
+// progressSelect answers a select prompt with an option index or option text.
+func progressSelect(s *progressStream, title string, options []string, recommended int) (int, error) {
+	def := ""
+	if recommended >= 0 && recommended < len(options) {
+		def = strconv.Itoa(recommended)
+	}
+	answer, err := askProgress(s, "select", title, options, def)
+	if err != nil {
+		return -1, err
+	}
+	return optionIndex(answer, options)
+}
+
+// progressSelectMultiple answers a multiselect prompt with comma-separated option indices.
+func progressSelectMultiple(s *progressStream, title string, options []string, preselected []int) ([]int, error) {
+	defaults := make([]string, len(preselected))
+	for i, idx := range preselected {
+		defaults[i] = strconv.Itoa(idx)
+	}
+	answer, err := askProgress(s, "multiselect", title, options, strings.Join(defaults, ","))
+	if err != nil {
+		return nil, err
+	}
+	var selected []int
+	for _, part := range strings.Split(answer, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		idx, err := optionIndex(part, options)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, idx)
+	}
+	return selected, nil
+}
+
+// optionIndex resolves an answer given as an index or as the option text.
+func optionIndex(answer string, options []string) (int, error) {
+	if idx, err := strconv.Atoi(answer); err == nil {
+		if idx < 0 || idx >= len(options) {
+			return -1, fmt.Errorf("answer %d is out of range (0-%d)", idx, len(options)-1)
+		}
+		return idx, nil
+	}
+	for i, option := range options {
+		if option == answer {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("answer %q is not one of the options", answer)
+}
+
+// isYes reports whether a confirm answer means yes.
+func isYes(answer string) bool {
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "true", "1":
+		return true
+	}
+	return false
+}
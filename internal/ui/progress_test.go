@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readProgress decodes the events written to buf.
+func readProgress(t *testing.T, buf *bytes.Buffer) []ProgressEvent {
+	t.Helper()
+	var events []ProgressEvent
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var e ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		if e.V != ProgressSchemaVersion {
+			t.Errorf("event %q has v=%d, want %d", e.Type, e.V, ProgressSchemaVersion)
+		}
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestProgressPrompts(t *testing.T) {
+	var buf bytes.Buffer
+	answers := map[string]string{"use-this-repository": "no", "select-apk": "app-arm64.apk"}
+	input := `{"id":"enter-your-npub","answer":"npub1test"}` + "\n" + `{"id":"port","answer":""}` + "\n"
+	if err := StartProgress(&buf, answers, strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+
+	confirmed, err := Confirm("Use this repository?", true)
+	if err != nil || confirmed {
+		t.Errorf("Confirm() = %v, %v; want false from the answers file", confirmed, err)
+	}
+	PrintSectionHeader("Select APK")
+	idx, err := Select("", []string{"app-armv7.apk", "app-arm64.apk"}, 0)
+	if err != nil || idx != 1 {
+		t.Errorf("Select() = %d, %v; want 1 from the answers file", idx, err)
+	}
+	npub, err := PromptDefault("Enter your npub", "")
+	if err != nil || npub != "npub1test" {
+		t.Errorf("PromptDefault() = %q, %v; want the stdin answer", npub, err)
+	}
+	port, err := PromptInt("Port", 8080)
+	if err != nil || port != 8080 {
+		t.Errorf("PromptInt() = %d, %v; want the default for an empty answer", port, err)
+	}
+	if _, err := Prompt("Anything else: "); err == nil || !strings.Contains(err.Error(), `"anything-else"`) {
+		t.Errorf("Prompt() error = %v, want a missing answer error", err)
+	}
+	FinishProgress(0)
+
+	var prompts []ProgressPrompt
+	for _, e := range readProgress(t, &buf) {
+		if e.Type == EventPrompt {
+			prompts = append(prompts, *e.Prompt)
+		}
+	}
+	var ids []string
+	for _, p := range prompts {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"use-this-repository", "select-apk", "enter-your-npub", "port", "anything-else"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("prompt IDs = %v, want %v", ids, want)
+	}
+	if p := prompts[0]; p.Kind != "confirm" || p.Default != "yes" || !p.Answered {
+		t.Errorf("confirm prompt = %+v", p)
+	}
+	if p := prompts[1]; p.Kind != "select" || len(p.Options) != 2 || p.Default != "0" {
+		t.Errorf("select prompt = %+v", p)
+	}
+	if prompts[2].Answered {
+		t.Errorf("stdin prompt = %+v, want answered false", prompts[2])
+	}
+}
+
+func TestProgressResultAndLog(t *testing.T) {
+	var buf bytes.Buffer
+	if err := StartProgress(&buf, nil, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	ProgressStage(StageFetch, "Fetch Assets")
+	RecordWarning("no screenshots found")
+	os.Stderr.WriteString("\r\033[Knotice: something\nError: failed to fetch release\n")
+	FinishProgress(1)
+
+	var got []string
+	events := readProgress(t, &buf)
+	for _, e := range events {
+		got = append(got, e.Type+":"+e.Stage+e.Message)
+	}
+	want := []string{"start:", "stage:fetchFetch Assets", "warning:no screenshots found", "log:notice: something", "log:Error: failed to fetch release", "result:"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	result := events[len(events)-1].Result
+	if result.Status != "failed" || result.ExitCode != 1 || result.Error != "failed to fetch release" {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestTrackBytes(t *testing.T) {
+	if TrackBytes("download", "app.apk", 10, nil) != nil {
+		t.Error("TrackBytes without --progress-json should return the callback unchanged")
+	}
+
+	var buf bytes.Buffer
+	if err := StartProgress(&buf, nil, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	var forwarded int64
+	track := TrackBytes("download", "app.apk", 10, func(done, total int64) { forwarded = done })
+	for done := int64(1); done <= 10; done++ {
+		track(done, 0)
+	}
+	FinishProgress(0)
+
+	if forwarded != 10 {
+		t.Errorf("wrapped callback saw %d bytes, want 10", forwarded)
+	}
+	var progress []ByteProgress
+	for _, e := range readProgress(t, &buf) {
+		if e.Type == EventProgress {
+			progress = append(progress, *e.Progress)
+		}
+	}
+	// The first update and the completed transfer; the rest fall within ProgressInterval
+	want := []ByteProgress{
+		{Operation: "download", Name: "app.apk", Bytes: 1, Total: 10},
+		{Operation: "download", Name: "app.apk", Bytes: 10, Total: 10},
+	}
+	if !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress events = %+v, want %+v", progress, want)
+	}
+}
+
+func TestLoadAnswers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "answers.json")
+	if err := os.WriteFile(path, []byte(`{"use-this-repository": true, "select-apk": 2, "screenshots": [0, 2], "npub": "npub1x"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	answers, err := LoadAnswers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"use-this-repository": "yes", "select-apk": "2", "screenshots": "0,2", "npub": "npub1x"}
+	if !reflect.DeepEqual(answers, want) {
+		t.Errorf("LoadAnswers() = %v, want %v", answers, want)
+	}
+}
@@ -87,6 +87,10 @@ func Prompt(message string) (string, error) {
 		return "", ErrInterrupted
 	}
 
+	if s := currentProgress(); s != nil {
+		return askProgress(s, "text", message, nil, "")
+	}
+
 	fmt.Print(message)
 
 	// Read in goroutine so we can select on context cancellation
@@ -106,6 +110,10 @@ func Prompt(message string) (string, error) {
 
 // PromptDefault asks for user input with a default value.
 func PromptDefault(message, defaultValue string) (string, error) {
+	if s := currentProgress(); s != nil {
+		return askProgress(s, "text", message, nil, defaultValue)
+	}
+
 	if defaultValue != "" {
 		message = fmt.Sprintf("%s [%s]: ", message, defaultValue)
 	} else {
@@ -125,6 +133,15 @@ func PromptDefault(message, defaultValue string) (string, error) {
 
 // Confirm asks for yes/no confirmation.
 func Confirm(message string, defaultYes bool) (bool, error) {
+	if s := currentProgress(); s != nil {
+		def := "no"
+		if defaultYes {
+			def = "yes"
+		}
+		answer, err := askProgress(s, "confirm", message, nil, def)
+		return isYes(answer), err
+	}
+
 	suffix := " [y/N]: "
 	if defaultYes {
 		suffix = " [Y/n]: "
@@ -178,6 +195,10 @@ func PromptPassword(message string) (string, error) {
 		return "", ErrInterrupted
 	}
 
+	if s := currentProgress(); s != nil {
+		return askProgress(s, "secret", message, nil, "")
+	}
+
 	fmt.Print(message + ": ")
 
 	// Check if stdin is a terminal
@@ -276,6 +297,7 @@ func PrintSuccess(message string) {
 		checkmark = "[OK]"
 	}
 	fmt.Printf("%s %s\n", Success(checkmark), message)
+	LogDebug("ok: %s", message)
 }
 
 // PrintError prints an error message.
@@ -285,6 +307,7 @@ func PrintError(message string) {
 		cross = "[ERROR]"
 	}
 	fmt.Printf("%s %s\n", Error(cross), message)
+	LogDebug("error: %s", message)
 }
 
 // PrintWarning prints a warning message.
@@ -294,6 +317,7 @@ func PrintWarning(message string) {
 		warning = "[WARN]"
 	}
 	fmt.Printf("%s %s\n", Warning(warning), message)
+	RecordWarning(message)
 }
 
 // PrintInfo prints an info message.
@@ -303,6 +327,7 @@ func PrintInfo(message string) {
 		info = "[INFO]"
 	}
 	fmt.Printf("%s %s\n", Info(info), message)
+	LogDebug("info: %s", message)
 }
 
 // PrintKeyValue prints a key-value pair.
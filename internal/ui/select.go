@@ -174,6 +174,10 @@ func Select(title string, options []string, recommended int) (int, error) {
 		return -1, fmt.Errorf("no options provided")
 	}
 
+	if s := currentProgress(); s != nil {
+		return progressSelect(s, title, options, recommended)
+	}
+
 	m := newSelectModel(title, options, recommended)
 	p := tea.NewProgram(m, tea.WithContext(ctx))
 
@@ -214,6 +218,10 @@ func SelectMultiplePreselected(title string, options []string, preselected []int
 		return nil, fmt.Errorf("no options provided")
 	}
 
+	if s := currentProgress(); s != nil {
+		return progressSelectMultiple(s, title, options, preselected)
+	}
+
 	m := newMultiSelectModelWithPreselected(title, options, preselected)
 	p := tea.NewProgram(m, tea.WithContext(ctx))
 
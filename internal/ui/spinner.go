@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
+	"github.com/zapstore/zsp/internal/ratelimit"
 )
 
 // Spinner displays a spinning animation during long operations.
@@ -97,6 +98,7 @@ func (s *Spinner) Stop() {
 func (s *Spinner) StopWithMessage(message string) {
 	s.Stop()
 	fmt.Fprintf(s.writer, "%s\n", message)
+	LogDebug("%s", message)
 }
 
 // StopWithSuccess stops the spinner with a success message.
@@ -107,6 +109,7 @@ func (s *Spinner) StopWithSuccess(message string) {
 		checkmark = "[OK]"
 	}
 	fmt.Fprintf(s.writer, "%s %s\n", Success(checkmark), message)
+	LogDebug("ok: %s", message)
 }
 
 // StopWithError stops the spinner with an error message.
@@ -117,6 +120,7 @@ func (s *Spinner) StopWithError(message string) {
 		cross = "[ERROR]"
 	}
 	fmt.Fprintf(s.writer, "%s %s\n", Error(cross), message)
+	LogDebug("error: %s", message)
 }
 
 // StopWithWarning stops the spinner with a warning message.
@@ -127,6 +131,7 @@ func (s *Spinner) StopWithWarning(message string) {
 		warning = "[WARN]"
 	}
 	fmt.Fprintf(s.writer, "%s %s\n", Warning(warning), message)
+	RecordWarning(message)
 }
 
 // UpdateMessage updates the spinner message.
@@ -212,6 +217,8 @@ type DownloadTracker struct {
 	frames     []string
 	frameIndex int
 	started    bool
+	startedAt  time.Time
+	direction  ratelimit.Direction // whose --limit-rate the rate suffix shows
 	mu         sync.Mutex
 }
 
@@ -247,6 +254,14 @@ func NewDownloadTracker(message string, initialTotal int64) *DownloadTracker {
 	}
 }
 
+// NewUploadTracker creates a tracker for an upload, which shows the upload
+// rate limit rather than the download one.
+func NewUploadTracker(message string, initialTotal int64) *DownloadTracker {
+	dt := NewDownloadTracker(message, initialTotal)
+	dt.direction = ratelimit.Upload
+	return dt
+}
+
 // Callback returns a function suitable for passing to download operations.
 func (dt *DownloadTracker) Callback() func(downloaded, total int64) {
 	return func(downloaded, total int64) {
@@ -259,6 +274,9 @@ func (dt *DownloadTracker) Update(downloaded, total int64) {
 	dt.mu.Lock()
 	defer dt.mu.Unlock()
 
+	if !dt.started {
+		dt.startedAt = time.Now()
+	}
 	dt.started = true
 	dt.downloaded = downloaded
 
@@ -273,13 +291,25 @@ func (dt *DownloadTracker) Update(downloaded, total int64) {
 		currentMB := float64(downloaded) / (1024 * 1024)
 		totalMB := float64(dt.total) / (1024 * 1024)
 		barView := dt.bar.ViewAs(pct)
-		fmt.Fprintf(dt.writer, "\r\033[K%s %s %.1f%% (%.1f / %.1f MB)", dt.message, barView, pct*100, currentMB, totalMB)
+		fmt.Fprintf(dt.writer, "\r\033[K%s %s %.1f%% (%.1f / %.1f MB)%s", dt.message, barView, pct*100, currentMB, totalMB, dt.rateSuffix())
 	} else {
 		// Unknown total: show spinner with bytes downloaded
 		frame := dt.frames[dt.frameIndex]
 		dt.frameIndex = (dt.frameIndex + 1) % len(dt.frames)
-		fmt.Fprintf(dt.writer, "\r\033[K%s %s %s", frame, dt.message, formatBytes(downloaded))
+		fmt.Fprintf(dt.writer, "\r\033[K%s %s %s%s", frame, dt.message, formatBytes(downloaded), dt.rateSuffix())
+	}
+}
+
+// rateSuffix returns the measured transfer rate when a rate limit throttles
+// the transfer's direction, so the throttled speed is visible. Empty otherwise.
+func (dt *DownloadTracker) rateSuffix() string {
+	limit := ratelimit.Limit(dt.direction)
+	elapsed := time.Since(dt.startedAt).Seconds()
+	if limit == 0 || elapsed < 0.5 {
+		return ""
 	}
+	rate := int64(float64(dt.downloaded) / elapsed)
+	return fmt.Sprintf(" %s/s, limit %s/s", formatBytes(rate), formatBytes(limit))
 }
 
 // Done marks the download as complete.
@@ -322,6 +352,11 @@ func (dt *DownloadTracker) DoneWithMessage(message string) {
 	fmt.Fprintf(dt.writer, "\r\033[K%s %s\n", Success(checkmark), message)
 }
 
+// FormatBytes formats a byte count for display, e.g. "4.2 MB".
+func FormatBytes(b int64) string {
+	return formatBytes(b)
+}
+
 // formatBytes formats bytes into human-readable form.
 func formatBytes(b int64) string {
 	const unit = 1024
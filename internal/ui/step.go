@@ -27,6 +27,15 @@ func SetVersion(v string) {
 	Version = v
 }
 
+// Watermark labels every step header and the completion summary (e.g. "DEV MODE").
+// Empty by default.
+var Watermark string
+
+// SetWatermark sets the label shown on step headers and the completion summary.
+func SetWatermark(w string) {
+	Watermark = w
+}
+
 // RenderLogo returns the styled logo with version underneath.
 func RenderLogo() string {
 	var result strings.Builder
@@ -68,6 +77,7 @@ func NewStepTracker(total int) *StepTracker {
 // It prints a visual header for the step.
 func (s *StepTracker) StartStep(name string) {
 	s.current++
+	setStage(name)
 	s.printStepHeader(name)
 }
 
@@ -88,13 +98,18 @@ func (s *StepTracker) printStepHeader(name string) {
 	fmt.Fprintln(s.writer)
 
 	if NoColor {
-		fmt.Fprintf(s.writer, "=== STEP %s: %s ===\n", stepNum, strings.ToUpper(name))
+		fmt.Fprintf(s.writer, "=== STEP %s: %s ===%s\n", stepNum, strings.ToUpper(name), watermarkSuffix())
 	} else {
 		// Top line
 		fmt.Fprintln(s.writer, DimStyle.Render(line))
 		// Step header with number and name
 		header := fmt.Sprintf(" %s ▸ %s", stepNum, strings.ToUpper(name))
-		fmt.Fprintln(s.writer, BoldStyle.Render(header))
+		if Watermark != "" {
+			header = BoldStyle.Render(header) + "  " + WarningStyle.Render("["+Watermark+"]")
+		} else {
+			header = BoldStyle.Render(header)
+		}
+		fmt.Fprintln(s.writer, header)
 		// Bottom line
 		fmt.Fprintln(s.writer, DimStyle.Render(line))
 	}
@@ -103,10 +118,21 @@ func (s *StepTracker) printStepHeader(name string) {
 // printBanner prints the zapstore ASCII art logo.
 func (s *StepTracker) printBanner() {
 	if NoColor {
-		fmt.Fprintln(s.writer, "=== ZAPSTORE ===")
+		fmt.Fprintf(s.writer, "=== ZAPSTORE ===%s\n", watermarkSuffix())
 		return
 	}
 	fmt.Fprint(s.writer, RenderLogo())
+	if Watermark != "" {
+		fmt.Fprintln(s.writer, WarningStyle.Render("*** "+Watermark+" ***"))
+	}
+}
+
+// watermarkSuffix returns " [WATERMARK]" for plain-text headers, or "" when unset.
+func watermarkSuffix() string {
+	if Watermark == "" {
+		return ""
+	}
+	return " [" + Watermark + "]"
 }
 
 // SetTotal updates the total number of steps (useful when steps are conditional).
@@ -157,6 +183,9 @@ type KeyValue struct {
 
 // PrintSectionHeader prints a minor section header within a step.
 func PrintSectionHeader(name string) {
+	progressMu.Lock()
+	lastSection = name
+	progressMu.Unlock()
 	fmt.Println()
 	if NoColor {
 		fmt.Printf("  --- %s ---\n", name)
@@ -165,8 +194,15 @@ func PrintSectionHeader(name string) {
 	}
 }
 
-// PrintCompletionSummary prints a final summary when all steps are done.
+// PrintCompletionSummary prints a final summary when all steps are done,
+// preceded by any warnings collected during the run.
 func PrintCompletionSummary(success bool, message string) {
+	printWarningsSummary()
+
+	if Watermark != "" {
+		message = "[" + Watermark + "] " + message
+	}
+
 	lineWidth := 60
 	line := strings.Repeat("━", lineWidth)
 
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Warnings printed during a run are collected so they can be repeated in one place
+// before the completion summary, where they are not lost in the scroll.
+var (
+	warningsMu sync.Mutex
+	collecting bool
+	warnings   []string
+)
+
+// CollectWarnings starts collecting warnings printed by PrintWarning and
+// Spinner.StopWithWarning, discarding any collected earlier.
+func CollectWarnings() {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	collecting = true
+	warnings = nil
+}
+
+// RecordWarning adds message to the collected warnings. Duplicate messages are
+// recorded once. It is a no-op unless CollectWarnings has been called.
+func RecordWarning(message string) {
+	LogDebug("warning: %s", message)
+	emitProgress(ProgressEvent{Type: EventWarning, Message: message})
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	if !collecting {
+		return
+	}
+	for _, w := range warnings {
+		if w == message {
+			return
+		}
+	}
+	warnings = append(warnings, message)
+}
+
+// CollectedWarnings returns the warnings collected so far.
+func CollectedWarnings() []string {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	return append([]string(nil), warnings...)
+}
+
+// printWarningsSummary prints a "Warnings (N)" section listing the collected warnings
+// and clears them. Prints nothing when there are none.
+func printWarningsSummary() {
+	warningsMu.Lock()
+	collected := warnings
+	warnings = nil
+	warningsMu.Unlock()
+
+	if len(collected) == 0 {
+		return
+	}
+	PrintSectionHeader(fmt.Sprintf("Warnings (%d)", len(collected)))
+	bullet := Warning("⚠")
+	if NoColor {
+		bullet = "-"
+	}
+	for _, w := range collected {
+		fmt.Printf("  %s %s\n", bullet, w)
+	}
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectWarnings(t *testing.T) {
+	RecordWarning("before collection")
+	if got := CollectedWarnings(); len(got) != 0 {
+		t.Fatalf("expected nothing recorded before CollectWarnings, got %v", got)
+	}
+
+	CollectWarnings()
+	RecordWarning("screenshot skipped")
+	RecordWarning("metadata fetch failed")
+	RecordWarning("screenshot skipped")
+
+	want := []string{"screenshot skipped", "metadata fetch failed"}
+	if got := CollectedWarnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectedWarnings() = %v, want %v", got, want)
+	}
+
+	CollectWarnings()
+	if got := CollectedWarnings(); len(got) != 0 {
+		t.Errorf("expected CollectWarnings to reset, got %v", got)
+	}
+}
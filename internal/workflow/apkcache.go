@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+// parseAPK parses the APK at p.apkPath and, when the forge published a digest
+// for the asset, checks that the file matches it.
+func (p *Publisher) parseAPK() (*apk.APKInfo, error) {
+	info, err := WithSpinner(p.opts, "Parsing APK...", func() (*apk.APKInfo, error) {
+		return apk.ParseWithOptions(p.apkPath, apk.ParseOptions{
+			IconDensity: p.opts.Publish.IconDensity,
+			Lenient:     p.opts.Publish.LenientParse,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse APK: %w", err)
+	}
+	for _, warning := range info.ParseWarnings {
+		p.warn(warning)
+	}
+	if want := p.selectedAsset.SHA256; want != "" && !strings.EqualFold(info.SHA256, want) {
+		return nil, fmt.Errorf("APK sha256 %s does not match the release digest %s", info.SHA256, want)
+	}
+	return info, nil
+}
+
+// rescueCachedAPK recovers from a cached APK that failed parseAPK with cause:
+// the cache entry is evicted and the asset downloaded and checked once more.
+// When the fresh copy fails too, it is evicted as well, and the error says
+// whether the remote file changed since it was cached or is itself broken.
+func (p *Publisher) rescueCachedAPK(ctx context.Context, cause error) (*apk.APKInfo, error) {
+	cachedSum, _ := apk.HashFile(p.apkPath)
+	p.warn(fmt.Sprintf("Cached APK failed verification (%v); downloading it again", cause))
+	if err := source.DeleteCachedDownload(p.selectedAsset.URL, p.selectedAsset.Name); err != nil {
+		return nil, fmt.Errorf("%w (evicting the cached copy failed: %v)", cause, err)
+	}
+	p.selectedAsset.LocalPath = ""
+	p.apkFromCache = false
+
+	path, err := p.downloadAPK(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.apkPath = path
+	info, err := p.parseAPK()
+	if err == nil {
+		return info, nil
+	}
+
+	freshSum, hashErr := apk.HashFile(path)
+	_ = source.DeleteCachedDownload(p.selectedAsset.URL, p.selectedAsset.Name)
+	switch {
+	case hashErr != nil || cachedSum == "":
+		return nil, fmt.Errorf("%w (a fresh download failed too)", err)
+	case freshSum != cachedSum:
+		return nil, fmt.Errorf("%w (the remote file changed since it was cached: sha256 %s, cached %s)", err, freshSum, cachedSum)
+	default:
+		return nil, fmt.Errorf("%w (the remote file itself is corrupt: a fresh download has the same sha256 %s as the cached copy)", err, freshSum)
+	}
+}
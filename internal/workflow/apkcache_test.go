@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/tempdir"
+)
+
+// fakeDownloadSource serves data for every download, through the download cache
+// like the forge sources do.
+type fakeDownloadSource struct {
+	data      []byte
+	downloads int
+}
+
+func (f *fakeDownloadSource) Type() config.SourceType { return config.SourceGitHub }
+
+func (f *fakeDownloadSource) FetchLatestRelease(context.Context) (*source.Release, error) {
+	return nil, nil
+}
+
+func (f *fakeDownloadSource) Download(_ context.Context, asset *source.Asset, _ string, _ source.DownloadProgress) (string, error) {
+	f.downloads++
+	path := filepath.Join(os.TempDir(), asset.Name)
+	if err := os.WriteFile(path, f.data, 0644); err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+	return source.SaveToDownloadCache(asset.URL, asset.Name, path)
+}
+
+func TestRescueCachedAPK(t *testing.T) {
+	const url = "https://example.com/app.apk"
+	tests := []struct {
+		name   string
+		cached string
+		remote string
+		want   string
+	}{
+		{"remote file changed", "stale bytes", "new bytes", "the remote file changed since it was cached"},
+		{"remote file corrupt", "broken bytes", "broken bytes", "the remote file itself is corrupt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CACHE_HOME", t.TempDir())
+			t.Setenv("TMPDIR", t.TempDir())
+			t.Cleanup(tempdir.Cleanup)
+			seed := filepath.Join(t.TempDir(), "app.apk")
+			if err := os.WriteFile(seed, []byte(tt.cached), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := source.SaveToDownloadCache(url, "app.apk", seed); err != nil {
+				t.Fatal(err)
+			}
+
+			src := &fakeDownloadSource{data: []byte(tt.remote)}
+			p := &Publisher{
+				opts:          &cli.Options{Publish: cli.PublishOptions{Quiet: true}, Global: cli.GlobalOptions{JSON: true}},
+				cfg:           &config.Config{},
+				src:           src,
+				selectedAsset: &source.Asset{Name: "app.apk", URL: url},
+			}
+			err := p.downloadAndParseAPK(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("downloadAndParseAPK() = %v, want an error saying %q", err, tt.want)
+			}
+			if src.downloads != 1 {
+				t.Errorf("downloaded %d times, want exactly one retry", src.downloads)
+			}
+			if cached := source.GetCachedDownload(url, "app.apk"); cached != "" {
+				t.Errorf("broken download left in the cache at %s", cached)
+			}
+		})
+	}
+}
+
+func TestParseAPKWithoutCacheIsNotRetried(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Cleanup(tempdir.Cleanup)
+	src := &fakeDownloadSource{data: []byte("not an apk")}
+	p := &Publisher{
+		opts:          &cli.Options{Publish: cli.PublishOptions{Quiet: true}, Global: cli.GlobalOptions{JSON: true}},
+		cfg:           &config.Config{},
+		src:           src,
+		selectedAsset: &source.Asset{Name: "app.apk", URL: "https://example.com/app.apk"},
+	}
+	if err := p.downloadAndParseAPK(context.Background()); err == nil {
+		t.Fatal("downloadAndParseAPK() succeeded, want a parse error")
+	}
+	if src.downloads != 1 {
+		t.Errorf("downloaded %d times, want no retry of a fresh download", src.downloads)
+	}
+}
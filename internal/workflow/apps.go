@@ -0,0 +1,81 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// AppResult is the outcome of publishing one app from a config's apps: list.
+type AppResult struct {
+	Name      string
+	PackageID string
+	Version   string
+	Err       error // nil on success, ErrNothingToDo when already published
+}
+
+// PublishApps publishes each app of a monorepo config in order. The release is
+// fetched once and shared by all apps, and the signer created for the first app
+// is reused by the rest. A failing app does not stop the others.
+func PublishApps(ctx context.Context, opts *cli.Options, apps []*config.Config) []AppResult {
+	var (
+		src      source.Source
+		release  *source.Release
+		signer   nostr.Signer
+		attested bool
+	)
+	defer func() {
+		if signer != nil {
+			signer.Close()
+		}
+	}()
+
+	results := make([]AppResult, 0, len(apps))
+	for i, app := range apps {
+		result := AppResult{Name: app.Name}
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+		if !opts.Publish.Quiet && !opts.Global.JSON {
+			ui.PrintHeader(fmt.Sprintf("App %d/%d: %s", i+1, len(apps), app.Name))
+		}
+
+		pub, err := NewPublisher(ctx, opts, app)
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+
+		if release != nil {
+			// Apps without a release version take it from their own APK, so each gets a copy
+			shared := *release
+			pub.src = src
+			pub.release = &shared
+		}
+		pub.signer = signer
+		pub.attested = attested
+
+		result.Err = pub.Execute(ctx)
+		result.PackageID = pub.PackageID()
+		if pub.release != nil {
+			result.Version = pub.release.Version
+		}
+		if release == nil && pub.fetchedRelease != nil {
+			src, release = pub.src, pub.fetchedRelease
+		}
+		if pub.signer != nil {
+			signer = pub.signer
+		}
+		attested = attested || pub.attested
+		results = append(results, result)
+	}
+	return results
+}
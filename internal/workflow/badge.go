@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/badge"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// ExportBadge writes a shields.io endpoint JSON, and with --svg a static
+// SVG, for the latest release of --package on RELAY_URLS.
+func ExportBadge(ctx context.Context, opts *cli.Options) error {
+	eo := opts.Export
+	if eo.Package == "" {
+		return fmt.Errorf("--package is required")
+	}
+	pubkey, err := exportPubkey(ctx, eo.Pubkey)
+	if err != nil {
+		return err
+	}
+
+	publisher := nostr.NewPublisherFromEnv(config.GetEnv("RELAY_URLS"))
+	releases, err := publisher.FetchReleases(ctx, pubkey, eo.Package)
+	// Releases are read newest first, so a cut-short history still holds the latest
+	if err != nil && !errors.Is(err, nostr.ErrHistoryIncomplete) {
+		return fmt.Errorf("failed to fetch releases: %w", err)
+	}
+	latest := badge.Latest(releases, eo.Channel)
+	if latest == nil {
+		npub, _ := nip19.EncodePublicKey(pubkey)
+		return fmt.Errorf("no %s release of %s published by %s on %s", cmp.Or(eo.Channel, "main"), eo.Package, npub, strings.Join(publisher.RelayURLs(), ", "))
+	}
+	version := ""
+	if tag := latest.Tags.Find("version"); tag != nil {
+		version = tag[1]
+	}
+	release, err := publisher.FetchRelease(ctx, pubkey, eo.Package, version)
+	if err != nil {
+		return fmt.Errorf("failed to fetch release %s: %w", version, err)
+	}
+	if release == nil {
+		release = &nostr.PublishedRelease{Event: latest}
+	}
+
+	endpoint, err := badge.New(eo.Package, cmp.Or(eo.Channel, "main"), eo.Label, release, publisher.RelayURLs())
+	if err != nil {
+		return err
+	}
+	data, err := endpoint.JSON()
+	if err != nil {
+		return err
+	}
+	if eo.Out == "" || eo.Out == "-" {
+		os.Stdout.Write(data)
+	} else if err := os.WriteFile(eo.Out, data, 0644); err != nil {
+		return fmt.Errorf("failed to write badge: %w", err)
+	}
+	if eo.SVG != "" {
+		svg, err := endpoint.SVG()
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(eo.SVG, svg, 0644); err != nil {
+			return fmt.Errorf("failed to write SVG badge: %w", err)
+		}
+	}
+
+	if !opts.Global.JSON && eo.Out != "" && eo.Out != "-" {
+		status := "verified"
+		if !endpoint.Verified {
+			status = "not verified: missing or invalid signatures"
+		}
+		ui.PrintSuccess(fmt.Sprintf("Wrote badge for %s %s (%s)", eo.Package, version, status))
+	}
+	return nil
+}
+
+// exportPubkey returns the hex pubkey of --pubkey (npub or hex), or else of SIGN_WITH.
+func exportPubkey(ctx context.Context, flagValue string) (string, error) {
+	if flagValue != "" {
+		if strings.HasPrefix(flagValue, "npub1") {
+			_, data, err := nip19.Decode(flagValue)
+			if err != nil {
+				return "", fmt.Errorf("invalid --pubkey: %w", err)
+			}
+			return data.(string), nil
+		}
+		pubkey := strings.ToLower(flagValue)
+		if !gonostr.IsValid32ByteHex(pubkey) {
+			return "", fmt.Errorf("invalid --pubkey %q: must be an npub or a hex pubkey", flagValue)
+		}
+		return pubkey, nil
+	}
+
+	signWith := config.GetSignWith()
+	if signWith == "" {
+		return "", fmt.Errorf("pass --pubkey or set SIGN_WITH to name the publisher")
+	}
+	if npub := config.ResolvePubkeyFromSignWith(signWith); npub != "" {
+		_, data, err := nip19.Decode(npub)
+		if err != nil {
+			return "", fmt.Errorf("invalid SIGN_WITH npub: %w", err)
+		}
+		return data.(string), nil
+	}
+	signer, err := nostr.NewSignerWithOptions(ctx, signWith, nostr.SignerOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to create signer: %w", err)
+	}
+	defer signer.Close()
+	return signer.PublicKey(), nil
+}
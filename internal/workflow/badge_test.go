@@ -0,0 +1,26 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestExportPubkey(t *testing.T) {
+	pubkey, _ := gonostr.GetPublicKey(gonostr.GeneratePrivateKey())
+	npub, _ := nip19.EncodePublicKey(pubkey)
+	t.Setenv("SIGN_WITH", npub)
+
+	for _, value := range []string{npub, strings.ToUpper(pubkey), ""} {
+		got, err := exportPubkey(context.Background(), value)
+		if err != nil || got != pubkey {
+			t.Errorf("exportPubkey(%q) = %q, %v; want %s", value, got, err, pubkey)
+		}
+	}
+	if _, err := exportPubkey(context.Background(), "alice"); err == nil {
+		t.Error("exportPubkey(alice) = nil error, want invalid --pubkey")
+	}
+}
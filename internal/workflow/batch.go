@@ -0,0 +1,127 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/metrics"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// AllConfigArgs reports whether every argument is a YAML config file.
+func AllConfigArgs(args []string) bool {
+	for _, arg := range args {
+		ext := strings.ToLower(filepath.Ext(arg))
+		if ext != ".yaml" && ext != ".yml" {
+			return false
+		}
+	}
+	return true
+}
+
+// batchResult is the outcome of publishing one config of a batch.
+type batchResult struct {
+	Path     string
+	ExitCode int
+	Skipped  bool // Not run: an earlier config failed without --keep-going, or the batch was interrupted
+}
+
+// PublishBatch publishes each config file in opts.Args as an independent run of
+// publish, which returns that run's exit code. Without --keep-going the batch stops at the first failing config; with it every
+// config is attempted. The exit code is non-zero if any config failed.
+func PublishBatch(ctx context.Context, opts *cli.Options, publish func(context.Context, *cli.Options) int) int {
+	// Metrics accumulate over the whole batch and are written once
+	if opts.Publish.MetricsOut != "" {
+		metrics.Enable()
+		defer WriteMetrics(opts)
+	}
+
+	showStatus := !opts.Global.JSON
+	results := make([]batchResult, len(opts.Args))
+	stop := false
+	for i, path := range opts.Args {
+		results[i].Path = path
+		if stop || ctx.Err() != nil {
+			results[i].Skipped = true
+			continue
+		}
+		if showStatus && !opts.Publish.Quiet {
+			ui.PrintHeader(fmt.Sprintf("Config %d/%d: %s", i+1, len(opts.Args), path))
+		}
+
+		runOpts := *opts
+		runOpts.Args = []string{path}
+		runOpts.Publish.MetricsOut = ""
+		code := publish(ctx, &runOpts)
+		results[i].ExitCode = code
+
+		if showStatus {
+			if code == 0 {
+				ui.PrintSuccess(path + ": done")
+			} else {
+				ui.PrintError(fmt.Sprintf("%s: failed (exit %d)", path, code))
+			}
+		}
+		if code == 130 || (code != 0 && !opts.Publish.KeepGoing) {
+			stop = true
+		}
+	}
+
+	exitCode := 0
+	failed := 0
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed++
+			if exitCode == 0 || r.ExitCode == 130 {
+				exitCode = r.ExitCode
+			}
+		}
+	}
+
+	if showStatus && (!opts.Publish.Quiet || failed > 0) {
+		printBatchSummary(results)
+		if failed > 0 && !opts.Publish.KeepGoing && failed < len(results) {
+			fmt.Println(ui.Dim("  Use --keep-going to publish the remaining configs after a failure."))
+		}
+	}
+	return exitCode
+}
+
+// printBatchSummary prints one row per config of a batch.
+func printBatchSummary(results []batchResult) {
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Path))
+	}
+
+	ui.PrintHeader("Summary")
+	ok, failed, skipped := 0, 0, 0
+	for _, r := range results {
+		var status string
+		switch {
+		case r.Skipped:
+			status = ui.Dim("skipped")
+			skipped++
+		case r.ExitCode == 0:
+			status = ui.Success("ok")
+			ok++
+		default:
+			status = ui.Error(fmt.Sprintf("failed (exit %d)", r.ExitCode))
+			failed++
+		}
+		fmt.Printf("  %-*s  %s\n", width, r.Path, status)
+	}
+	fmt.Printf("\n  %d ok, %d failed, %d skipped\n", ok, failed, skipped)
+}
+
+// WriteMetrics writes the --metrics-out snapshot. Failures are reported but do not
+// change the exit code.
+func WriteMetrics(opts *cli.Options) {
+	if err := metrics.WriteFile(opts.Publish.MetricsOut); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", ui.SanitizeErrorMessage(err))
+	}
+}
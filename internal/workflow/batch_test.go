@@ -0,0 +1,45 @@
+package workflow
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+)
+
+func TestAllConfigArgs(t *testing.T) {
+	if !AllConfigArgs([]string{"a.yaml", "b.YML"}) {
+		t.Error("AllConfigArgs() = false for two configs")
+	}
+	if AllConfigArgs([]string{"a.yaml", "app.apk"}) {
+		t.Error("AllConfigArgs() = true with an APK argument")
+	}
+}
+
+func TestPublishBatchStopsUnlessKeepGoing(t *testing.T) {
+	for _, keepGoing := range []bool{false, true} {
+		var ran []string
+		publish := func(_ context.Context, opts *cli.Options) int {
+			ran = append(ran, opts.Args[0])
+			if opts.Args[0] == "b.yaml" {
+				return 1
+			}
+			return 0
+		}
+		opts := &cli.Options{Args: []string{"a.yaml", "b.yaml", "c.yaml"}}
+		opts.Global.JSON = true
+		opts.Publish.KeepGoing = keepGoing
+
+		if code := PublishBatch(context.Background(), opts, publish); code != 1 {
+			t.Errorf("keep-going=%v: PublishBatch() = %d, want 1", keepGoing, code)
+		}
+		want := []string{"a.yaml", "b.yaml"}
+		if keepGoing {
+			want = append(want, "c.yaml")
+		}
+		if !slices.Equal(ran, want) {
+			t.Errorf("keep-going=%v: ran %v, want %v", keepGoing, ran, want)
+		}
+	}
+}
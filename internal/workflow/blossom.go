@@ -0,0 +1,305 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zapstore/zsp/internal/blossom"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/history"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// Blob is a blob stored on a Blossom server, with what is known about its use.
+type Blob struct {
+	blossom.BlobDescriptor
+	PackageID  string   `json:"package_id,omitempty"` // from a referencing event or the local publish history
+	References []string `json:"references"`           // IDs of relay events referencing the blob
+}
+
+// Referenced reports whether any relay event references the blob.
+func (b Blob) Referenced() bool {
+	return len(b.References) > 0
+}
+
+// BlobInventory lists the signer's blobs on the Blossom server, newest first, and
+// looks up the relay events referencing each one. Blobs no relay event references
+// are attributed to a package through the local publish history when possible.
+// It fails when a relay's history could not be read to the end, since a blob
+// referenced only by the unread events would look unused.
+func BlobInventory(ctx context.Context, client *blossom.Client, publisher *nostr.Publisher, signer nostr.Signer) ([]Blob, error) {
+	descriptors, err := client.List(ctx, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(descriptors))
+	for i, d := range descriptors {
+		hashes[i] = strings.ToLower(d.SHA256)
+	}
+	refs, err := publisher.FetchBlobReferences(ctx, signer.PublicKey(), hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up blob references: %w", err)
+	}
+
+	entries, err := history.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	blobs := make([]Blob, len(descriptors))
+	for i, d := range descriptors {
+		blob := Blob{BlobDescriptor: d}
+		for _, ref := range refs[hashes[i]] {
+			blob.References = append(blob.References, ref.EventID)
+			if blob.PackageID == "" {
+				blob.PackageID = ref.PackageID
+			}
+		}
+		if blob.PackageID == "" {
+			blob.PackageID = historyPackage(entries, hashes[i])
+		}
+		blobs[i] = blob
+	}
+
+	sort.SliceStable(blobs, func(i, j int) bool {
+		return blobs[i].Uploaded > blobs[j].Uploaded
+	})
+	return blobs, nil
+}
+
+// historyPackage returns the package of the newest history entry whose events
+// mention hash, in a tag or the content, or "" if none does.
+func historyPackage(entries []history.Entry, hash string) string {
+	for _, entry := range entries {
+		for _, event := range entry.Events {
+			if event == nil {
+				continue
+			}
+			if strings.Contains(strings.ToLower(event.Content), hash) {
+				return entry.PackageID
+			}
+			for _, tag := range event.Tags {
+				for _, value := range tag {
+					if strings.Contains(strings.ToLower(value), hash) {
+						return entry.PackageID
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// PrunableBlobs returns the blobs that are safe to delete: those no relay event
+// references, excluding the keepLast most recently uploaded blobs of each package.
+// Referenced blobs count towards keepLast. Blobs of unknown package form one group.
+// blobs must be sorted newest first, as BlobInventory returns them.
+func PrunableBlobs(blobs []Blob, keepLast int) []Blob {
+	kept := make(map[string]int)
+	var prunable []Blob
+	for _, blob := range blobs {
+		if kept[blob.PackageID] < keepLast {
+			kept[blob.PackageID]++
+			continue
+		}
+		if !blob.Referenced() {
+			prunable = append(prunable, blob)
+		}
+	}
+	return prunable
+}
+
+// DeleteBlobs deletes the given blobs with a single signed delete authorization.
+// It attempts every blob and returns the hashes deleted, with an error per failure.
+func DeleteBlobs(ctx context.Context, client *blossom.Client, signer nostr.Signer, blobs []Blob) ([]string, map[string]error, error) {
+	if len(blobs) == 0 {
+		return nil, nil, nil
+	}
+	hashes := make([]string, len(blobs))
+	for i, blob := range blobs {
+		hashes[i] = blob.SHA256
+	}
+	authEvent, err := client.SignDeleteAuth(ctx, hashes, signer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var deleted []string
+	failed := make(map[string]error)
+	for _, hash := range hashes {
+		if err := ctx.Err(); err != nil {
+			return deleted, failed, err
+		}
+		if err := client.Delete(ctx, hash, authEvent); err != nil {
+			failed[hash] = err
+			continue
+		}
+		deleted = append(deleted, hash)
+	}
+	return deleted, failed, nil
+}
+
+// blossomInventory creates the signer and lists its blobs on BLOSSOM_URL
+// (default: the Zapstore CDN) along with their references on RELAY_URLS.
+// The caller must close the returned signer.
+func blossomInventory(ctx context.Context, opts *cli.Options) (*blossom.Client, nostr.Signer, []Blob, error) {
+	signWith := config.GetSignWith()
+	if signWith == "" {
+		if !opts.IsInteractive() {
+			return nil, nil, nil, fmt.Errorf("SIGN_WITH environment variable is required")
+		}
+		var err error
+		signWith, err = config.PromptSignWith()
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("signing setup failed: %w", err)
+		}
+	}
+	signer, err := nostr.NewSignerWithOptions(ctx, signWith, nostr.SignerOptions{})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+
+	client := blossom.NewClient(config.GetEnv("BLOSSOM_URL"))
+	publisher := nostr.NewPublisherFromEnv(config.GetEnv("RELAY_URLS"))
+
+	var spinner *ui.Spinner
+	if !opts.Global.JSON {
+		spinner = ui.NewSpinner("Listing blobs on " + client.ServerURL() + "...")
+		spinner.Start()
+	}
+	blobs, err := BlobInventory(ctx, client, publisher, signer)
+	if err != nil {
+		if spinner != nil {
+			spinner.StopWithError("Failed to list blobs")
+		}
+		signer.Close()
+		return nil, nil, nil, err
+	}
+	if spinner != nil {
+		spinner.StopWithSuccess(fmt.Sprintf("Found %d blobs", len(blobs)))
+	}
+	return client, signer, blobs, nil
+}
+
+// BlossomList prints the signer's blobs and whether any relay event references them.
+func BlossomList(ctx context.Context, opts *cli.Options) error {
+	_, signer, blobs, err := blossomInventory(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+
+	if opts.Global.JSON {
+		for _, blob := range blobs {
+			data, _ := json.Marshal(blob)
+			fmt.Println(string(data))
+		}
+		return nil
+	}
+
+	fmt.Println()
+	for _, blob := range blobs {
+		fmt.Println(formatBlob(blob))
+	}
+	return nil
+}
+
+// BlossomPrune deletes the signer's blobs that no relay event references,
+// after listing them and asking for confirmation.
+func BlossomPrune(ctx context.Context, opts *cli.Options) error {
+	if opts.Blossom.KeepLast < 0 {
+		return fmt.Errorf("--keep-last must not be negative")
+	}
+	if !opts.Blossom.DryRun && !opts.Blossom.Yes && !opts.IsInteractive() {
+		return fmt.Errorf("prune needs confirmation: pass --yes, or --dry-run to only list")
+	}
+
+	client, signer, blobs, err := blossomInventory(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer signer.Close()
+
+	prunable := PrunableBlobs(blobs, opts.Blossom.KeepLast)
+	var total int64
+	for _, blob := range prunable {
+		total += blob.Size
+	}
+
+	if opts.Global.JSON && opts.Blossom.DryRun {
+		for _, blob := range prunable {
+			data, _ := json.Marshal(blob)
+			fmt.Println(string(data))
+		}
+		return nil
+	}
+	if !opts.Global.JSON {
+		fmt.Println()
+		if len(prunable) == 0 {
+			ui.PrintSuccess("No unreferenced blobs to delete")
+			return nil
+		}
+		for _, blob := range prunable {
+			fmt.Println(formatBlob(blob))
+		}
+		fmt.Println()
+		ui.PrintInfo(fmt.Sprintf("%d unreferenced blobs, %s", len(prunable), ui.FormatBytes(total)))
+	}
+	if opts.Blossom.DryRun || len(prunable) == 0 {
+		return nil
+	}
+
+	if !opts.Blossom.Yes {
+		confirmed, err := ui.Confirm(fmt.Sprintf("Delete these %d blobs from %s?", len(prunable), client.ServerURL()), false)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	deleted, failed, err := DeleteBlobs(ctx, client, signer, prunable)
+	if opts.Global.JSON {
+		for _, hash := range deleted {
+			fmt.Printf("{\"deleted\":%q}\n", hash)
+		}
+	} else if len(deleted) > 0 {
+		ui.PrintSuccess(fmt.Sprintf("Deleted %d blobs", len(deleted)))
+	}
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		for hash, ferr := range failed {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", hash, ui.SanitizeErrorMessage(ferr))
+		}
+		return fmt.Errorf("failed to delete %d of %d blobs", len(failed), len(prunable))
+	}
+	return nil
+}
+
+// formatBlob renders a blob as one line: hash, size, upload date, package and references.
+func formatBlob(blob Blob) string {
+	pkg := blob.PackageID
+	if pkg == "" {
+		pkg = "-"
+	}
+	refs := "unreferenced"
+	if blob.Referenced() {
+		refs = fmt.Sprintf("%d events", len(blob.References))
+		if len(blob.References) == 1 {
+			refs = "1 event"
+		}
+	}
+	return fmt.Sprintf("  %s  %9s  %s  %-30s  %s",
+		blob.SHA256, ui.FormatBytes(blob.Size), time.Unix(blob.Uploaded, 0).Format("2006-01-02"), pkg, refs)
+}
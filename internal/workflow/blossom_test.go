@@ -0,0 +1,137 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/blossom"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+)
+
+func TestNewPublisherBlossomURL(t *testing.T) {
+	opts := &cli.Options{}
+	opts.Publish.Offline = true // skips community resolution
+
+	tests := []struct {
+		name   string
+		env    string
+		config string
+		want   string
+	}{
+		{"default", "", "", blossom.DefaultServer},
+		{"config", "", "https://cdn.example.com", "https://cdn.example.com"},
+		{"env overrides config", "https://env.example.com", "https://cdn.example.com", "https://env.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BLOSSOM_URL", tt.env)
+			cfg := &config.Config{Repository: "https://github.com/user/app", BlossomURL: tt.config}
+			pub, err := NewPublisher(context.Background(), opts, cfg)
+			if err != nil {
+				t.Fatalf("NewPublisher() error: %v", err)
+			}
+			if _, got := pub.Endpoints(); got != tt.want {
+				t.Errorf("Blossom URL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrunableBlobs(t *testing.T) {
+	blob := func(hash, pkg string, uploaded int64, refs ...string) Blob {
+		return Blob{
+			BlobDescriptor: blossom.BlobDescriptor{SHA256: hash, Uploaded: uploaded},
+			PackageID:      pkg,
+			References:     refs,
+		}
+	}
+	// Newest first, as BlobInventory returns them
+	blobs := []Blob{
+		blob("a5", "com.a", 50),
+		blob("b4", "com.b", 40, "event1"),
+		blob("a3", "com.a", 30, "event2"),
+		blob("x2", "", 20),
+		blob("a1", "com.a", 10),
+		blob("x0", "", 5),
+	}
+
+	tests := []struct {
+		keepLast int
+		want     []string
+	}{
+		{0, []string{"a5", "x2", "a1", "x0"}},
+		{1, []string{"a1", "x0"}},
+		{2, []string{"a1"}},
+	}
+	for _, tt := range tests {
+		got := PrunableBlobs(blobs, tt.keepLast)
+		if len(got) != len(tt.want) {
+			t.Errorf("keepLast=%d: got %d blobs, want %v", tt.keepLast, len(got), tt.want)
+			continue
+		}
+		for i, b := range got {
+			if b.SHA256 != tt.want[i] {
+				t.Errorf("keepLast=%d: blob %d = %s, want %s", tt.keepLast, i, b.SHA256, tt.want[i])
+			}
+			if b.Referenced() {
+				t.Errorf("keepLast=%d: referenced blob %s is prunable", tt.keepLast, b.SHA256)
+			}
+		}
+	}
+}
+
+func TestCheckBlobsPresent(t *testing.T) {
+	const apkHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	const iconHash = "a1b2c3d4e5f6789012345678901234567890123456789012345678901234abcd"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+apkHash {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	opts := &cli.Options{}
+	opts.Publish.SignOnly = true
+	p := &Publisher{
+		opts:       opts,
+		cfg:        &config.Config{},
+		apkInfo:    &apk.APKInfo{SHA256: apkHash},
+		apkPath:    "app.apk",
+		blossomURL: server.URL,
+	}
+	if err := p.checkBlobsPresent(context.Background()); err != nil {
+		t.Fatalf("checkBlobsPresent() with the APK uploaded: %v", err)
+	}
+
+	p.iconURL = server.URL + "/" + iconHash
+	err := p.checkBlobsPresent(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "Icon ("+iconHash+")") {
+		t.Fatalf("checkBlobsPresent() = %v, want the missing icon named", err)
+	}
+}
+
+func TestNewPublisherSignOnlyOffline(t *testing.T) {
+	opts := &cli.Options{}
+	opts.Publish.Offline = true
+	opts.Publish.SignOnly = true
+	if _, err := NewPublisher(context.Background(), opts, &config.Config{}); err == nil {
+		t.Fatal("expected an error for --sign-only with --offline")
+	}
+}
+
+func TestFormatBlob(t *testing.T) {
+	blob := Blob{BlobDescriptor: blossom.BlobDescriptor{SHA256: strings.Repeat("a", 64), Size: 2048}}
+	if got := formatBlob(blob); !strings.Contains(got, " - ") || !strings.HasSuffix(got, "unreferenced") {
+		t.Errorf("formatBlob() = %q, want no package and unreferenced", got)
+	}
+	blob.PackageID = "com.example.app"
+	blob.References = []string{"e1"}
+	if got := formatBlob(blob); !strings.Contains(got, "com.example.app") || !strings.HasSuffix(got, "1 event") {
+		t.Errorf("formatBlob() = %q, want the package and 1 event", got)
+	}
+}
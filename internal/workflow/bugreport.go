@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/zapstore/zsp/internal/blossom"
+	"github.com/zapstore/zsp/internal/bugreport"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+	"golang.org/x/term"
+)
+
+// OfferBugReport writes a redacted diagnostic bundle for a failed publish to
+// the current directory: always with --bug-report, and after a confirmation in
+// interactive runs. Nothing is sent over the network. The caller sets
+// report.Version and whatever it knows of the run's config.
+func OfferBugReport(opts *cli.Options, report *bugreport.Report, err error) {
+	if !opts.Publish.BugReport {
+		if !opts.IsInteractive() || !term.IsTerminal(int(os.Stdin.Fd())) {
+			return
+		}
+		ok, promptErr := ui.Confirm("Write a diagnostic bundle to attach to a bug report?", false)
+		if promptErr != nil || !ok {
+			return
+		}
+	}
+
+	report.Args = os.Args[1:]
+	report.Stage = ui.CurrentStage()
+	report.Err = err
+	report.Warnings = ui.CollectedWarnings()
+	report.Log = ui.DebugLog()
+	report.Time = time.Now()
+	if report.RelayURLs == nil {
+		report.RelayURLs = nostr.NewPublisherFromEnv(config.GetEnv("RELAY_URLS")).RelayURLs()
+	}
+	if report.BlossomURL == "" {
+		var configured string
+		if report.Config != nil {
+			configured = report.Config.BlossomURL
+		}
+		report.BlossomURL = cmp.Or(config.GetEnv("BLOSSOM_URL"), configured, blossom.DefaultServer)
+	}
+
+	path, writeErr := bugreport.Write(".", report)
+	if writeErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", ui.SanitizeErrorMessage(writeErr))
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Diagnostic bundle written to %s\n", path)
+	fmt.Fprintln(os.Stderr, "Review it before attaching it to an issue; secrets and usernames are redacted.")
+}
@@ -0,0 +1,34 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/bugreport"
+	"github.com/zapstore/zsp/internal/cli"
+)
+
+func TestOfferBugReportWritesBundle(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("RELAY_URLS", "wss://relay.example")
+	opts := &cli.Options{Publish: cli.PublishOptions{BugReport: true}}
+
+	OfferBugReport(opts, &bugreport.Report{Version: "1.2.3"}, errors.New("upload failed"))
+
+	files, _ := filepath.Glob("*")
+	if len(files) != 1 {
+		t.Fatalf("OfferBugReport() wrote %v, want one bundle", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1.2.3", "upload failed", "wss://relay.example"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("bundle is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/identity"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// certComparison is the --json output of zsp apk --compare-cert.
+type certComparison struct {
+	APK      string `json:"apk"`
+	CertHash string `json:"cert_hash"`
+	Pubkey   string `json:"pubkey,omitempty"`    // npub compared against, if any
+	CertFile string `json:"cert_file,omitempty"` // certificate file compared against, if any
+	Status   string `json:"status"`              // verified, expired, revoked, invalid, no-proof, match, rotated, mismatch
+	Match    bool   `json:"match"`
+}
+
+// CompareCert checks whether the APK in opts.Args[0] was signed by a known
+// signer: either the certificate the npub in opts.Args[1] has published a valid,
+// active NIP-C1 identity proof for, or known, a certificate loaded from a local
+// file. Anything but a match is returned as an error.
+func CompareCert(ctx context.Context, opts *cli.Options, known *x509.Certificate) error {
+	if len(opts.Args) != 2 {
+		return fmt.Errorf("usage: zsp apk --compare-cert <file.apk> <npub|certificate>")
+	}
+	apkPath, target := opts.Args[0], opts.Args[1]
+
+	lineage, err := apk.ExtractCertificateLineage(apkPath)
+	if err != nil {
+		return fmt.Errorf("failed to extract certificate from APK: %w", err)
+	}
+	cert := lineage[len(lineage)-1]
+	result := certComparison{APK: filepath.Base(apkPath), CertHash: identity.ComputeCertHash(cert)}
+
+	if known != nil {
+		result.CertFile = filepath.Base(target)
+		result.Status = apk.CompareCertificate(lineage, known)
+		result.Match = result.Status == "match"
+	} else if pubkeyHex, ok := ParsePubkeyArg(target); ok {
+		result.Pubkey, _ = nip19.EncodePublicKey(pubkeyHex)
+		if err := compareCertWithProof(ctx, opts, cert, pubkeyHex, &result); err != nil {
+			return err
+		}
+	} else {
+		return fmt.Errorf("not an npub or certificate file: %s", target)
+	}
+
+	if opts.Global.JSON {
+		data, _ := json.Marshal(result)
+		fmt.Println(string(data))
+	} else {
+		ui.PrintSectionHeader("APK Certificate")
+		ui.PrintKeyValue("File", result.APK)
+		if cert.Subject.CommonName != "" {
+			ui.PrintKeyValue("Subject", cert.Subject.CommonName)
+		}
+		ui.PrintKeyValue("Cert hash", result.CertHash)
+		fmt.Println()
+		if result.Pubkey != "" {
+			ui.PrintKeyValue("Signer", result.Pubkey)
+		} else {
+			ui.PrintKeyValue("Certificate", result.CertFile)
+		}
+		if result.Match {
+			ui.PrintSuccess(apk.CompareCertMessage(result.Status))
+		} else {
+			ui.PrintError(apk.CompareCertMessage(result.Status))
+		}
+	}
+
+	if !result.Match {
+		return fmt.Errorf("APK is not signed by a verified certificate of this signer (%s)", result.Status)
+	}
+	return nil
+}
+
+// compareCertWithProof fetches the npub's kind 30509 proof for the APK's
+// certificate and verifies it, recording the outcome in result.
+func compareCertWithProof(ctx context.Context, opts *cli.Options, cert *x509.Certificate, pubkeyHex string, result *certComparison) error {
+	publisher := nostr.NewPublisher(opts.APK.Relays)
+	var spinner *ui.Spinner
+	if !opts.Global.JSON {
+		spinner = ui.NewSpinner("Fetching identity proof...")
+		spinner.Start()
+	}
+	event, err := publisher.FetchIdentityProof(ctx, pubkeyHex, result.CertHash)
+	if spinner != nil {
+		spinner.Stop()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch identity proof: %w", err)
+	}
+	if event == nil {
+		result.Status = "no-proof"
+		return nil
+	}
+
+	proof, err := identity.ParseIdentityProofFromEvent(event)
+	if err != nil {
+		result.Status = "invalid"
+		return nil
+	}
+	verification := identity.VerifyIdentityProofWithCert(proof, event, pubkeyHex, cert)
+	switch {
+	case verification.Error != nil || !verification.Valid || !verification.CertHashMatch:
+		result.Status = "invalid"
+	case verification.Revoked:
+		result.Status = "revoked"
+	case verification.Expired:
+		result.Status = "expired"
+	default:
+		result.Status = "verified"
+		result.Match = true
+	}
+	return nil
+}
+
+// ParsePubkeyArg returns the hex pubkey for an npub or 64-character hex argument.
+func ParsePubkeyArg(arg string) (string, bool) {
+	if strings.HasPrefix(arg, "npub1") {
+		_, data, err := nip19.Decode(arg)
+		if err != nil {
+			return "", false
+		}
+		pubkey, ok := data.(string)
+		return pubkey, ok
+	}
+	if _, err := hex.DecodeString(arg); err == nil && len(arg) == 64 {
+		return strings.ToLower(arg), true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/zapstore/zsp/internal/blossom"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+	"golang.org/x/term"
+)
+
+// ApplyDevPreset configures a --dev run: the local relay (ZSP_DEV_RELAY or
+// ws://localhost:10547), the local Blossom server and the built-in test key.
+// Production relay or Blossom URLs in the environment are an error. When the
+// local services are unreachable, it offers to skip uploads and print events.
+func ApplyDevPreset(opts *cli.Options) error {
+	relayURL := nostr.DevRelay
+	if v := config.GetEnv("ZSP_DEV_RELAY"); v != "" {
+		relayURL = v
+	}
+	relayURLs := []string{relayURL}
+	if v := config.GetEnv("RELAY_URLS"); v != "" {
+		relayURLs = append(relayURLs, strings.Split(v, ",")...)
+	}
+	if err := opts.Publish.ValidateDev(relayURLs, config.GetEnv("BLOSSOM_URL")); err != nil {
+		return err
+	}
+
+	os.Setenv("RELAY_URLS", relayURL)
+	os.Setenv("BLOSSOM_URL", blossom.DevServer)
+	os.Setenv("SIGN_WITH", nostr.DevSecretKey)
+	opts.Publish.SkipPreview = true
+	// The test key has no certificate to link, and local relays need no clock check
+	opts.Publish.SkipCertificateLinking = true
+	opts.Publish.TrustLocalClock = true
+	ui.SetWatermark("DEV MODE")
+
+	showStatus := !opts.Publish.Quiet && !opts.Global.JSON
+	if showStatus {
+		fmt.Fprintf(os.Stderr, "DEV MODE: publishing to %s and %s with the built-in test key\n", relayURL, blossom.DevServer)
+	}
+
+	if opts.Publish.Offline {
+		return nil
+	}
+	var unreachable []string
+	for _, u := range []string{relayURL, blossom.DevServer} {
+		if !devServiceReachable(u) {
+			unreachable = append(unreachable, u)
+		}
+	}
+	if len(unreachable) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("DEV MODE: %s not reachable", strings.Join(unreachable, " and "))
+	skip := true
+	if showStatus && term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintln(os.Stderr, message)
+		var err error
+		if skip, err = ui.Confirm("Skip uploads and just print events?", true); err != nil {
+			return err
+		}
+	} else if showStatus {
+		fmt.Fprintf(os.Stderr, "%s; skipping uploads and printing events\n", message)
+	}
+	if !skip {
+		return fmt.Errorf("local dev infrastructure not reachable: %s", strings.Join(unreachable, ", "))
+	}
+	opts.Publish.Offline = true
+	return nil
+}
+
+// devServiceReachable reports whether a TCP connection to rawURL's host succeeds.
+func devServiceReachable(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" || u.Scheme == "wss" {
+			port = "443"
+		}
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), 2*time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,51 @@
+package workflow
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+)
+
+func TestDevIgnoresConfigNIP65Relays(t *testing.T) {
+	t.Setenv("RELAY_URLS", nostr.DevRelay)
+	cfg, err := config.Parse(strings.NewReader("repository: https://github.com/acme/app\nrelays: nip65\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &cli.Options{Publish: cli.PublishOptions{Dev: true, Quiet: true}}
+	p, err := NewPublisher(context.Background(), opts, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.signer, _ = partialPublishFixture(t)
+
+	if err := p.resolveRelays(context.Background(), p.signer.PublicKey()); err != nil {
+		t.Fatalf("resolveRelays() = %v", err)
+	}
+	if got := p.publisher.AllRelayURLs(); !slices.Equal(got, []string{nostr.DevRelay}) {
+		t.Errorf("--dev with relays: nip65 publishes to %v, want only %s", got, nostr.DevRelay)
+	}
+}
+
+func TestSigningPubkeyBeforeSigner(t *testing.T) {
+	sk := gonostr.GeneratePrivateKey()
+	want, _ := gonostr.GetPublicKey(sk)
+	nsec, _ := nip19.EncodePrivateKey(sk)
+	t.Setenv("SIGN_WITH", nsec)
+
+	p := &Publisher{opts: &cli.Options{}}
+	if got := p.signingPubkey(); got != want {
+		t.Errorf("signingPubkey() = %q, want %q", got, want)
+	}
+	t.Setenv("SIGN_WITH", "bunker://abc?relay=wss://relay.example")
+	if got := p.signingPubkey(); got != "" {
+		t.Errorf("signingPubkey() for a bunker = %q, want empty", got)
+	}
+}
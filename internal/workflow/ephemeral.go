@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// ApplyEphemeralKey configures an --ephemeral-key run: a fresh keypair signs
+// the events, certificate linking is skipped, and the output is watermarked.
+func ApplyEphemeralKey(opts *cli.Options) {
+	nsec, _ := nip19.EncodePrivateKey(gonostr.GeneratePrivateKey())
+	opts.Publish.EphemeralSecret = nsec
+	opts.Publish.SkipCertificateLinking = true
+	ui.SetWatermark("EPHEMERAL KEY")
+
+	if !opts.Publish.Quiet && !opts.Global.JSON {
+		fmt.Fprintln(os.Stderr, "EPHEMERAL KEY: publishing with a throwaway key generated for this run")
+	}
+}
+
+// PrintEphemeralKey prints the --ephemeral-key keypair to stderr, even in quiet
+// mode, so the test events can be deleted later.
+func PrintEphemeralKey(opts *cli.Options) {
+	prefix, data, err := nip19.Decode(opts.Publish.EphemeralSecret)
+	if err != nil || prefix != "nsec" {
+		return
+	}
+	pubkey, err := gonostr.GetPublicKey(data.(string))
+	if err != nil {
+		return
+	}
+	npub, _ := nip19.EncodePublicKey(pubkey)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "EPHEMERAL KEY: keep this key to delete the test events later")
+	fmt.Fprintf(os.Stderr, "  npub: %s\n", npub)
+	fmt.Fprintf(os.Stderr, "  nsec: %s\n", opts.Publish.EphemeralSecret)
+}
+
+// attestEphemeralKey publishes a label event signed by the SIGN_WITH key that
+// marks the --ephemeral-key pubkey as its test publisher. Without SIGN_WITH the
+// run stays anonymous. Failures are warnings: the attestation is a courtesy to
+// relays and moderators, not part of the release.
+func (p *Publisher) attestEphemeralKey(ctx context.Context) {
+	p.attested = true
+	signWith := config.GetSignWith()
+	if signWith == "" {
+		if p.opts.Global.Verbose {
+			p.status("  SIGN_WITH not set; the ephemeral key is not linked to an identity")
+		}
+		return
+	}
+
+	owner, err := nostr.NewSignerWithOptions(ctx, signWith, nostr.SignerOptions{OnFailover: p.warn})
+	if err != nil {
+		p.warn(fmt.Sprintf("could not create the SIGN_WITH signer for the attestation: %v", err))
+		return
+	}
+	defer owner.Close()
+	if owner.Type() == nostr.SignerNpub {
+		p.warn("SIGN_WITH is an npub, which cannot sign the ephemeral key attestation")
+		return
+	}
+
+	attestation, err := nostr.BuildTestPublisherAttestation(p.signer.PublicKey(), owner.PublicKey())
+	if err != nil {
+		p.warn(fmt.Sprintf("could not build the ephemeral key attestation: %v", err))
+		return
+	}
+	if err := owner.Sign(ctx, attestation); err != nil {
+		p.warn(fmt.Sprintf("could not sign the ephemeral key attestation: %v", err))
+		return
+	}
+	for _, r := range p.publisher.Publish(ctx, attestation) {
+		if r.Success {
+			npub, _ := nip19.EncodePublicKey(owner.PublicKey())
+			p.status("  Ephemeral key attested as a test publisher for %s", npub)
+			return
+		}
+	}
+	p.warn("no relay accepted the ephemeral key attestation")
+}
@@ -0,0 +1,284 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/blossom"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/picker"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+// ExplainPlan describes in plain language what a publish run would do with
+// the resolved config and options, one step per entry (--explain). It reads
+// only the config and environment: nothing is fetched, signed or published,
+// so community relays and Blossom servers are named rather than resolved.
+func ExplainPlan(opts *cli.Options, cfg *config.Config) []string {
+	var steps []string
+	add := func(format string, args ...any) {
+		steps = append(steps, fmt.Sprintf(format, args...))
+	}
+
+	// Fetch
+	sourceType := cfg.GetSourceType()
+	switch sourceType {
+	case config.SourceLocal:
+		add("Will read the APK from %s", cfg.ReleaseSource.LocalPath)
+	case config.SourceWeb:
+		add("Will scrape the latest APK from %s", hostPath(cfg.GetAPKSourceURL()))
+	default:
+		release := "latest release"
+		if opts.Publish.IncludePreReleases || cfg.IncludePreReleases {
+			release = "latest release (pre-releases included)"
+		}
+		step := fmt.Sprintf("Will fetch the %s from %s", release, hostPath(cfg.GetAPKSourceURL()))
+		if sourceType != config.SourceUnknown {
+			step += " (" + sourceType.String() + ")"
+		}
+		if cfg.ReleaseFilter != "" {
+			step += fmt.Sprintf(", considering only tags matching %q", cfg.ReleaseFilter)
+		}
+		steps = append(steps, step)
+		if cfg.MinReleaseAge != "" && !opts.Publish.IgnoreReleaseAge {
+			add("Will stop if the release changed less than %s ago", cfg.MinReleaseAge)
+		}
+	}
+
+	// Select
+	if sourceType != config.SourceLocal {
+		switch {
+		case cfg.Match != "" && cfg.MatchLabel != "":
+			add("Will pick the APK whose name matches %q and label matches %q", cfg.Match, cfg.MatchLabel)
+		case cfg.Match != "":
+			add("Will pick the APK whose name matches %q", cfg.Match)
+		case cfg.MatchLabel != "":
+			add("Will pick the APK whose label matches %q", cfg.MatchLabel)
+		default:
+			add("Will pick the best-ranked arm64-v8a APK")
+		}
+		if opts.Publish.Variant != "" {
+			add("Will keep only the APKs matching variant %q (%q)", opts.Publish.Variant, cfg.Variants[opts.Publish.Variant])
+		}
+	}
+	if len(cfg.Variants) > 0 {
+		add("Will tag the release with the variant whose pattern matches the APK (%s)", strings.Join(slices.Sorted(maps.Keys(cfg.Variants)), ", "))
+	}
+	add("Will read the package ID, version and signing certificate from the APK")
+
+	// Metadata
+	if opts.Publish.SkipMetadata {
+		add("Will skip external metadata and use only the config and APK")
+	} else {
+		metadataSources := opts.Publish.Metadata
+		if len(metadataSources) == 0 {
+			metadataSources = source.DefaultMetadataSources(cfg)
+		}
+		if len(metadataSources) == 0 {
+			add("Will use metadata from the config and APK only (no external sources apply)")
+		} else {
+			add("Will fetch metadata (description, icon, screenshots) from %s", strings.Join(metadataSources, ", "))
+		}
+	}
+
+	signWith := config.GetEnv("SIGN_WITH")
+	// With --ephemeral-key, SIGN_WITH only signs the attestation
+	npubMode := !opts.Publish.EphemeralKey && strings.HasPrefix(strings.TrimSpace(signWith), "npub1")
+
+	// Detached signature
+	if sig := cfg.DetachedSignature; sig != nil {
+		add("Will sign the APK with %s using the key in %s and reference the signature from the asset event", sig.Type, sig.KeyEnv)
+	}
+
+	// Upload
+	files := "APK + icon"
+	if cfg.DetachedSignature != nil {
+		files = "APK + signature + icon"
+	}
+	if len(cfg.Images) > 0 {
+		files += fmt.Sprintf(" + %d screenshot(s)", len(cfg.Images))
+	} else if !opts.Publish.SkipMetadata {
+		files += " + screenshots"
+	}
+	switch {
+	case opts.Publish.Offline:
+		add("Will not upload anything (--offline); the %s must be uploaded separately", files)
+	case opts.Publish.SignOnly:
+		add("Will not upload anything (--sign-only); the %s must already be on %s", files, hostPath(explainBlossomURL(opts, cfg)))
+	case npubMode:
+		add("Will not upload anything (npub signer); an upload manifest for %s is printed instead", files)
+	default:
+		add("Will upload %s to %s", files, hostPath(explainBlossomURL(opts, cfg)))
+	}
+
+	// Sign
+	events := "3 events (app kind 32267, release kind 30063, asset kind 3063)"
+	if opts.Publish.SkipAppEvent {
+		events = "2 events (release kind 30063, asset kind 3063)"
+	}
+	add("Will build %s", events)
+	if opts.Publish.EphemeralKey {
+		add("Will sign them with a throwaway key generated for this run and print it at the end (--ephemeral-key)")
+		if signWith != "" && !strings.HasPrefix(strings.TrimSpace(signWith), "npub1") {
+			add("Will publish a label event signed by SIGN_WITH marking the throwaway key as its test publisher")
+		}
+	} else {
+		add("%s", explainSigner(opts, signWith))
+	}
+
+	// Publish
+	switch {
+	case opts.Publish.Offline:
+		add("Will print the signed events as JSON instead of publishing them (--offline)")
+	case opts.Publish.SignOnly:
+		add("Will print the signed events as JSONL instead of publishing them, without contacting relays (--sign-only)")
+	case npubMode:
+		add("Will print the unsigned events for external signing instead of publishing them")
+	default:
+		add("Will publish them to %s", explainRelays(opts, cfg))
+	}
+
+	return steps
+}
+
+// explainBlossomURL returns the Blossom server NewPublisher would use,
+// naming the community's server instead of looking it up.
+func explainBlossomURL(opts *cli.Options, cfg *config.Config) string {
+	if server := cmp.Or(config.GetEnv("BLOSSOM_URL"), cfg.BlossomURL); server != "" {
+		return server
+	}
+	if !opts.Publish.Dev && hasCommunity(cfg) {
+		return "the community's Blossom server (kind 10222), else " + hostPath(blossom.DefaultServer)
+	}
+	return blossom.DefaultServer
+}
+
+// explainRelays describes the relays the events would be published to.
+func explainRelays(opts *cli.Options, cfg *config.Config) string {
+	relays := splitRelays(config.GetEnv("RELAY_URLS"))
+	for i, relay := range relays {
+		relays[i] = hostPath(relay)
+	}
+	if len(relays) == 0 {
+		relays = []string{hostPath(nostr.DefaultRelay)}
+	}
+	target := strings.Join(relays, ", ")
+	if !opts.Publish.Dev && hasCommunity(cfg) {
+		target = "the community's relays (kind 10222), else " + target
+	}
+
+	mode := opts.Publish.Relays
+	if mode == "" {
+		mode = cfg.Relays
+	}
+	switch mode {
+	case "nip65":
+		return target + " plus the signer's NIP-65 write relays"
+	case "nip65-only":
+		return "the signer's NIP-65 write relays"
+	}
+	return target
+}
+
+// explainSigner describes how the events would be signed without connecting
+// to a bunker or starting the browser signer.
+func explainSigner(opts *cli.Options, signWith string) string {
+	signWith = strings.TrimSpace(signWith)
+	switch {
+	case signWith == "":
+		if opts.Publish.Quiet || opts.Publish.Offline || opts.Publish.SignOnly {
+			return "Cannot sign: SIGN_WITH is not set (it is required with --quiet, --offline and --sign-only)"
+		}
+		return "Will ask how to sign, since SIGN_WITH is not set"
+	case strings.HasPrefix(signWith, "npub1"):
+		return "Will leave the events unsigned for " + signWith
+	case strings.HasPrefix(signWith, "nsec1"):
+		if npub := config.ResolvePubkeyFromSignWith(signWith); npub != "" {
+			return "Will sign them as " + npub
+		}
+		return "Cannot sign: SIGN_WITH holds an invalid nsec"
+	case strings.HasPrefix(signWith, config.KeychainSignerPrefix):
+		return "Will sign them as " + strings.TrimPrefix(signWith, config.KeychainSignerPrefix) + " with the key in the OS keyring"
+	case nostr.ParseBunkerList(signWith) != nil:
+		return "Will sign them with a remote signer, failing over across the listed bunkers"
+	case strings.HasPrefix(signWith, "bunker://"):
+		return "Will sign them with the remote signer (bunker) at " + hostPath(signWith)
+	case signWith == "browser":
+		return "Will sign them with a browser extension (NIP-07)"
+	}
+	if len(signWith) <= 64 {
+		hexKey := strings.ReplaceAll(fmt.Sprintf("%064s", signWith), " ", "0")
+		if nsec, err := nip19.EncodePrivateKey(hexKey); err == nil {
+			if npub := config.ResolvePubkeyFromSignWith(nsec); npub != "" {
+				return "Will sign them as " + npub
+			}
+		}
+	}
+	return "Cannot sign: SIGN_WITH is not a recognised key, bunker URL or \"browser\""
+}
+
+// hasCommunity reports whether cfg names a community other than Zapstore,
+// whose relays and Blossom server would be resolved at publish time.
+func hasCommunity(cfg *config.Config) bool {
+	for _, c := range cfg.Communities {
+		if c != nostr.DefaultCommunity {
+			return true
+		}
+	}
+	return false
+}
+
+// hostPath shortens a URL to host and path for display, e.g.
+// "https://github.com/user/app" to "github.com/user/app". The query of
+// bunker URLs holds the connection secret, so it is always dropped.
+func hostPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host + strings.TrimSuffix(u.Path, "/")
+}
+
+// ExplainSelection fetches the latest release and reports how each asset was
+// filtered and ranked. Nothing is downloaded or published.
+func ExplainSelection(ctx context.Context, opts *cli.Options, cfg *config.Config) error {
+	src, err := source.NewWithOptions(cfg, source.Options{
+		BaseDir:            cfg.BaseDir,
+		SkipCache:          true,
+		SkipDownloadCache:  true,
+		IncludePreReleases: opts.Publish.IncludePreReleases,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create source: %w", err)
+	}
+
+	release, err := src.FetchLatestRelease(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch release: %w", err)
+	}
+
+	exp, err := picker.DefaultModel.ExplainVariants(release.Assets, cfg.Match, cfg.MatchLabel, cfg.Variants, opts.Publish.Variant)
+	if err != nil {
+		return err
+	}
+
+	if opts.Global.JSON {
+		data, err := json.Marshal(exp)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Print(exp.Render(release, cfg.Variants))
+	return nil
+}
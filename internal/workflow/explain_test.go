@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+)
+
+func TestExplainPlan(t *testing.T) {
+	t.Setenv("SIGN_WITH", "0000000000000000000000000000000000000000000000000000000000000001")
+	t.Setenv("RELAY_URLS", "wss://relay.example.com")
+	t.Setenv("BLOSSOM_URL", "https://blossom.example.com")
+
+	cfg := &config.Config{Repository: "https://github.com/user/app", Match: "arm64"}
+	opts := &cli.Options{}
+	opts.Publish.Metadata = []string{"fdroid"}
+
+	plan := strings.Join(ExplainPlan(opts, cfg), "\n")
+	for _, want := range []string{
+		"latest release from github.com/user/app (github)",
+		`APK whose name matches "arm64"`,
+		"metadata (description, icon, screenshots) from fdroid",
+		"upload APK + icon + screenshots to blossom.example.com",
+		"Will build 3 events",
+		"sign them as npub10xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqpkge6d",
+		"publish them to relay.example.com",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan missing %q:\n%s", want, plan)
+		}
+	}
+}
+
+func TestExplainPlanNpubSigner(t *testing.T) {
+	t.Setenv("SIGN_WITH", "npub10xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqpkge6d")
+
+	cfg := &config.Config{Repository: "https://github.com/user/app"}
+	opts := &cli.Options{}
+	opts.Publish.SkipAppEvent = true
+	opts.Publish.SkipMetadata = true
+
+	plan := strings.Join(ExplainPlan(opts, cfg), "\n")
+	for _, want := range []string{
+		"skip external metadata",
+		"Will not upload anything (npub signer)",
+		"Will build 2 events",
+		"unsigned events for external signing",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan missing %q:\n%s", want, plan)
+		}
+	}
+	if strings.Contains(plan, "publish them to") {
+		t.Errorf("npub plan should not publish:\n%s", plan)
+	}
+}
+
+func TestExplainPlanSignOnly(t *testing.T) {
+	t.Setenv("SIGN_WITH", "0000000000000000000000000000000000000000000000000000000000000001")
+	t.Setenv("BLOSSOM_URL", "https://blossom.example.com")
+
+	cfg := &config.Config{Repository: "https://github.com/user/app"}
+	opts := &cli.Options{}
+	opts.Publish.SignOnly = true
+
+	plan := strings.Join(ExplainPlan(opts, cfg), "\n")
+	for _, want := range []string{
+		"must already be on blossom.example.com",
+		"signed events as JSONL instead of publishing them, without contacting relays",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan missing %q:\n%s", want, plan)
+		}
+	}
+	if strings.Contains(plan, "publish them to") {
+		t.Errorf("sign-only plan should not publish:\n%s", plan)
+	}
+}
+
+func TestExplainPlanEphemeralKey(t *testing.T) {
+	t.Setenv("SIGN_WITH", "npub10xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqpkge6d")
+	t.Setenv("RELAY_URLS", "wss://relay.example.com")
+
+	cfg := &config.Config{Repository: "https://github.com/user/app"}
+	opts := &cli.Options{}
+	opts.Publish.EphemeralKey = true
+
+	plan := strings.Join(ExplainPlan(opts, cfg), "\n")
+	for _, want := range []string{
+		"throwaway key generated for this run",
+		"publish them to relay.example.com",
+	} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("plan missing %q:\n%s", want, plan)
+		}
+	}
+	if strings.Contains(plan, "unsigned") || strings.Contains(plan, "label event") {
+		t.Errorf("an npub SIGN_WITH can neither sign the events nor attest the key:\n%s", plan)
+	}
+}
+
+func TestExplainSignerHidesBunkerSecret(t *testing.T) {
+	got := explainSigner(&cli.Options{}, "bunker://abcdef?relay=wss://r.example.com&secret=hunter2")
+	if strings.Contains(got, "hunter2") || !strings.Contains(got, "abcdef") {
+		t.Errorf("explainSigner = %q", got)
+	}
+}
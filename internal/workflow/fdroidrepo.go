@@ -0,0 +1,317 @@
+package workflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/config"
+)
+
+// fdroidIndexVersion is the repo index format version written to index-v1.json.
+const fdroidIndexVersion = 21
+
+// pngSignature starts every PNG file; F-Droid icons must be PNGs.
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// FDroidRepoParams describes the app version added to an F-Droid repo.
+type FDroidRepoParams struct {
+	Dir     string       // repo root; files go in Dir/repo
+	APKInfo *apk.APKInfo // parsed APK (FilePath is copied into the repo)
+	Config  *config.Config
+	Icon    []byte    // PNG icon, nil for none
+	Now     time.Time // timestamp for the index and new entries
+}
+
+// fdroidPackage is a version entry of index-v1.json "packages".
+type fdroidPackage struct {
+	Added            int64       `json:"added"`
+	APKName          string      `json:"apkName"`
+	Features         []string    `json:"features,omitempty"`
+	Hash             string      `json:"hash"`
+	HashType         string      `json:"hashType"`
+	MinSDKVersion    int32       `json:"minSdkVersion,omitempty"`
+	NativeCode       []string    `json:"nativecode,omitempty"`
+	PackageName      string      `json:"packageName"`
+	Signer           string      `json:"signer,omitempty"`
+	Size             int64       `json:"size"`
+	TargetSDKVersion int32       `json:"targetSdkVersion,omitempty"`
+	UsesPermission   [][]*string `json:"uses-permission,omitempty"`
+	VersionCode      int64       `json:"versionCode"`
+	VersionName      string      `json:"versionName"`
+}
+
+// WriteFDroidRepo adds the APK to the unsigned F-Droid repo at params.Dir: the
+// APK is copied to repo/<package>_<versionCode>.apk, the icon to
+// repo/icons/<package>.<versionCode>.png (and icons-640), and repo/index-v1.json
+// is created or updated. Other apps' entries and this app's other versions are
+// kept; unknown index fields are preserved. Signing the repo is left to fdroidserver.
+func WriteFDroidRepo(params FDroidRepoParams) error {
+	info := params.APKInfo
+	repoDir := filepath.Join(params.Dir, "repo")
+	if err := os.MkdirAll(repoDir, 0755); err != nil {
+		return fmt.Errorf("failed to create F-Droid repo: %w", err)
+	}
+
+	apkName := fmt.Sprintf("%s_%d.apk", info.PackageID, info.VersionCode)
+	if err := copyFile(info.FilePath, filepath.Join(repoDir, apkName)); err != nil {
+		return fmt.Errorf("failed to copy APK into F-Droid repo: %w", err)
+	}
+
+	iconName := ""
+	if len(params.Icon) > 0 {
+		iconName = fmt.Sprintf("%s.%d.png", info.PackageID, info.VersionCode)
+		for _, dir := range []string{"icons", "icons-640"} {
+			if err := os.MkdirAll(filepath.Join(repoDir, dir), 0755); err != nil {
+				return fmt.Errorf("failed to create F-Droid icon directory: %w", err)
+			}
+			if err := os.WriteFile(filepath.Join(repoDir, dir, iconName), params.Icon, 0644); err != nil {
+				return fmt.Errorf("failed to write F-Droid icon: %w", err)
+			}
+		}
+	}
+
+	indexPath := filepath.Join(repoDir, "index-v1.json")
+	index, err := loadFDroidIndex(indexPath)
+	if err != nil {
+		return err
+	}
+	if err := updateFDroidIndex(index, params, apkName, iconName, filepath.Base(params.Dir)); err != nil {
+		return err
+	}
+	return writeFDroidIndex(indexPath, index)
+}
+
+// loadFDroidIndex reads index-v1.json as raw top-level fields so that fields
+// zsp does not write survive the update. A missing file yields an empty index.
+func loadFDroidIndex(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]json.RawMessage{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read F-Droid index: %w", err)
+	}
+	index := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse F-Droid index %s: %w", path, err)
+	}
+	return index, nil
+}
+
+// updateFDroidIndex sets this app's entry and version in index.
+func updateFDroidIndex(index map[string]json.RawMessage, params FDroidRepoParams, apkName, iconName, repoName string) error {
+	info := params.APKInfo
+	now := params.Now.UnixMilli()
+
+	repo := map[string]any{"name": repoName}
+	if err := unmarshalIndexField(index, "repo", &repo); err != nil {
+		return err
+	}
+	repo["timestamp"] = now
+	repo["version"] = fdroidIndexVersion
+
+	requests := map[string]any{"install": []string{}, "uninstall": []string{}}
+	if err := unmarshalIndexField(index, "requests", &requests); err != nil {
+		return err
+	}
+
+	// Packages: replace a previous entry for this version code, newest first
+	packages := map[string][]json.RawMessage{}
+	if err := unmarshalIndexField(index, "packages", &packages); err != nil {
+		return err
+	}
+	pkg := fdroidPackage{
+		Added:            now,
+		APKName:          apkName,
+		Features:         info.Features,
+		Hash:             info.SHA256,
+		HashType:         "sha256",
+		MinSDKVersion:    info.MinSDK,
+		NativeCode:       info.Architectures,
+		PackageName:      info.PackageID,
+		Signer:           info.CertFingerprint,
+		Size:             info.FileSize,
+		TargetSDKVersion: info.TargetSDK,
+		VersionCode:      info.VersionCode,
+		VersionName:      info.VersionName,
+	}
+	for _, perm := range info.Permissions {
+		pkg.UsesPermission = append(pkg.UsesPermission, []*string{&perm, nil})
+	}
+	versions := []json.RawMessage{}
+	var latest fdroidPackage
+	for _, raw := range packages[info.PackageID] {
+		var existing fdroidPackage
+		if err := json.Unmarshal(raw, &existing); err != nil {
+			return fmt.Errorf("failed to parse F-Droid index entry for %s: %w", info.PackageID, err)
+		}
+		if existing.VersionCode == info.VersionCode {
+			pkg.Added = existing.Added
+			continue
+		}
+		versions = append(versions, raw)
+		if existing.VersionCode > latest.VersionCode {
+			latest = existing
+		}
+	}
+	raw, err := json.Marshal(pkg)
+	if err != nil {
+		return err
+	}
+	versions = append(versions, raw)
+	if pkg.VersionCode >= latest.VersionCode {
+		latest = pkg
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versionCodeOf(versions[i]) > versionCodeOf(versions[j])
+	})
+	packages[info.PackageID] = versions
+
+	// Apps: merge into this app's entry, keeping fields set by hand
+	var apps []map[string]any
+	if err := unmarshalIndexField(index, "apps", &apps); err != nil {
+		return err
+	}
+	var app map[string]any
+	for _, a := range apps {
+		if a["packageName"] == info.PackageID {
+			app = a
+			break
+		}
+	}
+	if app == nil {
+		app = map[string]any{"packageName": info.PackageID, "added": now}
+		apps = append(apps, app)
+	}
+	setFDroidAppFields(app, params, latest, iconName, now)
+
+	for key, value := range map[string]any{"repo": repo, "requests": requests, "packages": packages, "apps": apps} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		index[key] = data
+	}
+	return nil
+}
+
+// setFDroidAppFields fills an index-v1 app entry from the config and APK.
+// The suggested version and icon follow the newest version in the repo.
+func setFDroidAppFields(app map[string]any, params FDroidRepoParams, latest fdroidPackage, iconName string, now int64) {
+	cfg, info := params.Config, params.APKInfo
+
+	name := cfg.Name
+	if name == "" {
+		name = info.Label
+	}
+	if name == "" {
+		name = info.PackageID
+	}
+	license := cfg.License
+	if license == "" {
+		license = "Unknown"
+	}
+
+	set := func(key, value string) {
+		if value != "" {
+			app[key] = value
+		}
+	}
+	set("name", name)
+	set("summary", cfg.Summary)
+	set("description", cfg.Description)
+	set("license", license)
+	set("webSite", cfg.Website)
+	if cfg.NIP34Repo == nil {
+		set("sourceCode", cfg.Repository)
+	}
+	if _, ok := app["categories"]; !ok {
+		app["categories"] = []string{}
+	}
+	if iconName != "" && latest.VersionCode == info.VersionCode {
+		app["icon"] = iconName
+	}
+	app["lastUpdated"] = now
+	app["suggestedVersionCode"] = strconv.FormatInt(latest.VersionCode, 10)
+	app["suggestedVersionName"] = latest.VersionName
+}
+
+// unmarshalIndexField decodes index[key] into v when present.
+func unmarshalIndexField(index map[string]json.RawMessage, key string, v any) error {
+	raw, ok := index[key]
+	if !ok || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to parse F-Droid index %q: %w", key, err)
+	}
+	return nil
+}
+
+// versionCodeOf returns the versionCode of a raw package entry (0 if unreadable).
+func versionCodeOf(raw json.RawMessage) int64 {
+	var p struct {
+		VersionCode int64 `json:"versionCode"`
+	}
+	json.Unmarshal(raw, &p)
+	return p.VersionCode
+}
+
+// writeFDroidIndex writes index through a temp file so a failed write never
+// leaves a truncated index behind.
+func writeFDroidIndex(path string, index map[string]json.RawMessage) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".index-v1-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write F-Droid index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write F-Droid index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write F-Droid index: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write F-Droid index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write F-Droid index: %w", err)
+	}
+	return nil
+}
+
+// copyFile copies src to dst, replacing dst. Copying a file onto itself is a no-op.
+func copyFile(src, dst string) error {
+	if srcInfo, err := os.Stat(src); err == nil {
+		if dstInfo, err := os.Stat(dst); err == nil && os.SameFile(srcInfo, dstInfo) {
+			return nil
+		}
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,220 @@
+package workflow
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/config"
+)
+
+// testFDroidParams returns params for versionCode of com.example.app, backed by a fake APK file.
+func testFDroidParams(t *testing.T, dir string, versionCode int64, versionName string) FDroidRepoParams {
+	t.Helper()
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := os.WriteFile(apkPath, []byte("apk "+versionName), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return FDroidRepoParams{
+		Dir: dir,
+		APKInfo: &apk.APKInfo{
+			PackageID:       "com.example.app",
+			VersionName:     versionName,
+			VersionCode:     versionCode,
+			MinSDK:          24,
+			TargetSDK:       34,
+			Label:           "Example",
+			Architectures:   []string{"arm64-v8a"},
+			Permissions:     []string{"android.permission.INTERNET"},
+			CertFingerprint: "ab12",
+			FilePath:        apkPath,
+			FileSize:        int64(len("apk " + versionName)),
+			SHA256:          "deadbeef",
+		},
+		Config: &config.Config{Summary: "An example", License: "MIT", Repository: "https://github.com/acme/app"},
+		Icon:   append(append([]byte{}, pngSignature...), "icon"...),
+		Now:    time.UnixMilli(1700000000000 + versionCode),
+	}
+}
+
+// readIndexV1 reads repo/index-v1.json and checks the fields and types the
+// F-Droid client requires of an index-v1 file.
+func readIndexV1(t *testing.T, dir string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "repo", "index-v1.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index map[string]any
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("index-v1.json is not valid JSON: %v", err)
+	}
+
+	expect := func(obj map[string]any, key string, kind reflect.Kind, where string) {
+		t.Helper()
+		v, ok := obj[key]
+		if !ok || reflect.ValueOf(v).Kind() != kind {
+			t.Errorf("%s.%s = %#v, want a %s", where, key, v, kind)
+		}
+	}
+
+	repo, _ := index["repo"].(map[string]any)
+	if repo == nil {
+		t.Fatal("index has no repo object")
+	}
+	expect(repo, "timestamp", reflect.Float64, "repo")
+	expect(repo, "version", reflect.Float64, "repo")
+	expect(repo, "name", reflect.String, "repo")
+	if _, ok := index["requests"].(map[string]any); !ok {
+		t.Error("index has no requests object")
+	}
+
+	apps, _ := index["apps"].([]any)
+	for _, a := range apps {
+		app := a.(map[string]any)
+		for _, key := range []string{"packageName", "suggestedVersionCode", "license"} {
+			expect(app, key, reflect.String, "app")
+		}
+		expect(app, "added", reflect.Float64, "app")
+		expect(app, "lastUpdated", reflect.Float64, "app")
+		expect(app, "categories", reflect.Slice, "app")
+	}
+
+	packages, _ := index["packages"].(map[string]any)
+	if packages == nil {
+		t.Fatal("index has no packages object")
+	}
+	for name, versions := range packages {
+		for _, v := range versions.([]any) {
+			pkg := v.(map[string]any)
+			for _, key := range []string{"apkName", "hash", "hashType", "packageName", "versionName"} {
+				expect(pkg, key, reflect.String, "packages["+name+"]")
+			}
+			for _, key := range []string{"added", "size", "versionCode"} {
+				expect(pkg, key, reflect.Float64, "packages["+name+"]")
+			}
+			if perms, ok := pkg["uses-permission"].([]any); ok {
+				for _, p := range perms {
+					if pair, ok := p.([]any); !ok || len(pair) != 2 {
+						t.Errorf("uses-permission entry %#v, want [name, maxSdkVersion]", p)
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+func TestWriteFDroidRepoCreatesRepo(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fdroid")
+	if err := WriteFDroidRepo(testFDroidParams(t, dir, 3, "1.2.0")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"com.example.app_3.apk", "icons/com.example.app.3.png", "icons-640/com.example.app.3.png"} {
+		if _, err := os.Stat(filepath.Join(dir, "repo", name)); err != nil {
+			t.Errorf("repo/%s missing: %v", name, err)
+		}
+	}
+
+	index := readIndexV1(t, dir)
+	app := index["apps"].([]any)[0].(map[string]any)
+	want := map[string]any{
+		"packageName":          "com.example.app",
+		"name":                 "Example",
+		"summary":              "An example",
+		"license":              "MIT",
+		"sourceCode":           "https://github.com/acme/app",
+		"icon":                 "com.example.app.3.png",
+		"suggestedVersionCode": "3",
+		"suggestedVersionName": "1.2.0",
+	}
+	for key, value := range want {
+		if app[key] != value {
+			t.Errorf("app.%s = %v, want %v", key, app[key], value)
+		}
+	}
+
+	pkg := index["packages"].(map[string]any)["com.example.app"].([]any)[0].(map[string]any)
+	if pkg["apkName"] != "com.example.app_3.apk" || pkg["hashType"] != "sha256" || pkg["signer"] != "ab12" {
+		t.Errorf("package entry = %v", pkg)
+	}
+	if perms := pkg["uses-permission"]; !reflect.DeepEqual(perms, []any{[]any{"android.permission.INTERNET", nil}}) {
+		t.Errorf("uses-permission = %v", perms)
+	}
+}
+
+func TestWriteFDroidRepoUpdatesIndex(t *testing.T) {
+	dir := t.TempDir()
+	existing := `{
+  "repo": {"timestamp": 1, "version": 21, "name": "My Repo", "address": "https://example.com/fdroid/repo"},
+  "requests": {"install": [], "uninstall": []},
+  "apps": [
+    {"packageName": "org.other", "name": "Other", "license": "GPL-3.0-only", "categories": ["Tools"], "added": 5, "lastUpdated": 5, "suggestedVersionCode": "1"},
+    {"packageName": "com.example.app", "categories": ["Internet"], "added": 10, "lastUpdated": 10, "suggestedVersionCode": "1", "license": "MIT"}
+  ],
+  "packages": {
+    "org.other": [{"added": 5, "apkName": "org.other_1.apk", "hash": "aa", "hashType": "sha256", "packageName": "org.other", "size": 1, "versionCode": 1, "versionName": "1.0"}],
+    "com.example.app": [{"added": 10, "apkName": "com.example.app_1.apk", "hash": "bb", "hashType": "sha256", "packageName": "com.example.app", "size": 1, "versionCode": 1, "versionName": "0.9", "srclibs": ["kept"]}]
+  },
+  "custom": true
+}`
+	if err := os.MkdirAll(filepath.Join(dir, "repo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "repo", "index-v1.json"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new version, then the same version again, then an older one
+	if err := WriteFDroidRepo(testFDroidParams(t, dir, 3, "1.2.0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFDroidRepo(testFDroidParams(t, dir, 3, "1.2.0")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFDroidRepo(testFDroidParams(t, dir, 2, "1.1.0")); err != nil {
+		t.Fatal(err)
+	}
+
+	index := readIndexV1(t, dir)
+	if index["custom"] != true {
+		t.Error("unknown top-level field was dropped")
+	}
+	repo := index["repo"].(map[string]any)
+	if repo["name"] != "My Repo" || repo["address"] != "https://example.com/fdroid/repo" {
+		t.Errorf("repo = %v, want name and address kept", repo)
+	}
+
+	apps := index["apps"].([]any)
+	if len(apps) != 2 || apps[0].(map[string]any)["packageName"] != "org.other" {
+		t.Fatalf("apps = %v, want org.other kept before com.example.app", apps)
+	}
+	app := apps[1].(map[string]any)
+	if app["added"] != float64(10) || !reflect.DeepEqual(app["categories"], []any{"Internet"}) {
+		t.Errorf("app = %v, want added and categories kept", app)
+	}
+	if app["suggestedVersionCode"] != "3" || app["icon"] != "com.example.app.3.png" {
+		t.Errorf("app = %v, want the newest version suggested", app)
+	}
+
+	packages := index["packages"].(map[string]any)
+	if other := packages["org.other"].([]any); len(other) != 1 {
+		t.Errorf("org.other packages = %v, want kept", other)
+	}
+	var codes []float64
+	for _, v := range packages["com.example.app"].([]any) {
+		codes = append(codes, v.(map[string]any)["versionCode"].(float64))
+	}
+	if !reflect.DeepEqual(codes, []float64{3, 2, 1}) {
+		t.Errorf("versionCodes = %v, want [3 2 1]", codes)
+	}
+	oldest := packages["com.example.app"].([]any)[2].(map[string]any)
+	if !reflect.DeepEqual(oldest["srclibs"], []any{"kept"}) {
+		t.Errorf("old version entry = %v, want it unchanged", oldest)
+	}
+}
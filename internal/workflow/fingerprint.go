@@ -0,0 +1,177 @@
+package workflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/source"
+	"gopkg.in/yaml.v3"
+)
+
+// maxFingerprints is how many publish fingerprints are kept; the oldest are dropped.
+const maxFingerprints = 100
+
+// Fingerprint records a successful publish. A later run with the same inputs
+// and the same release asset has nothing new to publish.
+type Fingerprint struct {
+	Inputs      string    `json:"inputs"`     // hash of config, publish flags, signer and targets
+	Asset       string    `json:"asset"`      // release asset identity (see assetIdentity)
+	APKSHA256   string    `json:"apk_sha256"` // hash of the published APK
+	PackageID   string    `json:"package_id"`
+	Version     string    `json:"version"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// fingerprintPath returns the fingerprint file location.
+func fingerprintPath() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	return filepath.Join(cacheDir, "zsp", "fingerprints.json")
+}
+
+// loadFingerprints returns the recorded fingerprints keyed by inputs hash.
+// A missing file yields none.
+func loadFingerprints(path string) (map[string]Fingerprint, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]Fingerprint{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read publish fingerprints: %w", err)
+	}
+	fingerprints := map[string]Fingerprint{}
+	if err := json.Unmarshal(data, &fingerprints); err != nil {
+		return nil, fmt.Errorf("failed to parse publish fingerprints: %w", err)
+	}
+	return fingerprints, nil
+}
+
+// saveFingerprint records fp, replacing any fingerprint with the same inputs and
+// keeping at most maxFingerprints.
+func saveFingerprint(path string, fp Fingerprint) error {
+	fingerprints, err := loadFingerprints(path)
+	if err != nil {
+		fingerprints = map[string]Fingerprint{} // start over rather than never recording again
+	}
+	fingerprints[fp.Inputs] = fp
+
+	if len(fingerprints) > maxFingerprints {
+		all := make([]Fingerprint, 0, len(fingerprints))
+		for _, f := range fingerprints {
+			all = append(all, f)
+		}
+		sort.Slice(all, func(i, j int) bool { return all[i].PublishedAt.After(all[j].PublishedAt) })
+		for _, f := range all[maxFingerprints:] {
+			delete(fingerprints, f.Inputs)
+		}
+	}
+
+	data, err := json.MarshalIndent(fingerprints, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// fingerprintInputs hashes everything besides the APK that shapes the published
+// events: the config, the release metadata resolved from the source, the
+// publish flags that change event content or who signs them, the signer
+// identity, and the relays and Blossom server published to.
+func fingerprintInputs(cfg *config.Config, release *source.Release, opts *cli.PublishOptions, signer string, relays []string, blossomURL string) (string, error) {
+	cfgYAML, err := yaml.Marshal(cfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash config: %w", err)
+	}
+	releaseJSON, err := releaseMetadata(release)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash release metadata: %w", err)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{
+		string(cfgYAML),
+		cfg.BaseDir,
+		opts.Channel,
+		strconv.FormatBool(opts.ChannelSuffix),
+		opts.Commit,
+		strings.Join(opts.Platforms, ","),
+		opts.PublishedAt,
+		opts.OverwriteApp,
+		strings.Join(opts.Metadata, ","),
+		opts.IconDensity,
+		strconv.FormatBool(opts.SkipMetadata),
+		strconv.FormatBool(opts.SkipAppEvent),
+		strconv.FormatBool(opts.AppCreatedAtRelease),
+		strconv.FormatBool(opts.NoCompress),
+		strconv.FormatBool(opts.NoBlurhash),
+		string(releaseJSON),
+		opts.Variant,
+		opts.Delegation,
+		opts.AddToSet,
+		strconv.FormatBool(opts.EphemeralKey),
+		signer,
+		strings.Join(relays, ","),
+		blossomURL,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// releaseMetadata encodes the release's resolved metadata (version, tag, notes,
+// dates and any repository metadata) without its assets, which assetIdentity covers.
+func releaseMetadata(release *source.Release) ([]byte, error) {
+	if release == nil {
+		return nil, nil
+	}
+	meta := *release
+	meta.Assets = nil
+	return json.Marshal(meta)
+}
+
+// signerIdentity returns a stable identity for a SIGN_WITH value without
+// keeping secrets: the npub for nsec/npub, and the scheme and remote key for bunkers.
+func signerIdentity(signWith string) string {
+	if npub := config.ResolvePubkeyFromSignWith(signWith); npub != "" {
+		return npub
+	}
+	if i := strings.IndexAny(signWith, "?#"); i >= 0 {
+		return signWith[:i]
+	}
+	return signWith
+}
+
+// assetIdentity identifies a release asset without downloading it: the download
+// URL and size for remote assets, the file hash for local ones.
+func assetIdentity(asset *source.Asset) (string, error) {
+	if asset.LocalPath == "" {
+		return fmt.Sprintf("%s|%d", asset.URL, asset.Size), nil
+	}
+	f, err := os.Open(asset.LocalPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,153 @@
+package workflow
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+func TestCheckFingerprintSkipsUnchangedRerun(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("SIGN_WITH", "npub1fingerprinttest")
+
+	newPublisher := func(opts *cli.Options, asset *source.Asset) *Publisher {
+		return &Publisher{
+			opts:          opts,
+			cfg:           &config.Config{Repository: "https://github.com/acme/app", Name: "Acme"},
+			publisher:     nostr.NewPublisher([]string{"wss://relay.example.com"}),
+			blossomURL:    "https://blossom.example.com",
+			selectedAsset: asset,
+			apkInfo:       &apk.APKInfo{PackageID: "com.acme.app", VersionName: "1.0.0", SHA256: "abc"},
+		}
+	}
+	quiet := &cli.Options{Publish: cli.PublishOptions{Quiet: true}}
+	asset := &source.Asset{URL: "https://github.com/acme/app/releases/download/v1.0.0/app.apk", Size: 1024}
+
+	// First run: nothing recorded yet
+	first := newPublisher(quiet, asset)
+	if err := first.checkFingerprint(); err != nil {
+		t.Fatalf("first run: checkFingerprint() = %v, want nil", err)
+	}
+
+	// A run some relays rejected records nothing, so the retry publishes
+	first.recordFingerprint()
+	if err := newPublisher(quiet, asset).checkFingerprint(); err != nil {
+		t.Fatalf("retry after relay failures: checkFingerprint() = %v, want nil", err)
+	}
+	first.published = true
+	first.recordFingerprint()
+
+	// Identical re-run stops early
+	if err := newPublisher(quiet, asset).checkFingerprint(); !errors.Is(err, ErrNothingToDo) {
+		t.Fatalf("identical re-run: checkFingerprint() = %v, want ErrNothingToDo", err)
+	}
+
+	// A new release asset publishes
+	newAsset := &source.Asset{URL: "https://github.com/acme/app/releases/download/v1.1.0/app.apk", Size: 1024}
+	if err := newPublisher(quiet, newAsset).checkFingerprint(); err != nil {
+		t.Errorf("new asset: checkFingerprint() = %v, want nil", err)
+	}
+
+	// A changed publish flag publishes
+	beta := &cli.Options{Publish: cli.PublishOptions{Quiet: true, Channel: "beta"}}
+	if err := newPublisher(beta, asset).checkFingerprint(); err != nil {
+		t.Errorf("changed channel: checkFingerprint() = %v, want nil", err)
+	}
+
+	// A changed signing option publishes
+	delegated := &cli.Options{Publish: cli.PublishOptions{Quiet: true, AddToSet: "naddr1set"}}
+	if err := newPublisher(delegated, asset).checkFingerprint(); err != nil {
+		t.Errorf("changed --add-to-set: checkFingerprint() = %v, want nil", err)
+	}
+
+	// Edited release notes on the same asset publish
+	edited := newPublisher(quiet, asset)
+	edited.release = &source.Release{Version: "1.0.0", Changelog: "Fixed the changelog"}
+	if err := edited.checkFingerprint(); err != nil {
+		t.Errorf("changed release notes: checkFingerprint() = %v, want nil", err)
+	}
+
+	// --overwrite-release bypasses the check
+	overwrite := &cli.Options{Publish: cli.PublishOptions{Quiet: true, OverwriteRelease: true}}
+	if err := newPublisher(overwrite, asset).checkFingerprint(); err != nil {
+		t.Errorf("--overwrite-release: checkFingerprint() = %v, want nil", err)
+	}
+
+	// --ephemeral-key test publishes neither check nor record the SIGN_WITH fingerprint
+	ephemeral := newPublisher(&cli.Options{Publish: cli.PublishOptions{Quiet: true, EphemeralKey: true}}, asset)
+	if err := ephemeral.checkFingerprint(); err != nil || ephemeral.fingerprint != nil {
+		t.Errorf("--ephemeral-key: checkFingerprint() = %v, fingerprint %v, want neither", err, ephemeral.fingerprint)
+	}
+
+	// A different signer publishes
+	t.Setenv("SIGN_WITH", "npub1othersigner")
+	if err := newPublisher(quiet, asset).checkFingerprint(); err != nil {
+		t.Errorf("different signer: checkFingerprint() = %v, want nil", err)
+	}
+}
+
+func TestSaveFingerprintKeepsNewest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.json")
+	base := time.Now()
+	for i := 0; i < maxFingerprints+5; i++ {
+		fp := Fingerprint{Inputs: string(rune('a'+i%26)) + string(rune('0'+i/26)), PublishedAt: base.Add(time.Duration(i) * time.Minute)}
+		if err := saveFingerprint(path, fp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fingerprints, err := loadFingerprints(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fingerprints) != maxFingerprints {
+		t.Fatalf("kept %d fingerprints, want %d", len(fingerprints), maxFingerprints)
+	}
+	if _, ok := fingerprints["a0"]; ok {
+		t.Error("oldest fingerprint should have been dropped")
+	}
+}
+
+func TestSignerIdentity(t *testing.T) {
+	tests := map[string]string{
+		"npub1abc":                               "npub1abc",
+		"bunker://abcdef?relay=wss://r&secret=s": "bunker://abcdef",
+		"browser":                                "browser",
+	}
+	for in, want := range tests {
+		if got := signerIdentity(in); got != want {
+			t.Errorf("signerIdentity(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAssetIdentityLocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.apk")
+	if err := os.WriteFile(path, []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := assetIdentity(&source.Asset{LocalPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same path, rebuilt APK: the identity follows the content
+	if err := os.WriteFile(path, []byte("v2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	after, err := assetIdentity(&source.Asset{LocalPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Error("asset identity should change when the local APK changes")
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 
+	gonostr "github.com/nbd-wtf/go-nostr"
 	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
 	"github.com/zapstore/zsp/internal/nostr"
 	"github.com/zapstore/zsp/internal/picker"
 	"github.com/zapstore/zsp/internal/source"
@@ -67,7 +70,11 @@ func selectAPKInteractive(ranked []picker.ScoredAsset) (*source.Asset, error) {
 			sizeMB := float64(sa.Asset.Size) / (1024 * 1024)
 			sizeStr = fmt.Sprintf(" (%.1f MB)", sizeMB)
 		}
-		options[i] = fmt.Sprintf("%s%s", sa.Asset.Name, sizeStr)
+		labelStr := ""
+		if sa.Asset.Label != "" && sa.Asset.Label != sa.Asset.Name {
+			labelStr = " " + ui.Dim("["+sa.Asset.Label+"]")
+		}
+		options[i] = fmt.Sprintf("%s%s%s", sa.Asset.Name, labelStr, sizeStr)
 	}
 
 	idx, err := ui.SelectOption("", options, 0)
@@ -78,6 +85,44 @@ func selectAPKInteractive(ranked []picker.ScoredAsset) (*source.Asset, error) {
 	return ranked[idx].Asset, nil
 }
 
+// showAppMetadataDiff prints field-level changes between the existing and new kind 32267
+// events before signing. Shown interactively and in verbose mode.
+func showAppMetadataDiff(opts *cli.Options, existing, updated *gonostr.Event) {
+	if existing == nil || updated == nil {
+		return
+	}
+	if !opts.IsInteractive() && !opts.Global.Verbose {
+		return
+	}
+
+	changes := nostr.DiffAppMetadata(existing, updated)
+	if len(changes) == 0 {
+		fmt.Printf("  %s\n", ui.Dim("App metadata unchanged from published version"))
+		return
+	}
+
+	ui.PrintSectionHeader("App Metadata Changes")
+	for _, c := range changes {
+		fmt.Printf("  %s\n", ui.Bold(c.Field))
+		if c.Old != "" {
+			fmt.Printf("    - %s\n", truncateDiffValue(c.Old))
+		}
+		if c.New != "" {
+			fmt.Printf("    + %s\n", truncateDiffValue(c.New))
+		}
+	}
+	fmt.Println()
+}
+
+// truncateDiffValue shortens long values (e.g. descriptions) to a single display line.
+func truncateDiffValue(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if r := []rune(s); len(r) > 100 {
+		return string(r[:97]) + "..."
+	}
+	return s
+}
+
 // zapstoreRelayHost is the hostname of the Zapstore relay used to detect Zapstore publishes.
 const zapstoreRelayHost = "relay.zapstore.dev"
 
@@ -91,6 +136,96 @@ func isPublishingToZapstore(relayURLs []string) bool {
 	return false
 }
 
+// verifyRelayURL returns the relay events are read back from after publishing:
+// the Zapstore relay if it is among relayURLs, otherwise the first relay.
+func verifyRelayURL(relayURLs []string) string {
+	for _, u := range relayURLs {
+		if strings.Contains(u, zapstoreRelayHost) {
+			return u
+		}
+	}
+	if len(relayURLs) == 0 {
+		return ""
+	}
+	return relayURLs[0]
+}
+
+// releaseOptions describes releases for the --pick-release menu: tag, date and
+// APK count.
+func releaseOptions(releases []*source.Release) []string {
+	options := make([]string, len(releases))
+	for i, r := range releases {
+		tag := r.TagName
+		if tag == "" {
+			tag = r.Version
+		}
+		date := "unknown date"
+		if !r.CreatedAt.IsZero() {
+			date = r.CreatedAt.Format("2006-01-02")
+		}
+		apks := len(picker.FilterAPKs(r.Assets))
+		option := fmt.Sprintf("%s  %s  %d APK", tag, date, apks)
+		if apks != 1 {
+			option += "s"
+		}
+		if r.PreRelease {
+			option += "  (pre-release)"
+		}
+		options[i] = option
+	}
+	return options
+}
+
+// blossomPublicURL returns blossom_public_url, or "" in --dev mode, where blobs
+// go to a local Blossom server that no public URL fronts.
+func blossomPublicURL(cfg *config.Config, opts *cli.Options) string {
+	if opts != nil && opts.Publish.Dev {
+		return ""
+	}
+	return cfg.BlossomPublicURL
+}
+
+// ciBuild returns the CI run to name in a build tag on every event with
+// embed_build_info, or nil when it is off or no CI run is detected.
+func ciBuild(cfg *config.Config) *nostr.CIBuild {
+	if !cfg.EmbedBuildInfo {
+		return nil
+	}
+	return nostr.DetectCIBuild(os.Getenv)
+}
+
+// appendReleaseLink adds link to the end of the release notes (append_release_link),
+// unless it is empty or the notes already contain it.
+func appendReleaseLink(notes, link string) string {
+	if link == "" || strings.Contains(notes, link) {
+		return notes
+	}
+	if notes = strings.TrimRight(notes, "\n"); notes == "" {
+		return link
+	}
+	return notes + "\n\n" + link
+}
+
+// eventsBelowQuorum returns the event types (sorted) accepted by fewer than
+// required relays, or by fewer than all of them when relay_routes sent the
+// event to fewer relays. Duplicates count as accepted.
+func eventsBelowQuorum(results map[string][]nostr.PublishResult, required int) []string {
+	var failed []string
+	for eventType, eventResults := range results {
+		accepted := 0
+		for _, r := range eventResults {
+			if r.Success {
+				accepted++
+			}
+		}
+		if accepted == 0 || accepted < min(required, len(eventResults)) {
+			failed = append(failed, eventType)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
 // confirmPublish shows a pre-publish summary and asks for confirmation.
 func confirmPublish(events *nostr.EventSet, relayURLs []string, apkSHA256 string, isClosedSource bool) (bool, error) {
 	packageID := ""
@@ -109,11 +244,14 @@ func confirmPublish(events *nostr.EventSet, relayURLs []string, apkSHA256 string
 
 	ui.PrintSectionHeader("Ready to Publish")
 	fmt.Printf("  App: %s v%s\n", packageID, version)
+	kinds := "Kind 30063 (Release) + Kind 3063 (Asset)"
 	if events.AppMetadata != nil {
-		fmt.Printf("  Events: Kind 32267 (App) + Kind 30063 (Release) + Kind 3063 (Asset)\n")
-	} else {
-		fmt.Printf("  Events: Kind 30063 (Release) + Kind 3063 (Asset)\n")
+		kinds = "Kind 32267 (App) + " + kinds
+	}
+	if events.ReleaseNotes != nil {
+		kinds += " + Kind 30023 (Release notes)"
 	}
+	fmt.Printf("  Events: %s\n", kinds)
 	fmt.Printf("  Target: %s\n", strings.Join(relayURLs, ", "))
 	fmt.Printf("  APK SHA-256: %s\n", ui.Bold(apkSHA256))
 	if isClosedSource {
@@ -182,6 +320,12 @@ func previewEventsJSON(events *nostr.EventSet) {
 		printColorizedJSON(asset)
 		fmt.Println()
 	}
+
+	if events.ReleaseNotes != nil {
+		fmt.Printf("  %s\n", ui.Bold("Kind 30023 (Release notes):"))
+		printColorizedJSON(events.ReleaseNotes)
+		fmt.Println()
+	}
 }
 
 // OutputEvents prints events as formatted, colorized JSON.
@@ -213,6 +357,12 @@ func OutputEvents(events *nostr.EventSet) {
 		printColorizedJSON(asset)
 		fmt.Println()
 	}
+
+	if events.ReleaseNotes != nil {
+		fmt.Printf("%s\n", ui.Bold("Kind 30023 (Release notes):"))
+		printColorizedJSON(events.ReleaseNotes)
+		fmt.Println()
+	}
 }
 
 // OutputEventsToStdout outputs events as newline-delimited JSON to stdout.
@@ -227,6 +377,9 @@ func OutputEventsToStdout(events *nostr.EventSet) {
 	if events.IdentityProof != nil {
 		outputEventLine(events.IdentityProof)
 	}
+	if events.ReleaseNotes != nil {
+		outputEventLine(events.ReleaseNotes)
+	}
 }
 
 // outputEventLine outputs a single event as JSON on one line to stdout.
@@ -272,6 +425,67 @@ func OutputUploadManifest(entries []UploadManifestEntry, blossomServer string, o
 	}
 }
 
+// WriteUploadManifestJSON writes the manifest entries as a JSON array to path, for
+// tooling that uploads the blobs itself. A path of "-" writes the array to stdout
+// on a single line, after any event lines already printed there.
+func WriteUploadManifestJSON(entries []UploadManifestEntry, path string) error {
+	if entries == nil {
+		entries = []UploadManifestEntry{}
+	}
+	if path == "-" {
+		data, err := json.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// WriteBlossomAuthEvents writes unsigned Blossom upload auth events as JSONL to
+// path, one per manifest entry and in the same order. A path of "-" writes them
+// to stdout, after any event lines already printed there.
+func WriteBlossomAuthEvents(events []*gonostr.Event, path string) error {
+	var b strings.Builder
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	if path == "-" {
+		fmt.Print(b.String())
+		return nil
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// OutputUploadRecipe prints to stderr a curl command per manifest entry that
+// uploads the file with its auth event once signed, taking line N of the signed
+// JSONL file for entry N.
+func OutputUploadRecipe(entries []UploadManifestEntry, blossomServer, authPath string) {
+	if len(entries) == 0 {
+		return
+	}
+	const signedPath = "signed-auth.jsonl"
+	fmt.Fprintf(os.Stderr, "Sign the auth events in %s without reordering them, save them as %s, then upload:\n", authPath, signedPath)
+	fmt.Fprintln(os.Stderr)
+	for i, e := range entries {
+		fmt.Fprintf(os.Stderr, "  curl -X PUT -T '%s' -H 'X-SHA-256: %s' \\\n", e.FilePath, e.SHA256)
+		fmt.Fprintf(os.Stderr, "    -H \"Authorization: Nostr $(sed -n %dp %s | tr -d '\\n' | base64 | tr -d '\\n')\" \\\n", i+1, signedPath)
+		fmt.Fprintf(os.Stderr, "    %s/upload\n", blossomServer)
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
 // printColorizedJSON prints a value as colorized JSON.
 func printColorizedJSON(v any) {
 	data, err := json.MarshalIndent(v, "", "  ")
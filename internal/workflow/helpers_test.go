@@ -0,0 +1,158 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+func TestEventsBelowQuorum(t *testing.T) {
+	failed := nostr.PublishResult{Success: false, Error: errors.New("timeout")}
+	ok := nostr.PublishResult{Success: true}
+	dup := nostr.PublishResult{Success: true, IsDuplicate: true}
+
+	results := map[string][]nostr.PublishResult{
+		"Release":     {ok, ok, failed},
+		"Software":    {ok, dup, failed},
+		"AppMetadata": {ok, failed, failed},
+		"Identity":    {dup}, // Routed to a single relay by relay_routes
+	}
+
+	tests := []struct {
+		required int
+		want     []string
+	}{
+		{1, nil},
+		{2, []string{"AppMetadata"}},
+		{3, []string{"AppMetadata", "Release", "Software"}},
+	}
+
+	for _, tt := range tests {
+		if got := eventsBelowQuorum(results, tt.required); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("eventsBelowQuorum(required=%d) = %v, want %v", tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestReleaseOptions(t *testing.T) {
+	releases := []*source.Release{
+		{TagName: "v1.2.0", CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), PreRelease: true,
+			Assets: []*source.Asset{{Name: "app-arm64.apk"}, {Name: "app-x86.apk"}, {Name: "notes.txt"}}},
+		{Version: "1.1.0", Assets: []*source.Asset{{Name: "app.apk"}}},
+	}
+	got := releaseOptions(releases)
+	want := []string{"v1.2.0  2026-03-01  2 APKs  (pre-release)", "1.1.0  unknown date  1 APK"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("releaseOptions() = %q, want %q", got, want)
+	}
+}
+
+func TestAppendReleaseLink(t *testing.T) {
+	const link = "https://github.com/acme/app/discussions/7"
+	tests := []struct {
+		notes, link, want string
+	}{
+		{"Fixes\n", link, "Fixes\n\n" + link},
+		{"", link, link},
+		{"Fixes", "", "Fixes"},
+		{"Discuss: " + link, link, "Discuss: " + link},
+	}
+	for _, tt := range tests {
+		if got := appendReleaseLink(tt.notes, tt.link); got != tt.want {
+			t.Errorf("appendReleaseLink(%q, %q) = %q, want %q", tt.notes, tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyRelayURL(t *testing.T) {
+	tests := []struct {
+		relays []string
+		want   string
+	}{
+		{[]string{"wss://relay.example.com", "wss://relay.zapstore.dev"}, "wss://relay.zapstore.dev"},
+		{[]string{"wss://relay.example.com", "wss://nos.lol"}, "wss://relay.example.com"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := verifyRelayURL(tt.relays); got != tt.want {
+			t.Errorf("verifyRelayURL(%v) = %q, want %q", tt.relays, got, tt.want)
+		}
+	}
+}
+
+func TestWriteUploadManifestJSON(t *testing.T) {
+	entries := []UploadManifestEntry{
+		{Description: "APK", FilePath: "/tmp/app.apk", SHA256: "aa", BlossomURL: "https://cdn.example.com/aa"},
+		{Description: "Screenshot 1", FilePath: "/tmp/s1.png", SHA256: "bb", BlossomURL: "https://cdn.example.com/bb"},
+	}
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := WriteUploadManifestJSON(entries, path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]string
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("manifest is not a JSON array: %v\n%s", err, data)
+	}
+	want := []map[string]string{
+		{"description": "APK", "file_path": "/tmp/app.apk", "sha256": "aa", "blossom_url": "https://cdn.example.com/aa"},
+		{"description": "Screenshot 1", "file_path": "/tmp/s1.png", "sha256": "bb", "blossom_url": "https://cdn.example.com/bb"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("manifest = %v, want %v", got, want)
+	}
+}
+
+func TestWriteBlossomAuthEvents(t *testing.T) {
+	pubkey := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	expiration := time.Unix(1700000000, 0)
+	events := []*gonostr.Event{
+		nostr.BuildBlossomAuthEvent("aa", pubkey, expiration),
+		nostr.BuildBlossomAuthEvent("bb", pubkey, expiration),
+	}
+	path := filepath.Join(t.TempDir(), "auth.jsonl")
+	if err := WriteBlossomAuthEvents(events, path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per event:\n%s", len(lines), data)
+	}
+	for i, line := range lines {
+		var event gonostr.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %d is not an event: %v", i+1, err)
+		}
+		if x := event.Tags.GetFirst([]string{"x"}); x == nil || (*x)[1] != events[i].Tags[1][1] {
+			t.Errorf("line %d x tag = %v, want entries in manifest order", i+1, x)
+		}
+		if event.Sig != "" {
+			t.Errorf("line %d is signed", i+1)
+		}
+	}
+}
+
+func TestRelayHintFollowsResolvedRelays(t *testing.T) {
+	p := &Publisher{publisher: nostr.NewPublisher([]string{"wss://write.example", "wss://relay.zapstore.dev"})}
+	if got := p.getRelayHint(); got != "wss://write.example" {
+		t.Errorf("getRelayHint() = %q, want the first publish relay", got)
+	}
+}
@@ -0,0 +1,137 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/history"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// ShowHistory lists recorded releases, or with --interactive lets the user pick one
+// to view or re-broadcast.
+func ShowHistory(ctx context.Context, opts *cli.Options) error {
+	entries, err := history.Load()
+	if err != nil {
+		return err
+	}
+	if len(opts.Args) > 0 {
+		var filtered []history.Entry
+		for _, e := range entries {
+			if e.PackageID == opts.Args[0] {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+
+	if opts.Global.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "No published releases recorded in %s\n", history.Path())
+		return nil
+	}
+	if !opts.History.Interactive {
+		for _, e := range entries {
+			fmt.Println(formatHistoryEntry(e))
+		}
+		return nil
+	}
+
+	labels := make([]string, len(entries))
+	for i, e := range entries {
+		labels[i] = formatHistoryEntry(e)
+	}
+	idx, err := ui.SelectOption("Select a release", labels, 0)
+	if err != nil {
+		return err
+	}
+	entry := entries[idx]
+	events := historyEventSet(entry)
+
+	actions := []string{
+		"View events",
+		fmt.Sprintf("Re-broadcast to %s", strings.Join(entry.Relays, ", ")),
+		"Cancel",
+	}
+	action, err := ui.SelectOption(fmt.Sprintf("%s v%s", entry.PackageID, entry.Version), actions, 0)
+	if err != nil {
+		return err
+	}
+	switch action {
+	case 0:
+		OutputEvents(events)
+	case 1:
+		return rebroadcast(ctx, entry, events)
+	}
+	return nil
+}
+
+// formatHistoryEntry renders one history line: package, version, date and relays.
+func formatHistoryEntry(e history.Entry) string {
+	return fmt.Sprintf("%-40s %-16s %s  %s", e.PackageID, e.Version,
+		e.PublishedAt.Local().Format("2006-01-02 15:04"), strings.Join(e.Relays, ", "))
+}
+
+// historyEventSet groups a history entry's signed events by kind.
+func historyEventSet(e history.Entry) *nostr.EventSet {
+	events := &nostr.EventSet{}
+	for _, event := range e.Events {
+		switch event.Kind {
+		case nostr.KindAppMetadata:
+			events.AppMetadata = event
+		case nostr.KindRelease:
+			events.Release = event
+		case nostr.KindLongForm:
+			events.ReleaseNotes = event
+		default:
+			events.SoftwareAssets = append(events.SoftwareAssets, event)
+		}
+	}
+	return events
+}
+
+// rebroadcast publishes a history entry's signed events again to the relays it was published to.
+func rebroadcast(ctx context.Context, entry history.Entry, events *nostr.EventSet) error {
+	if events.Release == nil {
+		return fmt.Errorf("history entry for %s v%s has no release event", entry.PackageID, entry.Version)
+	}
+	confirmed, err := ui.Confirm(fmt.Sprintf("Publish the stored events for %s v%s again?", entry.PackageID, entry.Version), true)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Println("  Aborted. No events were published.")
+		return nil
+	}
+
+	spinner := ui.NewSpinner(fmt.Sprintf("Publishing to %d relays...", len(entry.Relays)))
+	spinner.Start()
+	results, err := nostr.NewPublisher(entry.Relays).PublishEventSet(ctx, events)
+	if err != nil {
+		spinner.StopWithError("Failed to publish")
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+
+	if failures := nostr.RelayFailureSummaries(results); len(failures) > 0 {
+		spinner.StopWithWarning("Re-broadcast with some failures")
+		for _, f := range failures {
+			fmt.Println("    " + f)
+		}
+		return nil
+	}
+	spinner.StopWithSuccess("Re-broadcast successfully")
+	return nil
+}
@@ -0,0 +1,26 @@
+package workflow
+
+import (
+	"testing"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/history"
+	"github.com/zapstore/zsp/internal/nostr"
+)
+
+func TestHistoryEventSet(t *testing.T) {
+	entry := history.Entry{Events: []*gonostr.Event{
+		{Kind: nostr.KindAppMetadata},
+		{Kind: nostr.KindRelease},
+		{Kind: nostr.KindLongForm},
+		{Kind: nostr.KindSoftwareAsset},
+		{Kind: nostr.KindSoftwareAsset},
+	}}
+	events := historyEventSet(entry)
+	if events.AppMetadata == nil || events.Release == nil || events.ReleaseNotes == nil {
+		t.Fatalf("historyEventSet() = %+v, want app, release and notes events", events)
+	}
+	if len(events.SoftwareAssets) != 2 {
+		t.Errorf("historyEventSet() assets = %d, want 2", len(events.SoftwareAssets))
+	}
+}
@@ -0,0 +1,195 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/media"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+func testIconPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessIcon(t *testing.T) {
+	newPublisher := func(cfg *config.Config, apkIcon []byte) *Publisher {
+		return &Publisher{
+			opts:    &cli.Options{Publish: cli.PublishOptions{Quiet: true}, Global: cli.GlobalOptions{JSON: true}},
+			cfg:     cfg,
+			apkInfo: &apk.APKInfo{Icon: apkIcon},
+		}
+	}
+	writeIcon := func(t *testing.T, width, height int) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "icon.png"), testIconPNG(t, width, height), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	t.Run("non-square local icon is rejected", func(t *testing.T) {
+		p := newPublisher(&config.Config{Icon: "icon.png", BaseDir: writeIcon(t, 512, 256)}, nil)
+		if err := p.processIcon(); !errors.Is(err, media.ErrIconNotSquare) {
+			t.Errorf("processIcon() = %v, want ErrIconNotSquare", err)
+		}
+	})
+
+	t.Run("non-square local icon is padded with icon_autofix", func(t *testing.T) {
+		p := newPublisher(&config.Config{Icon: "icon.png", BaseDir: writeIcon(t, 512, 256), IconAutofix: true}, nil)
+		if err := p.processIcon(); err != nil {
+			t.Fatalf("processIcon() = %v", err)
+		}
+		if p.preDownloaded == nil || p.preDownloaded.Icon == nil {
+			t.Fatal("padded icon not kept for upload")
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(p.preDownloaded.Icon.Data))
+		if err != nil || cfg.Width != 512 || cfg.Height != 512 {
+			t.Errorf("padded icon = %dx%d (%v), want 512x512", cfg.Width, cfg.Height, err)
+		}
+	})
+
+	t.Run("small icon is rejected", func(t *testing.T) {
+		p := newPublisher(&config.Config{}, testIconPNG(t, 48, 48))
+		if err := p.processIcon(); !errors.Is(err, media.ErrIconTooSmall) {
+			t.Errorf("processIcon() = %v, want ErrIconTooSmall", err)
+		}
+	})
+
+	t.Run("small icon only warns with icon_autofix", func(t *testing.T) {
+		small := testIconPNG(t, 48, 48)
+		p := newPublisher(&config.Config{IconAutofix: true}, small)
+		if err := p.processIcon(); err != nil {
+			t.Errorf("processIcon() = %v, want a warning only", err)
+		}
+		if !bytes.Equal(p.apkInfo.Icon, small) {
+			t.Error("small icon was modified, want it left as is")
+		}
+	})
+
+	t.Run("icon_min_size lowers the limit", func(t *testing.T) {
+		p := newPublisher(&config.Config{IconMinSize: 48}, testIconPNG(t, 48, 48))
+		if err := p.processIcon(); err != nil {
+			t.Errorf("processIcon() = %v", err)
+		}
+	})
+
+	writeSVG := func(t *testing.T, svg string) string {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "icon.svg"), []byte(svg), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	t.Run("SVG icon is rasterized with icon_rasterize", func(t *testing.T) {
+		dir := writeSVG(t, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><circle cx="12" cy="12" r="10" fill="#0a0"/></svg>`)
+		p := newPublisher(&config.Config{Icon: "icon.svg", BaseDir: dir, IconRasterize: true, IconRasterSize: 256}, nil)
+		if err := p.processIcon(); err != nil {
+			t.Fatalf("processIcon() = %v", err)
+		}
+		if p.preDownloaded == nil || p.preDownloaded.Icon == nil || p.preDownloaded.Icon.MimeType != "image/png" {
+			t.Fatal("rasterized PNG not kept for upload")
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(p.preDownloaded.Icon.Data))
+		if err != nil || cfg.Width != 256 || cfg.Height != 256 {
+			t.Errorf("rasterized icon = %dx%d (%v), want 256x256", cfg.Width, cfg.Height, err)
+		}
+	})
+
+	t.Run("SVG icon that fails to render is kept", func(t *testing.T) {
+		dir := writeSVG(t, `<svg xmlns="http://www.w3.org/2000/svg"><circle r="10"/></svg>`)
+		p := newPublisher(&config.Config{Icon: "icon.svg", BaseDir: dir, IconRasterize: true}, nil)
+		if err := p.processIcon(); err != nil {
+			t.Fatalf("processIcon() = %v, want a warning only", err)
+		}
+		if p.preDownloaded != nil {
+			t.Error("icon replaced, want the SVG uploaded as is")
+		}
+	})
+
+	t.Run("non-square APK icon is padded with icon_autofix", func(t *testing.T) {
+		p := newPublisher(&config.Config{IconAutofix: true}, testIconPNG(t, 192, 256))
+		if err := p.processIcon(); err != nil {
+			t.Fatalf("processIcon() = %v", err)
+		}
+		if err := media.CheckIcon(p.apkInfo.Icon, media.IconMinSize); err != nil {
+			t.Errorf("APK icon after autofix: %v", err)
+		}
+	})
+}
+
+func TestResolveMediaRefs(t *testing.T) {
+	iconPNG := testIconPNG(t, 256, 256)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/owner/app/raw/images/icon.png" || r.URL.Query().Get("ref") != "v1.0" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(iconPNG)
+	}))
+	defer server.Close()
+
+	newPublisher := func(cfg *config.Config, apkIcon []byte) *Publisher {
+		cfg.ReleaseSource = &config.ReleaseSource{URL: server.URL + "/owner/app", Type: "gitea"}
+		return &Publisher{
+			opts:    &cli.Options{Publish: cli.PublishOptions{Quiet: true}, Global: cli.GlobalOptions{JSON: true}},
+			cfg:     cfg,
+			apkInfo: &apk.APKInfo{Icon: apkIcon},
+			release: &source.Release{TagName: "v1.0"},
+		}
+	}
+
+	t.Run("apk: selects the APK icon", func(t *testing.T) {
+		p := newPublisher(&config.Config{Icon: config.IconFromAPK}, iconPNG)
+		if err := p.resolveMediaRefs(context.Background()); err != nil || p.cfg.Icon != "" {
+			t.Errorf("resolveMediaRefs() = %v, icon %q; want the APK icon", err, p.cfg.Icon)
+		}
+	})
+
+	t.Run("apk: without an APK icon fails", func(t *testing.T) {
+		p := newPublisher(&config.Config{Icon: config.IconFromAPK}, nil)
+		if err := p.resolveMediaRefs(context.Background()); err == nil {
+			t.Error("resolveMediaRefs() succeeded, want an error")
+		}
+	})
+
+	t.Run("repo: files are fetched at the release tag", func(t *testing.T) {
+		p := newPublisher(&config.Config{Icon: "repo:images/icon.png", Images: []string{"local.png", "repo:images/icon.png"}}, nil)
+		if err := p.resolveMediaRefs(context.Background()); err != nil {
+			t.Fatalf("resolveMediaRefs() = %v", err)
+		}
+		rawURL := server.URL + "/api/v1/repos/owner/app/raw/images/icon.png?ref=v1.0"
+		if p.cfg.Icon != rawURL || p.cfg.Images[0] != "local.png" || p.cfg.Images[1] != rawURL {
+			t.Errorf("icon %q images %v, want repo: entries replaced by %s", p.cfg.Icon, p.cfg.Images, rawURL)
+		}
+		if p.preDownloaded.Icon == nil || len(p.preDownloaded.Images) != 1 || p.preDownloaded.Images[0].Hash == "" {
+			t.Errorf("fetched files not held for upload: %+v", p.preDownloaded)
+		}
+	})
+
+	t.Run("missing repo: file names the tag", func(t *testing.T) {
+		p := newPublisher(&config.Config{Images: []string{"repo:images/missing.png"}}, nil)
+		err := p.resolveMediaRefs(context.Background())
+		if !errors.Is(err, source.ErrRepoFileNotFound) || !strings.Contains(err.Error(), "at tag v1.0") {
+			t.Errorf("resolveMediaRefs() = %v, want ErrRepoFileNotFound at tag v1.0", err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/identity"
+	"github.com/zapstore/zsp/internal/keyring"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// KeysGenerate creates a new keypair and either stores the nsec in the OS
+// keyring (--keyring) or prints it. Only the key data goes to stdout; status
+// and advice go to stderr, the status not at all with --quiet.
+func KeysGenerate(opts *cli.Options) error {
+	key, err := identity.GenerateNostrKey()
+	if err != nil {
+		return err
+	}
+	status := func(line string) {
+		if !opts.Keys.Quiet && !opts.Global.JSON {
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+
+	if opts.Keys.Keyring {
+		if err := keyring.Set(key.Npub, key.Nsec); err != nil {
+			return fmt.Errorf("failed to store key in OS keyring: %w (run without --keyring to print it instead)", err)
+		}
+		signWith := config.KeychainSignerPrefix + key.Npub
+		if opts.Global.JSON {
+			data, _ := json.Marshal(map[string]string{"npub": key.Npub, "pubkey": key.Pubkey, "sign_with": signWith})
+			fmt.Println(string(data))
+			return nil
+		}
+		status("Generated a new Nostr key and stored it in the OS keyring")
+		ui.PrintKeyValue("npub", key.Npub)
+		status("To sign with it, set:")
+		fmt.Println("SIGN_WITH=" + signWith)
+		return nil
+	}
+
+	if opts.Global.JSON {
+		data, _ := json.Marshal(map[string]string{"npub": key.Npub, "pubkey": key.Pubkey, "nsec": key.Nsec})
+		fmt.Println(string(data))
+		return nil
+	}
+	status("Generated a new Nostr key")
+	ui.PrintKeyValue("npub", key.Npub)
+	ui.PrintKeyValue("nsec", key.Nsec)
+	fmt.Fprintln(os.Stderr, "warning: the nsec is your private key. Anyone who has it can publish as you, and it cannot be revoked or recovered.")
+	fmt.Fprintln(os.Stderr, "warning: store it in a password manager now, never commit it or share it, and prefer --keyring or a bunker for signing.")
+	return nil
+}
+
+// KeysShow prints the npub SIGN_WITH signs as. Bunker and browser signers
+// are connected to, to ask for their public key.
+func KeysShow(ctx context.Context, opts *cli.Options) error {
+	signWith := config.GetSignWith()
+	if signWith == "" {
+		return fmt.Errorf("SIGN_WITH environment variable is not set (run zsp keys generate to create a key)")
+	}
+
+	npub := config.ResolvePubkeyFromSignWith(signWith)
+	if npub == "" {
+		signer, err := nostr.NewSignerWithOptions(ctx, signWith, nostr.SignerOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create signer: %w", err)
+		}
+		defer signer.Close()
+		if npub, err = nip19.EncodePublicKey(signer.PublicKey()); err != nil {
+			return fmt.Errorf("failed to encode public key: %w", err)
+		}
+	}
+
+	if opts.Global.JSON {
+		_, pk, _ := nip19.Decode(npub)
+		data, _ := json.Marshal(map[string]any{"npub": npub, "pubkey": pk})
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Println(npub)
+	return nil
+}
@@ -0,0 +1,54 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+)
+
+// captureOutput runs fn with stdout and stderr redirected to files and returns
+// what was written to each.
+func captureOutput(t *testing.T, fn func()) (stdout, stderr string) {
+	t.Helper()
+	dir := t.TempDir()
+	outFile, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFile, err := os.Create(filepath.Join(dir, "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	origOut, origErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	defer func() { os.Stdout, os.Stderr = origOut, origErr }()
+
+	fn()
+
+	out, _ := os.ReadFile(outFile.Name())
+	errOut, _ := os.ReadFile(errFile.Name())
+	return string(out), string(errOut)
+}
+
+func TestKeysGenerateKeepsStatusOffStdout(t *testing.T) {
+	for _, quiet := range []bool{false, true} {
+		opts := &cli.Options{Keys: cli.KeysOptions{Operation: "generate", Quiet: quiet}}
+		var err error
+		stdout, stderr := captureOutput(t, func() { err = KeysGenerate(opts) })
+		if err != nil {
+			t.Fatalf("KeysGenerate() = %v", err)
+		}
+		if !strings.Contains(stdout, "npub1") || !strings.Contains(stdout, "nsec1") || strings.Contains(stdout, "Generated") {
+			t.Errorf("quiet=%v: stdout = %q, want only the key data", quiet, stdout)
+		}
+		if got := strings.Contains(stderr, "Generated a new Nostr key"); got == quiet {
+			t.Errorf("quiet=%v: stderr = %q", quiet, stderr)
+		}
+		if !strings.Contains(stderr, "warning: the nsec is your private key") {
+			t.Errorf("quiet=%v: stderr = %q, want the nsec warning", quiet, stderr)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// LintAPK checks an APK for packaging mistakes (zsp apk --lint) and prints a
+// scored report. The apk_lint section of the config given after the APK, or of
+// ./zapstore.yaml, sets ignored checks and the failing severity; --fail-on
+// overrides the latter. Findings at or above it are returned as an error.
+func LintAPK(ctx context.Context, opts *cli.Options) error {
+	if len(opts.Args) < 1 || len(opts.Args) > 2 {
+		return fmt.Errorf("usage: zsp apk --lint <file.apk> [zapstore.yaml]")
+	}
+	apkPath := opts.Args[0]
+
+	lintCfg := &config.APKLintConfig{}
+	configPath := ""
+	if len(opts.Args) == 2 {
+		configPath = opts.Args[1]
+	} else if _, err := os.Stat("zapstore.yaml"); err == nil {
+		configPath = "zapstore.yaml"
+	}
+	if configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if cfg.APKLint != nil {
+			lintCfg = cfg.APKLint
+		}
+	}
+
+	failOn := cmp.Or(opts.APK.FailOn, lintCfg.FailOn, string(apk.LintError))
+	threshold, err := apk.ParseLintSeverity(failOn)
+	if err != nil {
+		return fmt.Errorf("--fail-on: %w", err)
+	}
+
+	info, err := apk.Parse(apkPath)
+	if err != nil {
+		return err
+	}
+	report, err := apk.Lint(info, apk.LintOptions{Ignore: lintCfg.Ignore})
+	if err != nil {
+		return err
+	}
+
+	if opts.Global.JSON {
+		data, _ := json.Marshal(report)
+		fmt.Println(string(data))
+	} else {
+		printLintReport(report)
+	}
+
+	if report.Fails(threshold) {
+		return fmt.Errorf("%s has lint findings at or above %s", report.APK, threshold)
+	}
+	return nil
+}
+
+// printLintReport prints a zsp apk --lint report for humans.
+func printLintReport(report *apk.LintReport) {
+	ui.PrintSectionHeader("APK Lint")
+	ui.PrintKeyValue("File", report.APK)
+	ui.PrintKeyValue("Package", fmt.Sprintf("%s %s", report.PackageID, report.Version))
+	ui.PrintKeyValue("Score", fmt.Sprintf("%d/100", report.Score))
+	if len(report.Ignored) > 0 {
+		ui.PrintKeyValue("Ignored", strings.Join(report.Ignored, ", "))
+	}
+	fmt.Println()
+
+	if len(report.Findings) == 0 {
+		ui.PrintSuccess("No packaging problems found")
+		return
+	}
+	for _, f := range report.Findings {
+		msg := fmt.Sprintf("%s %s", f.Message, ui.Dim("["+f.Check+"]"))
+		switch f.Severity {
+		case apk.LintError:
+			ui.PrintError(msg)
+		case apk.LintWarning:
+			ui.PrintWarning(msg)
+		default:
+			ui.PrintInfo(msg)
+		}
+	}
+}
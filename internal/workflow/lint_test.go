@@ -0,0 +1,24 @@
+package workflow
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+)
+
+func TestLintAPKRejectsBadArguments(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	opts := &cli.Options{}
+	if err := LintAPK(context.Background(), opts); err == nil || !strings.HasPrefix(err.Error(), "usage:") {
+		t.Errorf("LintAPK() without an APK = %v, want usage", err)
+	}
+
+	opts.Args = []string{"app.apk"}
+	opts.APK.FailOn = "fatal"
+	if err := LintAPK(context.Background(), opts); err == nil || !strings.Contains(err.Error(), "--fail-on") {
+		t.Errorf("LintAPK() with --fail-on fatal = %v, want a --fail-on error", err)
+	}
+}
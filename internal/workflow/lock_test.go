@@ -0,0 +1,87 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/publock"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+// Two runs of the same publish triggered together: the second waits for the
+// first and, once it gets the lock, finds the release published.
+func TestAcquireLockCoalescesConcurrentPublishes(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("SIGN_WITH", "npub1locktest")
+
+	asset := &source.Asset{URL: "https://github.com/acme/app/releases/download/v1.0.0/app.apk", Size: 1024}
+	newPublisher := func(wait time.Duration) *Publisher {
+		return &Publisher{
+			opts:          &cli.Options{Publish: cli.PublishOptions{Quiet: true, WaitLock: wait}},
+			cfg:           &config.Config{Repository: "https://github.com/acme/app", Name: "Acme"},
+			publisher:     nostr.NewPublisher([]string{"wss://relay.example.com"}),
+			blossomURL:    "https://blossom.example.com",
+			selectedAsset: asset,
+			apkInfo:       &apk.APKInfo{PackageID: "com.acme.app", VersionName: "1.0.0", SHA256: "abc"},
+		}
+	}
+	ctx := context.Background()
+
+	first, second := newPublisher(0), newPublisher(10*time.Second)
+	for _, p := range []*Publisher{first, second} {
+		if err := p.checkFingerprint(); err != nil {
+			t.Fatalf("checkFingerprint() = %v, want nil before either publish", err)
+		}
+	}
+	if err := first.acquireLock(ctx); err != nil {
+		t.Fatalf("first run: acquireLock() = %v", err)
+	}
+
+	// A run that does not wait stops at once
+	if err := newPublisher(0).acquireLock(ctx); !errors.Is(err, publock.ErrLocked) {
+		t.Fatalf("run without --wait-lock: acquireLock() = %v, want ErrLocked", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- second.acquireLock(ctx)
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("second run got the lock while the first held it: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The first run publishes and finishes
+	first.published = true
+	first.recordFingerprint()
+	first.releaseLock()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrNothingToDo) {
+			t.Fatalf("second run: acquireLock() = %v, want ErrNothingToDo", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second run did not get the lock after the first released it")
+	}
+	second.releaseLock()
+
+	// Offline runs take no lock
+	offline := newPublisher(0)
+	offline.opts.Publish.Offline = true
+	held := newPublisher(0)
+	if err := held.acquireLock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer held.releaseLock()
+	if err := offline.acquireLock(ctx); err != nil {
+		t.Errorf("offline run: acquireLock() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,107 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/media"
+	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// resolveMediaRefs resolves icon: apk: and repo: icon and images entries.
+// Repository files are fetched at the release tag and held like downloaded
+// images under their raw content URL, which replaces the repo: entry, so they
+// are hashed and uploaded like any other image. They are fetched in offline
+// mode too, as remote icons are, to compute their hashes.
+func (p *Publisher) resolveMediaRefs(ctx context.Context) error {
+	if p.cfg.Icon == config.IconFromAPK {
+		if p.apkInfo == nil || len(p.apkInfo.Icon) == 0 {
+			return fmt.Errorf("icon: %s is set but the APK has no icon", config.IconFromAPK)
+		}
+		// With no configured icon the APK icon is used
+		p.cfg.Icon = ""
+	}
+
+	iconPath, repoIcon := config.RepoFilePath(p.cfg.Icon)
+	count := 0
+	if repoIcon {
+		count++
+	}
+	for _, image := range p.cfg.Images {
+		if _, ok := config.RepoFilePath(image); ok {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var spinner *ui.Spinner
+	if p.opts.ShouldShowSpinners() {
+		spinner = ui.NewSpinner("Fetching images from repository...")
+		spinner.Start()
+	}
+	fail := func(field string, err error) error {
+		if spinner != nil {
+			spinner.StopWithError("Failed to fetch images from repository")
+		}
+		return fmt.Errorf("%s: %w", field, err)
+	}
+
+	if p.preDownloaded == nil {
+		p.preDownloaded = &PreDownloadedImages{}
+	}
+	if repoIcon {
+		img, err := p.fetchRepoImage(ctx, iconPath, "icon")
+		if err != nil {
+			return fail("icon", err)
+		}
+		p.cfg.Icon = img.URL
+		p.preDownloaded.Icon = img
+	}
+	for i, image := range p.cfg.Images {
+		path, ok := config.RepoFilePath(image)
+		if !ok {
+			continue
+		}
+		img, err := p.fetchRepoImage(ctx, path, "screenshot")
+		if err != nil {
+			return fail("images", err)
+		}
+		p.cfg.Images[i] = img.URL
+		p.preDownloaded.Images = append(p.preDownloaded.Images, img)
+	}
+
+	if spinner != nil {
+		spinner.StopWithSuccess(fmt.Sprintf("Fetched %d image(s) from repository", count))
+	}
+	return nil
+}
+
+// fetchRepoImage fetches path from the repository at the release tag and
+// prepares it like a downloaded image of the given label.
+func (p *Publisher) fetchRepoImage(ctx context.Context, path, label string) (*DownloadedImage, error) {
+	rawURL, err := source.RepoFileURL(p.cfg, p.release.TagName, path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := source.FetchRepoFile(ctx, p.cfg, p.release.TagName, path)
+	if err != nil {
+		return nil, err
+	}
+	mimeType, err := media.Preflight(data, detectImageMimeType(path))
+	if err != nil {
+		return nil, fmt.Errorf("refusing %s %s: %w", label, path, err)
+	}
+	maxWidth := media.ScreenshotMaxWidth
+	if label == "icon" {
+		maxWidth = media.IconMaxWidth
+	}
+	result, err := prepareImage(data, mimeType, maxWidth, label, p.opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare %s %s: %w", label, path, err)
+	}
+	return &DownloadedImage{URL: rawURL, Data: result.Data, Hash: result.Hash, MimeType: result.MimeType}, nil
+}
@@ -0,0 +1,223 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coder/websocket"
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/nostr"
+)
+
+// rejectingRelay is a mock relay that rejects events of one kind and records
+// the deletion requests it receives.
+type rejectingRelay struct {
+	URL string
+
+	mu        sync.Mutex
+	deletions []*gonostr.Event
+}
+
+func newRejectingRelay(t *testing.T, rejectKind int) *rejectingRelay {
+	t.Helper()
+	rr := &rejectingRelay{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		ctx := r.Context()
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			env, ok := gonostr.ParseMessage(string(data)).(*gonostr.EventEnvelope)
+			if !ok {
+				continue
+			}
+			okEnv := gonostr.OKEnvelope{EventID: env.Event.ID, OK: env.Event.Kind != rejectKind}
+			if !okEnv.OK {
+				okEnv.Reason = "blocked: kind not accepted"
+			}
+			if env.Event.Kind == nostr.KindDeletion {
+				rr.mu.Lock()
+				rr.deletions = append(rr.deletions, &env.Event)
+				rr.mu.Unlock()
+			}
+			msg, _ := okEnv.MarshalJSON()
+			conn.Write(ctx, websocket.MessageText, msg)
+		}
+	}))
+	t.Cleanup(server.Close)
+	rr.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	return rr
+}
+
+// retracted returns the event IDs the relay was asked to delete.
+func (rr *rejectingRelay) retracted() []string {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	var ids []string
+	for _, deletion := range rr.deletions {
+		for _, tag := range deletion.Tags {
+			if len(tag) >= 2 && tag[0] == "e" {
+				ids = append(ids, tag[1])
+			}
+		}
+	}
+	return ids
+}
+
+func partialPublishFixture(t *testing.T) (*nostr.NsecSigner, *nostr.EventSet) {
+	t.Helper()
+	sk := gonostr.GeneratePrivateKey()
+	sign := func(kind int, tags gonostr.Tags) *gonostr.Event {
+		event := &gonostr.Event{Kind: kind, CreatedAt: gonostr.Now(), Tags: tags}
+		if err := event.Sign(sk); err != nil {
+			t.Fatal(err)
+		}
+		return event
+	}
+	events := &nostr.EventSet{
+		AppMetadata:    sign(nostr.KindAppMetadata, gonostr.Tags{{"d", "com.example.app"}}),
+		Release:        sign(nostr.KindRelease, gonostr.Tags{{"d", "com.example.app@1.1.0"}}),
+		SoftwareAssets: []*gonostr.Event{sign(nostr.KindSoftwareAsset, gonostr.Tags{{"i", "com.example.app"}})},
+	}
+	nsec, _ := nip19.EncodePrivateKey(sk)
+	signer, err := nostr.NewNsecSigner(nsec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, events
+}
+
+func TestHandlePartialPublishKeepsReplacedApp(t *testing.T) {
+	tests := []struct {
+		name        string
+		existingApp bool
+		wantAppGone bool
+	}{
+		{"re-publish keeps the app event", true, false},
+		{"first publish retracts the app event", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRejectingRelay(t, nostr.KindRelease)
+			signer, events := partialPublishFixture(t)
+			p := &Publisher{
+				opts:       &cli.Options{},
+				publisher:  nostr.NewPublisher([]string{relay.URL}),
+				signer:     signer,
+				events:     events,
+				appChecked: true,
+			}
+			if tt.existingApp {
+				p.existingApp = &gonostr.Event{Kind: nostr.KindAppMetadata}
+			}
+
+			results, err := p.publisher.PublishEventSet(context.Background(), events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.handlePartialPublish(context.Background(), results); err == nil {
+				t.Fatal("handlePartialPublish() = nil, want the rejected release reported")
+			}
+
+			retracted := relay.retracted()
+			if !slices.Contains(retracted, events.SoftwareAssets[0].ID) {
+				t.Errorf("asset not retracted: %v", retracted)
+			}
+			if got := slices.Contains(retracted, events.AppMetadata.ID); got != tt.wantAppGone {
+				t.Errorf("app event retracted = %v, want %v", got, tt.wantAppGone)
+			}
+		})
+	}
+}
+
+func TestHandlePartialPublishKeepsReplacedRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		overwrite       bool
+		wantReleaseGone bool
+	}{
+		{"--overwrite-release keeps the release", true, false},
+		{"new release is retracted", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newRejectingRelay(t, nostr.KindSoftwareAsset)
+			signer, events := partialPublishFixture(t)
+			opts := &cli.Options{}
+			opts.Publish.OverwriteRelease = tt.overwrite
+			p := &Publisher{
+				opts:        opts,
+				publisher:   nostr.NewPublisher([]string{relay.URL}),
+				signer:      signer,
+				events:      events,
+				appChecked:  true,
+				existingApp: &gonostr.Event{Kind: nostr.KindAppMetadata},
+			}
+
+			results, err := p.publisher.PublishEventSet(context.Background(), events)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.handlePartialPublish(context.Background(), results); err == nil {
+				t.Fatal("handlePartialPublish() = nil, want the rejected asset reported")
+			}
+
+			retracted := relay.retracted()
+			if got := slices.Contains(retracted, events.Release.ID); got != tt.wantReleaseGone {
+				t.Errorf("release retracted = %v, want %v", got, tt.wantReleaseGone)
+			}
+			if slices.Contains(retracted, events.AppMetadata.ID) {
+				t.Error("app event that replaced an earlier one was retracted")
+			}
+		})
+	}
+}
+
+func TestCheckMinRelaySuccess(t *testing.T) {
+	signer, _ := partialPublishFixture(t)
+	assetsOnly := []nostr.RelayRoute{{Kinds: []int{nostr.KindSoftwareAsset}, Relays: []string{"wss://assets.example"}, Replace: true}}
+	tests := []struct {
+		name         string
+		minSuccess   int
+		routes       []nostr.RelayRoute
+		skipAppEvent bool
+		wantErr      bool
+	}{
+		{"within the configured relays", 2, nil, false, false},
+		{"more than the configured relays", 3, nil, false, true},
+		{"more than a replacing route", 2, assetsOnly, false, true},
+		{"within a replacing route", 1, assetsOnly, false, false},
+		{"extra route relays count", 3, []nostr.RelayRoute{{Kinds: []int{nostr.KindAppMetadata, nostr.KindRelease, nostr.KindSoftwareAsset}, Relays: []string{"wss://c.example"}}}, false, false},
+		{"skipped app event is not checked", 3, []nostr.RelayRoute{{Kinds: []int{nostr.KindRelease, nostr.KindSoftwareAsset}, Relays: []string{"wss://c.example"}}}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &cli.Options{}
+			opts.Publish.MinRelaySuccess = tt.minSuccess
+			opts.Publish.SkipAppEvent = tt.skipAppEvent
+			p := &Publisher{
+				opts:      opts,
+				publisher: nostr.NewPublisher([]string{"wss://a.example", "wss://b.example"}),
+				signer:    signer,
+			}
+			p.publisher.SetRoutes(tt.routes)
+			if err := p.checkMinRelaySuccess(); (err != nil) != tt.wantErr {
+				t.Errorf("checkMinRelaySuccess() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// TestProgressJSONDryRun drives an offline publish of a test APK with
+// --progress-json and checks the sequence of events a wrapping UI sees.
+func TestProgressJSONDryRun(t *testing.T) {
+	apkPath := filepath.Join("..", "..", "testdata", "apks", "sample.apk")
+	if _, err := os.Stat(apkPath); os.IsNotExist(err) {
+		t.Skipf("test APK not found: %s", apkPath)
+	}
+	apkPath, _ = filepath.Abs(apkPath)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("SIGN_WITH", "0000000000000000000000000000000000000000000000000000000000000001")
+
+	opts := &cli.Options{Publish: cli.PublishOptions{
+		Offline:      true,
+		SkipMetadata: true,
+		ProgressJSON: true,
+		Channel:      "main",
+		OverwriteApp: "merge",
+	}}
+	cfg := &config.Config{ReleaseSource: &config.ReleaseSource{LocalPath: apkPath}}
+
+	// The signed events go to stdout; keep them out of the test output
+	stdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = devNull
+	defer func() {
+		os.Stdout = stdout
+		devNull.Close()
+	}()
+
+	var buf bytes.Buffer
+	if err := ui.StartProgress(&buf, nil, strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := NewPublisher(context.Background(), opts, cfg)
+	if err == nil {
+		err = pub.Execute(context.Background())
+		pub.Close()
+	}
+	exitCode := 0
+	if err != nil {
+		exitCode = 1
+	}
+	ui.FinishProgress(exitCode)
+	if err != nil {
+		t.Fatalf("offline publish failed: %v", err)
+	}
+
+	var sequence []string
+	var result *ui.ProgressResult
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e ui.ProgressEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		switch e.Type {
+		case ui.EventLog, ui.EventWarning:
+			// Human output and warnings depend on the APK
+		case ui.EventStage:
+			sequence = append(sequence, e.Type+":"+e.Stage)
+		default:
+			sequence = append(sequence, e.Type)
+		}
+		if e.Result != nil {
+			result = e.Result
+		}
+	}
+
+	want := []string{"start", "stage:fetch", "stage:metadata", "stage:sign", "result"}
+	if !reflect.DeepEqual(sequence, want) {
+		t.Errorf("event sequence = %v, want %v", sequence, want)
+	}
+	if result == nil || result.Status != "success" || result.ExitCode != 0 {
+		t.Errorf("result = %+v, want success", result)
+	}
+}
@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/ratelimit"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// ApplyRateLimit installs the --limit-rate (or ZSP_LIMIT_RATE) bandwidth limit,
+// then the --max-download-rate and --max-upload-rate limits, which replace it
+// for their direction. A value that does not parse is ignored with a warning
+// rather than failing the publish.
+func ApplyRateLimit(opts *cli.Options) {
+	value := opts.Publish.LimitRate
+	if value == "" {
+		value = config.GetEnv(ratelimit.EnvLimitRate)
+	}
+	if value != "" {
+		if bytesPerSec, err := ratelimit.ParseRate(value); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s; transfers are not rate limited\n", ui.SanitizeErrorMessage(err))
+		} else {
+			ratelimit.SetLimit(bytesPerSec)
+		}
+	}
+
+	for _, limit := range []struct {
+		flag, value, what string
+		dir               ratelimit.Direction
+	}{
+		{"--max-download-rate", opts.Publish.MaxDownloadRate, "downloads", ratelimit.Download},
+		{"--max-upload-rate", opts.Publish.MaxUploadRate, "uploads", ratelimit.Upload},
+	} {
+		if limit.value == "" {
+			continue
+		}
+		bytesPerSec, err := ratelimit.ParseRate(limit.value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s; %s are not limited by it\n", limit.flag, ui.SanitizeErrorMessage(err), limit.what)
+			continue
+		}
+		ratelimit.SetDirectionLimit(limit.dir, bytesPerSec)
+	}
+}
@@ -0,0 +1,22 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/ratelimit"
+)
+
+func TestApplyRateLimit(t *testing.T) {
+	t.Cleanup(func() { ratelimit.SetLimit(0) })
+
+	opts := &cli.Options{Publish: cli.PublishOptions{LimitRate: "1M", MaxUploadRate: "100k", MaxDownloadRate: "fast"}}
+	ApplyRateLimit(opts)
+
+	if got := ratelimit.Limit(ratelimit.Download); got != 1<<20 {
+		t.Errorf("download limit = %d, want --limit-rate kept when --max-download-rate does not parse", got)
+	}
+	if got := ratelimit.Limit(ratelimit.Upload); got != 100<<10 {
+		t.Errorf("upload limit = %d, want --max-upload-rate", got)
+	}
+}
@@ -0,0 +1,272 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// FormatRelayInfo renders a relay's NIP-11 document as indented lines: name,
+// software, supported NIPs, limits, access requirements and restrictions on
+// the kinds zsp publishes.
+func FormatRelayInfo(r nostr.RelayInfoResult) []string {
+	if r.Err != nil {
+		return []string{"  " + ui.SanitizeErrorMessage(r.Err)}
+	}
+	info := r.Info
+	var lines []string
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("  %-10s %s", key+":", value))
+		}
+	}
+
+	add("Name", info.Name)
+	add("Software", strings.TrimSpace(info.Software+" "+info.Version))
+	nips := make([]string, len(info.SupportedNIPs))
+	for i, nip := range info.SupportedNIPs {
+		nips[i] = fmt.Sprint(nip)
+	}
+	add("NIPs", strings.Join(nips, ", "))
+
+	var limits []string
+	if l := info.Limitation; l != nil {
+		if l.MaxMessageLength > 0 {
+			limits = append(limits, "message "+ui.FormatBytes(int64(l.MaxMessageLength)))
+		}
+		if l.MaxContentLength > 0 {
+			limits = append(limits, fmt.Sprintf("content %d chars", l.MaxContentLength))
+		}
+		if l.MaxEventTags > 0 {
+			limits = append(limits, fmt.Sprintf("%d tags", l.MaxEventTags))
+		}
+	}
+	add("Limits", strings.Join(limits, ", "))
+
+	access := "open"
+	if reqs := info.AccessRequirements(); len(reqs) > 0 {
+		access = strings.Join(reqs, ", ")
+	}
+	add("Access", access)
+	add("Payments", info.PaymentsURL)
+
+	kinds := make([]string, len(nostr.PublishedKinds))
+	for i, kind := range nostr.PublishedKinds {
+		kinds[i] = fmt.Sprint(kind)
+	}
+	restrictions := info.KindRestrictions(nostr.PublishedKinds)
+	if len(restrictions) == 0 {
+		add("Kinds", fmt.Sprintf("no restrictions advertised for %s", strings.Join(kinds, ", ")))
+	}
+	for _, restriction := range restrictions {
+		add("Kinds", restriction)
+	}
+	return lines
+}
+
+// relayPreflight fetches the publish relays' NIP-11 documents and warns about
+// anything that would likely get the events rejected. With --relay-info each
+// relay's document is printed as well. Relays without a document are skipped.
+func (p *Publisher) relayPreflight(ctx context.Context) {
+	events := []*gonostr.Event{p.events.Release}
+	if p.events.AppMetadata != nil {
+		events = append(events, p.events.AppMetadata)
+	}
+	events = append(events, p.events.SoftwareAssets...)
+	if p.events.ReleaseNotes != nil {
+		events = append(events, p.events.ReleaseNotes)
+	}
+
+	infos := p.relayInfos
+	if infos == nil {
+		infos = nostr.FetchRelayInfos(ctx, p.publisher.AllRelayURLs())
+	}
+	for _, r := range infos {
+		if p.opts.Publish.RelayInfo && p.opts.ShouldShowSpinners() {
+			fmt.Println("  " + r.URL)
+			for _, line := range FormatRelayInfo(r) {
+				fmt.Println("  " + line)
+			}
+		}
+		if r.Err != nil {
+			if p.opts.Global.Verbose {
+				fmt.Printf("    No relay information for %s: %v\n", r.URL, r.Err)
+			}
+			continue
+		}
+		for _, problem := range r.Info.EventProblems(events) {
+			p.warn(fmt.Sprintf("%s: %s; the relay may reject the events", r.URL, problem))
+		}
+	}
+}
+
+// checkContentLimits compares the release notes and the description with the
+// smallest NIP-11 max_content_length of the publish relays, in characters as
+// NIP-11 defines it, before the events are built: relays reject oversized content, and some silently truncate it.
+// Interactively, release notes over the limit can be moved to a long-form
+// article (release_notes_event) or truncated; otherwise zsp only warns.
+func (p *Publisher) checkContentLimits(ctx context.Context) error {
+	if p.skipsRelays() {
+		return nil
+	}
+	p.relayInfos = nostr.FetchRelayInfos(ctx, p.publisher.AllRelayURLs())
+	limit, relayURL := minContentLength(p.relayInfos)
+	if limit == 0 {
+		return nil
+	}
+
+	if n := utf8.RuneCountInString(p.cfg.Description); n > limit {
+		p.warn(fmt.Sprintf("the description is %d characters, over the %d character content limit of %s; the relay may reject or truncate the app event", n, limit, relayURL))
+	}
+
+	n := utf8.RuneCountInString(p.releaseContent())
+	if n <= limit {
+		return nil
+	}
+	msg := fmt.Sprintf("the release notes are %d characters, over the %d character content limit of %s", n, limit, relayURL)
+	if !p.opts.IsInteractive() {
+		hint := "set release_notes_event: true to publish them as a long-form article"
+		if p.cfg.ReleaseNotesEvent {
+			hint = "set a shorter release_notes_excerpt"
+		}
+		p.warn(fmt.Sprintf("%s; the relay may reject or truncate the release (%s)", msg, hint))
+		return nil
+	}
+
+	ui.PrintWarning(msg)
+	var options []string
+	if !p.cfg.ReleaseNotesEvent {
+		options = append(options, "Move them to a long-form article (kind 30023), keeping an excerpt in the release")
+	}
+	options = append(options,
+		fmt.Sprintf("Truncate them to %d characters", limit),
+		"Publish them as they are")
+	idx, err := ui.SelectOption("Release notes:", options, 0)
+	if err != nil {
+		return err
+	}
+	switch idx {
+	case len(options) - 1:
+		return nil
+	case len(options) - 2:
+		p.truncateReleaseNotes(limit)
+		return nil
+	}
+	return p.moveNotesToArticle(ctx, limit)
+}
+
+// minContentLength returns the smallest max_content_length the relays
+// advertise, and the relay advertising it. Zero when none advertises one.
+func minContentLength(infos []nostr.RelayInfoResult) (int, string) {
+	limit, relayURL := 0, ""
+	for _, r := range infos {
+		if r.Info == nil || r.Info.Limitation == nil || r.Info.Limitation.MaxContentLength <= 0 {
+			continue
+		}
+		if n := r.Info.Limitation.MaxContentLength; limit == 0 || n < limit {
+			limit, relayURL = n, r.URL
+		}
+	}
+	return limit, relayURL
+}
+
+// releaseContent returns the content the release event will carry: the
+// release notes, or their excerpt when they are published as an article.
+func (p *Publisher) releaseContent() string {
+	if p.cfg.ReleaseNotesEvent {
+		return nostr.ReleaseNotesExcerpt(p.releaseNotes, p.cfg.ReleaseNotesExcerpt)
+	}
+	return p.releaseNotes
+}
+
+// truncateReleaseNotes shortens the release content to limit characters.
+func (p *Publisher) truncateReleaseNotes(limit int) {
+	if p.cfg.ReleaseNotesEvent {
+		p.cfg.ReleaseNotesExcerpt = truncateContent(p.releaseContent(), limit)
+		return
+	}
+	p.releaseNotes = truncateContent(p.releaseNotes, limit)
+}
+
+// moveNotesToArticle publishes the release notes as a long-form article
+// (release_notes_event) for this run, truncating the excerpt the release keeps
+// if it is still over limit, and fetches the notes' images for upload.
+func (p *Publisher) moveNotesToArticle(ctx context.Context, limit int) error {
+	p.cfg.ReleaseNotesEvent = true
+	if len(p.releaseContent()) > limit {
+		p.truncateReleaseNotes(limit)
+	}
+	if p.opts.ShouldShowSpinners() {
+		ui.PrintInfo("Publishing the release notes as a long-form article (set release_notes_event: true to keep doing so)")
+	}
+	return p.prepareNotesImages(ctx)
+}
+
+// truncateContent cuts s to at most limit characters, at a line or word break
+// where one is near, and marks the cut with an ellipsis.
+func truncateContent(s string, limit int) string {
+	const ellipsis = "…"
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	// The ellipsis is one character
+	s = string([]rune(s)[:max(limit-1, 0)])
+	if i := strings.LastIndexAny(s, "\n "); i > len(s)/2 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, " \t\n") + ellipsis
+}
+
+// RelayInfo prints the NIP-11 information of the given relays, or of
+// RELAY_URLS when none are given.
+func RelayInfo(ctx context.Context, opts *cli.Options) error {
+	relayURLs := opts.Args
+	if len(relayURLs) == 0 {
+		relayURLs = nostr.NewPublisherFromEnv(config.GetEnv("RELAY_URLS")).RelayURLs()
+	}
+
+	results := nostr.FetchRelayInfos(ctx, relayURLs)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	failed := 0
+	for i, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+		if opts.Global.JSON {
+			out := map[string]any{"url": r.URL}
+			if r.Err != nil {
+				out["error"] = ui.SanitizeErrorMessage(r.Err)
+			} else {
+				out["info"] = r.Info
+				out["access"] = r.Info.AccessRequirements()
+				out["restrictions"] = r.Info.KindRestrictions(nostr.PublishedKinds)
+			}
+			data, _ := json.Marshal(out)
+			fmt.Println(string(data))
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(r.URL)
+		for _, line := range FormatRelayInfo(r) {
+			fmt.Println(line)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("could not fetch relay information from %d of %d relays", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+func TestTruncateContent(t *testing.T) {
+	tests := []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"first line\nsecond_line", 20, "first line…"},
+		{"one two three four", 15, "one two three…"},
+		{"ääääää", 4, "äää…"},
+	}
+	for _, tt := range tests {
+		got := truncateContent(tt.in, tt.limit)
+		if got != tt.want {
+			t.Errorf("truncateContent(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+		}
+		if n := utf8.RuneCountInString(got); n > tt.limit {
+			t.Errorf("truncateContent(%q, %d) is %d characters", tt.in, tt.limit, n)
+		}
+	}
+}
+
+func TestMinContentLength(t *testing.T) {
+	infos := []nostr.RelayInfoResult{
+		{URL: "wss://a", Info: &nostr.RelayInfo{Limitation: &nostr.RelayLimitation{MaxContentLength: 8000}}},
+		{URL: "wss://b", Info: &nostr.RelayInfo{}},
+		{URL: "wss://c", Info: &nostr.RelayInfo{Limitation: &nostr.RelayLimitation{MaxContentLength: 2000}}},
+		{URL: "wss://d"},
+	}
+	if limit, relayURL := minContentLength(infos); limit != 2000 || relayURL != "wss://c" {
+		t.Errorf("minContentLength() = %d, %s, want 2000, wss://c", limit, relayURL)
+	}
+	if limit, _ := minContentLength(infos[1:2]); limit != 0 {
+		t.Errorf("minContentLength() without limits = %d, want 0", limit)
+	}
+}
+
+func TestCheckContentLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/nostr+json")
+		w.Write([]byte(`{"name":"small","limitation":{"max_content_length":100}}`))
+	}))
+	defer server.Close()
+	relayURL := "ws://" + strings.TrimPrefix(server.URL, "http://")
+
+	ui.CollectWarnings()
+	p := &Publisher{
+		opts:         &cli.Options{Publish: cli.PublishOptions{Quiet: true}},
+		cfg:          &config.Config{},
+		publisher:    nostr.NewPublisher([]string{relayURL}),
+		releaseNotes: strings.Repeat("A long changelog line.\n", 20),
+	}
+	if err := p.checkContentLimits(context.Background()); err != nil {
+		t.Fatalf("checkContentLimits() error: %v", err)
+	}
+	warnings := strings.Join(ui.CollectedWarnings(), "\n")
+	if !strings.Contains(warnings, "over the 100 character content limit of "+relayURL) || !strings.Contains(warnings, "release_notes_event") {
+		t.Errorf("warnings = %q, want one about the release notes", warnings)
+	}
+	if len(p.relayInfos) != 1 || p.relayInfos[0].Info == nil {
+		t.Errorf("relayInfos = %+v, want the relay's document kept for the preflight", p.relayInfos)
+	}
+
+	// Moving to an article keeps a short excerpt in the release
+	p.releaseNotes = "Fixes sync.\n\n" + p.releaseNotes
+	if err := p.moveNotesToArticle(context.Background(), 100); err != nil {
+		t.Fatalf("moveNotesToArticle() error: %v", err)
+	}
+	if !p.cfg.ReleaseNotesEvent || p.releaseContent() != "Fixes sync." {
+		t.Errorf("after moving: release_notes_event=%v, release content %q", p.cfg.ReleaseNotesEvent, p.releaseContent())
+	}
+
+	p.cfg = &config.Config{}
+	p.truncateReleaseNotes(100)
+	if n := utf8.RuneCountInString(p.releaseContent()); n > 100 {
+		t.Errorf("truncated release notes are %d characters, want at most 100", n)
+	}
+}
+
+func TestRelayInfoReportsFailedRelays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/nostr+json")
+		w.Write([]byte(`{"name":"up"}`))
+	}))
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	opts := &cli.Options{Args: []string{
+		"ws://" + strings.TrimPrefix(server.URL, "http://"),
+		"ws://" + strings.TrimPrefix(down.URL, "http://"),
+	}}
+	opts.Global.JSON = true
+	err := RelayInfo(context.Background(), opts)
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 relays") {
+		t.Errorf("RelayInfo() = %v, want a failure for 1 of 2 relays", err)
+	}
+}
@@ -0,0 +1,60 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+func TestCheckReleaseAge(t *testing.T) {
+	published := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	reuploaded := published.Add(30 * time.Minute)
+
+	newPublisher := func(minAge string, ignore bool, release *source.Release) *Publisher {
+		return &Publisher{
+			opts:    &cli.Options{Publish: cli.PublishOptions{Quiet: true, IgnoreReleaseAge: ignore}},
+			cfg:     &config.Config{MinReleaseAge: minAge},
+			release: release,
+		}
+	}
+	release := &source.Release{
+		Version:   "1.0.0",
+		CreatedAt: published,
+		Assets:    []*source.Asset{{Name: "app.apk", UpdatedAt: reuploaded}},
+	}
+
+	tests := []struct {
+		name    string
+		minAge  string
+		ignore  bool
+		now     time.Time
+		refused bool
+	}{
+		{"unset", "", false, reuploaded, false},
+		{"just re-uploaded", "1h", false, reuploaded, true},
+		{"old enough by publish date only", "1h", false, published.Add(time.Hour), true},
+		{"one second before eligible", "1h", false, reuploaded.Add(time.Hour - time.Second), true},
+		{"exactly eligible", "1h", false, reuploaded.Add(time.Hour), false},
+		{"after eligible", "1h", false, reuploaded.Add(2 * time.Hour), false},
+		{"zero window", "0s", false, reuploaded, false},
+		{"ignored", "1h", true, reuploaded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newPublisher(tt.minAge, tt.ignore, release).checkReleaseAge(tt.now)
+			if refused := errors.Is(err, ErrNothingToDo); refused != tt.refused || (err != nil && !refused) {
+				t.Errorf("checkReleaseAge() = %v, want refused=%v", err, tt.refused)
+			}
+		})
+	}
+
+	// Without any dates the window cannot be enforced; the release is published
+	undated := &source.Release{Version: "1.0.0", Assets: []*source.Asset{{Name: "app.apk"}}}
+	if err := newPublisher("1h", false, undated).checkReleaseAge(reuploaded); err != nil {
+		t.Errorf("checkReleaseAge() without dates = %v, want nil", err)
+	}
+}
@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zapstore/zsp/internal/media"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/source"
+)
+
+// markdownImageRe matches a Markdown image, ![alt](url "title"), capturing its URL.
+var markdownImageRe = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+
+// notesImageRefs lists the distinct image URLs and paths in Markdown notes, in order.
+func notesImageRefs(notes string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, m := range markdownImageRe.FindAllStringSubmatch(notes, -1) {
+		ref := m[1]
+		if seen[ref] || strings.HasPrefix(ref, "data:") {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// prepareNotesImages fetches the images of the release notes when they are
+// published as a long-form event (release_notes_event), so they are uploaded
+// to Blossom with the other blobs and the article points there. Images already
+// on the Blossom server are left alone, and remote images are not fetched
+// offline. An image that cannot be fetched keeps its URL, with a warning.
+func (p *Publisher) prepareNotesImages(ctx context.Context) error {
+	p.notesImages = nil
+	if !p.cfg.ReleaseNotesEvent || p.releaseNotes == "" {
+		return nil
+	}
+
+	// Relative paths are relative to a local release notes file
+	baseDir := p.cfg.BaseDir
+	if p.cfg.ReleaseNotes != "" && !isRemoteURL(p.cfg.ReleaseNotes) && p.cfg.ReleaseNotes != source.GitLogReleaseNotes {
+		baseDir = filepath.Dir(resolvePath(p.cfg.ReleaseNotes, p.cfg.BaseDir))
+	}
+
+	for _, ref := range notesImageRefs(p.releaseNotes) {
+		if isRemoteURL(ref) && (p.isOffline() || isBlossomURL(ref, p.blossomURL)) {
+			continue
+		}
+		img, err := p.fetchNotesImage(ctx, ref, baseDir)
+		if err != nil {
+			p.warn(fmt.Sprintf("release notes image %s: %v; keeping its URL", ref, err))
+			continue
+		}
+		p.notesImages = append(p.notesImages, img)
+	}
+	return nil
+}
+
+// fetchNotesImage downloads a remote release notes image or reads a local one
+// relative to baseDir, prepared like a screenshot.
+func (p *Publisher) fetchNotesImage(ctx context.Context, ref, baseDir string) (*DownloadedImage, error) {
+	if isRemoteURL(ref) {
+		return downloadImageWithSpinner(ctx, ref, "release notes image", p.opts)
+	}
+	path := resolvePath(ref, baseDir)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prepared, err := prepareImage(data, detectImageMimeType(path), media.ScreenshotMaxWidth, "release notes image", p.opts)
+	if err != nil {
+		return nil, err
+	}
+	return &DownloadedImage{URL: ref, Data: prepared.Data, Hash: prepared.Hash, MimeType: prepared.MimeType}, nil
+}
+
+// rewriteNotesImages points the Markdown images of notes that were fetched for
+// upload at their Blossom copies on server, in their public form (see
+// nostr.PublicBlossomURL).
+func rewriteNotesImages(notes string, images []*DownloadedImage, server, public string) string {
+	if len(images) == 0 {
+		return notes
+	}
+	urls := make(map[string]string, len(images))
+	for _, img := range images {
+		urls[img.URL] = nostr.PublicBlossomURL(fmt.Sprintf("%s/%s", server, img.Hash), server, public)
+	}
+	return markdownImageRe.ReplaceAllStringFunc(notes, func(image string) string {
+		m := markdownImageRe.FindStringSubmatchIndex(image)
+		ref := image[m[2]:m[3]]
+		if url, ok := urls[ref]; ok {
+			return image[:m[2]] + url + image[m[3]:]
+		}
+		return image
+	})
+}
@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotesImageRefs(t *testing.T) {
+	notes := "![a](img/a.png)\n![b]( https://example.com/b.png \"Title\" )\n![a again](img/a.png)\n![inline](data:image/png;base64,AAAA)\n[not an image](https://example.com)"
+	want := []string{"img/a.png", "https://example.com/b.png"}
+	if got := notesImageRefs(notes); !reflect.DeepEqual(got, want) {
+		t.Errorf("notesImageRefs = %v, want %v", got, want)
+	}
+}
+
+func TestRewriteNotesImages(t *testing.T) {
+	notes := "![a](img/a.png)\n![b](https://example.com/b.png \"B\")\n![c](img/c.png)"
+	images := []*DownloadedImage{
+		{URL: "img/a.png", Hash: "aaa"},
+		{URL: "https://example.com/b.png", Hash: "bbb"},
+	}
+	want := "![a](https://cdn.example.com/aaa)\n![b](https://cdn.example.com/bbb \"B\")\n![c](img/c.png)"
+	if got := rewriteNotesImages(notes, images, "https://blossom.example.com", "https://cdn.example.com/"); got != want {
+		t.Errorf("rewriteNotesImages = %q, want %q", got, want)
+	}
+	if got := rewriteNotesImages(notes, nil, "https://blossom.example.com", ""); got != notes {
+		t.Errorf("rewriteNotesImages without images changed the notes: %q", got)
+	}
+}
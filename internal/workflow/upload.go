@@ -3,6 +3,7 @@ package workflow
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
 	"io"
@@ -18,7 +19,10 @@ import (
 	"github.com/zapstore/zsp/internal/blossom"
 	"github.com/zapstore/zsp/internal/cli"
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/detachedsig"
 	"github.com/zapstore/zsp/internal/media"
+	"github.com/zapstore/zsp/internal/metrics"
+	"github.com/zapstore/zsp/internal/netpolicy"
 	"github.com/zapstore/zsp/internal/nostr"
 	"github.com/zapstore/zsp/internal/source"
 	"github.com/zapstore/zsp/internal/ui"
@@ -55,9 +59,19 @@ type UploadParams struct {
 	Variant             string
 	Commit              string
 	Channel             string
+	ChannelSuffix       bool                       // Suffix the app identifier with the channel (--channel-suffix)
+	Platforms           []string                   // Overrides detected platforms (--platform)
+	Provenance          []nostr.MetadataProvenance // Metadata source tags (metadata_provenance)
 	Opts                *cli.Options
 	AppCreatedAtRelease bool
-	MinReleaseTimestamp time.Time // Bump Release.CreatedAt above this (--overwrite-release)
+	MinReleaseTimestamp time.Time              // Bump Release.CreatedAt above this (--overwrite-release)
+	PublishedAt         time.Time              // Release published_at tag (zero omits it)
+	Now                 time.Time              // Current time for created_at (zero means the local clock)
+	ExistingApp         *gonostr.Event         // Existing 32267 to merge empty fields from (--overwrite-app=merge)
+	DetachedSignature   *detachedsig.Signature // Uploaded next to the APK (detached_signature)
+	NotesImages         []*DownloadedImage     // Release notes images (release_notes_event)
+	KeepAssets          []*gonostr.Event       // Published assets the release keeps referencing (--only-new-assets)
+	Delegation          *nostr.Delegation      // NIP-26 delegation added to every event (--delegation)
 }
 
 // uploadItem represents a file to upload with its auth event.
@@ -67,7 +81,7 @@ type uploadItem struct {
 	mimeType   string
 	authEvent  *gonostr.Event
 	isAPK      bool
-	uploadType string // "icon", "image", "APK" - for display
+	uploadType string // "icon", "image", "signature", "APK" - for display
 	apkPath    string
 }
 
@@ -79,17 +93,50 @@ type PendingUploads struct {
 	opts      *cli.Options
 }
 
+// IconBlurhash returns the blurhash of the icon being uploaded, or "" when there is
+// no icon, it cannot be decoded (e.g. SVG) or --no-blurhash is set.
+func (p *PendingUploads) IconBlurhash() string {
+	return iconBlurhash(p.items, p.opts)
+}
+
+// iconBlurhash computes the blurhash of the icon among uploads.
+func iconBlurhash(uploads []uploadItem, opts *cli.Options) string {
+	if opts != nil && opts.Publish.NoBlurhash {
+		return ""
+	}
+	for _, u := range uploads {
+		if u.uploadType != "icon" {
+			continue
+		}
+		hash, err := media.Blurhash(u.data)
+		if err != nil {
+			if opts != nil && opts.Global.Verbose {
+				fmt.Fprintf(os.Stderr, "    skipping icon blurhash: %v\n", err)
+			}
+			return ""
+		}
+		return hash
+	}
+	return ""
+}
+
 // Execute performs the pending blob uploads to the Blossom server.
 func (p *PendingUploads) Execute(ctx context.Context) error {
 	return performUploads(ctx, p.client, p.items, p.existsMap, p.opts)
 }
 
 // PreDownloadImages downloads cfg.Icon and cfg.Images if they are remote URLs.
-func PreDownloadImages(ctx context.Context, cfg *config.Config, opts *cli.Options) (*PreDownloadedImages, error) {
+// Images already in fetched (repo: files) are reused instead of downloaded again.
+func PreDownloadImages(ctx context.Context, cfg *config.Config, opts *cli.Options, fetched *PreDownloadedImages) (*PreDownloadedImages, error) {
 	result := &PreDownloadedImages{}
+	if fetched == nil {
+		fetched = &PreDownloadedImages{}
+	}
 
 	// Download icon if it's a remote URL
-	if cfg.Icon != "" && isRemoteURL(cfg.Icon) {
+	if fetched.Icon != nil && fetched.Icon.URL == cfg.Icon {
+		result.Icon = fetched.Icon
+	} else if cfg.Icon != "" && isRemoteURL(cfg.Icon) {
 		img, err := downloadImageWithSpinner(ctx, cfg.Icon, "icon", opts)
 		if err != nil {
 			if opts.ShouldShowSpinners() {
@@ -104,44 +151,51 @@ func PreDownloadImages(ctx context.Context, cfg *config.Config, opts *cli.Option
 	}
 
 	// Download screenshots if they are remote URLs
-	remoteImages := countRemoteImages(cfg.Images)
-	if remoteImages > 0 {
-		var spinner *ui.Spinner
-		if opts.ShouldShowSpinners() {
-			spinner = ui.NewSpinner(fmt.Sprintf("Downloading 0/%d screenshots...", remoteImages))
-			spinner.Start()
+	remoteImages := 0
+	for _, img := range cfg.Images {
+		if isRemoteURL(img) && findPreDownloadedImage(fetched.Images, img) == nil {
+			remoteImages++
 		}
+	}
+	var spinner *ui.Spinner
+	if remoteImages > 0 && opts.ShouldShowSpinners() {
+		spinner = ui.NewSpinner(fmt.Sprintf("Downloading 0/%d screenshots...", remoteImages))
+		spinner.Start()
+	}
 
-		downloaded := 0
-		for _, img := range cfg.Images {
-			if !isRemoteURL(img) {
-				continue
-			}
-
-			data, hash, mimeType, err := downloadAndPrepareImage(ctx, img, "screenshot", opts)
-			if err != nil {
-				if spinner != nil {
-					spinner.StopWithWarning(fmt.Sprintf("Failed to download screenshot: %v", err))
-				}
-				continue
-			}
+	downloaded := 0
+	for _, img := range cfg.Images {
+		if !isRemoteURL(img) {
+			continue
+		}
+		if existing := findPreDownloadedImage(fetched.Images, img); existing != nil {
+			result.Images = append(result.Images, existing)
+			continue
+		}
 
-			downloaded++
+		data, hash, mimeType, err := downloadAndPrepareImage(ctx, img, "screenshot", opts)
+		if err != nil {
 			if spinner != nil {
-				spinner.UpdateMessage(fmt.Sprintf("Downloading %d/%d screenshots...", downloaded, remoteImages))
+				spinner.StopWithWarning(fmt.Sprintf("Failed to download screenshot: %v", err))
 			}
-
-			result.Images = append(result.Images, &DownloadedImage{
-				URL:      img,
-				Data:     data,
-				Hash:     hash,
-				MimeType: mimeType,
-			})
+			continue
 		}
 
+		downloaded++
 		if spinner != nil {
-			spinner.StopWithSuccess(fmt.Sprintf("Downloaded %d screenshots", len(result.Images)))
+			spinner.UpdateMessage(fmt.Sprintf("Downloading %d/%d screenshots...", downloaded, remoteImages))
 		}
+
+		result.Images = append(result.Images, &DownloadedImage{
+			URL:      img,
+			Data:     data,
+			Hash:     hash,
+			MimeType: mimeType,
+		})
+	}
+
+	if spinner != nil {
+		spinner.StopWithSuccess(fmt.Sprintf("Downloaded %d screenshots", downloaded))
 	}
 
 	return result, nil
@@ -313,6 +367,8 @@ func UploadAndSignWithBatch(ctx context.Context, params UploadParams) (*nostr.Ev
 		hash:      params.APKInfo.SHA256,
 		authEvent: nostr.BuildBlossomAuthEvent(params.APKInfo.SHA256, params.Pubkey, expiration),
 	})
+	uploads = append(uploads, collectSignatureUpload(params, expiration)...)
+	uploads = append(uploads, collectNotesImageUploads(params, expiration)...)
 
 	// Build main events
 	releaseNotes := params.Release.Changelog
@@ -325,26 +381,46 @@ func UploadAndSignWithBatch(ctx context.Context, params UploadParams) (*nostr.Ev
 	}
 
 	var releaseTimestamp time.Time
+	var tagName string
 	if params.Release != nil {
 		releaseTimestamp = params.Release.CreatedAt
+		tagName = params.Release.TagName
 	}
 
-	events := nostr.BuildEventSet(nostr.BuildEventSetParams{
+	events, err := nostr.BuildEventSet(nostr.BuildEventSetParams{
 		APKInfo:                   params.APKInfo,
 		Config:                    params.Cfg,
 		Pubkey:                    params.Pubkey,
 		OriginalURL:               params.OriginalURL,
 		BlossomServer:             params.BlossomServer,
+		BlossomPublicURL:          blossomPublicURL(params.Cfg, params.Opts),
 		IconURL:                   iconURL,
+		IconBlurhash:              iconBlurhash(iconUploads, params.Opts),
 		ImageURLs:                 imageURLs,
-		Changelog:                 releaseNotes,
+		Changelog:                 rewriteNotesImages(releaseNotes, params.NotesImages, params.BlossomServer, blossomPublicURL(params.Cfg, params.Opts)),
 		Variant:                   params.Variant,
 		Commit:                    params.Commit,
+		TagName:                   tagName,
 		Channel:                   params.Channel,
+		ChannelSuffix:             params.ChannelSuffix,
 		ReleaseTimestamp:          releaseTimestamp,
+		PublishedAt:               params.PublishedAt,
 		UseReleaseTimestampForApp: params.AppCreatedAtRelease,
+		ExistingApp:               params.ExistingApp,
+		ReplaceApp:                params.Opts != nil && params.Opts.Publish.OverwriteApp == "replace",
 		MinReleaseTimestamp:       params.MinReleaseTimestamp,
+		KeepAssets:                params.KeepAssets,
+		Platforms:                 params.Platforms,
+		Now:                       params.Now,
+		Provenance:                params.Provenance,
+		DetachedSignature:         signatureRef(params.DetachedSignature, params.BlossomServer),
+		CIBuild:                   ciBuild(params.Cfg),
+		Delegation:                params.Delegation,
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	showAppMetadataDiff(params.Opts, params.ExistingApp, events.AppMetadata)
 
 	// Pre-compute asset event IDs
 	for _, asset := range events.SoftwareAssets {
@@ -360,8 +436,11 @@ func UploadAndSignWithBatch(ctx context.Context, params UploadParams) (*nostr.Ev
 	}
 	allEvents = append(allEvents, events.AppMetadata, events.Release)
 	allEvents = append(allEvents, events.SoftwareAssets...)
+	if events.ReleaseNotes != nil {
+		allEvents = append(allEvents, events.ReleaseNotes)
+	}
 
-	// Pre-check existence for non-APK uploads
+	// Pre-check which blobs the server already has, including the APK
 	existsMap := checkUploadsExist(ctx, params.Client, uploads, params.Opts)
 
 	// Batch sign everything
@@ -420,6 +499,8 @@ func UploadWithIndividualSigning(ctx context.Context, params UploadParams) (icon
 			params.APKInfo.SHA256, params.Pubkey, expiration,
 		),
 	})
+	uploads = append(uploads, collectSignatureUpload(params, expiration)...)
+	uploads = append(uploads, collectNotesImageUploads(params, expiration)...)
 
 	// Sign each auth event individually
 	for _, u := range uploads {
@@ -431,7 +512,7 @@ func UploadWithIndividualSigning(ctx context.Context, params UploadParams) (icon
 		}
 	}
 
-	// Pre-check existence for non-APK uploads
+	// Pre-check which blobs the server already has, including the APK
 	existsMap := checkUploadsExist(ctx, params.Client, uploads, params.Opts)
 
 	return iconURL, imageURLs, &PendingUploads{
@@ -789,17 +870,17 @@ func uploadLocalImage(ctx context.Context, client *blossom.Client, signer nostr.
 
 // uploadAPK uploads the APK file.
 func uploadAPK(ctx context.Context, params UploadParams) error {
+	var size int64
+	if fileInfo, _ := os.Stat(params.APKPath); fileInfo != nil {
+		size = fileInfo.Size()
+	}
 	var tracker *ui.DownloadTracker
 	var uploadCallback func(uploaded, total int64)
 	if params.Opts.ShouldShowSpinners() {
-		fileInfo, _ := os.Stat(params.APKPath)
-		var size int64
-		if fileInfo != nil {
-			size = fileInfo.Size()
-		}
-		tracker = ui.NewDownloadTracker(fmt.Sprintf("Uploading APK to %s", params.Client.ServerURL()), size)
+		tracker = ui.NewUploadTracker(fmt.Sprintf("Uploading APK to %s", params.Client.ServerURL()), size)
 		uploadCallback = tracker.Callback()
 	}
+	uploadCallback = ui.TrackBytes("upload", filepath.Base(params.APKPath), size, uploadCallback)
 
 	result, err := params.Client.Upload(ctx, params.APKPath, params.APKInfo.SHA256, params.Signer, uploadCallback)
 	if err != nil {
@@ -961,26 +1042,69 @@ func collectImageUploads(ctx context.Context, params UploadParams, expiration ti
 	return imageURLs, uploads, nil
 }
 
-// checkUploadsExist checks which uploads already exist on the server.
+// collectSignatureUpload returns the upload of the APK's detached signature, if any.
+func collectSignatureUpload(params UploadParams, expiration time.Time) []uploadItem {
+	sig := params.DetachedSignature
+	if sig == nil {
+		return nil
+	}
+	return []uploadItem{{
+		data:       sig.Data,
+		hash:       sig.SHA256,
+		mimeType:   sig.MimeType,
+		authEvent:  nostr.BuildBlossomAuthEvent(sig.SHA256, params.Pubkey, expiration),
+		uploadType: "signature",
+	}}
+}
+
+// collectNotesImageUploads returns the uploads of the release notes images
+// (release_notes_event).
+func collectNotesImageUploads(params UploadParams, expiration time.Time) []uploadItem {
+	var uploads []uploadItem
+	for _, img := range params.NotesImages {
+		uploads = append(uploads, uploadItem{
+			data:       img.Data,
+			hash:       img.Hash,
+			mimeType:   img.MimeType,
+			authEvent:  nostr.BuildBlossomAuthEvent(img.Hash, params.Pubkey, expiration),
+			uploadType: "release notes image",
+		})
+	}
+	return uploads
+}
+
+// signatureRef returns the asset event's reference to a detached signature
+// stored on server, or nil without one.
+func signatureRef(sig *detachedsig.Signature, server string) *nostr.DetachedSignature {
+	if sig == nil {
+		return nil
+	}
+	return &nostr.DetachedSignature{
+		Type:   sig.Type,
+		URL:    fmt.Sprintf("%s/%s", server, sig.SHA256),
+		SHA256: sig.SHA256,
+	}
+}
+
+// checkUploadsExist checks which uploads already exist on the server, so blobs
+// it has (typically the APK when re-publishing identical bytes) are not sent again.
 func checkUploadsExist(ctx context.Context, client *blossom.Client, uploads []uploadItem, opts *cli.Options) map[string]bool {
-	var nonAPKHashes []string
+	var hashes []string
 	for _, u := range uploads {
-		if !u.isAPK {
-			nonAPKHashes = append(nonAPKHashes, u.hash)
-		}
+		hashes = append(hashes, u.hash)
 	}
 
-	if len(nonAPKHashes) == 0 {
+	if len(hashes) == 0 {
 		return nil
 	}
 
 	var spinner *ui.Spinner
 	if opts.ShouldShowSpinners() {
-		spinner = ui.NewSpinner(fmt.Sprintf("Checking %d files...", len(nonAPKHashes)))
+		spinner = ui.NewSpinner(fmt.Sprintf("Checking %d files...", len(hashes)))
 		spinner.Start()
 	}
 
-	existsMap := client.ExistsBatch(ctx, nonAPKHashes, 4)
+	existsMap := client.ExistsBatch(ctx, hashes, 4)
 
 	if spinner != nil {
 		existCount := 0
@@ -1002,62 +1126,140 @@ func checkUploadsExist(ctx context.Context, client *blossom.Client, uploads []up
 // performUploads performs the actual uploads after batch signing.
 func performUploads(ctx context.Context, client *blossom.Client, uploads []uploadItem, existsMap map[string]bool, opts *cli.Options) error {
 	for _, u := range uploads {
-		if u.isAPK {
-			var tracker *ui.DownloadTracker
-			var callback func(uploaded, total int64)
-			if opts.ShouldShowSpinners() {
-				fileInfo, _ := os.Stat(u.apkPath)
-				var size int64
-				if fileInfo != nil {
-					size = fileInfo.Size()
-				}
-				tracker = ui.NewDownloadTracker(fmt.Sprintf("Uploading APK to %s", client.ServerURL()), size)
-				callback = tracker.Callback()
+		if err := performUpload(ctx, client, u, existsMap, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UploadFailure is a blob that could not be uploaded.
+type UploadFailure struct {
+	Type  string // "APK", "signature", "icon", "screenshot" or "image"
+	Hash  string
+	IsAPK bool
+	Err   error
+}
+
+// ExecuteAll performs the uploads like Execute but continues past failures and
+// returns the blobs that failed. The error is non-nil only if ctx was cancelled.
+func (p *PendingUploads) ExecuteAll(ctx context.Context) ([]UploadFailure, error) {
+	var failures []UploadFailure
+	for _, u := range p.items {
+		if err := ctx.Err(); err != nil {
+			return failures, err
+		}
+		if err := performUpload(ctx, p.client, u, p.existsMap, p.opts); err != nil {
+			uploadType := u.uploadType
+			if u.isAPK {
+				uploadType = "APK"
 			}
+			failures = append(failures, UploadFailure{Type: uploadType, Hash: u.hash, IsAPK: u.isAPK, Err: err})
+		}
+	}
+	return failures, nil
+}
 
-			result, err := client.UploadWithAuth(ctx, u.apkPath, u.hash, u.authEvent, callback)
-			if err != nil {
-				return fmt.Errorf("failed to upload APK: %w", err)
+// performUpload uploads one item, skipping blobs the server already has.
+func performUpload(ctx context.Context, client *blossom.Client, u uploadItem, existsMap map[string]bool, opts *cli.Options) error {
+	if existsMap[u.hash] {
+		if opts.ShouldShowSpinners() {
+			name := u.uploadType
+			if u.isAPK {
+				name = "APK"
 			}
+			ui.PrintSuccess(fmt.Sprintf("%s already exists (%s/%s)", name, client.ServerURL(), u.hash))
+		}
+		return nil
+	}
 
-			if tracker != nil {
-				if result.Existed {
-					tracker.DoneWithMessage(fmt.Sprintf("APK already exists (%s)", result.URL))
-				} else {
-					tracker.Done()
-				}
+	if u.isAPK {
+		var size int64
+		if fileInfo, _ := os.Stat(u.apkPath); fileInfo != nil {
+			size = fileInfo.Size()
+		}
+		var tracker *ui.DownloadTracker
+		var callback func(uploaded, total int64)
+		if opts.ShouldShowSpinners() {
+			tracker = ui.NewUploadTracker(fmt.Sprintf("Uploading APK to %s", client.ServerURL()), size)
+			callback = tracker.Callback()
+		}
+		callback = ui.TrackBytes("upload", filepath.Base(u.apkPath), size, callback)
+
+		result, err := client.UploadWithAuth(ctx, u.apkPath, u.hash, u.authEvent, callback)
+		if err != nil {
+			return fmt.Errorf("failed to upload APK: %w", err)
+		}
+
+		if !result.Existed {
+			if fileInfo, err := os.Stat(u.apkPath); err == nil {
+				metrics.Add(metrics.UploadedBytes, float64(fileInfo.Size()))
 			}
-		} else {
-			existed := existsMap[u.hash]
-			if existed {
-				if opts.ShouldShowSpinners() {
-					ui.PrintSuccess(fmt.Sprintf("%s already exists (%s/%s)", u.uploadType, client.ServerURL(), u.hash))
-				}
+		}
+
+		if tracker != nil {
+			if result.Existed {
+				tracker.DoneWithMessage(fmt.Sprintf("APK already exists (%s)", result.URL))
 			} else {
-				var spinner *ui.Spinner
-				if opts.ShouldShowSpinners() {
-					spinner = ui.NewSpinner(fmt.Sprintf("Uploading %s...", u.uploadType))
-					spinner.Start()
-				}
+				tracker.Done()
+			}
+		}
+		return nil
+	}
 
-				_, err := client.UploadBytesWithAuthPreChecked(ctx, u.data, u.hash, u.mimeType, u.authEvent, false)
-				if err != nil {
-					if spinner != nil {
-						spinner.StopWithError(fmt.Sprintf("Failed to upload %s", u.uploadType))
-					}
-					return fmt.Errorf("failed to upload file: %w", err)
-				}
+	var spinner *ui.Spinner
+	if opts.ShouldShowSpinners() {
+		spinner = ui.NewSpinner(fmt.Sprintf("Uploading %s...", u.uploadType))
+		spinner.Start()
+	}
 
-				if spinner != nil {
-					spinner.StopWithSuccess(fmt.Sprintf("Uploaded %s", u.uploadType))
-				}
-			}
+	_, err := client.UploadBytesWithAuthPreChecked(ctx, u.data, u.hash, u.mimeType, u.authEvent, false)
+	if err != nil {
+		if spinner != nil {
+			spinner.StopWithError(fmt.Sprintf("Failed to upload %s", u.uploadType))
 		}
+		return fmt.Errorf("failed to upload file: %w", err)
 	}
+	metrics.Add(metrics.UploadedBytes, float64(len(u.data)))
 
+	if spinner != nil {
+		spinner.StopWithSuccess(fmt.Sprintf("Uploaded %s", u.uploadType))
+	}
 	return nil
 }
 
+// dropBlobTags removes every tag whose values mention one of the hashes, e.g. the
+// icon, image and imeta tags of blobs that failed to upload. It reports whether
+// any tag was removed.
+func dropBlobTags(event *gonostr.Event, hashes []string) bool {
+	kept := event.Tags[:0]
+	dropped := false
+	for _, tag := range event.Tags {
+		if tagMentionsAny(tag, hashes) {
+			dropped = true
+			continue
+		}
+		kept = append(kept, tag)
+	}
+	event.Tags = kept
+	return dropped
+}
+
+// tagMentionsAny reports whether any value of the tag (past its name) contains one of the hashes.
+func tagMentionsAny(tag gonostr.Tag, hashes []string) bool {
+	if len(tag) < 2 {
+		return false
+	}
+	for _, value := range tag[1:] {
+		for _, hash := range hashes {
+			if strings.Contains(value, hash) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // Helper functions
 
 func isRemoteURL(path string) bool {
@@ -1073,22 +1275,98 @@ func hasRemoteImages(images []string) bool {
 	return false
 }
 
-func countRemoteImages(images []string) int {
-	count := 0
+func findPreDownloadedImage(images []*DownloadedImage, url string) *DownloadedImage {
 	for _, img := range images {
-		if isRemoteURL(img) {
-			count++
+		if img.URL == url {
+			return img
 		}
 	}
-	return count
+	return nil
 }
 
-func findPreDownloadedImage(images []*DownloadedImage, url string) *DownloadedImage {
-	for _, img := range images {
-		if img.URL == url {
-			return img
+// imageProblem is a screenshot that would end up as a broken image tag.
+type imageProblem struct {
+	Image   string // as listed in cfg.Images
+	Problem string
+}
+
+// checkImages finds screenshots that would not render. Local paths must exist and
+// decode. Remote URLs that will be referenced as-is (already on the Blossom server
+// and not pre-downloaded) are probed. When reportFailedDownloads is set, remote
+// images that were not pre-downloaded are reported too.
+func checkImages(ctx context.Context, cfg *config.Config, preDownloaded *PreDownloadedImages, blossomURL string, reportFailedDownloads bool) []imageProblem {
+	client := newImageHTTPClient(15 * time.Second)
+	var problems []imageProblem
+	for _, img := range cfg.Images {
+		if !isRemoteURL(img) {
+			imgPath := resolvePath(img, cfg.BaseDir)
+			data, err := os.ReadFile(imgPath)
+			if err != nil {
+				problems = append(problems, imageProblem{img, "file not found"})
+				continue
+			}
+			if _, err := media.Preflight(data, detectImageMimeType(imgPath)); err != nil {
+				problems = append(problems, imageProblem{img, err.Error()})
+			}
+			continue
+		}
+		if preDownloaded != nil && findPreDownloadedImage(preDownloaded.Images, img) != nil {
+			continue
+		}
+		if isBlossomURL(img, blossomURL) {
+			if err := probeImageURLWithClient(ctx, img, client); err != nil {
+				problems = append(problems, imageProblem{img, err.Error()})
+			}
+			continue
+		}
+		if reportFailedDownloads {
+			problems = append(problems, imageProblem{img, "could not be downloaded"})
 		}
 	}
+	return problems
+}
+
+// probeImageURLWithClient checks that url answers 200 with an image, using HEAD and
+// falling back to a GET of the first bytes for servers that do not support HEAD.
+func probeImageURLWithClient(ctx context.Context, url string, client *http.Client) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	resp, err := source.DoWithTorFallback(ctx, client, req)
+	if err != nil {
+		return fmt.Errorf("unreachable: %w", err)
+	}
+	resp.Body.Close()
+
+	var head []byte
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %w", err)
+		}
+		req.Header.Set("Range", "bytes=0-511")
+		resp, err = source.DoWithTorFallback(ctx, client, req)
+		if err != nil {
+			return fmt.Errorf("unreachable: %w", err)
+		}
+		head, _ = io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusPartialContent {
+			resp.StatusCode = http.StatusOK
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("returned status %d", resp.StatusCode)
+	}
+
+	contentType := strings.TrimSpace(strings.SplitN(resp.Header.Get("Content-Type"), ";", 2)[0])
+	if (contentType == "" || contentType == "application/octet-stream") && len(head) > 0 {
+		contentType = http.DetectContentType(head)
+	}
+	if contentType != "" && contentType != "application/octet-stream" && !strings.HasPrefix(contentType, "image/") {
+		return fmt.Errorf("is not an image (%s)", contentType)
+	}
 	return nil
 }
 
@@ -1149,7 +1427,19 @@ func formatImageBytes(size int) string {
 const maxImageDownloadSize = 20 * 1024 * 1024
 
 func downloadRemoteImage(ctx context.Context, url string) (data []byte, hashStr string, mimeType string, err error) {
-	return downloadRemoteImageWithClient(ctx, url, &http.Client{Timeout: 60 * time.Second})
+	return downloadRemoteImageWithClient(ctx, url, newImageHTTPClient(60*time.Second))
+}
+
+// newImageHTTPClient returns the HTTP client of remote icon and screenshot
+// downloads and probes, which honors network_allowlist.
+func newImageHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: netpolicy.WrapTransport(&http.Transport{
+			TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+			Proxy:           http.ProxyFromEnvironment,
+		}),
+	}
 }
 
 func downloadRemoteImageWithClient(ctx context.Context, url string, client *http.Client) (data []byte, hashStr string, mimeType string, err error) {
@@ -1239,6 +1529,14 @@ func downloadAndPrepareImage(ctx context.Context, url, label string, opts *cli.O
 	if err != nil {
 		return nil, "", "", fmt.Errorf("failed to download %s: %w", label, err)
 	}
+	// Remote images are untrusted: check them before they end up in our events
+	declared := "application/octet-stream"
+	if u, parseErr := urlpkg.Parse(url); parseErr == nil {
+		declared = detectImageMimeType(u.Path)
+	}
+	if mimeType, err = media.Preflight(data, declared); err != nil {
+		return nil, "", "", fmt.Errorf("refusing %s from %s: %w", label, url, err)
+	}
 	maxWidth := media.ScreenshotMaxWidth
 	if label == "icon" {
 		maxWidth = media.IconMaxWidth
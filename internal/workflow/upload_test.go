@@ -5,9 +5,18 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/zapstore/zsp/internal/blossom"
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/netpolicy"
 	"github.com/zapstore/zsp/internal/source"
 )
 
@@ -160,3 +169,188 @@ func TestDownloadRemoteImageTorUnavailableOn403(t *testing.T) {
 		t.Fatalf("error = %v, want Tor unavailable message", err)
 	}
 }
+
+func TestProbeImageURL(t *testing.T) {
+	respond := func(status int, contentType, body string) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				Header:     http.Header{"Content-Type": []string{contentType}},
+				Request:    req,
+			}, nil
+		}
+	}
+	tests := []struct {
+		name    string
+		head    func(*http.Request) (*http.Response, error)
+		get     func(*http.Request) (*http.Response, error)
+		wantErr string
+	}{
+		{name: "image", head: respond(http.StatusOK, "image/png", "")},
+		{name: "not found", head: respond(http.StatusNotFound, "text/plain", ""), wantErr: "status 404"},
+		{name: "html page", head: respond(http.StatusOK, "text/html; charset=utf-8", ""), wantErr: "not an image"},
+		{
+			name: "HEAD unsupported, GET sniffs PNG",
+			head: respond(http.StatusMethodNotAllowed, "text/plain", ""),
+			get:  respond(http.StatusPartialContent, "application/octet-stream", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &http.Client{Transport: imageRoundTripper(func(req *http.Request) (*http.Response, error) {
+				if req.Method == http.MethodGet {
+					if req.Header.Get("Range") == "" {
+						t.Error("GET fallback without Range header")
+					}
+					return tt.get(req)
+				}
+				return tt.head(req)
+			})}
+			err := probeImageURLWithClient(context.Background(), "https://cdn.zapstore.dev/abc", client)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("probeImageURLWithClient() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("probeImageURLWithClient() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImageDownloadsHonorAllowlist(t *testing.T) {
+	netpolicy.SetAllowlist([]string{"relay.zapstore.dev"})
+	t.Cleanup(func() { netpolicy.SetAllowlist(nil) })
+
+	var blocked *netpolicy.BlockedError
+	_, _, _, err := downloadRemoteImage(context.Background(), "https://play-lh.googleusercontent.com/icon.png")
+	if !errors.As(err, &blocked) {
+		t.Errorf("downloadRemoteImage() error = %v, want a blocked connection", err)
+	}
+
+	err = probeImageURLWithClient(context.Background(), "https://cdn.zapstore.dev/abc.png", newImageHTTPClient(time.Second))
+	if !errors.As(err, &blocked) {
+		t.Errorf("probeImageURLWithClient() error = %v, want a blocked connection", err)
+	}
+}
+
+func TestCheckImagesLocalFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fake.png"), []byte("not an image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{BaseDir: dir, Images: []string{"missing.png", "fake.png"}}
+
+	problems := checkImages(context.Background(), cfg, nil, "https://cdn.zapstore.dev", false)
+	if len(problems) != 2 || problems[0].Image != "missing.png" || problems[1].Image != "fake.png" {
+		t.Errorf("checkImages() = %+v, want both local images reported", problems)
+	}
+}
+
+func TestPendingUploadsExecuteAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			if r.Header.Get("X-SHA-256") == "badhash" {
+				http.Error(w, "storage hiccup", http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := os.WriteFile(apkPath, []byte("apk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pending := &PendingUploads{
+		client: blossom.NewClient(server.URL),
+		items: []uploadItem{
+			{data: []byte("icon"), hash: "iconhash", uploadType: "icon", authEvent: &gonostr.Event{}},
+			{data: []byte("shot"), hash: "badhash", uploadType: "screenshot", authEvent: &gonostr.Event{}},
+			{isAPK: true, apkPath: apkPath, hash: "apkhash", authEvent: &gonostr.Event{}},
+		},
+		opts: &cli.Options{Publish: cli.PublishOptions{Quiet: true}},
+	}
+
+	failures, err := pending.ExecuteAll(context.Background())
+	if err != nil {
+		t.Fatalf("ExecuteAll() error = %v", err)
+	}
+	if len(failures) != 1 || failures[0].Hash != "badhash" || failures[0].Type != "screenshot" || failures[0].IsAPK {
+		t.Fatalf("failures = %+v, want only the screenshot", failures)
+	}
+	if !strings.Contains(failures[0].Err.Error(), "storage hiccup") {
+		t.Errorf("failure error = %v, want the server's reason", failures[0].Err)
+	}
+}
+
+func TestExistingAPKIsNotReuploaded(t *testing.T) {
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusOK) // the server already has every blob
+		case http.MethodPut:
+			puts++
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	apkPath := filepath.Join(t.TempDir(), "app.apk")
+	if err := os.WriteFile(apkPath, []byte("apk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	client := blossom.NewClient(server.URL)
+	opts := &cli.Options{Publish: cli.PublishOptions{Quiet: true}}
+	uploads := []uploadItem{{isAPK: true, apkPath: apkPath, hash: "apkhash", authEvent: &gonostr.Event{}}}
+
+	existsMap := checkUploadsExist(context.Background(), client, uploads, opts)
+	if !existsMap["apkhash"] {
+		t.Fatalf("checkUploadsExist() = %v, want the APK marked as existing", existsMap)
+	}
+	if err := performUploads(context.Background(), client, uploads, existsMap, opts); err != nil {
+		t.Fatalf("performUploads() error = %v", err)
+	}
+
+	// Without a pre-check the client still checks before sending
+	result, err := client.UploadWithAuth(context.Background(), apkPath, "apkhash", &gonostr.Event{}, nil)
+	if err != nil {
+		t.Fatalf("UploadWithAuth() error = %v", err)
+	}
+	if !result.Existed {
+		t.Error("UploadWithAuth() did not report the existing blob")
+	}
+	if puts != 0 {
+		t.Errorf("server received %d uploads, want none", puts)
+	}
+}
+
+func TestDropBlobTags(t *testing.T) {
+	event := &gonostr.Event{Tags: gonostr.Tags{
+		{"d", "com.example.app"},
+		{"icon", "https://cdn.zapstore.dev/iconhash"},
+		{"image", "https://cdn.zapstore.dev/goodhash"},
+		{"image", "https://cdn.zapstore.dev/badhash"},
+		{"imeta", "url https://cdn.zapstore.dev/iconhash", "blurhash LKO2"},
+	}}
+	if !dropBlobTags(event, []string{"badhash", "iconhash"}) {
+		t.Fatal("dropBlobTags() = false, want tags removed")
+	}
+	want := gonostr.Tags{{"d", "com.example.app"}, {"image", "https://cdn.zapstore.dev/goodhash"}}
+	if len(event.Tags) != len(want) {
+		t.Fatalf("tags = %v, want %v", event.Tags, want)
+	}
+	for i := range want {
+		if strings.Join(event.Tags[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("tag %d = %v, want %v", i, event.Tags[i], want[i])
+		}
+	}
+	if dropBlobTags(event, []string{"otherhash"}) {
+		t.Error("dropBlobTags() = true with no matching tags")
+	}
+}
@@ -0,0 +1,274 @@
+package workflow
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zapstore/zsp/internal/cli"
+	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/netpolicy"
+	"github.com/zapstore/zsp/internal/nostr"
+	"github.com/zapstore/zsp/internal/ui"
+)
+
+// Finding severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// reachabilityTimeout bounds each HEAD request made by ValidateConfig.
+const reachabilityTimeout = 10 * time.Second
+
+// Finding is one problem reported by ValidateConfig.
+type Finding struct {
+	Severity string `json:"severity"`        // SeverityError or SeverityWarning
+	Field    string `json:"field,omitempty"` // config field, e.g. "tags[2]" or "apps[Kiosk].match"
+	Message  string `json:"message"`
+}
+
+// ValidateConfigOptions controls ValidateConfig.
+type ValidateConfigOptions struct {
+	Offline bool         // Skip URL reachability checks
+	Client  *http.Client // Client for reachability checks (nil for the default)
+}
+
+// ValidateConfig checks a loaded config without fetching releases or using a
+// signer: config validation and pattern compilation, license, tag and NIP lint,
+// the limits the relay enforces on published fields, and unless Offline, that
+// configured URLs respond to a HEAD request. Each app of an apps: list is
+// checked as well. Findings are returned in a stable order.
+func ValidateConfig(ctx context.Context, cfg *config.Config, opts ValidateConfigOptions) []Finding {
+	findings := validateApp(cfg, "")
+	for _, app := range cfg.Apps {
+		findings = append(findings, validateApp(app, fmt.Sprintf("apps[%s]", app.Name))...)
+	}
+
+	if !opts.Offline {
+		client := opts.Client
+		if client == nil {
+			client = &http.Client{
+				Timeout: reachabilityTimeout,
+				Transport: netpolicy.WrapTransport(&http.Transport{
+					TLSClientConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+					Proxy:           http.ProxyFromEnvironment,
+				}),
+			}
+		}
+		findings = append(findings, checkReachability(ctx, client, reachabilityTargets(cfg))...)
+	}
+	return findings
+}
+
+// validateApp runs the offline checks on one config. prefix qualifies the
+// field names of an app from an apps: list.
+func validateApp(cfg *config.Config, prefix string) []Finding {
+	field := func(name string) string {
+		switch {
+		case prefix == "":
+			return name
+		case name == "":
+			return prefix
+		}
+		return prefix + "." + name
+	}
+
+	// Publishing translates glob-looking patterns before validating; do the same
+	cfg.NormalizePatterns()
+
+	var findings []Finding
+	for _, err := range cfg.ValidateAll() {
+		findings = append(findings, Finding{SeverityError, field(""), err.Error()})
+	}
+
+	var verr *nostr.ValidationError
+	if errors.As(nostr.ValidateConfig(cfg), &verr) {
+		for _, v := range verr.Violations {
+			findings = append(findings, Finding{SeverityError, field(v.Field), v.Problem})
+		}
+	}
+
+	for _, w := range cfg.Lint() {
+		findings = append(findings, Finding{SeverityWarning, field(w.Field), w.Message})
+	}
+	return findings
+}
+
+// reachabilityTarget is a configured URL checked with a HEAD request.
+type reachabilityTarget struct {
+	field    string
+	url      string
+	severity string // severity when the URL is missing or unreachable
+}
+
+// reachabilityTargets lists the remote URLs in cfg and its apps worth probing.
+// Release sources are errors when unreachable, display metadata only warnings.
+// URLs that fail validation are skipped since ValidateAll already reports them,
+// and a URL shared by several fields or apps is probed once.
+func reachabilityTargets(cfg *config.Config) []reachabilityTarget {
+	var targets []reachabilityTarget
+	seen := make(map[string]bool)
+	prefix := ""
+	add := func(field, rawURL, severity string) {
+		if rawURL == "" || seen[rawURL] || config.ValidateURL(rawURL) != nil {
+			return
+		}
+		seen[rawURL] = true
+		targets = append(targets, reachabilityTarget{prefix + field, rawURL, severity})
+	}
+
+	for i, c := range append([]*config.Config{cfg}, cfg.Apps...) {
+		if i > 0 {
+			prefix = fmt.Sprintf("apps[%s].", c.Name)
+		}
+		if c.NIP34Repo == nil {
+			add("repository", c.Repository, SeverityError)
+		}
+		if rs := c.ReleaseSource; rs != nil && !rs.IsLocal() {
+			add("release_source", rs.URL, SeverityError)
+			if rs.Version != nil {
+				add("release_source.version.url", rs.Version.URL, SeverityError)
+			}
+			if rs.Asset != nil {
+				add("release_source.asset.url", rs.Asset.URL, SeverityError)
+			}
+			if !rs.HasVersionPlaceholder() {
+				add("release_source.asset_url", rs.AssetURL, SeverityError)
+			}
+		}
+
+		add("website", c.Website, SeverityWarning)
+		add("icon", c.Icon, SeverityWarning)
+		for j, img := range c.Images {
+			add(fmt.Sprintf("images[%d]", j), img, SeverityWarning)
+		}
+		if strings.HasPrefix(c.ReleaseNotes, "https://") || strings.HasPrefix(c.ReleaseNotes, "http://") {
+			add("release_notes", c.ReleaseNotes, SeverityWarning)
+		}
+	}
+	return targets
+}
+
+// checkReachability sends a HEAD request to each target concurrently.
+// A missing resource (404, 410) or a failed request gets the target's
+// severity; other error statuses, which often mean the server rejects HEAD
+// or bots rather than that the URL is wrong, are warnings.
+func checkReachability(ctx context.Context, client *http.Client, targets []reachabilityTarget) []Finding {
+	results := make([]*Finding, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, target.url, nil)
+			if err != nil {
+				results[i] = &Finding{target.severity, target.field, err.Error()}
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				var urlErr *url.Error
+				if errors.As(err, &urlErr) {
+					err = urlErr.Err // the URL is already in the message
+				}
+				results[i] = &Finding{target.severity, target.field, fmt.Sprintf("%s is unreachable: %v", target.url, err)}
+				return
+			}
+			resp.Body.Close()
+
+			switch {
+			case resp.StatusCode < 400, resp.StatusCode == http.StatusMethodNotAllowed, resp.StatusCode == http.StatusNotImplemented:
+			case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusGone:
+				results[i] = &Finding{target.severity, target.field, fmt.Sprintf("%s returned %s", target.url, resp.Status)}
+			default:
+				results[i] = &Finding{SeverityWarning, target.field, fmt.Sprintf("%s returned %s", target.url, resp.Status)}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var findings []Finding
+	for _, f := range results {
+		if f != nil {
+			findings = append(findings, *f)
+		}
+	}
+	return findings
+}
+
+// CountFindings returns the number of errors and warnings in findings.
+func CountFindings(findings []Finding) (errs, warnings int) {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	return errs, warnings
+}
+
+// ValidateConfigFile checks a config file without fetching releases or touching
+// the signer and reports every finding, as one JSON object with --json. Returns
+// the exit code: 1 if there are errors, 130 if interrupted.
+func ValidateConfigFile(ctx context.Context, path string, opts *cli.Options) int {
+	var findings []Finding
+	cfg, err := config.Load(path)
+	if err != nil {
+		findings = append(findings, Finding{Severity: SeverityError, Message: err.Error()})
+	} else {
+		netpolicy.SetAllowlist(cfg.AllowedHosts())
+		findings = ValidateConfig(ctx, cfg, ValidateConfigOptions{Offline: opts.Config.Offline})
+	}
+	if ctx.Err() != nil {
+		return 130
+	}
+	errCount, warnCount := CountFindings(findings)
+
+	if opts.Global.JSON {
+		if findings == nil {
+			findings = []Finding{}
+		}
+		data, _ := json.Marshal(map[string]any{
+			"file":     path,
+			"valid":    errCount == 0,
+			"errors":   errCount,
+			"warnings": warnCount,
+			"findings": findings,
+		})
+		fmt.Println(string(data))
+	} else {
+		for _, f := range findings {
+			label := ui.Error("error  ")
+			if f.Severity == SeverityWarning {
+				label = ui.Warning("warning")
+			}
+			msg := ui.SanitizeErrorMessage(errors.New(f.Message))
+			if f.Field != "" {
+				msg = f.Field + ": " + msg
+			}
+			fmt.Printf("%s  %s\n", label, msg)
+		}
+		summary := fmt.Sprintf("%s: %d error(s), %d warning(s)", path, errCount, warnCount)
+		if errCount == 0 && warnCount == 0 {
+			summary = fmt.Sprintf("%s: valid", path)
+		}
+		if opts.Config.Offline {
+			summary += " (URLs not checked)"
+		}
+		fmt.Println(summary)
+	}
+
+	if errCount > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zapstore/zsp/internal/config"
+)
+
+func TestValidateConfigOffline(t *testing.T) {
+	cfg := &config.Config{
+		Repository: "https://github.com/acme/app",
+		Match:      "([",
+		Summary:    strings.Repeat("x", 600),
+		License:    "GNU GPL v3",
+		Tags:       []string{"tools", "#tools"},
+		Apps:       []*config.Config{{Name: "Kiosk", Repository: "https://github.com/acme/app", Variants: map[string]string{"x": "["}}},
+	}
+
+	findings := ValidateConfig(context.Background(), cfg, ValidateConfigOptions{Offline: true})
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Severity+" "+f.Field)
+	}
+	want := []string{
+		"error ",
+		"error summary",
+		"warning license",
+		"warning tags[1]",
+		"warning tags[1]",
+		"error apps[Kiosk]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+	if errs, warnings := CountFindings(findings); errs != 3 || warnings != 3 {
+		t.Errorf("CountFindings() = %d, %d, want 3, 3", errs, warnings)
+	}
+}
+
+func TestValidateConfigReachability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("method = %s, want HEAD", r.Method)
+		}
+		switch r.URL.Path {
+		case "/missing-repo", "/missing.png":
+			w.WriteHeader(http.StatusNotFound)
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Repository: server.URL + "/missing-repo",
+		Website:    server.URL + "/ok",
+		Icon:       server.URL + "/forbidden",
+		Images:     []string{server.URL + "/missing.png", "screenshots/local.png"},
+	}
+
+	findings := ValidateConfig(context.Background(), cfg, ValidateConfigOptions{Client: server.Client()})
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Severity+" "+f.Field)
+	}
+	want := []string{"error repository", "warning icon", "warning images[0]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findings = %v, want %v", got, want)
+	}
+}
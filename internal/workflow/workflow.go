@@ -2,30 +2,44 @@
 package workflow
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	gonostr "github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/zapstore/zsp/internal/apk"
 	"github.com/zapstore/zsp/internal/blossom"
 	"github.com/zapstore/zsp/internal/cli"
 	"github.com/zapstore/zsp/internal/config"
+	"github.com/zapstore/zsp/internal/detachedsig"
+	"github.com/zapstore/zsp/internal/history"
 	"github.com/zapstore/zsp/internal/identity"
 	"github.com/zapstore/zsp/internal/media"
+	"github.com/zapstore/zsp/internal/metrics"
+	"github.com/zapstore/zsp/internal/netclock"
 	"github.com/zapstore/zsp/internal/nostr"
 	"github.com/zapstore/zsp/internal/picker"
+	"github.com/zapstore/zsp/internal/publock"
 	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/tempdir"
 	"github.com/zapstore/zsp/internal/ui"
 )
 
@@ -39,8 +53,10 @@ type Publisher struct {
 
 	// Computed during workflow
 	release                  *source.Release
+	fetchedRelease           *source.Release // release as fetched, before the APK fills in a missing version
 	selectedAsset            *source.Asset
 	apkPath                  string
+	apkFromCache             bool // apkPath is a download cache entry
 	apkInfo                  *apk.APKInfo
 	iconURL                  string
 	imageURLs                []string
@@ -50,17 +66,60 @@ type Publisher struct {
 	pendingUploads           *PendingUploads
 	blossomURL               string
 	browserPort              int
-	existingReleaseTimestamp time.Time // created_at of existing 30063 on relay (for --overwrite-release)
+	browserPortFixed         bool                       // browserPort was chosen by the user, so the servers must not fall back to another
+	existingReleaseTimestamp time.Time                  // created_at of existing 30063 on relay (for --overwrite-release)
+	existingApp              *gonostr.Event             // publisher's existing 32267 on relay (for --overwrite-app=merge)
+	appChecked               bool                       // the relays answered the existingApp lookup, so nil means none exists
+	keepAssets               []*gonostr.Event           // assets of the published release kept alongside this APK (--only-new-assets)
+	relaysResolved           bool                       // publish relays already replaced via NIP-65 discovery
+	clockOffset              time.Duration              // network time minus local time, when the local clock is skewed
+	provenance               []nostr.MetadataProvenance // fetched metadata sources (metadata_provenance)
+	fingerprint              *Fingerprint               // this run's inputs and asset, recorded on success
+	published                bool                       // every target relay, or the --min-relay-success quorum, accepted the events
+	detachedSig              *detachedsig.Signature     // detached signature of the APK (detached_signature)
+	notesImages              []*DownloadedImage         // release notes images uploaded for release_notes_event
+	lock                     *publock.Lock              // held from APK parsing until Execute returns
+	relayInfos               []nostr.RelayInfoResult    // publish relays' NIP-11 documents, fetched before building the events
+	delegation               *nostr.Delegation          // NIP-26 delegation added to every event (--delegation)
+	curationSet              *nostr.CurationSet         // NIP-51 app curation set the app is added to (--add-to-set)
+	attested                 bool                       // the --ephemeral-key attestation was published (shared by PublishApps)
 }
 
 // NewPublisher creates a new publish workflow.
 func NewPublisher(ctx context.Context, opts *cli.Options, cfg *config.Config) (*Publisher, error) {
+	// --sign-only checks the Blossom server for the blobs, which --offline rules out
+	if opts.Publish.SignOnly && opts.Publish.Offline {
+		return nil, fmt.Errorf("--sign-only cannot be used with --offline")
+	}
+
+	var delegation *nostr.Delegation
+	if opts.Publish.Delegation != "" {
+		d, err := nostr.ParseDelegation(opts.Publish.Delegation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --delegation: %w", err)
+		}
+		delegation = d
+	}
+
+	var curationSet *nostr.CurationSet
+	if opts.Publish.AddToSet != "" {
+		if opts.Publish.Offline || opts.Publish.SignOnly {
+			return nil, fmt.Errorf("--add-to-set publishes to relays and cannot be used with --offline or --sign-only")
+		}
+		set, err := nostr.ParseCurationSet(opts.Publish.AddToSet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --add-to-set: %w", err)
+		}
+		curationSet = set
+	}
+
 	// Create source with base directory for relative paths
 	src, err := source.NewWithOptions(cfg, source.Options{
 		BaseDir:            cfg.BaseDir,
-		SkipCache:          opts.Publish.OverwriteRelease,
+		SkipCache:          opts.Publish.OverwriteRelease || opts.Publish.OnlyNewAssets || opts.Publish.EphemeralKey,
 		SkipDownloadCache:  opts.Publish.Quiet,
 		IncludePreReleases: opts.Publish.IncludePreReleases,
+		StrictRedirects:    opts.Publish.StrictRedirects,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create source: %w", err)
@@ -72,16 +131,16 @@ func NewPublisher(ctx context.Context, opts *cli.Options, cfg *config.Config) (*
 	bootstrapRelays := splitRelays(relaysEnv)
 
 	// BLOSSOM_URL env is an explicit operator override; takes precedence over
-	// anything resolved from a community event.
-	blossomEnv := config.GetEnv("BLOSSOM_URL")
-
-	blossomURL := blossomEnv
+	// the config's blossom_url, which in turn beats a community's server.
+	blossomURL := cmp.Or(config.GetEnv("BLOSSOM_URL"), cfg.BlossomURL)
 	var publisher *nostr.Publisher
 
 	// Resolve community infra from kind:10222 for any non-default community.
 	// Skip in offline mode: there is nothing to publish to, so knowing the
-	// community's relay and Blossom targets is not needed.
-	if !opts.Publish.Offline {
+	// community's relay and Blossom targets is not needed; nor with --sign-only,
+	// which contacts no relays. Skip with --dev too: a community's production
+	// relays must never replace the local dev relay.
+	if !opts.Publish.Offline && !opts.Publish.SignOnly && !opts.Publish.Dev {
 		communities := cfg.Communities
 		if len(communities) == 0 {
 			communities = []string{nostr.DefaultCommunity}
@@ -113,6 +172,10 @@ func NewPublisher(ctx context.Context, opts *cli.Options, cfg *config.Config) (*
 	if publisher == nil {
 		publisher = nostr.NewPublisherFromEnv(relaysEnv)
 	}
+	// relay_routes never apply to the local dev relay.
+	if !opts.Publish.Dev {
+		publisher.SetRoutes(relayRoutes(cfg))
+	}
 
 	// Fall back to the Zapstore CDN when nothing else provided a Blossom URL.
 	if blossomURL == "" {
@@ -120,14 +183,25 @@ func NewPublisher(ctx context.Context, opts *cli.Options, cfg *config.Config) (*
 	}
 
 	return &Publisher{
-		opts:       opts,
-		cfg:        cfg,
-		src:        src,
-		publisher:  publisher,
-		blossomURL: blossomURL,
+		opts:        opts,
+		cfg:         cfg,
+		src:         src,
+		publisher:   publisher,
+		blossomURL:  blossomURL,
+		delegation:  delegation,
+		curationSet: curationSet,
 	}, nil
 }
 
+// relayRoutes converts the config's relay_routes for the publisher.
+func relayRoutes(cfg *config.Config) []nostr.RelayRoute {
+	routes := make([]nostr.RelayRoute, 0, len(cfg.RelayRoutes))
+	for _, r := range cfg.RelayRoutes {
+		routes = append(routes, nostr.RelayRoute{Kinds: r.Kinds, Relays: r.Relays, Replace: r.Replace})
+	}
+	return routes
+}
+
 // splitRelays splits a comma-separated relay URL string into a slice.
 // Returns nil for an empty string.
 func splitRelays(env string) []string {
@@ -148,27 +222,31 @@ func splitRelays(env string) []string {
 func (p *Publisher) Execute(ctx context.Context) error {
 	// Determine total steps based on mode
 	totalSteps := 5
-	if p.opts.Publish.Offline {
+	if p.skipsRelays() {
 		totalSteps = 2
 	}
 
 	var steps *ui.StepTracker
 	if p.opts.ShouldShowSpinners() {
 		steps = ui.NewStepTracker(totalSteps)
+		// Repeat warnings in one section before the completion summary
+		ui.CollectWarnings()
 	}
 
 	// Step 1: Fetch assets
-	if steps != nil {
-		steps.StartStep("Fetch Assets")
-	}
+	startStep(steps, "Fetch Assets", ui.StageFetch)
 	if err := p.fetchAssets(ctx); err != nil {
 		return err
 	}
 
-	// Step 2: Gather metadata
-	if steps != nil {
-		steps.StartStep("Gather Metadata")
+	// One publish of a package at a time; a run that waited re-checks what the other published
+	if err := p.acquireLock(ctx); err != nil {
+		return err
 	}
+	defer p.releaseLock()
+
+	// Step 2: Gather metadata
+	startStep(steps, "Gather Metadata", ui.StageMetadata)
 	if err := p.gatherMetadata(ctx); err != nil {
 		return err
 	}
@@ -177,38 +255,85 @@ func (p *Publisher) Execute(ctx context.Context) error {
 	if err := p.handlePreview(ctx); err != nil {
 		return err
 	}
+	if p.opts.Publish.NoPreviewImages {
+		if err := p.prepareImages(ctx); err != nil {
+			return err
+		}
+	}
 
-	// Step 3: Sign (skip in offline mode)
-	if steps != nil && !p.opts.Publish.Offline {
-		steps.StartStep("Sign")
+	// Step 3: Sign (the last step in offline and sign-only mode)
+	if p.skipsRelays() {
+		ui.ProgressStage(ui.StageSign, "Sign")
+	} else {
+		startStep(steps, "Sign", ui.StageSign)
 	}
 	if err := p.signAndUpload(ctx); err != nil {
 		return err
 	}
 
+	// Add the APK to the local F-Droid repo (fdroid_repo_out)
+	if err := p.writeFDroidRepo(); err != nil {
+		return err
+	}
+
 	// Handle offline mode output
 	if p.isOffline() {
 		return p.outputOffline()
 	}
+	if p.opts.Publish.SignOnly {
+		return p.outputSignOnly()
+	}
 
 	// Handle npub signer: events are built with correct pubkey but unsigned, output for external signing
 	if p.signer != nil && p.signer.Type() == nostr.SignerNpub {
 		return p.outputNpubEvents()
 	}
 
-	// Step 4: Publish to relays
-	if steps != nil {
-		steps.StartStep("Publish")
+	// zsp uploads the blobs itself when publishing, so there is no manifest to hand off
+	if p.opts.Publish.ManifestJSON != "" {
+		p.warn("--manifest-json only applies with --offline or an npub signer; no manifest written")
+	}
+	if p.opts.Publish.BlossomAuthOut != "" {
+		p.warn("--blossom-auth-out only applies with an npub signer; no auth events written")
+	}
+
+	// With --partial-assets, blobs are uploaded first so the published events
+	// reference only the blobs that reached the server
+	if p.opts.Publish.PartialAssets {
+		startStep(steps, "Upload", ui.StageUpload)
+		if err := p.uploadBlobsPartial(ctx); err != nil {
+			return err
+		}
+		startStep(steps, "Publish", ui.StagePublish)
+		if err := p.publishToRelays(ctx); err != nil {
+			return err
+		}
+		p.recordFingerprint()
+		return nil
 	}
+
+	// Step 4: Publish to relays
+	startStep(steps, "Publish", ui.StagePublish)
 	if err := p.publishToRelays(ctx); err != nil {
 		return err
 	}
 
 	// Step 5: Upload blobs to Blossom
+	startStep(steps, "Upload", ui.StageUpload)
+	if err := p.uploadBlobs(ctx); err != nil {
+		return err
+	}
+	p.recordFingerprint()
+	return nil
+}
+
+// startStep shows the header of the next step, if steps are shown, and
+// reports the stage to --progress-json.
+func startStep(steps *ui.StepTracker, name, stage string) {
 	if steps != nil {
-		steps.StartStep("Upload")
+		steps.StartStep(name)
 	}
-	return p.uploadBlobs(ctx)
+	ui.ProgressStage(stage, name)
 }
 
 // fetchAssets fetches and selects the APK to publish.
@@ -217,12 +342,21 @@ func (p *Publisher) fetchAssets(ctx context.Context) error {
 		fmt.Printf("Source type: %s\n", p.src.Type())
 	}
 
-	// Fetch release
-	release, err := p.fetchRelease(ctx)
-	if err != nil {
+	// Fetch release (already set when PublishApps shares one fetch across apps)
+	if p.release == nil {
+		release, err := p.fetchRelease(ctx)
+		if err != nil {
+			return err
+		}
+		p.release = release
+		fetched := *release
+		p.fetchedRelease = &fetched
+	}
+
+	// Wait for releases that changed within min_release_age to settle
+	if err := p.checkReleaseAge(time.Now()); err != nil {
 		return err
 	}
-	p.release = release
 
 	// Select APK
 	asset, err := p.selectAPK(ctx)
@@ -231,8 +365,88 @@ func (p *Publisher) fetchAssets(ctx context.Context) error {
 	}
 	p.selectedAsset = asset
 
+	// Stop before downloading if nothing changed since the last publish
+	if err := p.checkFingerprint(); err != nil {
+		return err
+	}
+
 	// Download and parse APK
-	return p.downloadAndParseAPK(ctx)
+	if err := p.downloadAndParseAPK(ctx); err != nil {
+		return err
+	}
+
+	if err := p.applyBuildInfo(); err != nil {
+		return err
+	}
+
+	return p.signDetached(ctx)
+}
+
+// signDetached makes the detached signature of the APK configured by
+// detached_signature. It runs before anything is uploaded, so a missing or
+// wrong key stops the run early.
+func (p *Publisher) signDetached(ctx context.Context) error {
+	sigCfg := p.cfg.DetachedSignature
+	if sigCfg == nil {
+		return nil
+	}
+	opts := detachedsig.Options{
+		Type:     sigCfg.Type,
+		Key:      config.GetEnv(sigCfg.KeyEnv),
+		KeyEnv:   sigCfg.KeyEnv,
+		Filename: filepath.Base(p.apkPath),
+	}
+	if sigCfg.PasswordEnv != "" {
+		opts.Password = config.GetEnv(sigCfg.PasswordEnv)
+	}
+
+	sig, err := detachedsig.Sign(ctx, p.apkPath, opts)
+	if err != nil {
+		if errors.Is(err, detachedsig.ErrNoKey) {
+			return fmt.Errorf("detached_signature: %w (set it to the %s key, or remove detached_signature from the config)", err, sigCfg.Type)
+		}
+		return fmt.Errorf("detached_signature: %w", err)
+	}
+	p.detachedSig = sig
+	if p.opts.Global.Verbose {
+		fmt.Printf("Signed APK with %s (%s%s, %s)\n", sig.Type, filepath.Base(p.apkPath), sig.Ext, sig.SHA256)
+	}
+	return nil
+}
+
+// applyBuildInfo fills repository and commit from source provenance embedded in the APK
+// (F-Droid buildinfo, Built-From, buildinfo.json) when no repository is configured.
+// Interactive runs confirm first; otherwise the discovered values are used and
+// logged to stderr unless --quiet.
+func (p *Publisher) applyBuildInfo() error {
+	info := p.apkInfo.BuildInfo
+	if info == nil || p.cfg.Repository != "" {
+		return nil
+	}
+
+	if p.opts.IsInteractive() {
+		ui.PrintSectionHeader("Source Found in APK")
+		ui.PrintKeyValue("Repository", info.Repository)
+		if info.Commit != "" {
+			ui.PrintKeyValue("Commit", info.Commit)
+		}
+		ui.PrintKeyValue("From", info.Entry)
+		confirmed, err := ui.Confirm("Use this repository?", true)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	} else {
+		p.status("notice: using repository %s from APK entry %s", info.Repository, info.Entry)
+	}
+
+	p.cfg.Repository = info.Repository
+	if p.opts.Publish.Commit == "" {
+		p.opts.Publish.Commit = info.Commit
+	}
+	return nil
 }
 
 // fetchRelease fetches the latest release with spinner feedback.
@@ -240,6 +454,9 @@ func (p *Publisher) fetchRelease(ctx context.Context) (*source.Release, error) {
 	if p.opts.Publish.Offline && p.src.Type() != config.SourceLocal {
 		return nil, fmt.Errorf("--offline requires a local APK path; remote sources (%s) make network calls", p.src.Type())
 	}
+	if p.opts.Publish.PickRelease {
+		return p.pickRelease(ctx)
+	}
 
 	release, err := WithSpinner(p.opts, "Fetching release info...", func() (*source.Release, error) {
 		return p.src.FetchLatestRelease(ctx)
@@ -266,6 +483,12 @@ func (p *Publisher) fetchRelease(ctx context.Context) (*source.Release, error) {
 		return nil, fmt.Errorf("failed to fetch release: %w", err)
 	}
 
+	if reporter, ok := p.src.(source.RedirectHostReporter); ok {
+		if change := reporter.RedirectHostChange(); change != nil {
+			p.warn(fmt.Sprintf("%v (the new host is pinned after this publish; --strict-redirects fails instead)", change))
+		}
+	}
+
 	if p.opts.ShouldShowSpinners() {
 		if release.Version != "" {
 			ui.PrintSuccess(fmt.Sprintf("Found release %s with %d assets", release.Version, len(release.Assets)))
@@ -277,6 +500,40 @@ func (p *Publisher) fetchRelease(ctx context.Context) (*source.Release, error) {
 	return release, nil
 }
 
+// pickReleaseCount is how many recent releases --pick-release offers.
+const pickReleaseCount = 20
+
+// pickRelease lets the user choose the release to publish from the source's
+// recent releases (--pick-release). The source's release cache is not touched,
+// so a later run still sees the latest release as new.
+func (p *Publisher) pickRelease(ctx context.Context) (*source.Release, error) {
+	lister, ok := p.src.(source.ReleaseLister)
+	if !ok {
+		return nil, fmt.Errorf("--pick-release is not supported for %s sources", p.src.Type())
+	}
+	if !p.opts.IsInteractive() {
+		return nil, fmt.Errorf("--pick-release needs an interactive terminal")
+	}
+
+	releases, err := WithSpinner(p.opts, "Fetching recent releases...", func() ([]*source.Release, error) {
+		return lister.ListReleases(ctx, pickReleaseCount)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no recent releases with valid APKs")
+	}
+
+	idx, err := ui.SelectOption("Select release to publish", releaseOptions(releases), 0)
+	if err != nil {
+		return nil, err
+	}
+	release := releases[idx]
+	ui.PrintSuccess(fmt.Sprintf("Selected release %s with %d assets", release.Version, len(release.Assets)))
+	return release, nil
+}
+
 // selectAPK filters and selects the best APK from the release.
 func (p *Publisher) selectAPK(ctx context.Context) (*source.Asset, error) {
 	// Filter to APKs only
@@ -302,6 +559,17 @@ func (p *Publisher) selectAPK(ctx context.Context) (*source.Asset, error) {
 		return nil, fmt.Errorf("no APK files found in release")
 	}
 
+	// Check variant patterns against every APK: an APK matching two variants
+	// cannot be tagged, and a variant matching none has likely gone stale
+	if len(p.cfg.Variants) > 0 {
+		if _, err := picker.AssetVariants(apkAssets, p.cfg.Variants); err != nil {
+			return nil, err
+		}
+		for _, name := range picker.UnmatchedVariants(apkAssets, p.cfg.Variants) {
+			p.warn(fmt.Sprintf("variant %q pattern %q matches no APK in release %s", name, p.cfg.Variants[name], p.release.Version))
+		}
+	}
+
 	// Apply match filter if specified
 	if p.cfg.Match != "" {
 		var err error
@@ -314,6 +582,29 @@ func (p *Publisher) selectAPK(ctx context.Context) (*source.Asset, error) {
 		}
 	}
 
+	// Apply label filter if specified
+	if p.cfg.MatchLabel != "" {
+		apkAssets = picker.FilterByLabel(apkAssets, p.cfg.MatchLabel)
+		if len(apkAssets) == 0 {
+			return nil, fmt.Errorf("no APK files match label: %s", p.cfg.MatchLabel)
+		}
+	}
+
+	// Apply variant filter if specified
+	if p.opts.Publish.Variant != "" {
+		pattern, err := p.cfg.VariantPattern(p.opts.Publish.Variant)
+		if err != nil {
+			return nil, err
+		}
+		apkAssets, _, err = picker.PartitionByVariant(apkAssets, p.opts.Publish.Variant, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(apkAssets) == 0 {
+			return nil, fmt.Errorf("no APK files match variant %s: %s", p.opts.Publish.Variant, pattern)
+		}
+	}
+
 	// Single APK - use it
 	if len(apkAssets) == 1 {
 		if p.opts.ShouldShowSpinners() {
@@ -354,17 +645,24 @@ func (p *Publisher) downloadAndParseAPK(ctx context.Context) error {
 		return err
 	}
 
-	// Parse APK
-	p.apkInfo, err = WithSpinner(p.opts, "Parsing APK...", func() (*apk.APKInfo, error) {
-		return apk.Parse(p.apkPath)
-	})
+	// Parse APK; a cached copy that fails is downloaded once more
+	p.apkInfo, err = p.parseAPK()
+	if err != nil && p.apkFromCache {
+		p.apkInfo, err = p.rescueCachedAPK(ctx, err)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to parse APK: %w", err)
+		return err
+	}
+	if p.opts.Publish.IconDensity != "" && p.apkInfo.IconDensity == "" {
+		p.warn(fmt.Sprintf("No %s icon raster in the APK, using the automatically picked icon", p.opts.Publish.IconDensity))
 	}
 
 	if p.apkInfo.IsWatch() {
 		return fmt.Errorf("Wear OS/watch APKs are not supported")
 	}
+	for _, conflict := range p.apkInfo.DeviceConflicts() {
+		p.warn("no device can install this APK: " + conflict)
+	}
 
 	// Verify arm64 support
 	if !p.apkInfo.IsArm64() {
@@ -375,6 +673,10 @@ func (p *Publisher) downloadAndParseAPK(ctx context.Context) error {
 		ui.PrintSuccess("Parsed and verified APK")
 	}
 
+	if err := p.checkV1OnlySignature(); err != nil {
+		return err
+	}
+
 	// Backfill version from APK if not known from release
 	if p.release.Version == "" {
 		p.release.Version = p.apkInfo.VersionName
@@ -394,12 +696,48 @@ func (p *Publisher) downloadAndParseAPK(ctx context.Context) error {
 		ui.PrintKeyValue("App ID", p.apkInfo.PackageID)
 		ui.PrintKeyValue("Version", fmt.Sprintf("%s (%d)", p.apkInfo.VersionName, p.apkInfo.VersionCode))
 		ui.PrintKeyValue("Certificate hash", p.apkInfo.CertFingerprint)
+		if n := len(p.apkInfo.CertLineage); n > 1 {
+			ui.PrintKeyValue("Rotated from", strings.Join(p.apkInfo.CertLineage[:n-1], ", "))
+		}
+		ui.PrintKeyValue("Signature schemes", strings.Join(p.apkInfo.SignatureSchemes, ", "))
 		ui.PrintKeyValue("Size", fmt.Sprintf("%.2f MB", float64(p.apkInfo.FileSize)/(1024*1024)))
 	}
 
 	return nil
 }
 
+// checkV1OnlySignature guards against publishing an APK with no v2/v3 signature, which
+// most current devices will refuse to install. Interactive runs ask for confirmation;
+// otherwise --allow-v1-only is required.
+func (p *Publisher) checkV1OnlySignature() error {
+	if !p.apkInfo.IsV1OnlySigned() {
+		return nil
+	}
+	if p.opts.Publish.AllowV1Only {
+		if p.opts.ShouldShowSpinners() {
+			ui.PrintWarning("APK is signed only with the legacy v1 (JAR) scheme; it may not install on Android 11+")
+		}
+		return nil
+	}
+	if p.opts.Publish.Dev {
+		p.warn(apk.ErrV1OnlySignature.Error())
+		return nil
+	}
+	if !p.opts.IsInteractive() {
+		return fmt.Errorf("%w; re-sign it with apksigner (v2/v3) or pass --allow-v1-only", apk.ErrV1OnlySignature)
+	}
+
+	ui.PrintWarning(apk.ErrV1OnlySignature.Error())
+	confirmed, err := ui.Confirm("Publish anyway? Most Zapstore users will not be able to install it", false)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		return fmt.Errorf("aborted: %w", apk.ErrV1OnlySignature)
+	}
+	return nil
+}
+
 // getAPKPath returns the local path to the APK, downloading if necessary.
 func (p *Publisher) getAPKPath(ctx context.Context) (string, error) {
 	if p.selectedAsset.LocalPath != "" {
@@ -414,13 +752,18 @@ func (p *Publisher) getAPKPath(ctx context.Context) (string, error) {
 		_ = source.DeleteCachedDownload(p.selectedAsset.URL, p.selectedAsset.Name)
 	} else if cachedPath := source.GetCachedDownload(p.selectedAsset.URL, p.selectedAsset.Name); cachedPath != "" {
 		p.selectedAsset.LocalPath = cachedPath
+		p.apkFromCache = true
 		if p.opts.ShouldShowSpinners() {
 			ui.PrintSuccess("Using cached APK")
 		}
 		return cachedPath, nil
 	}
 
-	// Download
+	return p.downloadAPK(ctx)
+}
+
+// downloadAPK downloads the selected asset with progress feedback.
+func (p *Publisher) downloadAPK(ctx context.Context) (string, error) {
 	if p.opts.Global.Verbose {
 		fmt.Printf("  Download URL: %s\n", p.selectedAsset.URL)
 	}
@@ -431,8 +774,11 @@ func (p *Publisher) getAPKPath(ctx context.Context) (string, error) {
 		tracker = ui.NewDownloadTracker(fmt.Sprintf("Downloading %s", p.selectedAsset.Name), p.selectedAsset.Size)
 		progressCallback = tracker.Callback()
 	}
+	progressCallback = ui.TrackBytes("download", p.selectedAsset.Name, p.selectedAsset.Size, progressCallback)
 
+	downloadStart := time.Now()
 	apkPath, err := p.src.Download(ctx, p.selectedAsset, "", progressCallback)
+	metrics.Since(metrics.StageDownload, downloadStart)
 	if tracker != nil {
 		tracker.Done()
 	}
@@ -443,14 +789,150 @@ func (p *Publisher) getAPKPath(ctx context.Context) (string, error) {
 	return apkPath, nil
 }
 
+// checkReleaseAge returns ErrNothingToDo when the release was published, or had
+// an asset uploaded, more recently than min_release_age before now, so that a
+// scheduled run publishes it once it has settled. Skipped with --ignore-release-age.
+func (p *Publisher) checkReleaseAge(now time.Time) error {
+	window, err := p.cfg.MinReleaseAgeDuration()
+	if err != nil {
+		return err
+	}
+	if window == 0 || p.opts.Publish.IgnoreReleaseAge {
+		return nil
+	}
+
+	changed := p.release.LastChangedAt()
+	if changed.IsZero() {
+		p.warn("min_release_age is set but the source reports no release or asset dates; publishing anyway")
+		return nil
+	}
+	eligible := changed.Add(window)
+	if !now.Before(eligible) {
+		return nil
+	}
+
+	if p.opts.ShouldShowSpinners() {
+		ui.PrintInfo(fmt.Sprintf("Release %s changed %s ago, within min_release_age (%s)",
+			p.release.Version, now.Sub(changed).Round(time.Second), p.cfg.MinReleaseAge))
+		fmt.Printf("  It can be published after %s. Use --ignore-release-age to publish now.\n",
+			eligible.Local().Format("2006-01-02 15:04:05 MST"))
+	}
+	return ErrNothingToDo
+}
+
+// checkFingerprint returns ErrNothingToDo, before the APK is downloaded, when the
+// last successful publish had the same inputs and release asset. It is skipped
+// with --overwrite-release, --overwrite-app=replace, --offline, when the signer
+// is chosen interactively, and with --ephemeral-key, whose test publishes must
+// not stand in for (or be stood in for by) the SIGN_WITH identity's.
+func (p *Publisher) checkFingerprint() error {
+	if p.opts.Publish.OverwriteRelease || p.opts.Publish.OverwriteApp == "replace" || p.skipsRelays() || p.opts.Publish.EphemeralKey {
+		return nil
+	}
+	signWith := config.GetEnv("SIGN_WITH")
+	if signWith == "" {
+		return nil
+	}
+
+	inputs, err := fingerprintInputs(p.cfg, p.release, &p.opts.Publish, signerIdentity(signWith), p.publisher.RelayURLs(), p.blossomURL)
+	if err != nil {
+		return nil
+	}
+	asset, err := assetIdentity(p.selectedAsset)
+	if err != nil {
+		return nil
+	}
+	p.fingerprint = &Fingerprint{Inputs: inputs, Asset: asset}
+
+	fingerprints, err := loadFingerprints(fingerprintPath())
+	if err != nil {
+		if p.opts.Global.Verbose {
+			fmt.Fprintf(os.Stderr, "  Could not check last publish: %v\n", err)
+		}
+		return nil
+	}
+	last, ok := fingerprints[inputs]
+	if !ok || last.Asset != asset {
+		return nil
+	}
+
+	if p.opts.ShouldShowSpinners() {
+		ui.PrintInfo(fmt.Sprintf("Nothing changed since last publish (%s@%s on %s)",
+			last.PackageID, last.Version, last.PublishedAt.Local().Format("2006-01-02 15:04")))
+		fmt.Println("  Use --overwrite-release to publish anyway.")
+	}
+	return ErrNothingToDo
+}
+
+// acquireLock takes the publish lock of the APK's package, so that two runs
+// triggered together do not race on the cache, relays and Blossom. Without
+// --wait-lock a held lock fails the run with publock.ErrLocked. After waiting,
+// the fingerprint is checked again, and the relay checks run later as usual,
+// so a run queued behind a successful publish of the same release does nothing.
+// Offline and sign-only runs publish nothing and take no lock.
+func (p *Publisher) acquireLock(ctx context.Context) error {
+	if p.skipsRelays() {
+		return nil
+	}
+	packageID := p.apkInfo.PackageID
+	lock, waited, err := publock.Acquire(ctx, publock.Dir(), packageID, p.opts.Publish.WaitLock, func(owner publock.Owner) {
+		if p.opts.ShouldShowSpinners() {
+			ui.PrintInfo(fmt.Sprintf("Waiting up to %s for another publish of %s to finish (%s)", p.opts.Publish.WaitLock, packageID, owner))
+		}
+	})
+	if err != nil {
+		if errors.Is(err, publock.ErrLocked) && !waited {
+			return fmt.Errorf("%s: %w; use --wait-lock to wait for it", packageID, err)
+		}
+		return fmt.Errorf("%s: %w", packageID, err)
+	}
+	p.lock = lock
+	if waited {
+		return p.checkFingerprint()
+	}
+	return nil
+}
+
+// releaseLock releases the publish lock, if held.
+func (p *Publisher) releaseLock() {
+	if err := p.lock.Release(); err != nil && p.opts.Global.Verbose {
+		fmt.Fprintf(os.Stderr, "  Could not release publish lock: %v\n", err)
+	}
+	p.lock = nil
+}
+
+// recordFingerprint saves this run's fingerprint after a successful publish.
+// A run some relays did not accept records nothing, so a retry reaches them.
+func (p *Publisher) recordFingerprint() {
+	if p.fingerprint == nil || !p.published {
+		return
+	}
+	fp := *p.fingerprint
+	fp.APKSHA256 = p.apkInfo.SHA256
+	fp.PackageID = p.apkInfo.PackageID
+	fp.Version = p.apkInfo.VersionName
+	fp.PublishedAt = time.Now()
+	if err := saveFingerprint(fingerprintPath(), fp); err != nil && p.opts.Global.Verbose {
+		fmt.Printf("  Could not record publish fingerprint: %v\n", err)
+	}
+}
+
 // checkExistingAsset checks if the release already exists on relays for this publisher.
 // pubkey must be the hex public key of the signer so the query is scoped to their events only.
 func (p *Publisher) checkExistingAsset(ctx context.Context, pubkey string) error {
-	if p.opts.Publish.OverwriteRelease || p.opts.Publish.Offline {
+	if p.opts.Publish.OnlyNewAssets {
+		return p.checkNewAsset(ctx, pubkey)
+	}
+	if p.opts.Publish.OverwriteRelease || p.skipsRelays() {
 		return nil
 	}
 
 	existingAsset, err := p.publisher.CheckExistingAsset(ctx, pubkey, p.apkInfo.PackageID, p.apkInfo.VersionName)
+	if errors.Is(err, nostr.ErrExistenceUnknown) {
+		p.warn(fmt.Sprintf("could not rule out that %s@%s is already published (%v); publishing anyway",
+			p.apkInfo.PackageID, p.apkInfo.VersionName, err))
+		return nil
+	}
 	if err != nil {
 		if p.opts.Global.Verbose {
 			fmt.Fprintf(os.Stderr, "  Could not check relays: %v\n", err)
@@ -470,6 +952,189 @@ func (p *Publisher) checkExistingAsset(ctx context.Context, pubkey string) error
 	return nil
 }
 
+// checkNewAsset implements --only-new-assets: the release for this version is
+// fetched and, unless it already references an asset with this APK's hash
+// (ErrNothingToDo), re-published referencing its assets plus this APK's new
+// asset event. Unchanged assets are neither re-signed nor re-uploaded.
+func (p *Publisher) checkNewAsset(ctx context.Context, pubkey string) error {
+	if p.skipsRelays() {
+		return fmt.Errorf("--only-new-assets needs the published release from relays and cannot be used with --offline or --sign-only")
+	}
+
+	release, err := p.publisher.FetchRelease(ctx, pubkey, p.appIdentifier(), p.apkInfo.VersionName)
+	if err != nil {
+		return fmt.Errorf("--only-new-assets: could not fetch the published release: %w", err)
+	}
+	if release == nil {
+		return nil // The first asset of the release
+	}
+	if release.HasAsset(p.apkInfo.SHA256) {
+		if p.opts.ShouldShowSpinners() {
+			ui.PrintWarning(fmt.Sprintf("Release %s@%s already references this APK (--only-new-assets)",
+				p.apkInfo.PackageID, p.apkInfo.VersionName))
+		}
+		return ErrNothingToDo
+	}
+
+	referenced := 0
+	for _, tag := range release.Event.Tags {
+		if len(tag) >= 2 && tag[0] == "e" {
+			referenced++
+		}
+	}
+	if missing := referenced - len(release.Assets); missing > 0 {
+		p.warn(fmt.Sprintf("%d asset(s) referenced by the published release were not found on relays and will be dropped from it", missing))
+	}
+	p.keepAssets = release.Assets
+	p.existingReleaseTimestamp = release.Event.CreatedAt.Time()
+	if p.opts.ShouldShowSpinners() {
+		ui.PrintInfo(fmt.Sprintf("Adding this APK to the published release, which keeps its %d asset(s) (--only-new-assets)", len(release.Assets)))
+	}
+	return nil
+}
+
+// checkAppAuthor fails, unless --allow-different-author is set, when relays
+// list the app under a pubkey other than the signer's and the signer has no app
+// event of its own for it: publishing would split the app across two authors,
+// usually because SIGN_WITH holds the wrong key.
+func (p *Publisher) checkAppAuthor(ctx context.Context, pubkey string) error {
+	if p.skipsRelays() {
+		return nil
+	}
+
+	identifier := p.appIdentifier()
+	existing, err := p.publisher.CheckExistingApp(ctx, identifier)
+	if err != nil || existing == nil || existing.Pubkey == pubkey || p.delegatedBy(existing.Pubkey) {
+		if err != nil && p.opts.Global.Verbose {
+			fmt.Fprintf(os.Stderr, "  Could not check the app's author: %v\n", err)
+		}
+		return nil
+	}
+	if own, err := p.publisher.FetchAppMetadata(ctx, pubkey, identifier); err == nil && own != nil {
+		return nil // Already published by this signer too
+	}
+
+	msg := fmt.Sprintf("%s is already published on %s by %s, not by the signer %s",
+		identifier, existing.RelayURL, nostr.NpubOrHex(existing.Pubkey), nostr.NpubOrHex(pubkey))
+	if !p.opts.Publish.AllowDifferentAuthor {
+		return fmt.Errorf("%s; check SIGN_WITH, or pass --allow-different-author to publish a separate entry under this key", msg)
+	}
+	p.warn(msg + "; publishing a separate entry under this key (--allow-different-author)")
+	return nil
+}
+
+// delegatedBy reports whether the events are published on pubkey's behalf (--delegation).
+func (p *Publisher) delegatedBy(pubkey string) bool {
+	return p.delegation != nil && p.delegation.Delegator == pubkey
+}
+
+// checkVersionCodes warns, or fails with --strict-versioning, when the APK's
+// versionCode is not above every versionCode already published for the package
+// on any channel. Android only installs updates with a higher versionCode, so a
+// lower code on another channel strands users who switch to it.
+func (p *Publisher) checkVersionCodes(ctx context.Context, pubkey string) error {
+	if p.skipsRelays() {
+		return nil
+	}
+
+	codes, err := p.publisher.FetchVersionCodesByChannel(ctx, pubkey, p.apkInfo.PackageID)
+	if err != nil && p.opts.Global.Verbose {
+		fmt.Fprintf(os.Stderr, "  Could not check all published version codes: %v\n", err)
+	}
+	// A cut-short history still shows the conflicts it holds
+	if err != nil && !errors.Is(err, nostr.ErrHistoryIncomplete) {
+		return nil
+	}
+
+	var conflicts []string
+	for _, published := range codes {
+		// Re-publishing the same version (--overwrite-release) is not a downgrade
+		if published.Version == p.apkInfo.VersionName || published.VersionCode < p.apkInfo.VersionCode {
+			continue
+		}
+		channel := published.Channel
+		if channel == "" {
+			channel = "unknown channel"
+		}
+		conflicts = append(conflicts, fmt.Sprintf("%s %s (code %d)", channel, published.Version, published.VersionCode))
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	sort.Strings(conflicts)
+
+	channel := p.opts.Publish.Channel
+	if channel == "" {
+		channel = "main"
+	}
+	msg := fmt.Sprintf("versionCode %d (%s on %s) is not higher than already published %s. "+
+		"Android only updates to a higher versionCode: users with those versions installed will not get this release, "+
+		"and switching channels would require uninstalling",
+		p.apkInfo.VersionCode, p.apkInfo.VersionName, channel, strings.Join(conflicts, ", "))
+	if p.opts.Publish.StrictVersioning {
+		return fmt.Errorf("%s (--strict-versioning)", msg)
+	}
+	p.warn(msg)
+	return nil
+}
+
+// checkCertChange fails, unless --confirm-cert-change is set, when the APK is
+// signed by a different certificate than the signer's last published asset for
+// the package. A rotation through the v3 signing lineage, which vouches for the
+// new key with the old one, passes. Whether the new certificate has a NIP-C1
+// identity proof by the signer is included in the message.
+func (p *Publisher) checkCertChange(ctx context.Context, pubkey string) error {
+	if p.skipsRelays() || p.apkInfo.CertFingerprint == "" {
+		return nil
+	}
+
+	published, err := p.publisher.FetchLatestAssetCert(ctx, pubkey, p.apkInfo.PackageID)
+	if err != nil {
+		if p.opts.Global.Verbose {
+			fmt.Fprintf(os.Stderr, "  Could not check the published signing certificate: %v\n", err)
+		}
+		return nil
+	}
+	current := strings.ToLower(p.apkInfo.CertFingerprint)
+	if published == nil || published.CertHash == current {
+		return nil
+	}
+	if slices.Contains(p.apkInfo.CertLineage, published.CertHash) {
+		if p.opts.ShouldShowSpinners() {
+			ui.PrintInfo(fmt.Sprintf("Signing key rotated since %s (APK Signature Scheme v3 lineage)", published.Version))
+		}
+		return nil
+	}
+
+	msg := fmt.Sprintf("APK signing certificate %s differs from %s of the last published version %s; "+
+		"Android will not install this APK over an installed earlier version", current, published.CertHash, published.Version)
+	if p.certLinked(ctx, pubkey, current) {
+		msg += ". The new certificate is linked to your Nostr identity (NIP-C1 proof)"
+	} else {
+		msg += ". The new certificate has no NIP-C1 proof linking it to your Nostr identity (zsp identity --link-key)"
+	}
+	if !p.opts.Publish.ConfirmCertChange {
+		return fmt.Errorf("%s. Pass --confirm-cert-change if the signing key change is deliberate", msg)
+	}
+	p.warn(msg + " (--confirm-cert-change)")
+	return nil
+}
+
+// certLinked reports whether relays hold a valid, unexpired and unrevoked
+// identity proof by pubkey for the certificate.
+func (p *Publisher) certLinked(ctx context.Context, pubkey, certHash string) bool {
+	event, err := p.publisher.FetchIdentityProof(ctx, pubkey, certHash)
+	if err != nil || event == nil {
+		return false
+	}
+	proof, err := identity.ParseIdentityProofFromEvent(event)
+	if err != nil {
+		return false
+	}
+	result := identity.VerifyIdentityProof(proof, event, pubkey)
+	return result.Valid && !result.Expired && !result.Revoked
+}
+
 // gatherMetadata fetches metadata from external sources.
 // In offline mode, network fetches (external metadata, remote images) are skipped,
 // but local data (release notes from a local file, local icon/screenshots) is still processed.
@@ -488,6 +1153,18 @@ func (p *Publisher) gatherMetadata(ctx context.Context) error {
 		ui.PrintInfo("Skipping external metadata fetch (--offline)")
 	}
 
+	// Drop unwanted screenshots (e.g. promo graphics from Play Store) before download/upload
+	if removed := p.cfg.ApplyImagesExclude(); len(removed) > 0 {
+		if p.opts.ShouldShowSpinners() {
+			ui.PrintInfo(fmt.Sprintf("Excluded %d image(s) via images_exclude", len(removed)))
+		}
+		if p.opts.Global.Verbose {
+			for _, image := range removed {
+				fmt.Printf("    - %s\n", image)
+			}
+		}
+	}
+
 	// Determine release notes (local file paths work in offline mode too)
 	p.releaseNotes = p.release.Changelog
 	if p.cfg.ReleaseNotes != "" {
@@ -503,12 +1180,178 @@ func (p *Publisher) gatherMetadata(ctx context.Context) error {
 			}
 		}
 	}
+	if p.cfg.AppendReleaseLink {
+		p.releaseNotes = appendReleaseLink(p.releaseNotes, p.release.ReleaseLink())
+	}
+
+	// With --no-preview-images, images are fetched after the preview instead
+	if p.opts.Publish.NoPreviewImages {
+		return nil
+	}
+	return p.prepareImages(ctx)
+}
+
+// prepareImages pre-downloads remote images and drops screenshots that would not render.
+func (p *Publisher) prepareImages(ctx context.Context) error {
+	if err := p.resolveMediaRefs(ctx); err != nil {
+		return err
+	}
 
 	// Pre-download remote images (skipped in offline mode; local images are used directly)
-	if p.isOffline() {
+	if !p.isOffline() {
+		if err := p.preDownloadImages(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.processIcon(); err != nil {
+		return err
+	}
+	p.checkScreenshotShapes()
+
+	if err := p.prepareNotesImages(ctx); err != nil {
+		return err
+	}
+
+	// Catch screenshots that would become broken image tags
+	return p.checkImages(ctx)
+}
+
+// processIcon prepares the icon that will be published. An SVG icon is
+// rasterized with icon_rasterize. The icon must then be square and at least
+// icon_min_size; with icon_autofix, a non-square icon is padded to a square and
+// a small one only warns, since icons are never upscaled. A converted icon
+// replaces the original for hashing and upload. Remote icons are handled once
+// downloaded.
+func (p *Publisher) processIcon() error {
+	var data []byte
+	var local string
+	switch {
+	case p.preDownloaded != nil && p.preDownloaded.Icon != nil:
+		data = p.preDownloaded.Icon.Data
+	case p.cfg.Icon != "" && !isRemoteURL(p.cfg.Icon):
+		path := resolvePath(p.cfg.Icon, p.cfg.BaseDir)
+		raw, err := os.ReadFile(path)
+		if err != nil || len(raw) == 0 {
+			return nil // Reported when the icon is uploaded
+		}
+		prepared, err := media.Process(raw, detectImageMimeType(path), media.IconMaxWidth, !p.opts.Publish.NoCompress)
+		if err != nil {
+			return nil
+		}
+		data, local = prepared.Data, path
+	case p.cfg.Icon == "" && p.apkInfo != nil && len(p.apkInfo.Icon) > 0:
+		data = p.apkInfo.Icon
+	default:
+		return nil
+	}
+
+	// Vector icons become PNGs with icon_rasterize; the SVG is kept if rendering fails
+	if p.cfg.IconRasterize && media.IsSVG(data) {
+		raster, err := media.RasterizeSVG(data, cmp.Or(p.cfg.IconRasterSize, media.IconRasterSize))
+		if err != nil {
+			p.warn(fmt.Sprintf("could not rasterize SVG icon, uploading it as SVG: %v", err))
+			return nil
+		}
+		if p.opts.Global.Verbose {
+			fmt.Fprintf(os.Stderr, "  Rasterized SVG icon to %s PNG\n", formatImageBytes(len(raster.Data)))
+		}
+		p.replaceIcon(raster, local)
+		data = raster.Data
+	}
+
+	minSize := cmp.Or(p.cfg.IconMinSize, media.IconMinSize)
+	err := media.CheckIcon(data, minSize)
+	if errors.Is(err, media.ErrIconNotSquare) && p.cfg.IconAutofix {
+		padded, padErr := media.PadIconToSquare(data)
+		if padErr != nil {
+			return fmt.Errorf("icon_autofix: %w", padErr)
+		}
+		if p.opts.ShouldShowSpinners() {
+			ui.PrintInfo(fmt.Sprintf("Padded non-square icon to a square (icon_autofix): %v", err))
+		}
+		p.replaceIcon(padded, local)
+		err = media.CheckIcon(padded.Data, minSize)
+	}
+	if errors.Is(err, media.ErrIconTooSmall) && p.cfg.IconAutofix {
+		p.warn(fmt.Sprintf("%v; icons are not upscaled, supply a larger one", err))
 		return nil
 	}
-	return p.preDownloadImages(ctx)
+	if errors.Is(err, media.ErrIconNotSquare) || errors.Is(err, media.ErrIconTooSmall) {
+		return fmt.Errorf("%w (set icon_autofix: true to pad non-square icons, or icon_min_size to lower the limit)", err)
+	}
+	if err != nil && p.opts.Global.Verbose {
+		fmt.Fprintf(os.Stderr, "  Skipping icon check: %v\n", err)
+	}
+	return nil
+}
+
+// replaceIcon makes a processed icon the one that is hashed and uploaded,
+// in place of the remote download, the local file at localPath, or the APK icon.
+func (p *Publisher) replaceIcon(icon media.Result, localPath string) {
+	switch {
+	case p.preDownloaded != nil && p.preDownloaded.Icon != nil:
+		p.preDownloaded.Icon.Data, p.preDownloaded.Icon.Hash, p.preDownloaded.Icon.MimeType = icon.Data, icon.Hash, icon.MimeType
+	case localPath != "":
+		if p.preDownloaded == nil {
+			p.preDownloaded = &PreDownloadedImages{}
+		}
+		p.preDownloaded.Icon = &DownloadedImage{URL: localPath, Data: icon.Data, Hash: icon.Hash, MimeType: icon.MimeType}
+	default:
+		p.apkInfo.Icon = icon.Data
+	}
+}
+
+// checkScreenshotShapes warns about screenshots that are very narrow or
+// elongated. They are still published.
+func (p *Publisher) checkScreenshotShapes() {
+	downloaded := make(map[string][]byte)
+	if p.preDownloaded != nil {
+		for _, img := range p.preDownloaded.Images {
+			downloaded[img.URL] = img.Data
+		}
+	}
+	for _, img := range p.cfg.Images {
+		data, ok := downloaded[img]
+		if !ok && !isRemoteURL(img) {
+			data, _ = os.ReadFile(resolvePath(img, p.cfg.BaseDir))
+		}
+		if problem := media.CheckScreenshot(data); problem != "" {
+			p.warn(fmt.Sprintf("screenshot %s: %s and may render poorly", img, problem))
+		}
+	}
+}
+
+// checkImages reports screenshots that would not render. Broken ones are dropped
+// with a warning, or fail the run with --strict-images.
+func (p *Publisher) checkImages(ctx context.Context) error {
+	// Failed downloads were already warned about; only --strict-images needs them again
+	problems := checkImages(ctx, p.cfg, p.preDownloaded, p.blossomURL, p.opts.Publish.StrictImages && !p.isOffline())
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if p.opts.Publish.StrictImages {
+		parts := make([]string, len(problems))
+		for i, problem := range problems {
+			parts[i] = fmt.Sprintf("%s: %s", problem.Image, problem.Problem)
+		}
+		return fmt.Errorf("broken screenshot(s) (--strict-images): %s", strings.Join(parts, "; "))
+	}
+
+	broken := make(map[string]bool, len(problems))
+	for _, problem := range problems {
+		broken[problem.Image] = true
+		p.warn(fmt.Sprintf("skipping screenshot %s: %s", problem.Image, problem.Problem))
+	}
+	kept := p.cfg.Images[:0]
+	for _, img := range p.cfg.Images {
+		if !broken[img] {
+			kept = append(kept, img)
+		}
+	}
+	p.cfg.Images = kept
+	return nil
 }
 
 // fetchExternalMetadata fetches metadata from configured sources.
@@ -529,6 +1372,10 @@ func (p *Publisher) fetchExternalMetadata(ctx context.Context) error {
 
 	fetcher := source.NewMetadataFetcherWithPackageID(p.cfg, p.apkInfo.PackageID)
 	fetcher.APKName = p.apkInfo.Label
+	fetcher.VersionCode = p.apkInfo.VersionCode
+	if p.release != nil {
+		fetcher.GitHubRepo = p.release.Repository
+	}
 
 	var result *source.MetadataResult
 	err := WithSpinnerMsg(p.opts, "Fetching metadata from external sources...", func() error {
@@ -557,6 +1404,24 @@ func (p *Publisher) fetchExternalMetadata(ctx context.Context) error {
 		}
 	}
 
+	// Record where listing content came from (metadata_provenance)
+	if result != nil && p.cfg.MetadataProvenance {
+		for _, prov := range result.Provenance() {
+			p.provenance = append(p.provenance, nostr.MetadataProvenance{
+				Source: prov.Source,
+				URL:    prov.URL,
+				SHA256: prov.SHA256,
+			})
+		}
+	}
+
+	// Let the user pick per field when config, APK and fetched metadata disagree
+	if result != nil && p.opts.IsInteractive() {
+		if err := p.reconcileMetadata(result); err != nil {
+			return err
+		}
+	}
+
 	if p.opts.Global.Verbose && err == nil {
 		fmt.Printf("    name=%q, description=%d chars, tags=%v\n",
 			p.cfg.Name, len(p.cfg.Description), p.cfg.Tags)
@@ -565,6 +1430,63 @@ func (p *Publisher) fetchExternalMetadata(ctx context.Context) error {
 	return nil // Metadata errors are non-fatal
 }
 
+// reconcileMetadata shows each field with conflicting candidate values and lets the
+// user override the default choice. Overrides can be saved back to the config file.
+func (p *Publisher) reconcileMetadata(result *source.MetadataResult) error {
+	conflicts := result.Conflicts()
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	ui.PrintSectionHeader("Metadata Conflicts")
+	fmt.Println("  Sources disagree on these fields. The first option is used unless you pick another.")
+
+	overrides := make(map[string]string)
+	for _, conflict := range conflicts {
+		options := make([]string, len(conflict.Candidates))
+		for i, candidate := range conflict.Candidates {
+			options[i] = fmt.Sprintf("%s (%s)", truncateCandidate(candidate.Value), candidate.Source)
+		}
+		idx, err := ui.SelectOption(fmt.Sprintf("%s:", conflict.Field), options, 0)
+		if err != nil {
+			return err
+		}
+		if idx > 0 {
+			source.SetMetadataField(p.cfg, conflict.Field, conflict.Candidates[idx].Value)
+			overrides[conflict.Field] = conflict.Candidates[idx].Value
+		}
+	}
+
+	if len(overrides) == 0 || p.cfg.Path == "" {
+		return nil
+	}
+	save, err := ui.Confirm(fmt.Sprintf("Save your choices to %s?", filepath.Base(p.cfg.Path)), false)
+	if err != nil {
+		return err
+	}
+	if !save {
+		return nil
+	}
+	if err := config.SetFields(p.cfg.Path, overrides); err != nil {
+		return fmt.Errorf("failed to save metadata choices: %w", err)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Saved %d field(s) to %s", len(overrides), p.cfg.Path))
+	return nil
+}
+
+// truncateCandidate shortens a candidate value to one line for display.
+func truncateCandidate(value string) string {
+	const maxLen = 60
+	line, _, multiline := strings.Cut(strings.TrimSpace(value), "\n")
+	if runes := []rune(line); len(runes) > maxLen {
+		return string(runes[:maxLen]) + "..."
+	}
+	if multiline {
+		return line + " ..."
+	}
+	return line
+}
+
 // preDownloadImages downloads remote icons and screenshots.
 func (p *Publisher) preDownloadImages(ctx context.Context) error {
 	if p.cfg.Icon == "" || !isRemoteURL(p.cfg.Icon) {
@@ -574,7 +1496,7 @@ func (p *Publisher) preDownloadImages(ctx context.Context) error {
 	}
 
 	var err error
-	p.preDownloaded, err = PreDownloadImages(ctx, p.cfg, p.opts)
+	p.preDownloaded, err = PreDownloadImages(ctx, p.cfg, p.opts, p.preDownloaded)
 	if err != nil {
 		return fmt.Errorf("failed to download images: %w", err)
 	}
@@ -589,6 +1511,13 @@ func (p *Publisher) handlePreview(ctx context.Context) error {
 		return nil
 	}
 
+	// Serve the preview for a browser elsewhere, e.g. through an SSH tunnel
+	if p.opts.Publish.PreviewURLOnly {
+		p.browserPort = cmp.Or(p.opts.Publish.Port, nostr.DefaultPreviewPort)
+		p.browserPortFixed = p.opts.Publish.Port != 0
+		return p.showPreview(ctx)
+	}
+
 	// Skip preview prompt if no graphical display is available
 	if !ui.HasDisplay() {
 		return nil
@@ -613,6 +1542,8 @@ func (p *Publisher) handlePreview(ctx context.Context) error {
 	if p.browserPort == 0 {
 		p.browserPort = nostr.DefaultPreviewPort
 	}
+	// Only a port the user chose must be free; the default falls back to the next free one
+	p.browserPortFixed = p.opts.Publish.Port != 0 || p.browserPort != nostr.DefaultPreviewPort
 
 	return p.showPreview(ctx)
 }
@@ -620,6 +1551,17 @@ func (p *Publisher) handlePreview(ctx context.Context) error {
 // showPreview displays the browser preview.
 func (p *Publisher) showPreview(ctx context.Context) error {
 	previewData := nostr.BuildPreviewDataFromAPK(p.apkInfo, p.cfg, p.releaseNotes, p.blossomURL, p.publisher.RelayURLs())
+	if platforms := p.opts.Publish.Platforms; len(platforms) > 0 {
+		previewData.Platforms = platforms
+		for i := range previewData.Assets {
+			previewData.Assets[i].Platforms = platforms
+		}
+	}
+
+	// Show placeholders instead of screenshots; images are fetched after the preview
+	if p.opts.Publish.NoPreviewImages {
+		previewData.NoImages = true
+	}
 
 	// Override icon with pre-downloaded icon if available
 	if p.preDownloaded != nil && p.preDownloaded.Icon != nil {
@@ -634,7 +1576,11 @@ func (p *Publisher) showPreview(ctx context.Context) error {
 		}
 	}
 
-	for _, img := range p.cfg.Images {
+	images := p.cfg.Images
+	if previewData.NoImages {
+		images = nil
+	}
+	for _, img := range images {
 		if pd, ok := preDownloadedByURL[img]; ok {
 			// Use pre-downloaded remote image
 			previewData.ImageData = append(previewData.ImageData, nostr.PreviewImageData{
@@ -665,42 +1611,127 @@ func (p *Publisher) showPreview(ctx context.Context) error {
 		}
 	}
 
-	previewServer := nostr.NewPreviewServer(previewData, p.releaseNotes, "", p.browserPort)
+	p.offerServerTakeover(p.browserPort, "preview")
+	fixedPort := 0
+	if p.browserPortFixed {
+		fixedPort = p.browserPort
+	}
+	previewServer := nostr.NewPreviewServer(previewData, p.releaseNotes, "", fixedPort)
+	previewServer.SetNoBrowser(p.opts.Publish.PreviewURLOnly)
 	url, err := previewServer.Start()
 	if err != nil {
 		return fmt.Errorf("failed to start preview server: %w", err)
 	}
+	defer previewServer.Close()
 
+	if previewServer.Port() != p.browserPort {
+		fmt.Fprintf(os.Stderr, "Port %d is in use, using %d instead\n", p.browserPort, previewServer.Port())
+	}
 	fmt.Printf("Preview server started at %s\n", url)
+	if p.opts.Publish.PreviewURLOnly {
+		fmt.Printf("Open it in a browser; from another machine, forward the port first: ssh -L %d:localhost:%d <this host>\n", previewServer.Port(), previewServer.Port())
+	}
 	fmt.Println("Press Enter to continue, or Ctrl+C to cancel...")
 
 	// Wait for Enter with context support
-	err = cli.WaitForEnterWithContext(ctx)
-	if err != nil {
-		previewServer.Close()
+	if err := cli.WaitForEnterWithContext(ctx); err != nil {
 		return err
 	}
 
 	previewServer.ConfirmFromCLI()
-	previewServer.Close()
-
 	return nil
 }
 
+// offerServerTakeover checks whether a zsp server from an earlier run still holds
+// port and, when interactive, offers to shut it down so the port can be reused.
+// Otherwise the new server simply binds the next free port.
+func (p *Publisher) offerServerTakeover(port int, what string) {
+	if !p.opts.IsInteractive() || !nostr.IsStaleServer(port) {
+		return
+	}
+	ok, err := ui.Confirm(fmt.Sprintf("A zsp server from an earlier run is using port %d. Shut it down and use the port for the %s?", port, what), true)
+	if err != nil || !ok {
+		return
+	}
+	if err := nostr.TakeOverStaleServer(port); err != nil {
+		p.warn(fmt.Sprintf("could not take over port %d: %v", port, err))
+	}
+}
+
 // signAndUpload handles signer creation and file uploads.
 func (p *Publisher) signAndUpload(ctx context.Context) error {
+	// NIP-65 discovery needs the signer's pubkey. When SIGN_WITH names the key,
+	// publish relays are settled before the signer is created
+	if pubkey := p.signingPubkey(); pubkey != "" && p.signer == nil {
+		if err := p.resolveRelays(ctx, pubkey); err != nil {
+			return err
+		}
+	}
+
 	// Create signer
 	if err := p.createSigner(ctx); err != nil {
 		return err
 	}
+	if p.opts.Publish.SignOnly && p.signer.Type() == nostr.SignerNpub {
+		return fmt.Errorf("--sign-only needs a signer that can sign; with an npub use --offline for unsigned events")
+	}
+
+	// A delegation for another key would only fail once the events are built
+	if p.delegation != nil {
+		if err := p.delegation.Check(p.signer.PublicKey()); err != nil {
+			return fmt.Errorf("invalid --delegation: %w", err)
+		}
+	}
+
+	// Only the set's owner can re-sign it, so a mismatch fails before anything is published
+	if p.curationSet != nil {
+		if err := p.checkCurationSetOwner(); err != nil {
+			return err
+		}
+	}
+
+	// Bunker and browser signers reveal the pubkey only once created
+	if err := p.resolveRelays(ctx, p.signer.PublicKey()); err != nil {
+		return err
+	}
+
+	// An unreachable quorum must fail before any blob is uploaded
+	if err := p.checkMinRelaySuccess(); err != nil {
+		return err
+	}
+
+	// Oversized release notes can still be truncated or moved to an article
+	if err := p.checkContentLimits(ctx); err != nil {
+		return err
+	}
+
+	// Events must not carry created_at from a skewed local clock
+	if !p.skipsRelays() && !p.opts.Publish.TrustLocalClock {
+		p.checkClock(ctx)
+	}
 
 	// Check if this publisher's asset already exists on relays (scoped to their pubkey)
 	if err := p.checkExistingAsset(ctx, p.signer.PublicKey()); err != nil {
 		return err
 	}
 
-	// C1 certificate linking check (skip in offline mode or when --skip-linking is set)
-	if !p.isOffline() && !p.opts.Publish.SkipCertificateLinking {
+	// A different signer than the app's author would create a competing entry
+	if err := p.checkAppAuthor(ctx, p.signer.PublicKey()); err != nil {
+		return err
+	}
+
+	// versionCode must increase across every channel, not just this one
+	if err := p.checkVersionCodes(ctx, p.signer.PublicKey()); err != nil {
+		return err
+	}
+
+	// A new signing key must be deliberate
+	if err := p.checkCertChange(ctx, p.signer.PublicKey()); err != nil {
+		return err
+	}
+
+	// C1 certificate linking check (skip in offline and sign-only mode or when --skip-linking is set)
+	if !p.skipsRelays() && !p.opts.Publish.SkipCertificateLinking {
 		if err := p.checkAndLinkCertificate(ctx); err != nil {
 			return err
 		}
@@ -708,28 +1739,104 @@ func (p *Publisher) signAndUpload(ctx context.Context) error {
 
 	// When overwriting a release, fetch the existing 30063's created_at so the new
 	// event gets a strictly higher timestamp and the relay's NIP-33 guard fires.
-	if p.opts.Publish.OverwriteRelease && !p.isOffline() {
-		ts, err := p.publisher.CheckExistingRelease(ctx, p.signer.PublicKey(), p.apkInfo.PackageID, p.apkInfo.VersionName)
+	if p.opts.Publish.OverwriteRelease && !p.skipsRelays() {
+		ts, err := p.publisher.CheckExistingRelease(ctx, p.signer.PublicKey(), p.appIdentifier(), p.apkInfo.VersionName)
 		if err == nil {
 			p.existingReleaseTimestamp = ts
+			if ahead := ts.Sub(time.Now().Add(p.clockOffset)); ahead > netclock.DefaultMaxSkew {
+				p.warn(fmt.Sprintf("existing release created_at is %s in the future; relays may keep it until then", ahead.Round(time.Second)))
+			}
 		} else if p.opts.Global.Verbose {
 			fmt.Printf("  Could not fetch existing release timestamp: %v\n", err)
 		}
 	}
 
+	// Fetch the existing app event so fields this build leaves empty are preserved
+	if p.opts.Publish.OverwriteApp != "replace" && !p.opts.Publish.SkipAppEvent && !p.skipsRelays() {
+		existing, err := p.publisher.FetchAppMetadata(ctx, p.signer.PublicKey(), p.appIdentifier())
+		if err == nil {
+			p.existingApp = existing
+			p.appChecked = true
+		} else if p.opts.Global.Verbose {
+			fmt.Printf("  Could not fetch existing app metadata: %v\n", err)
+		}
+	}
+
 	// Determine URLs and build events
-	if p.isOffline() || p.signer.Type() == nostr.SignerNpub {
+	if p.skipsRelays() || p.signer.Type() == nostr.SignerNpub {
 		return p.buildEventsWithoutUpload(ctx)
 	}
 
 	return p.uploadAndBuildEvents(ctx)
 }
 
+// checkClock compares the local clock with the Date headers of HTTPS responses seen so far,
+// probing the publish relays' NIP-11 documents when fewer than two were seen. When the skew
+// exceeds netclock.DefaultMaxSkew, events use network time instead of the local clock.
+func (p *Publisher) checkClock(ctx context.Context) {
+	if netclock.Samples() < 2 {
+		netclock.Probe(ctx, p.publisher.RelayURLs())
+	}
+	skew, samples := netclock.Skew()
+	if samples == 0 {
+		return
+	}
+	if skew < netclock.DefaultMaxSkew && skew > -netclock.DefaultMaxSkew {
+		return
+	}
+	direction := "ahead of"
+	if skew > 0 {
+		direction = "behind"
+	}
+	p.clockOffset = skew
+	p.warn(fmt.Sprintf("local clock is %s %s network time (from %d HTTPS Date headers); using network time for created_at (--trust-local-clock to disable)",
+		skew.Abs().Round(time.Second), direction, samples))
+}
+
+// now returns the time to use for event created_at: zero (the local clock) unless
+// checkClock found it skewed.
+func (p *Publisher) now() time.Time {
+	if p.clockOffset == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(p.clockOffset)
+}
+
+// warn prints a warning to stderr even in quiet mode, since it concerns what gets published.
+func (p *Publisher) warn(message string) {
+	if !p.opts.Global.JSON {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", message)
+	}
+	ui.RecordWarning(message)
+}
+
+// status prints a progress line to stderr, unless --quiet, --json or
+// --progress-json keep stderr for errors and warnings.
+func (p *Publisher) status(format string, args ...any) {
+	if p.opts.ShouldShowSpinners() {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
 // createSigner creates the appropriate signer based on configuration.
 func (p *Publisher) createSigner(ctx context.Context) error {
+	if p.signer != nil {
+		return nil // shared with a previous app (see PublishApps)
+	}
+
+	// --ephemeral-key signs with the throwaway key; SIGN_WITH only signs the attestation
+	if p.opts.Publish.EphemeralSecret != "" {
+		signer, err := nostr.NewNsecSigner(p.opts.Publish.EphemeralSecret)
+		if err != nil {
+			return fmt.Errorf("failed to create ephemeral signer: %w", err)
+		}
+		p.signer = signer
+		return nil
+	}
+
 	signWith := config.GetSignWith()
 	if signWith == "" {
-		if p.opts.Publish.Quiet || p.opts.Publish.Offline {
+		if p.opts.Publish.Quiet || p.skipsRelays() {
 			return fmt.Errorf("SIGN_WITH environment variable is required")
 		}
 		ui.PrintSectionHeader("Signing Setup")
@@ -740,18 +1847,41 @@ func (p *Publisher) createSigner(ctx context.Context) error {
 		}
 	}
 
-	// Determine port for browser signer
-	signerPort := p.browserPort
-	if signWith == "browser" && p.browserPort == 0 && p.opts.IsInteractive() {
+	// Determine port for browser signer. A port the user chose must be free;
+	// 0 starts at the default and falls back to the next free one
+	signerPort := 0
+	switch {
+	case p.browserPortFixed:
+		signerPort = p.browserPort
+	case p.opts.Publish.Port != 0:
+		signerPort = p.opts.Publish.Port
+	case signWith == "browser" && p.browserPort == 0 && p.opts.IsInteractive():
 		port, err := ui.ConfirmWithPortYesOnly("Browser signing port?", nostr.DefaultNIP07Port)
 		if err != nil {
 			return fmt.Errorf("prompt failed: %w", err)
 		}
-		signerPort = port
+		if port != nostr.DefaultNIP07Port {
+			signerPort = port
+		}
+	}
+
+	requested := signerPort
+	if requested == 0 {
+		requested = nostr.DefaultNIP07Port
+	}
+	if signWith == "browser" {
+		p.offerServerTakeover(requested, "browser signer")
 	}
 
 	signer, err := nostr.NewSignerWithOptions(ctx, signWith, nostr.SignerOptions{
-		Port: signerPort,
+		Port:       signerPort,
+		OnFailover: p.warn,
+		OnListen: func(listenURL string) {
+			if u, err := url.Parse(listenURL); err == nil && u.Port() != strconv.Itoa(requested) {
+				fmt.Fprintf(os.Stderr, "Port %d is in use, using %s instead\n", requested, u.Port())
+			}
+			fmt.Fprintf(os.Stderr, "Browser signer listening at %s\n", listenURL)
+		},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create signer: %w", err)
@@ -771,6 +1901,61 @@ func (p *Publisher) createSigner(ctx context.Context) error {
 	return nil
 }
 
+// relayMode returns the publish relay discovery mode (--relays overrides config;
+// none with --dev).
+func (p *Publisher) relayMode() string {
+	// The local dev relay is never replaced by the signer's public relays
+	if p.opts.Publish.Dev {
+		return ""
+	}
+	if p.opts.Publish.Relays != "" {
+		return p.opts.Publish.Relays
+	}
+	return p.cfg.Relays
+}
+
+// resolveRelays replaces the publish relays with pubkey's NIP-65 write relays
+// when --relays nip65 (or config relays: nip65) is set. Runs once per session.
+func (p *Publisher) resolveRelays(ctx context.Context, pubkey string) error {
+	mode := p.relayMode()
+	if mode == "" || p.skipsRelays() || p.relaysResolved {
+		return nil
+	}
+
+	bootstrapRelays := splitRelays(config.GetEnv("RELAY_URLS"))
+	relays, err := WithSpinner(p.opts, "Discovering NIP-65 relays...", func() ([]string, error) {
+		return nostr.DiscoverWriteRelays(ctx, pubkey, bootstrapRelays, mode != "nip65-only")
+	})
+	if err != nil {
+		return fmt.Errorf("NIP-65 relay discovery failed (publish a kind 10002 relay list or drop --relays %s): %w", mode, err)
+	}
+
+	p.publisher = nostr.NewPublisher(relays)
+	p.publisher.SetRoutes(relayRoutes(p.cfg))
+	p.relaysResolved = true
+
+	if !p.opts.Publish.Quiet && !p.opts.Global.JSON {
+		fmt.Fprintf(os.Stderr, "Publishing to NIP-65 relays: %s\n", strings.Join(relays, ", "))
+	}
+	return nil
+}
+
+// signingPubkey returns the hex pubkey the events are signed with when it is
+// known before the signer is created: that of the --ephemeral-key secret or of
+// a SIGN_WITH nsec, npub or hex key. "" for bunker and browser signers.
+func (p *Publisher) signingPubkey() string {
+	npub := config.ResolvePubkeyFromSignWith(cmp.Or(p.opts.Publish.EphemeralSecret, config.GetSignWith()))
+	if npub == "" {
+		return ""
+	}
+	_, data, err := nip19.Decode(npub)
+	if err != nil {
+		return ""
+	}
+	pubkey, _ := data.(string)
+	return pubkey
+}
+
 // checkAndLinkCertificate checks for a valid identity proof on the relay.
 //
 // Online signers (nsec/bunker/browser): if no valid proof exists, prompts the user
@@ -818,6 +2003,9 @@ func (p *Publisher) checkAndLinkCertificate(ctx context.Context) error {
 	if isNpub {
 		fmt.Println(ui.Dim("The proof event will be included in the output for external signing."))
 	}
+	if len(p.apkInfo.CertLineage) > 1 {
+		fmt.Println(ui.Dim("This APK's signing key was rotated; the proof must be for the current certificate, not an earlier one."))
+	}
 	fmt.Println()
 
 	privateKey, cert, loadErr := p.loadFromJKS()
@@ -918,7 +2106,7 @@ func (p *Publisher) loadFromJKS() (crypto.PrivateKey, *x509.Certificate, error)
 	}
 	tempPassword := hex.EncodeToString(randBytes)
 
-	tmpDir, err := os.MkdirTemp("", "zsp-keystore-*")
+	tmpDir, err := os.MkdirTemp(tempdir.Dir(), "keystore-*")
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create temp dir: %w", err)
 	}
@@ -952,7 +2140,36 @@ func (p *Publisher) isOffline() bool {
 	return p.opts.Publish.Offline
 }
 
-// buildEventsWithoutUpload builds events without uploading files (offline / npub mode).
+// checkMinRelaySuccess fails when --min-relay-success asks for more relays
+// than an event kind is published to. With relay_routes, each kind is checked
+// against the relays it is routed to rather than the configured list.
+func (p *Publisher) checkMinRelaySuccess() error {
+	minSuccess := p.opts.Publish.MinRelaySuccess
+	if minSuccess == 0 || p.skipsRelays() || p.signer.Type() == nostr.SignerNpub {
+		return nil
+	}
+	kinds := []int{nostr.KindRelease, nostr.KindSoftwareAsset}
+	if !p.opts.Publish.SkipAppEvent {
+		kinds = append([]int{nostr.KindAppMetadata}, kinds...)
+	}
+	for _, kind := range kinds {
+		if relayCount := len(p.publisher.RelaysForKind(kind)); minSuccess > relayCount {
+			if p.publisher.HasRoutes() {
+				return fmt.Errorf("--min-relay-success %d exceeds the %d relay(s) kind %d events are routed to", minSuccess, relayCount, kind)
+			}
+			return fmt.Errorf("--min-relay-success %d exceeds the %d configured relay(s)", minSuccess, relayCount)
+		}
+	}
+	return nil
+}
+
+// skipsRelays reports whether the run neither reads from nor publishes to
+// relays: offline runs, and --sign-only runs, whose events another tool publishes.
+func (p *Publisher) skipsRelays() bool {
+	return p.opts.Publish.Offline || p.opts.Publish.SignOnly
+}
+
+// buildEventsWithoutUpload builds events without uploading files (offline / sign-only / npub mode).
 func (p *Publisher) buildEventsWithoutUpload(ctx context.Context) error {
 	var err error
 	p.iconURL, p.imageURLs, err = ResolveURLsWithoutUpload(ctx, p.cfg, p.apkInfo, p.blossomURL, p.preDownloaded, p.opts)
@@ -960,25 +2177,49 @@ func (p *Publisher) buildEventsWithoutUpload(ctx context.Context) error {
 		return err
 	}
 
-	p.events = nostr.BuildEventSet(nostr.BuildEventSetParams{
+	// Signed events must not reference blobs nobody will upload
+	if p.opts.Publish.SignOnly {
+		if err := p.checkBlobsPresent(ctx); err != nil {
+			return err
+		}
+	}
+
+	p.events, err = nostr.BuildEventSet(nostr.BuildEventSetParams{
 		APKInfo:                   p.apkInfo,
 		Config:                    p.cfg,
 		Pubkey:                    p.signer.PublicKey(),
 		OriginalURL:               p.getOriginalURL(),
 		BlossomServer:             p.blossomURL,
+		BlossomPublicURL:          blossomPublicURL(p.cfg, p.opts),
 		IconURL:                   p.iconURL,
 		ImageURLs:                 p.imageURLs,
-		Changelog:                 p.releaseNotes,
+		Changelog:                 rewriteNotesImages(p.releaseNotes, p.notesImages, p.blossomURL, blossomPublicURL(p.cfg, p.opts)),
 		Variant:                   p.matchVariant(),
 		Commit:                    p.opts.Publish.Commit,
+		TagName:                   p.releaseTagName(),
 		Channel:                   p.opts.Publish.Channel,
+		ChannelSuffix:             p.opts.Publish.ChannelSuffix,
 		ReleaseTimestamp:          p.getReleaseTimestamp(),
+		PublishedAt:               p.getPublishedAt(),
 		UseReleaseTimestampForApp: p.opts.Publish.AppCreatedAtRelease,
+		ExistingApp:               p.existingApp,
+		ReplaceApp:                p.opts.Publish.OverwriteApp == "replace",
 		MinReleaseTimestamp:       p.existingReleaseTimestamp,
+		KeepAssets:                p.keepAssets,
+		Platforms:                 p.opts.Publish.Platforms,
+		Now:                       p.now(),
+		Provenance:                p.provenance,
+		DetachedSignature:         signatureRef(p.detachedSig, p.blossomURL),
+		CIBuild:                   ciBuild(p.cfg),
+		Delegation:                p.delegation,
 	})
+	if err != nil {
+		return err
+	}
 	if p.opts.Publish.SkipAppEvent {
 		p.events.AppMetadata = nil
 	}
+	showAppMetadataDiff(p.opts, p.existingApp, p.events.AppMetadata)
 
 	relayHint := p.getRelayHint()
 	return nostr.SignEventSet(ctx, p.signer, p.events, relayHint)
@@ -1009,9 +2250,19 @@ func (p *Publisher) uploadAndBuildEvents(ctx context.Context) error {
 			Variant:             p.matchVariant(),
 			Commit:              p.opts.Publish.Commit,
 			Channel:             p.opts.Publish.Channel,
+			ChannelSuffix:       p.opts.Publish.ChannelSuffix,
+			Platforms:           p.opts.Publish.Platforms,
+			Provenance:          p.provenance,
+			DetachedSignature:   p.detachedSig,
+			NotesImages:         p.notesImages,
 			Opts:                p.opts,
 			AppCreatedAtRelease: p.opts.Publish.AppCreatedAtRelease,
 			MinReleaseTimestamp: p.existingReleaseTimestamp,
+			PublishedAt:         p.getPublishedAt(),
+			Now:                 p.now(),
+			ExistingApp:         p.existingApp,
+			KeepAssets:          p.keepAssets,
+			Delegation:          p.delegation,
 		})
 		return err
 	}
@@ -1019,52 +2270,71 @@ func (p *Publisher) uploadAndBuildEvents(ctx context.Context) error {
 	// Regular signing mode
 	var err error
 	p.iconURL, p.imageURLs, p.pendingUploads, err = UploadWithIndividualSigning(ctx, UploadParams{
-		Cfg:           p.cfg,
-		APKInfo:       p.apkInfo,
-		APKPath:       p.apkPath,
-		Client:        client,
-		Signer:        p.signer,
-		Pubkey:        p.signer.PublicKey(),
-		PreDownloaded: p.preDownloaded,
-		Opts:          p.opts,
+		Cfg:               p.cfg,
+		APKInfo:           p.apkInfo,
+		APKPath:           p.apkPath,
+		Client:            client,
+		Signer:            p.signer,
+		Pubkey:            p.signer.PublicKey(),
+		PreDownloaded:     p.preDownloaded,
+		Opts:              p.opts,
+		DetachedSignature: p.detachedSig,
+		NotesImages:       p.notesImages,
 	})
 	if err != nil {
 		return err
 	}
 
-	p.events = nostr.BuildEventSet(nostr.BuildEventSetParams{
+	p.events, err = nostr.BuildEventSet(nostr.BuildEventSetParams{
 		APKInfo:                   p.apkInfo,
 		Config:                    p.cfg,
 		Pubkey:                    p.signer.PublicKey(),
 		OriginalURL:               p.getOriginalURL(),
 		BlossomServer:             p.blossomURL,
+		BlossomPublicURL:          blossomPublicURL(p.cfg, p.opts),
 		IconURL:                   p.iconURL,
+		IconBlurhash:              p.pendingUploads.IconBlurhash(),
 		ImageURLs:                 p.imageURLs,
-		Changelog:                 p.releaseNotes,
+		Changelog:                 rewriteNotesImages(p.releaseNotes, p.notesImages, p.blossomURL, blossomPublicURL(p.cfg, p.opts)),
 		Variant:                   p.matchVariant(),
 		Commit:                    p.opts.Publish.Commit,
+		TagName:                   p.releaseTagName(),
 		Channel:                   p.opts.Publish.Channel,
+		ChannelSuffix:             p.opts.Publish.ChannelSuffix,
 		ReleaseTimestamp:          p.getReleaseTimestamp(),
+		PublishedAt:               p.getPublishedAt(),
 		UseReleaseTimestampForApp: p.opts.Publish.AppCreatedAtRelease,
+		ExistingApp:               p.existingApp,
+		ReplaceApp:                p.opts.Publish.OverwriteApp == "replace",
 		MinReleaseTimestamp:       p.existingReleaseTimestamp,
+		KeepAssets:                p.keepAssets,
+		Platforms:                 p.opts.Publish.Platforms,
+		Now:                       p.now(),
+		Provenance:                p.provenance,
+		DetachedSignature:         signatureRef(p.detachedSig, p.blossomURL),
+		CIBuild:                   ciBuild(p.cfg),
+		Delegation:                p.delegation,
 	})
+	if err != nil {
+		return err
+	}
 	if p.opts.Publish.SkipAppEvent {
 		p.events.AppMetadata = nil
 	}
+	showAppMetadataDiff(p.opts, p.existingApp, p.events.AppMetadata)
 
 	return nostr.SignEventSet(ctx, p.signer, p.events, relayHint)
 }
 
-// getRelayHint returns the first relay URL for event references.
+// getRelayHint returns the relay URL for event references: the first of the
+// resolved publish relays, which include NIP-65 and community relays.
 func (p *Publisher) getRelayHint() string {
-	relayHint := nostr.DefaultRelay
-	if relaysEnv := config.GetEnv("RELAY_URLS"); relaysEnv != "" {
-		parts := strings.Split(relaysEnv, ",")
-		if len(parts) > 0 && strings.TrimSpace(parts[0]) != "" {
-			relayHint = strings.TrimSpace(parts[0])
+	if p.publisher != nil {
+		if relays := p.publisher.RelayURLs(); len(relays) > 0 {
+			return relays[0]
 		}
 	}
-	return relayHint
+	return nostr.DefaultRelay
 }
 
 // getReleaseTimestamp returns the release creation/publish timestamp.
@@ -1076,6 +2346,17 @@ func (p *Publisher) getReleaseTimestamp() time.Time {
 	return time.Time{}
 }
 
+// getPublishedAt returns the published_at date: --published-at if set,
+// otherwise the source release's publish date (zero when unknown).
+func (p *Publisher) getPublishedAt() time.Time {
+	if p.opts.Publish.PublishedAt != "" {
+		if t, err := cli.ParseTimestamp(p.opts.Publish.PublishedAt); err == nil {
+			return t
+		}
+	}
+	return p.getReleaseTimestamp()
+}
+
 // getOriginalURL returns the original download URL for the asset.
 // Returns empty string if the asset's URL should be excluded from the event
 // (e.g., versionless web sources where only Blossom URL should be used).
@@ -1090,14 +2371,19 @@ func (p *Publisher) getOriginalURL() string {
 }
 
 // matchVariant returns the variant name if the APK matches a variant pattern.
+// The release asset name is matched when there is one, as in selectAPK, since
+// a downloaded file may be cached under a different name.
 func (p *Publisher) matchVariant() string {
 	if len(p.cfg.Variants) == 0 {
 		return ""
 	}
 
 	filename := filepath.Base(p.apkInfo.FilePath)
-	for name, pattern := range p.cfg.Variants {
-		re, err := regexp.Compile(pattern)
+	if p.selectedAsset != nil && p.selectedAsset.Name != "" {
+		filename = p.selectedAsset.Name
+	}
+	for _, name := range slices.Sorted(maps.Keys(p.cfg.Variants)) {
+		re, err := regexp.Compile(p.cfg.Variants[name])
 		if err != nil {
 			continue
 		}
@@ -1110,25 +2396,121 @@ func (p *Publisher) matchVariant() string {
 
 // outputOffline outputs signed events to stdout and upload manifest to stderr.
 func (p *Publisher) outputOffline() error {
+	if err := p.events.CheckIDs(); err != nil {
+		return err
+	}
+
 	// Output events to stdout (JSON, one per line for piping to nak)
 	OutputEventsToStdout(p.events)
 
 	// Output upload manifest to stderr (human text or JSONL depending on --json)
-	p.outputUploadManifest()
+	return p.outputUploadManifest()
+}
+
+// checkBlobsPresent fails unless every blob the events reference is already on
+// the Blossom server (--sign-only).
+func (p *Publisher) checkBlobsPresent(ctx context.Context) error {
+	entries := p.uploadManifestEntries()
+	hashes := make([]string, len(entries))
+	for i, e := range entries {
+		hashes[i] = e.SHA256
+	}
+	exists := blossom.NewClient(p.blossomURL).ExistsBatch(ctx, hashes, 0)
+
+	var missing []string
+	for _, e := range entries {
+		if !exists[e.SHA256] {
+			missing = append(missing, fmt.Sprintf("%s (%s)", e.Description, e.SHA256))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("--sign-only: %d blob(s) not found on %s: %s; upload them first, or publish without --sign-only",
+			len(missing), p.blossomURL, strings.Join(missing, ", "))
+	}
+	return nil
+}
 
+// outputSignOnly outputs the signed events to stdout as JSONL. Their blobs are
+// already on the Blossom server, so there is no upload manifest.
+func (p *Publisher) outputSignOnly() error {
+	if err := p.events.CheckIDs(); err != nil {
+		return err
+	}
+	OutputEventsToStdout(p.events)
+	if p.opts.Publish.ManifestJSON != "" || p.opts.Publish.BlossomAuthOut != "" {
+		p.warn("--manifest-json and --blossom-auth-out do not apply with --sign-only; its blobs are already uploaded")
+	}
 	return nil
 }
 
 // UploadManifestEntry represents a file that must be uploaded to Blossom.
 type UploadManifestEntry struct {
-	Description string // Human-readable description (e.g., "APK", "Icon", "Screenshot 1")
-	FilePath    string // Local file path or "(from APK)" for extracted data
-	SHA256      string // SHA256 hash of the file
-	BlossomURL  string // Expected Blossom URL
+	Description string `json:"description"` // Human-readable description (e.g., "APK", "Icon", "Screenshot 1")
+	FilePath    string `json:"file_path"`   // Local file path or "(from APK)" for extracted data
+	SHA256      string `json:"sha256"`      // SHA256 hash of the file
+	BlossomURL  string `json:"blossom_url"` // Expected Blossom URL
+}
+
+// outputUploadManifest outputs the upload manifest to stderr, as JSON to the
+// --manifest-json destination and as auth events to --blossom-auth-out when set.
+func (p *Publisher) outputUploadManifest() error {
+	entries := p.uploadManifestEntries()
+	// The manifest points into the run's temp directory for files uploaded
+	// later; leave it for the startup sweep instead of removing it on exit
+	tempdir.Keep()
+	OutputUploadManifest(entries, p.blossomURL, p.opts)
+	if err := p.writeManifestJSON(entries); err != nil {
+		return err
+	}
+	return p.writeBlossomAuthEvents(entries)
+}
+
+// uploadAuthExpiration is how long the unsigned upload auth events written with
+// --blossom-auth-out stay valid, leaving time to sign them externally.
+const uploadAuthExpiration = 24 * time.Hour
+
+// writeBlossomAuthEvents writes an unsigned kind 24242 upload auth event per
+// manifest entry to the --blossom-auth-out destination, if any. Only npub
+// signers need them; other signers authorize uploads themselves.
+func (p *Publisher) writeBlossomAuthEvents(entries []UploadManifestEntry) error {
+	path := p.opts.Publish.BlossomAuthOut
+	if path == "" {
+		return nil
+	}
+	if p.signer == nil || p.signer.Type() != nostr.SignerNpub {
+		p.warn("--blossom-auth-out only applies with an npub signer; no auth events written")
+		return nil
+	}
+
+	expiration := time.Now().Add(uploadAuthExpiration)
+	authEvents := make([]*gonostr.Event, len(entries))
+	for i, e := range entries {
+		authEvents[i] = nostr.BuildBlossomAuthEvent(e.SHA256, p.signer.PublicKey(), expiration)
+		authEvents[i].ID = authEvents[i].GetID()
+	}
+	if err := WriteBlossomAuthEvents(authEvents, path); err != nil {
+		return fmt.Errorf("failed to write Blossom auth events: %w", err)
+	}
+	if p.opts.ShouldShowSpinners() {
+		OutputUploadRecipe(entries, p.blossomURL, path)
+	}
+	return nil
+}
+
+// writeManifestJSON writes the upload manifest to the --manifest-json destination, if any.
+func (p *Publisher) writeManifestJSON(entries []UploadManifestEntry) error {
+	if p.opts.Publish.ManifestJSON == "" {
+		return nil
+	}
+	if err := WriteUploadManifestJSON(entries, p.opts.Publish.ManifestJSON); err != nil {
+		return fmt.Errorf("failed to write upload manifest: %w", err)
+	}
+	return nil
 }
 
-// outputUploadManifest outputs the upload manifest to stderr.
-func (p *Publisher) outputUploadManifest() {
+// uploadManifestEntries lists the blobs the events reference: the APK, its
+// detached signature, icon, screenshots and release notes images.
+func (p *Publisher) uploadManifestEntries() []UploadManifestEntry {
 	var entries []UploadManifestEntry
 
 	// APK entry
@@ -1139,6 +2521,16 @@ func (p *Publisher) outputUploadManifest() {
 		BlossomURL:  fmt.Sprintf("%s/%s", p.blossomURL, p.apkInfo.SHA256),
 	})
 
+	// Detached signature entry
+	if sig := p.detachedSig; sig != nil {
+		entries = append(entries, UploadManifestEntry{
+			Description: fmt.Sprintf("Detached signature (%s)", sig.Type),
+			FilePath:    p.saveToTemp("signature", sig.Data, sig.SHA256),
+			SHA256:      sig.SHA256,
+			BlossomURL:  fmt.Sprintf("%s/%s", p.blossomURL, sig.SHA256),
+		})
+	}
+
 	// Icon entry
 	if p.iconURL != "" {
 		hash := extractHashFromBlossomURL(p.iconURL)
@@ -1163,12 +2555,26 @@ func (p *Publisher) outputUploadManifest() {
 		})
 	}
 
-	// Output manifest to stderr
-	OutputUploadManifest(entries, p.blossomURL, p.opts)
+	// Release notes image entries (release_notes_event)
+	for i, img := range p.notesImages {
+		entries = append(entries, UploadManifestEntry{
+			Description: fmt.Sprintf("Release notes image %d", i+1),
+			FilePath:    p.saveToTemp("notes-image", img.Data, img.Hash),
+			SHA256:      img.Hash,
+			BlossomURL:  fmt.Sprintf("%s/%s", p.blossomURL, img.Hash),
+		})
+	}
+
+	return entries
 }
 
 // resolveIconPath returns the path to the icon file, saving APK-extracted icons to temp.
 func (p *Publisher) resolveIconPath(hash string) string {
+	// A padded local icon (icon_autofix) is held like a downloaded one
+	if p.cfg.Icon != "" && !isRemoteURL(p.cfg.Icon) && p.preDownloaded != nil && p.preDownloaded.Icon != nil {
+		return p.saveToTemp("icon", p.preDownloaded.Icon.Data, hash)
+	}
+
 	// Config icon takes precedence
 	if p.cfg.Icon != "" {
 		if isRemoteURL(p.cfg.Icon) {
@@ -1209,10 +2615,10 @@ func (p *Publisher) resolveImagePath(index int, hash string) string {
 	return "(none)"
 }
 
-// saveToTemp saves data to a temp file and returns the path.
+// saveToTemp saves data to a file in the run's temp directory and returns the path.
 func (p *Publisher) saveToTemp(prefix string, data []byte, hash string) string {
 	// Use hash as filename for easy identification
-	tmpFile := filepath.Join(os.TempDir(), fmt.Sprintf("zsp_%s_%s", prefix, hash[:16]))
+	tmpFile := filepath.Join(tempdir.Dir(), fmt.Sprintf("zsp_%s_%s", prefix, hash[:16]))
 	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
 		return fmt.Sprintf("(failed to save: %v)", err)
 	}
@@ -1235,19 +2641,32 @@ func (p *Publisher) outputNpubEvents() error {
 		fmt.Println()
 		ui.PrintInfo("npub mode - outputting unsigned events for external signing")
 	}
+	// The release references the assets by these IDs, so the signer must not alter the events
+	if err := p.events.CheckIDs(); err != nil {
+		return err
+	}
 	OutputEvents(p.events)
+	if err := p.outputUploadManifest(); err != nil {
+		return err
+	}
 	if p.opts.ShouldShowSpinners() {
-		ui.PrintCompletionSummary(true, "Unsigned events generated - sign externally before publishing")
+		ui.PrintInfo("Sign the events unchanged: the release references the asset events by ID")
+		ui.PrintCompletionSummary(true, "Unsigned events generated - upload the files and sign externally before publishing")
 	}
 	return nil
 }
 
 // publishToRelays publishes events to configured relays.
 func (p *Publisher) publishToRelays(ctx context.Context) error {
-	// Confirm before publishing
-	if !p.opts.Publish.Quiet && !p.opts.Global.JSON {
+	minSuccess := p.opts.Publish.MinRelaySuccess
+
+	// Explain likely rejections (size limits, kind restrictions, paid relays) up front
+	p.relayPreflight(ctx)
+
+	// Confirm before publishing (--dev auto-confirms)
+	if p.opts.IsInteractive() {
 		isClosedSource := p.cfg.Repository == ""
-		confirmed, err := confirmPublish(p.events, p.publisher.RelayURLs(), p.apkInfo.SHA256, isClosedSource)
+		confirmed, err := confirmPublish(p.events, p.publisher.AllRelayURLs(), p.apkInfo.SHA256, isClosedSource)
 		if err != nil {
 			return fmt.Errorf("confirmation failed: %w", err)
 		}
@@ -1258,14 +2677,21 @@ func (p *Publisher) publishToRelays(ctx context.Context) error {
 		}
 	}
 
+	// Link the throwaway key to its owner before anything is published with it
+	if p.opts.Publish.EphemeralSecret != "" && !p.attested {
+		p.attestEphemeralKey(ctx)
+	}
+
 	// Publish with spinner
 	var publishSpinner *ui.Spinner
 	if p.opts.ShouldShowSpinners() {
-		publishSpinner = ui.NewSpinner(fmt.Sprintf("Publishing to %d relays...", len(p.publisher.RelayURLs())))
+		publishSpinner = ui.NewSpinner(fmt.Sprintf("Publishing to %d relays...", len(p.publisher.AllRelayURLs())))
 		publishSpinner.Start()
 	}
 
+	publishStart := time.Now()
 	results, err := p.publisher.PublishEventSet(ctx, p.events)
+	metrics.Since(metrics.StagePublish, publishStart)
 	if err != nil {
 		if publishSpinner != nil {
 			publishSpinner.StopWithError("Failed to publish")
@@ -1277,33 +2703,39 @@ func (p *Publisher) publishToRelays(ctx context.Context) error {
 	allSuccess := true
 	hasDuplicates := false
 	var messages []string
-	// Track per-event-type success: an event is considered published if it
-	// succeeded on at least one relay. If every relay rejected an event, that
-	// event failed to publish and zsp must exit non-zero (critical for CI).
-	eventHasSuccess := make(map[string]bool, len(results))
-	for eventType, eventResults := range results {
-		for _, r := range eventResults {
+	// With relay_routes each event type goes to different relays: show every outcome
+	showEach := p.opts.Global.Verbose || p.publisher.HasRoutes()
+	for _, eventType := range slices.Sorted(maps.Keys(results)) {
+		for _, r := range results[eventType] {
 			if r.Success {
-				eventHasSuccess[eventType] = true
 				if r.IsDuplicate {
 					hasDuplicates = true
 					messages = append(messages, fmt.Sprintf("    %s -> %s: already exists", eventType, r.RelayURL))
-				} else if p.opts.Global.Verbose {
+				} else if showEach {
 					messages = append(messages, fmt.Sprintf("    %s -> %s: OK", eventType, r.RelayURL))
 				}
 			} else {
-				messages = append(messages, fmt.Sprintf("    %s -> %s: FAILED (%v)", eventType, r.RelayURL, r.Error))
+				metrics.Inc(metrics.RelayFailures, "relay", r.RelayURL)
 				allSuccess = false
+				if p.publisher.HasRoutes() {
+					messages = append(messages, fmt.Sprintf("    %s -> %s: failed", eventType, r.RelayURL))
+				}
 			}
 		}
 	}
+	for _, summary := range nostr.RelayFailureSummaries(results) {
+		messages = append(messages, "    "+summary)
+	}
 
-	// An event entirely rejected by all relays is a hard failure.
-	var failedEventTypes []string
-	for eventType := range results {
-		if !eventHasSuccess[eventType] {
-			failedEventTypes = append(failedEventTypes, eventType)
-		}
+	// An event entirely rejected by all relays is a hard failure. With
+	// --min-relay-success N, an event accepted by fewer than N relays is too.
+	failedEventTypes := eventsBelowQuorum(results, max(minSuccess, 1))
+
+	// By default the run succeeds only if every relay accepted every event.
+	// With --min-relay-success, reaching the quorum is enough.
+	succeeded := allSuccess
+	if minSuccess > 0 {
+		succeeded = len(failedEventTypes) == 0
 	}
 
 	if publishSpinner != nil {
@@ -1313,6 +2745,8 @@ func (p *Publisher) publishToRelays(ctx context.Context) error {
 			} else {
 				publishSpinner.StopWithSuccess("Published successfully")
 			}
+		} else if succeeded {
+			publishSpinner.StopWithWarning(fmt.Sprintf("Published to at least %d relays (some relays failed)", minSuccess))
 		} else {
 			publishSpinner.StopWithWarning("Published with some failures")
 		}
@@ -1322,9 +2756,25 @@ func (p *Publisher) publishToRelays(ctx context.Context) error {
 		fmt.Println(msg)
 	}
 
+	// A required relay that stored only part of the release fails the run
+	partialErr := p.handlePartialPublish(ctx, results)
+	if partialErr != nil {
+		succeeded = false
+	}
+
+	// Read the replaceable events back: a relay can accept an event yet keep a newer one
+	var verifyErr error
+	if p.opts.Publish.VerifyAfterPublish && succeeded {
+		verifyErr = p.verifyPublished(ctx, results)
+		succeeded = verifyErr == nil
+	}
+
 	// Commit or clear cache
-	if allSuccess {
+	p.published = succeeded
+	if succeeded {
 		p.commitCache()
+		p.recordHistory()
+		p.addToCurationSet(ctx)
 	} else {
 		p.clearCache()
 		if p.opts.Global.Verbose {
@@ -1334,16 +2784,16 @@ func (p *Publisher) publishToRelays(ctx context.Context) error {
 
 	// Print completion summary
 	if p.opts.ShouldShowSpinners() {
-		if allSuccess {
+		if succeeded {
 			ui.PrintCompletionSummary(true, fmt.Sprintf("Published %s v%s to %s",
-				p.apkInfo.PackageID, p.apkInfo.VersionName, strings.Join(p.publisher.RelayURLs(), ", ")))
+				p.apkInfo.PackageID, p.apkInfo.VersionName, strings.Join(p.publisher.AllRelayURLs(), ", ")))
 		} else {
 			ui.PrintCompletionSummary(false, "Published with some failures")
 		}
 	}
 
 	// Show zapstore.dev URL if the app was successfully published to relay.zapstore.dev
-	if allSuccess && !p.opts.Publish.Quiet && !p.opts.Global.JSON {
+	if succeeded && !p.opts.Publish.Quiet && !p.opts.Global.JSON {
 		p.showZapstoreURL(results)
 	}
 
@@ -1355,19 +2805,149 @@ func (p *Publisher) publishToRelays(ctx context.Context) error {
 	// If any event was rejected by every relay, publishing did not succeed.
 	// Returning an error ensures zsp exits non-zero so CI pipelines (GitHub
 	// Actions, etc.) surface the failure instead of silently passing.
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if partialErr != nil {
+		return partialErr
+	}
 	if len(failedEventTypes) > 0 {
-		sort.Strings(failedEventTypes)
+		if minSuccess > 1 {
+			return fmt.Errorf("event(s) accepted by fewer than %d relays: %s", minSuccess, strings.Join(failedEventTypes, ", "))
+		}
 		return fmt.Errorf("failed to publish event(s) to any relay: %s", strings.Join(failedEventTypes, ", "))
 	}
 
 	return nil
 }
 
+// handlePartialPublish returns an error when the required relay (the Zapstore
+// relay, or the first relay) rejected the app event, the release or every
+// asset. The events it did store would show a half-published app, so unless
+// --no-retract-on-partial is set they are retracted with a deletion request,
+// except an app event or release that replaced an earlier one.
+func (p *Publisher) handlePartialPublish(ctx context.Context, results map[string][]nostr.PublishResult) error {
+	relayURL := verifyRelayURL(p.publisher.RelayURLs())
+	partial := nostr.FindPartialPublish(results, relayURL)
+	if partial == nil {
+		return nil
+	}
+	err := fmt.Errorf("%s rejected required event(s): %s", relayURL, strings.Join(partial.Rejected, ", "))
+
+	// The app event and the release are addressable, so on this relay they
+	// already replaced the previous ones: retracting them would delist the app
+	// or the release. They are only rolled back when nothing existed before.
+	for _, kept := range []struct {
+		key, label string
+		replaced   bool
+	}{
+		{"software_application", "app event", p.existingApp != nil || !p.appChecked},
+		{"software_release", "release", p.opts.Publish.OverwriteRelease || !p.existingReleaseTimestamp.IsZero()},
+	} {
+		if !kept.replaced || !slices.Contains(partial.Accepted, kept.key) {
+			continue
+		}
+		partial.Accepted = slices.DeleteFunc(partial.Accepted, func(key string) bool { return key == kept.key })
+		p.status("    Kept the %s on %s: it replaced the previously published one", kept.label, relayURL)
+	}
+	if len(partial.Accepted) == 0 {
+		return err
+	}
+	accepted := strings.Join(partial.Accepted, ", ")
+	if p.opts.Publish.NoRetractOnPartial {
+		p.warn(fmt.Sprintf("%s keeps the %s event(s) of this release (--no-retract-on-partial)", relayURL, accepted))
+		return err
+	}
+
+	events := make([]*gonostr.Event, len(partial.Accepted))
+	for i, key := range partial.Accepted {
+		events[i] = p.events.EventByKey(key)
+	}
+	if rerr := p.publisher.Retract(ctx, p.signer, relayURL, events, "Partially published release rolled back: "+err.Error()); rerr != nil {
+		p.warn(fmt.Sprintf("could not retract %s from %s: %v; the app is half-published there", accepted, relayURL, rerr))
+		return err
+	}
+	p.status("    Rolled back on %s: %s (deletion request)", relayURL, accepted)
+	return fmt.Errorf("%w; retracted %s", err, accepted)
+}
+
+// verifyPublished reads the app, release and release notes events back from the verification relay
+// and returns an error naming each event the relay replaced with another one.
+// Events the relay did not accept are not checked; they are already reported as failed.
+func (p *Publisher) verifyPublished(ctx context.Context, results map[string][]nostr.PublishResult) error {
+	relayURL := verifyRelayURL(p.publisher.RelayURLs())
+	accepted := func(eventType string) bool {
+		for _, r := range results[eventType] {
+			if r.RelayURL == relayURL && r.Success {
+				return true
+			}
+		}
+		return false
+	}
+
+	var eventTypes []string
+	var events []*gonostr.Event
+	if p.events.AppMetadata != nil && accepted("software_application") {
+		eventTypes = append(eventTypes, "software_application")
+		events = append(events, p.events.AppMetadata)
+	}
+	if accepted("software_release") {
+		eventTypes = append(eventTypes, "software_release")
+		events = append(events, p.events.Release)
+	}
+	if p.events.ReleaseNotes != nil && accepted("release_notes") {
+		eventTypes = append(eventTypes, "release_notes")
+		events = append(events, p.events.ReleaseNotes)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	var verifySpinner *ui.Spinner
+	if p.opts.ShouldShowSpinners() {
+		verifySpinner = ui.NewSpinner(fmt.Sprintf("Verifying events on %s...", relayURL))
+		verifySpinner.Start()
+	}
+
+	var failed, messages []string
+	for i, r := range p.publisher.ReadBack(ctx, relayURL, events) {
+		var detail string
+		switch {
+		case r.Matches():
+			continue
+		case r.Stored != nil:
+			detail = fmt.Sprintf("relay kept event %s, created_at %s",
+				r.Stored.ID, r.Stored.CreatedAt.Time().UTC().Format(time.RFC3339))
+		case r.Error != nil:
+			detail = fmt.Sprintf("read back failed: %v", r.Error)
+		default:
+			detail = "not found"
+		}
+		failed = append(failed, fmt.Sprintf("%s (%s)", eventTypes[i], detail))
+		messages = append(messages, fmt.Sprintf("    %s -> %s: MISMATCH (%s)", eventTypes[i], relayURL, detail))
+	}
+
+	if len(failed) == 0 {
+		if verifySpinner != nil {
+			verifySpinner.StopWithSuccess("Verified events on " + relayURL)
+		}
+		return nil
+	}
+	if verifySpinner != nil {
+		verifySpinner.StopWithError("Relay does not store the published events")
+	}
+	for _, msg := range messages {
+		fmt.Println(msg)
+	}
+	return fmt.Errorf("%s does not store the published event(s): %s", relayURL, strings.Join(failed, "; "))
+}
+
 // uploadBlobs executes pending Blossom uploads after events have been published to relays.
 func (p *Publisher) uploadBlobs(ctx context.Context) error {
 	if p.pendingUploads == nil {
 		return nil
 	}
+	defer metrics.Since(metrics.StageUpload, time.Now())
 	if err := p.pendingUploads.Execute(ctx); err != nil {
 		return err
 	}
@@ -1375,6 +2955,48 @@ func (p *Publisher) uploadBlobs(ctx context.Context) error {
 	return nil
 }
 
+// uploadBlobsPartial uploads every pending blob before publishing, continuing past
+// failures (--partial-assets). A failed APK upload aborts the run, since the release
+// cannot be installed without it, and so does a failed detached signature upload,
+// since the signed asset event references it. Failed icon and screenshot uploads are listed and
+// removed from the app event, which is then re-signed.
+func (p *Publisher) uploadBlobsPartial(ctx context.Context) error {
+	if p.pendingUploads == nil {
+		return nil
+	}
+	uploadStart := time.Now()
+	failures, err := p.pendingUploads.ExecuteAll(ctx)
+	metrics.Since(metrics.StageUpload, uploadStart)
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		p.deleteCachedAPK()
+		return nil
+	}
+
+	var dropped []string
+	for _, f := range failures {
+		if f.IsAPK || f.Type == "signature" {
+			return fmt.Errorf("nothing was published: %w", f.Err)
+		}
+		p.warn(fmt.Sprintf("dropped %s %s: %v", f.Type, f.Hash, f.Err))
+		dropped = append(dropped, f.Hash)
+	}
+	p.deleteCachedAPK()
+	p.fingerprint = nil // an incomplete publish should not make the next run a no-op
+
+	if p.events.AppMetadata != nil && dropBlobTags(p.events.AppMetadata, dropped) {
+		if err := p.signer.Sign(ctx, p.events.AppMetadata); err != nil {
+			return fmt.Errorf("failed to re-sign Software Application event: %w", err)
+		}
+	}
+	if p.opts.ShouldShowSpinners() {
+		ui.PrintWarning(fmt.Sprintf("Publishing without %d file(s) that failed to upload", len(dropped)))
+	}
+	return nil
+}
+
 // showZapstoreURL prints the zapstore.dev app URL if the app was published to relay.zapstore.dev.
 func (p *Publisher) showZapstoreURL(results map[string][]nostr.PublishResult) {
 	if p.events.AppMetadata == nil {
@@ -1409,6 +3031,79 @@ func (p *Publisher) showZapstoreURL(results map[string][]nostr.PublishResult) {
 	fmt.Printf("  View your app: https://zapstore.dev/apps/%s\n\n", identifier)
 }
 
+// recordHistory adds the published events to the local history (zsp history).
+// Failing to record is not a publish failure.
+func (p *Publisher) recordHistory() {
+	events := make([]*gonostr.Event, 0, 2+len(p.events.SoftwareAssets))
+	if p.events.AppMetadata != nil {
+		events = append(events, p.events.AppMetadata)
+	}
+	events = append(events, p.events.Release)
+	events = append(events, p.events.SoftwareAssets...)
+	if p.events.ReleaseNotes != nil {
+		events = append(events, p.events.ReleaseNotes)
+	}
+
+	err := history.Append(history.Entry{
+		PackageID:   p.apkInfo.PackageID,
+		Version:     p.apkInfo.VersionName,
+		PublishedAt: time.Now(),
+		Relays:      p.publisher.RelayURLs(),
+		Events:      events,
+	})
+	if err != nil && p.opts.Global.Verbose {
+		fmt.Printf("  Could not record publish history: %v\n", err)
+	}
+}
+
+// writeFDroidRepo adds the APK, its icon and an index-v1.json entry to the
+// unsigned F-Droid repo configured with fdroid_repo_out, if any.
+func (p *Publisher) writeFDroidRepo() error {
+	if p.cfg.FDroidRepoOut == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(resolvePath(p.cfg.FDroidRepoOut, p.cfg.BaseDir))
+	if err != nil {
+		return fmt.Errorf("invalid fdroid_repo_out: %w", err)
+	}
+	err = WriteFDroidRepo(FDroidRepoParams{
+		Dir:     dir,
+		APKInfo: p.apkInfo,
+		Config:  p.cfg,
+		Icon:    p.fdroidIcon(),
+		Now:     p.now(),
+	})
+	if err != nil {
+		return err
+	}
+	if p.opts.ShouldShowSpinners() {
+		fmt.Fprintf(os.Stderr, "  Added %s v%s to F-Droid repo %s\n", p.apkInfo.PackageID, p.apkInfo.VersionName, dir)
+	}
+	return nil
+}
+
+// fdroidIcon returns the icon for the F-Droid repo, picked like the published
+// icon (downloaded or local config icon, then the APK icon) but PNG only,
+// since F-Droid clients expect PNG icons. Returns nil when there is none.
+func (p *Publisher) fdroidIcon() []byte {
+	var candidates [][]byte
+	if p.preDownloaded != nil && p.preDownloaded.Icon != nil {
+		candidates = append(candidates, p.preDownloaded.Icon.Data)
+	}
+	if p.cfg.Icon != "" && !isRemoteURL(p.cfg.Icon) {
+		if data, err := os.ReadFile(resolvePath(p.cfg.Icon, p.cfg.BaseDir)); err == nil {
+			candidates = append(candidates, data)
+		}
+	}
+	candidates = append(candidates, p.apkInfo.Icon)
+	for _, data := range candidates {
+		if bytes.HasPrefix(data, pngSignature) {
+			return data
+		}
+	}
+	return nil
+}
+
 // clearCache clears the source cache.
 func (p *Publisher) clearCache() {
 	if cacheClearer, ok := p.src.(source.CacheClearer); ok {
@@ -1416,8 +3111,66 @@ func (p *Publisher) clearCache() {
 	}
 }
 
+// checkCurationSetOwner verifies that the signer owns the --add-to-set set.
+func (p *Publisher) checkCurationSetOwner() error {
+	if p.signer.Type() == nostr.SignerNpub {
+		return fmt.Errorf("--add-to-set re-signs the curation set and needs a signer that can sign")
+	}
+	if p.signer.PublicKey() != p.curationSet.Pubkey {
+		owner, _ := nip19.EncodePublicKey(p.curationSet.Pubkey)
+		return fmt.Errorf("--add-to-set: the curation set is owned by %s, which does not match SIGN_WITH", owner)
+	}
+	return nil
+}
+
+// addToCurationSet adds the published app to the --add-to-set curation set
+// and re-publishes it. The app is already published, so failures are warnings.
+func (p *Publisher) addToCurationSet(ctx context.Context) {
+	if p.curationSet == nil {
+		return
+	}
+
+	current, err := p.publisher.FetchCurationSet(ctx, p.curationSet)
+	if err != nil {
+		p.warn(fmt.Sprintf("could not fetch the curation set: %v", err))
+		return
+	}
+	if current == nil {
+		p.warn("curation set not found on any relay; the app was not added to it")
+		return
+	}
+
+	updated := nostr.AddToCurationSet(current, nostr.AppAddress(p.signer.PublicKey(), p.appIdentifier()), p.getRelayHint())
+	if updated == nil {
+		fmt.Println("  App is already in the curation set")
+		return
+	}
+	if err := p.signer.Sign(ctx, updated); err != nil {
+		p.warn(fmt.Sprintf("could not sign the curation set: %v", err))
+		return
+	}
+
+	accepted := 0
+	for _, r := range p.publisher.Publish(ctx, updated) {
+		if r.Success {
+			accepted++
+		} else if p.opts.Global.Verbose {
+			fmt.Printf("    curation set -> %s: %v\n", r.RelayURL, r.Error)
+		}
+	}
+	if accepted == 0 {
+		p.warn("no relay accepted the updated curation set")
+		return
+	}
+	ui.PrintSuccess(fmt.Sprintf("Added to curation set %q", p.curationSet.Identifier))
+}
+
 // commitCache commits the source cache to disk.
 func (p *Publisher) commitCache() {
+	// A test publish with a throwaway key must not make the real one look unchanged
+	if p.opts.Publish.EphemeralKey {
+		return
+	}
 	if cacheCommitter, ok := p.src.(source.CacheCommitter); ok {
 		_ = cacheCommitter.CommitCache()
 	}
@@ -1431,6 +3184,34 @@ func (p *Publisher) deleteCachedAPK() {
 	_ = source.DeleteCachedDownload(p.selectedAsset.URL, p.selectedAsset.Name)
 }
 
+// PackageID returns the parsed APK's package ID, or "" if no APK has been parsed yet.
+func (p *Publisher) PackageID() string {
+	if p.apkInfo == nil {
+		return ""
+	}
+	return p.apkInfo.PackageID
+}
+
+// Endpoints returns the relays events are published to and the Blossom server
+// files are uploaded to.
+func (p *Publisher) Endpoints() (relayURLs []string, blossomURL string) {
+	return p.publisher.RelayURLs(), p.blossomURL
+}
+
+// releaseTagName returns the upstream git tag of the release being published, if any.
+func (p *Publisher) releaseTagName() string {
+	if p.release == nil {
+		return ""
+	}
+	return p.release.TagName
+}
+
+// appIdentifier returns the d tag of the app event this run publishes, which
+// carries the channel with --channel-suffix.
+func (p *Publisher) appIdentifier() string {
+	return nostr.AppIdentifier(p.apkInfo.PackageID, p.opts.Publish.Channel, p.opts.Publish.ChannelSuffix)
+}
+
 // Close releases resources.
 func (p *Publisher) Close() {
 	if p.signer != nil {
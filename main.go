@@ -11,19 +11,26 @@ import (
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/zapstore/zsp/internal/apk"
+	"github.com/zapstore/zsp/internal/bugreport"
 	"github.com/zapstore/zsp/internal/cli"
 	"github.com/zapstore/zsp/internal/config"
 	"github.com/zapstore/zsp/internal/help"
 	"github.com/zapstore/zsp/internal/identity"
+	"github.com/zapstore/zsp/internal/metrics"
+	"github.com/zapstore/zsp/internal/netpolicy"
+	"github.com/zapstore/zsp/internal/nettrace"
 	nostrpkg "github.com/zapstore/zsp/internal/nostr"
 	"github.com/zapstore/zsp/internal/picker"
+	"github.com/zapstore/zsp/internal/publock"
 	"github.com/zapstore/zsp/internal/source"
+	"github.com/zapstore/zsp/internal/tempdir"
 	"github.com/zapstore/zsp/internal/ui"
 	"github.com/zapstore/zsp/internal/workflow"
 )
@@ -53,8 +60,12 @@ func main() {
 	sigHandler := cli.NewSignalHandler()
 	defer sigHandler.Stop()
 
+	// Temp files live in a per-run directory, removed on exit or interrupt
+	sigHandler.OnCleanup(tempdir.Cleanup)
+
 	// Run the main logic
 	exitCode := run(sigHandler)
+	tempdir.Cleanup()
 
 	// Exit with appropriate code
 	os.Exit(exitCode)
@@ -72,8 +83,9 @@ func run(sigHandler *cli.SignalHandler) int {
 		return 1
 	}
 
-	// Set version for UI rendering
+	// Set version for UI rendering and the client tag of published events
 	ui.SetVersion(getVersion())
+	nostrpkg.SetClientVersion(getVersion())
 
 	// --json implies --no-color (no ANSI escapes in machine-readable output)
 	if opts.Global.JSON {
@@ -103,14 +115,41 @@ func run(sigHandler *cli.SignalHandler) int {
 		return 0
 	}
 
+	if opts.Global.Trace {
+		nettrace.Enable(os.Stderr)
+	}
+
+	// Remove run directories left by runs that were killed before cleaning up
+	_, _ = tempdir.Sweep(tempdir.MaxAge)
+
+	// ZSP_ALLOWED_HOSTS applies to every command; publish also merges network_allowlist
+	netpolicy.SetAllowlist(netpolicy.ParsePatterns(config.GetEnv(netpolicy.EnvAllowedHosts)))
+
 	// Dispatch to subcommand
 	switch opts.Command {
 	case cli.CommandPublish:
+		if opts.Publish.ProgressJSON || opts.Publish.ProgressFD != "" || opts.Publish.AnswersFile != "" {
+			return runPublishWithProgress(ctx, opts)
+		}
 		return runPublishCommand(ctx, opts)
 	case cli.CommandIdentity:
 		return runIdentityCommand(ctx, opts)
 	case cli.CommandUtils:
 		return runUtilsCommand(ctx, opts)
+	case cli.CommandConfig:
+		return runConfigCommand(ctx, opts)
+	case cli.CommandHistory:
+		return runHistoryCommand(ctx, opts)
+	case cli.CommandAPK:
+		return runAPKCommand(ctx, opts)
+	case cli.CommandBlossom:
+		return runBlossomCommand(ctx, opts)
+	case cli.CommandRelay:
+		return runRelayCommand(ctx, opts)
+	case cli.CommandKeys:
+		return runKeysCommand(ctx, opts)
+	case cli.CommandExport:
+		return runExportCommand(ctx, opts)
 	default:
 		// No subcommand - show help
 		help.HandleHelp(cli.CommandNone, nil)
@@ -125,6 +164,35 @@ func runPublishCommand(ctx context.Context, opts *cli.Options) int {
 		ui.SetNoColor(true)
 	}
 
+	// Several config files are a batch of independent publishes
+	if len(opts.Args) > 1 && workflow.AllConfigArgs(opts.Args) {
+		if opts.Publish.Wizard {
+			err := fmt.Errorf("--wizard takes a single config file")
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+		return workflow.PublishBatch(ctx, opts, runPublishCommand)
+	}
+
+	// adb://<package>: pull the installed APK from a connected device and treat it as a local file
+	if len(opts.Args) > 0 && source.IsADBRef(opts.Args[0]) {
+		apkPath, cleanup, err := source.PullFromADB(ctx, opts.Args[0])
+		if err != nil {
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+		defer cleanup()
+		opts.Args[0] = apkPath
+	}
+
 	// Handle --check flag (validates config without publishing)
 	if opts.Publish.Check {
 		if err := checkAPK(ctx, opts); err != nil {
@@ -157,6 +225,34 @@ func runPublishCommand(ctx context.Context, opts *cli.Options) int {
 		return 1
 	}
 
+	// Apply CLI flag overrides
+	if opts.Publish.BaseDir != "" {
+		if err := cfg.SetBaseDir(opts.Publish.BaseDir); err != nil {
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+	}
+	if opts.Publish.Match != "" {
+		cfg.Match = opts.Publish.Match
+	}
+	if opts.Publish.MatchLabel != "" {
+		cfg.MatchLabel = opts.Publish.MatchLabel
+	}
+	if opts.Publish.ChangelogFrom == "git" {
+		cfg.ReleaseNotes = source.GitLogReleaseNotes
+	}
+
+	// Translate glob-looking patterns (e.g. "*arm64*.apk") to regex
+	for _, notice := range cfg.NormalizePatterns() {
+		if !opts.Publish.Quiet && !opts.Global.JSON {
+			fmt.Fprintf(os.Stderr, "notice: %s\n", notice)
+		}
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		if opts.Global.JSON {
@@ -167,8 +263,25 @@ func runPublishCommand(ctx context.Context, opts *cli.Options) int {
 		return 1
 	}
 
+	// A config with an apps: list publishes each (selected) app in turn
+	apps, err := selectApps(opts, cfg)
+	if err == nil && len(apps) == 0 && opts.Publish.Variant != "" {
+		_, err = cfg.VariantPattern(opts.Publish.Variant)
+	}
+	if err != nil {
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+
+	// Restrict outbound connections when network_allowlist / ZSP_ALLOWED_HOSTS is set
+	netpolicy.SetAllowlist(cfg.AllowedHosts())
+
 	// Validate CLI options
-	if err := opts.Publish.ValidateChannel(); err != nil {
+	if err := opts.Publish.Validate(); err != nil {
 		if opts.Global.JSON {
 			ui.PrintJSONError(err)
 		} else {
@@ -177,13 +290,62 @@ func runPublishCommand(ctx context.Context, opts *cli.Options) int {
 		return 1
 	}
 
-	// Apply CLI flag overrides
-	if opts.Publish.Match != "" {
-		cfg.Match = opts.Publish.Match
+	// --dev points relays, Blossom and the signer at local dev infrastructure
+	if opts.Publish.Dev {
+		if err := workflow.ApplyDevPreset(opts); err != nil {
+			if errors.Is(err, ui.ErrInterrupted) {
+				return 130
+			}
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+	}
+
+	// Handle --explain (outlines the publish steps without running them)
+	if opts.Publish.Explain {
+		explainPlan(opts, cfg, apps)
+		return 0
+	}
+
+	// Handle --explain-selection (reports asset selection without publishing)
+	if opts.Publish.ExplainSelection {
+		if err := workflow.ExplainSelection(ctx, opts, cfg); err != nil {
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+		return 0
+	}
+
+	// --ephemeral-key signs with a throwaway key, printed when the run ends
+	if opts.Publish.EphemeralKey {
+		workflow.ApplyEphemeralKey(opts)
+		defer workflow.PrintEphemeralKey(opts)
+	}
+
+	// --limit-rate throttles APK downloads and Blossom uploads
+	workflow.ApplyRateLimit(opts)
+
+	// Record metrics for --metrics-out; the file is written when the command returns
+	if opts.Publish.MetricsOut != "" {
+		metrics.Enable()
+		defer workflow.WriteMetrics(opts)
+	}
+
+	if len(apps) > 0 {
+		return runPublishApps(ctx, opts, apps)
 	}
 
 	// Run the publish workflow
-	if err := runPublish(ctx, opts, cfg); err != nil {
+	report := &bugreport.Report{Version: getVersion(), Config: cfg}
+	if err := runPublish(ctx, opts, cfg, report); err != nil {
 		if errors.Is(err, workflow.ErrNothingToDo) {
 			return 0
 		}
@@ -195,12 +357,60 @@ func runPublishCommand(ctx context.Context, opts *cli.Options) int {
 		} else {
 			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
 		}
+		if errors.Is(err, publock.ErrLocked) {
+			return exitInProgress
+		}
+		workflow.OfferBugReport(opts, report, err)
 		return 1
 	}
 
 	return 0
 }
 
+// runPublishWithProgress runs the publish subcommand with --progress-json:
+// progress, prompts, warnings and the result are sent as JSON lines to stderr
+// or --progress-fd, and prompts are answered from --answers-file or stdin.
+func runPublishWithProgress(ctx context.Context, opts *cli.Options) int {
+	fail := func(err error) int {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		return 1
+	}
+	if err := opts.ValidateProgressJSON(); err != nil {
+		return fail(err)
+	}
+
+	var answers map[string]string
+	if opts.Publish.AnswersFile != "" {
+		var err error
+		if answers, err = ui.LoadAnswers(opts.Publish.AnswersFile); err != nil {
+			return fail(err)
+		}
+	}
+
+	out := os.Stderr
+	if target := opts.Publish.ProgressFD; target != "" {
+		if fd, err := strconv.Atoi(target); err == nil {
+			if fd < 0 {
+				return fail(fmt.Errorf("invalid --progress-fd %d", fd))
+			}
+			out = os.NewFile(uintptr(fd), "progress-fd")
+		} else {
+			// A named pipe (or plain file) the wrapping UI reads from
+			if out, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600); err != nil {
+				return fail(fmt.Errorf("failed to open --progress-fd: %w", err))
+			}
+		}
+		defer out.Close()
+	}
+
+	if err := ui.StartProgress(out, answers, os.Stdin); err != nil {
+		return fail(err)
+	}
+	exitCode := runPublishCommand(ctx, opts)
+	ui.FinishProgress(exitCode)
+	return exitCode
+}
+
 // runIdentityCommand handles the identity subcommand.
 func runIdentityCommand(ctx context.Context, opts *cli.Options) int {
 	// Handle no-color for subcommand
@@ -277,7 +487,15 @@ func runUtilsCommand(ctx context.Context, opts *cli.Options) int {
 			}
 			return 1
 		}
-		if err := extractAPKMetadata(opts.Args[0]); err != nil {
+		if err := opts.Publish.ValidateIconDensity(); err != nil {
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+		if err := extractAPKMetadata(opts.Args[0], opts.Publish.IconDensity); err != nil {
 			if opts.Global.JSON {
 				ui.PrintJSONError(err)
 			} else {
@@ -313,6 +531,68 @@ func runUtilsCommand(ctx context.Context, opts *cli.Options) int {
 	}
 }
 
+// runConfigCommand handles the config subcommand.
+func runConfigCommand(ctx context.Context, opts *cli.Options) int {
+	if opts.Global.NoColor {
+		ui.SetNoColor(true)
+	}
+
+	switch opts.Config.Operation {
+	case "migrate":
+		if len(opts.Args) == 0 {
+			if opts.Global.JSON {
+				ui.PrintJSONError(fmt.Errorf("migrate requires a config file as argument"))
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: migrate requires a config file as argument")
+				fmt.Fprintln(os.Stderr, "Usage: zsp config migrate [--dry-run] <config.yaml>")
+			}
+			return 1
+		}
+		if err := config.MigrateFile(opts.Args[0], opts.Config.DryRun, opts.Global.JSON); err != nil {
+			if opts.Global.JSON {
+				ui.PrintJSONError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+			}
+			return 1
+		}
+		return 0
+
+	case "validate":
+		path := "zapstore.yaml"
+		if len(opts.Args) > 0 {
+			path = opts.Args[0]
+		}
+		return workflow.ValidateConfigFile(ctx, path, opts)
+
+	default:
+		err := fmt.Errorf("unknown config subcommand %q", opts.Config.Operation)
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintln(os.Stderr, ui.SanitizeErrorMessage(err))
+			help.HandleHelp(cli.CommandConfig, nil)
+		}
+		return 1
+	}
+}
+
+// runHistoryCommand handles the history subcommand.
+func runHistoryCommand(ctx context.Context, opts *cli.Options) int {
+	if err := workflow.ShowHistory(ctx, opts); err != nil {
+		if errors.Is(err, ui.ErrInterrupted) || errors.Is(err, context.Canceled) {
+			return 130
+		}
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+	return 0
+}
+
 // hasNewRelease checks whether there is a new release since the last successful publish.
 // It is a read-only, local-cache-based check: it uses ETag and the stored
 // latest_published_release_version. It does NOT download the APK or query the relay.
@@ -387,15 +667,136 @@ func hasNewRelease(ctx context.Context, arg string, opts *cli.Options) error {
 	return nil
 }
 
-// runPublish executes the publish workflow.
-func runPublish(ctx context.Context, opts *cli.Options, cfg *config.Config) error {
+// exitInProgress is the exit code when another publish of the same package
+// holds its lock (EX_TEMPFAIL: try again later).
+const exitInProgress = 75
+
+// runPublish executes the publish workflow. The relays and Blossom server
+// it resolves are recorded in report for --bug-report.
+func runPublish(ctx context.Context, opts *cli.Options, cfg *config.Config, report *bugreport.Report) error {
 	pub, err := workflow.NewPublisher(ctx, opts, cfg)
 	if err != nil {
+		recordPublishOutcome("", err)
 		return err
 	}
 	defer pub.Close()
+	report.RelayURLs, report.BlossomURL = pub.Endpoints()
+
+	err = pub.Execute(ctx)
+	recordPublishOutcome(pub.PackageID(), err)
+	return err
+}
+
+// selectApps returns the apps of a monorepo config to publish, narrowed by --only,
+// with glob patterns normalized and each app validated. Returns nil for a
+// single-app config.
+func selectApps(opts *cli.Options, cfg *config.Config) ([]*config.Config, error) {
+	apps, err := cfg.SelectApps(opts.Publish.Only)
+	if err != nil || len(apps) == 0 {
+		return nil, err
+	}
+	if opts.Publish.Match != "" {
+		return nil, fmt.Errorf("--match cannot be used with an apps: list; set match on each app")
+	}
+	if opts.Publish.MatchLabel != "" {
+		return nil, fmt.Errorf("--match-label cannot be used with an apps: list; set match_label on each app")
+	}
+	for _, app := range apps {
+		if opts.Publish.ChangelogFrom == "git" {
+			app.ReleaseNotes = source.GitLogReleaseNotes
+		}
+		for _, notice := range app.NormalizePatterns() {
+			if !opts.Publish.Quiet && !opts.Global.JSON {
+				fmt.Fprintf(os.Stderr, "notice: %s: %s\n", app.Name, notice)
+			}
+		}
+		if err := app.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration for app %q: %w", app.Name, err)
+		}
+		if opts.Publish.Variant != "" {
+			if _, err := app.VariantPattern(opts.Publish.Variant); err != nil {
+				return nil, fmt.Errorf("app %q: %w", app.Name, err)
+			}
+		}
+	}
+	return apps, nil
+}
+
+// runPublishApps publishes each app of a monorepo config and reports every
+// outcome. Returns 1 if any app failed, 130 if cancelled.
+func runPublishApps(ctx context.Context, opts *cli.Options, apps []*config.Config) int {
+	results := workflow.PublishApps(ctx, opts, apps)
+
+	exitCode := 0
+	for _, r := range results {
+		recordPublishOutcome(r.PackageID, r.Err)
+		switch {
+		case r.Err == nil, errors.Is(r.Err, workflow.ErrNothingToDo):
+		case errors.Is(r.Err, context.Canceled):
+			exitCode = 130
+		case errors.Is(r.Err, publock.ErrLocked):
+			if exitCode == 0 {
+				exitCode = exitInProgress
+			}
+		default:
+			if exitCode == 0 {
+				exitCode = 1
+			}
+		}
+	}
+
+	if opts.Global.JSON {
+		for _, r := range results {
+			if r.Err != nil && !errors.Is(r.Err, workflow.ErrNothingToDo) {
+				ui.PrintJSONError(fmt.Errorf("%s: %w", r.Name, r.Err))
+			}
+		}
+		return exitCode
+	}
+	if opts.Publish.Quiet && exitCode == 0 {
+		return exitCode
+	}
+
+	done := "published"
+	if opts.Publish.Offline || opts.Publish.SignOnly {
+		done = "signed"
+	}
+	ui.PrintHeader("Apps")
+	for _, r := range results {
+		label := r.Name
+		if r.PackageID != "" {
+			label += " (" + r.PackageID
+			if r.Version != "" {
+				label += " " + r.Version
+			}
+			label += ")"
+		}
+		switch {
+		case r.Err == nil:
+			ui.PrintSuccess(label + ": " + done)
+		case errors.Is(r.Err, workflow.ErrNothingToDo):
+			ui.PrintInfo(label + ": already published")
+		default:
+			ui.PrintError(label + ": " + ui.SanitizeErrorMessage(r.Err))
+		}
+	}
+	return exitCode
+}
 
-	return pub.Execute(ctx)
+// recordPublishOutcome counts a publish run for --metrics-out, labeled by package.
+func recordPublishOutcome(packageID string, err error) {
+	if packageID == "" {
+		packageID = "unknown"
+	}
+	metrics.Inc(metrics.PublishAttempted, "package", packageID)
+	switch {
+	case err == nil:
+		metrics.Inc(metrics.PublishSucceeded, "package", packageID)
+	case errors.Is(err, workflow.ErrNothingToDo):
+		metrics.Inc(metrics.PublishSkipped, "package", packageID)
+	default:
+		metrics.Inc(metrics.PublishFailed, "package", packageID)
+	}
 }
 
 // loadConfig loads configuration from various sources.
@@ -413,11 +814,7 @@ func loadConfig(opts *cli.PublishOptions, args []string) (*config.Config, error)
 		if cfg, err := config.Load(configPath); err == nil {
 			defaults = cfg
 		}
-		return config.RunWizardWithOptions(defaults, config.WizardOptions{
-			FetchAPKInfo:   fetchAPKInfoForWizard,
-			ResolvePubkey:  resolvePubkeyForWizard,
-			CheckAppExists: checkAppExistsForWizard,
-		})
+		return config.RunWizardWithOptions(defaults, wizardOptions(opts))
 	}
 
 	// Quick mode with APK file as positional argument
@@ -453,11 +850,7 @@ func loadConfig(opts *cli.PublishOptions, args []string) (*config.Config, error)
 		return nil, fmt.Errorf("no configuration provided. Use 'zsp publish <config.yaml>' or 'zsp publish -r <repo-url>'")
 	}
 
-	return config.RunWizardWithOptions(nil, config.WizardOptions{
-		FetchAPKInfo:   fetchAPKInfoForWizard,
-		ResolvePubkey:  resolvePubkeyForWizard,
-		CheckAppExists: checkAppExistsForWizard,
-	})
+	return config.RunWizardWithOptions(nil, wizardOptions(opts))
 }
 
 // loadConfigWithMigrationCheck loads a config file, detecting and migrating zapstore-cli format if needed.
@@ -521,15 +914,29 @@ func loadConfigWithMigrationCheck(path string, quiet bool) (*config.Config, erro
 	return config.Load(path)
 }
 
+// wizardOptions returns the wizard callbacks, fetching releases the same way publish will.
+func wizardOptions(opts *cli.PublishOptions) config.WizardOptions {
+	return config.WizardOptions{
+		FetchAPKInfo: func(cfg *config.Config, matchPattern string) *config.APKBasicInfo {
+			return fetchAPKInfoForWizard(cfg, matchPattern, opts.IncludePreReleases)
+		},
+		ResolvePubkey:  resolvePubkeyForWizard,
+		CheckAppExists: checkAppExistsForWizard,
+	}
+}
+
 // fetchAPKInfoForWizard downloads the APK and extracts basic info.
 // This is passed as a callback to the wizard since config package can't import source/picker/apk.
-func fetchAPKInfoForWizard(cfg *config.Config, matchPattern string) *config.APKBasicInfo {
+func fetchAPKInfoForWizard(cfg *config.Config, matchPattern string, includePreReleases bool) *config.APKBasicInfo {
 	ctx := ui.GetContext()
 
 	spinner := ui.NewSpinner("Fetching APK to detect app info...")
 	spinner.Start()
 
-	src, err := source.NewWithOptions(cfg, source.Options{})
+	src, err := source.NewWithOptions(cfg, source.Options{
+		BaseDir:            cfg.BaseDir,
+		IncludePreReleases: includePreReleases,
+	})
 	if err != nil {
 		spinner.StopWithWarning("Could not create source")
 		return nil
@@ -621,6 +1028,10 @@ func checkAppExistsForWizard(ctx context.Context, packageID string) bool {
 
 // loadAPKConfig creates config from a local APK path with optional -r and -s flags.
 func loadAPKConfig(opts *cli.PublishOptions, apkPath string) (*config.Config, error) {
+	// The APK argument is relative to the working directory, not to --base-dir
+	if abs, err := filepath.Abs(apkPath); err == nil {
+		apkPath = abs
+	}
 	cfg := &config.Config{
 		ReleaseSource: &config.ReleaseSource{LocalPath: apkPath},
 	}
@@ -675,24 +1086,26 @@ func normalizeRepoURL(url string) string {
 }
 
 // extractAPKMetadata parses an APK and outputs its metadata as JSON.
-func extractAPKMetadata(apkPath string) error {
-	apkInfo, err := apk.Parse(apkPath)
+// iconDensity selects the icon raster as for publish --icon-density.
+func extractAPKMetadata(apkPath, iconDensity string) error {
+	apkInfo, err := apk.ParseWithOptions(apkPath, apk.ParseOptions{IconDensity: iconDensity})
 	if err != nil {
 		return fmt.Errorf("failed to parse APK: %w", err)
 	}
 
 	output := map[string]any{
-		"package_id":       apkInfo.PackageID,
-		"version_name":     apkInfo.VersionName,
-		"version_code":     apkInfo.VersionCode,
-		"min_sdk":          apkInfo.MinSDK,
-		"target_sdk":       apkInfo.TargetSDK,
-		"label":            apkInfo.Label,
-		"architectures":    apkInfo.Architectures,
-		"cert_fingerprint": apkInfo.CertFingerprint,
-		"file_path":        apkInfo.FilePath,
-		"file_size":        apkInfo.FileSize,
-		"sha256":           apkInfo.SHA256,
+		"package_id":        apkInfo.PackageID,
+		"version_name":      apkInfo.VersionName,
+		"version_code":      apkInfo.VersionCode,
+		"min_sdk":           apkInfo.MinSDK,
+		"target_sdk":        apkInfo.TargetSDK,
+		"label":             apkInfo.Label,
+		"architectures":     apkInfo.Architectures,
+		"cert_fingerprint":  apkInfo.CertFingerprint,
+		"signature_schemes": apkInfo.SignatureSchemes,
+		"file_path":         apkInfo.FilePath,
+		"file_size":         apkInfo.FileSize,
+		"sha256":            apkInfo.SHA256,
 	}
 
 	if apkInfo.Icon != nil {
@@ -704,6 +1117,13 @@ func extractAPKMetadata(apkPath string) error {
 		output["icon"] = iconPath
 	}
 
+	if len(apkInfo.CertLineage) > 0 {
+		output["cert_lineage"] = apkInfo.CertLineage
+	}
+	if len(apkInfo.Locales) > 0 {
+		output["locales"] = apkInfo.Locales
+	}
+
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
 	return enc.Encode(output)
@@ -727,15 +1147,23 @@ func checkAPK(ctx context.Context, opts *cli.Options) error {
 		return err
 	}
 
+	for _, notice := range cfg.NormalizePatterns() {
+		if !opts.Global.JSON {
+			fmt.Fprintf(os.Stderr, "notice: %s\n", notice)
+		}
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return err
 	}
+	netpolicy.SetAllowlist(cfg.AllowedHosts())
 
 	src, err := source.NewWithOptions(cfg, source.Options{
 		BaseDir:            cfg.BaseDir,
 		SkipCache:          true,
 		SkipDownloadCache:  true,
 		IncludePreReleases: opts.Publish.IncludePreReleases,
+		StrictRedirects:    opts.Publish.StrictRedirects,
 	})
 	if err != nil {
 		return err
@@ -778,6 +1206,27 @@ func checkAPK(ctx context.Context, opts *cli.Options) error {
 		}
 	}
 
+	if cfg.MatchLabel != "" {
+		apkAssets = picker.FilterByLabel(apkAssets, cfg.MatchLabel)
+		if len(apkAssets) == 0 {
+			return fmt.Errorf("no APK files match label: %s", cfg.MatchLabel)
+		}
+	}
+
+	if opts.Publish.Variant != "" {
+		pattern, err := cfg.VariantPattern(opts.Publish.Variant)
+		if err != nil {
+			return err
+		}
+		apkAssets, _, err = picker.PartitionByVariant(apkAssets, opts.Publish.Variant, pattern)
+		if err != nil {
+			return err
+		}
+		if len(apkAssets) == 0 {
+			return fmt.Errorf("no APK files match variant %s: %s", opts.Publish.Variant, pattern)
+		}
+	}
+
 	var selectedAsset *source.Asset
 	if len(apkAssets) == 1 {
 		selectedAsset = apkAssets[0]
@@ -809,11 +1258,40 @@ func checkAPK(ctx context.Context, opts *cli.Options) error {
 		return fmt.Errorf("APK does not support arm64-v8a architecture (found: %v)", apkInfo.Architectures)
 	}
 
+	if apkInfo.IsV1OnlySigned() && !opts.Publish.AllowV1Only {
+		return fmt.Errorf("%w; re-sign it with apksigner (v2/v3) or pass --allow-v1-only", apk.ErrV1OnlySignature)
+	}
+
 	data, _ := json.Marshal(map[string]string{"package_id": apkInfo.PackageID})
 	fmt.Println(string(data))
 	return nil
 }
 
+// explainPlan prints the steps a publish run would take for cfg, or for
+// each app of an apps: list; one JSON line per app with --json.
+func explainPlan(opts *cli.Options, cfg *config.Config, apps []*config.Config) {
+	if len(apps) == 0 {
+		apps = []*config.Config{cfg}
+	}
+	for i, app := range apps {
+		steps := workflow.ExplainPlan(opts, app)
+		if opts.Global.JSON {
+			data, _ := json.Marshal(map[string]any{"app": app.Name, "steps": steps})
+			fmt.Println(string(data))
+			continue
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		if app.Name != "" && len(apps) > 1 {
+			fmt.Printf("%s:\n", app.Name)
+		}
+		for n, step := range steps {
+			fmt.Printf("%d. %s\n", n+1, step)
+		}
+	}
+}
+
 // runLinkKey handles the --link-key flag for linking a signing certificate to a Nostr identity.
 func runLinkKey(ctx context.Context, opts *cli.Options) error {
 	filePath := opts.Identity.LinkKey
@@ -1213,6 +1691,166 @@ func runVerifyIdentity(ctx context.Context, opts *cli.Options) error {
 	return nil
 }
 
+// runCompareCert dispatches --compare-cert. A certificate file is loaded here,
+// since loading it may prompt for the keystore password or the private key file.
+func runCompareCert(ctx context.Context, opts *cli.Options) error {
+	var known *x509.Certificate
+	if len(opts.Args) == 2 {
+		if _, ok := workflow.ParsePubkeyArg(opts.Args[1]); !ok {
+			_, cert, err := loadX509FromFile(opts.Args[1])
+			if err != nil {
+				return err
+			}
+			known = cert
+		}
+	}
+	return workflow.CompareCert(ctx, opts, known)
+}
+
+// runAPKCommand handles the apk subcommand.
+func runAPKCommand(ctx context.Context, opts *cli.Options) int {
+	if opts.Global.NoColor {
+		ui.SetNoColor(true)
+	}
+
+	run := runCompareCert
+	if opts.APK.Lint {
+		run = workflow.LintAPK
+	}
+	if err := run(ctx, opts); err != nil {
+		if errors.Is(err, ui.ErrInterrupted) || errors.Is(err, context.Canceled) {
+			return 130
+		}
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+	return 0
+}
+
+// runBlossomCommand handles the blossom subcommand.
+func runBlossomCommand(ctx context.Context, opts *cli.Options) int {
+	if opts.Global.NoColor {
+		ui.SetNoColor(true)
+	}
+
+	var err error
+	switch opts.Blossom.Operation {
+	case "list":
+		err = workflow.BlossomList(ctx, opts)
+	case "prune":
+		err = workflow.BlossomPrune(ctx, opts)
+	default:
+		help.HandleHelp(cli.CommandBlossom, nil)
+		return 0
+	}
+
+	if err != nil {
+		if errors.Is(err, ui.ErrInterrupted) || errors.Is(err, context.Canceled) {
+			return 130
+		}
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+	return 0
+}
+
+// runRelayCommand handles the relay subcommand.
+func runRelayCommand(ctx context.Context, opts *cli.Options) int {
+	if opts.Global.NoColor {
+		ui.SetNoColor(true)
+	}
+
+	var err error
+	switch opts.Relay.Operation {
+	case "info":
+		err = workflow.RelayInfo(ctx, opts)
+	default:
+		help.HandleHelp(cli.CommandRelay, nil)
+		return 0
+	}
+
+	if err != nil {
+		if errors.Is(err, ui.ErrInterrupted) || errors.Is(err, context.Canceled) {
+			return 130
+		}
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+	return 0
+}
+
+// runKeysCommand handles the keys subcommand.
+func runKeysCommand(ctx context.Context, opts *cli.Options) int {
+	if opts.Global.NoColor {
+		ui.SetNoColor(true)
+	}
+
+	var err error
+	switch opts.Keys.Operation {
+	case "generate":
+		err = workflow.KeysGenerate(opts)
+	case "show":
+		err = workflow.KeysShow(ctx, opts)
+	default:
+		help.HandleHelp(cli.CommandKeys, nil)
+		return 0
+	}
+
+	if err != nil {
+		if errors.Is(err, ui.ErrInterrupted) || errors.Is(err, context.Canceled) {
+			return 130
+		}
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+	return 0
+}
+
+// runExportCommand handles the export subcommand.
+func runExportCommand(ctx context.Context, opts *cli.Options) int {
+	if opts.Global.NoColor {
+		ui.SetNoColor(true)
+	}
+
+	var err error
+	switch opts.Export.Operation {
+	case "badge":
+		err = workflow.ExportBadge(ctx, opts)
+	default:
+		help.HandleHelp(cli.CommandExport, nil)
+		return 0
+	}
+
+	if err != nil {
+		if errors.Is(err, ui.ErrInterrupted) || errors.Is(err, context.Canceled) {
+			return 130
+		}
+		if opts.Global.JSON {
+			ui.PrintJSONError(err)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", ui.SanitizeErrorMessage(err))
+		}
+		return 1
+	}
+	return 0
+}
+
 // extractPubkeyFromSignWith extracts the pubkey from signWith without creating a signer.
 // Returns (pubkey, true) for nsec/npub/hex, or ("", false) for browser/bunker.
 func extractPubkeyFromSignWith(signWith string) (string, bool) {